@@ -0,0 +1,558 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"exportready-battery/internal/openapi"
+)
+
+// Contract checks for the HTTP API against the OpenAPI document in internal/openapi.
+//
+//	static: every route registered in cmd/server/main.go is documented, and every
+//	        documented route is registered (no server or database needed)
+//	live:   runs a scripted tenant workflow against a running server and validates
+//	        each JSON response against the documented schema for its status code
+//
+// Usage:
+//
+//	go run ./cmd/contract -static
+//	go run ./cmd/contract -base http://localhost:8080
+//
+// The live run creates a throwaway tenant. The server must run with
+// APP_ENV=development so magic links are returned in the response.
+func main() {
+	static := flag.Bool("static", false, "only compare registered routes with the document")
+	base := flag.String("base", "", "base URL of a running server for live checks")
+	mainFile := flag.String("main", "cmd/server/main.go", "server main file to read routes from")
+	flag.Parse()
+
+	failed := false
+	if !checkRoutes(*mainFile) {
+		failed = true
+	}
+	if !*static && *base != "" {
+		if !runLive(strings.TrimSuffix(*base, "/")) {
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+	fmt.Println("✅ Contract checks passed")
+}
+
+// ============================================================================
+// STATIC: registered routes vs documented routes
+// ============================================================================
+
+// registeredPatterns returns the string-literal patterns passed to mux.Handle/HandleFunc
+func registeredPatterns(path string) ([]string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	var patterns []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || (sel.Sel.Name != "Handle" && sel.Sel.Name != "HandleFunc") {
+			return true
+		}
+		if recv, ok := sel.X.(*ast.Ident); !ok || recv.Name != "mux" {
+			return true
+		}
+		if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			if pattern, err := strconv.Unquote(lit.Value); err == nil {
+				patterns = append(patterns, pattern)
+			}
+		}
+		return true
+	})
+	sort.Strings(patterns)
+	return patterns, nil
+}
+
+func checkRoutes(mainFile string) bool {
+	registered, err := registeredPatterns(mainFile)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+	documented := openapi.Patterns()
+
+	missing := difference(registered, documented)
+	stale := difference(documented, registered)
+	for _, p := range missing {
+		fmt.Printf("❌ registered but not documented: %s\n", p)
+	}
+	for _, p := range stale {
+		fmt.Printf("❌ documented but not registered: %s\n", p)
+	}
+
+	fmt.Printf("Routes: %d registered, %d documented\n", len(registered), len(documented))
+	return len(missing) == 0 && len(stale) == 0
+}
+
+// difference returns the items of a that are not in b
+func difference(a, b []string) []string {
+	seen := make(map[string]bool, len(b))
+	for _, s := range b {
+		seen[s] = true
+	}
+	var out []string
+	for _, s := range a {
+		if !seen[s] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// ============================================================================
+// LIVE: scripted workflow with response validation
+// ============================================================================
+
+type client struct {
+	base       string
+	http       *http.Client
+	jwt        string
+	apiKey     string
+	magicToken string
+
+	exercised  map[string]bool
+	violations []string
+}
+
+type authMode int
+
+const (
+	noAuth authMode = iota
+	jwtAuth
+	keyAuth
+	magicAuth
+)
+
+// call performs a request for a documented route pattern, validates the response and
+// decodes it into out (if non-nil). Path values replace {name} segments; a query
+// string may follow the pattern, e.g. "GET /api/v1/batches?tenant_id=...".
+func (c *client) call(pattern string, auth authMode, path map[string]string, body interface{}, out interface{}) int {
+	pattern, query, _ := strings.Cut(pattern, "?")
+	method, urlPath, _ := strings.Cut(pattern, " ")
+	for name, value := range path {
+		urlPath = strings.Replace(urlPath, "{"+name+"}", value, 1)
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	}
+	if query != "" {
+		urlPath += "?" + query
+	}
+	req, err := http.NewRequest(method, c.base+urlPath, reader)
+	if err != nil {
+		c.violations = append(c.violations, fmt.Sprintf("%s: %v", pattern, err))
+		return 0
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	switch auth {
+	case jwtAuth:
+		req.Header.Set("Authorization", "Bearer "+c.jwt)
+	case keyAuth:
+		req.Header.Set("X-API-Key", c.apiKey)
+	case magicAuth:
+		req.Header.Set("Authorization", "Bearer "+c.magicToken)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		c.violations = append(c.violations, fmt.Sprintf("%s: %v", pattern, err))
+		return 0
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+
+	c.exercised[pattern] = true
+	if err := openapi.ValidateResponse(pattern, resp.StatusCode, data); err != nil {
+		c.violations = append(c.violations, err.Error())
+		fmt.Printf("❌ %s -> %d\n", pattern, resp.StatusCode)
+	} else {
+		fmt.Printf("   %s -> %d\n", pattern, resp.StatusCode)
+	}
+
+	if out != nil && resp.StatusCode < 300 {
+		json.Unmarshal(data, out)
+	}
+	return resp.StatusCode
+}
+
+func runLive(base string) bool {
+	c := &client{
+		base:      base,
+		http:      &http.Client{Timeout: 30 * time.Second},
+		exercised: map[string]bool{},
+	}
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	partnerDomain := "contract-" + suffix + ".example"
+	specs := map[string]interface{}{
+		"chemistry": "LFP", "voltage": "3.2V", "capacity": "100Ah", "manufacturer": "Contract Test",
+		"weight": "2kg", "carbon_footprint": "10 kg CO2e", "country_of_origin": "India", "battery_category": "PORTABLE",
+	}
+
+	c.call("GET /health", noAuth, nil, nil, nil)
+	c.call("GET /api/v1/openapi.json", noAuth, nil, nil, nil)
+
+	// Tenant & dashboard session
+	var auth struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+		TenantID     string `json:"tenant_id"`
+	}
+	email := "contract-" + suffix + "@example.com"
+	c.call("POST /api/v1/auth/register", noAuth, nil, map[string]string{
+		"company_name": "Contract " + suffix, "email": email, "password": "Contract-Pass-123",
+	}, &auth)
+	if auth.Token == "" {
+		return c.report()
+	}
+	c.jwt = auth.Token
+	tenant := auth.TenantID
+	c.call("POST /api/v1/auth/login", noAuth, nil, map[string]string{"email": email, "password": "Contract-Pass-123"}, nil)
+	c.call("POST /api/v1/auth/refresh", noAuth, nil, map[string]string{"refresh_token": auth.RefreshToken}, nil)
+	c.call("GET /api/v1/auth/me", jwtAuth, nil, nil, nil)
+	c.call("PUT /api/v1/auth/profile", jwtAuth, nil, map[string]string{"website": "https://example.com"}, nil)
+
+	// Batches
+	var created struct {
+		Batch struct {
+			ID string `json:"id"`
+		} `json:"batch"`
+	}
+	c.call("POST /api/v1/batches", jwtAuth, nil, map[string]interface{}{
+		"tenant_id": tenant, "batch_name": "Contract " + suffix, "market_region": "GLOBAL", "specs": specs,
+	}, &created)
+	batch := map[string]string{"id": created.Batch.ID}
+	c.call("POST /api/v1/batches", jwtAuth, nil, map[string]interface{}{
+		"tenant_id": tenant, "batch_name": "Contract US/UK " + suffix, "markets": []string{"US", "UK"}, "specs": specs,
+	}, nil)
+	c.call("GET /api/v1/batches?tenant_id="+tenant, jwtAuth, nil, nil, nil)
+	c.call("GET /api/v1/batches/{id}", jwtAuth, batch, nil, nil)
+	c.call("POST /api/v1/batches/{id}/auto-generate", jwtAuth, batch, map[string]interface{}{"count": 3, "prefix": "CT" + suffix}, nil)
+
+	var passports struct {
+		Passports []struct {
+			UUID         string `json:"uuid"`
+			SerialNumber string `json:"serial_number"`
+		} `json:"passports"`
+	}
+	c.call("GET /api/v1/batches/{id}/passports", jwtAuth, batch, nil, &passports)
+	c.call("GET /api/v1/passports/search?q=CT"+suffix, jwtAuth, nil, nil, nil)
+	c.call("POST /api/v1/graphql", jwtAuth, nil, map[string]string{
+		"query": `{ tenant { companyName } batches(first: 5) { nodes { id name passports(first: 5) { uuid events { eventType } scans { city } } } pageInfo { hasMore endCursor } } }`,
+	}, nil)
+	c.call("GET /api/v1/batches/{id}/export", jwtAuth, batch, nil, nil)
+	c.call("GET /api/v1/batches/recent?tenant_id="+tenant, jwtAuth, nil, nil, nil)
+	c.call("GET /api/v1/dashboard/stats?tenant_id="+tenant, jwtAuth, nil, nil, nil)
+	c.call("GET /api/v1/scans/feed?tenant_id="+tenant, jwtAuth, nil, nil, nil)
+
+	// Billing
+	c.call("GET /api/v1/billing/balance", jwtAuth, nil, nil, nil)
+	c.call("POST /api/v1/billing/top-up", jwtAuth, nil, map[string]int{"amount": 5}, nil)
+	c.call("GET /api/v1/billing/packages", jwtAuth, nil, nil, nil)
+	c.call("POST /api/v1/batches/{id}/activate", jwtAuth, batch, nil, nil)
+	c.call("GET /api/v1/billing/transactions", jwtAuth, nil, nil, nil)
+
+	// Lifecycle
+	if len(passports.Passports) > 0 {
+		passport := map[string]string{"uuid": passports.Passports[0].UUID}
+		c.call("GET /api/v1/passports/{uuid}", noAuth, passport, nil, nil)
+		c.call("GET /api/v1/passports/{uuid}/transitions", jwtAuth, passport, nil, nil)
+		c.call("POST /api/v1/passports/{uuid}/transition", jwtAuth, passport, map[string]string{"to_status": "SHIPPED"}, nil)
+		c.call("GET /api/v1/passports/{uuid}/events", jwtAuth, passport, nil, nil)
+		c.call("POST /api/v1/scans/record", noAuth, nil, map[string]string{"passport_id": passport["uuid"]}, nil)
+	}
+
+	// Recalls (last passport only; the first is used by the magic link flow below)
+	if n := len(passports.Passports); n > 1 {
+		target := passports.Passports[n-1]
+		scope := map[string]interface{}{"batch_ids": []string{batch["id"]}, "serial_from": target.SerialNumber, "serial_to": target.SerialNumber}
+		var recall struct {
+			Campaign struct {
+				ID string `json:"id"`
+			} `json:"campaign"`
+		}
+		c.call("POST /api/v1/recalls/preview", jwtAuth, nil, scope, nil)
+		c.call("POST /api/v1/recalls", jwtAuth, nil, map[string]interface{}{
+			"title": "Contract recall " + suffix, "reason": "Cell swelling", "severity": "HIGH", "scope": scope,
+		}, &recall)
+		rc := map[string]string{"id": recall.Campaign.ID}
+		c.call("GET /api/v1/recalls", jwtAuth, nil, nil, nil)
+		c.call("GET /api/v1/recalls/{id}", jwtAuth, rc, nil, nil)
+		c.call("GET /api/v1/recalls/{id}/units", jwtAuth, rc, nil, nil)
+		c.call("GET /api/v1/passports/{uuid}", noAuth, map[string]string{"uuid": target.UUID}, nil, nil)
+		c.call("POST /api/v1/recalls/{id}/notify", jwtAuth, rc, nil, nil)
+		c.call("POST /api/v1/recalls/{id}/remedies", jwtAuth, rc, map[string]interface{}{
+			"passport_ids": []string{target.UUID}, "remedy_status": "RECYCLED",
+		}, nil)
+		c.call("POST /api/v1/recalls/{id}/close", jwtAuth, rc, nil, nil)
+	}
+
+	// Custody (second passport; acceptance needs the emailed token, so the transfer is cancelled)
+	if n := len(passports.Passports); n > 2 {
+		target := map[string]string{"uuid": passports.Passports[1].UUID}
+		var transfer struct {
+			GroupID string `json:"group_id"`
+		}
+		c.call("POST /api/v1/passports/{uuid}/custody/transfers", jwtAuth, target, map[string]interface{}{
+			"to_email": "distributor@" + partnerDomain, "to_name": "Contract Distributor", "to_type": "DISTRIBUTOR",
+			"document_ref": "INV-" + suffix,
+		}, &transfer)
+		var transfers struct {
+			Transfers []struct {
+				ID string `json:"id"`
+			} `json:"transfers"`
+		}
+		c.call("GET /api/v1/custody/transfers?status=PENDING", jwtAuth, nil, nil, &transfers)
+		c.call("GET /api/v1/passports/{uuid}/custody?at=2026-03-15", jwtAuth, target, nil, nil)
+		c.call("GET /api/v1/custody/accept?token=contract-"+suffix, noAuth, nil, nil, nil)
+		if len(transfers.Transfers) > 0 {
+			c.call("POST /api/v1/custody/transfers/{id}/cancel", jwtAuth, map[string]string{"id": transfers.Transfers[0].ID}, nil, nil)
+		}
+	}
+
+	// Shipments (second passport, after its custody transfer was cancelled)
+	if n := len(passports.Passports); n > 2 {
+		var shipment struct {
+			Shipment struct {
+				ID string `json:"id"`
+			} `json:"shipment"`
+		}
+		c.call("POST /api/v1/shipments", jwtAuth, nil, map[string]interface{}{
+			"reference": "SHP-" + suffix, "destination_country": "DE", "consignee_name": "Contract Importer GmbH",
+			"invoice_number": "INV-" + suffix, "incoterm": "FOB", "passport_ids": []string{passports.Passports[1].UUID},
+			"unit_values": map[string]float64{batch["id"]: 120},
+		}, &shipment)
+		sc := map[string]string{"id": shipment.Shipment.ID}
+		c.call("GET /api/v1/shipments?status=DRAFT", jwtAuth, nil, nil, nil)
+		c.call("GET /api/v1/shipments/{id}", jwtAuth, sc, nil, nil)
+		c.call("GET /api/v1/shipments/{id}/documents?format=pdf", jwtAuth, sc, nil, nil)
+		c.call("GET /api/v1/shipments/{id}/documents", jwtAuth, sc, nil, nil)
+		c.call("POST /api/v1/shipments/{id}/ship", jwtAuth, sc, nil, nil)
+		c.call("POST /api/v1/shipments/{id}/cancel", jwtAuth, sc, nil, nil)
+	}
+
+	// Warranty claims are filed as multipart through a magic link; only the review list is JSON-only
+	c.call("GET /api/v1/warranty-claims", jwtAuth, nil, nil, nil)
+
+	// Carbon footprint (specs above: 100Ah x 3.2V = 0.32 kWh)
+	var factors struct {
+		Factors []struct {
+			ID string `json:"id"`
+		} `json:"factors"`
+	}
+	c.call("POST /api/v1/emission-factors", jwtAuth, nil, map[string]interface{}{
+		"factors": []map[string]interface{}{
+			{"key": "cam.lfp", "name": "LFP cathode active material", "unit": "kg", "kg_co2e_per_unit": 12.5, "source": "Contract"},
+			{"key": "electricity.in", "name": "Indian grid electricity", "unit": "kWh", "kg_co2e_per_unit": 0.71, "region": "IN"},
+			{"key": "contract.obsolete", "name": "Obsolete factor", "unit": "kg", "kg_co2e_per_unit": 1},
+		},
+	}, &factors)
+	c.call("GET /api/v1/emission-factors", jwtAuth, nil, nil, nil)
+	footprint := map[string]interface{}{
+		"bill_of_materials": []map[string]interface{}{{"name": "Cathode", "factor_key": "cam.lfp", "quantity": 0.6}},
+		"energy":            []map[string]interface{}{{"name": "Cell formation", "factor_key": "electricity.in", "quantity": 12}},
+	}
+	c.call("POST /api/v1/batches/{id}/carbon-footprint?dry_run=true", jwtAuth, batch, footprint, nil)
+	c.call("POST /api/v1/batches/{id}/carbon-footprint", jwtAuth, batch, footprint, nil)
+	c.call("GET /api/v1/batches/{id}/carbon-footprint", jwtAuth, batch, nil, nil)
+	if len(factors.Factors) == 3 {
+		c.call("DELETE /api/v1/emission-factors/{id}", jwtAuth, map[string]string{"id": factors.Factors[2].ID}, nil, nil)
+	}
+
+	// Recycled content and due diligence (audit report uploads are multipart, like warranty claims)
+	c.call("PUT /api/v1/batches/{id}/due-diligence", jwtAuth, batch, map[string]interface{}{
+		"recycled_content": map[string]float64{"lithium_pct": 6},
+		"due_diligence": map[string]interface{}{
+			"policy": "OECD Due Diligence Guidance",
+			"suppliers": []map[string]string{
+				{"name": "Contract Lithium Refinery", "role": "REFINER", "material": "lithium", "country": "AU", "certification": "IRMA 50"},
+				{"name": "Contract Graphite Mine", "role": "MINE", "material": "graphite", "country": "MZ"},
+			},
+		},
+	}, nil)
+	c.call("GET /api/v1/batches/{id}/due-diligence", jwtAuth, batch, nil, nil)
+	c.call("GET /api/v1/batches/{id}/compliance?market=GLOBAL", jwtAuth, batch, nil, nil)
+	c.call("GET /api/v1/batches/{id}/compliance?market=US,UK", jwtAuth, batch, nil, nil)
+	c.call("GET /api/v1/markets", jwtAuth, nil, nil, nil)
+	c.call("GET /api/v1/battery-categories", jwtAuth, nil, nil, nil)
+
+	// PLI DVA audits (the GLOBAL batch targets INDIA; attestation is multipart)
+	c.call("POST /api/v1/batches/{id}/pli-audit", jwtAuth, batch, map[string]string{"ca_email": "ca-" + suffix + "@example.com"}, nil)
+	c.call("GET /api/v1/batches/{id}/pli-audit", jwtAuth, batch, nil, nil)
+	c.call("GET /api/v1/pli-audit/attest?token=contract-"+suffix, noAuth, nil, nil, nil)
+
+	// EPR annual return
+	c.call("PUT /api/v1/epr/targets", jwtAuth, nil, map[string]interface{}{
+		"targets": []map[string]interface{}{{"category": "PORTABLE", "target_pct": 80}},
+	}, nil)
+	c.call("GET /api/v1/epr/targets", jwtAuth, nil, nil, nil)
+	c.call("GET /api/v1/epr/annual-return", jwtAuth, nil, nil, nil)
+	c.call("GET /api/v1/epr/annual-return/export?format=pdf", jwtAuth, nil, nil, nil)
+
+	// Templates
+	var template struct {
+		Template struct {
+			ID string `json:"id"`
+		} `json:"template"`
+	}
+	c.call("POST /api/v1/templates", jwtAuth, nil, map[string]interface{}{
+		"tenant_id": tenant, "name": "Contract " + suffix, "specs": specs,
+	}, &template)
+	c.call("GET /api/v1/templates?tenant_id="+tenant, jwtAuth, nil, nil, nil)
+	c.call("GET /api/v1/templates/{id}", jwtAuth, map[string]string{"id": template.Template.ID}, nil, nil)
+	c.call("DELETE /api/v1/templates/{id}", jwtAuth, map[string]string{"id": template.Template.ID}, nil, nil)
+
+	// API keys & external API
+	var key struct {
+		ID  string `json:"id"`
+		Key string `json:"key"`
+	}
+	c.call("POST /api/v1/api-keys", jwtAuth, nil, map[string]interface{}{
+		"name": "Contract", "rate_limit_tier": "production",
+		"scopes": []string{"passports:read", "passports:transition", "batches:write", "labels:read"},
+	}, &key)
+	c.apiKey = key.Key
+	apiKey := map[string]string{"id": key.ID}
+	c.call("GET /api/v1/api-keys", jwtAuth, nil, nil, nil)
+	c.call("GET /api/v1/api-keys/{id}", jwtAuth, apiKey, nil, nil)
+	c.call("PATCH /api/v1/api-keys/{id}", jwtAuth, apiKey, map[string]string{"name": "Contract (renamed)"}, nil)
+
+	var extBatch struct {
+		ID string `json:"id"`
+	}
+	c.call("POST /api/v1/external/batches", keyAuth, nil, map[string]interface{}{
+		"batch_name": "External " + suffix, "market_region": "GLOBAL", "specs": specs,
+	}, &extBatch)
+	ext := map[string]string{"id": extBatch.ID}
+	c.call("POST /api/v1/external/batches/{id}/passports", keyAuth, ext, map[string]interface{}{
+		"passports": []map[string]string{{"serial_number": "EXT" + suffix + "-1", "manufacture_date": "2025-01-15"}},
+	}, nil)
+	c.call("GET /api/v1/external/batches", keyAuth, nil, nil, nil)
+	c.call("GET /api/v1/external/batches/{id}", keyAuth, ext, nil, nil)
+	c.call("PATCH /api/v1/external/batches/{id}", keyAuth, ext, map[string]string{"batch_name": "External " + suffix + " v2"}, nil)
+	c.call("POST /api/v1/external/batches/{id}/activate", keyAuth, ext, nil, nil)
+	c.call("GET /api/v1/external/batches/{id}/export", keyAuth, ext, nil, nil)
+
+	var extPassports struct {
+		Passports []struct {
+			UUID string `json:"uuid"`
+		} `json:"passports"`
+	}
+	c.call("GET /api/v1/external/batches/{id}/passports", keyAuth, ext, nil, &extPassports)
+	c.call("GET /api/v1/external/passports", keyAuth, nil, nil, nil)
+	c.call("GET /api/v1/external/passports/search?q=EXT"+suffix, keyAuth, nil, nil, nil)
+	c.call("POST /api/v1/external/graphql", keyAuth, nil, map[string]string{
+		"query": `{ passports(first: 10) { nodes { uuid serialNumber batch { name } } } }`,
+	}, nil)
+	if len(extPassports.Passports) > 0 {
+		passport := map[string]string{"uuid": extPassports.Passports[0].UUID}
+		c.call("GET /api/v1/external/passports/{uuid}", keyAuth, passport, nil, nil)
+		c.call("GET /api/v1/external/passports/{uuid}/transitions", keyAuth, passport, nil, nil)
+		c.call("POST /api/v1/external/passports/{uuid}/transition", keyAuth, passport, map[string]string{"to_status": "SHIPPED"}, nil)
+		c.call("POST /api/v1/external/passports/bulk/transition", keyAuth, nil, map[string]interface{}{
+			"passport_ids": []string{passport["uuid"]}, "to_status": "IN_SERVICE",
+		}, nil)
+		c.call("GET /api/v1/external/passports/{uuid}/events", keyAuth, passport, nil, nil)
+	}
+	c.call("GET /api/v1/api-keys/{id}/usage", jwtAuth, apiKey, nil, nil)
+	c.call("GET /api/v1/api-keys/{id}/audit", jwtAuth, apiKey, nil, nil)
+
+	// Trusted partners, magic link & rewards
+	var partner struct {
+		Partner struct {
+			ID string `json:"id"`
+		} `json:"partner"`
+	}
+	c.call("POST /api/v1/partners/trusted", jwtAuth, nil, map[string]string{
+		"company_name": "Contract Recycler", "email_domain": partnerDomain, "role": "TECHNICIAN",
+	}, &partner)
+	c.call("GET /api/v1/partners/trusted", jwtAuth, nil, nil, nil)
+
+	var code struct {
+		Code struct {
+			ID string `json:"id"`
+		} `json:"code"`
+	}
+	c.call("POST /api/v1/partners/codes", jwtAuth, nil, map[string]interface{}{
+		"code": "CT-" + strings.ToUpper(suffix), "role": "TECHNICIAN", "description": "Contract test", "max_uses": 5,
+	}, &code)
+	c.call("GET /api/v1/partners/codes", jwtAuth, nil, nil, nil)
+
+	if len(passports.Passports) > 0 {
+		passport := map[string]string{"uuid": passports.Passports[0].UUID}
+		var link struct {
+			Link string `json:"link"`
+		}
+		c.call("POST /api/v1/auth/magic-link", noAuth, nil, map[string]string{
+			"passport_id": passport["uuid"], "email": "tech@" + partnerDomain, "role": "TECHNICIAN",
+		}, &link)
+		if _, token, found := strings.Cut(link.Link, "token="); found {
+			c.magicToken = strings.SplitN(token, "&", 2)[0]
+			c.call("GET /api/v1/passport/{uuid}/action-info", magicAuth, passport, nil, nil)
+			c.call("POST /api/v1/passport/{uuid}/transition", magicAuth, passport, map[string]string{"to_status": "IN_SERVICE"}, nil)
+			c.call("GET /api/v1/rewards/balance", magicAuth, nil, nil, nil)
+			c.call("GET /api/v1/rewards/history", magicAuth, nil, nil, nil)
+		}
+	}
+	c.call("GET /api/v1/rewards/leaderboard?tenant_id="+tenant, noAuth, nil, nil, nil)
+
+	// Cleanup
+	c.call("DELETE /api/v1/partners/codes/{id}", jwtAuth, map[string]string{"id": code.Code.ID}, nil, nil)
+	c.call("DELETE /api/v1/partners/trusted/{id}", jwtAuth, map[string]string{"id": partner.Partner.ID}, nil, nil)
+	c.call("DELETE /api/v1/api-keys/{id}", jwtAuth, apiKey, nil, nil)
+	c.call("POST /api/v1/batches/{id}/duplicate", jwtAuth, batch, nil, nil)
+	c.call("DELETE /api/v1/batches/{id}", jwtAuth, batch, nil, nil)
+
+	return c.report()
+}
+
+// report prints violations and the documented routes the workflow did not reach
+func (c *client) report() bool {
+	var unexercised []string
+	for _, p := range openapi.Patterns() {
+		if !c.exercised[p] {
+			unexercised = append(unexercised, p)
+		}
+	}
+
+	fmt.Printf("\nLive: %d routes exercised, %d not covered by the workflow\n", len(c.exercised), len(unexercised))
+	for _, p := range unexercised {
+		fmt.Printf("   skipped: %s\n", p)
+	}
+	for _, v := range c.violations {
+		fmt.Printf("❌ %s\n", v)
+	}
+	return len(c.violations) == 0
+}
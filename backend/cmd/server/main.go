@@ -13,9 +13,12 @@ import (
 
 	"exportready-battery/internal/config"
 	"exportready-battery/internal/db"
+	"exportready-battery/internal/graph"
 	"exportready-battery/internal/handlers"
 	"exportready-battery/internal/logger"
 	"exportready-battery/internal/middleware"
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/openapi"
 	"exportready-battery/internal/repository"
 	"exportready-battery/internal/services"
 )
@@ -35,6 +38,22 @@ func main() {
 	// Load configuration
 	cfg := config.Load()
 
+	// Configure API rate limit tiers (falls back to built-in tiers)
+	if cfg.RateLimitTiers != "" {
+		tiers, err := models.ParseRateLimitTiers(cfg.RateLimitTiers)
+		if err != nil {
+			log.Printf("⚠️ Invalid API_RATE_LIMIT_TIERS, using defaults: %v", err)
+		} else {
+			models.SetRateLimitTiers(tiers)
+		}
+	}
+
+	// Forwarding headers are only believed from these proxies
+	trustedProxies, err := middleware.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		log.Printf("⚠️ Invalid TRUSTED_PROXIES, trusting no proxy: %v", err)
+	}
+
 	// Initialize database connection
 	database, err := db.Connect(cfg.DatabaseURL)
 	if err != nil {
@@ -53,13 +72,26 @@ func main() {
 	repo := repository.New(database)
 
 	// Initialize handlers
-	h := handlers.New(database, cfg.BaseURL, "assets/GeoLite2-City.mmdb", cfg.RazorpayKeyID, cfg.RazorpayKeySecret)
+	h := handlers.New(repo, cfg.BaseURL, "assets/GeoLite2-City.mmdb", cfg.RazorpayKeyID, cfg.RazorpayKeySecret)
 	authHandler := handlers.NewAuthHandler(database, repo, authService, authEmailService)
 
 	// Initialize middleware
 	authMiddleware := middleware.NewAuth(authService)
 	apiKeyService := services.NewAPIKeyService()
-	apiKeyMiddleware := middleware.NewAPIKeyAuth(repo, apiKeyService)
+	apiUsageRecorder := services.NewAPIUsageRecorder(repo)
+	defer apiUsageRecorder.Close()
+	apiKeyMiddleware := middleware.NewAPIKeyAuth(repo, apiKeyService, newBucketStore(cfg, repo), apiUsageRecorder)
+	apiKeyMiddleware.SetTrustedProxies(trustedProxies)
+	idempotency := middleware.NewIdempotency(repo) // Only wraps external routes that change data
+
+	// Brute-force protection for unauthenticated auth endpoints
+	loginLimiter := middleware.NewRateLimiter(middleware.LoginRateLimitRule())
+	forgotPasswordLimiter := middleware.NewRateLimiter(middleware.ForgotPasswordRateLimitRule())
+	magicLinkLimiter := middleware.NewRateLimiter(middleware.MagicLinkRateLimitRule())
+	for _, limiter := range []*middleware.RateLimiter{loginLimiter, forgotPasswordLimiter, magicLinkLimiter} {
+		limiter.SetTrustedProxies(trustedProxies)
+	}
+	authHandler.SetLoginLimiter(loginLimiter)
 
 	// Initialize lifecycle service and handler
 	lifecycleService := services.NewLifecycleService(repo)
@@ -71,12 +103,53 @@ func main() {
 	// Initialize magic link handler (with reward service for scan-to-earn and email service)
 	magicLinkHandler := handlers.NewMagicLinkHandler(repo, lifecycleService, rewardService, magicLinkEmailService, cfg.JWTSecret, cfg.BaseURL)
 
+	// Initialize recall campaigns (bulk RECALLED transition, owner notices, remedies)
+	recallService := services.NewRecallService(repo, lifecycleService, magicLinkEmailService)
+	recallHandler := handlers.NewRecallHandler(recallService)
+
+	// Initialize export shipments (customs documentation pack, bulk SHIPPED transition)
+	shipmentHandler := handlers.NewShipmentHandler(services.NewShipmentService(repo, services.NewQRService(cfg.BaseURL)))
+
+	// Initialize warranty claims (magic-link submission, RMA review)
+	warrantyService := services.NewWarrantyService(repo, lifecycleService, magicLinkEmailService)
+	warrantyHandler := handlers.NewWarrantyHandler(warrantyService, repo, cfg.JWTSecret)
+	magicLinkHandler.SetWarrantyService(warrantyService)
+
+	// Initialize chain-of-custody ledger (transfers accepted by the receiving party)
+	custodyService := services.NewCustodyService(repo, magicLinkEmailService)
+	custodyHandler := handlers.NewCustodyHandler(custodyService)
+	magicLinkHandler.SetCustodyService(custodyService)
+
+	// Initialize carbon footprint calculator (tenant emission factor library)
+	carbonService := services.NewCarbonService(repo)
+	carbonHandler := handlers.NewCarbonHandler(carbonService)
+
+	// Initialize recycled content and supply-chain due diligence (audit reports for partners)
+	dueDiligenceService := services.NewDueDiligenceService(repo)
+	dueDiligenceHandler := handlers.NewDueDiligenceHandler(dueDiligenceService, cfg.JWTSecret)
+
+	// Initialize PLI DVA audits (CA attestation via one-time link, append-only log)
+	pliAuditHandler := handlers.NewPLIAuditHandler(services.NewPLIAuditService(repo, magicLinkEmailService))
+
+	// Initialize compliance readiness checks (per-market rule packs)
+	complianceHandler := handlers.NewComplianceHandler(services.NewComplianceService(repo))
+
+	// Initialize EPR annual return (BWM Rules 2022, CPCB filing exports)
+	eprHandler := handlers.NewEPRHandler(services.NewEPRService(repo))
+
 	// Initialize trusted partner handler
 	trustedPartnerHandler := handlers.NewTrustedPartnerHandler(repo)
 
 	// Initialize reward handler
 	rewardHandler := handlers.NewRewardHandler(rewardService, cfg.JWTSecret)
 
+	// Initialize live event stream (fan-out via Postgres LISTEN/NOTIFY)
+	eventHub := services.NewEventHub(repo, cfg.ListenURL)
+	eventStreamHandler := handlers.NewEventStreamHandler(eventHub)
+
+	// Initialize GraphQL read API (shared by dashboard and external routes)
+	graphHandler := graph.NewHandler(repo)
+
 	// Setup routes
 	mux := http.NewServeMux()
 
@@ -87,15 +160,19 @@ func main() {
 		w.Write([]byte(`{"status":"healthy","service":"exportready-battery"}`))
 	})
 
+	// OpenAPI document (public)
+	mux.HandleFunc("GET /api/v1/openapi.json", openapi.Handler)
+
 	// ============================================
 	// AUTH ROUTES (Public)
 	// ============================================
 	mux.HandleFunc("POST /api/v1/auth/register", authHandler.Register)
-	mux.HandleFunc("POST /api/v1/auth/login", authHandler.Login)
+	mux.Handle("POST /api/v1/auth/login", loginLimiter.Limit(http.HandlerFunc(authHandler.Login)))
 	mux.HandleFunc("POST /api/v1/auth/refresh", authHandler.Refresh)
-	mux.HandleFunc("POST /api/v1/auth/forgot-password", authHandler.ForgotPassword)
+	mux.Handle("POST /api/v1/auth/forgot-password", forgotPasswordLimiter.Limit(http.HandlerFunc(authHandler.ForgotPassword)))
 	mux.HandleFunc("POST /api/v1/auth/reset-password", authHandler.ResetPassword)
-	mux.HandleFunc("POST /api/v1/auth/magic-link", magicLinkHandler.RequestMagicLink)
+	mux.HandleFunc("POST /api/v1/auth/unlock", authHandler.UnlockAccount)
+	mux.Handle("POST /api/v1/auth/magic-link", magicLinkLimiter.Limit(http.HandlerFunc(magicLinkHandler.RequestMagicLink)))
 
 	// ============================================
 	// AUTH ROUTES (Protected)
@@ -118,6 +195,62 @@ func main() {
 	mux.Handle("GET /api/v1/batches/{id}/passports", authMiddleware.Protect(http.HandlerFunc(h.GetBatchPassports)))
 	mux.Handle("DELETE /api/v1/batches/{id}", authMiddleware.Protect(http.HandlerFunc(h.DeleteBatch)))
 
+	// ============================================
+	// CARBON FOOTPRINT (Protected)
+	// ============================================
+	mux.Handle("POST /api/v1/emission-factors", authMiddleware.Protect(http.HandlerFunc(carbonHandler.ImportFactors)))
+	mux.Handle("GET /api/v1/emission-factors", authMiddleware.Protect(http.HandlerFunc(carbonHandler.ListFactors)))
+	mux.Handle("DELETE /api/v1/emission-factors/{id}", authMiddleware.Protect(http.HandlerFunc(carbonHandler.DeleteFactor)))
+	mux.Handle("POST /api/v1/batches/{id}/carbon-footprint", authMiddleware.Protect(http.HandlerFunc(carbonHandler.CalculateCarbonFootprint)))
+	mux.Handle("GET /api/v1/batches/{id}/carbon-footprint", authMiddleware.Protect(http.HandlerFunc(carbonHandler.GetCarbonFootprint)))
+
+	// ============================================
+	// RECYCLED CONTENT & DUE DILIGENCE (Protected)
+	// ============================================
+	mux.Handle("GET /api/v1/batches/{id}/due-diligence", authMiddleware.Protect(http.HandlerFunc(dueDiligenceHandler.GetDueDiligence)))
+	mux.Handle("PUT /api/v1/batches/{id}/due-diligence", authMiddleware.Protect(http.HandlerFunc(dueDiligenceHandler.UpdateDueDiligence)))
+	mux.Handle("POST /api/v1/batches/{id}/due-diligence/documents", authMiddleware.Protect(http.HandlerFunc(dueDiligenceHandler.UploadAuditReport)))
+	mux.Handle("GET /api/v1/batches/{id}/due-diligence/documents/{doc}", authMiddleware.Protect(http.HandlerFunc(dueDiligenceHandler.GetAuditReport)))
+	mux.Handle("DELETE /api/v1/batches/{id}/due-diligence/documents/{doc}", authMiddleware.Protect(http.HandlerFunc(dueDiligenceHandler.DeleteAuditReport)))
+
+	// ============================================
+	// PLI DVA AUDITS (Protected)
+	// ============================================
+	mux.Handle("POST /api/v1/batches/{id}/pli-audit", authMiddleware.Protect(http.HandlerFunc(pliAuditHandler.RequestAudit)))
+	mux.Handle("GET /api/v1/batches/{id}/pli-audit", authMiddleware.Protect(http.HandlerFunc(pliAuditHandler.GetAudits)))
+	mux.Handle("POST /api/v1/batches/{id}/pli-audit/{audit}/cancel", authMiddleware.Protect(http.HandlerFunc(pliAuditHandler.CancelAudit)))
+	mux.Handle("GET /api/v1/batches/{id}/pli-audit/certificate", authMiddleware.Protect(http.HandlerFunc(pliAuditHandler.GetCertificate)))
+
+	// ============================================
+	// COMPLIANCE READINESS (Protected)
+	// ============================================
+	mux.Handle("GET /api/v1/batches/{id}/compliance", authMiddleware.Protect(http.HandlerFunc(complianceHandler.GetCompliance)))
+	mux.Handle("GET /api/v1/markets", authMiddleware.Protect(http.HandlerFunc(complianceHandler.ListMarketRules)))
+	mux.Handle("GET /api/v1/battery-categories", authMiddleware.Protect(http.HandlerFunc(complianceHandler.ListBatteryCategories)))
+
+	// ============================================
+	// EPR ANNUAL RETURN (Protected)
+	// ============================================
+	mux.Handle("GET /api/v1/epr/targets", authMiddleware.Protect(http.HandlerFunc(eprHandler.GetTargets)))
+	mux.Handle("PUT /api/v1/epr/targets", authMiddleware.Protect(http.HandlerFunc(eprHandler.UpdateTargets)))
+	mux.Handle("GET /api/v1/epr/annual-return", authMiddleware.Protect(http.HandlerFunc(eprHandler.GetAnnualReturn)))
+	mux.Handle("GET /api/v1/epr/annual-return/export", authMiddleware.Protect(http.HandlerFunc(eprHandler.ExportAnnualReturn)))
+
+	// ============================================
+	// PASSPORT SEARCH (Protected)
+	// ============================================
+	mux.Handle("GET /api/v1/passports/search", authMiddleware.Protect(http.HandlerFunc(h.SearchPassports)))
+
+	// ============================================
+	// LIVE EVENTS (Protected, Server-Sent Events)
+	// ============================================
+	mux.Handle("GET /api/v1/events/stream", authMiddleware.Protect(http.HandlerFunc(eventStreamHandler.Stream)))
+
+	// ============================================
+	// GRAPHQL (Protected)
+	// ============================================
+	mux.Handle("POST /api/v1/graphql", authMiddleware.Protect(graphHandler))
+
 	// ============================================
 	// BULK OPERATIONS (Protected)
 	// ============================================
@@ -132,6 +265,43 @@ func main() {
 	mux.Handle("GET /api/v1/passports/{uuid}/transitions", authMiddleware.Protect(http.HandlerFunc(lifecycleHandler.GetAllowedTransitions)))
 	mux.Handle("GET /api/v1/passports/{uuid}/events", authMiddleware.Protect(http.HandlerFunc(lifecycleHandler.GetPassportEvents)))
 
+	// ============================================
+	// RECALL CAMPAIGNS (Protected)
+	// ============================================
+	mux.Handle("POST /api/v1/recalls/preview", authMiddleware.Protect(http.HandlerFunc(recallHandler.PreviewRecall)))
+	mux.Handle("POST /api/v1/recalls", authMiddleware.Protect(http.HandlerFunc(recallHandler.CreateRecall)))
+	mux.Handle("GET /api/v1/recalls", authMiddleware.Protect(http.HandlerFunc(recallHandler.ListRecalls)))
+	mux.Handle("GET /api/v1/recalls/{id}", authMiddleware.Protect(http.HandlerFunc(recallHandler.GetRecall)))
+	mux.Handle("GET /api/v1/recalls/{id}/units", authMiddleware.Protect(http.HandlerFunc(recallHandler.ListRecallUnits)))
+	mux.Handle("POST /api/v1/recalls/{id}/remedies", authMiddleware.Protect(http.HandlerFunc(recallHandler.UpdateRecallRemedies)))
+	mux.Handle("POST /api/v1/recalls/{id}/notify", authMiddleware.Protect(http.HandlerFunc(recallHandler.NotifyRecallOwners)))
+	mux.Handle("POST /api/v1/recalls/{id}/close", authMiddleware.Protect(http.HandlerFunc(recallHandler.CloseRecall)))
+
+	// ============================================
+	// EXPORT SHIPMENTS (Protected)
+	// ============================================
+	mux.Handle("POST /api/v1/shipments", authMiddleware.Protect(http.HandlerFunc(shipmentHandler.CreateShipment)))
+	mux.Handle("GET /api/v1/shipments", authMiddleware.Protect(http.HandlerFunc(shipmentHandler.ListShipments)))
+	mux.Handle("GET /api/v1/shipments/{id}", authMiddleware.Protect(http.HandlerFunc(shipmentHandler.GetShipment)))
+	mux.Handle("GET /api/v1/shipments/{id}/documents", authMiddleware.Protect(http.HandlerFunc(shipmentHandler.GetShipmentDocuments)))
+	mux.Handle("POST /api/v1/shipments/{id}/ship", authMiddleware.Protect(http.HandlerFunc(shipmentHandler.ShipShipment)))
+	mux.Handle("POST /api/v1/shipments/{id}/cancel", authMiddleware.Protect(http.HandlerFunc(shipmentHandler.CancelShipment)))
+
+	// Chain of custody
+	mux.Handle("POST /api/v1/passports/{uuid}/custody/transfers", authMiddleware.Protect(http.HandlerFunc(custodyHandler.TransferPassport)))
+	mux.Handle("GET /api/v1/passports/{uuid}/custody", authMiddleware.Protect(http.HandlerFunc(custodyHandler.GetPassportCustody)))
+	mux.Handle("POST /api/v1/custody/transfers", authMiddleware.Protect(http.HandlerFunc(custodyHandler.BulkTransfer)))
+	mux.Handle("GET /api/v1/custody/transfers", authMiddleware.Protect(http.HandlerFunc(custodyHandler.ListTransfers)))
+	mux.Handle("POST /api/v1/custody/transfers/{id}/cancel", authMiddleware.Protect(http.HandlerFunc(custodyHandler.CancelTransfer)))
+
+	// Warranty claims
+	mux.Handle("GET /api/v1/warranty-claims", authMiddleware.Protect(http.HandlerFunc(warrantyHandler.ListClaims)))
+	mux.Handle("GET /api/v1/warranty-claims/{id}", authMiddleware.Protect(http.HandlerFunc(warrantyHandler.GetClaim)))
+	mux.Handle("GET /api/v1/warranty-claims/{id}/photos/{name}", authMiddleware.Protect(http.HandlerFunc(warrantyHandler.GetClaimPhoto)))
+	mux.Handle("POST /api/v1/warranty-claims/{id}/approve", authMiddleware.Protect(http.HandlerFunc(warrantyHandler.ApproveClaim)))
+	mux.Handle("POST /api/v1/warranty-claims/{id}/reject", authMiddleware.Protect(http.HandlerFunc(warrantyHandler.RejectClaim)))
+	mux.Handle("POST /api/v1/warranty-claims/{id}/receive", authMiddleware.Protect(http.HandlerFunc(warrantyHandler.ReceiveClaim)))
+
 	// ============================================
 	// TEMPLATE ROUTES (Protected)
 	// ============================================
@@ -174,9 +344,9 @@ func main() {
 	mux.Handle("POST /api/v1/settings/upload-logo", authMiddleware.Protect(http.HandlerFunc(h.UploadLogo)))
 
 	// ============================================
-	// STATIC UPLOADS (Public - for serving logos)
+	// STATIC UPLOADS (Public - logos only, no listings)
 	// ============================================
-	uploadsFS := http.FileServer(http.Dir("./uploads"))
+	uploadsFS := http.FileServer(handlers.LogoFS{Root: http.Dir("./uploads")})
 	mux.Handle("GET /api/v1/uploads/", http.StripPrefix("/api/v1/uploads/", uploadsFS))
 
 	// ============================================
@@ -199,6 +369,21 @@ func main() {
 	// ============================================
 	mux.HandleFunc("POST /api/v1/passport/{uuid}/transition", magicLinkHandler.TransitionWithMagicLink)
 	mux.HandleFunc("GET /api/v1/passport/{uuid}/action-info", magicLinkHandler.GetPassportForAction)
+	mux.HandleFunc("POST /api/v1/passport/{uuid}/warranty-claim", warrantyHandler.SubmitClaim)
+	mux.HandleFunc("GET /api/v1/passport/{uuid}/due-diligence", dueDiligenceHandler.GetPassportDueDiligence)
+	mux.HandleFunc("GET /api/v1/passport/{uuid}/due-diligence/documents/{doc}", dueDiligenceHandler.GetPassportAuditReport)
+
+	// ============================================
+	// CUSTODY ACCEPTANCE (Token Authenticated)
+	// ============================================
+	mux.HandleFunc("GET /api/v1/custody/accept", custodyHandler.GetAcceptance)
+	mux.HandleFunc("POST /api/v1/custody/accept", custodyHandler.RespondToTransfer)
+
+	// ============================================
+	// PLI DVA ATTESTATION (Token Authenticated)
+	// ============================================
+	mux.HandleFunc("GET /api/v1/pli-audit/attest", pliAuditHandler.GetAttestation)
+	mux.HandleFunc("POST /api/v1/pli-audit/attest", pliAuditHandler.Attest)
 
 	// ============================================
 	// API KEY MANAGEMENT (Protected)
@@ -206,6 +391,8 @@ func main() {
 	mux.Handle("POST /api/v1/api-keys", authMiddleware.Protect(http.HandlerFunc(h.CreateAPIKey)))
 	mux.Handle("GET /api/v1/api-keys", authMiddleware.Protect(http.HandlerFunc(h.ListAPIKeys)))
 	mux.Handle("GET /api/v1/api-keys/{id}", authMiddleware.Protect(http.HandlerFunc(h.GetAPIKey)))
+	mux.Handle("GET /api/v1/api-keys/{id}/usage", authMiddleware.Protect(http.HandlerFunc(h.GetAPIKeyUsage)))
+	mux.Handle("GET /api/v1/api-keys/{id}/audit", authMiddleware.Protect(http.HandlerFunc(h.GetAPIKeyAudit)))
 	mux.Handle("PATCH /api/v1/api-keys/{id}", authMiddleware.Protect(http.HandlerFunc(h.UpdateAPIKey)))
 	mux.Handle("DELETE /api/v1/api-keys/{id}", authMiddleware.Protect(http.HandlerFunc(h.DeleteAPIKey)))
 
@@ -229,10 +416,23 @@ func main() {
 	// ============================================
 	// EXTERNAL API (API Key Authenticated - ERP Integration)
 	// ============================================
-	mux.Handle("GET /api/v1/external/passports/{uuid}", apiKeyMiddleware.Authenticate(http.HandlerFunc(h.ExternalGetPassport)))
-	mux.Handle("POST /api/v1/external/batches", apiKeyMiddleware.AuthenticateWrite(http.HandlerFunc(h.ExternalCreateBatch)))
-	mux.Handle("POST /api/v1/external/batches/{id}/passports", apiKeyMiddleware.AuthenticateWrite(http.HandlerFunc(h.ExternalCreatePassports)))
-	mux.Handle("GET /api/v1/external/batches/{id}/labels", apiKeyMiddleware.Authenticate(http.HandlerFunc(h.ExternalDownloadLabels)))
+	mux.Handle("GET /api/v1/external/passports", apiKeyMiddleware.RequireScopes(models.ScopePassportsRead)(http.HandlerFunc(h.ExternalListPassports)))
+	mux.Handle("GET /api/v1/external/passports/search", apiKeyMiddleware.RequireScopes(models.ScopePassportsRead)(http.HandlerFunc(h.ExternalSearchPassports)))
+	mux.Handle("GET /api/v1/external/passports/{uuid}", apiKeyMiddleware.RequireScopes(models.ScopePassportsRead)(http.HandlerFunc(h.ExternalGetPassport)))
+	mux.Handle("GET /api/v1/external/passports/{uuid}/events", apiKeyMiddleware.RequireScopes(models.ScopePassportsRead)(http.HandlerFunc(h.ExternalGetPassportEvents)))
+	mux.Handle("GET /api/v1/external/passports/{uuid}/transitions", apiKeyMiddleware.RequireScopes(models.ScopePassportsRead)(http.HandlerFunc(h.ExternalGetAllowedTransitions)))
+	mux.Handle("POST /api/v1/external/passports/{uuid}/transition", apiKeyMiddleware.RequireScopes(models.ScopePassportsTransition)(idempotency.Wrap(http.HandlerFunc(h.ExternalTransitionPassport))))
+	mux.Handle("POST /api/v1/external/passports/bulk/transition", apiKeyMiddleware.RequireScopes(models.ScopePassportsTransition)(idempotency.Wrap(http.HandlerFunc(h.ExternalBulkTransitionPassports))))
+	mux.Handle("GET /api/v1/external/batches", apiKeyMiddleware.RequireScopes(models.ScopePassportsRead)(http.HandlerFunc(h.ExternalListBatches)))
+	mux.Handle("POST /api/v1/external/batches", apiKeyMiddleware.RequireScopes(models.ScopeBatchesWrite)(idempotency.Wrap(http.HandlerFunc(h.ExternalCreateBatch))))
+	mux.Handle("GET /api/v1/external/batches/{id}", apiKeyMiddleware.RequireScopes(models.ScopePassportsRead)(http.HandlerFunc(h.ExternalGetBatch)))
+	mux.Handle("PATCH /api/v1/external/batches/{id}", apiKeyMiddleware.RequireScopes(models.ScopeBatchesWrite)(http.HandlerFunc(h.ExternalUpdateBatch)))
+	mux.Handle("POST /api/v1/external/batches/{id}/activate", apiKeyMiddleware.RequireScopes(models.ScopeBatchesWrite)(idempotency.Wrap(http.HandlerFunc(h.ExternalActivateBatch))))
+	mux.Handle("GET /api/v1/external/batches/{id}/passports", apiKeyMiddleware.RequireScopes(models.ScopePassportsRead)(http.HandlerFunc(h.ExternalListPassports)))
+	mux.Handle("POST /api/v1/external/batches/{id}/passports", apiKeyMiddleware.RequireScopes(models.ScopeBatchesWrite)(idempotency.Wrap(http.HandlerFunc(h.ExternalCreatePassports))))
+	mux.Handle("GET /api/v1/external/batches/{id}/export", apiKeyMiddleware.RequireScopes(models.ScopePassportsRead)(http.HandlerFunc(h.ExternalExportBatchCSV)))
+	mux.Handle("GET /api/v1/external/batches/{id}/labels", apiKeyMiddleware.RequireScopes(models.ScopeLabelsRead)(http.HandlerFunc(h.ExternalDownloadLabels)))
+	mux.Handle("POST /api/v1/external/graphql", apiKeyMiddleware.RequireScopes(models.ScopePassportsRead)(graphHandler))
 
 	// Create HTTP server
 	server := &http.Server{
@@ -243,6 +443,9 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// End open event streams so Shutdown does not wait on them
+	server.RegisterOnShutdown(eventHub.Close)
+
 	// Graceful shutdown channel
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
@@ -279,12 +482,23 @@ func loggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// newBucketStore selects the token bucket store for API key rate limiting
+func newBucketStore(cfg *config.Config, repo *repository.Repository) middleware.BucketStore {
+	if cfg.RateLimitStore == "postgres" {
+		log.Println("✅ API rate limiting: shared Postgres token buckets")
+		return middleware.NewPostgresBucketStore(repo)
+	}
+	log.Println("✅ API rate limiting: in-process token buckets")
+	return middleware.NewMemoryBucketStore()
+}
+
 // corsMiddleware adds CORS headers for Next.js frontend
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, Idempotency-Key, Last-Event-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After, Idempotent-Replayed")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -30,6 +30,12 @@ func IsValidActorRole(role string) bool {
 	return false
 }
 
+// HasLegitimateInterest reports whether the role may see restricted passport
+// data such as supplier names and audit reports. Customers see the public passport.
+func HasLegitimateInterest(role string) bool {
+	return IsValidActorRole(role) && ActorRole(role) != ActorRoleCustomer
+}
+
 // MagicLinkClaims contains the JWT claims for magic link tokens
 type MagicLinkClaims struct {
 	PassportID string `json:"passport_id"`
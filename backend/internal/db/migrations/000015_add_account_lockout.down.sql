@@ -0,0 +1,9 @@
+-- Rollback account lockout columns
+
+-- 1. Drop index
+DROP INDEX IF EXISTS idx_tenants_unlock_token;
+
+-- 2. Drop columns
+ALTER TABLE public.tenants DROP COLUMN IF EXISTS last_locked_at;
+ALTER TABLE public.tenants DROP COLUMN IF EXISTS unlock_token_expires;
+ALTER TABLE public.tenants DROP COLUMN IF EXISTS unlock_token;
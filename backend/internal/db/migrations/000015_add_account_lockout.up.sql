@@ -0,0 +1,14 @@
+-- ============================================================================
+-- ACCOUNT LOCKOUT
+-- Unlock tokens emailed when repeated failed logins lock an account
+-- ============================================================================
+
+ALTER TABLE public.tenants ADD COLUMN IF NOT EXISTS unlock_token VARCHAR(255);
+ALTER TABLE public.tenants ADD COLUMN IF NOT EXISTS unlock_token_expires TIMESTAMPTZ;
+ALTER TABLE public.tenants ADD COLUMN IF NOT EXISTS last_locked_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_tenants_unlock_token
+    ON public.tenants(unlock_token) WHERE unlock_token IS NOT NULL;
+
+COMMENT ON COLUMN public.tenants.unlock_token IS 'One-time token to lift a brute-force lockout early';
+COMMENT ON COLUMN public.tenants.last_locked_at IS 'When the account was last locked for too many failed logins';
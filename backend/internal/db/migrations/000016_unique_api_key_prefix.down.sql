@@ -0,0 +1,4 @@
+-- Rollback unique API key prefix
+-- Note: key_prefix is not shrunk back to VARCHAR(20) because new-format prefixes would not fit
+
+DROP INDEX IF EXISTS idx_api_keys_key_prefix_unique;
@@ -0,0 +1,17 @@
+-- ============================================================================
+-- O(1) API KEY LOOKUP
+-- New keys store 12 hex chars of the secret in key_prefix (er_sk_live_xxxxxxxxxxxx****)
+-- so validation can fetch exactly one row instead of scanning every active key.
+-- Legacy keys keep their 4-char prefix (19 chars) and fall back to a prefix scan.
+-- ============================================================================
+
+ALTER TABLE api_keys ALTER COLUMN key_prefix TYPE VARCHAR(32);
+
+-- Unique lookup for new-format prefixes (legacy prefixes may collide)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_prefix_unique
+    ON api_keys(key_prefix) WHERE length(key_prefix) > 19;
+
+-- Legacy fallback lookup
+CREATE INDEX IF NOT EXISTS idx_api_keys_prefix ON api_keys(key_prefix);
+
+COMMENT ON COLUMN api_keys.key_prefix IS 'Display/lookup prefix; unique for new-format keys';
@@ -0,0 +1,7 @@
+-- Rollback fine-grained API key scopes
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS actor_role;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS allowed_cidrs;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS allowed_markets;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS allowed_batch_ids;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS scopes;
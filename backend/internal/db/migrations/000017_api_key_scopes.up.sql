@@ -0,0 +1,22 @@
+-- ============================================================================
+-- FINE-GRAINED API KEY SCOPES AND RESOURCE RESTRICTIONS
+-- ============================================================================
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS scopes TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS allowed_batch_ids UUID[] NOT NULL DEFAULT '{}';
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS allowed_markets TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS allowed_cidrs TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS actor_role VARCHAR(20);
+
+-- Backfill scopes from the legacy read/write scope
+UPDATE api_keys SET scopes = ARRAY['passports:read', 'labels:read', 'batches:write']
+WHERE scope = 'write' AND scopes = '{}';
+
+UPDATE api_keys SET scopes = ARRAY['passports:read', 'labels:read']
+WHERE scope <> 'write' AND scopes = '{}';
+
+COMMENT ON COLUMN api_keys.scopes IS 'Granted scopes: passports:read, passports:transition, batches:write, labels:read, telemetry:write';
+COMMENT ON COLUMN api_keys.allowed_batch_ids IS 'If non-empty, key may only access these batches';
+COMMENT ON COLUMN api_keys.allowed_markets IS 'If non-empty, key may only access batches in these market regions';
+COMMENT ON COLUMN api_keys.allowed_cidrs IS 'If non-empty, requests must originate from these CIDR ranges';
+COMMENT ON COLUMN api_keys.actor_role IS 'Lifecycle role the key acts as for passport transitions';
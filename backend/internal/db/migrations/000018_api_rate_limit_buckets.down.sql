@@ -0,0 +1,15 @@
+-- Rollback shared token buckets
+
+DROP INDEX IF EXISTS idx_api_rate_limit_buckets_updated;
+DROP TABLE IF EXISTS api_rate_limit_buckets;
+
+CREATE TABLE IF NOT EXISTS api_key_usage (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    api_key_id UUID NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    endpoint VARCHAR(100) NOT NULL,
+    request_count INT DEFAULT 0,
+    window_start TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(api_key_id, endpoint, window_start)
+);
+CREATE INDEX IF NOT EXISTS idx_api_key_usage_key ON api_key_usage(api_key_id);
+CREATE INDEX IF NOT EXISTS idx_api_key_usage_window ON api_key_usage(window_start);
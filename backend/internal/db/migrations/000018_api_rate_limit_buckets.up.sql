@@ -0,0 +1,20 @@
+-- ============================================================================
+-- SHARED TOKEN BUCKETS FOR API RATE LIMITING
+-- Used when API_RATE_LIMIT_STORE=postgres so all instances share one budget
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS api_rate_limit_buckets (
+    bucket_key VARCHAR(100) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    last_allowed BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- For pruning idle buckets
+CREATE INDEX IF NOT EXISTS idx_api_rate_limit_buckets_updated
+    ON api_rate_limit_buckets(updated_at);
+
+COMMENT ON TABLE api_rate_limit_buckets IS 'Token buckets per API key and operation type';
+
+-- The buckets replace the hourly per-endpoint counters, which nothing reads
+DROP TABLE IF EXISTS api_key_usage;
@@ -0,0 +1,13 @@
+-- Rollback API key request logs & audit
+
+-- 1. Drop alerts
+DROP INDEX IF EXISTS idx_api_key_alerts_key_created;
+DROP TABLE IF EXISTS api_key_alerts;
+
+-- 2. Drop known IPs
+DROP TABLE IF EXISTS api_key_ips;
+
+-- 3. Drop request logs
+DROP INDEX IF EXISTS idx_api_key_request_logs_created;
+DROP INDEX IF EXISTS idx_api_key_request_logs_key_created;
+DROP TABLE IF EXISTS api_key_request_logs;
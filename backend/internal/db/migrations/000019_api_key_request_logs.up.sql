@@ -0,0 +1,53 @@
+-- ============================================================================
+-- API KEY REQUEST LOGS & AUDIT
+-- Per-request log of external API traffic, known client IPs and new-IP alerts
+-- ============================================================================
+
+-- 1. Request log (one row per authenticated API key request)
+CREATE TABLE IF NOT EXISTS api_key_request_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    api_key_id UUID NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    endpoint VARCHAR(200) NOT NULL,   -- Route pattern, e.g. GET /api/v1/external/passports/{uuid}
+    path VARCHAR(500) NOT NULL,       -- Actual request path
+    status_code INT NOT NULL,
+    latency_ms INT NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_key_request_logs_key_created
+    ON api_key_request_logs(api_key_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_api_key_request_logs_created
+    ON api_key_request_logs(created_at);
+
+-- 2. Known client IPs per key
+CREATE TABLE IF NOT EXISTS api_key_ips (
+    api_key_id UUID NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    ip_address VARCHAR(45) NOT NULL,
+    first_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    request_count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (api_key_id, ip_address)
+);
+
+-- 3. Security alerts (e.g. key used from a new IP)
+CREATE TABLE IF NOT EXISTS api_key_alerts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    api_key_id UUID NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL,
+    alert_type VARCHAR(30) NOT NULL, -- NEW_IP
+    ip_address VARCHAR(45),
+    message TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_key_alerts_key_created
+    ON api_key_alerts(api_key_id, created_at DESC);
+
+COMMENT ON TABLE api_key_request_logs IS 'Audit log of API key requests (retained 90 days)';
+COMMENT ON TABLE api_key_ips IS 'Client IPs seen per API key, for new-IP alerts';
+COMMENT ON TABLE api_key_alerts IS 'Security alerts raised for API keys';
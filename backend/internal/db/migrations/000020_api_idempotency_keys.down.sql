@@ -0,0 +1,4 @@
+-- Rollback idempotency keys
+
+DROP INDEX IF EXISTS idx_api_idempotency_keys_expires;
+DROP TABLE IF EXISTS api_idempotency_keys;
@@ -0,0 +1,23 @@
+-- ============================================================================
+-- IDEMPOTENCY KEYS FOR EXTERNAL API
+-- Stored responses for POST requests sent with an Idempotency-Key header, so
+-- client retries replay the original result instead of repeating the write
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS api_idempotency_keys (
+    tenant_id UUID NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    fingerprint CHAR(64) NOT NULL,    -- SHA-256 of method, path and normalized body
+    status_code INT,                  -- NULL while the original request is in flight
+    content_type VARCHAR(100),
+    response_body BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (tenant_id, idempotency_key)
+);
+
+-- For pruning expired keys
+CREATE INDEX IF NOT EXISTS idx_api_idempotency_keys_expires
+    ON api_idempotency_keys(expires_at);
+
+COMMENT ON TABLE api_idempotency_keys IS 'Replayable responses per tenant and Idempotency-Key (kept 24 hours)';
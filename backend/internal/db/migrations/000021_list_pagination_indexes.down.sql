@@ -0,0 +1,9 @@
+-- Rollback list pagination indexes
+
+DROP INDEX IF EXISTS idx_batches_tenant_created_id;
+DROP INDEX IF EXISTS idx_batches_tenant_chemistry;
+DROP INDEX IF EXISTS idx_passports_batch_serial_uuid;
+DROP INDEX IF EXISTS idx_passports_created_uuid;
+DROP INDEX IF EXISTS idx_passports_serial_pattern;
+DROP INDEX IF EXISTS idx_scan_events_scanned_id;
+DROP INDEX IF EXISTS idx_transactions_tenant_created_id;
@@ -0,0 +1,30 @@
+-- Composite indexes for keyset pagination and list filters
+-- Each list orders by (sort column, id) so the cursor condition is an index range scan
+
+-- Batches newest first per tenant
+CREATE INDEX IF NOT EXISTS idx_batches_tenant_created_id
+    ON public.batches(tenant_id, created_at DESC, id DESC) WHERE deleted_at IS NULL;
+
+-- Batch chemistry filter (specs->>'chemistry', case-insensitive)
+CREATE INDEX IF NOT EXISTS idx_batches_tenant_chemistry
+    ON public.batches(tenant_id, lower(specs->>'chemistry')) WHERE deleted_at IS NULL;
+
+-- Passports of a batch in serial order
+CREATE INDEX IF NOT EXISTS idx_passports_batch_serial_uuid
+    ON public.passports(batch_id, serial_number, uuid);
+
+-- Passports newest first across batches
+CREATE INDEX IF NOT EXISTS idx_passports_created_uuid
+    ON public.passports(created_at DESC, uuid DESC);
+
+-- Serial prefix filter (LIKE 'prefix%')
+CREATE INDEX IF NOT EXISTS idx_passports_serial_pattern
+    ON public.passports(serial_number text_pattern_ops);
+
+-- Scan feed newest first
+CREATE INDEX IF NOT EXISTS idx_scan_events_scanned_id
+    ON public.scan_events(scanned_at DESC, id DESC);
+
+-- Transactions newest first per tenant
+CREATE INDEX IF NOT EXISTS idx_transactions_tenant_created_id
+    ON public.transactions(tenant_id, created_at DESC, id DESC);
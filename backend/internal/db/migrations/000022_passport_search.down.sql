@@ -0,0 +1,13 @@
+-- Rollback passport search
+-- pg_trgm is left installed; other objects may depend on it
+
+DROP INDEX IF EXISTS idx_passports_search_vector;
+DROP INDEX IF EXISTS idx_batches_search_vector;
+DROP INDEX IF EXISTS idx_passports_serial_trgm;
+DROP INDEX IF EXISTS idx_passports_owner_email_trgm;
+DROP INDEX IF EXISTS idx_batches_name_trgm;
+DROP INDEX IF EXISTS idx_batches_bill_of_entry_trgm;
+DROP INDEX IF EXISTS idx_batches_hsn_code_trgm;
+
+ALTER TABLE public.passports DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE public.batches DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,50 @@
+-- ============================================================================
+-- PASSPORT SEARCH
+-- Full-text vectors for whole-word matches and trigram indexes for partial
+-- identifiers (serial/BPAN fragments, Bill of Entry numbers, emails)
+-- ============================================================================
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Current owner, kept up to date by the custody ledger (000026)
+ALTER TABLE public.passports ADD COLUMN IF NOT EXISTS current_owner_email VARCHAR(255);
+
+-- Earlier versions indexed current_custody_email, which nothing writes
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = 'public' AND table_name = 'passports' AND column_name = 'search_vector'
+                 AND generation_expression LIKE '%current_custody_email%') THEN
+        ALTER TABLE public.passports DROP COLUMN search_vector;
+    END IF;
+END $$;
+DROP INDEX IF EXISTS idx_passports_custody_email_trgm;
+
+-- 'simple' config: identifiers and emails must not be stemmed or stop-worded
+ALTER TABLE public.passports
+ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple', COALESCE(serial_number, '') || ' ' || COALESCE(current_owner_email, ''))
+) STORED;
+
+ALTER TABLE public.batches
+ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple',
+        COALESCE(batch_name, '') || ' ' ||
+        COALESCE(bill_of_entry_no, '') || ' ' ||
+        COALESCE(hsn_code, '') || ' ' ||
+        COALESCE(country_of_origin, ''))
+    || jsonb_to_tsvector('simple', COALESCE(specs, '{}'::jsonb), '["string", "numeric"]')
+) STORED;
+
+COMMENT ON COLUMN public.passports.search_vector IS 'Serial number and current owner email for passport search';
+COMMENT ON COLUMN public.batches.search_vector IS 'Batch name, customs fields and spec values for passport search';
+
+CREATE INDEX IF NOT EXISTS idx_passports_search_vector ON public.passports USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_batches_search_vector ON public.batches USING GIN (search_vector);
+
+-- Substring (ILIKE '%...%') and similarity matches
+CREATE INDEX IF NOT EXISTS idx_passports_serial_trgm ON public.passports USING GIN (serial_number gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_passports_owner_email_trgm ON public.passports USING GIN (current_owner_email gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_batches_name_trgm ON public.batches USING GIN (batch_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_batches_bill_of_entry_trgm ON public.batches USING GIN (bill_of_entry_no gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_batches_hsn_code_trgm ON public.batches USING GIN (hsn_code gin_trgm_ops);
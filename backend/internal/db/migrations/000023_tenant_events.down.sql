@@ -0,0 +1,3 @@
+-- Rollback tenant event stream
+
+DROP TABLE IF EXISTS tenant_events;
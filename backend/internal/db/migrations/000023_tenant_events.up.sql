@@ -0,0 +1,24 @@
+-- ============================================================================
+-- TENANT EVENT STREAM
+-- Append-only log behind the dashboard's Server-Sent Events stream. Each insert
+-- is announced with NOTIFY tenant_events so every server instance can fan it
+-- out; clients resume with Last-Event-ID by replaying rows after that id.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS tenant_events (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES public.tenants(id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL,  -- scan.recorded, passport.transitioned, batch.activated, import.progress, ...
+    payload JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Replay after Last-Event-ID
+CREATE INDEX IF NOT EXISTS idx_tenant_events_tenant_id
+    ON tenant_events(tenant_id, id);
+
+-- For pruning old events
+CREATE INDEX IF NOT EXISTS idx_tenant_events_created
+    ON tenant_events(created_at);
+
+COMMENT ON TABLE tenant_events IS 'Live event stream per tenant (kept 24 hours for Last-Event-ID replay)';
@@ -0,0 +1,5 @@
+-- Rollback recall campaigns
+-- Recalled passports keep their RECALLED status and passport_events history
+
+DROP TABLE IF EXISTS recall_units;
+DROP TABLE IF EXISTS recall_campaigns;
@@ -0,0 +1,43 @@
+-- ============================================================================
+-- RECALL CAMPAIGNS
+-- A recall covers every passport matching its scope. Creating one moves those
+-- passports to RECALLED; recall_units tracks the remedy for each unit.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS recall_campaigns (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES public.tenants(id) ON DELETE CASCADE,
+    title VARCHAR(200) NOT NULL,
+    reason TEXT NOT NULL,
+    severity VARCHAR(20) NOT NULL,          -- LOW, MEDIUM, HIGH, CRITICAL
+    regulator_reference VARCHAR(100),       -- Authority case / notification number
+    remedy_instructions TEXT,
+    scope JSONB NOT NULL,                   -- batch_ids, serial range, manufacture window, chemistry
+    status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE', -- ACTIVE, CLOSED
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    closed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_recall_campaigns_tenant_created
+    ON recall_campaigns(tenant_id, created_at DESC, id DESC);
+
+CREATE TABLE IF NOT EXISTS recall_units (
+    campaign_id UUID NOT NULL REFERENCES recall_campaigns(id) ON DELETE CASCADE,
+    passport_id UUID NOT NULL REFERENCES public.passports(uuid) ON DELETE CASCADE,
+    previous_status VARCHAR(30) NOT NULL,   -- Passport status before the recall
+    owner_email VARCHAR(255),               -- passports.current_owner_email, refreshed until notified
+    remedy_status VARCHAR(20) NOT NULL DEFAULT 'PENDING', -- PENDING, NOTIFIED, RETURNED, REPLACED, RECYCLED
+    notified_at TIMESTAMPTZ,
+    remedied_at TIMESTAMPTZ,
+    note TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (campaign_id, passport_id)
+);
+
+-- Recall banner lookup on the public passport page
+CREATE INDEX IF NOT EXISTS idx_recall_units_passport
+    ON recall_units(passport_id);
+
+COMMENT ON TABLE recall_campaigns IS 'Manufacturer recall campaigns with reason, severity, scope and regulator reference';
+COMMENT ON TABLE recall_units IS 'Passports covered by a recall campaign and their remedy status';
@@ -0,0 +1,4 @@
+-- Rollback warranty claims
+-- Passport lifecycle timestamp columns are kept (older schemas already had them)
+
+DROP TABLE IF EXISTS warranty_claims;
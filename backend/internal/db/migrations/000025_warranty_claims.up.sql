@@ -0,0 +1,46 @@
+-- ============================================================================
+-- WARRANTY CLAIMS
+-- A technician files a claim through a magic link; it moves the passport to
+-- RETURN_REQUESTED. The manufacturer approves it (issuing an RMA number) or
+-- rejects it (passport back IN_SERVICE), then marks the unit received (RETURNED).
+-- ============================================================================
+
+-- Lifecycle timestamps used for warranty eligibility (already present on most
+-- installs; older schemas only had lifecycle_timestamps JSONB)
+ALTER TABLE public.passports ADD COLUMN IF NOT EXISTS shipped_at TIMESTAMPTZ;
+ALTER TABLE public.passports ADD COLUMN IF NOT EXISTS installed_at TIMESTAMPTZ;
+ALTER TABLE public.passports ADD COLUMN IF NOT EXISTS returned_at TIMESTAMPTZ;
+ALTER TABLE public.passports ADD COLUMN IF NOT EXISTS state_of_health DECIMAL(5,2) DEFAULT 100.00;
+
+CREATE TABLE IF NOT EXISTS warranty_claims (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES public.tenants(id) ON DELETE CASCADE,
+    passport_id UUID NOT NULL REFERENCES public.passports(uuid) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'SUBMITTED', -- SUBMITTED, APPROVED, REJECTED, RECEIVED
+    rma_number VARCHAR(32) UNIQUE,                   -- Issued on approval
+    fault_code VARCHAR(40) NOT NULL,
+    description TEXT,
+    state_of_health DECIMAL(5,2) NOT NULL,           -- SoH reading at submission (literal %)
+    photos JSONB NOT NULL DEFAULT '[]',              -- Stored photo file names
+    previous_status VARCHAR(30) NOT NULL,            -- Passport status before the claim
+    eligible BOOLEAN NOT NULL,                       -- In warranty at submission
+    eligibility_reason VARCHAR(255),
+    warranty_expires_at TIMESTAMPTZ,
+    submitted_by VARCHAR(255) NOT NULL,
+    submitted_role VARCHAR(50) NOT NULL,
+    decided_by VARCHAR(255),
+    decided_at TIMESTAMPTZ,
+    decision_note TEXT,
+    received_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One open claim per passport
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warranty_claims_open_passport
+    ON warranty_claims(passport_id) WHERE status IN ('SUBMITTED', 'APPROVED');
+
+CREATE INDEX IF NOT EXISTS idx_warranty_claims_tenant_created
+    ON warranty_claims(tenant_id, created_at DESC, id DESC);
+
+COMMENT ON TABLE warranty_claims IS 'Warranty claims filed by field technicians, with fault code, photos, SoH reading and RMA number';
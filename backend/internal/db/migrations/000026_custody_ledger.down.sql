@@ -0,0 +1,6 @@
+-- Rollback chain of custody
+-- passports.current_owner_email keeps the last accepted owner
+
+ALTER TABLE public.passports DROP COLUMN IF EXISTS owner_id;
+DROP TABLE IF EXISTS custody_transfers;
+DROP TABLE IF EXISTS custody_parties;
@@ -0,0 +1,87 @@
+-- ============================================================================
+-- CHAIN OF CUSTODY
+-- Every hand-over of a passport is a custody transfer. A transfer starts
+-- PENDING and takes effect when the receiving party accepts it through the
+-- emailed link; transfers created together (a distributor pallet) share a
+-- group_id and one acceptance link. The current owner is the receiving party
+-- of the latest accepted transfer; passports.owner_id and current_owner_email
+-- are only written when a transfer is accepted.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS custody_parties (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES public.tenants(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,            -- Lower-cased
+    name VARCHAR(200) NOT NULL,
+    party_type VARCHAR(20) NOT NULL,        -- DISTRIBUTOR, RETAILER, INSTALLER, LOGISTICS, END_USER, RECYCLER, OTHER
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, email)
+);
+
+CREATE TABLE IF NOT EXISTS custody_transfers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES public.tenants(id) ON DELETE CASCADE,
+    group_id UUID NOT NULL,                 -- Transfers accepted together
+    passport_id UUID NOT NULL REFERENCES public.passports(uuid) ON DELETE CASCADE,
+    from_party_id UUID REFERENCES custody_parties(id), -- NULL = the manufacturer
+    to_party_id UUID NOT NULL REFERENCES custody_parties(id),
+    document_ref VARCHAR(100),              -- Invoice, delivery note or e-way bill number
+    note TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING', -- PENDING, ACCEPTED, REJECTED, CANCELLED
+    method VARCHAR(20) NOT NULL,            -- ACCEPTANCE, MAGIC_LINK, IMPORTED
+    acceptance_token_hash VARCHAR(64),      -- SHA-256 of the emailed acceptance token
+    initiated_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    responded_at TIMESTAMPTZ,
+    response_note TEXT,
+    transferred_at TIMESTAMPTZ               -- When custody changed hands (accepted)
+);
+
+-- One pending transfer per passport
+CREATE UNIQUE INDEX IF NOT EXISTS idx_custody_transfers_pending_passport
+    ON custody_transfers(passport_id) WHERE status = 'PENDING';
+
+-- Ownership timeline
+CREATE INDEX IF NOT EXISTS idx_custody_transfers_passport_time
+    ON custody_transfers(passport_id, transferred_at) WHERE status = 'ACCEPTED';
+
+CREATE INDEX IF NOT EXISTS idx_custody_transfers_token
+    ON custody_transfers(acceptance_token_hash) WHERE acceptance_token_hash IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_custody_transfers_tenant_created
+    ON custody_transfers(tenant_id, created_at DESC, id DESC);
+
+-- Current owner, kept in step with the latest accepted transfer
+ALTER TABLE public.passports ADD COLUMN IF NOT EXISTS current_owner_email VARCHAR(255);
+ALTER TABLE public.passports ADD COLUMN IF NOT EXISTS owner_id UUID REFERENCES custody_parties(id) ON DELETE SET NULL;
+
+-- Import existing current owners as the first entry of their ledger, once:
+-- passports that already have a ledger are skipped when migrations re-run
+INSERT INTO custody_parties (tenant_id, email, name, party_type)
+SELECT DISTINCT b.tenant_id, LOWER(p.current_owner_email), LOWER(p.current_owner_email), 'OTHER'
+FROM public.passports p
+JOIN public.batches b ON b.id = p.batch_id
+WHERE NULLIF(p.current_owner_email, '') IS NOT NULL
+ON CONFLICT (tenant_id, email) DO NOTHING;
+
+INSERT INTO custody_transfers
+    (tenant_id, group_id, passport_id, to_party_id, status, method, initiated_by, created_at, responded_at, transferred_at)
+SELECT b.tenant_id, gen_random_uuid(), p.uuid, cp.id, 'ACCEPTED', 'IMPORTED', 'migration',
+       COALESCE(p.installed_at, p.shipped_at, p.created_at),
+       COALESCE(p.installed_at, p.shipped_at, p.created_at),
+       COALESCE(p.installed_at, p.shipped_at, p.created_at)
+FROM public.passports p
+JOIN public.batches b ON b.id = p.batch_id
+JOIN custody_parties cp ON cp.tenant_id = b.tenant_id AND cp.email = LOWER(p.current_owner_email)
+WHERE NULLIF(p.current_owner_email, '') IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM custody_transfers t WHERE t.passport_id = p.uuid);
+
+UPDATE public.passports p
+SET owner_id = cp.id
+FROM public.batches b, custody_parties cp
+WHERE b.id = p.batch_id
+  AND cp.tenant_id = b.tenant_id AND cp.email = LOWER(p.current_owner_email)
+  AND p.owner_id IS NULL;
+
+COMMENT ON TABLE custody_parties IS 'Distributors, retailers, installers and other holders of a tenant''s batteries';
+COMMENT ON TABLE custody_transfers IS 'Chain-of-custody ledger: passport hand-overs with document reference and receiver acceptance';
@@ -0,0 +1,5 @@
+-- Rollback carbon footprint
+-- specs.carbon_footprint and specs.carbon_footprint_detail are left in place
+
+ALTER TABLE public.batches DROP COLUMN IF EXISTS carbon_report;
+DROP TABLE IF EXISTS emission_factors;
@@ -0,0 +1,27 @@
+-- ============================================================================
+-- CARBON FOOTPRINT
+-- Tenants import an emission factor library; the calculator applies it to a
+-- battery's bill of materials and energy inputs. The resulting per-kWh total,
+-- stage breakdown and performance class go into batches.specs, and the full
+-- calculation report into batches.carbon_report.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS emission_factors (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES public.tenants(id) ON DELETE CASCADE,
+    key VARCHAR(100) NOT NULL,                  -- Referenced by calculation inputs
+    name VARCHAR(255) NOT NULL,
+    unit VARCHAR(20) NOT NULL,                  -- kg, kWh, MJ, tkm...
+    kg_co2e_per_unit DECIMAL(14,6) NOT NULL,    -- Negative for recycling credits
+    stage VARCHAR(30),                          -- Default lifecycle stage
+    source VARCHAR(255),                        -- e.g. ecoinvent 3.10
+    region VARCHAR(50),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, key)
+);
+
+ALTER TABLE public.batches ADD COLUMN IF NOT EXISTS carbon_report JSONB;
+
+COMMENT ON TABLE emission_factors IS 'Per-tenant emission factor library used by the carbon footprint calculator';
+COMMENT ON COLUMN public.batches.carbon_report IS 'Last carbon footprint calculation: input lines, factors applied and stage totals';
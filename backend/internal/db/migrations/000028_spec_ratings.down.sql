@@ -0,0 +1,4 @@
+-- Rollback typed spec ratings
+
+UPDATE public.batch_templates SET specs = specs - 'ratings' WHERE specs ? 'ratings';
+UPDATE public.batches SET specs = specs - 'ratings' WHERE specs ? 'ratings';
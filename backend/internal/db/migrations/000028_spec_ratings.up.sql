@@ -0,0 +1,71 @@
+-- ============================================================================
+-- TYPED SPEC RATINGS
+-- specs.voltage, capacity and weight are free text ("3.7V", "5000mAh", "500g").
+-- New saves add specs.ratings with the values in V, Ah, kg and Wh plus rated and
+-- specific energy; this back-fills existing batches and templates the same way.
+-- Values that do not parse are skipped rather than rejected.
+-- ============================================================================
+
+-- Number in the base unit, or NULL when the text does not parse or the unit is
+-- not in units (lower-case unit -> multiplier; "" is a bare number)
+CREATE OR REPLACE FUNCTION pg_temp.spec_quantity(raw TEXT, units JSONB) RETURNS NUMERIC AS $$
+DECLARE
+    m TEXT[];
+    digits TEXT;
+BEGIN
+    m := regexp_match(COALESCE(raw, ''), '^\s*([0-9]+(?:[.,][0-9]+)?)\s*([a-zA-Z]*)\s*$');
+    IF m IS NULL OR NOT units ? lower(m[2]) THEN
+        RETURN NULL;
+    END IF;
+    IF m[1] ~ '^[0-9]+,[0-9]{3}$' THEN
+        digits := replace(m[1], ',', '');  -- Thousands separator: 1,000mAh
+    ELSE
+        digits := replace(m[1], ',', '.'); -- Decimal comma: 1,5kg
+    END IF;
+    RETURN digits::NUMERIC * (units ->> lower(m[2]))::NUMERIC;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION pg_temp.spec_ratings(specs JSONB) RETURNS JSONB AS $$
+DECLARE
+    v NUMERIC;
+    ah NUMERIC;
+    wh NUMERIC;
+    kg NUMERIC;
+BEGIN
+    v := pg_temp.spec_quantity(specs ->> 'voltage',
+        '{"": 1, "v": 1, "volt": 1, "volts": 1, "mv": 0.001, "kv": 1000}');
+    ah := pg_temp.spec_quantity(specs ->> 'capacity', '{"": 1, "ah": 1, "mah": 0.001}');
+    wh := pg_temp.spec_quantity(specs ->> 'capacity', '{"wh": 1, "kwh": 1000}');
+    kg := pg_temp.spec_quantity(specs ->> 'weight',
+        '{"": 1, "kg": 1, "kgs": 1, "kilogram": 1, "kilograms": 1, "g": 0.001, "gram": 0.001, "grams": 0.001}');
+
+    IF v <= 0 OR v > 1500 THEN v := NULL; END IF;
+    IF kg <= 0 OR kg > 50000 THEN kg := NULL; END IF;
+    IF ah <= 0 THEN ah := NULL; END IF;
+    IF wh <= 0 THEN wh := NULL; END IF;
+
+    IF ah IS NOT NULL THEN
+        wh := ah * v;
+    ELSIF wh IS NOT NULL THEN
+        ah := wh / v;
+    END IF;
+    IF wh > 5000000 THEN wh := NULL; END IF;
+
+    RETURN jsonb_strip_nulls(jsonb_build_object(
+        'nominal_voltage_v', v,
+        'capacity_ah', round(ah, 4),
+        'weight_kg', round(kg, 4),
+        'rated_energy_wh', round(wh, 2),
+        'specific_energy_wh_kg', round(wh / kg, 1)
+    ));
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+UPDATE public.batches
+SET specs = specs || jsonb_build_object('ratings', pg_temp.spec_ratings(specs))
+WHERE NOT specs ? 'ratings' AND pg_temp.spec_ratings(specs) <> '{}'::jsonb;
+
+UPDATE public.batch_templates
+SET specs = specs || jsonb_build_object('ratings', pg_temp.spec_ratings(specs))
+WHERE NOT specs ? 'ratings' AND pg_temp.spec_ratings(specs) <> '{}'::jsonb;
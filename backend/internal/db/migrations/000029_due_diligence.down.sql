@@ -0,0 +1,4 @@
+-- Rollback due diligence documents
+-- specs.recycled_content and specs.due_diligence are left in place; uploaded files are not removed
+
+DROP TABLE IF EXISTS due_diligence_documents;
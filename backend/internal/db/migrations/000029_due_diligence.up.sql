@@ -0,0 +1,26 @@
+-- ============================================================================
+-- RECYCLED CONTENT AND SUPPLY-CHAIN DUE DILIGENCE
+-- Per-material recycled shares and the supplier/smelter list live in
+-- batches.specs (recycled_content, due_diligence). Third-party audit reports
+-- are PDFs stored under ./storage/{tenant_id}/due-diligence/{batch_id}/.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS due_diligence_documents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES public.tenants(id) ON DELETE CASCADE,
+    batch_id UUID NOT NULL REFERENCES public.batches(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    auditor VARCHAR(255),
+    supplier VARCHAR(255),              -- Party audited
+    issued_on DATE,
+    file_name VARCHAR(255) NOT NULL,    -- Uploaded file name
+    file_path TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    uploaded_by VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_due_diligence_documents_batch
+    ON due_diligence_documents (batch_id, created_at);
+
+COMMENT ON TABLE due_diligence_documents IS 'Third-party supply-chain audit reports attached to a batch; shown to legitimate-interest partners only';
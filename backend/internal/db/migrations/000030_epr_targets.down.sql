@@ -0,0 +1,4 @@
+-- Rollback EPR annual return
+-- specs.epr_category is left in place
+
+DROP TABLE IF EXISTS epr_targets;
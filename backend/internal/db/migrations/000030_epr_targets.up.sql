@@ -0,0 +1,16 @@
+-- ============================================================================
+-- EPR ANNUAL RETURN
+-- The return is aggregated from batches and passports on request. Producers
+-- configure their collection/recycling target per BWM battery category; the
+-- category itself lives in batches.specs (epr_category).
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS epr_targets (
+    tenant_id UUID NOT NULL REFERENCES public.tenants(id) ON DELETE CASCADE,
+    category VARCHAR(20) NOT NULL,              -- PORTABLE, AUTOMOTIVE, INDUSTRIAL, EV
+    target_pct DECIMAL(5,2) NOT NULL CHECK (target_pct >= 0 AND target_pct <= 100),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, category)
+);
+
+COMMENT ON TABLE epr_targets IS 'Per-tenant EPR target (% of weight placed on the market) by BWM battery category';
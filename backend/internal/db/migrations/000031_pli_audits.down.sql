@@ -0,0 +1,6 @@
+-- Rollback PLI DVA audits
+-- Batches keep their attested dva_source and audited_domestic_value_add
+
+DROP TABLE IF EXISTS pli_audit_log;
+DROP FUNCTION IF EXISTS pli_audit_log_immutable();
+DROP TABLE IF EXISTS pli_audits;
@@ -0,0 +1,78 @@
+-- ============================================================================
+-- PLI DVA AUDITS
+-- A batch's domestic value addition becomes AUDITED only when a chartered
+-- accountant attests it. The manufacturer invites the CA with a one-time
+-- emailed link; the CA enters the audited DVA with their membership number and
+-- UDIN and uploads the signed certificate, stored under
+-- ./storage/{tenant_id}/pli-audits/{batch_id}/. Every step is appended to
+-- pli_audit_log, which cannot be updated or deleted.
+-- ============================================================================
+
+-- Created by the earlier DVA audit fields migration; repeated for databases without it
+ALTER TABLE public.batches
+    ADD COLUMN IF NOT EXISTS dva_source VARCHAR(20) DEFAULT 'ESTIMATED',
+    ADD COLUMN IF NOT EXISTS audited_domestic_value_add DECIMAL(5,2),
+    ADD COLUMN IF NOT EXISTS pli_certificate_url TEXT;
+
+CREATE TABLE IF NOT EXISTS pli_audits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES public.tenants(id) ON DELETE CASCADE,
+    batch_id UUID NOT NULL REFERENCES public.batches(id) ON DELETE CASCADE,
+    ca_email VARCHAR(255) NOT NULL,          -- Lower-cased
+    ca_name VARCHAR(200) NOT NULL,
+    note TEXT,                               -- Manufacturer's message to the CA
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING', -- PENDING, ATTESTED, CANCELLED, EXPIRED
+    token_hash VARCHAR(64),                  -- SHA-256 of the emailed link token; cleared once used
+    expires_at TIMESTAMPTZ NOT NULL,
+    requested_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- Set by the attestation
+    audited_dva DECIMAL(5,2) CHECK (audited_dva >= 0 AND audited_dva <= 100),
+    ca_membership_no VARCHAR(6),             -- ICAI membership number
+    udin VARCHAR(18),                        -- ICAI Unique Document Identification Number
+    certificate_file_name VARCHAR(255),
+    certificate_path TEXT,
+    certificate_sha256 VARCHAR(64),
+    attested_at TIMESTAMPTZ,
+    closed_at TIMESTAMPTZ                    -- When cancelled or expired
+);
+
+-- One open request per batch
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pli_audits_pending_batch
+    ON pli_audits(batch_id) WHERE status = 'PENDING';
+
+CREATE INDEX IF NOT EXISTS idx_pli_audits_token
+    ON pli_audits(token_hash) WHERE token_hash IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_pli_audits_batch_created
+    ON pli_audits(batch_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS pli_audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES public.tenants(id),
+    batch_id UUID NOT NULL REFERENCES public.batches(id),
+    audit_id UUID NOT NULL REFERENCES pli_audits(id),
+    action VARCHAR(20) NOT NULL,             -- REQUESTED, CANCELLED, ATTESTED
+    actor VARCHAR(255) NOT NULL,             -- Manufacturer user or CA email
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pli_audit_log_batch_created
+    ON pli_audit_log(batch_id, created_at, id);
+
+-- Append-only: entries can be added but never changed or removed
+CREATE OR REPLACE FUNCTION pli_audit_log_immutable()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'pli_audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_pli_audit_log_immutable ON pli_audit_log;
+CREATE TRIGGER trg_pli_audit_log_immutable
+    BEFORE UPDATE OR DELETE ON pli_audit_log
+    FOR EACH ROW EXECUTE FUNCTION pli_audit_log_immutable();
+
+COMMENT ON TABLE pli_audits IS 'Requests to a chartered accountant to attest a batch''s PLI domestic value addition';
+COMMENT ON TABLE pli_audit_log IS 'Append-only log of PLI DVA audit requests, cancellations and attestations';
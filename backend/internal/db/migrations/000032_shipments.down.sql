@@ -0,0 +1,5 @@
+-- Rollback export shipments
+-- Shipped passports keep their SHIPPED status and passport_events history
+
+DROP TABLE IF EXISTS shipment_items;
+DROP TABLE IF EXISTS shipments;
@@ -0,0 +1,51 @@
+-- ============================================================================
+-- EXPORT SHIPMENTS
+-- A shipment groups passports for one consignment. While DRAFT it produces the
+-- customs documentation pack; shipping it moves every passport to SHIPPED.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS shipments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES public.tenants(id) ON DELETE CASCADE,
+    reference VARCHAR(100) NOT NULL,          -- Exporter's shipment reference
+    status VARCHAR(20) NOT NULL DEFAULT 'DRAFT', -- DRAFT, SHIPPED, CANCELLED
+    destination_country VARCHAR(100) NOT NULL,
+    consignee_name VARCHAR(200) NOT NULL,
+    consignee_address TEXT,
+    invoice_number VARCHAR(100),
+    invoice_date DATE,
+    incoterm VARCHAR(10),                     -- FOB, CIF, DAP, ...
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    unit_values JSONB NOT NULL DEFAULT '{}',  -- Invoice value per unit, by batch ID
+    carrier VARCHAR(200),
+    port_of_loading VARCHAR(100),
+    port_of_discharge VARCHAR(100),
+    packing VARCHAR(30) NOT NULL,             -- BATTERIES_ONLY, PACKED_WITH_EQUIPMENT, CONTAINED_IN_EQUIPMENT
+    note TEXT,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    shipped_by VARCHAR(255),
+    shipped_at TIMESTAMPTZ,
+    cancelled_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipments_tenant_created
+    ON shipments(tenant_id, created_at DESC, id DESC);
+
+CREATE TABLE IF NOT EXISTS shipment_items (
+    shipment_id UUID NOT NULL REFERENCES shipments(id) ON DELETE CASCADE,
+    passport_id UUID NOT NULL REFERENCES public.passports(uuid) ON DELETE CASCADE,
+    batch_id UUID NOT NULL REFERENCES public.batches(id) ON DELETE CASCADE,
+    open BOOLEAN NOT NULL DEFAULT TRUE,       -- Cleared when the shipment is shipped or cancelled
+    PRIMARY KEY (shipment_id, passport_id)
+);
+
+-- A passport can be in one draft shipment at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shipment_items_open_passport
+    ON shipment_items(passport_id) WHERE open;
+
+CREATE INDEX IF NOT EXISTS idx_shipment_items_batch
+    ON shipment_items(shipment_id, batch_id);
+
+COMMENT ON TABLE shipments IS 'Export consignments with invoice, consignee and dangerous-goods packing data';
+COMMENT ON TABLE shipment_items IS 'Passports in a shipment';
@@ -0,0 +1,7 @@
+-- Rollback multi-market batches
+-- Enum values cannot be dropped; US and UK batches fall back to GLOBAL
+
+UPDATE public.batches SET market_region = 'GLOBAL' WHERE market_region::text IN ('US', 'UK');
+
+DROP INDEX IF EXISTS idx_batches_markets;
+ALTER TABLE public.batches DROP COLUMN IF EXISTS markets;
@@ -0,0 +1,25 @@
+-- ============================================================================
+-- MULTI-MARKET BATCHES
+-- A batch targets one or more markets, each validated by its own rule pack.
+-- batches.market_region stays as a summary: the market itself for a single
+-- market, GLOBAL for several. Existing GLOBAL batches target INDIA and EU.
+-- ============================================================================
+
+-- New markets (not used in this file, so safe inside the migration transaction)
+ALTER TYPE market_region ADD VALUE IF NOT EXISTS 'US';
+ALTER TYPE market_region ADD VALUE IF NOT EXISTS 'UK';
+
+ALTER TABLE public.batches
+    ADD COLUMN IF NOT EXISTS markets TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE public.batches
+SET markets = CASE COALESCE(market_region::text, 'GLOBAL')
+                  WHEN 'GLOBAL' THEN ARRAY['INDIA', 'EU']
+                  ELSE ARRAY[market_region::text]
+              END
+WHERE markets = '{}';
+
+CREATE INDEX IF NOT EXISTS idx_batches_markets ON public.batches USING GIN (markets);
+
+COMMENT ON COLUMN public.batches.market_region IS 'Target market summary: the market of a single-market batch, or GLOBAL for several (see markets)';
+COMMENT ON COLUMN public.batches.markets IS 'Target markets (INDIA, EU, US, UK); each has a compliance rule pack';
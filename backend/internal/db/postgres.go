@@ -66,3 +66,19 @@ func (db *DB) Close() {
 func (db *DB) Ping(ctx context.Context) error {
 	return db.Pool.Ping(ctx)
 }
+
+// ConnectListener opens a dedicated connection for LISTEN/NOTIFY. It must be a
+// direct or session-mode connection: PgBouncer in transaction mode drops LISTEN.
+func ConnectListener(ctx context.Context, databaseURL string) (*pgx.Conn, error) {
+	config, err := pgx.ParseConfig(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database URL: %w", err)
+	}
+	config.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+
+	conn, err := pgx.ConnectConfig(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect listener: %w", err)
+	}
+	return conn, nil
+}
@@ -0,0 +1,586 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// QUERY COST
+// ============================================================================
+// The executor enforces depth; cost is estimated here before execution. Every
+// field costs 1 and a list field multiplies the cost of its selection by the
+// number of items it may return ("first", or the field's default), so
+//
+//	{ batches(first: 10) { passports(first: 50) { events { id } } } }
+//
+// costs 1 + 10 * (1 + 50 * (1 + 20 * 1)). The parser reads just enough of the
+// GraphQL grammar for this; the executor still does full validation.
+
+const (
+	maxQueryDepth      = 8
+	maxQueryComplexity = 10000
+	maxQueryLength     = 16 << 10
+)
+
+// listFields maps list-returning field names to their default "first"
+var listFields = map[string]int{
+	"batches":           defaultFirst,
+	"passports":         defaultFirst,
+	"events":            defaultFirst,
+	"scans":             defaultFirst,
+	"rewards":           defaultFirst,
+	"rewardLeaderboard": 10,
+}
+
+// queryCost estimates the cost of the operation that will run. Without an
+// operation name the most expensive operation in the document is used.
+func queryCost(query, operationName string, variables map[string]interface{}) (int, error) {
+	doc, err := parseDocument(query)
+	if err != nil {
+		return 0, err
+	}
+
+	c := costCounter{doc: doc, variables: variables, fragments: map[string]int{}, visiting: map[string]bool{}}
+	cost := 0
+	for _, op := range doc.operations {
+		if operationName != "" && op.name != operationName {
+			continue
+		}
+		if n := c.selections(op.selections); n > cost {
+			cost = n
+		}
+	}
+	return cost, nil
+}
+
+type costCounter struct {
+	doc       *document
+	variables map[string]interface{}
+	fragments map[string]int // Memoized cost per named fragment
+	visiting  map[string]bool
+}
+
+// selections sums a selection set, saturating just above the limit
+func (c *costCounter) selections(sels []*selection) int {
+	total := 0
+	for _, s := range sels {
+		switch {
+		case s.spread != "":
+			total += c.fragment(s.spread)
+		case s.name == "":
+			total += c.selections(s.children) // Inline fragment
+		default:
+			total++
+			if len(s.children) > 0 {
+				total += c.multiplier(s) * c.selections(s.children)
+			}
+		}
+		if total > maxQueryComplexity {
+			return maxQueryComplexity + 1
+		}
+	}
+	return total
+}
+
+func (c *costCounter) fragment(name string) int {
+	if cost, ok := c.fragments[name]; ok {
+		return cost
+	}
+	if c.visiting[name] {
+		return 0 // Fragment cycles are rejected by validation
+	}
+	c.visiting[name] = true
+	cost := c.selections(c.doc.fragments[name])
+	c.visiting[name] = false
+	c.fragments[name] = cost
+	return cost
+}
+
+// multiplier is how many items a list field may return
+func (c *costCounter) multiplier(s *selection) int {
+	def, ok := listFields[s.name]
+	if !ok {
+		return 1
+	}
+	n := def
+	if v, ok := s.args["first"]; ok {
+		switch {
+		case v.variable != "":
+			if value, ok := c.variables[v.variable].(float64); ok {
+				n = int(value)
+			} else if d, ok := c.doc.variableDefaults[v.variable]; ok {
+				n = d
+			}
+		case v.isInt:
+			n = v.number
+		}
+	}
+	if n < 1 {
+		n = 1
+	}
+	if n > maxFirst {
+		n = maxFirst
+	}
+	return n
+}
+
+// ============================================================================
+// DOCUMENT PARSER
+// ============================================================================
+
+type document struct {
+	operations       []*operation
+	fragments        map[string][]*selection
+	variableDefaults map[string]int // Integer defaults of operation variables
+}
+
+type operation struct {
+	name       string
+	selections []*selection
+}
+
+// selection is a field (name set), a fragment spread (spread set) or an inline fragment (neither)
+type selection struct {
+	name     string
+	spread   string
+	args     map[string]argValue
+	children []*selection
+}
+
+// argValue keeps what the cost needs from an argument: an integer or a variable reference
+type argValue struct {
+	variable string
+	number   int
+	isInt    bool
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokName
+	tokNumber
+	tokString
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+type parser struct {
+	src string
+	pos int
+	tok token
+}
+
+func parseDocument(src string) (*document, error) {
+	p := &parser{src: src}
+	doc := &document{fragments: map[string][]*selection{}, variableDefaults: map[string]int{}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	for p.tok.kind != tokEOF {
+		switch {
+		case p.is(tokPunct, "{"):
+			sels, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, &operation{selections: sels})
+
+		case p.is(tokName, "query"), p.is(tokName, "mutation"), p.is(tokName, "subscription"):
+			op := &operation{}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if p.tok.kind == tokName {
+				op.name = p.tok.text
+				if err := p.advance(); err != nil {
+					return nil, err
+				}
+			}
+			if p.is(tokPunct, "(") {
+				if err := p.variableDefinitions(doc); err != nil {
+					return nil, err
+				}
+			}
+			if err := p.directives(); err != nil {
+				return nil, err
+			}
+			sels, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			op.selections = sels
+			doc.operations = append(doc.operations, op)
+
+		case p.is(tokName, "fragment"):
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			name, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			if !p.is(tokName, "on") {
+				return nil, p.errorf("expected \"on\"")
+			}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if _, err := p.expectName(); err != nil {
+				return nil, err
+			}
+			if err := p.directives(); err != nil {
+				return nil, err
+			}
+			sels, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.fragments[name] = sels
+
+		default:
+			return nil, p.errorf("unexpected %q", p.tok.text)
+		}
+	}
+	return doc, nil
+}
+
+func (p *parser) selectionSet() ([]*selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var sels []*selection
+	for !p.is(tokPunct, "}") {
+		if p.tok.kind == tokEOF {
+			return nil, p.errorf("unterminated selection set")
+		}
+		s, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, s)
+	}
+	return sels, p.advance()
+}
+
+func (p *parser) selection() (*selection, error) {
+	if p.is(tokPunct, "...") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tokName && p.tok.text != "on" {
+			s := &selection{spread: p.tok.text}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			return s, p.directives()
+		}
+		if p.is(tokName, "on") {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if _, err := p.expectName(); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.directives(); err != nil {
+			return nil, err
+		}
+		children, err := p.selectionSet()
+		return &selection{children: children}, err
+	}
+
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if p.is(tokPunct, ":") { // Alias
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+	s := &selection{name: name}
+	if p.is(tokPunct, "(") {
+		if s.args, err = p.arguments(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.directives(); err != nil {
+		return nil, err
+	}
+	if p.is(tokPunct, "{") {
+		if s.children, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (p *parser) arguments() (map[string]argValue, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	args := map[string]argValue{}
+	for !p.is(tokPunct, ")") {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if args[name], err = p.value(); err != nil {
+			return nil, err
+		}
+	}
+	return args, p.advance()
+}
+
+func (p *parser) variableDefinitions(doc *document) error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	for !p.is(tokPunct, ")") {
+		if err := p.expect("$"); err != nil {
+			return err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return err
+		}
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+		if err := p.typeRef(); err != nil {
+			return err
+		}
+		if p.is(tokPunct, "=") {
+			if err := p.advance(); err != nil {
+				return err
+			}
+			v, err := p.value()
+			if err != nil {
+				return err
+			}
+			if v.isInt {
+				doc.variableDefaults[name] = v.number
+			}
+		}
+		if err := p.directives(); err != nil {
+			return err
+		}
+	}
+	return p.advance()
+}
+
+func (p *parser) typeRef() error {
+	if p.is(tokPunct, "[") {
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if err := p.typeRef(); err != nil {
+			return err
+		}
+		if err := p.expect("]"); err != nil {
+			return err
+		}
+	} else if _, err := p.expectName(); err != nil {
+		return err
+	}
+	if p.is(tokPunct, "!") {
+		return p.advance()
+	}
+	return nil
+}
+
+func (p *parser) directives() error {
+	for p.is(tokPunct, "@") {
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if _, err := p.expectName(); err != nil {
+			return err
+		}
+		if p.is(tokPunct, "(") {
+			if _, err := p.arguments(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// value parses any input value, keeping integers and variable references
+func (p *parser) value() (argValue, error) {
+	switch {
+	case p.is(tokPunct, "$"):
+		if err := p.advance(); err != nil {
+			return argValue{}, err
+		}
+		name, err := p.expectName()
+		return argValue{variable: name}, err
+
+	case p.tok.kind == tokNumber:
+		v := argValue{}
+		if n, err := strconv.Atoi(p.tok.text); err == nil {
+			v = argValue{number: n, isInt: true}
+		}
+		return v, p.advance()
+
+	case p.tok.kind == tokString, p.tok.kind == tokName:
+		return argValue{}, p.advance()
+
+	case p.is(tokPunct, "["):
+		if err := p.advance(); err != nil {
+			return argValue{}, err
+		}
+		for !p.is(tokPunct, "]") {
+			if p.tok.kind == tokEOF {
+				return argValue{}, p.errorf("unterminated list")
+			}
+			if _, err := p.value(); err != nil {
+				return argValue{}, err
+			}
+		}
+		return argValue{}, p.advance()
+
+	case p.is(tokPunct, "{"):
+		if err := p.advance(); err != nil {
+			return argValue{}, err
+		}
+		for !p.is(tokPunct, "}") {
+			if _, err := p.expectName(); err != nil {
+				return argValue{}, err
+			}
+			if err := p.expect(":"); err != nil {
+				return argValue{}, err
+			}
+			if _, err := p.value(); err != nil {
+				return argValue{}, err
+			}
+		}
+		return argValue{}, p.advance()
+	}
+	return argValue{}, p.errorf("unexpected %q", p.tok.text)
+}
+
+func (p *parser) is(kind tokenKind, text string) bool {
+	return p.tok.kind == kind && p.tok.text == text
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.is(tokPunct, punct) {
+		return p.errorf("expected %q", punct)
+	}
+	return p.advance()
+}
+
+func (p *parser) expectName() (string, error) {
+	if p.tok.kind != tokName {
+		return "", p.errorf("expected name")
+	}
+	name := p.tok.text
+	return name, p.advance()
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("syntax error at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+// advance reads the next token, skipping whitespace, commas and comments
+func (p *parser) advance() error {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' {
+			p.pos++
+		} else if c == '#' {
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' && p.src[p.pos] != '\r' {
+				p.pos++
+			}
+		} else if strings.HasPrefix(p.src[p.pos:], "\uFEFF") {
+			p.pos += len("\uFEFF")
+		} else {
+			break
+		}
+	}
+	if p.pos >= len(p.src) {
+		p.tok = token{kind: tokEOF}
+		return nil
+	}
+
+	start := p.pos
+	c := p.src[p.pos]
+	switch {
+	case strings.HasPrefix(p.src[p.pos:], "..."):
+		p.pos += 3
+		p.tok = token{kind: tokPunct, text: "..."}
+
+	case strings.IndexByte("!$&()[]{}:=@|", c) >= 0:
+		p.pos++
+		p.tok = token{kind: tokPunct, text: string(c)}
+
+	case c == '_' || isLetter(c):
+		for p.pos < len(p.src) && (p.src[p.pos] == '_' || isLetter(p.src[p.pos]) || isDigit(p.src[p.pos])) {
+			p.pos++
+		}
+		p.tok = token{kind: tokName, text: p.src[start:p.pos]}
+
+	case c == '-' || isDigit(c):
+		p.pos++
+		for p.pos < len(p.src) && (isDigit(p.src[p.pos]) || strings.IndexByte(".eE+-", p.src[p.pos]) >= 0) {
+			p.pos++
+		}
+		p.tok = token{kind: tokNumber, text: p.src[start:p.pos]}
+
+	case strings.HasPrefix(p.src[p.pos:], `"""`):
+		end := strings.Index(p.src[p.pos+3:], `"""`)
+		for end >= 0 && p.src[p.pos+3+end-1] == '\\' { // Escaped \"""
+			next := strings.Index(p.src[p.pos+3+end+3:], `"""`)
+			if next < 0 {
+				end = -1
+				break
+			}
+			end += 3 + next
+		}
+		if end < 0 {
+			return p.errorf("unterminated block string")
+		}
+		p.pos += 3 + end + 3
+		p.tok = token{kind: tokString, text: p.src[start:p.pos]}
+
+	case c == '"':
+		p.pos++
+		for {
+			if p.pos >= len(p.src) || p.src[p.pos] == '\n' {
+				return p.errorf("unterminated string")
+			}
+			if p.src[p.pos] == '\\' {
+				p.pos += 2
+				continue
+			}
+			if p.src[p.pos] == '"' {
+				p.pos++
+				break
+			}
+			p.pos++
+		}
+		p.tok = token{kind: tokString, text: p.src[start:p.pos]}
+
+	default:
+		return p.errorf("unexpected character %q", c)
+	}
+	return nil
+}
+
+func isLetter(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
@@ -0,0 +1,111 @@
+// Package graph serves the GraphQL read API over the repository layer.
+package graph
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"exportready-battery/internal/middleware"
+	"exportready-battery/internal/repository"
+
+	"github.com/google/uuid"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+const maxRequestBytes = 1 << 20
+
+// Request is a GraphQL-over-HTTP POST body
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Handler executes GraphQL queries for the tenant of the authenticated JWT or API key
+type Handler struct {
+	repo   *repository.Repository
+	schema *graphql.Schema
+}
+
+// NewHandler parses the schema; it panics if the schema and resolvers disagree
+func NewHandler(repo *repository.Repository) *Handler {
+	schema := graphql.MustParseSchema(schemaSDL, &rootResolver{},
+		graphql.MaxDepth(maxQueryDepth),
+		graphql.MaxQueryLength(maxQueryLength),
+		graphql.MaxParallelism(10),
+	)
+	return &Handler{repo: repo, schema: schema}
+}
+
+type viewerKey struct{}
+
+func viewerFrom(ctx context.Context) *viewer {
+	return ctx.Value(viewerKey{}).(*viewer)
+}
+
+// ServeHTTP handles POST /api/v1/graphql and POST /api/v1/external/graphql
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v, ok := h.viewer(r)
+	if !ok {
+		writeErrors(w, http.StatusUnauthorized, "not authenticated")
+		return
+	}
+
+	var req Request
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		writeErrors(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Query == "" {
+		writeErrors(w, http.StatusBadRequest, "query is required")
+		return
+	}
+	if len(req.Query) > maxQueryLength {
+		writeErrors(w, http.StatusBadRequest, fmt.Sprintf("query exceeds %d bytes", maxQueryLength))
+		return
+	}
+
+	cost, err := queryCost(req.Query, req.OperationName, req.Variables)
+	if err != nil {
+		writeErrors(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if cost > maxQueryComplexity {
+		writeErrors(w, http.StatusBadRequest, fmt.Sprintf("query is too complex: estimated cost exceeds %d; request fewer items with first", maxQueryComplexity))
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), viewerKey{}, v)
+	resp := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// viewer builds the request's tenant scope from the API key or JWT in context
+func (h *Handler) viewer(r *http.Request) (*viewer, bool) {
+	if key := middleware.GetAPIKey(r.Context()); key != nil {
+		return &viewer{repo: h.repo, tenantID: key.TenantID, batchIDs: key.AllowedBatchIDs, markets: key.AllowedMarkets}, true
+	}
+	tenantID, err := uuid.Parse(middleware.GetTenantID(r.Context()))
+	if err != nil {
+		return nil, false
+	}
+	return &viewer{repo: h.repo, tenantID: tenantID}, true
+}
+
+// writeErrors writes a GraphQL error response for requests that were not executed
+func writeErrors(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"errors": []map[string]string{{"message": message}},
+	})
+}
@@ -0,0 +1,199 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// SIBLING LOADING (dataloader-style batching)
+// ============================================================================
+// Every list resolver hands its items a shared group. The first item to resolve
+// a relation loads it for the whole group with one query; the others read the
+// memoized result. A query therefore costs one round trip per relation per
+// level, however many parents the level holds.
+
+// relation memoizes one relation of a group per argument set (e.g. first: 5)
+type relation[V any] struct {
+	mu    sync.Mutex
+	calls map[string]*relationCall[V]
+}
+
+type relationCall[V any] struct {
+	once sync.Once
+	data map[uuid.UUID]V
+	err  error
+}
+
+// load runs fetch once per key and returns the shared result
+func (r *relation[V]) load(key string, fetch func() (map[uuid.UUID]V, error)) (map[uuid.UUID]V, error) {
+	r.mu.Lock()
+	if r.calls == nil {
+		r.calls = map[string]*relationCall[V]{}
+	}
+	call, ok := r.calls[key]
+	if !ok {
+		call = &relationCall[V]{}
+		r.calls[key] = call
+	}
+	r.mu.Unlock()
+
+	call.once.Do(func() {
+		call.data, call.err = fetch()
+	})
+	return call.data, call.err
+}
+
+// batchGroup is a list of batches resolved together
+type batchGroup struct {
+	v         *viewer
+	ids       []uuid.UUID
+	passports relation[[]*passportResolver]
+}
+
+// newBatchResolvers wraps batches in resolvers sharing one group
+func newBatchResolvers(v *viewer, batches []*models.Batch) []*batchResolver {
+	g := &batchGroup{v: v}
+	resolvers := make([]*batchResolver, len(batches))
+	for i, b := range batches {
+		g.ids = append(g.ids, b.ID)
+		resolvers[i] = &batchResolver{batch: b, group: g}
+	}
+	return resolvers
+}
+
+// passportGroup is a list of passports resolved together
+type passportGroup struct {
+	v        *viewer
+	ids      []uuid.UUID
+	batchIDs []uuid.UUID
+
+	batches relation[*batchResolver]
+	events  relation[[]*models.PassportEvent]
+	scans   relation[[]*models.ScanEvent]
+	rewards relation[[]*models.RewardLedger]
+}
+
+// newPassportResolvers wraps passports in resolvers sharing one group
+func newPassportResolvers(v *viewer, passports []*models.Passport) []*passportResolver {
+	g := &passportGroup{v: v}
+	seen := map[uuid.UUID]bool{}
+	resolvers := make([]*passportResolver, len(passports))
+	for i, p := range passports {
+		g.ids = append(g.ids, p.UUID)
+		if !seen[p.BatchID] {
+			seen[p.BatchID] = true
+			g.batchIDs = append(g.batchIDs, p.BatchID)
+		}
+		resolvers[i] = &passportResolver{passport: p, group: g}
+	}
+	return resolvers
+}
+
+// loadBatches resolves the group's batches, themselves as one batch group
+func (g *passportGroup) loadBatches(ctx context.Context) (map[uuid.UUID]*batchResolver, error) {
+	return g.batches.load("", func() (map[uuid.UUID]*batchResolver, error) {
+		batches, err := g.v.batchesByID(ctx, g.batchIDs)
+		if err != nil {
+			return nil, err
+		}
+		result := make(map[uuid.UUID]*batchResolver, len(batches))
+		for _, b := range newBatchResolvers(g.v, batches) {
+			result[b.batch.ID] = b
+		}
+		return result, nil
+	})
+}
+
+var errMarketNotAllowed = errors.New("API key is not allowed for this market region")
+
+// viewer is who the request acts for: a tenant, narrowed by API key restrictions
+type viewer struct {
+	repo     *repository.Repository
+	tenantID uuid.UUID
+	batchIDs []uuid.UUID // API key batch restriction (empty = all)
+	markets  []string    // API key market restriction (empty = all)
+}
+
+// batchFilter applies the viewer's restrictions to a batch filter
+func (v *viewer) batchFilter(f repository.BatchFilter) (repository.BatchFilter, error) {
+	f.BatchIDs = v.batchIDs
+	if len(v.markets) > 0 {
+		if !containsAll(v.markets, f.MarketRegions) {
+			return f, errMarketNotAllowed
+		}
+		if len(f.MarketRegions) == 0 {
+			f.MarketRegions = v.markets
+		}
+	}
+	return f, nil
+}
+
+// passportFilter applies the viewer's restrictions to a passport filter
+func (v *viewer) passportFilter(f repository.PassportFilter) repository.PassportFilter {
+	f.BatchIDs = v.batchIDs
+	f.MarketRegions = v.markets
+	return f
+}
+
+// batchesByID loads visible batches by ID, in pages of the repository maximum
+func (v *viewer) batchesByID(ctx context.Context, ids []uuid.UUID) ([]*models.Batch, error) {
+	const chunk = 200
+	var batches []*models.Batch
+	for start := 0; start < len(ids); start += chunk {
+		end := min(start+chunk, len(ids))
+		filter, _ := v.batchFilter(repository.BatchFilter{})
+		filter.BatchIDs = intersect(ids[start:end], v.batchIDs)
+		if len(filter.BatchIDs) == 0 {
+			continue
+		}
+		page, _, err := v.repo.ListBatches(ctx, v.tenantID, filter, repository.PageRequest{Limit: chunk})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load batches: %w", err)
+		}
+		batches = append(batches, page...)
+	}
+	return batches, nil
+}
+
+// intersect narrows ids to the allowed set; an empty allowed set allows everything
+func intersect(ids, allowed []uuid.UUID) []uuid.UUID {
+	if len(allowed) == 0 {
+		return ids
+	}
+	ok := make(map[uuid.UUID]bool, len(allowed))
+	for _, id := range allowed {
+		ok[id] = true
+	}
+	var result []uuid.UUID
+	for _, id := range ids {
+		if ok[id] {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// containsAll reports whether every value is in set
+func containsAll(set, values []string) bool {
+	for _, value := range values {
+		found := false
+		for _, s := range set {
+			if s == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,443 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+
+	"github.com/google/uuid"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+const (
+	defaultFirst = 20
+	maxFirst     = 100
+)
+
+// internalError logs a repository failure and returns a message safe to show clients
+func internalError(what string, err error) error {
+	log.Printf("GraphQL: failed to %s: %v", what, err)
+	return fmt.Errorf("failed to %s", what)
+}
+
+// firstArg bounds a "first" argument (the schema supplies the default)
+func firstArg(first int32) int {
+	n := int(first)
+	if n < 1 {
+		return 1
+	}
+	if n > maxFirst {
+		return maxFirst
+	}
+	return n
+}
+
+func str(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func parseID(id graphql.ID) (uuid.UUID, error) {
+	parsed, err := uuid.Parse(string(id))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid ID %q", id)
+	}
+	return parsed, nil
+}
+
+// ============================================================================
+// QUERY
+// ============================================================================
+
+type rootResolver struct{}
+
+type batchesArgs struct {
+	First        int32
+	After        *string
+	Status       *string
+	MarketRegion *string
+	Chemistry    *string
+}
+
+type passportsArgs struct {
+	First        int32
+	After        *string
+	BatchID      *graphql.ID
+	Status       *string
+	SerialPrefix *string
+}
+
+func (r *rootResolver) Tenant(ctx context.Context) (*tenantResolver, error) {
+	v := viewerFrom(ctx)
+	tenant, err := v.repo.GetTenant(ctx, v.tenantID)
+	if err != nil {
+		return nil, internalError("load tenant", err)
+	}
+	return &tenantResolver{tenant: tenant}, nil
+}
+
+func (r *rootResolver) Batches(ctx context.Context, args batchesArgs) (*batchConnection, error) {
+	return listBatches(ctx, args)
+}
+
+func (r *rootResolver) Batch(ctx context.Context, args struct{ ID graphql.ID }) (*batchResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	batches, err := viewerFrom(ctx).batchesByID(ctx, []uuid.UUID{id})
+	if err != nil {
+		return nil, internalError("load batch", err)
+	}
+	if len(batches) == 0 {
+		return nil, nil
+	}
+	return newBatchResolvers(viewerFrom(ctx), batches)[0], nil
+}
+
+func (r *rootResolver) Passports(ctx context.Context, args passportsArgs) (*passportConnection, error) {
+	v := viewerFrom(ctx)
+	filter := v.passportFilter(repository.PassportFilter{
+		Status:       strings.ToUpper(str(args.Status)),
+		SerialPrefix: str(args.SerialPrefix),
+	})
+	if args.BatchID != nil {
+		batchID, err := parseID(*args.BatchID)
+		if err != nil {
+			return nil, err
+		}
+		filter.BatchID = &batchID
+	}
+
+	page := repository.PageRequest{Limit: firstArg(args.First)}
+	if args.After != nil {
+		after, err := repository.DecodeCursor(*args.After, filter.Sort())
+		if err != nil {
+			return nil, err
+		}
+		page.After = after
+	}
+
+	passports, info, err := v.repo.ListPassports(ctx, v.tenantID, filter, page)
+	if err != nil {
+		return nil, internalError("list passports", err)
+	}
+	return &passportConnection{nodes: newPassportResolvers(v, passports), info: info}, nil
+}
+
+func (r *rootResolver) Passport(ctx context.Context, args struct{ UUID graphql.ID }) (*passportResolver, error) {
+	id, err := parseID(args.UUID)
+	if err != nil {
+		return nil, err
+	}
+	v := viewerFrom(ctx)
+	filter := v.passportFilter(repository.PassportFilter{PassportIDs: []uuid.UUID{id}})
+	passports, _, err := v.repo.ListPassports(ctx, v.tenantID, filter, repository.PageRequest{Limit: 1})
+	if err != nil {
+		return nil, internalError("load passport", err)
+	}
+	if len(passports) == 0 {
+		return nil, nil
+	}
+	return newPassportResolvers(v, passports)[0], nil
+}
+
+// listBatches serves Query.batches and Tenant.batches
+func listBatches(ctx context.Context, args batchesArgs) (*batchConnection, error) {
+	v := viewerFrom(ctx)
+	filter := repository.BatchFilter{
+		Status:    strings.ToUpper(str(args.Status)),
+		Chemistry: str(args.Chemistry),
+	}
+	if args.MarketRegion != nil {
+		region := strings.ToUpper(*args.MarketRegion)
+		if !models.MarketRegion(region).IsValid() {
+			return nil, fmt.Errorf("invalid marketRegion: must be %s", models.ValidMarketRegions)
+		}
+		filter.MarketRegions = []string{region}
+	}
+	filter, err := v.batchFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := repository.PageRequest{Limit: firstArg(args.First)}
+	if args.After != nil {
+		after, err := repository.DecodeCursor(*args.After, repository.SortBatchesNewest)
+		if err != nil {
+			return nil, err
+		}
+		page.After = after
+	}
+
+	batches, info, err := v.repo.ListBatches(ctx, v.tenantID, filter, page)
+	if err != nil {
+		return nil, internalError("list batches", err)
+	}
+	return &batchConnection{nodes: newBatchResolvers(v, batches), info: info}, nil
+}
+
+// ============================================================================
+// CONNECTIONS
+// ============================================================================
+
+type pageInfoResolver struct {
+	info repository.PageInfo
+}
+
+func (p *pageInfoResolver) HasMore() bool      { return p.info.HasMore }
+func (p *pageInfoResolver) EndCursor() *string { return optional(p.info.NextCursor) }
+
+type batchConnection struct {
+	nodes []*batchResolver
+	info  repository.PageInfo
+}
+
+func (c *batchConnection) Nodes() []*batchResolver     { return c.nodes }
+func (c *batchConnection) PageInfo() *pageInfoResolver { return &pageInfoResolver{info: c.info} }
+
+type passportConnection struct {
+	nodes []*passportResolver
+	info  repository.PageInfo
+}
+
+func (c *passportConnection) Nodes() []*passportResolver  { return c.nodes }
+func (c *passportConnection) PageInfo() *pageInfoResolver { return &pageInfoResolver{info: c.info} }
+
+// ============================================================================
+// TENANT
+// ============================================================================
+
+type tenantResolver struct {
+	tenant *models.Tenant
+}
+
+func (t *tenantResolver) ID() graphql.ID          { return graphql.ID(t.tenant.ID.String()) }
+func (t *tenantResolver) CompanyName() string     { return t.tenant.CompanyName }
+func (t *tenantResolver) CreatedAt() graphql.Time { return graphql.Time{Time: t.tenant.CreatedAt} }
+
+func (t *tenantResolver) Batches(ctx context.Context, args batchesArgs) (*batchConnection, error) {
+	return listBatches(ctx, args)
+}
+
+func (t *tenantResolver) RewardLeaderboard(ctx context.Context, args struct{ First int32 }) ([]*rewardBalanceResolver, error) {
+	v := viewerFrom(ctx)
+	balances, err := v.repo.GetRewardLeaderboard(ctx, v.tenantID, firstArg(args.First))
+	if err != nil {
+		return nil, internalError("load reward leaderboard", err)
+	}
+	resolvers := make([]*rewardBalanceResolver, len(balances))
+	for i, b := range balances {
+		resolvers[i] = &rewardBalanceResolver{balance: b}
+	}
+	return resolvers, nil
+}
+
+// ============================================================================
+// BATCH
+// ============================================================================
+
+type batchResolver struct {
+	batch *models.Batch
+	group *batchGroup
+}
+
+func (b *batchResolver) ID() graphql.ID           { return graphql.ID(b.batch.ID.String()) }
+func (b *batchResolver) Name() string             { return b.batch.BatchName }
+func (b *batchResolver) Status() string           { return b.batch.Status }
+func (b *batchResolver) MarketRegion() string     { return string(b.batch.MarketRegion) }
+func (b *batchResolver) Markets() []string        { return models.MarketStrings(b.batch.Markets) }
+func (b *batchResolver) Chemistry() string        { return b.batch.Specs.Chemistry }
+func (b *batchResolver) Manufacturer() string     { return b.batch.Specs.Manufacturer }
+func (b *batchResolver) Capacity() string         { return b.batch.Specs.Capacity }
+func (b *batchResolver) Voltage() string          { return b.batch.Specs.NominalVoltage }
+func (b *batchResolver) BatteryCategory() *string { return optional(b.batch.Specs.BatteryCategory) }
+func (b *batchResolver) PassportCount() int32     { return int32(b.batch.TotalPassports) }
+func (b *batchResolver) BillOfEntryNo() *string   { return optional(b.batch.BillOfEntryNo) }
+func (b *batchResolver) HSNCode() *string         { return optional(b.batch.HSNCode) }
+func (b *batchResolver) CountryOfOrigin() *string { return optional(b.batch.CountryOfOrigin) }
+func (b *batchResolver) CreatedAt() graphql.Time  { return graphql.Time{Time: b.batch.CreatedAt} }
+
+// Passports loads the first passports of every batch in the group with one query
+func (b *batchResolver) Passports(ctx context.Context, args struct {
+	First  int32
+	Status *string
+}) ([]*passportResolver, error) {
+	g := b.group
+	first := firstArg(args.First)
+	status := strings.ToUpper(str(args.Status))
+
+	byBatch, err := g.passports.load(fmt.Sprintf("%d:%s", first, status), func() (map[uuid.UUID][]*passportResolver, error) {
+		loaded, err := g.v.repo.ListPassportsForBatches(ctx, g.ids, status, first)
+		if err != nil {
+			return nil, internalError("load batch passports", err)
+		}
+		var all []*models.Passport
+		for _, id := range g.ids {
+			all = append(all, loaded[id]...)
+		}
+		result := make(map[uuid.UUID][]*passportResolver, len(loaded))
+		for _, p := range newPassportResolvers(g.v, all) {
+			result[p.passport.BatchID] = append(result[p.passport.BatchID], p)
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if byBatch[b.batch.ID] == nil {
+		return []*passportResolver{}, nil
+	}
+	return byBatch[b.batch.ID], nil
+}
+
+// ============================================================================
+// PASSPORT
+// ============================================================================
+
+type passportResolver struct {
+	passport *models.Passport
+	group    *passportGroup
+}
+
+func (p *passportResolver) UUID() graphql.ID        { return graphql.ID(p.passport.UUID.String()) }
+func (p *passportResolver) SerialNumber() string    { return p.passport.SerialNumber }
+func (p *passportResolver) Status() string          { return p.passport.Status }
+func (p *passportResolver) CreatedAt() graphql.Time { return graphql.Time{Time: p.passport.CreatedAt} }
+func (p *passportResolver) ManufactureDate() graphql.Time {
+	return graphql.Time{Time: p.passport.ManufactureDate}
+}
+
+func (p *passportResolver) Batch(ctx context.Context) (*batchResolver, error) {
+	batches, err := p.group.loadBatches(ctx)
+	if err != nil {
+		return nil, internalError("load batches", err)
+	}
+	batch, ok := batches[p.passport.BatchID]
+	if !ok {
+		return nil, fmt.Errorf("batch not found")
+	}
+	return batch, nil
+}
+
+func (p *passportResolver) Events(ctx context.Context, args struct{ First int32 }) ([]*eventResolver, error) {
+	g := p.group
+	first := firstArg(args.First)
+	events, err := g.events.load(fmt.Sprint(first), func() (map[uuid.UUID][]*models.PassportEvent, error) {
+		return g.v.repo.GetPassportEventsForPassports(ctx, g.ids, first)
+	})
+	if err != nil {
+		return nil, internalError("load passport events", err)
+	}
+	resolvers := make([]*eventResolver, 0, len(events[p.passport.UUID]))
+	for _, e := range events[p.passport.UUID] {
+		resolvers = append(resolvers, &eventResolver{event: e})
+	}
+	return resolvers, nil
+}
+
+func (p *passportResolver) Scans(ctx context.Context, args struct{ First int32 }) ([]*scanResolver, error) {
+	g := p.group
+	first := firstArg(args.First)
+	scans, err := g.scans.load(fmt.Sprint(first), func() (map[uuid.UUID][]*models.ScanEvent, error) {
+		return g.v.repo.GetScanEventsForPassports(ctx, g.ids, first)
+	})
+	if err != nil {
+		return nil, internalError("load scans", err)
+	}
+	resolvers := make([]*scanResolver, 0, len(scans[p.passport.UUID]))
+	for _, s := range scans[p.passport.UUID] {
+		resolvers = append(resolvers, &scanResolver{scan: s})
+	}
+	return resolvers, nil
+}
+
+func (p *passportResolver) Rewards(ctx context.Context, args struct{ First int32 }) ([]*rewardEntryResolver, error) {
+	g := p.group
+	first := firstArg(args.First)
+	entries, err := g.rewards.load(fmt.Sprint(first), func() (map[uuid.UUID][]*models.RewardLedger, error) {
+		return g.v.repo.GetRewardEntriesForPassports(ctx, g.v.tenantID, g.ids, first)
+	})
+	if err != nil {
+		return nil, internalError("load rewards", err)
+	}
+	resolvers := make([]*rewardEntryResolver, 0, len(entries[p.passport.UUID]))
+	for _, e := range entries[p.passport.UUID] {
+		resolvers = append(resolvers, &rewardEntryResolver{entry: e})
+	}
+	return resolvers, nil
+}
+
+// ============================================================================
+// EVENTS, SCANS AND REWARDS
+// ============================================================================
+
+type eventResolver struct {
+	event *models.PassportEvent
+}
+
+func (e *eventResolver) ID() graphql.ID          { return graphql.ID(e.event.ID.String()) }
+func (e *eventResolver) EventType() string       { return e.event.EventType }
+func (e *eventResolver) Actor() string           { return e.event.Actor }
+func (e *eventResolver) CreatedAt() graphql.Time { return graphql.Time{Time: e.event.CreatedAt} }
+
+func (e *eventResolver) Metadata() *string {
+	if len(e.event.Metadata) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(e.event.Metadata)
+	if err != nil {
+		return nil
+	}
+	return optional(string(data))
+}
+
+type scanResolver struct {
+	scan *models.ScanEvent
+}
+
+func (s *scanResolver) ID() graphql.ID          { return graphql.ID(s.scan.ID.String()) }
+func (s *scanResolver) City() string            { return s.scan.City }
+func (s *scanResolver) Country() string         { return s.scan.Country }
+func (s *scanResolver) DeviceType() string      { return s.scan.DeviceType }
+func (s *scanResolver) ScannedAt() graphql.Time { return graphql.Time{Time: s.scan.ScannedAt} }
+
+type rewardEntryResolver struct {
+	entry *models.RewardLedger
+}
+
+func (r *rewardEntryResolver) ID() graphql.ID          { return graphql.ID(r.entry.ID.String()) }
+func (r *rewardEntryResolver) RecipientEmail() string  { return r.entry.RecipientEmail }
+func (r *rewardEntryResolver) ActionType() string      { return r.entry.ActionType }
+func (r *rewardEntryResolver) Points() int32           { return int32(r.entry.PointsEarned) }
+func (r *rewardEntryResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.entry.CreatedAt} }
+
+type rewardBalanceResolver struct {
+	balance *models.RewardBalance
+}
+
+func (r *rewardBalanceResolver) RecipientEmail() string { return r.balance.RecipientEmail }
+func (r *rewardBalanceResolver) TotalPoints() int32     { return int32(r.balance.TotalPoints) }
+func (r *rewardBalanceResolver) InstallCount() int32    { return int32(r.balance.InstallCount) }
+func (r *rewardBalanceResolver) RecycleCount() int32    { return int32(r.balance.RecycleCount) }
+func (r *rewardBalanceResolver) ReturnCount() int32     { return int32(r.balance.ReturnCount) }
+func (r *rewardBalanceResolver) LoyaltyLevel() string {
+	return models.GetLoyaltyLevel(r.balance.TotalPoints)
+}
+func (r *rewardBalanceResolver) LastActivity() graphql.Time {
+	return graphql.Time{Time: r.balance.LastActivity}
+}
@@ -1,9 +1,14 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"exportready-battery/internal/middleware"
@@ -31,21 +36,24 @@ func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate scope
-	if req.Scope == "" {
-		req.Scope = "read"
-	}
-	if !models.IsValidScope(req.Scope) {
-		respondError(w, http.StatusBadRequest, "Invalid scope. Must be 'read' or 'write'")
-		return
+	// Validate scopes (legacy read/write expands to fine-grained scopes)
+	if len(req.Scopes) == 0 {
+		if req.Scope == "" {
+			req.Scope = "read"
+		}
+		req.Scopes = models.LegacyScopes(req.Scope)
+		if req.Scopes == nil {
+			respondError(w, http.StatusBadRequest, "Invalid scope. Must be 'read' or 'write'")
+			return
+		}
 	}
 
 	// Validate rate limit tier
 	if req.RateLimitTier == "" {
-		req.RateLimitTier = "starter"
+		req.RateLimitTier = models.DefaultRateLimitTier
 	}
-	if req.RateLimitTier != "starter" && req.RateLimitTier != "production" {
-		respondError(w, http.StatusBadRequest, "Invalid rate_limit_tier. Must be 'starter' or 'production'")
+	if !models.IsValidRateLimitTier(req.RateLimitTier) {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid rate_limit_tier. Must be one of: %s", strings.Join(models.RateLimitTierNames(), ", ")))
 		return
 	}
 
@@ -57,6 +65,13 @@ func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 	}
 	tenantID, _ := uuid.Parse(tenantIDStr)
 
+	// Validate scopes and resource restrictions
+	cidrs, msg := h.validateAPIKeyPermissions(r.Context(), tenantID, req.Scopes, req.AllowedBatchIDs, req.AllowedMarkets, req.AllowedCIDRs, req.ActorRole)
+	if msg != "" {
+		respondError(w, http.StatusBadRequest, msg)
+		return
+	}
+
 	// Generate key
 	keyService := services.NewAPIKeyService()
 	fullKey, prefix, err := keyService.GenerateKey()
@@ -88,11 +103,17 @@ func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 		Name:          req.Name,
 		KeyHash:       keyHash,
 		KeyPrefix:     prefix,
-		Scope:         req.Scope,
+		Scope:         models.SummarizeScopes(req.Scopes),
 		RateLimitTier: req.RateLimitTier,
 		ExpiresAt:     expiresAt,
 		IsActive:      true,
 		CreatedAt:     time.Now(),
+
+		Scopes:          req.Scopes,
+		AllowedBatchIDs: req.AllowedBatchIDs,
+		AllowedMarkets:  req.AllowedMarkets,
+		AllowedCIDRs:    cidrs,
+		ActorRole:       req.ActorRole,
 	}
 
 	if err := h.repo.CreateAPIKey(r.Context(), apiKey); err != nil {
@@ -101,7 +122,7 @@ func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("🔑 API key created: %s (tenant: %s, scope: %s)", req.Name, tenantIDStr[:8], req.Scope)
+	log.Printf("🔑 API key created: %s (tenant: %s, scopes: %s)", req.Name, tenantIDStr[:8], strings.Join(req.Scopes, ","))
 
 	// Return key with full key (only time it's shown)
 	respondJSON(w, http.StatusCreated, models.APIKeyWithSecret{
@@ -198,7 +219,36 @@ func (h *Handler) UpdateAPIKey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.repo.UpdateAPIKey(r.Context(), keyID, req.Name, req.IsActive); err != nil {
+	// Validate changed scopes and restrictions against the resulting key
+	if req.Scopes != nil || req.AllowedBatchIDs != nil || req.AllowedMarkets != nil || req.AllowedCIDRs != nil || req.ActorRole != nil {
+		scopes, batchIDs, markets, cidrs, role := apiKey.Scopes, apiKey.AllowedBatchIDs, apiKey.AllowedMarkets, apiKey.AllowedCIDRs, apiKey.ActorRole
+		if req.Scopes != nil {
+			scopes = *req.Scopes
+		}
+		if req.AllowedBatchIDs != nil {
+			batchIDs = *req.AllowedBatchIDs
+		}
+		if req.AllowedMarkets != nil {
+			markets = *req.AllowedMarkets
+		}
+		if req.AllowedCIDRs != nil {
+			cidrs = *req.AllowedCIDRs
+		}
+		if req.ActorRole != nil {
+			role = *req.ActorRole
+		}
+
+		normalized, msg := h.validateAPIKeyPermissions(r.Context(), tenantID, scopes, batchIDs, markets, cidrs, role)
+		if msg != "" {
+			respondError(w, http.StatusBadRequest, msg)
+			return
+		}
+		if req.AllowedCIDRs != nil {
+			req.AllowedCIDRs = &normalized
+		}
+	}
+
+	if err := h.repo.UpdateAPIKey(r.Context(), keyID, req); err != nil {
 		log.Printf("Failed to update API key: %v", err)
 		respondError(w, http.StatusInternalServerError, "Failed to update API key")
 		return
@@ -248,3 +298,149 @@ func (h *Handler) DeleteAPIKey(w http.ResponseWriter, r *http.Request) {
 		"message": "API key deleted",
 	})
 }
+
+// validateAPIKeyPermissions checks scopes and resource restrictions for a key.
+// Returns the CIDR list normalized to network notation, or a user-facing error message.
+func (h *Handler) validateAPIKeyPermissions(ctx context.Context, tenantID uuid.UUID, scopes []string, batchIDs []uuid.UUID, markets []string, cidrs []string, actorRole string) ([]string, string) {
+	if len(scopes) == 0 {
+		return nil, "At least one scope is required"
+	}
+	for _, scope := range scopes {
+		if !models.IsValidScope(scope) {
+			return nil, fmt.Sprintf("Invalid scope '%s'. Must be one of: %s", scope, strings.Join(models.ValidScopes(), ", "))
+		}
+	}
+
+	for _, batchID := range batchIDs {
+		batch, err := h.repo.GetBatch(ctx, batchID)
+		if err != nil || batch.TenantID != tenantID {
+			return nil, fmt.Sprintf("Batch %s not found", batchID)
+		}
+	}
+
+	for _, market := range markets {
+		if !models.MarketRegion(market).IsValid() {
+			return nil, fmt.Sprintf("Invalid market '%s'. Must be %s", market, models.ValidMarketRegions)
+		}
+	}
+
+	normalized := make([]string, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		// Accept bare IPs as single-host ranges
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil {
+				if ip.To4() != nil {
+					cidr += "/32"
+				} else {
+					cidr += "/128"
+				}
+			}
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Sprintf("Invalid CIDR range '%s'", cidr)
+		}
+		normalized = append(normalized, network.String())
+	}
+
+	if actorRole != "" && !models.IsValidActorRole(actorRole) {
+		return nil, "Invalid actor_role. Must be MANUFACTURER, LOGISTICS, TECHNICIAN, or RECYCLER"
+	}
+
+	return normalized, ""
+}
+
+// GetAPIKeyUsage handles GET /api/v1/api-keys/{id}/usage
+// Returns request counts and error rates per endpoint and per day (?days=30, max 90)
+func (h *Handler) GetAPIKeyUsage(w http.ResponseWriter, r *http.Request) {
+	apiKey, ok := h.getOwnedAPIKey(w, r)
+	if !ok {
+		return
+	}
+
+	days := 30
+	if d, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil && d > 0 {
+		days = d
+	}
+	if days > 90 {
+		days = 90 // Request logs are retained for 90 days
+	}
+
+	summary, err := h.repo.GetAPIKeyUsageSummary(r.Context(), apiKey.ID, days)
+	if err != nil {
+		log.Printf("Failed to get API key usage: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to get API key usage")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"usage": summary,
+	})
+}
+
+// GetAPIKeyAudit handles GET /api/v1/api-keys/{id}/audit
+// Returns the last 100 requests, known client IPs and security alerts
+func (h *Handler) GetAPIKeyAudit(w http.ResponseWriter, r *http.Request) {
+	apiKey, ok := h.getOwnedAPIKey(w, r)
+	if !ok {
+		return
+	}
+
+	requests, err := h.repo.GetRecentAPIRequestLogs(r.Context(), apiKey.ID, 100)
+	if err != nil {
+		log.Printf("Failed to get API request logs: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to get API key audit log")
+		return
+	}
+
+	ips, err := h.repo.ListAPIKeyIPs(r.Context(), apiKey.ID)
+	if err != nil {
+		log.Printf("Failed to list API key IPs: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to get API key audit log")
+		return
+	}
+
+	alerts, err := h.repo.ListAPIKeyAlerts(r.Context(), apiKey.ID, 50)
+	if err != nil {
+		log.Printf("Failed to list API key alerts: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to get API key audit log")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"api_key_id":      apiKey.ID,
+		"recent_requests": requests,
+		"known_ips":       ips,
+		"alerts":          alerts,
+	})
+}
+
+// getOwnedAPIKey loads the API key from the {id} path value and verifies the
+// authenticated tenant owns it. Writes the error response on failure.
+func (h *Handler) getOwnedAPIKey(w http.ResponseWriter, r *http.Request) (*models.APIKey, bool) {
+	keyID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid API key ID")
+		return nil, false
+	}
+
+	tenantIDStr := middleware.GetTenantID(r.Context())
+	if tenantIDStr == "" {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return nil, false
+	}
+	tenantID, _ := uuid.Parse(tenantIDStr)
+
+	apiKey, err := h.repo.GetAPIKeyByID(r.Context(), keyID)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "API key not found")
+		return nil, false
+	}
+	if apiKey.TenantID != tenantID {
+		respondError(w, http.StatusForbidden, "Access denied")
+		return nil, false
+	}
+
+	return apiKey, true
+}
@@ -24,6 +24,7 @@ type AuthHandler struct {
 	repo         *repository.Repository
 	authService  *services.AuthService
 	emailService *services.EmailService
+	loginLimiter *middleware.RateLimiter
 }
 
 // NewAuthHandler creates a new auth handler
@@ -36,6 +37,13 @@ func NewAuthHandler(database *db.DB, repo *repository.Repository, authService *s
 	}
 }
 
+// SetLoginLimiter connects the login rate limiter so lockouts send unlock
+// emails and successful unlocks/password resets clear the lock
+func (h *AuthHandler) SetLoginLimiter(limiter *middleware.RateLimiter) {
+	h.loginLimiter = limiter
+	limiter.OnLockout(h.handleLoginLockout)
+}
+
 // RegisterRequest is the request body for registration
 type RegisterRequest struct {
 	CompanyName string `json:"company_name"`
@@ -348,23 +356,106 @@ func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Update password and clear reset token
-	_, err = h.db.Pool.Exec(r.Context(),
+	// Update password and clear reset token (a reset also lifts any lockout)
+	var email string
+	err = h.db.Pool.QueryRow(r.Context(),
 		`UPDATE public.tenants 
-		 SET password_hash = $1, reset_token = NULL, reset_token_expires = NULL 
-		 WHERE id = $2`,
-		passwordHash, tenantID)
+		 SET password_hash = $1, reset_token = NULL, reset_token_expires = NULL,
+		     unlock_token = NULL, unlock_token_expires = NULL
+		 WHERE id = $2
+		 RETURNING email`,
+		passwordHash, tenantID).Scan(&email)
 	if err != nil {
 		log.Printf("Failed to update password: %v", err)
 		respondError(w, http.StatusInternalServerError, "Failed to reset password")
 		return
 	}
 
+	if h.loginLimiter != nil {
+		h.loginLimiter.Unlock(email)
+	}
+
 	respondJSON(w, http.StatusOK, map[string]string{
 		"message": "Password reset successfully",
 	})
 }
 
+// UnlockAccountRequest is the request to lift a login lockout
+type UnlockAccountRequest struct {
+	Token string `json:"token"`
+}
+
+// UnlockAccount handles POST /api/v1/auth/unlock
+// Lifts a brute-force lockout using the one-time token from the lockout email
+func (h *AuthHandler) UnlockAccount(w http.ResponseWriter, r *http.Request) {
+	var req UnlockAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Token == "" {
+		respondError(w, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	// Consume the unlock token
+	var email string
+	err := h.db.Pool.QueryRow(r.Context(),
+		`UPDATE public.tenants 
+		 SET unlock_token = NULL, unlock_token_expires = NULL 
+		 WHERE unlock_token = $1 AND unlock_token_expires > NOW()
+		 RETURNING email`,
+		req.Token).Scan(&email)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			respondError(w, http.StatusBadRequest, "Invalid or expired unlock token")
+			return
+		}
+		log.Printf("Failed to consume unlock token: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to unlock account")
+		return
+	}
+
+	if h.loginLimiter != nil {
+		h.loginLimiter.Unlock(email)
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{
+		"message": "Account unlocked. You can sign in again.",
+	})
+}
+
+// handleLoginLockout issues an unlock token and emails it to the account owner
+func (h *AuthHandler) handleLoginLockout(email, ip string, until time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	unlockToken, err := h.authService.GenerateResetToken()
+	if err != nil {
+		log.Printf("Failed to generate unlock token: %v", err)
+		return
+	}
+
+	// Only registered accounts get an email; unknown emails stay locked silently
+	tag, err := h.db.Pool.Exec(ctx,
+		`UPDATE public.tenants 
+		 SET unlock_token = $1, unlock_token_expires = $2, last_locked_at = NOW() 
+		 WHERE LOWER(email) = $3`,
+		unlockToken, until, email)
+	if err != nil {
+		log.Printf("Failed to save unlock token: %v", err)
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		return
+	}
+
+	if err := h.emailService.SendAccountLockedEmail(email, unlockToken, ip, until); err != nil {
+		log.Printf("Failed to send account locked email: %v", err)
+	}
+}
+
 // UpdateProfile handles PUT /api/v1/auth/profile
 func (h *AuthHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	tenantIDStr := middleware.GetTenantID(r.Context())
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"time"
 
+	"exportready-battery/internal/middleware"
 	"exportready-battery/internal/models"
 	"exportready-battery/internal/repository"
 	"exportready-battery/internal/services"
@@ -18,7 +19,7 @@ import (
 // Maximum passports allowed in a single PDF/QR download to prevent memory exhaustion
 const maxDownloadLimit = 500
 
-// CreateBatch handles POST /api/v1/batches with dual-mode validation
+// CreateBatch handles POST /api/v1/batches, validated by the rule pack of each target market
 func (h *Handler) CreateBatch(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateBatchRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -36,181 +37,37 @@ func (h *Handler) CreateBatch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Default to GLOBAL if not specified
-	if req.MarketRegion == "" {
-		req.MarketRegion = models.MarketRegionGlobal
-	}
-
-	// Validate market region
-	if !req.MarketRegion.IsValid() {
-		respondError(w, http.StatusBadRequest, "Invalid market_region. Must be INDIA, EU, or GLOBAL")
+	// Target markets: markets, or market_region (default GLOBAL: INDIA and EU)
+	markets, region, err := models.ResolveMarkets(req.MarketRegion, req.Markets)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	legacyGlobal := len(req.Markets) == 0 && region == models.MarketRegionGlobal
+	req.Markets, req.MarketRegion = markets, region
 
-	// ===== DUAL-MODE VALIDATION =====
-
-	// EU Mode: Carbon Footprint is MANDATORY
-	if req.MarketRegion == models.MarketRegionEU {
-		if req.Specs.CarbonFootprint == "" {
-			respondError(w, http.StatusBadRequest, "EU compliance requires carbon_footprint")
-			return
-		}
-		// EU Mode: Certifications should include CE
-		hasCE := false
-		for _, cert := range req.Specs.Certifications {
-			if cert == "CE" {
-				hasCE = true
-				break
-			}
-		}
-		if !hasCE {
-			log.Printf("Warning: EU batch created without CE certification")
-		}
-	}
-
-	// India Mode: Validate cell_source if provided
-	if req.MarketRegion == models.MarketRegionIndia {
-		if req.CellSource != "" && req.CellSource != "IMPORTED" && req.CellSource != "DOMESTIC" {
-			respondError(w, http.StatusBadRequest, "cell_source must be IMPORTED or DOMESTIC")
-			return
-		}
-
-		// ===== SECURITY: FORCE OVERRIDES FOR IMPORTED CELLS =====
-		// Prevent API manipulation of PLI flags for imported battery cells
-		if req.CellSource == "IMPORTED" {
-			// Force all imported cells to be PLI ineligible
-			if req.PLICompliant {
-				log.Printf("⚠️ SECURITY OVERRIDE: Forced PLICompliant=false for IMPORTED cells (TenantID=%s, BatchName=%s)",
-					req.TenantID, req.BatchName)
-				req.PLICompliant = false
-			}
-
-			// Force DVA to zero for imports (no domestic value addition)
-			if req.DomesticValueAdd > 0 {
-				log.Printf("⚠️ SECURITY OVERRIDE: Forced DomesticValueAdd=0 for IMPORTED cells (was %.2f%%, TenantID=%s)",
-					req.DomesticValueAdd, req.TenantID)
-				req.DomesticValueAdd = 0
-			}
-
-			// Block audited DVA certificates for imported cells
-			if req.AuditedDomesticValueAdd != nil {
-				log.Printf("⚠️ SECURITY OVERRIDE: Blocked AuditedDVA upload for IMPORTED cells (TenantID=%s)", req.TenantID)
-				req.AuditedDomesticValueAdd = nil
-			}
-
-			// Force DVA source to ESTIMATED (no CA audits for imports)
-			if req.DVASource != "" && req.DVASource != services.DVASourceEstimated {
-				log.Printf("⚠️ SECURITY OVERRIDE: Forced DVASource=ESTIMATED for IMPORTED cells (was %s, TenantID=%s)",
-					req.DVASource, req.TenantID)
-			}
-			req.DVASource = services.DVASourceEstimated
-		}
-
-		// ===== SECURITY: STRICT PLI VALIDATION FOR DOMESTIC CELLS =====
-		// Enforce PLI scheme minimum DVA requirement
-		if req.CellSource == "DOMESTIC" && req.PLICompliant {
-			// PLI Scheme requires minimum 50% DVA
-			if req.DomesticValueAdd < 50 {
-				respondError(w, http.StatusBadRequest,
-					fmt.Sprintf("PLI Scheme requires minimum 50%% Domestic Value Add. Current DVA: %.2f%%", req.DomesticValueAdd))
-				return
-			}
-			log.Printf("✅ PLI Eligibility Check Passed: DVA=%.2f%% (TenantID=%s)", req.DomesticValueAdd, req.TenantID)
-		}
-
-		// Validate DVA source
-		dvaSourceResult := h.validationService.ValidateDVASource(req.DVASource)
-		if !dvaSourceResult.Valid {
-			respondError(w, http.StatusBadRequest, dvaSourceResult.Message)
-			return
-		}
-
-		// Default to ESTIMATED if not provided
-		if req.DVASource == "" {
-			req.DVASource = services.DVASourceEstimated
-		}
-
-		// Calculate DVA based on source
-		var calculatedDVA float64
-		if req.DVASource == services.DVASourceAudited {
-			// AUDITED: Use provided audited value, skip calculation
-			if req.AuditedDomesticValueAdd != nil {
-				calculatedDVA = *req.AuditedDomesticValueAdd
-				req.DomesticValueAdd = calculatedDVA
-			} else {
-				respondError(w, http.StatusBadRequest, "Audited DVA mode requires audited_domestic_value_add value")
-				return
-			}
-			log.Printf("DVA AUDITED Mode: AuditedDVA=%.2f%%", calculatedDVA)
-		} else {
-			// ESTIMATED: Calculate DVA server-side from specs financial fields
-			// Never trust client-provided domestic_value_add to prevent PLI fraud
-			if req.Specs.SalePriceINR > 0 {
-				calculatedDVA = ((req.Specs.SalePriceINR - req.Specs.ImportCostINR) / req.Specs.SalePriceINR) * 100
-				if calculatedDVA < 0 {
-					calculatedDVA = 0
-				}
-			}
-			// Override any client-provided DVA with server-calculated value
-			req.DomesticValueAdd = calculatedDVA
-			log.Printf("DVA ESTIMATED Mode: SalePrice=%.2f, ImportCost=%.2f, Calculated DVA=%.2f%%",
-				req.Specs.SalePriceINR, req.Specs.ImportCostINR, calculatedDVA)
-		}
-
-		// India Mode + IMPORTED: Require customs declaration fields
-		if req.CellSource == "IMPORTED" {
-			if req.BillOfEntryNo == "" || req.CountryOfOrigin == "" {
-				respondError(w, http.StatusBadRequest,
-					"Imported batches must include Bill of Entry and Country of Origin per Customs regulations")
-				return
-			}
-			// For pure imports with no local value add (ESTIMATED mode only)
-			if req.DVASource == services.DVASourceEstimated {
-				if req.Specs.SalePriceINR == 0 || req.Specs.ImportCostINR >= req.Specs.SalePriceINR {
-					req.DomesticValueAdd = 0
-				}
-			}
-		} else if req.CellSource == "DOMESTIC" {
-			// India Mode + DOMESTIC: Require value add > 0
-			if req.DomesticValueAdd <= 0 {
-				respondError(w, http.StatusBadRequest, "Domestic batches must have Domestic Value Add > 0%. Please enter Sale Price and Import Cost.")
-				return
-			}
-		}
-
-		// PLI Compliance Validation using validation service
-		if err := h.validationService.ValidatePLICompliance(req.DVASource, req.AuditedDomesticValueAdd, req.DomesticValueAdd, req.PLICompliant); err != nil {
-			respondError(w, http.StatusBadRequest, err.Error())
-			return
-		}
-	}
-
-	// Validate IEC Code format if provided (India: 10 digits)
-	tenantID := req.TenantID
-	tenant, err := h.repo.GetTenant(r.Context(), tenantID)
-	if err == nil && tenant.IECCode != "" {
-		iecResult := h.validationService.ValidateIECCode(tenant.IECCode)
-		if !iecResult.Valid {
-			log.Printf("WARNING: Tenant %s has invalid IEC Code format: %s - %s",
-				tenant.CompanyName, tenant.IECCode, iecResult.Message)
-		}
+	warnings, ok := checkBatchSpec(w, &req.Specs)
+	if !ok {
+		return
 	}
 
-	// For IMPORTED cells, ensure tenant has valid IEC
-	if req.MarketRegion == models.MarketRegionIndia && req.CellSource == "IMPORTED" {
-		if err == nil && tenant.IECCode == "" {
-			respondError(w, http.StatusBadRequest, "IEC Code is required in Organization Settings for importing battery cells")
+	tenant, err := h.repo.GetTenant(r.Context(), req.TenantID)
+	if err != nil {
+		if err.Error() == "tenant not found" {
+			respondError(w, http.StatusBadRequest, "Tenant not found")
 			return
 		}
+		log.Printf("Failed to get tenant: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to create batch")
+		return
 	}
 
-	// Validate HSN Code for India market
-	if req.MarketRegion == models.MarketRegionIndia {
-		hsnResult := h.validationService.ValidateHSNCode(req.HSNCode)
-		if !hsnResult.Valid {
-			respondError(w, http.StatusBadRequest, hsnResult.Message)
-			return
-		}
+	// ===== MARKET RULE PACKS =====
+	// Each target market checks its own fields, certifications and documents
+	// (India also derives the DVA server-side)
+	validation, ok := h.validateNewBatch(w, &req, tenant, legacyGlobal)
+	if !ok {
+		return
 	}
 
 	// Parse customs date if provided
@@ -225,23 +82,22 @@ func (h *Handler) CreateBatch(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create the batch
-	log.Printf("DEBUG Handler CreateBatch: TenantID=%s, BatchName=%s, MarketRegion=%s, Specs=%+v",
-		req.TenantID, req.BatchName, req.MarketRegion, req.Specs)
+	log.Printf("DEBUG Handler CreateBatch: TenantID=%s, BatchName=%s, Markets=%v, Specs=%+v",
+		req.TenantID, req.BatchName, req.Markets, req.Specs)
 
 	batch, err := h.repo.CreateBatch(r.Context(), repository.CreateBatchRequest{
-		TenantID:          req.TenantID,
-		BatchName:         req.BatchName,
-		Specs:             req.Specs,
-		MarketRegion:      req.MarketRegion,
-		PLICompliant:      req.PLICompliant,
-		DomesticValueAdd:  req.DomesticValueAdd,
-		CellSource:        req.CellSource,
-		BillOfEntryNo:     req.BillOfEntryNo,
-		CountryOfOrigin:   req.CountryOfOrigin,
-		CustomsDate:       customsDate,
-		HSNCode:           req.HSNCode,
-		DVASource:         req.DVASource,
-		PLICertificateURL: req.PLICertificateURL,
+		TenantID:         req.TenantID,
+		BatchName:        req.BatchName,
+		Specs:            req.Specs,
+		MarketRegion:     req.MarketRegion,
+		Markets:          req.Markets,
+		PLICompliant:     req.PLICompliant,
+		DomesticValueAdd: req.DomesticValueAdd,
+		CellSource:       req.CellSource,
+		BillOfEntryNo:    req.BillOfEntryNo,
+		CountryOfOrigin:  req.CountryOfOrigin,
+		CustomsDate:      customsDate,
+		HSNCode:          req.HSNCode,
 	})
 	if err != nil {
 		log.Printf("Failed to create batch: %v", err)
@@ -249,10 +105,56 @@ func (h *Handler) CreateBatch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respondJSON(w, http.StatusCreated, models.CreateBatchResponse{Batch: batch})
+	respondJSON(w, http.StatusCreated, models.CreateBatchResponse{
+		Batch: batch, Warnings: warnings, MarketWarnings: validation.Warnings,
+	})
+}
+
+// validateNewBatch runs the market rule packs on a new batch (see
+// ValidationService.ValidateNewBatch). On a refusal it writes a 400 with the
+// failed checks; otherwise it returns the warnings.
+func (h *Handler) validateNewBatch(w http.ResponseWriter, req *models.CreateBatchRequest, tenant *models.Tenant, legacyGlobal bool) (*services.MarketValidation, bool) {
+	validation, err := h.validationService.ValidateNewBatch(req, tenant, legacyGlobal)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	if len(validation.Errors) > 0 {
+		first := validation.Errors[0]
+		respondJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":           fmt.Sprintf("%s compliance: %s", first.Market, first.Message),
+			"checks":          validation.Errors,
+			"market_warnings": validation.Warnings,
+		})
+		return nil, false
+	}
+	return validation, true
+}
+
+// checkBatchSpec derives the typed ratings of a spec and runs the quantity,
+// chemistry composition and due diligence rules. On errors it writes a 400
+// listing them per field; otherwise it returns the warnings.
+func checkBatchSpec(w http.ResponseWriter, spec *models.BatchSpec) ([]models.SpecIssue, bool) {
+	return respondSpecIssues(w, models.ValidateBatchSpec(spec))
+}
+
+// respondSpecIssues writes a 400 listing the errors among issues per field, or
+// returns the warnings when there are none
+func respondSpecIssues(w http.ResponseWriter, issues []models.SpecIssue) ([]models.SpecIssue, bool) {
+	errs, warnings := models.SplitSpecIssues(issues)
+	if len(errs) > 0 {
+		respondJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":    fmt.Sprintf("Invalid specs.%s: %s", errs[0].Field, errs[0].Message),
+			"issues":   errs,
+			"warnings": warnings,
+		})
+		return nil, false
+	}
+	return warnings, true
 }
 
 // ListBatches handles GET /api/v1/batches?tenant_id=xxx&page=1&limit=50
+// Also accepts cursor, status, market_region, created_after, created_before and chemistry (see listParams)
 func (h *Handler) ListBatches(w http.ResponseWriter, r *http.Request) {
 	tenantIDStr := r.URL.Query().Get("tenant_id")
 	if tenantIDStr == "" {
@@ -266,39 +168,36 @@ func (h *Handler) ListBatches(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse pagination params
-	limit := 50 // Default
-	page := 1
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if parsed, err := parseInt(limitStr); err == nil && parsed > 0 && parsed <= 200 {
-			limit = parsed
-		}
+	params, err := parseListParams(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
 	}
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if parsed, err := parseInt(pageStr); err == nil && parsed > 0 {
-			page = parsed
-		}
+	if !validBatchStatus(params.Status) {
+		respondError(w, http.StatusBadRequest, "Invalid status. Must be DRAFT, ACTIVE, or ARCHIVED")
+		return
+	}
+	page, err := params.pageRequest(repository.SortBatchesNewest, true)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid cursor")
+		return
 	}
-	offset := (page - 1) * limit
 
-	batches, totalCount, err := h.repo.ListBatches(r.Context(), tenantID, limit, offset)
+	filter := repository.BatchFilter{
+		Status:        params.Status,
+		MarketRegions: params.MarketRegions,
+		Chemistry:     params.Chemistry,
+		CreatedAfter:  params.CreatedAfter,
+		CreatedBefore: params.CreatedBefore,
+	}
+	batches, info, err := h.repo.ListBatches(r.Context(), tenantID, filter, page)
 	if err != nil {
 		log.Printf("Failed to list batches: %v", err)
 		respondError(w, http.StatusInternalServerError, "Failed to list batches")
 		return
 	}
 
-	totalPages := (totalCount + limit - 1) / limit
-
-	respondJSON(w, http.StatusOK, map[string]interface{}{
-		"batches":     batches,
-		"count":       len(batches),
-		"total":       totalCount,
-		"page":        page,
-		"limit":       limit,
-		"total_pages": totalPages,
-		"has_more":    page < totalPages,
-	})
+	respondJSON(w, http.StatusOK, listResponse("batches", batches, len(batches), page, info))
 }
 
 // GetBatch handles GET /api/v1/batches/{id}
@@ -481,15 +380,20 @@ func (h *Handler) ExportBatchCSV(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.writeBatchCSV(w, r, batch)
+}
+
+// writeBatchCSV streams every passport in a batch as a CSV attachment
+func (h *Handler) writeBatchCSV(w http.ResponseWriter, r *http.Request, batch *models.Batch) {
 	// Get passport count
-	count, _ := h.repo.CountPassportsByBatch(r.Context(), batchID)
+	count, _ := h.repo.CountPassportsByBatch(r.Context(), batch.ID)
 	if count == 0 {
 		respondError(w, http.StatusNotFound, "No passports found for this batch")
 		return
 	}
 
 	// Get all passports
-	passports, err := h.repo.GetPassportsByBatch(r.Context(), batchID, count, 0)
+	passports, err := h.repo.GetPassportsByBatch(r.Context(), batch.ID, count, 0)
 	if err != nil {
 		log.Printf("Failed to get passports: %v", err)
 		respondError(w, http.StatusInternalServerError, "Failed to retrieve passports")
@@ -515,6 +419,7 @@ func (h *Handler) ExportBatchCSV(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetBatchPassports handles GET /api/v1/batches/{id}/passports?page=1&limit=50
+// Also accepts cursor, status, serial_prefix, created_after and created_before (see listParams)
 func (h *Handler) GetBatchPassports(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	batchID, err := uuid.Parse(idStr)
@@ -523,42 +428,46 @@ func (h *Handler) GetBatchPassports(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse pagination params
-	limit := 50 // Default
-	page := 1
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if parsed, err := parseInt(limitStr); err == nil && parsed > 0 && parsed <= 100 {
-			limit = parsed
-		}
+	tenantID, err := uuid.Parse(middleware.GetTenantID(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
 	}
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if parsed, err := parseInt(pageStr); err == nil && parsed > 0 {
-			page = parsed
-		}
+
+	params, err := parseListParams(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !validPassportStatus(params.Status) {
+		respondError(w, http.StatusBadRequest, "Invalid status")
+		return
+	}
+	if params.Limit > 100 {
+		params.Limit = 100
 	}
-	offset := (page - 1) * limit
 
-	// Get total count
-	totalCount, _ := h.repo.CountPassportsByBatch(r.Context(), batchID)
+	filter := repository.PassportFilter{
+		BatchID:       &batchID,
+		Status:        params.Status,
+		SerialPrefix:  params.SerialPrefix,
+		CreatedAfter:  params.CreatedAfter,
+		CreatedBefore: params.CreatedBefore,
+	}
+	page, err := params.pageRequest(filter.Sort(), true)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
 
-	passports, err := h.repo.GetPassportsByBatch(r.Context(), batchID, limit, offset)
+	passports, info, err := h.repo.ListPassports(r.Context(), tenantID, filter, page)
 	if err != nil {
 		log.Printf("Failed to get passports: %v", err)
 		respondError(w, http.StatusInternalServerError, "Failed to retrieve passports")
 		return
 	}
 
-	totalPages := (totalCount + limit - 1) / limit
-
-	respondJSON(w, http.StatusOK, map[string]interface{}{
-		"passports":   passports,
-		"count":       len(passports),
-		"total":       totalCount,
-		"page":        page,
-		"limit":       limit,
-		"total_pages": totalPages,
-		"has_more":    page < totalPages,
-	})
+	respondJSON(w, http.StatusOK, listResponse("passports", passports, len(passports), page, info))
 }
 
 // DuplicateBatch handles POST /api/v1/batches/{id}/duplicate
@@ -592,6 +501,7 @@ func (h *Handler) DuplicateBatch(w http.ResponseWriter, r *http.Request) {
 		BatchName:        newBatchName,
 		Specs:            originalBatch.Specs,
 		MarketRegion:     originalBatch.MarketRegion,
+		Markets:          originalBatch.Markets,
 		PLICompliant:     originalBatch.PLICompliant,
 		DomesticValueAdd: originalBatch.DomesticValueAdd,
 		CellSource:       originalBatch.CellSource,
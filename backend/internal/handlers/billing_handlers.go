@@ -8,6 +8,7 @@ import (
 
 	"exportready-battery/internal/middleware"
 	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
 
 	"github.com/google/uuid"
 )
@@ -113,6 +114,7 @@ func (h *Handler) GetBalance(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetTransactions handles GET /api/v1/billing/transactions
+// Accepts limit, cursor, created_after and created_before (see listParams)
 func (h *Handler) GetTransactions(w http.ResponseWriter, r *http.Request) {
 	tenantIDStr := middleware.GetTenantID(r.Context())
 	if tenantIDStr == "" {
@@ -126,20 +128,34 @@ func (h *Handler) GetTransactions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	transactions, err := h.repo.ListTransactions(r.Context(), tenantID, 50)
+	params, err := parseListParams(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := params.pageRequest(repository.SortTransactionsNewest, false)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+
+	filter := repository.TransactionFilter{
+		CreatedAfter:  params.CreatedAfter,
+		CreatedBefore: params.CreatedBefore,
+	}
+	transactions, info, err := h.repo.ListTransactions(r.Context(), tenantID, filter, page)
 	if err != nil {
 		log.Printf("Failed to get transactions: %v", err)
 		respondError(w, http.StatusInternalServerError, "Failed to retrieve transactions")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]interface{}{
-		"transactions": transactions,
-		"count":        len(transactions),
-	})
+	respondJSON(w, http.StatusOK, listResponse("transactions", transactions, len(transactions), page, info))
 }
 
 // ActivateBatch handles POST /api/v1/batches/{id}/activate
+// With ?enforce_compliance=true, a batch failing a blocking check of its market is
+// not activated and the checklist is returned instead
 func (h *Handler) ActivateBatch(w http.ResponseWriter, r *http.Request) {
 	// Get batch ID from path
 	idStr := r.PathValue("id")
@@ -174,10 +190,44 @@ func (h *Handler) ActivateBatch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	balance, ok := h.activateBatch(w, r, tenantID, batch)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"message":       "Batch activated successfully",
+		"batch_id":      batchID,
+		"quota_balance": balance,
+	})
+}
+
+// activateBatch spends one activation quota unit to move a batch to ACTIVE.
+// Shared by the dashboard and external API. Writes the error response on failure
+// and returns the remaining quota balance on success.
+func (h *Handler) activateBatch(w http.ResponseWriter, r *http.Request, tenantID uuid.UUID, batch *models.Batch) (int, bool) {
 	// Check if already active
 	if batch.Status == models.BatchStatusActive {
 		respondError(w, http.StatusBadRequest, "Batch is already active")
-		return
+		return 0, false
+	}
+
+	// Optional readiness gate, checked before any quota is spent
+	if r.URL.Query().Get("enforce_compliance") == "true" {
+		tenant, err := h.repo.GetTenant(r.Context(), tenantID)
+		if err != nil {
+			log.Printf("Failed to get tenant for compliance check: %v", err)
+			respondError(w, http.StatusInternalServerError, "Failed to check compliance")
+			return 0, false
+		}
+		report := h.complianceService.Evaluate(batch, tenant, nil)
+		if !report.Ready {
+			respondJSON(w, http.StatusConflict, map[string]interface{}{
+				"error":      fmt.Sprintf("Batch fails %d blocking compliance check(s) for %s", report.BlockingFailures, report.Market),
+				"compliance": report,
+			})
+			return 0, false
+		}
 	}
 
 	// Check quota balance
@@ -185,12 +235,12 @@ func (h *Handler) ActivateBatch(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Printf("Failed to get quota balance: %v", err)
 		respondError(w, http.StatusInternalServerError, "Failed to check quota")
-		return
+		return 0, false
 	}
 
 	if balance < 1 {
 		respondError(w, http.StatusPaymentRequired, "Insufficient quota. Please purchase more activation slots.")
-		return
+		return 0, false
 	}
 
 	// Deduct quota (atomic operation)
@@ -202,25 +252,26 @@ func (h *Handler) ActivateBatch(w http.ResponseWriter, r *http.Request) {
 		} else {
 			respondError(w, http.StatusInternalServerError, "Failed to deduct quota")
 		}
-		return
+		return 0, false
 	}
 
 	// Set batch status to ACTIVE
-	err = h.repo.SetBatchStatus(r.Context(), batchID, models.BatchStatusActive)
+	err = h.repo.SetBatchStatus(r.Context(), batch.ID, models.BatchStatusActive)
 	if err != nil {
 		log.Printf("Failed to activate batch: %v", err)
 		// Try to refund quota if activation fails
 		_ = h.repo.AddQuota(r.Context(), tenantID, 1)
 		respondError(w, http.StatusInternalServerError, "Failed to activate batch")
-		return
+		return 0, false
 	}
+	batch.Status = models.BatchStatusActive
 
 	// Log transaction
 	tx := &models.Transaction{
 		TenantID:    tenantID,
 		Description: fmt.Sprintf("Batch Activation: %s", batch.BatchName),
 		QuotaChange: -1,
-		BatchID:     &batchID,
+		BatchID:     &batch.ID,
 	}
 	err = h.repo.CreateTransaction(r.Context(), tx)
 	if err != nil {
@@ -228,11 +279,17 @@ func (h *Handler) ActivateBatch(w http.ResponseWriter, r *http.Request) {
 		// Non-critical, don't fail the request
 	}
 
-	respondJSON(w, http.StatusOK, map[string]interface{}{
-		"message":       "Batch activated successfully",
-		"batch_id":      batchID,
-		"quota_balance": balance - 1,
-	})
+	passportCount, _ := h.repo.CountPassportsByBatch(r.Context(), batch.ID)
+	if err := h.repo.PublishTenantEvent(r.Context(), tenantID, models.EventBatchActivated, models.BatchActivatedData{
+		BatchID:       batch.ID,
+		BatchName:     batch.BatchName,
+		PassportCount: passportCount,
+		QuotaBalance:  balance - 1,
+	}); err != nil {
+		log.Printf("Failed to publish batch activation event: %v", err)
+	}
+
+	return balance - 1, true
 }
 
 // TopUpQuota handles POST /api/v1/billing/top-up (MOCK for now)
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"exportready-battery/internal/middleware"
+	"exportready-battery/internal/models"
 	"exportready-battery/internal/repository"
 
 	"github.com/google/uuid"
@@ -101,6 +102,14 @@ func (h *Handler) BulkUpdateStatus(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("✅ Bulk status update: %d passports → %s (tenant: %s)", updated, req.Status, tenantIDStr[:8])
 
+	if err := h.repo.PublishTenantEvent(r.Context(), tenantID, models.EventPassportsBulkUpdated, models.PassportsBulkUpdatedData{
+		PassportIDs: passportIDs,
+		Status:      req.Status,
+		Updated:     updated,
+	}); err != nil {
+		log.Printf("Failed to publish bulk update event: %v", err)
+	}
+
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"updated": updated,
 		"status":  req.Status,
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"exportready-battery/internal/middleware"
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/services"
+
+	"github.com/google/uuid"
+)
+
+const maxFactorImportSize = 10 << 20 // 10MB
+
+// CarbonHandler handles the emission factor library and batch carbon footprint
+// calculations
+type CarbonHandler struct {
+	service *services.CarbonService
+}
+
+// NewCarbonHandler creates a new carbon footprint handler
+func NewCarbonHandler(service *services.CarbonService) *CarbonHandler {
+	return &CarbonHandler{service: service}
+}
+
+// respondCarbonError maps carbon service errors to HTTP responses
+func respondCarbonError(w http.ResponseWriter, err error, notFound, action string) {
+	var importErr *services.FactorImportError
+	switch {
+	case errors.As(err, &importErr):
+		respondJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":  importErr.Error(),
+			"errors": importErr.Errors,
+		})
+	case errors.Is(err, services.ErrCarbonInvalid):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrCarbonNotFound):
+		respondError(w, http.StatusNotFound, notFound)
+	case errors.Is(err, services.ErrCarbonConflict):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("Failed to %s: %v", action, err)
+		respondError(w, http.StatusInternalServerError, "Failed to "+action)
+	}
+}
+
+// carbonTenantID reads the authenticated tenant
+func carbonTenantID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return uuid.Nil, false
+	}
+	return tenantID, true
+}
+
+// ImportFactorsRequest is a JSON factor import
+type ImportFactorsRequest struct {
+	Factors []*models.EmissionFactor `json:"factors"`
+}
+
+// ImportFactors handles POST /api/v1/emission-factors
+// Accepts a CSV upload ('file' field: key, name, unit, kg_co2e_per_unit and
+// optional stage, source, region) or a JSON body {"factors": [...]}.
+// Existing keys are replaced; any invalid row rejects the whole import.
+func (h *CarbonHandler) ImportFactors(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := carbonTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	var factors []*models.EmissionFactor
+	rowOffset := 1
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(maxFactorImportSize); err != nil {
+			respondError(w, http.StatusBadRequest, "Failed to parse form data")
+			return
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "No file uploaded. Use 'file' field in multipart form")
+			return
+		}
+		defer file.Close()
+
+		factors, err = services.ParseEmissionFactorsCSV(file)
+		if err != nil {
+			respondCarbonError(w, err, "", "parse emission factors")
+			return
+		}
+		rowOffset = 2 // Header is row 1
+	} else {
+		var req ImportFactorsRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxFactorImportSize)).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		factors = req.Factors
+	}
+
+	imported, err := h.service.ImportFactors(r.Context(), tenantID, factors, rowOffset)
+	if err != nil {
+		respondCarbonError(w, err, "", "import emission factors")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"imported": imported,
+		"factors":  factors,
+	})
+}
+
+// ListFactors handles GET /api/v1/emission-factors
+func (h *CarbonHandler) ListFactors(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := carbonTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	factors, err := h.service.ListFactors(r.Context(), tenantID)
+	if err != nil {
+		respondCarbonError(w, err, "", "list emission factors")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"factors": factors,
+		"count":   len(factors),
+	})
+}
+
+// DeleteFactor handles DELETE /api/v1/emission-factors/{id}
+func (h *CarbonHandler) DeleteFactor(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := carbonTenantID(w, r)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid emission factor ID")
+		return
+	}
+
+	if err := h.service.DeleteFactor(r.Context(), tenantID, id); err != nil {
+		respondCarbonError(w, err, "Emission factor not found", "delete emission factor")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Emission factor deleted"})
+}
+
+// CalculateCarbonFootprint handles POST /api/v1/batches/{id}/carbon-footprint?dry_run=true
+// Calculates the footprint of one battery from its bill of materials and energy
+// inputs and attaches it to the batch: carbon_footprint (kg CO2e/kWh) and
+// carbon_footprint_detail in the specs, and the full report. dry_run only returns it;
+// batches past DRAFT accept dry runs only.
+func (h *CarbonHandler) CalculateCarbonFootprint(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := carbonTenantID(w, r)
+	if !ok {
+		return
+	}
+	batchID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid batch ID format")
+		return
+	}
+
+	var input models.CarbonCalculationInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	report, err := h.service.Calculate(r.Context(), tenantID, batchID, middleware.GetEmail(r.Context()), input, dryRun)
+	if err != nil {
+		respondCarbonError(w, err, "Batch not found", "calculate carbon footprint")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
+
+// GetCarbonFootprint handles GET /api/v1/batches/{id}/carbon-footprint
+// Returns the calculation report attached to the batch
+func (h *CarbonHandler) GetCarbonFootprint(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := carbonTenantID(w, r)
+	if !ok {
+		return
+	}
+	batchID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid batch ID format")
+		return
+	}
+
+	report, err := h.service.Report(r.Context(), tenantID, batchID)
+	if err != nil {
+		respondCarbonError(w, err, "Batch not found", "get carbon footprint")
+		return
+	}
+	if report == nil {
+		respondError(w, http.StatusNotFound, "No carbon footprint calculated for this batch")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"exportready-battery/internal/middleware"
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// ComplianceHandler handles per-market compliance readiness checks
+type ComplianceHandler struct {
+	service *services.ComplianceService
+}
+
+// NewComplianceHandler creates a new compliance handler
+func NewComplianceHandler(service *services.ComplianceService) *ComplianceHandler {
+	return &ComplianceHandler{service: service}
+}
+
+// GetCompliance handles GET /api/v1/batches/{id}/compliance?market=EU
+// Returns the scored checklist of mandatory (blocking) and advisory items for
+// the market (or comma-separated markets); without ?market= the batch's own
+// markets are used.
+func (h *ComplianceHandler) GetCompliance(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	batchID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid batch ID format")
+		return
+	}
+
+	report, err := h.service.Check(r.Context(), tenantID, batchID, r.URL.Query().Get("market"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrComplianceInvalid):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrComplianceNotFound):
+			respondError(w, http.StatusNotFound, "Batch not found")
+		default:
+			log.Printf("Failed to check compliance: %v", err)
+			respondError(w, http.StatusInternalServerError, "Failed to check compliance")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
+
+// ListMarketRules handles GET /api/v1/markets
+// Lists the rule pack of every market: required fields, certifications and documents
+func (h *ComplianceHandler) ListMarketRules(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, map[string]interface{}{"markets": services.MarketRulePacks()})
+}
+
+// ListBatteryCategories handles GET /api/v1/battery-categories
+// Lists the EU obligations of every battery category: passport, carbon
+// footprint deadline, labelling and collection targets
+func (h *ComplianceHandler) ListBatteryCategories(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, map[string]interface{}{"categories": models.AllBatteryCategoryRules()})
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"exportready-battery/internal/middleware"
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+	"exportready-battery/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// CustodyHandler handles the chain-of-custody ledger: transfers from the
+// dashboard and acceptance by the receiving party
+type CustodyHandler struct {
+	service *services.CustodyService
+}
+
+// NewCustodyHandler creates a new custody handler
+func NewCustodyHandler(service *services.CustodyService) *CustodyHandler {
+	return &CustodyHandler{service: service}
+}
+
+// respondCustodyError maps custody service errors to HTTP responses
+func respondCustodyError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, services.ErrCustodyInvalid):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrCustodyNotFound):
+		respondError(w, http.StatusNotFound, "Custody transfer not found")
+	case errors.Is(err, services.ErrCustodyConflict):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("Failed to %s: %v", action, err)
+		respondError(w, http.StatusInternalServerError, "Failed to "+action)
+	}
+}
+
+// custodyTenantID reads the authenticated tenant
+func custodyTenantID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return uuid.Nil, false
+	}
+	return tenantID, true
+}
+
+// TransferPassport handles POST /api/v1/passports/{uuid}/custody/transfers
+// Hands one passport to a receiving party, pending their acceptance
+func (h *CustodyHandler) TransferPassport(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := custodyTenantID(w, r)
+	if !ok {
+		return
+	}
+	passportID, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid passport UUID")
+		return
+	}
+
+	var req services.CreateTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	req.PassportIDs = []uuid.UUID{passportID}
+
+	h.createTransfer(w, r, tenantID, req)
+}
+
+// BulkTransfer handles POST /api/v1/custody/transfers
+// Hands up to 1000 passports (a distributor pallet) to one receiving party,
+// accepted together through one link
+func (h *CustodyHandler) BulkTransfer(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := custodyTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.CreateTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	h.createTransfer(w, r, tenantID, req)
+}
+
+func (h *CustodyHandler) createTransfer(w http.ResponseWriter, r *http.Request, tenantID uuid.UUID, req services.CreateTransferRequest) {
+	result, err := h.service.CreateTransfer(r.Context(), tenantID, middleware.GetEmail(r.Context()), req)
+	if err != nil {
+		respondCustodyError(w, err, "create custody transfer")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, result)
+}
+
+// ListTransfers handles GET /api/v1/custody/transfers?status=PENDING&limit=20&cursor=
+func (h *CustodyHandler) ListTransfers(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := custodyTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	params, err := parseListParams(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	switch params.Status {
+	case "", models.TransferStatusPending, models.TransferStatusAccepted, models.TransferStatusRejected, models.TransferStatusCancelled:
+	default:
+		respondError(w, http.StatusBadRequest, "Invalid status. Must be PENDING, ACCEPTED, REJECTED or CANCELLED")
+		return
+	}
+	page, err := params.pageRequest(repository.SortTransfersNewest, false)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+
+	transfers, info, err := h.service.ListTransfers(r.Context(), tenantID, params.Status, page)
+	if err != nil {
+		respondCustodyError(w, err, "list custody transfers")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, listResponse("transfers", transfers, len(transfers), page, info))
+}
+
+// CancelTransfer handles POST /api/v1/custody/transfers/{id}/cancel
+// Withdraws every pending transfer in the transfer's group
+func (h *CustodyHandler) CancelTransfer(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := custodyTenantID(w, r)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid transfer ID")
+		return
+	}
+
+	cancelled, err := h.service.CancelTransfer(r.Context(), tenantID, id)
+	if err != nil {
+		respondCustodyError(w, err, "cancel custody transfer")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"cancelled": cancelled})
+}
+
+// GetPassportCustody handles GET /api/v1/passports/{uuid}/custody?at=2026-03-15
+// Returns the ownership timeline and current owner; with at, also who held the
+// battery at that time
+func (h *CustodyHandler) GetPassportCustody(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := custodyTenantID(w, r)
+	if !ok {
+		return
+	}
+	passportID, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid passport UUID")
+		return
+	}
+	at, err := parseListTime(r.URL.Query().Get("at"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid at. Use RFC 3339 or YYYY-MM-DD")
+		return
+	}
+
+	timeline, err := h.service.Timeline(r.Context(), tenantID, passportID, at)
+	if err != nil {
+		if errors.Is(err, services.ErrCustodyNotFound) {
+			respondError(w, http.StatusNotFound, "Passport not found")
+			return
+		}
+		respondCustodyError(w, err, "get custody timeline")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, timeline)
+}
+
+// GetAcceptance handles GET /api/v1/custody/accept?token=
+// Public: the acceptance token from the email is the credential
+func (h *CustodyHandler) GetAcceptance(w http.ResponseWriter, r *http.Request) {
+	acceptance, err := h.service.GetAcceptance(r.Context(), r.URL.Query().Get("token"))
+	if err != nil {
+		respondCustodyError(w, err, "get custody transfer")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, acceptance)
+}
+
+// RespondToTransferRequest is the receiving party's answer to a transfer
+type RespondToTransferRequest struct {
+	Token    string `json:"token"`
+	Decision string `json:"decision"`       // ACCEPT or REJECT
+	Note     string `json:"note,omitempty"` // e.g. damaged units, short delivery
+}
+
+// RespondToTransfer handles POST /api/v1/custody/accept
+// Public: the acceptance token from the email is the credential
+func (h *CustodyHandler) RespondToTransfer(w http.ResponseWriter, r *http.Request) {
+	var req RespondToTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var accept bool
+	switch strings.ToUpper(req.Decision) {
+	case "ACCEPT":
+		accept = true
+	case "REJECT":
+	default:
+		respondError(w, http.StatusBadRequest, "decision must be ACCEPT or REJECT")
+		return
+	}
+
+	acceptance, err := h.service.RespondToTransfer(r.Context(), req.Token, accept, req.Note)
+	if err != nil {
+		respondCustodyError(w, err, "respond to custody transfer")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, acceptance)
+}
@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"strconv"
 
+	"exportready-battery/internal/repository"
+
 	"github.com/google/uuid"
 )
 
@@ -67,7 +69,8 @@ func (h *Handler) GetRecentBatches(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// GetScanFeed handles GET /api/v1/scans/feed
+// GetScanFeed handles GET /api/v1/scans/feed?tenant_id=xxx&limit=10
+// Also accepts cursor, serial_prefix, market_region, created_after and created_before (scan time)
 func (h *Handler) GetScanFeed(w http.ResponseWriter, r *http.Request) {
 	tenantIDStr := r.URL.Query().Get("tenant_id")
 	if tenantIDStr == "" {
@@ -81,23 +84,29 @@ func (h *Handler) GetScanFeed(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Default to 10
-	limit := 10
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 && parsed <= 50 {
-			limit = parsed
-		}
+	params, err := parseListParams(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := params.pageRequest(repository.SortScansNewest, false)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid cursor")
+		return
 	}
 
-	feed, err := h.repo.GetScanFeed(r.Context(), tenantID, limit)
+	filter := repository.ScanFilter{
+		SerialPrefix:  params.SerialPrefix,
+		MarketRegions: params.MarketRegions,
+		CreatedAfter:  params.CreatedAfter,
+		CreatedBefore: params.CreatedBefore,
+	}
+	feed, info, err := h.repo.GetScanFeed(r.Context(), tenantID, filter, page)
 	if err != nil {
 		log.Printf("Failed to get scan feed: %v", err)
 		respondError(w, http.StatusInternalServerError, "Failed to get scan feed")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]interface{}{
-		"scans": feed,
-		"count": len(feed),
-	})
+	respondJSON(w, http.StatusOK, listResponse("scans", feed, len(feed), page, info))
 }
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"mime/multipart"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -54,26 +56,12 @@ func (h *Handler) UploadDocument(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get the uploaded file
-	file, header, err := r.FormFile("file")
-	if err != nil {
-		respondError(w, http.StatusBadRequest, "No file uploaded. Use 'file' field in multipart form")
+	file, _, ok := formPDF(w, r)
+	if !ok {
 		return
 	}
 	defer file.Close()
 
-	// Validate file size
-	if header.Size > MaxUploadSize {
-		respondError(w, http.StatusBadRequest, "File too large. Maximum size is 5MB")
-		return
-	}
-
-	// Validate file type (must be PDF)
-	contentType := header.Header.Get("Content-Type")
-	if contentType != "application/pdf" && !strings.HasSuffix(strings.ToLower(header.Filename), ".pdf") {
-		respondError(w, http.StatusBadRequest, "Only PDF files are allowed")
-		return
-	}
-
 	// Create uploads directory structure: ./uploads/{tenant_id}/
 	uploadDir := filepath.Join(".", "uploads", tenantID.String())
 	if err := os.MkdirAll(uploadDir, 0755); err != nil {
@@ -119,6 +107,32 @@ func (h *Handler) UploadDocument(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// formPDF returns the PDF in the 'file' field of a parsed multipart form. It
+// writes the error response when the file is missing, too large or not a PDF.
+func formPDF(w http.ResponseWriter, r *http.Request) (multipart.File, *multipart.FileHeader, bool) {
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "No file uploaded. Use 'file' field in multipart form")
+		return nil, nil, false
+	}
+
+	// Validate file size
+	if header.Size > MaxUploadSize {
+		file.Close()
+		respondError(w, http.StatusBadRequest, "File too large. Maximum size is 5MB")
+		return nil, nil, false
+	}
+
+	// Validate file type (must be PDF)
+	contentType := header.Header.Get("Content-Type")
+	if contentType != "application/pdf" && !strings.HasSuffix(strings.ToLower(header.Filename), ".pdf") {
+		file.Close()
+		respondError(w, http.StatusBadRequest, "Only PDF files are allowed")
+		return nil, nil, false
+	}
+	return file, header, true
+}
+
 // ViewDocument handles GET /api/v1/settings/documents/{type}
 // Serves the uploaded PDF file for viewing
 func (h *Handler) ViewDocument(w http.ResponseWriter, r *http.Request) {
@@ -275,3 +289,19 @@ func (h *Handler) UploadLogo(w http.ResponseWriter, r *http.Request) {
 		"message":  "Logo uploaded successfully",
 	})
 }
+
+// LogoFS is the public uploads directory, restricted to tenant logos
+// ({tenant_id}/logo.png or logo.jpg). Certificates, other files and directory
+// listings are not found.
+type LogoFS struct {
+	Root http.Dir
+}
+
+// Open implements http.FileSystem
+func (fs LogoFS) Open(name string) (http.File, error) {
+	dir, file := path.Split(strings.TrimPrefix(name, "/"))
+	if _, err := uuid.Parse(strings.TrimSuffix(dir, "/")); err != nil || (file != "logo.png" && file != "logo.jpg") {
+		return nil, os.ErrNotExist
+	}
+	return fs.Root.Open(name)
+}
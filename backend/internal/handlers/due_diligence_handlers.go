@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"exportready-battery/internal/auth"
+	"exportready-battery/internal/middleware"
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// DueDiligenceHandler handles a batch's recycled content, supplier list and
+// audit reports, and their restricted view for legitimate-interest partners
+type DueDiligenceHandler struct {
+	service   *services.DueDiligenceService
+	jwtSecret string
+}
+
+// NewDueDiligenceHandler creates a new due diligence handler
+func NewDueDiligenceHandler(service *services.DueDiligenceService, jwtSecret string) *DueDiligenceHandler {
+	return &DueDiligenceHandler{service: service, jwtSecret: jwtSecret}
+}
+
+// respondDueDiligenceError maps due diligence service errors to HTTP responses
+func respondDueDiligenceError(w http.ResponseWriter, err error, notFound, action string) {
+	switch {
+	case errors.Is(err, services.ErrDueDiligenceInvalid):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrDueDiligenceNotFound):
+		respondError(w, http.StatusNotFound, notFound)
+	default:
+		log.Printf("Failed to %s: %v", action, err)
+		respondError(w, http.StatusInternalServerError, "Failed to "+action)
+	}
+}
+
+// dueDiligenceBatch reads the authenticated tenant and the {id} batch
+func dueDiligenceBatch(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return uuid.Nil, uuid.Nil, false
+	}
+	batchID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid batch ID format")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return tenantID, batchID, true
+}
+
+// documentID reads the {doc} path value
+func documentID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue("doc"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid document ID")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// serveAuditReport sends an audit report PDF
+func serveAuditReport(w http.ResponseWriter, r *http.Request, doc *models.DueDiligenceDocument) {
+	if _, err := os.Stat(doc.FilePath); os.IsNotExist(err) {
+		respondError(w, http.StatusNotFound, "Audit report file not found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", doc.FileName))
+	http.ServeFile(w, r, doc.FilePath)
+}
+
+// GetDueDiligence handles GET /api/v1/batches/{id}/due-diligence
+func (h *DueDiligenceHandler) GetDueDiligence(w http.ResponseWriter, r *http.Request) {
+	tenantID, batchID, ok := dueDiligenceBatch(w, r)
+	if !ok {
+		return
+	}
+
+	disclosure, err := h.service.Get(r.Context(), tenantID, batchID)
+	if err != nil {
+		respondDueDiligenceError(w, err, "Batch not found", "get due diligence")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, disclosure)
+}
+
+// UpdateDueDiligenceRequest is the body of PUT /api/v1/batches/{id}/due-diligence
+type UpdateDueDiligenceRequest struct {
+	RecycledContent *models.RecycledContent `json:"recycled_content"`
+	DueDiligence    *models.DueDiligence    `json:"due_diligence"`
+}
+
+// UpdateDueDiligence handles PUT /api/v1/batches/{id}/due-diligence
+// Replaces the per-material recycled shares and the supplier/smelter list in
+// the batch specs. Invalid entries are rejected per field like batch specs.
+func (h *DueDiligenceHandler) UpdateDueDiligence(w http.ResponseWriter, r *http.Request) {
+	tenantID, batchID, ok := dueDiligenceBatch(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateDueDiligenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	disclosure, issues, err := h.service.Update(r.Context(), tenantID, batchID, req.RecycledContent, req.DueDiligence)
+	if err != nil {
+		respondDueDiligenceError(w, err, "Batch not found", "update due diligence")
+		return
+	}
+	warnings, ok := respondSpecIssues(w, issues)
+	if !ok {
+		return
+	}
+
+	response := map[string]interface{}{"due_diligence": disclosure}
+	if len(warnings) > 0 {
+		response["warnings"] = warnings
+	}
+	respondJSON(w, http.StatusOK, response)
+}
+
+// UploadAuditReport handles POST /api/v1/batches/{id}/due-diligence/documents
+// Accepts multipart form with 'file' (PDF, 5MB), 'title' and optional 'auditor',
+// 'supplier' (party audited) and 'issued_on' (YYYY-MM-DD)
+func (h *DueDiligenceHandler) UploadAuditReport(w http.ResponseWriter, r *http.Request) {
+	tenantID, batchID, ok := dueDiligenceBatch(w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseMultipartForm(MaxUploadSize); err != nil {
+		respondError(w, http.StatusBadRequest, "File too large. Maximum size is 5MB")
+		return
+	}
+	file, header, ok := formPDF(w, r)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	doc, err := h.service.AddDocument(r.Context(), tenantID, batchID, services.AddDocumentRequest{
+		Title:      r.FormValue("title"),
+		Auditor:    r.FormValue("auditor"),
+		Supplier:   r.FormValue("supplier"),
+		IssuedOn:   r.FormValue("issued_on"),
+		FileName:   header.Filename,
+		SizeBytes:  header.Size,
+		UploadedBy: middleware.GetEmail(r.Context()),
+	}, file)
+	if err != nil {
+		respondDueDiligenceError(w, err, "Batch not found", "upload audit report")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{"document": doc})
+}
+
+// GetAuditReport handles GET /api/v1/batches/{id}/due-diligence/documents/{doc}
+func (h *DueDiligenceHandler) GetAuditReport(w http.ResponseWriter, r *http.Request) {
+	tenantID, batchID, ok := dueDiligenceBatch(w, r)
+	if !ok {
+		return
+	}
+	docID, ok := documentID(w, r)
+	if !ok {
+		return
+	}
+
+	doc, err := h.service.Document(r.Context(), tenantID, batchID, docID)
+	if err != nil {
+		respondDueDiligenceError(w, err, "Audit report not found", "get audit report")
+		return
+	}
+
+	serveAuditReport(w, r, doc)
+}
+
+// DeleteAuditReport handles DELETE /api/v1/batches/{id}/due-diligence/documents/{doc}
+func (h *DueDiligenceHandler) DeleteAuditReport(w http.ResponseWriter, r *http.Request) {
+	tenantID, batchID, ok := dueDiligenceBatch(w, r)
+	if !ok {
+		return
+	}
+	docID, ok := documentID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteDocument(r.Context(), tenantID, batchID, docID); err != nil {
+		respondDueDiligenceError(w, err, "Audit report not found", "delete audit report")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Audit report deleted"})
+}
+
+// partnerPassport reads the {uuid} passport after validating the magic link token
+// (Authorization header or ?token=, so reports open in a new tab) and checking
+// that its role has a legitimate interest
+func (h *DueDiligenceHandler) partnerPassport(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	passportIDStr := r.PathValue("uuid")
+	passportID, err := uuid.Parse(passportIDStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid passport UUID")
+		return uuid.Nil, false
+	}
+
+	tokenString := r.URL.Query().Get("token")
+	if tokenString == "" {
+		authHeader := r.Header.Get("Authorization")
+		if strings.HasPrefix(authHeader, "Bearer ") {
+			tokenString = strings.TrimPrefix(authHeader, "Bearer ")
+		}
+	}
+	if tokenString == "" {
+		respondError(w, http.StatusUnauthorized, "Token required")
+		return uuid.Nil, false
+	}
+
+	claims, err := auth.ValidateMagicTokenForPassport(tokenString, h.jwtSecret, passportIDStr)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Invalid or expired magic link")
+		return uuid.Nil, false
+	}
+	if !auth.HasLegitimateInterest(claims.Role) {
+		respondError(w, http.StatusForbidden, "Supply-chain due diligence details are not available to customers")
+		return uuid.Nil, false
+	}
+	return passportID, true
+}
+
+// GetPassportDueDiligence handles GET /api/v1/passport/{uuid}/due-diligence
+// Authenticated by magic link. Returns the disclosure with supplier names and
+// audit reports, which the public passport withholds.
+func (h *DueDiligenceHandler) GetPassportDueDiligence(w http.ResponseWriter, r *http.Request) {
+	passportID, ok := h.partnerPassport(w, r)
+	if !ok {
+		return
+	}
+
+	disclosure, err := h.service.PassportDisclosure(r.Context(), passportID)
+	if err != nil {
+		respondDueDiligenceError(w, err, "Passport not found", "get due diligence")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, disclosure)
+}
+
+// GetPassportAuditReport handles GET /api/v1/passport/{uuid}/due-diligence/documents/{doc}
+// Authenticated by magic link
+func (h *DueDiligenceHandler) GetPassportAuditReport(w http.ResponseWriter, r *http.Request) {
+	passportID, ok := h.partnerPassport(w, r)
+	if !ok {
+		return
+	}
+	docID, ok := documentID(w, r)
+	if !ok {
+		return
+	}
+
+	doc, err := h.service.PassportDocument(r.Context(), passportID, docID)
+	if err != nil {
+		respondDueDiligenceError(w, err, "Audit report not found", "get audit report")
+		return
+	}
+
+	serveAuditReport(w, r, doc)
+}
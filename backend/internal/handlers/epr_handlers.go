@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"exportready-battery/internal/middleware"
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// EPRHandler handles the India EPR annual return and its targets
+type EPRHandler struct {
+	service *services.EPRService
+}
+
+// NewEPRHandler creates a new EPR handler
+func NewEPRHandler(service *services.EPRService) *EPRHandler {
+	return &EPRHandler{service: service}
+}
+
+// respondEPRError maps EPR service errors to HTTP responses
+func respondEPRError(w http.ResponseWriter, err error, action string) {
+	if errors.Is(err, services.ErrEPRInvalid) {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	log.Printf("Failed to %s: %v", action, err)
+	respondError(w, http.StatusInternalServerError, "Failed to "+action)
+}
+
+// eprTenantID reads the authenticated tenant
+func eprTenantID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return uuid.Nil, false
+	}
+	return tenantID, true
+}
+
+// GetTargets handles GET /api/v1/epr/targets
+func (h *EPRHandler) GetTargets(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := eprTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	targets, err := h.service.Targets(r.Context(), tenantID)
+	if err != nil {
+		respondEPRError(w, err, "get EPR targets")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"targets": targets})
+}
+
+// UpdateEPRTargetsRequest is the body of PUT /api/v1/epr/targets
+type UpdateEPRTargetsRequest struct {
+	Targets []models.EPRTarget `json:"targets"`
+}
+
+// UpdateTargets handles PUT /api/v1/epr/targets
+// Sets the listed categories' targets; the others keep theirs
+func (h *EPRHandler) UpdateTargets(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := eprTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateEPRTargetsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	targets, err := h.service.SetTargets(r.Context(), tenantID, req.Targets)
+	if err != nil {
+		respondEPRError(w, err, "update EPR targets")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"targets": targets})
+}
+
+// GetAnnualReturn handles GET /api/v1/epr/annual-return?fy=2025-26
+// Defaults to the current financial year
+func (h *EPRHandler) GetAnnualReturn(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := eprTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	ret, err := h.service.AnnualReturn(r.Context(), tenantID, r.URL.Query().Get("fy"))
+	if err != nil {
+		respondEPRError(w, err, "build EPR annual return")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ret)
+}
+
+// ExportAnnualReturn handles GET /api/v1/epr/annual-return/export?fy=2025-26&format=xlsx
+// Format is xlsx (default) or pdf
+func (h *EPRHandler) ExportAnnualReturn(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := eprTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "xlsx"
+	}
+	if format != "xlsx" && format != "pdf" {
+		respondError(w, http.StatusBadRequest, "format must be xlsx or pdf")
+		return
+	}
+
+	ret, err := h.service.AnnualReturn(r.Context(), tenantID, r.URL.Query().Get("fy"))
+	if err != nil {
+		respondEPRError(w, err, "build EPR annual return")
+		return
+	}
+
+	// Rendered to a buffer so a failure can still be reported as JSON
+	var buf bytes.Buffer
+	contentType := "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	if format == "pdf" {
+		contentType = "application/pdf"
+		err = h.service.WriteReturnPDF(ret, &buf)
+	} else {
+		err = h.service.WriteReturnXLSX(ret, &buf)
+	}
+	if err != nil {
+		respondEPRError(w, err, "export EPR annual return")
+		return
+	}
+
+	filename := fmt.Sprintf("epr-annual-return-%s.%s", ret.FinancialYear, format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"exportready-battery/internal/middleware"
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/services"
+
+	"github.com/google/uuid"
+)
+
+const (
+	eventReplayLimit = 500              // Events replayed on reconnect before asking the client to resync
+	eventHeartbeat   = 25 * time.Second // Comment line that keeps proxies from closing idle streams
+	eventRetryMillis = 3000             // Reconnect delay suggested to EventSource
+)
+
+// EventStreamHandler serves the tenant's live event stream
+type EventStreamHandler struct {
+	hub *services.EventHub
+}
+
+// NewEventStreamHandler creates a new event stream handler
+func NewEventStreamHandler(hub *services.EventHub) *EventStreamHandler {
+	return &EventStreamHandler{hub: hub}
+}
+
+// Stream handles GET /api/v1/events/stream
+// Server-Sent Events for scans, passport transitions, batch activations, recalls, custody
+// transfers and import progress. Each message has the event ID as id, the event type
+// as event and the TenantEvent JSON as data. Reconnects resume after the
+// Last-Event-ID header (sent by EventSource) or ?last_event_id=; a client too far
+// behind receives "resync" and should reload its snapshots.
+func (h *EventStreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	types, err := parseEventTypes(r.URL.Query().Get("types"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+	var afterID int64
+	if lastID != "" {
+		if afterID, err = strconv.ParseInt(lastID, 10, 64); err != nil || afterID < 0 {
+			respondError(w, http.StatusBadRequest, "Invalid Last-Event-ID")
+			return
+		}
+	}
+
+	// Streams outlive the server's write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		respondError(w, http.StatusInternalServerError, "Streaming not supported")
+		return
+	}
+
+	// Subscribe before replaying so nothing published in between is missed;
+	// live copies of replayed events are skipped below
+	sub := h.hub.Subscribe(tenantID)
+	defer sub.Close()
+
+	var replay []*models.TenantEvent
+	if afterID > 0 {
+		replay, err = h.hub.ReplayEvents(r.Context(), tenantID, afterID, eventReplayLimit+1)
+		if err != nil {
+			log.Printf("Failed to replay events: %v", err)
+			respondError(w, http.StatusInternalServerError, "Failed to replay events")
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable nginx response buffering
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", eventRetryMillis)
+
+	replayed := make(map[int64]bool, len(replay))
+	if len(replay) > eventReplayLimit {
+		writeSSE(w, strconv.FormatInt(replay[len(replay)-1].ID, 10), "resync", []byte("{}"))
+		for _, event := range replay {
+			replayed[event.ID] = true
+		}
+		replay = nil
+	}
+	for _, event := range replay {
+		replayed[event.ID] = true
+		if types[event.Type] {
+			writeEvent(w, event)
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				return // Dropped by the hub; the client reconnects with Last-Event-ID
+			}
+			if replayed[event.ID] || !types[event.Type] {
+				continue
+			}
+			writeEvent(w, event)
+		case <-heartbeat.C:
+			io.WriteString(w, ": ping\n\n")
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// parseEventTypes reads the comma-separated ?types= filter; empty selects every type
+func parseEventTypes(value string) (map[string]bool, error) {
+	types := make(map[string]bool, len(models.EventTypes))
+	if value == "" {
+		for _, t := range models.EventTypes {
+			types[t] = true
+		}
+		return types, nil
+	}
+	for _, t := range strings.Split(value, ",") {
+		t = strings.TrimSpace(t)
+		if !slices.Contains(models.EventTypes, t) {
+			return nil, fmt.Errorf("Invalid event type %q. Must be one of: %s", t, strings.Join(models.EventTypes, ", "))
+		}
+		types[t] = true
+	}
+	return types, nil
+}
+
+// writeEvent writes one tenant event as an SSE message
+func writeEvent(w io.Writer, event *models.TenantEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to encode event %d: %v", event.ID, err)
+		return
+	}
+	writeSSE(w, strconv.FormatInt(event.ID, 10), event.Type, data)
+}
+
+// writeSSE writes an SSE message; data must be a single line (compact JSON)
+func writeSSE(w io.Writer, id, event string, data []byte) {
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", id, event, data)
+}
@@ -10,6 +10,7 @@ import (
 	"exportready-battery/internal/middleware"
 	"exportready-battery/internal/models"
 	"exportready-battery/internal/repository"
+	"exportready-battery/internal/services"
 
 	"github.com/google/uuid"
 )
@@ -22,13 +23,15 @@ import (
 type ExternalCreateBatchRequest struct {
 	BatchName        string           `json:"batch_name"`
 	MarketRegion     string           `json:"market_region"`
+	Markets          []string         `json:"markets,omitempty"` // Several target markets; takes precedence over market_region
 	Specs            models.BatchSpec `json:"specs"`
-	PLICompliant     bool             `json:"pli_compliant,omitempty"`
-	DomesticValueAdd float64          `json:"domestic_value_add,omitempty"`
+	PLICompliant     bool             `json:"pli_compliant,omitempty"`      // Checked against the calculated DVA
+	DomesticValueAdd float64          `json:"domestic_value_add,omitempty"` // Ignored: calculated from specs.sale_price_inr and import_cost_inr
 	CellSource       string           `json:"cell_source,omitempty"`
 	BillOfEntryNo    string           `json:"bill_of_entry_no,omitempty"`
 	CountryOfOrigin  string           `json:"country_of_origin,omitempty"`
 	CustomsDate      string           `json:"customs_date,omitempty"`
+	HSNCode          string           `json:"hsn_code,omitempty"`
 }
 
 // ExternalCreatePassportsRequest is the request for adding passports via external API
@@ -42,6 +45,29 @@ type ExternalPassportEntry struct {
 	ManufactureDate string `json:"manufacture_date"` // YYYY-MM-DD
 }
 
+// authorizeExternalBatch checks that the API key's tenant owns the batch and that
+// the key's batch and market restrictions allow it. Writes the error response on failure.
+func authorizeExternalBatch(w http.ResponseWriter, r *http.Request, tenantID, batchID, ownerID uuid.UUID, market models.MarketRegion) bool {
+	if ownerID != tenantID {
+		respondError(w, http.StatusForbidden, "Access denied")
+		return false
+	}
+
+	key := middleware.GetAPIKey(r.Context())
+	if key == nil {
+		return true
+	}
+	if !key.AllowsBatch(batchID) {
+		respondError(w, http.StatusForbidden, "API key is not allowed for this batch")
+		return false
+	}
+	if !key.AllowsMarket(market) {
+		respondError(w, http.StatusForbidden, "API key is not allowed for this market region")
+		return false
+	}
+	return true
+}
+
 // ExternalCreateBatch handles POST /api/v1/external/batches
 // Creates a new batch for ERP integrations
 func (h *Handler) ExternalCreateBatch(w http.ResponseWriter, r *http.Request) {
@@ -65,16 +91,33 @@ func (h *Handler) ExternalCreateBatch(w http.ResponseWriter, r *http.Request) {
 	}
 	tenantID, _ := uuid.Parse(tenantIDStr)
 
-	// Validate market region
-	marketRegion := models.MarketRegion(req.MarketRegion)
-	if req.MarketRegion == "" {
-		marketRegion = models.MarketRegionGlobal
+	// Target markets: markets, or market_region (default GLOBAL: INDIA and EU)
+	requested := make([]models.MarketRegion, len(req.Markets))
+	for i, m := range req.Markets {
+		requested[i] = models.MarketRegion(m)
+	}
+	markets, marketRegion, err := models.ResolveMarkets(models.MarketRegion(req.MarketRegion), requested)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
 	}
-	if !marketRegion.IsValid() {
-		respondError(w, http.StatusBadRequest, "Invalid market_region. Must be INDIA, EU, or GLOBAL")
+	warnings, ok := checkBatchSpec(w, &req.Specs)
+	if !ok {
 		return
 	}
 
+	// Keys pinned to specific batches or markets cannot create batches outside them
+	if key := middleware.GetAPIKey(r.Context()); key != nil {
+		if len(key.AllowedBatchIDs) > 0 {
+			respondError(w, http.StatusForbidden, "API key is restricted to specific batches")
+			return
+		}
+		if !key.AllowsMarket(marketRegion) {
+			respondError(w, http.StatusForbidden, "API key is not allowed for this market region")
+			return
+		}
+	}
+
 	// Parse customs date if provided
 	var customsDate *time.Time
 	if req.CustomsDate != "" {
@@ -86,18 +129,49 @@ func (h *Handler) ExternalCreateBatch(w http.ResponseWriter, r *http.Request) {
 		customsDate = &parsed
 	}
 
+	tenant, err := h.repo.GetTenant(r.Context(), tenantID)
+	if err != nil {
+		log.Printf("External API: Failed to get tenant: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to create batch")
+		return
+	}
+
+	// Each target market's rule pack checks the batch, as for dashboard batches;
+	// India derives the DVA server-side (a client-sent domestic_value_add is
+	// ignored) and checks the PLI claim against it
+	prepared := models.CreateBatchRequest{
+		TenantID:        tenantID,
+		BatchName:       req.BatchName,
+		Specs:           req.Specs,
+		MarketRegion:    marketRegion,
+		Markets:         markets,
+		PLICompliant:    req.PLICompliant,
+		CellSource:      req.CellSource,
+		BillOfEntryNo:   req.BillOfEntryNo,
+		CountryOfOrigin: req.CountryOfOrigin,
+		CustomsDate:     req.CustomsDate,
+		HSNCode:         req.HSNCode,
+	}
+	legacyGlobal := len(req.Markets) == 0 && marketRegion == models.MarketRegionGlobal
+	validation, ok := h.validateNewBatch(w, &prepared, tenant, legacyGlobal)
+	if !ok {
+		return
+	}
+
 	// Create batch using repository request struct
 	createReq := repository.CreateBatchRequest{
 		TenantID:         tenantID,
-		BatchName:        req.BatchName,
-		Specs:            req.Specs,
-		MarketRegion:     marketRegion,
-		PLICompliant:     req.PLICompliant,
-		DomesticValueAdd: req.DomesticValueAdd,
-		CellSource:       req.CellSource,
-		BillOfEntryNo:    req.BillOfEntryNo,
-		CountryOfOrigin:  req.CountryOfOrigin,
+		BatchName:        prepared.BatchName,
+		Specs:            prepared.Specs,
+		MarketRegion:     prepared.MarketRegion,
+		Markets:          prepared.Markets,
+		PLICompliant:     prepared.PLICompliant,
+		DomesticValueAdd: prepared.DomesticValueAdd,
+		CellSource:       prepared.CellSource,
+		BillOfEntryNo:    prepared.BillOfEntryNo,
+		CountryOfOrigin:  prepared.CountryOfOrigin,
 		CustomsDate:      customsDate,
+		HSNCode:          prepared.HSNCode,
 	}
 
 	batch, err := h.repo.CreateBatch(r.Context(), createReq)
@@ -109,11 +183,18 @@ func (h *Handler) ExternalCreateBatch(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("🔗 External API: Batch created - %s (tenant: %s)", batch.BatchName, tenantIDStr[:8])
 
-	respondJSON(w, http.StatusCreated, map[string]interface{}{
+	response := map[string]interface{}{
 		"id":         batch.ID.String(),
 		"batch_name": batch.BatchName,
 		"message":    "Batch created successfully",
-	})
+	}
+	if len(warnings) > 0 {
+		response["warnings"] = warnings
+	}
+	if len(validation.Warnings) > 0 {
+		response["market_warnings"] = validation.Warnings
+	}
+	respondJSON(w, http.StatusCreated, response)
 }
 
 // ExternalCreatePassports handles POST /api/v1/external/batches/{id}/passports
@@ -151,14 +232,13 @@ func (h *Handler) ExternalCreatePassports(w http.ResponseWriter, r *http.Request
 	}
 	tenantID, _ := uuid.Parse(tenantIDStr)
 
-	// Verify batch ownership
+	// Verify batch ownership and key restrictions
 	batch, err := h.repo.GetBatch(r.Context(), batchID)
 	if err != nil {
 		respondError(w, http.StatusNotFound, "Batch not found")
 		return
 	}
-	if batch.TenantID != tenantID {
-		respondError(w, http.StatusForbidden, "Access denied")
+	if !authorizeExternalBatch(w, r, tenantID, batch.ID, batch.TenantID, batch.MarketRegion) {
 		return
 	}
 
@@ -207,12 +287,13 @@ func (h *Handler) ExternalCreatePassports(w http.ResponseWriter, r *http.Request
 			BatchID:         batchID,
 			SerialNumber:    p.SerialNumber,
 			ManufactureDate: manufDate,
-			Status:          "ACTIVE",
+			Status:          models.PassportStatusCreated,
 			CreatedAt:       time.Now(),
 		}
 	}
 
-	created, err := h.repo.CreatePassportsBatch(r.Context(), passports)
+	job := h.startImport(r.Context(), tenantID, batchID, models.ImportSourceAPI, len(passports), 0)
+	created, err := job.insert(r.Context(), passports)
 	if err != nil {
 		log.Printf("External API: Failed to create passports: %v", err)
 		respondError(w, http.StatusInternalServerError, "Failed to create passports")
@@ -239,12 +320,29 @@ func (h *Handler) ExternalGetPassport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Get tenant ID from API key context
+	tenantIDStr := middleware.GetAPIKeyTenantID(r.Context())
+	if tenantIDStr == "" {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+	tenantID, _ := uuid.Parse(tenantIDStr)
+
 	passport, err := h.repo.GetPassportWithSpecs(r.Context(), passportID)
 	if err != nil {
 		respondError(w, http.StatusNotFound, "Passport not found")
 		return
 	}
 
+	// Verify ownership and key restrictions
+	if !authorizeExternalBatch(w, r, tenantID, passport.Passport.BatchID, passport.Tenant.ID, passport.MarketRegion) {
+		return
+	}
+	h.attachRecallNotice(r.Context(), passport)
+	attachWarranty(passport)
+	attachObligations(passport)
+	h.attachAuditReports(r.Context(), passport, false)
+
 	// Return passport data
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"passport": passport,
@@ -269,14 +367,13 @@ func (h *Handler) ExternalDownloadLabels(w http.ResponseWriter, r *http.Request)
 	}
 	tenantID, _ := uuid.Parse(tenantIDStr)
 
-	// Verify batch ownership
+	// Verify batch ownership and key restrictions
 	batch, err := h.repo.GetBatch(r.Context(), batchID)
 	if err != nil {
 		respondError(w, http.StatusNotFound, "Batch not found")
 		return
 	}
-	if batch.TenantID != tenantID {
-		respondError(w, http.StatusForbidden, "Access denied")
+	if !authorizeExternalBatch(w, r, tenantID, batch.ID, batch.TenantID, batch.MarketRegion) {
 		return
 	}
 
@@ -316,3 +413,615 @@ func (h *Handler) ExternalDownloadLabels(w http.ResponseWriter, r *http.Request)
 
 	log.Printf("🔗 External API: Labels downloaded for batch %s (tenant: %s)", batch.BatchName, tenantIDStr[:8])
 }
+
+// ============================================================================
+// EXTERNAL API - LISTING, LIFECYCLE & BATCH MANAGEMENT
+// ============================================================================
+
+// ExternalUpdateBatchRequest is the request for editing a DRAFT batch via external API.
+// Omitted fields are left unchanged; specs replaces the whole spec object.
+type ExternalUpdateBatchRequest struct {
+	BatchName        *string           `json:"batch_name,omitempty"`
+	Specs            *models.BatchSpec `json:"specs,omitempty"`
+	PLICompliant     *bool             `json:"pli_compliant,omitempty"`      // Checked against the recalculated DVA
+	DomesticValueAdd *float64          `json:"domestic_value_add,omitempty"` // Rejected: calculated from specs.sale_price_inr and import_cost_inr
+	CellSource       *string           `json:"cell_source,omitempty"`
+	BillOfEntryNo    *string           `json:"bill_of_entry_no,omitempty"`
+	CountryOfOrigin  *string           `json:"country_of_origin,omitempty"`
+	CustomsDate      *string           `json:"customs_date,omitempty"` // YYYY-MM-DD
+	HSNCode          *string           `json:"hsn_code,omitempty"`
+}
+
+// ExternalTransitionRequest is the request for changing a passport's status via external API
+type ExternalTransitionRequest struct {
+	ToStatus string                 `json:"to_status"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"` // e.g., {"carrier": "DHL", "tracking": "1234"}
+}
+
+// ExternalBulkTransitionRequest is the request for changing many passports' status via external API
+type ExternalBulkTransitionRequest struct {
+	PassportIDs []string               `json:"passport_ids"`
+	ToStatus    string                 `json:"to_status"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// externalTenantID returns the tenant of the authenticated API key. Writes the error response on failure.
+func externalTenantID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	tenantID, err := uuid.Parse(middleware.GetAPIKeyTenantID(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return uuid.Nil, false
+	}
+	return tenantID, true
+}
+
+// applyExternalMarketFilter narrows markets to the requested market_region values,
+// rejecting regions the API key is not allowed to see
+func applyExternalMarketFilter(w http.ResponseWriter, r *http.Request, requested []string, markets *[]string) bool {
+	if len(requested) == 0 {
+		return true
+	}
+	key := middleware.GetAPIKey(r.Context())
+	for _, region := range requested {
+		if key != nil && !key.AllowsMarket(models.MarketRegion(region)) {
+			respondError(w, http.StatusForbidden, "API key is not allowed for this market region")
+			return false
+		}
+	}
+	*markets = requested
+	return true
+}
+
+// externalKeyRestrictions returns the batch and market restrictions of the API key,
+// so list endpoints only return resources the key may access
+func externalKeyRestrictions(r *http.Request) ([]uuid.UUID, []string) {
+	key := middleware.GetAPIKey(r.Context())
+	if key == nil {
+		return nil, nil
+	}
+	return key.AllowedBatchIDs, key.AllowedMarkets
+}
+
+// externalActor identifies the API key in passport events and returns its lifecycle role
+func externalActor(r *http.Request) (actor, role string) {
+	key := middleware.GetAPIKey(r.Context())
+	if key == nil {
+		return "api_key", "MANUFACTURER"
+	}
+	role = key.ActorRole
+	if role == "" {
+		role = "MANUFACTURER"
+	}
+	return fmt.Sprintf("api_key:%s (%s)", key.Name, key.KeyPrefix), role
+}
+
+// loadExternalBatch resolves the {id} batch and checks that the API key may access it
+func (h *Handler) loadExternalBatch(w http.ResponseWriter, r *http.Request, tenantID uuid.UUID) (*models.Batch, bool) {
+	batchID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid batch ID")
+		return nil, false
+	}
+
+	batch, err := h.repo.GetBatch(r.Context(), batchID)
+	if err != nil {
+		if err.Error() == "batch not found" {
+			respondError(w, http.StatusNotFound, "Batch not found")
+			return nil, false
+		}
+		log.Printf("External API: Failed to get batch: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to get batch")
+		return nil, false
+	}
+
+	if !authorizeExternalBatch(w, r, tenantID, batch.ID, batch.TenantID, batch.MarketRegion) {
+		return nil, false
+	}
+	return batch, true
+}
+
+// loadExternalPassport resolves the {uuid} passport and checks that the API key may access it
+func (h *Handler) loadExternalPassport(w http.ResponseWriter, r *http.Request, tenantID uuid.UUID) (*models.PassportWithSpecs, bool) {
+	passportID, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid passport ID")
+		return nil, false
+	}
+
+	passport, err := h.repo.GetPassportWithSpecs(r.Context(), passportID)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Passport not found")
+		return nil, false
+	}
+
+	if !authorizeExternalBatch(w, r, tenantID, passport.Passport.BatchID, passport.Tenant.ID, passport.MarketRegion) {
+		return nil, false
+	}
+	return passport, true
+}
+
+// ExternalListBatches handles GET /api/v1/external/batches?status=DRAFT&market_region=EU&q=name&limit=50
+// Pages with cursor (or legacy page) and accepts the shared filters in listParams
+func (h *Handler) ExternalListBatches(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := externalTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	params, err := parseListParams(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !validBatchStatus(params.Status) {
+		respondError(w, http.StatusBadRequest, "Invalid status. Must be DRAFT, ACTIVE, or ARCHIVED")
+		return
+	}
+
+	filter := repository.BatchFilter{
+		Status:        params.Status,
+		Search:        r.URL.Query().Get("q"),
+		Chemistry:     params.Chemistry,
+		CreatedAfter:  params.CreatedAfter,
+		CreatedBefore: params.CreatedBefore,
+	}
+	filter.BatchIDs, filter.MarketRegions = externalKeyRestrictions(r)
+	if !applyExternalMarketFilter(w, r, params.MarketRegions, &filter.MarketRegions) {
+		return
+	}
+
+	page, err := params.pageRequest(repository.SortBatchesNewest, true)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	batches, info, err := h.repo.ListBatches(r.Context(), tenantID, filter, page)
+	if err != nil {
+		log.Printf("External API: Failed to list batches: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to list batches")
+		return
+	}
+	if batches == nil {
+		batches = []*models.Batch{}
+	}
+
+	respondJSON(w, http.StatusOK, listResponse("batches", batches, len(batches), page, info))
+}
+
+// ExternalGetBatch handles GET /api/v1/external/batches/{id}
+func (h *Handler) ExternalGetBatch(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := externalTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	batch, ok := h.loadExternalBatch(w, r, tenantID)
+	if !ok {
+		return
+	}
+
+	count, _ := h.repo.CountPassportsByBatch(r.Context(), batch.ID)
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"batch":          batch,
+		"passport_count": count,
+	})
+}
+
+// ExternalUpdateBatch handles PATCH /api/v1/external/batches/{id}
+// Edits batch details while the batch is still in DRAFT
+func (h *Handler) ExternalUpdateBatch(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := externalTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	batch, ok := h.loadExternalBatch(w, r, tenantID)
+	if !ok {
+		return
+	}
+
+	var req ExternalUpdateBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if batch.Status != models.BatchStatusDraft {
+		respondError(w, http.StatusConflict, "Only DRAFT batches can be updated")
+		return
+	}
+	// An attested DVA can only be replaced by a new CA attestation
+	if batch.DVASource == models.DVASourceAudited &&
+		(req.PLICompliant != nil || req.DomesticValueAdd != nil || req.CellSource != nil) {
+		respondError(w, http.StatusConflict, "The DVA of this batch is CA-attested; request a new PLI audit to change it")
+		return
+	}
+	if req.BatchName != nil && *req.BatchName == "" {
+		respondError(w, http.StatusBadRequest, "batch_name cannot be empty")
+		return
+	}
+	if req.DomesticValueAdd != nil {
+		respondError(w, http.StatusBadRequest,
+			"domestic_value_add is calculated from specs.sale_price_inr and specs.import_cost_inr; send those instead")
+		return
+	}
+	var warnings []models.SpecIssue
+	if req.Specs != nil {
+		if warnings, ok = checkBatchSpec(w, req.Specs); !ok {
+			return
+		}
+	}
+
+	update := repository.UpdateDraftBatchRequest{
+		BatchName:       req.BatchName,
+		Specs:           req.Specs,
+		CellSource:      req.CellSource,
+		BillOfEntryNo:   req.BillOfEntryNo,
+		CountryOfOrigin: req.CountryOfOrigin,
+		HSNCode:         req.HSNCode,
+	}
+
+	// Recalculate the estimated DVA and recheck the PLI claim against the
+	// updated specs and cell source, as at creation. An attested DVA is left as is.
+	if batch.DVASource != models.DVASourceAudited && (req.Specs != nil || req.CellSource != nil || req.PLICompliant != nil) {
+		prepared := models.CreateBatchRequest{
+			TenantID:     tenantID,
+			BatchName:    batch.BatchName,
+			Specs:        batch.Specs,
+			MarketRegion: batch.MarketRegion,
+			Markets:      batch.Markets,
+			PLICompliant: batch.PLICompliant,
+			CellSource:   batch.CellSource,
+		}
+		if req.Specs != nil {
+			prepared.Specs = *req.Specs
+		}
+		if req.CellSource != nil {
+			prepared.CellSource = *req.CellSource
+		}
+		if req.PLICompliant != nil {
+			prepared.PLICompliant = *req.PLICompliant
+		}
+		if err := h.validationService.PrepareMarkets(&prepared); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		update.PLICompliant = &prepared.PLICompliant
+		update.DomesticValueAdd = &prepared.DomesticValueAdd
+	}
+	if req.CustomsDate != nil {
+		parsed, err := time.Parse("2006-01-02", *req.CustomsDate)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid customs_date format. Use YYYY-MM-DD")
+			return
+		}
+		update.CustomsDate = &parsed
+	}
+
+	if err := h.repo.UpdateDraftBatch(r.Context(), batch.ID, update); err != nil {
+		if err.Error() == "batch is not in draft" {
+			respondError(w, http.StatusConflict, "Only DRAFT batches can be updated")
+			return
+		}
+		log.Printf("External API: Failed to update batch: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to update batch")
+		return
+	}
+
+	updated, err := h.repo.GetBatch(r.Context(), batch.ID)
+	if err != nil {
+		log.Printf("External API: Failed to reload batch: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to get batch")
+		return
+	}
+
+	log.Printf("🔗 External API: Batch updated - %s (tenant: %s)", updated.BatchName, tenantID.String()[:8])
+
+	response := map[string]interface{}{
+		"batch":   updated,
+		"message": "Batch updated successfully",
+	}
+	if len(warnings) > 0 {
+		response["warnings"] = warnings
+	}
+	respondJSON(w, http.StatusOK, response)
+}
+
+// ExternalActivateBatch handles POST /api/v1/external/batches/{id}/activate
+// Spends one activation quota unit, same as the dashboard, including the
+// optional ?enforce_compliance=true gate
+func (h *Handler) ExternalActivateBatch(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := externalTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	batch, ok := h.loadExternalBatch(w, r, tenantID)
+	if !ok {
+		return
+	}
+
+	balance, ok := h.activateBatch(w, r, tenantID, batch)
+	if !ok {
+		return
+	}
+
+	log.Printf("🔗 External API: Batch activated - %s (tenant: %s)", batch.BatchName, tenantID.String()[:8])
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"message":       "Batch activated successfully",
+		"batch_id":      batch.ID,
+		"quota_balance": balance,
+	})
+}
+
+// ExternalExportBatchCSV handles GET /api/v1/external/batches/{id}/export
+func (h *Handler) ExternalExportBatchCSV(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := externalTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	batch, ok := h.loadExternalBatch(w, r, tenantID)
+	if !ok {
+		return
+	}
+
+	h.writeBatchCSV(w, r, batch)
+}
+
+// ExternalListPassports handles GET /api/v1/external/passports?batch_id=&status=&serial_prefix=&limit=50
+// and GET /api/v1/external/batches/{id}/passports. Pages with cursor (or legacy page)
+// and accepts the shared filters in listParams.
+func (h *Handler) ExternalListPassports(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := externalTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	params, err := parseListParams(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !validPassportStatus(params.Status) {
+		respondError(w, http.StatusBadRequest, "Invalid status")
+		return
+	}
+
+	filter := repository.PassportFilter{
+		Status:        params.Status,
+		SerialPrefix:  params.SerialPrefix,
+		Chemistry:     params.Chemistry,
+		CreatedAfter:  params.CreatedAfter,
+		CreatedBefore: params.CreatedBefore,
+	}
+	filter.BatchIDs, filter.MarketRegions = externalKeyRestrictions(r)
+	if !applyExternalMarketFilter(w, r, params.MarketRegions, &filter.MarketRegions) {
+		return
+	}
+
+	// Batch scope comes from the path on /batches/{id}/passports, else from ?batch_id
+	if r.PathValue("id") != "" {
+		batch, ok := h.loadExternalBatch(w, r, tenantID)
+		if !ok {
+			return
+		}
+		filter.BatchID = &batch.ID
+	} else if batchIDStr := r.URL.Query().Get("batch_id"); batchIDStr != "" {
+		batchID, err := uuid.Parse(batchIDStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid batch_id")
+			return
+		}
+		filter.BatchID = &batchID
+	}
+
+	page, err := params.pageRequest(filter.Sort(), true)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	passports, info, err := h.repo.ListPassports(r.Context(), tenantID, filter, page)
+	if err != nil {
+		log.Printf("External API: Failed to list passports: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to list passports")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, listResponse("passports", passports, len(passports), page, info))
+}
+
+// ExternalTransitionPassport handles POST /api/v1/external/passports/{uuid}/transition
+// The API key is recorded as the actor and its actor_role governs which transitions are allowed
+func (h *Handler) ExternalTransitionPassport(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := externalTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	passport, ok := h.loadExternalPassport(w, r, tenantID)
+	if !ok {
+		return
+	}
+
+	var req ExternalTransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.ToStatus == "" {
+		respondError(w, http.StatusBadRequest, "to_status is required")
+		return
+	}
+
+	actor, role := externalActor(r)
+	result, err := h.lifecycleService.TransitionPassport(r.Context(), services.TransitionRequest{
+		PassportID: passport.Passport.UUID,
+		ToStatus:   req.ToStatus,
+		Actor:      actor,
+		ActorRole:  role,
+		Metadata:   req.Metadata,
+	})
+	if err != nil {
+		switch err.Error() {
+		case "invalid status transition":
+			respondError(w, http.StatusConflict, result.Error)
+		case "role not permitted for this transition":
+			respondError(w, http.StatusForbidden, result.Error)
+		default:
+			log.Printf("External API: Failed to transition passport: %v", err)
+			respondError(w, http.StatusInternalServerError, "Failed to transition passport")
+		}
+		return
+	}
+
+	log.Printf("🔗 External API: Passport %s %s -> %s (%s)", passport.Passport.SerialNumber, result.PreviousStatus, result.NewStatus, actor)
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// ExternalBulkTransitionPassports handles POST /api/v1/external/passports/bulk/transition
+// Passports the key cannot access are reported as failed rather than rejecting the whole request
+func (h *Handler) ExternalBulkTransitionPassports(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := externalTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	var req ExternalBulkTransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if len(req.PassportIDs) == 0 {
+		respondError(w, http.StatusBadRequest, "passport_ids is required")
+		return
+	}
+	if len(req.PassportIDs) > 500 {
+		respondError(w, http.StatusBadRequest, "Maximum 500 passports per request")
+		return
+	}
+	if req.ToStatus == "" {
+		respondError(w, http.StatusBadRequest, "to_status is required")
+		return
+	}
+
+	passportIDs := make([]uuid.UUID, 0, len(req.PassportIDs))
+	for _, idStr := range req.PassportIDs {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid passport UUID: "+idStr)
+			return
+		}
+		passportIDs = append(passportIDs, id)
+	}
+
+	// Resolve which of the requested passports this key may touch
+	filter := repository.PassportFilter{PassportIDs: passportIDs}
+	filter.BatchIDs, filter.MarketRegions = externalKeyRestrictions(r)
+	accessible, _, err := h.repo.ListPassports(r.Context(), tenantID, filter, repository.PageRequest{Limit: len(passportIDs)})
+	if err != nil {
+		log.Printf("External API: Failed to resolve passports: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to process bulk transition")
+		return
+	}
+	allowed := make(map[uuid.UUID]bool, len(accessible))
+	for _, p := range accessible {
+		allowed[p.UUID] = true
+	}
+
+	var permitted []uuid.UUID
+	var denied []string
+	for _, id := range passportIDs {
+		if allowed[id] {
+			permitted = append(permitted, id)
+		} else {
+			denied = append(denied, id.String())
+		}
+	}
+
+	actor, role := externalActor(r)
+	result, err := h.lifecycleService.BulkTransitionPassports(r.Context(), services.BulkTransitionRequest{
+		PassportIDs: permitted,
+		ToStatus:    req.ToStatus,
+		Actor:       actor,
+		ActorRole:   role,
+		Metadata:    req.Metadata,
+	})
+	if err != nil {
+		log.Printf("External API: Failed bulk transition: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to process bulk transition")
+		return
+	}
+
+	for _, id := range denied {
+		result.Total++
+		result.Failed++
+		result.FailedIDs = append(result.FailedIDs, id)
+		result.Errors = append(result.Errors, "Passport not found")
+	}
+
+	log.Printf("🔗 External API: Bulk transition to %s - %d/%d succeeded (%s)", req.ToStatus, result.Succeeded, result.Total, actor)
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// ExternalGetAllowedTransitions handles GET /api/v1/external/passports/{uuid}/transitions
+// Lists the next statuses this API key's actor_role may move the passport to
+func (h *Handler) ExternalGetAllowedTransitions(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := externalTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	passport, ok := h.loadExternalPassport(w, r, tenantID)
+	if !ok {
+		return
+	}
+
+	current := passport.Passport.Status
+	_, role := externalActor(r)
+	allowed := []string{}
+	for _, next := range models.GetAllowedTransitions(current) {
+		if role == "MANUFACTURER" || models.IsValidRoleTransition(role, current, next) {
+			allowed = append(allowed, next)
+		}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"current_status":      current,
+		"actor_role":          role,
+		"allowed_transitions": allowed,
+	})
+}
+
+// ExternalGetPassportEvents handles GET /api/v1/external/passports/{uuid}/events
+func (h *Handler) ExternalGetPassportEvents(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := externalTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	passport, ok := h.loadExternalPassport(w, r, tenantID)
+	if !ok {
+		return
+	}
+
+	events, err := h.lifecycleService.GetPassportEvents(r.Context(), passport.Passport.UUID)
+	if err != nil {
+		log.Printf("External API: Failed to get passport events: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to retrieve events")
+		return
+	}
+	if events == nil {
+		events = []*models.PassportEvent{}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"passport_id": passport.Passport.UUID,
+		"events":      events,
+		"count":       len(events),
+	})
+}
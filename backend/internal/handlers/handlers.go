@@ -5,7 +5,6 @@ import (
 	"log"
 	"net/http"
 
-	"exportready-battery/internal/db"
 	"exportready-battery/internal/repository"
 	"exportready-battery/internal/services"
 )
@@ -19,23 +18,28 @@ type Handler struct {
 	pdfService        *services.PDFService
 	razorpayService   *services.RazorpayService
 	validationService *services.ValidationService // India compliance validation
+	lifecycleService  *services.LifecycleService  // Passport transitions for the external API
+	complianceService *services.ComplianceService // Optional readiness gate on activation
 }
 
-// New creates a new Handler with the given database connection
-func New(database *db.DB, baseURL string, geoDBPath string, razorpayKeyID, razorpayKeySecret string) *Handler {
+// New creates a new Handler on the shared repository, so that API key cache
+// invalidations reach the key middleware
+func New(repo *repository.Repository, baseURL string, geoDBPath string, razorpayKeyID, razorpayKeySecret string) *Handler {
 	var razorpayService *services.RazorpayService
 	if razorpayKeyID != "" && razorpayKeySecret != "" {
 		razorpayService = services.NewRazorpayService(razorpayKeyID, razorpayKeySecret)
 	}
 
 	return &Handler{
-		repo:              repository.New(database),
+		repo:              repo,
 		csvService:        services.NewCSVService(),
 		qrService:         services.NewQRService(baseURL),
 		geoService:        services.NewGeoIPService(geoDBPath),
 		pdfService:        services.NewPDFService(baseURL),
 		razorpayService:   razorpayService,
 		validationService: services.NewValidationService(),
+		lifecycleService:  services.NewLifecycleService(repo),
+		complianceService: services.NewComplianceService(repo),
 	}
 }
 
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+)
+
+// listParams is the query grammar shared by list endpoints:
+//
+//	limit           page size (each endpoint has its own default and maximum)
+//	cursor          next_cursor from the previous response (keyset paging)
+//	page            legacy 1-based offset paging, used when no cursor is given
+//	status          batch or passport status, depending on the endpoint
+//	market_region   INDIA, EU, US, UK or GLOBAL; comma-separated for several
+//	created_after   RFC 3339 timestamp or YYYY-MM-DD, inclusive
+//	created_before  RFC 3339 timestamp or YYYY-MM-DD, exclusive
+//	serial_prefix   serial numbers starting with this value
+//	chemistry       battery chemistry from the batch specs, e.g. LFP
+//
+// Endpoints ignore filters that do not apply to them.
+type listParams struct {
+	Limit         int
+	Page          int
+	Cursor        string
+	Status        string
+	MarketRegions []string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	SerialPrefix  string
+	Chemistry     string
+}
+
+// parseListParams reads the shared list query grammar. Unparseable limit/page
+// values fall back to the defaults; malformed filters are errors.
+func parseListParams(r *http.Request) (listParams, error) {
+	query := r.URL.Query()
+	p := listParams{
+		Cursor:       query.Get("cursor"),
+		Status:       strings.ToUpper(query.Get("status")),
+		SerialPrefix: query.Get("serial_prefix"),
+		Chemistry:    query.Get("chemistry"),
+	}
+	if p.SerialPrefix == "" {
+		p.SerialPrefix = query.Get("serial") // Earlier name on the external API
+	}
+	if parsed, err := parseInt(query.Get("limit")); err == nil && parsed > 0 {
+		p.Limit = parsed
+	}
+	if parsed, err := parseInt(query.Get("page")); err == nil && parsed > 0 {
+		p.Page = parsed
+	}
+
+	if regions := query.Get("market_region"); regions != "" {
+		for _, region := range strings.Split(regions, ",") {
+			region = strings.ToUpper(strings.TrimSpace(region))
+			if !models.MarketRegion(region).IsValid() {
+				return p, fmt.Errorf("Invalid market_region. Must be %s", models.ValidMarketRegions)
+			}
+			p.MarketRegions = append(p.MarketRegions, region)
+		}
+	}
+
+	var err error
+	if p.CreatedAfter, err = parseListTime(query.Get("created_after")); err != nil {
+		return p, fmt.Errorf("Invalid created_after. Use RFC 3339 or YYYY-MM-DD")
+	}
+	if p.CreatedBefore, err = parseListTime(query.Get("created_before")); err != nil {
+		return p, fmt.Errorf("Invalid created_before. Use RFC 3339 or YYYY-MM-DD")
+	}
+
+	return p, nil
+}
+
+// parseListTime accepts an RFC 3339 timestamp or a date (midnight UTC)
+func parseListTime(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// pageRequest builds the repository page for a sort order. A cursor switches to
+// keyset paging; otherwise page mode is used and, if withTotal, rows are counted.
+func (p listParams) pageRequest(sort string, withTotal bool) (repository.PageRequest, error) {
+	page := repository.PageRequest{Limit: p.Limit, Page: p.Page}
+	if p.Cursor != "" {
+		after, err := repository.DecodeCursor(p.Cursor, sort)
+		if err != nil {
+			return page, err
+		}
+		page.After = after
+		return page, nil
+	}
+	page.WithTotal = withTotal
+	return page, nil
+}
+
+// listResponse builds the list envelope: the items under key, plus next_cursor for
+// keyset paging and, when rows were counted, the legacy page/total fields
+func listResponse(key string, items interface{}, count int, page repository.PageRequest, info repository.PageInfo) map[string]interface{} {
+	var nextCursor interface{}
+	if info.NextCursor != "" {
+		nextCursor = info.NextCursor
+	}
+
+	resp := map[string]interface{}{
+		key:           items,
+		"count":       count,
+		"limit":       info.Limit,
+		"has_more":    info.HasMore,
+		"next_cursor": nextCursor,
+	}
+	if info.Total >= 0 {
+		current := page.Page
+		if current < 1 {
+			current = 1
+		}
+		resp["total"] = info.Total
+		resp["page"] = current
+		resp["total_pages"] = (info.Total + info.Limit - 1) / info.Limit
+	}
+	return resp
+}
+
+// validBatchStatus reports whether s is a batch status filter value
+func validBatchStatus(s string) bool {
+	switch s {
+	case "", models.BatchStatusDraft, models.BatchStatusActive, models.BatchStatusArchived:
+		return true
+	}
+	return false
+}
+
+// validPassportStatus reports whether s is a passport status filter value
+func validPassportStatus(s string) bool {
+	if s == "" {
+		return true
+	}
+	_, exists := models.ValidPassportTransitions[s]
+	return exists
+}
@@ -21,6 +21,8 @@ type MagicLinkHandler struct {
 	lifecycleService *services.LifecycleService
 	rewardService    *services.RewardService
 	emailService     *services.EmailService
+	warrantyService  *services.WarrantyService
+	custodyService   *services.CustodyService
 	jwtSecret        string
 	baseURL          string
 }
@@ -37,6 +39,16 @@ func NewMagicLinkHandler(repo *repository.Repository, lifecycleService *services
 	}
 }
 
+// SetWarrantyService enables warranty coverage and open claims in action info
+func (h *MagicLinkHandler) SetWarrantyService(warrantyService *services.WarrantyService) {
+	h.warrantyService = warrantyService
+}
+
+// SetCustodyService records custody hand-overs from magic link status updates
+func (h *MagicLinkHandler) SetCustodyService(custodyService *services.CustodyService) {
+	h.custodyService = custodyService
+}
+
 // RequestMagicLinkRequest is the request body for requesting a magic link
 type RequestMagicLinkRequest struct {
 	PassportID  string `json:"passport_id"`
@@ -252,10 +264,10 @@ func (h *MagicLinkHandler) TransitionWithMagicLink(w http.ResponseWriter, r *htt
 		return
 	}
 
-	// Update current_owner_email if transitioning to certain states
-	if req.ToStatus == models.PassportStatusInService || req.ToStatus == models.PassportStatusShipped {
-		if err := h.repo.UpdatePassportOwner(r.Context(), passportID, claims.Email); err != nil {
-			log.Printf("Warning: Failed to update passport owner: %v", err)
+	// Shipping or installing the battery puts it in the actor's custody
+	if h.custodyService != nil && (req.ToStatus == models.PassportStatusInService || req.ToStatus == models.PassportStatusShipped) {
+		if err := h.custodyService.RecordHandover(r.Context(), passportID, claims.Email, claims.Role); err != nil {
+			log.Printf("Warning: Failed to record custody hand-over: %v", err)
 		}
 	}
 
@@ -352,7 +364,7 @@ func (h *MagicLinkHandler) GetPassportForAction(w http.ResponseWriter, r *http.R
 	// Get allowed transitions
 	allowed := models.GetAllowedTransitions(passport.Status)
 
-	respondJSON(w, http.StatusOK, map[string]interface{}{
+	response := map[string]interface{}{
 		"passport": map[string]interface{}{
 			"uuid":          passport.UUID,
 			"serial_number": passport.SerialNumber,
@@ -363,5 +375,22 @@ func (h *MagicLinkHandler) GetPassportForAction(w http.ResponseWriter, r *http.R
 			"role":  claims.Role,
 		},
 		"allowed_transitions": allowed,
-	})
+	}
+
+	// Warranty position and any open claim, for the technician's claim form
+	if h.warrantyService != nil {
+		if coverage, err := h.warrantyService.Coverage(r.Context(), passport); err != nil {
+			log.Printf("Failed to compute warranty for %s: %v", passport.UUID, err)
+		} else {
+			response["warranty"] = coverage
+		}
+		if claim, err := h.warrantyService.GetOpenClaim(r.Context(), passport.UUID); err != nil {
+			log.Printf("Failed to load open claim for %s: %v", passport.UUID, err)
+		} else if claim != nil {
+			response["open_claim"] = claim
+		}
+		response["fault_codes"] = models.FaultCodes
+	}
+
+	respondJSON(w, http.StatusOK, response)
 }
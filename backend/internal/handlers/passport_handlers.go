@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"time"
+
+	"exportready-battery/internal/models"
 
 	"github.com/google/uuid"
 )
@@ -29,5 +33,59 @@ func (h *Handler) GetPassport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Active recall banner (non-critical: the page still renders without it)
+	h.attachRecallNotice(r.Context(), passportWithSpecs)
+	attachWarranty(passportWithSpecs)
+	attachObligations(passportWithSpecs)
+	h.attachAuditReports(r.Context(), passportWithSpecs, true)
+
 	respondJSON(w, http.StatusOK, passportWithSpecs)
 }
+
+// attachRecallNotice sets the passport's active recall, if any
+func (h *Handler) attachRecallNotice(ctx context.Context, passport *models.PassportWithSpecs) {
+	notice, err := h.repo.GetActiveRecallForPassport(ctx, passport.Passport.UUID)
+	if err != nil {
+		log.Printf("Failed to get recall for passport %s: %v", passport.Passport.UUID, err)
+		return
+	}
+	passport.Recall = notice
+}
+
+// attachWarranty sets the passport's warranty coverage from its batch specs
+func attachWarranty(passport *models.PassportWithSpecs) {
+	if passport.Passport == nil || passport.Specs == nil {
+		return
+	}
+	coverage := models.ComputeWarranty(passport.Passport, passport.Specs.WarrantyMonths, time.Now())
+	passport.Warranty = &coverage
+}
+
+// attachObligations sets the obligations of the passport's battery category
+func attachObligations(passport *models.PassportWithSpecs) {
+	if passport.Specs == nil {
+		return
+	}
+	passport.Obligations = models.ObligationsFor(passport.Specs)
+}
+
+// attachAuditReports lists the batch's due diligence audit reports on the
+// passport. The public view withholds supplier names, here and in the specs;
+// the reports themselves are downloaded through a partner magic link.
+func (h *Handler) attachAuditReports(ctx context.Context, passport *models.PassportWithSpecs, public bool) {
+	if public && passport.Specs != nil {
+		passport.Specs.DueDiligence = passport.Specs.DueDiligence.Public()
+	}
+
+	docs, err := h.repo.ListDueDiligenceDocuments(ctx, passport.Passport.BatchID)
+	if err != nil {
+		log.Printf("Failed to get audit reports for passport %s: %v", passport.Passport.UUID, err)
+		return
+	}
+	for _, doc := range docs {
+		if public {
+			doc = doc.Public()
+		}
+		passport.AuditReports = append(passport.AuditReports, doc)
+	}
+}
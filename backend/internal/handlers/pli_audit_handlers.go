@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"exportready-battery/internal/middleware"
+	"exportready-battery/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// PLIAuditHandler handles the CA attestation of a batch's PLI domestic value
+// addition: requests from the dashboard and the attestation by the CA
+type PLIAuditHandler struct {
+	service *services.PLIAuditService
+}
+
+// NewPLIAuditHandler creates a new PLI audit handler
+func NewPLIAuditHandler(service *services.PLIAuditService) *PLIAuditHandler {
+	return &PLIAuditHandler{service: service}
+}
+
+// respondPLIAuditError maps PLI audit service errors to HTTP responses
+func respondPLIAuditError(w http.ResponseWriter, err error, notFound, action string) {
+	switch {
+	case errors.Is(err, services.ErrPLIAuditInvalid):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrPLIAuditNotFound):
+		respondError(w, http.StatusNotFound, notFound)
+	case errors.Is(err, services.ErrPLIAuditConflict):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("Failed to %s: %v", action, err)
+		respondError(w, http.StatusInternalServerError, "Failed to "+action)
+	}
+}
+
+// pliAuditBatch reads the authenticated tenant and the {id} batch
+func pliAuditBatch(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return uuid.Nil, uuid.Nil, false
+	}
+	batchID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid batch ID format")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return tenantID, batchID, true
+}
+
+// RequestAudit handles POST /api/v1/batches/{id}/pli-audit
+// Invites a chartered accountant to attest the batch's DVA via an emailed one-time link
+func (h *PLIAuditHandler) RequestAudit(w http.ResponseWriter, r *http.Request) {
+	tenantID, batchID, ok := pliAuditBatch(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.RequestPLIAuditRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	audit, err := h.service.RequestAudit(r.Context(), tenantID, batchID, middleware.GetEmail(r.Context()), req)
+	if err != nil {
+		respondPLIAuditError(w, err, "Batch not found", "request PLI audit")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{"audit": audit})
+}
+
+// GetAudits handles GET /api/v1/batches/{id}/pli-audit
+// Returns the batch's DVA source, audit requests and audit log
+func (h *PLIAuditHandler) GetAudits(w http.ResponseWriter, r *http.Request) {
+	tenantID, batchID, ok := pliAuditBatch(w, r)
+	if !ok {
+		return
+	}
+
+	history, err := h.service.History(r.Context(), tenantID, batchID)
+	if err != nil {
+		respondPLIAuditError(w, err, "Batch not found", "get PLI audits")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, history)
+}
+
+// CancelAudit handles POST /api/v1/batches/{id}/pli-audit/{audit}/cancel
+func (h *PLIAuditHandler) CancelAudit(w http.ResponseWriter, r *http.Request) {
+	tenantID, batchID, ok := pliAuditBatch(w, r)
+	if !ok {
+		return
+	}
+	auditID, err := uuid.Parse(r.PathValue("audit"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid audit ID")
+		return
+	}
+
+	audit, err := h.service.Cancel(r.Context(), tenantID, batchID, auditID, middleware.GetEmail(r.Context()))
+	if err != nil {
+		respondPLIAuditError(w, err, "PLI audit not found", "cancel PLI audit")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"audit": audit})
+}
+
+// GetCertificate handles GET /api/v1/batches/{id}/pli-audit/certificate
+// Serves the signed CA certificate of the batch's latest attestation
+func (h *PLIAuditHandler) GetCertificate(w http.ResponseWriter, r *http.Request) {
+	tenantID, batchID, ok := pliAuditBatch(w, r)
+	if !ok {
+		return
+	}
+
+	audit, err := h.service.Certificate(r.Context(), tenantID, batchID)
+	if err != nil {
+		respondPLIAuditError(w, err, "No attested PLI certificate", "get PLI certificate")
+		return
+	}
+
+	if _, err := os.Stat(audit.CertificatePath); os.IsNotExist(err) {
+		respondError(w, http.StatusNotFound, "Certificate file not found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", audit.CertificateFileName))
+	http.ServeFile(w, r, audit.CertificatePath)
+}
+
+// GetAttestation handles GET /api/v1/pli-audit/attest?token=
+// Public: the link token from the email is the credential
+func (h *PLIAuditHandler) GetAttestation(w http.ResponseWriter, r *http.Request) {
+	invitation, err := h.service.GetInvitation(r.Context(), r.URL.Query().Get("token"))
+	if err != nil {
+		respondPLIAuditError(w, err, "PLI audit request not found or already used", "get PLI audit request")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, invitation)
+}
+
+// Attest handles POST /api/v1/pli-audit/attest
+// Public: the link token from the email is the credential. Accepts multipart
+// form with 'token', 'audited_dva' (%), 'ca_membership_no', 'udin', optional
+// 'ca_name' and 'file' (signed certificate, PDF, 5MB)
+func (h *PLIAuditHandler) Attest(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(MaxUploadSize); err != nil {
+		respondError(w, http.StatusBadRequest, "File too large. Maximum size is 5MB")
+		return
+	}
+	file, header, ok := formPDF(w, r)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	audit, err := h.service.Attest(r.Context(), services.AttestPLIAuditRequest{
+		Token:          r.FormValue("token"),
+		AuditedDVA:     r.FormValue("audited_dva"),
+		CAName:         r.FormValue("ca_name"),
+		CAMembershipNo: r.FormValue("ca_membership_no"),
+		UDIN:           r.FormValue("udin"),
+		FileName:       header.Filename,
+	}, file)
+	if err != nil {
+		respondPLIAuditError(w, err, "PLI audit request not found or already used", "attest PLI audit")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"audit": audit})
+}
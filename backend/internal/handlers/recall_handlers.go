@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"exportready-battery/internal/middleware"
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+	"exportready-battery/internal/services"
+
+	"github.com/google/uuid"
+)
+
+const maxRecallRemedyBatch = 1000
+
+// RecallHandler handles recall campaigns
+type RecallHandler struct {
+	service *services.RecallService
+}
+
+// NewRecallHandler creates a new recall handler
+func NewRecallHandler(service *services.RecallService) *RecallHandler {
+	return &RecallHandler{service: service}
+}
+
+// recallTenantID reads the authenticated tenant
+func recallTenantID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return uuid.Nil, false
+	}
+	return tenantID, true
+}
+
+// recallCampaignID reads the {id} path value
+func recallCampaignID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid recall ID")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondRecallError maps recall service errors to HTTP responses
+func respondRecallError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, services.ErrRecallInvalid):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrRecallNotFound):
+		respondError(w, http.StatusNotFound, "Recall campaign not found")
+	case errors.Is(err, services.ErrRecallClosed):
+		respondError(w, http.StatusConflict, "Recall campaign is closed")
+	default:
+		log.Printf("Failed to %s: %v", action, err)
+		respondError(w, http.StatusInternalServerError, "Failed to "+action)
+	}
+}
+
+// PreviewRecall handles POST /api/v1/recalls/preview
+// Counts the passports a scope would recall without changing anything
+func (h *RecallHandler) PreviewRecall(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := recallTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	var scope models.RecallScope
+	if err := json.NewDecoder(r.Body).Decode(&scope); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	preview, err := h.service.PreviewRecall(r.Context(), tenantID, scope)
+	if err != nil {
+		respondRecallError(w, err, "preview recall")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, preview)
+}
+
+// CreateRecall handles POST /api/v1/recalls
+// Opens a campaign, moves every passport in scope to RECALLED and emails known owners
+func (h *RecallHandler) CreateRecall(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := recallTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.CreateRecallRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.service.CreateRecall(r.Context(), tenantID, middleware.GetEmail(r.Context()), req)
+	if err != nil {
+		respondRecallError(w, err, "create recall")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, result)
+}
+
+// ListRecalls handles GET /api/v1/recalls?status=ACTIVE&limit=20&cursor=
+func (h *RecallHandler) ListRecalls(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := recallTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	params, err := parseListParams(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if params.Status != "" && params.Status != models.RecallStatusActive && params.Status != models.RecallStatusClosed {
+		respondError(w, http.StatusBadRequest, "Invalid status. Must be ACTIVE or CLOSED")
+		return
+	}
+	page, err := params.pageRequest(repository.SortRecallsNewest, false)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+
+	campaigns, info, err := h.service.ListRecalls(r.Context(), tenantID, params.Status, page)
+	if err != nil {
+		respondRecallError(w, err, "list recalls")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, listResponse("recalls", campaigns, len(campaigns), page, info))
+}
+
+// GetRecall handles GET /api/v1/recalls/{id}
+// Returns the campaign with its remedy progress report
+func (h *RecallHandler) GetRecall(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := recallTenantID(w, r)
+	if !ok {
+		return
+	}
+	campaignID, ok := recallCampaignID(w, r)
+	if !ok {
+		return
+	}
+
+	campaign, progress, err := h.service.GetRecall(r.Context(), tenantID, campaignID)
+	if err != nil {
+		respondRecallError(w, err, "get recall")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"recall":   campaign,
+		"progress": progress,
+	})
+}
+
+// ListRecallUnits handles GET /api/v1/recalls/{id}/units?remedy_status=PENDING&limit=50&cursor=
+func (h *RecallHandler) ListRecallUnits(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := recallTenantID(w, r)
+	if !ok {
+		return
+	}
+	campaignID, ok := recallCampaignID(w, r)
+	if !ok {
+		return
+	}
+
+	params, err := parseListParams(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	remedyStatus := r.URL.Query().Get("remedy_status")
+	if remedyStatus != "" && remedyStatus != models.RemedyPending && !models.IsValidRemedyStatus(remedyStatus) {
+		respondError(w, http.StatusBadRequest, "Invalid remedy_status. Must be PENDING, NOTIFIED, RETURNED, REPLACED or RECYCLED")
+		return
+	}
+	page, err := params.pageRequest(repository.SortRecallUnitsSerial, false)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+
+	units, info, err := h.service.ListRecallUnits(r.Context(), tenantID, campaignID, remedyStatus, page)
+	if err != nil {
+		respondRecallError(w, err, "list recall units")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, listResponse("units", units, len(units), page, info))
+}
+
+// UpdateRecallRemedies handles POST /api/v1/recalls/{id}/remedies
+// Sets the remedy status of up to 1000 units; RECYCLED also recycles the passport
+func (h *RecallHandler) UpdateRecallRemedies(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := recallTenantID(w, r)
+	if !ok {
+		return
+	}
+	campaignID, ok := recallCampaignID(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.UpdateRemediesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if len(req.PassportIDs) == 0 {
+		respondError(w, http.StatusBadRequest, "passport_ids is required")
+		return
+	}
+	if len(req.PassportIDs) > maxRecallRemedyBatch {
+		respondError(w, http.StatusBadRequest, "Maximum 1000 passports per request")
+		return
+	}
+
+	result, err := h.service.UpdateRemedies(r.Context(), tenantID, campaignID, middleware.GetEmail(r.Context()), req)
+	if err != nil {
+		respondRecallError(w, err, "update recall remedies")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// NotifyRecallOwners handles POST /api/v1/recalls/{id}/notify
+// Emails owners not yet notified (e.g. registered after the recall) in the background
+func (h *RecallHandler) NotifyRecallOwners(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := recallTenantID(w, r)
+	if !ok {
+		return
+	}
+	campaignID, ok := recallCampaignID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.NotifyRecallOwners(r.Context(), tenantID, campaignID); err != nil {
+		respondRecallError(w, err, "notify recall owners")
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, map[string]string{
+		"message": "Owner notification started",
+	})
+}
+
+// CloseRecall handles POST /api/v1/recalls/{id}/close
+// Ends the campaign and removes the banner from its passports
+func (h *RecallHandler) CloseRecall(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := recallTenantID(w, r)
+	if !ok {
+		return
+	}
+	campaignID, ok := recallCampaignID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.CloseRecall(r.Context(), tenantID, campaignID); err != nil {
+		respondRecallError(w, err, "close recall")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{
+		"message": "Recall campaign closed",
+	})
+}
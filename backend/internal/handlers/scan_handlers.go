@@ -81,6 +81,18 @@ func (h *Handler) RecordScan(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Push to the owner's live feed (non-critical)
+	if err := h.repo.PublishPassportEvent(r.Context(), passportID, models.EventScanRecorded, models.ScanRecordedData{
+		ID:         scanEvent.ID,
+		PassportID: passportID,
+		City:       scanEvent.City,
+		Country:    scanEvent.Country,
+		DeviceType: scanEvent.DeviceType,
+		ScannedAt:  scanEvent.ScannedAt,
+	}); err != nil {
+		log.Printf("Failed to publish scan event: %v", err)
+	}
+
 	respondJSON(w, http.StatusCreated, map[string]interface{}{
 		"status":  "recorded",
 		"city":    geoResult.City,
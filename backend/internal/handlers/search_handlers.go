@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"exportready-battery/internal/middleware"
+	"exportready-battery/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	minSearchQueryLength = 2
+	maxSearchQueryLength = 100
+)
+
+// SearchPassports handles GET /api/v1/passports/search?q=00042&status=SHIPPED&page=1&limit=20
+// Matches serial numbers/BPANs, custodian email, batch name, Bill of Entry, HSN code and
+// batch spec values. Also accepts batch_id, market_region, chemistry and created_after/before.
+func (h *Handler) SearchPassports(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params, filter, ok := parseSearchFilter(w, r)
+	if !ok {
+		return
+	}
+	filter.MarketRegions = params.MarketRegions
+
+	h.searchPassports(w, r, tenantID, params, filter)
+}
+
+// ExternalSearchPassports handles GET /api/v1/external/passports/search?q=
+// Same as SearchPassports, limited to the batches and markets the API key may see.
+func (h *Handler) ExternalSearchPassports(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := externalTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	params, filter, ok := parseSearchFilter(w, r)
+	if !ok {
+		return
+	}
+	filter.BatchIDs, filter.MarketRegions = externalKeyRestrictions(r)
+	if !applyExternalMarketFilter(w, r, params.MarketRegions, &filter.MarketRegions) {
+		return
+	}
+
+	h.searchPassports(w, r, tenantID, params, filter)
+}
+
+// parseSearchFilter reads the list grammar for search and validates it
+func parseSearchFilter(w http.ResponseWriter, r *http.Request) (listParams, repository.PassportFilter, bool) {
+	var filter repository.PassportFilter
+
+	params, err := parseListParams(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return params, filter, false
+	}
+	if params.Cursor != "" {
+		respondError(w, http.StatusBadRequest, "Search results are ranked; page with page and limit instead of cursor")
+		return params, filter, false
+	}
+	if !validPassportStatus(params.Status) {
+		respondError(w, http.StatusBadRequest, "Invalid status")
+		return params, filter, false
+	}
+
+	filter = repository.PassportFilter{
+		Status:        params.Status,
+		SerialPrefix:  params.SerialPrefix,
+		Chemistry:     params.Chemistry,
+		CreatedAfter:  params.CreatedAfter,
+		CreatedBefore: params.CreatedBefore,
+	}
+	if batchIDStr := r.URL.Query().Get("batch_id"); batchIDStr != "" {
+		batchID, err := uuid.Parse(batchIDStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid batch_id")
+			return params, filter, false
+		}
+		filter.BatchID = &batchID
+	}
+
+	return params, filter, true
+}
+
+// searchPassports runs the search and facet queries and writes the response
+func (h *Handler) searchPassports(w http.ResponseWriter, r *http.Request, tenantID uuid.UUID, params listParams, filter repository.PassportFilter) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if n := utf8.RuneCountInString(q); n < minSearchQueryLength || n > maxSearchQueryLength {
+		respondError(w, http.StatusBadRequest, "q must be between 2 and 100 characters")
+		return
+	}
+
+	page := repository.PageRequest{Limit: params.Limit, Page: params.Page, WithTotal: true}
+	results, info, err := h.repo.SearchPassports(r.Context(), tenantID, q, filter, page)
+	if err != nil {
+		log.Printf("Failed to search passports: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to search passports")
+		return
+	}
+
+	facets, err := h.repo.SearchPassportFacets(r.Context(), tenantID, q, filter)
+	if err != nil {
+		log.Printf("Failed to count search facets: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to search passports")
+		return
+	}
+
+	resp := listResponse("results", results, len(results), page, info)
+	resp["query"] = q
+	resp["facets"] = facets
+	respondJSON(w, http.StatusOK, resp)
+}
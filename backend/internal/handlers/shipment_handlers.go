@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"exportready-battery/internal/middleware"
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+	"exportready-battery/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// unsafeFileChars are replaced in download file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ShipmentHandler handles export shipments and their customs documentation pack
+type ShipmentHandler struct {
+	service *services.ShipmentService
+}
+
+// NewShipmentHandler creates a new shipment handler
+func NewShipmentHandler(service *services.ShipmentService) *ShipmentHandler {
+	return &ShipmentHandler{service: service}
+}
+
+// shipmentRequest reads the authenticated tenant and the {id} shipment
+func shipmentRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid shipment ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return tenantID, id, true
+}
+
+// respondShipmentError maps shipment service errors to HTTP responses
+func respondShipmentError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, services.ErrShipmentInvalid):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrShipmentNotFound):
+		respondError(w, http.StatusNotFound, "Shipment not found")
+	case errors.Is(err, services.ErrShipmentConflict):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("Failed to %s: %v", action, err)
+		respondError(w, http.StatusInternalServerError, "Failed to "+action)
+	}
+}
+
+// CreateShipment handles POST /api/v1/shipments
+// Drafts a shipment from whole batches and/or single passports and reserves them
+func (h *ShipmentHandler) CreateShipment(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req services.CreateShipmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	detail, err := h.service.CreateShipment(r.Context(), tenantID, middleware.GetEmail(r.Context()), req)
+	if err != nil {
+		respondShipmentError(w, err, "create shipment")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, detail)
+}
+
+// ListShipments handles GET /api/v1/shipments?status=DRAFT&limit=20&cursor=
+func (h *ShipmentHandler) ListShipments(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params, err := parseListParams(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	switch params.Status {
+	case "", models.ShipmentStatusDraft, models.ShipmentStatusShipped, models.ShipmentStatusCancelled:
+	default:
+		respondError(w, http.StatusBadRequest, "Invalid status. Must be DRAFT, SHIPPED or CANCELLED")
+		return
+	}
+	page, err := params.pageRequest(repository.SortShipmentsNewest, false)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+
+	shipments, info, err := h.service.ListShipments(r.Context(), tenantID, params.Status, page)
+	if err != nil {
+		respondShipmentError(w, err, "list shipments")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, listResponse("shipments", shipments, len(shipments), page, info))
+}
+
+// GetShipment handles GET /api/v1/shipments/{id}
+// Returns the shipment with its invoice lines and missing document data
+func (h *ShipmentHandler) GetShipment(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := shipmentRequest(w, r)
+	if !ok {
+		return
+	}
+
+	detail, err := h.service.GetShipment(r.Context(), tenantID, id)
+	if err != nil {
+		respondShipmentError(w, err, "get shipment")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, detail)
+}
+
+// GetShipmentDocuments handles GET /api/v1/shipments/{id}/documents?format=zip|pdf
+// Downloads the customs documentation pack: the PDF alone, or a ZIP with the
+// PDF, a CSV passport index and the QR codes
+func (h *ShipmentHandler) GetShipmentDocuments(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := shipmentRequest(w, r)
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "zip"
+	}
+	if format != "zip" && format != "pdf" {
+		respondError(w, http.StatusBadRequest, "format must be zip or pdf")
+		return
+	}
+
+	pack, err := h.service.Pack(r.Context(), tenantID, id)
+	if err != nil {
+		respondShipmentError(w, err, "get shipment documents")
+		return
+	}
+
+	// Rendered to a buffer so a failure can still be reported as JSON
+	var buf bytes.Buffer
+	contentType := "application/zip"
+	if format == "pdf" {
+		contentType = "application/pdf"
+		err = h.service.WritePackPDF(pack, &buf)
+	} else {
+		err = h.service.WritePackZIP(pack, &buf)
+	}
+	if err != nil {
+		respondShipmentError(w, err, "generate shipment documents")
+		return
+	}
+
+	filename := fmt.Sprintf("customs-pack-%s.%s", unsafeFileChars.ReplaceAllString(pack.Detail.Shipment.Reference, "_"), format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// ShipShipment handles POST /api/v1/shipments/{id}/ship
+// Moves every passport of the draft shipment to SHIPPED in one step
+func (h *ShipmentHandler) ShipShipment(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := shipmentRequest(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.ShipShipment(r.Context(), tenantID, id, middleware.GetEmail(r.Context()))
+	if err != nil {
+		respondShipmentError(w, err, "ship shipment")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// CancelShipment handles POST /api/v1/shipments/{id}/cancel
+// Cancels a draft shipment and releases its passports
+func (h *ShipmentHandler) CancelShipment(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := shipmentRequest(w, r)
+	if !ok {
+		return
+	}
+
+	shipment, err := h.service.CancelShipment(r.Context(), tenantID, id)
+	if err != nil {
+		respondShipmentError(w, err, "cancel shipment")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"shipment": shipment})
+}
@@ -28,6 +28,11 @@ func (h *Handler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	warnings, ok := checkBatchSpec(w, &req.Specs)
+	if !ok {
+		return
+	}
+
 	template, err := h.repo.CreateTemplate(r.Context(), req.TenantID, req.Name, req.Specs)
 	if err != nil {
 		log.Printf("Failed to create template: %v", err)
@@ -35,9 +40,11 @@ func (h *Handler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respondJSON(w, http.StatusCreated, map[string]interface{}{
-		"template": template,
-	})
+	response := map[string]interface{}{"template": template}
+	if len(warnings) > 0 {
+		response["warnings"] = warnings
+	}
+	respondJSON(w, http.StatusCreated, response)
 }
 
 // ListTemplates handles GET /api/v1/templates?tenant_id=xxx
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
 
 	"github.com/google/uuid"
 )
@@ -25,7 +27,7 @@ func (h *Handler) UploadCSV(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Verify batch exists
-	_, err = h.repo.GetBatch(r.Context(), batchID)
+	batch, err := h.repo.GetBatch(r.Context(), batchID)
 	if err != nil {
 		respondError(w, http.StatusNotFound, "Batch not found")
 		return
@@ -88,8 +90,9 @@ func (h *Handler) UploadCSV(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Bulk insert passports
-	insertedCount, err := h.repo.CreatePassportsBatch(r.Context(), parseResult.Passports)
+	// Bulk insert passports, reporting progress on the tenant event stream
+	job := h.startImport(r.Context(), batch.TenantID, batchID, models.ImportSourceCSV, len(parseResult.Passports), len(parseResult.Errors))
+	insertedCount, err := job.insert(r.Context(), parseResult.Passports)
 	if err != nil {
 		log.Printf("Failed to insert passports: %v", err)
 		respondError(w, http.StatusInternalServerError, "Failed to save passports to database")
@@ -290,7 +293,8 @@ func (h *Handler) AutoGeneratePassports(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Bulk insert
-	insertedCount, err := h.repo.CreatePassportsBatch(r.Context(), passports)
+	job := h.startImport(r.Context(), batch.TenantID, batchID, models.ImportSourceAutoGenerate, len(passports), 0)
+	insertedCount, err := job.insert(r.Context(), passports)
 	if err != nil {
 		log.Printf("Failed to insert auto-generated passports: %v", err)
 		respondError(w, http.StatusInternalServerError, "Failed to generate passports")
@@ -308,3 +312,52 @@ func (h *Handler) AutoGeneratePassports(w http.ResponseWriter, r *http.Request)
 		"qr_codes_ready":    true,
 	})
 }
+
+// passportImport reports a bulk passport insert on the tenant event stream
+type passportImport struct {
+	repo     *repository.Repository
+	tenantID uuid.UUID
+	data     models.ImportProgressData
+}
+
+// startImport announces an import of total passports into a batch
+func (h *Handler) startImport(ctx context.Context, tenantID, batchID uuid.UUID, source string, total, rejected int) *passportImport {
+	job := &passportImport{
+		repo:     h.repo,
+		tenantID: tenantID,
+		data: models.ImportProgressData{
+			JobID:    uuid.New(),
+			BatchID:  batchID,
+			Source:   source,
+			Total:    total,
+			Rejected: rejected,
+		},
+	}
+	job.publish(ctx, models.ImportStageStarted)
+	return job
+}
+
+// insert writes the passports, publishing progress after each chunk and the outcome
+func (j *passportImport) insert(ctx context.Context, passports []*models.Passport) (int, error) {
+	inserted, err := j.repo.CreatePassportsBatchWithProgress(ctx, passports, func(n int) {
+		j.data.Processed = n
+		j.publish(ctx, models.ImportStageInserting)
+	})
+	if err != nil {
+		j.data.Processed = 0
+		j.data.Error = "Failed to save passports"
+		j.publish(ctx, models.ImportStageFailed)
+		return 0, err
+	}
+
+	j.data.Processed = inserted
+	j.publish(ctx, models.ImportStageCompleted)
+	return inserted, nil
+}
+
+func (j *passportImport) publish(ctx context.Context, stage string) {
+	j.data.Stage = stage
+	if err := j.repo.PublishTenantEvent(ctx, j.tenantID, models.EventImportProgress, j.data); err != nil {
+		log.Printf("Failed to publish import progress: %v", err)
+	}
+}
@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"exportready-battery/internal/auth"
+	"exportready-battery/internal/middleware"
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+	"exportready-battery/internal/services"
+
+	"github.com/google/uuid"
+)
+
+const maxClaimPhotoSize = 5 << 20 // 5MB per photo
+
+// WarrantyHandler handles warranty claims: filing through a magic link and the
+// manufacturer's review in the dashboard
+type WarrantyHandler struct {
+	service   *services.WarrantyService
+	repo      *repository.Repository
+	jwtSecret string
+}
+
+// NewWarrantyHandler creates a new warranty handler
+func NewWarrantyHandler(service *services.WarrantyService, repo *repository.Repository, jwtSecret string) *WarrantyHandler {
+	return &WarrantyHandler{service: service, repo: repo, jwtSecret: jwtSecret}
+}
+
+// respondClaimError maps warranty service errors to HTTP responses
+func respondClaimError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, services.ErrClaimInvalid):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrClaimNotFound):
+		respondError(w, http.StatusNotFound, "Warranty claim not found")
+	case errors.Is(err, services.ErrClaimState):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("Failed to %s: %v", action, err)
+		respondError(w, http.StatusInternalServerError, "Failed to "+action)
+	}
+}
+
+// SubmitClaim handles POST /api/v1/passport/{uuid}/warranty-claim
+// Authenticated by magic link. Multipart form: fault_code, state_of_health,
+// description (optional) and up to 6 photos (JPEG, PNG or WebP, 5MB each).
+// Moves the passport to RETURN_REQUESTED.
+func (h *WarrantyHandler) SubmitClaim(w http.ResponseWriter, r *http.Request) {
+	passportIDStr := r.PathValue("uuid")
+	passportID, err := uuid.Parse(passportIDStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid passport UUID")
+		return
+	}
+
+	// Extract token from Authorization header
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+		respondError(w, http.StatusUnauthorized, "Authorization header required. Use: Bearer <magic_link_token>")
+		return
+	}
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+
+	claims, err := auth.ValidateMagicTokenForPassport(tokenString, h.jwtSecret, passportIDStr)
+	if err != nil {
+		log.Printf("Magic token validation failed: %v", err)
+		respondError(w, http.StatusUnauthorized, "Invalid or expired magic link")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxClaimPhotos*maxClaimPhotoSize+(1<<20))
+	if err := r.ParseMultipartForm(maxClaimPhotoSize); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid form. Maximum 6 photos of 5MB each")
+		return
+	}
+
+	soh, err := strconv.ParseFloat(strings.TrimSpace(r.FormValue("state_of_health")), 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "state_of_health is required (0-100)")
+		return
+	}
+
+	var headers []*multipart.FileHeader
+	if r.MultipartForm != nil {
+		headers = r.MultipartForm.File["photos"]
+	}
+	if len(headers) > services.MaxClaimPhotos {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("At most %d photos", services.MaxClaimPhotos))
+		return
+	}
+
+	photos := make([]services.ClaimPhoto, 0, len(headers))
+	for _, header := range headers {
+		if header.Size > maxClaimPhotoSize {
+			respondError(w, http.StatusBadRequest, "Photo too large. Maximum size is 5MB")
+			return
+		}
+		ext, ok := claimPhotoExt(header)
+		if !ok {
+			respondError(w, http.StatusBadRequest, "Only JPEG, PNG and WebP photos are allowed")
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Failed to read photo")
+			return
+		}
+		defer file.Close()
+		photos = append(photos, services.ClaimPhoto{Ext: ext, Content: file})
+	}
+
+	claim, err := h.service.SubmitClaim(r.Context(), services.SubmitClaimRequest{
+		PassportID:    passportID,
+		FaultCode:     r.FormValue("fault_code"),
+		Description:   r.FormValue("description"),
+		StateOfHealth: soh,
+		Actor:         claims.Email,
+		ActorRole:     claims.Role,
+	}, photos)
+	if err != nil {
+		respondClaimError(w, err, "submit warranty claim")
+		return
+	}
+
+	// Mark token as used, as for magic link transitions
+	if err := h.repo.MarkMagicTokenUsed(r.Context(), auth.HashToken(tokenString)); err != nil {
+		log.Printf("Warning: Failed to mark token as used: %v", err)
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"claim":   claim,
+		"message": "Warranty claim submitted. The manufacturer will review it and issue an RMA number if approved.",
+	})
+}
+
+// claimPhotoExt returns the stored extension for an accepted photo type
+func claimPhotoExt(header *multipart.FileHeader) (string, bool) {
+	contentType := header.Header.Get("Content-Type")
+	ext := strings.ToLower(filepath.Ext(header.Filename))
+
+	switch {
+	case contentType == "image/png" || ext == ".png":
+		return ".png", true
+	case contentType == "image/jpeg" || ext == ".jpg" || ext == ".jpeg":
+		return ".jpg", true
+	case contentType == "image/webp" || ext == ".webp":
+		return ".webp", true
+	}
+	return "", false
+}
+
+// claimTenantID reads the authenticated tenant
+func claimTenantID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return uuid.Nil, false
+	}
+	return tenantID, true
+}
+
+// claimID reads the {id} path value
+func claimID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid claim ID")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// ListClaims handles GET /api/v1/warranty-claims?status=SUBMITTED&limit=20&cursor=
+func (h *WarrantyHandler) ListClaims(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := claimTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	params, err := parseListParams(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	switch params.Status {
+	case "", models.ClaimStatusSubmitted, models.ClaimStatusApproved, models.ClaimStatusRejected, models.ClaimStatusReceived:
+	default:
+		respondError(w, http.StatusBadRequest, "Invalid status. Must be SUBMITTED, APPROVED, REJECTED or RECEIVED")
+		return
+	}
+	page, err := params.pageRequest(repository.SortClaimsNewest, false)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+
+	claims, info, err := h.service.ListClaims(r.Context(), tenantID, params.Status, page)
+	if err != nil {
+		respondClaimError(w, err, "list warranty claims")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, listResponse("claims", claims, len(claims), page, info))
+}
+
+// GetClaim handles GET /api/v1/warranty-claims/{id}
+func (h *WarrantyHandler) GetClaim(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := claimTenantID(w, r)
+	if !ok {
+		return
+	}
+	id, ok := claimID(w, r)
+	if !ok {
+		return
+	}
+
+	claim, err := h.service.GetClaim(r.Context(), tenantID, id)
+	if err != nil {
+		respondClaimError(w, err, "get warranty claim")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"claim": claim})
+}
+
+// GetClaimPhoto handles GET /api/v1/warranty-claims/{id}/photos/{name}
+func (h *WarrantyHandler) GetClaimPhoto(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := claimTenantID(w, r)
+	if !ok {
+		return
+	}
+	id, ok := claimID(w, r)
+	if !ok {
+		return
+	}
+
+	claim, err := h.service.GetClaim(r.Context(), tenantID, id)
+	if err != nil {
+		respondClaimError(w, err, "get warranty claim")
+		return
+	}
+
+	path := h.service.ClaimPhotoPath(claim, r.PathValue("name"))
+	if path == "" {
+		respondError(w, http.StatusNotFound, "Photo not found")
+		return
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		respondError(w, http.StatusNotFound, "Photo file not found")
+		return
+	}
+
+	http.ServeFile(w, r, path)
+}
+
+// ClaimDecisionRequest is the body of approve and reject
+type ClaimDecisionRequest struct {
+	Note string `json:"note,omitempty"` // Required to reject; shown to the technician
+}
+
+// ApproveClaim handles POST /api/v1/warranty-claims/{id}/approve
+// Issues the RMA number; the passport stays RETURN_REQUESTED until the unit is received
+func (h *WarrantyHandler) ApproveClaim(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.service.ApproveClaim, "approve warranty claim")
+}
+
+// RejectClaim handles POST /api/v1/warranty-claims/{id}/reject
+// Moves the passport back to IN_SERVICE
+func (h *WarrantyHandler) RejectClaim(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.service.RejectClaim, "reject warranty claim")
+}
+
+type claimDecision func(ctx context.Context, tenantID, claimID uuid.UUID, actor, note string) (*models.WarrantyClaim, error)
+
+func (h *WarrantyHandler) decide(w http.ResponseWriter, r *http.Request, decide claimDecision, action string) {
+	tenantID, ok := claimTenantID(w, r)
+	if !ok {
+		return
+	}
+	id, ok := claimID(w, r)
+	if !ok {
+		return
+	}
+
+	var req ClaimDecisionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	claim, err := decide(r.Context(), tenantID, id, middleware.GetEmail(r.Context()), req.Note)
+	if err != nil {
+		respondClaimError(w, err, action)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"claim": claim})
+}
+
+// ReceiveClaim handles POST /api/v1/warranty-claims/{id}/receive
+// Records the unit's arrival against its RMA; the passport becomes RETURNED
+func (h *WarrantyHandler) ReceiveClaim(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := claimTenantID(w, r)
+	if !ok {
+		return
+	}
+	id, ok := claimID(w, r)
+	if !ok {
+		return
+	}
+
+	claim, err := h.service.ReceiveClaim(r.Context(), tenantID, id, middleware.GetEmail(r.Context()))
+	if err != nil {
+		respondClaimError(w, err, "receive warranty claim")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"claim": claim})
+}
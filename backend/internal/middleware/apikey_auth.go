@@ -2,8 +2,13 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
 	"net/http"
-	"strings"
+	"strconv"
 	"time"
 
 	"exportready-battery/internal/models"
@@ -21,64 +26,136 @@ const (
 	APIKeyScopeKey APIKeyContextKey = "api_key_scope"
 	// APIKeyTenantIDKey is the context key for tenant ID from API key
 	APIKeyTenantIDKey APIKeyContextKey = "api_key_tenant_id"
+	// APIKeyKey is the context key for the full API key record
+	APIKeyKey APIKeyContextKey = "api_key"
 )
 
 // APIKeyAuth middleware for API key authentication
 type APIKeyAuth struct {
 	repo       *repository.Repository
 	keyService *services.APIKeyService
+	buckets    BucketStore
+	usage      *services.APIUsageRecorder
+
+	proxies *TrustedProxies // Optional: proxies whose forwarding headers give the client IP
 }
 
 // NewAPIKeyAuth creates a new API key auth middleware
-func NewAPIKeyAuth(repo *repository.Repository, keyService *services.APIKeyService) *APIKeyAuth {
+func NewAPIKeyAuth(repo *repository.Repository, keyService *services.APIKeyService, buckets BucketStore, usage *services.APIUsageRecorder) *APIKeyAuth {
 	return &APIKeyAuth{
 		repo:       repo,
 		keyService: keyService,
+		buckets:    buckets,
+		usage:      usage,
 	}
 }
 
-// Authenticate validates API key for any scope (read or write)
+// SetTrustedProxies sets the proxies whose X-Forwarded-For is used for the
+// client IP of CIDR restrictions and usage logs. Without them the connection
+// address is used.
+func (a *APIKeyAuth) SetTrustedProxies(proxies *TrustedProxies) {
+	a.proxies = proxies
+}
+
+// Authenticate validates the API key without requiring any particular scope
 func (a *APIKeyAuth) Authenticate(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		key, err := a.validateKey(w, r)
-		if err != nil {
-			return // Error already written
-		}
+	return a.RequireScopes()(next)
+}
 
-		// Check rate limit
-		if !a.checkRateLimit(w, r, key, "read") {
-			return
-		}
+// RequireScopes returns a middleware that validates the API key, enforces its
+// IP restriction and requires every listed scope. External routes declare their
+// scopes at registration:
+//
+//	apiKeyMiddleware.RequireScopes(models.ScopeBatchesWrite)(http.HandlerFunc(h.ExternalCreateBatch))
+func (a *APIKeyAuth) RequireScopes(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, err := a.validateKey(w, r)
+			if err != nil {
+				return // Error already written
+			}
+
+			// Log every request made with a valid key, including rejected ones
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			w = rec
+			defer a.recordUsage(key, r, rec, time.Now())
+
+			// Check IP restriction
+			if !a.allowsClientIP(key, r) {
+				http.Error(w, `{"error":"API key not allowed from this IP address"}`, http.StatusForbidden)
+				return
+			}
+
+			// Check scopes
+			for _, scope := range scopes {
+				if !key.HasScope(scope) {
+					http.Error(w, fmt.Sprintf(`{"error":"insufficient scope - %s required"}`, scope), http.StatusForbidden)
+					return
+				}
+			}
+
+			// Check rate limit
+			opType := "read"
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				opType = "write"
+			}
+			if !a.checkRateLimit(w, r, key, opType) {
+				return
+			}
 
-		// Add key info to context
-		ctx := a.enrichContext(r.Context(), key)
-		next.ServeHTTP(w, r.WithContext(ctx))
+			// Add key info to context
+			ctx := a.enrichContext(r.Context(), key)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// recordUsage queues a request log entry for the key
+func (a *APIKeyAuth) recordUsage(key *models.APIKey, r *http.Request, rec *statusRecorder, start time.Time) {
+	if a.usage == nil {
+		return
+	}
+
+	endpoint := r.Pattern
+	if endpoint == "" {
+		endpoint = r.Method + " " + r.URL.Path
+	}
+
+	a.usage.Record(&models.APIRequestLog{
+		APIKeyID:   key.ID,
+		TenantID:   key.TenantID,
+		Method:     r.Method,
+		Endpoint:   endpoint,
+		Path:       r.URL.Path,
+		StatusCode: rec.status,
+		LatencyMs:  int(time.Since(start).Milliseconds()),
+		IPAddress:  a.proxies.ClientIP(r),
+		UserAgent:  r.UserAgent(),
+		CreatedAt:  start,
 	})
 }
 
-// AuthenticateWrite validates API key and requires write scope
-func (a *APIKeyAuth) AuthenticateWrite(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		key, err := a.validateKey(w, r)
-		if err != nil {
-			return // Error already written
-		}
+// allowsClientIP checks the request IP against the key's CIDR restriction
+func (a *APIKeyAuth) allowsClientIP(key *models.APIKey, r *http.Request) bool {
+	if len(key.AllowedCIDRs) == 0 {
+		return true
+	}
 
-		// Check scope
-		if key.Scope != "write" {
-			http.Error(w, `{"error":"insufficient scope - write access required"}`, http.StatusForbidden)
-			return
-		}
+	ip := net.ParseIP(a.proxies.ClientIP(r))
+	if ip == nil {
+		return false
+	}
 
-		// Check rate limit
-		if !a.checkRateLimit(w, r, key, "write") {
-			return
+	for _, cidr := range key.AllowedCIDRs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
 		}
-
-		// Add key info to context
-		ctx := a.enrichContext(r.Context(), key)
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
 }
 
 // validateKey extracts and validates the API key from the request
@@ -96,25 +173,18 @@ func (a *APIKeyAuth) validateKey(w http.ResponseWriter, r *http.Request) (*model
 		return nil, http.ErrAbortHandler
 	}
 
-	// Get prefix for lookup
-	prefix := a.keyService.ExtractPrefix(keyHeader)
-
-	// Get all active keys with this prefix
-	keys, err := a.repo.GetAllActiveAPIKeys(r.Context())
-	if err != nil {
-		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
-		return nil, http.ErrAbortHandler
-	}
-
-	// Find matching key by validating hash
-	var matchedKey *models.APIKey
-	for _, key := range keys {
-		if strings.HasPrefix(key.KeyPrefix, prefix[:len(services.KeyPrefix)+4]) {
-			if a.keyService.ValidateKey(keyHeader, key.KeyHash) {
-				matchedKey = key
-				break
-			}
+	// Serve recently validated keys from cache (skips the query and bcrypt)
+	matchedKey, cached := a.repo.GetCachedAPIKey(keyHeader)
+	if !cached {
+		key, err := a.lookupKey(r.Context(), keyHeader)
+		if err != nil {
+			http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+			return nil, http.ErrAbortHandler
+		}
+		if key != nil {
+			a.repo.CacheValidatedAPIKey(keyHeader, key)
 		}
+		matchedKey = key
 	}
 
 	if matchedKey == nil {
@@ -134,46 +204,87 @@ func (a *APIKeyAuth) validateKey(w http.ResponseWriter, r *http.Request) (*model
 	return matchedKey, nil
 }
 
-// checkRateLimit checks if the request is within rate limits
+// lookupKey fetches the single row for the key's unique prefix and verifies its hash.
+// Keys issued before unique prefixes fall back to the few rows sharing a 4-char prefix.
+// Returns nil, nil when no key matches.
+func (a *APIKeyAuth) lookupKey(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	key, err := a.repo.GetAPIKeyByPrefix(ctx, a.keyService.ExtractPrefix(rawKey))
+	if err == nil {
+		if a.keyService.ValidateKey(rawKey, key.KeyHash) {
+			return key, nil
+		}
+		return nil, nil
+	}
+	if !errors.Is(err, repository.ErrAPIKeyNotFound) {
+		return nil, err
+	}
+
+	legacyKeys, err := a.repo.GetAPIKeysByLegacyPrefix(ctx, a.keyService.ExtractLegacyPrefix(rawKey))
+	if err != nil {
+		return nil, err
+	}
+	for _, legacyKey := range legacyKeys {
+		if a.keyService.ValidateKey(rawKey, legacyKey.KeyHash) {
+			return legacyKey, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// checkRateLimit takes a token from the key's bucket for this operation type
 func (a *APIKeyAuth) checkRateLimit(w http.ResponseWriter, r *http.Request, key *models.APIKey, opType string) bool {
 	// Get rate limits for tier
 	limits := models.GetRateLimits(key.RateLimitTier)
 
-	var limit int
+	perHour, burst := limits.ReadLimit, limits.ReadBurst
 	if opType == "write" {
-		limit = limits.WriteLimit
-	} else {
-		limit = limits.ReadLimit
+		perHour, burst = limits.WriteLimit, limits.WriteBurst
+	}
+	if burst <= 0 {
+		burst = perHour
 	}
 
-	// Get current usage and increment
-	count, err := a.repo.IncrementUsage(r.Context(), key.ID, opType)
+	result, err := a.buckets.Take(r.Context(), key.ID.String()+":"+opType, burst, float64(perHour)/3600)
 	if err != nil {
 		// Log error but allow request
+		log.Printf("Failed to check API rate limit: %v", err)
 		return true
 	}
 
-	if count > limit {
-		w.Header().Set("X-RateLimit-Limit", string(rune(limit)))
-		w.Header().Set("X-RateLimit-Remaining", "0")
+	writeRateLimitHeaders(w, result.Limit, result.Remaining, ceilSeconds(result.Reset))
+
+	if !result.Allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
 		http.Error(w, `{"error":"rate limit exceeded"}`, http.StatusTooManyRequests)
 		return false
 	}
 
-	w.Header().Set("X-RateLimit-Limit", string(rune(limit)))
-	w.Header().Set("X-RateLimit-Remaining", string(rune(limit-count)))
-
 	return true
 }
 
+// ceilSeconds rounds a duration up to whole seconds for rate limit headers
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
 // enrichContext adds API key info to the request context
 func (a *APIKeyAuth) enrichContext(ctx context.Context, key *models.APIKey) context.Context {
 	ctx = context.WithValue(ctx, APIKeyIDKey, key.ID.String())
 	ctx = context.WithValue(ctx, APIKeyScopeKey, key.Scope)
 	ctx = context.WithValue(ctx, APIKeyTenantIDKey, key.TenantID.String())
+	ctx = context.WithValue(ctx, APIKeyKey, key)
 	return ctx
 }
 
+// GetAPIKey extracts the authenticated API key (with scopes and restrictions) from context
+func GetAPIKey(ctx context.Context) *models.APIKey {
+	if key, ok := ctx.Value(APIKeyKey).(*models.APIKey); ok {
+		return key
+	}
+	return nil
+}
+
 // GetAPIKeyTenantID extracts tenant ID from API key context
 func GetAPIKeyTenantID(ctx context.Context) string {
 	if id, ok := ctx.Value(APIKeyTenantIDKey).(string); ok {
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+	"exportready-battery/internal/services"
+)
+
+// BenchmarkValidateKey compares the API key validation paths in-process,
+// without a database:
+//
+//	scan:   old path - load every active key, bcrypt each one whose 4-char prefix matches
+//	lookup: new path - fetch the one row for the unique 12-char prefix, bcrypt once
+//	cached: new path on a cache hit - SHA-256 digest + map read, no bcrypt
+//
+// The "database" is an in-memory slice/map, so the numbers understate the old
+// path: in production it also transfers every active key row on each request.
+//
+// Run with: go test ./internal/middleware -run '^$' -bench ValidateKey -benchmem
+func BenchmarkValidateKey(b *testing.B) {
+	for _, keyCount := range []int{1000, 10000, 100000} {
+		b.Run(fmt.Sprintf("keys=%d", keyCount), func(b *testing.B) {
+			benchmarkKeyCount(b, keyCount)
+		})
+	}
+}
+
+// benchmarkKeyCount runs the three validation paths against keyCount active keys
+func benchmarkKeyCount(b *testing.B, keyCount int) {
+	keyService := services.NewAPIKeyService()
+
+	// Filler keys share one cheap hash; they are only compared on a prefix collision
+	fillerHash, _ := bcrypt.GenerateFromPassword([]byte("filler"), bcrypt.MinCost)
+
+	all := make([]models.APIKey, 0, keyCount)
+	byPrefix := make(map[string]models.APIKey, keyCount)
+	for i := 0; i < keyCount-1; i++ {
+		_, prefix, err := keyService.GenerateKey()
+		if err != nil {
+			b.Fatal(err)
+		}
+		key := models.APIKey{ID: uuid.New(), KeyHash: string(fillerHash), KeyPrefix: prefix, IsActive: true, CreatedAt: time.Now()}
+		all = append(all, key)
+		byPrefix[prefix] = key
+	}
+
+	// The key under test uses a real DefaultCost hash
+	rawKey, prefix, err := keyService.GenerateKey()
+	if err != nil {
+		b.Fatal(err)
+	}
+	hash, err := keyService.HashKey(rawKey)
+	if err != nil {
+		b.Fatal(err)
+	}
+	target := models.APIKey{ID: uuid.New(), KeyHash: hash, KeyPrefix: prefix, IsActive: true, CreatedAt: time.Now()}
+	all = append(all, target)
+	byPrefix[prefix] = target
+
+	legacyPrefix := keyService.ExtractLegacyPrefix(rawKey)
+
+	// The validation cache does not touch the database
+	repo := repository.New(nil)
+	repo.CacheValidatedAPIKey(rawKey, &target)
+
+	b.Run("scan", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			rows := make([]models.APIKey, len(all)) // simulates GetAllActiveAPIKeys
+			copy(rows, all)
+			for j := range rows {
+				if strings.HasPrefix(rows[j].KeyPrefix, legacyPrefix[:len(services.KeyPrefix)+4]) {
+					if keyService.ValidateKey(rawKey, rows[j].KeyHash) {
+						break
+					}
+				}
+			}
+		}
+	})
+
+	b.Run("lookup", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			row := byPrefix[keyService.ExtractPrefix(rawKey)] // simulates GetAPIKeyByPrefix
+			if !keyService.ValidateKey(rawKey, row.KeyHash) {
+				b.Fatal("key did not validate")
+			}
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, ok := repo.GetCachedAPIKey(rawKey); !ok {
+				b.Fatal("cache miss")
+			}
+		}
+	})
+}
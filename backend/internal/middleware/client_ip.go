@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ============================================================================
+// CLIENT IP (forwarding headers only from trusted proxies)
+// ============================================================================
+
+// TrustedProxies resolves the client IP of a request. X-Forwarded-For and
+// X-Real-IP are only honoured when the connection comes from one of these
+// networks; otherwise a client could pick its own address. A nil
+// *TrustedProxies trusts no proxy and always uses the connection address.
+type TrustedProxies struct {
+	networks []*net.IPNet
+}
+
+// ParseTrustedProxies parses a comma-separated list of proxy IPs and CIDRs,
+// e.g. "10.0.0.0/8,192.168.1.10". An empty list trusts no proxy.
+func ParseTrustedProxies(list string) (*TrustedProxies, error) {
+	t := &TrustedProxies{}
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !strings.Contains(field, "/") {
+			ip := net.ParseIP(field)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid proxy address %q", field)
+			}
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			t.networks = append(t.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy network %q", field)
+		}
+		t.networks = append(t.networks, network)
+	}
+	return t, nil
+}
+
+// trusts reports whether ip is a trusted proxy
+func (t *TrustedProxies) trusts(ip string) bool {
+	if t == nil {
+		return false
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range t.networks {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the IP of the client that sent r: the connection address,
+// or, when that is a trusted proxy, the nearest untrusted address in
+// X-Forwarded-For (then X-Real-IP)
+func (t *TrustedProxies) ClientIP(r *http.Request) string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	if !t.trusts(ip) {
+		return ip
+	}
+
+	// Walk the chain from the nearest hop; addresses left of the first
+	// untrusted one were supplied by the client
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			ip = hop
+			if !t.trusts(hop) {
+				return ip
+			}
+		}
+		return ip
+	}
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+	return ip
+}
@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client's retry key on POST requests
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set to "true" on responses served from a stored result
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	maxIdempotentBodyBytes  = 10 << 20 // Larger requests are rejected rather than buffered
+)
+
+// Idempotency replays stored responses for retried POST requests that carry an
+// Idempotency-Key header. Keys are scoped to the API key's tenant and kept for
+// models.IdempotencyKeyTTL. Reusing a key with a different request is rejected.
+type Idempotency struct {
+	repo *repository.Repository
+	ttl  time.Duration
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+// NewIdempotency creates the idempotency middleware backed by Postgres
+func NewIdempotency(repo *repository.Repository) *Idempotency {
+	return &Idempotency{repo: repo, ttl: models.IdempotencyKeyTTL, lastCleanup: time.Now()}
+}
+
+// Wrap applies idempotency to POST requests with an Idempotency-Key header.
+// Only wrap handlers that change data; a replayed read would return stale results.
+// It must run after API key authentication so the tenant is known.
+func (m *Idempotency) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		apiKey := GetAPIKey(r.Context())
+		if r.Method != http.MethodPost || key == "" || apiKey == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			http.Error(w, `{"error":"Idempotency-Key must be at most 255 characters"}`, http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentBodyBytes+1))
+		if err != nil {
+			http.Error(w, `{"error":"failed to read request body"}`, http.StatusBadRequest)
+			return
+		}
+		if len(body) > maxIdempotentBodyBytes {
+			http.Error(w, `{"error":"request body too large"}`, http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		fingerprint := requestFingerprint(r, body)
+		existing, err := m.repo.ReserveIdempotencyKey(r.Context(), apiKey.TenantID, key, fingerprint, m.ttl)
+		if err != nil {
+			log.Printf("Failed to reserve idempotency key: %v", err)
+			http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+			return
+		}
+		m.maybeCleanup()
+
+		if existing != nil {
+			replay(w, existing, fingerprint)
+			return
+		}
+
+		// First use of the key: run the handler and keep a copy of the response
+		rec := &responseCapture{ResponseWriter: w, status: http.StatusOK}
+		completed := false
+		defer func() {
+			// Free the key on server errors and panics so the retry runs again
+			if !completed {
+				if err := m.repo.ReleaseIdempotencyKey(context.Background(), apiKey.TenantID, key); err != nil {
+					log.Printf("Failed to release idempotency key: %v", err)
+				}
+			}
+		}()
+
+		next.ServeHTTP(rec, r)
+
+		if rec.status >= http.StatusInternalServerError {
+			return
+		}
+		err = m.repo.CompleteIdempotencyKey(context.Background(), apiKey.TenantID, key,
+			rec.status, rec.Header().Get("Content-Type"), rec.body.Bytes())
+		if err != nil {
+			log.Printf("Failed to store idempotent response: %v", err)
+			return
+		}
+		completed = true
+	})
+}
+
+// replay answers a retry from the stored record
+func replay(w http.ResponseWriter, rec *models.IdempotencyRecord, fingerprint string) {
+	if rec.Fingerprint != fingerprint {
+		http.Error(w, `{"error":"Idempotency-Key was already used with a different request"}`, http.StatusUnprocessableEntity)
+		return
+	}
+	if rec.StatusCode == nil {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, `{"error":"a request with this Idempotency-Key is still being processed"}`, http.StatusConflict)
+		return
+	}
+
+	if rec.ContentType != "" {
+		w.Header().Set("Content-Type", rec.ContentType)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(*rec.StatusCode)
+	w.Write(rec.ResponseBody)
+}
+
+// maybeCleanup prunes expired keys at most once an hour per instance
+func (m *Idempotency) maybeCleanup() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if time.Since(m.lastCleanup) > time.Hour {
+		m.lastCleanup = time.Now()
+		go m.repo.CleanupExpiredIdempotencyKeys(context.Background())
+	}
+}
+
+// requestFingerprint hashes the method, path and body. JSON bodies are normalized
+// (key order, whitespace) so a re-serialized retry of the same payload still matches.
+func requestFingerprint(r *http.Request, body []byte) string {
+	var parsed interface{}
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		if normalized, err := json.Marshal(parsed); err == nil {
+			body = normalized
+		}
+	}
+
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseCapture passes a response through while keeping a copy for replay
+type responseCapture struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseCapture) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseCapture) Write(b []byte) (int, error) {
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
@@ -0,0 +1,421 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// SLIDING WINDOW RATE LIMITER (Unauthenticated endpoints)
+// ============================================================================
+
+// RateLimitRule configures throttling for a single route.
+// Every request counts toward the IP and email limits. Requests whose response
+// status matches IsFailure additionally count as failures, which drive the
+// progressive delay and the temporary account lockout.
+type RateLimitRule struct {
+	Route  string        // Route name used in limiter keys (e.g. "login")
+	Window time.Duration // Sliding window length
+
+	IPLimit    int // Max requests per IP per window (0 = unlimited)
+	EmailLimit int // Max requests per email per window (0 = unlimited)
+
+	// Progressive delay: after DelayAfter failures, each further failure
+	// doubles the delay starting at DelayBase, capped at MaxDelay
+	DelayAfter int
+	DelayBase  time.Duration
+	MaxDelay   time.Duration
+
+	// Lockout: LockoutAfter failures for one email within Window locks that
+	// email out of this route for LockoutDuration (0 = never lock)
+	LockoutAfter    int
+	LockoutDuration time.Duration
+
+	// IsFailure reports whether a response status counts as a failed attempt.
+	// Nil means no request is treated as a failure.
+	IsFailure func(status int) bool
+}
+
+// LockoutHandler is called once when an email gets locked out of a route
+type LockoutHandler func(email, ip string, until time.Time)
+
+// LoginRateLimitRule is the default rule for POST /api/v1/auth/login
+func LoginRateLimitRule() RateLimitRule {
+	return RateLimitRule{
+		Route:           "login",
+		Window:          15 * time.Minute,
+		IPLimit:         30,
+		EmailLimit:      15,
+		DelayAfter:      3,
+		DelayBase:       500 * time.Millisecond,
+		MaxDelay:        8 * time.Second,
+		LockoutAfter:    10,
+		LockoutDuration: 30 * time.Minute,
+		IsFailure: func(status int) bool {
+			return status == http.StatusUnauthorized
+		},
+	}
+}
+
+// ForgotPasswordRateLimitRule is the default rule for POST /api/v1/auth/forgot-password
+func ForgotPasswordRateLimitRule() RateLimitRule {
+	return RateLimitRule{
+		Route:      "forgot-password",
+		Window:     time.Hour,
+		IPLimit:    20,
+		EmailLimit: 5,
+	}
+}
+
+// MagicLinkRateLimitRule is the default rule for POST /api/v1/auth/magic-link
+func MagicLinkRateLimitRule() RateLimitRule {
+	return RateLimitRule{
+		Route:      "magic-link",
+		Window:     time.Hour,
+		IPLimit:    30,
+		EmailLimit: 10,
+		DelayAfter: 3,
+		DelayBase:  500 * time.Millisecond,
+		MaxDelay:   5 * time.Second,
+		IsFailure: func(status int) bool {
+			return status == http.StatusForbidden
+		},
+	}
+}
+
+// RateLimiter is an in-memory sliding window limiter keyed by route, IP and email
+type RateLimiter struct {
+	rule      RateLimitRule
+	onLockout LockoutHandler
+	proxies   *TrustedProxies // Proxies whose forwarding headers give the client IP
+
+	mu        sync.Mutex
+	requests  map[string][]time.Time // key -> request timestamps inside window
+	failures  map[string][]time.Time // key -> failure timestamps inside window
+	lockouts  map[string]time.Time   // email key -> locked until
+	lastSweep time.Time
+}
+
+// NewRateLimiter creates a new rate limiter for the given rule
+func NewRateLimiter(rule RateLimitRule) *RateLimiter {
+	return &RateLimiter{
+		rule:      rule,
+		requests:  make(map[string][]time.Time),
+		failures:  make(map[string][]time.Time),
+		lockouts:  make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+// OnLockout registers a callback invoked when an email is locked out
+func (l *RateLimiter) OnLockout(fn LockoutHandler) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onLockout = fn
+}
+
+// SetTrustedProxies sets the proxies whose X-Forwarded-For is used for the
+// per-IP limit; call before serving. Without them the connection address is used.
+func (l *RateLimiter) SetTrustedProxies(proxies *TrustedProxies) {
+	l.proxies = proxies
+}
+
+// Unlock clears the lockout and failure history for an email
+func (l *RateLimiter) Unlock(email string) {
+	key := l.emailKey(normalizeEmail(email))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.lockouts, key)
+	delete(l.failures, key)
+	delete(l.requests, key)
+}
+
+// Limit wraps a handler with the limiter
+func (l *RateLimiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := l.proxies.ClientIP(r)
+		email := peekEmail(r)
+
+		ipKey := l.ipKey(ip)
+		emailKey := ""
+		if email != "" {
+			emailKey = l.emailKey(email)
+		}
+
+		now := time.Now()
+		decision := l.allow(now, ipKey, emailKey)
+		writeRateLimitHeaders(w, decision.limit, decision.remaining, decision.reset)
+
+		if decision.lockedUntil.After(now) {
+			retryAfter := int(math.Ceil(decision.lockedUntil.Sub(now).Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			respondRateLimited(w, http.StatusTooManyRequests,
+				"Too many failed attempts. This account is temporarily locked; check your email for an unlock link.")
+			return
+		}
+		if !decision.allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(decision.reset))
+			respondRateLimited(w, http.StatusTooManyRequests, "Too many requests. Please try again later.")
+			return
+		}
+
+		// Progressive delay for keys with a history of failures
+		if decision.delay > 0 {
+			select {
+			case <-time.After(decision.delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		if l.rule.IsFailure != nil && l.rule.IsFailure(rec.status) {
+			l.recordFailure(time.Now(), ip, email, ipKey, emailKey)
+		}
+	})
+}
+
+// rateLimitDecision is the outcome of checking a request against the limits
+type rateLimitDecision struct {
+	allowed     bool
+	limit       int
+	remaining   int
+	reset       int // seconds until the oldest counted request leaves the window
+	delay       time.Duration
+	lockedUntil time.Time
+}
+
+// allow records the request and decides whether it may proceed
+func (l *RateLimiter) allow(now time.Time, ipKey, emailKey string) rateLimitDecision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	if emailKey != "" {
+		if until, ok := l.lockouts[emailKey]; ok {
+			if until.After(now) {
+				return rateLimitDecision{lockedUntil: until, limit: l.rule.EmailLimit, reset: int(math.Ceil(until.Sub(now).Seconds()))}
+			}
+			delete(l.lockouts, emailKey)
+		}
+	}
+
+	decision := rateLimitDecision{allowed: true, limit: -1, remaining: -1}
+
+	// Evaluate each key; the most restrictive one determines the headers
+	check := func(key string, limit int) {
+		if key == "" || limit <= 0 {
+			return
+		}
+		window := l.prune(l.requests, key, now)
+		remaining := limit - len(window)
+		reset := 0
+		if len(window) > 0 {
+			reset = int(math.Ceil(window[0].Add(l.rule.Window).Sub(now).Seconds()))
+		}
+		if remaining <= 0 {
+			decision.allowed = false
+		}
+		if decision.remaining < 0 || remaining < decision.remaining {
+			decision.limit = limit
+			decision.remaining = remaining
+			decision.reset = reset
+		}
+	}
+	check(ipKey, l.rule.IPLimit)
+	check(emailKey, l.rule.EmailLimit)
+
+	if decision.remaining < 0 {
+		decision.limit, decision.remaining = 0, 0
+	}
+
+	if !decision.allowed {
+		return decision
+	}
+
+	// Count this request
+	if ipKey != "" && l.rule.IPLimit > 0 {
+		l.requests[ipKey] = append(l.requests[ipKey], now)
+	}
+	if emailKey != "" && l.rule.EmailLimit > 0 {
+		l.requests[emailKey] = append(l.requests[emailKey], now)
+	}
+	decision.remaining--
+	if decision.remaining < 0 {
+		decision.remaining = 0
+	}
+	if decision.reset == 0 {
+		decision.reset = int(l.rule.Window.Seconds())
+	}
+
+	// Progressive delay based on the worst failure history
+	failures := len(l.prune(l.failures, ipKey, now))
+	if emailKey != "" {
+		if n := len(l.prune(l.failures, emailKey, now)); n > failures {
+			failures = n
+		}
+	}
+	decision.delay = l.delayFor(failures)
+
+	return decision
+}
+
+// recordFailure stores a failed attempt and locks the email out when needed
+func (l *RateLimiter) recordFailure(now time.Time, ip, email, ipKey, emailKey string) {
+	l.mu.Lock()
+	l.failures[ipKey] = append(l.failures[ipKey], now)
+
+	var onLockout LockoutHandler
+	var until time.Time
+	if emailKey != "" {
+		l.failures[emailKey] = append(l.failures[emailKey], now)
+		count := len(l.prune(l.failures, emailKey, now))
+		if l.rule.LockoutAfter > 0 && count >= l.rule.LockoutAfter {
+			if _, locked := l.lockouts[emailKey]; !locked {
+				until = now.Add(l.rule.LockoutDuration)
+				l.lockouts[emailKey] = until
+				onLockout = l.onLockout
+			}
+		}
+	}
+	l.mu.Unlock()
+
+	if !until.IsZero() {
+		log.Printf("🔒 Rate limit: locked %s out of %s until %s (ip %s)", email, l.rule.Route, until.Format(time.RFC3339), ip)
+		if onLockout != nil {
+			go onLockout(email, ip, until)
+		}
+	}
+}
+
+// delayFor returns the progressive delay for a number of recent failures
+func (l *RateLimiter) delayFor(failures int) time.Duration {
+	if l.rule.DelayBase <= 0 || failures < l.rule.DelayAfter {
+		return 0
+	}
+	exp := failures - l.rule.DelayAfter
+	if exp > 16 {
+		exp = 16
+	}
+	delay := l.rule.DelayBase * time.Duration(1<<uint(exp))
+	if l.rule.MaxDelay > 0 && delay > l.rule.MaxDelay {
+		delay = l.rule.MaxDelay
+	}
+	return delay
+}
+
+// prune drops timestamps older than the window and returns what remains.
+// Callers must hold l.mu.
+func (l *RateLimiter) prune(store map[string][]time.Time, key string, now time.Time) []time.Time {
+	entries := store[key]
+	cutoff := now.Add(-l.rule.Window)
+	i := 0
+	for i < len(entries) && !entries[i].After(cutoff) {
+		i++
+	}
+	if i == len(entries) {
+		delete(store, key)
+		return nil
+	}
+	if i > 0 {
+		entries = entries[i:]
+		store[key] = entries
+	}
+	return entries
+}
+
+// sweep periodically removes idle keys so memory stays bounded.
+// Callers must hold l.mu.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.rule.Window {
+		return
+	}
+	l.lastSweep = now
+	for key := range l.requests {
+		l.prune(l.requests, key, now)
+	}
+	for key := range l.failures {
+		l.prune(l.failures, key, now)
+	}
+	for key, until := range l.lockouts {
+		if !until.After(now) {
+			delete(l.lockouts, key)
+		}
+	}
+}
+
+func (l *RateLimiter) ipKey(ip string) string {
+	return l.rule.Route + "|ip:" + ip
+}
+
+func (l *RateLimiter) emailKey(email string) string {
+	return l.rule.Route + "|email:" + email
+}
+
+// maxPeekBody caps how much of the body is buffered to find the email
+const maxPeekBody = 64 << 10
+
+// peekEmail reads the "email" field from a JSON body without consuming it
+func peekEmail(r *http.Request) string {
+	if r.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPeekBody))
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil || len(body) == 0 {
+		return ""
+	}
+
+	var payload struct {
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+	return normalizeEmail(payload.Email)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// writeRateLimitHeaders sets the IETF RateLimit-* headers
+func writeRateLimitHeaders(w http.ResponseWriter, limit, remaining, reset int) {
+	if limit <= 0 {
+		return
+	}
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(reset))
+}
+
+func respondRateLimited(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"error":%q}`, message)
+}
+
+// statusRecorder captures the status code written by the wrapped handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
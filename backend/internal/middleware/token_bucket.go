@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"exportready-battery/internal/repository"
+)
+
+// ============================================================================
+// TOKEN BUCKET RATE LIMITING (API keys)
+// ============================================================================
+
+// BucketResult is the outcome of taking a token from a bucket
+type BucketResult struct {
+	Allowed    bool
+	Limit      int           // Bucket capacity
+	Remaining  int           // Whole tokens left after this request
+	Reset      time.Duration // Time until the bucket is full again
+	RetryAfter time.Duration // Time until one token is available (only when denied)
+}
+
+// BucketStore takes tokens from named token buckets
+type BucketStore interface {
+	Take(ctx context.Context, key string, capacity int, refillPerSecond float64) (BucketResult, error)
+}
+
+// newBucketResult derives headers-friendly values from the tokens left in a bucket
+func newBucketResult(allowed bool, tokens float64, capacity int, refillPerSecond float64) BucketResult {
+	result := BucketResult{
+		Allowed:   allowed,
+		Limit:     capacity,
+		Remaining: int(math.Floor(tokens)),
+	}
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+	if refillPerSecond > 0 {
+		result.Reset = time.Duration((float64(capacity) - tokens) / refillPerSecond * float64(time.Second))
+		if !allowed {
+			result.RetryAfter = time.Duration((1 - tokens) / refillPerSecond * float64(time.Second))
+		}
+	}
+	return result
+}
+
+// ----------------------------------------------------------------------------
+// In-process store
+// ----------------------------------------------------------------------------
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// MemoryBucketStore keeps buckets in process memory (per instance)
+type MemoryBucketStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+}
+
+// NewMemoryBucketStore creates a new in-process bucket store
+func NewMemoryBucketStore() *MemoryBucketStore {
+	return &MemoryBucketStore{
+		buckets:   make(map[string]*memoryBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Take refills the bucket for elapsed time and tries to take one token
+func (s *MemoryBucketStore) Take(ctx context.Context, key string, capacity int, refillPerSecond float64) (BucketResult, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	bucket, exists := s.buckets[key]
+	if !exists {
+		bucket = &memoryBucket{tokens: float64(capacity), updatedAt: now}
+		s.buckets[key] = bucket
+	}
+
+	elapsed := now.Sub(bucket.updatedAt).Seconds()
+	bucket.tokens = math.Min(float64(capacity), bucket.tokens+elapsed*refillPerSecond)
+	bucket.updatedAt = now
+
+	allowed := bucket.tokens >= 1
+	if allowed {
+		bucket.tokens--
+	}
+
+	return newBucketResult(allowed, bucket.tokens, capacity, refillPerSecond), nil
+}
+
+// sweep drops buckets idle for an hour; a refilled bucket is equivalent to a new one.
+// Callers must hold s.mu.
+func (s *MemoryBucketStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < 10*time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.updatedAt) > time.Hour {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Postgres store
+// ----------------------------------------------------------------------------
+
+// PostgresBucketStore keeps buckets in Postgres so all instances share one budget
+type PostgresBucketStore struct {
+	repo *repository.Repository
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+// NewPostgresBucketStore creates a new Postgres-backed bucket store
+func NewPostgresBucketStore(repo *repository.Repository) *PostgresBucketStore {
+	return &PostgresBucketStore{repo: repo, lastCleanup: time.Now()}
+}
+
+// Take atomically refills the bucket and tries to take one token
+func (s *PostgresBucketStore) Take(ctx context.Context, key string, capacity int, refillPerSecond float64) (BucketResult, error) {
+	tokens, allowed, err := s.repo.TakeRateLimitToken(ctx, key, capacity, refillPerSecond)
+	if err != nil {
+		return BucketResult{}, err
+	}
+
+	// Prune idle buckets at most once an hour per instance
+	s.mu.Lock()
+	if time.Since(s.lastCleanup) > time.Hour {
+		s.lastCleanup = time.Now()
+		go s.repo.CleanupRateLimitBuckets(context.Background(), 24*time.Hour)
+	}
+	s.mu.Unlock()
+	return newBucketResult(allowed, tokens, capacity, refillPerSecond), nil
+}
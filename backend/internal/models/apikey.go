@@ -1,6 +1,11 @@
 package models
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,12 +22,57 @@ type APIKey struct {
 	Name          string     `json:"name"`
 	KeyHash       string     `json:"-"` // Never expose hash
 	KeyPrefix     string     `json:"key_prefix"`
-	Scope         string     `json:"scope"`           // "read" or "write"
-	RateLimitTier string     `json:"rate_limit_tier"` // "starter" or "production"
+	Scope         string     `json:"scope"`           // Legacy summary: "read" or "write"
+	RateLimitTier string     `json:"rate_limit_tier"` // Configured tier, e.g. "starter" or "production"
 	LastUsedAt    *time.Time `json:"last_used_at,omitempty"`
 	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
 	IsActive      bool       `json:"is_active"`
 	CreatedAt     time.Time  `json:"created_at"`
+
+	// Fine-grained permissions (e.g. "passports:read", "batches:write")
+	Scopes []string `json:"scopes"`
+
+	// Optional resource restrictions (empty = unrestricted)
+	AllowedBatchIDs []uuid.UUID `json:"allowed_batch_ids"`
+	AllowedMarkets  []string    `json:"allowed_markets"`      // INDIA, EU, US, UK, GLOBAL
+	AllowedCIDRs    []string    `json:"allowed_cidrs"`        // e.g. 203.0.113.0/24
+	ActorRole       string      `json:"actor_role,omitempty"` // Lifecycle role for transitions: LOGISTICS, TECHNICIAN, ...
+}
+
+// HasScope checks if the key grants a scope
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsBatch checks the key's batch restriction
+func (k *APIKey) AllowsBatch(batchID uuid.UUID) bool {
+	if len(k.AllowedBatchIDs) == 0 {
+		return true
+	}
+	for _, id := range k.AllowedBatchIDs {
+		if id == batchID {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsMarket checks the key's market region restriction
+func (k *APIKey) AllowsMarket(market MarketRegion) bool {
+	if len(k.AllowedMarkets) == 0 {
+		return true
+	}
+	for _, m := range k.AllowedMarkets {
+		if MarketRegion(m) == market {
+			return true
+		}
+	}
+	return false
 }
 
 // APIKeyWithSecret contains the full key (only returned on creation)
@@ -34,51 +84,157 @@ type APIKeyWithSecret struct {
 // CreateAPIKeyRequest is the request body for creating an API key
 type CreateAPIKeyRequest struct {
 	Name          string `json:"name"`
-	Scope         string `json:"scope"`                     // "read" or "write"
+	Scope         string `json:"scope"`                     // Legacy: "read" or "write" (used when scopes is empty)
 	RateLimitTier string `json:"rate_limit_tier"`           // "starter" or "production"
 	ExpiresInDays *int   `json:"expires_in_days,omitempty"` // nil = never expires
+
+	Scopes          []string    `json:"scopes,omitempty"`
+	AllowedBatchIDs []uuid.UUID `json:"allowed_batch_ids,omitempty"`
+	AllowedMarkets  []string    `json:"allowed_markets,omitempty"`
+	AllowedCIDRs    []string    `json:"allowed_cidrs,omitempty"`
+	ActorRole       string      `json:"actor_role,omitempty"`
 }
 
 // UpdateAPIKeyRequest is the request body for updating an API key
 type UpdateAPIKeyRequest struct {
 	Name     *string `json:"name,omitempty"`
 	IsActive *bool   `json:"is_active,omitempty"`
-}
 
-// APIKeyUsage tracks rate limiting for an API key
-type APIKeyUsage struct {
-	ID           uuid.UUID `json:"id"`
-	APIKeyID     uuid.UUID `json:"api_key_id"`
-	Endpoint     string    `json:"endpoint"`
-	RequestCount int       `json:"request_count"`
-	WindowStart  time.Time `json:"window_start"`
+	Scopes          *[]string    `json:"scopes,omitempty"`
+	AllowedBatchIDs *[]uuid.UUID `json:"allowed_batch_ids,omitempty"`
+	AllowedMarkets  *[]string    `json:"allowed_markets,omitempty"`
+	AllowedCIDRs    *[]string    `json:"allowed_cidrs,omitempty"`
+	ActorRole       *string      `json:"actor_role,omitempty"`
 }
 
-// RateLimitConfig defines rate limits per tier
+// RateLimitConfig defines rate limits per tier.
+// Limits are enforced as token buckets: Burst tokens, refilled at the hourly rate.
 type RateLimitConfig struct {
 	ReadLimit  int // requests per hour for GET
 	WriteLimit int // requests per hour for POST/PUT/DELETE
+	ReadBurst  int // bucket capacity for reads (0 = ReadLimit)
+	WriteBurst int // bucket capacity for writes (0 = WriteLimit)
+}
+
+// DefaultRateLimitTier is used for keys whose tier is not configured
+const DefaultRateLimitTier = "starter"
+
+// DefaultRateLimitTiers returns the built-in tier definitions
+func DefaultRateLimitTiers() map[string]RateLimitConfig {
+	return map[string]RateLimitConfig{
+		"starter":    {ReadLimit: 100, WriteLimit: 100},
+		"production": {ReadLimit: 1000, WriteLimit: 500},
+	}
+}
+
+var (
+	rateLimitTiersMu sync.RWMutex
+	rateLimitTiers   = DefaultRateLimitTiers()
+)
+
+// SetRateLimitTiers replaces the tier definitions (called once at startup from config)
+func SetRateLimitTiers(tiers map[string]RateLimitConfig) {
+	rateLimitTiersMu.Lock()
+	defer rateLimitTiersMu.Unlock()
+	rateLimitTiers = tiers
 }
 
-// GetRateLimits returns the rate limits for a tier
+// GetRateLimits returns the rate limits for a tier, falling back to the default tier
 func GetRateLimits(tier string) RateLimitConfig {
-	switch tier {
-	case "production":
-		return RateLimitConfig{
-			ReadLimit:  1000,
-			WriteLimit: 500,
+	rateLimitTiersMu.RLock()
+	defer rateLimitTiersMu.RUnlock()
+
+	if limits, exists := rateLimitTiers[tier]; exists {
+		return limits
+	}
+	if limits, exists := rateLimitTiers[DefaultRateLimitTier]; exists {
+		return limits
+	}
+	return DefaultRateLimitTiers()[DefaultRateLimitTier]
+}
+
+// IsValidRateLimitTier checks if a tier is configured
+func IsValidRateLimitTier(tier string) bool {
+	rateLimitTiersMu.RLock()
+	defer rateLimitTiersMu.RUnlock()
+	_, exists := rateLimitTiers[tier]
+	return exists
+}
+
+// RateLimitTierNames returns the configured tier names, sorted
+func RateLimitTierNames() []string {
+	rateLimitTiersMu.RLock()
+	defer rateLimitTiersMu.RUnlock()
+
+	names := make([]string, 0, len(rateLimitTiers))
+	for name := range rateLimitTiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseRateLimitTiers parses tier definitions in the form
+// "starter=100:100,production=1000:500:200:100" (read:write[:read_burst:write_burst] per hour)
+func ParseRateLimitTiers(spec string) (map[string]RateLimitConfig, error) {
+	tiers := make(map[string]RateLimitConfig)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
 		}
-	default: // "starter"
-		return RateLimitConfig{
-			ReadLimit:  100,
-			WriteLimit: 100,
+
+		name, values, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid rate limit tier %q: expected name=read:write", entry)
+		}
+
+		parts := strings.Split(values, ":")
+		if len(parts) != 2 && len(parts) != 4 {
+			return nil, fmt.Errorf("invalid rate limit tier %q: expected read:write or read:write:read_burst:write_burst", entry)
 		}
+
+		nums := make([]int, len(parts))
+		for i, part := range parts {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid rate limit tier %q: limits must be positive integers", entry)
+			}
+			nums[i] = n
+		}
+
+		config := RateLimitConfig{ReadLimit: nums[0], WriteLimit: nums[1]}
+		if len(nums) == 4 {
+			config.ReadBurst, config.WriteBurst = nums[2], nums[3]
+		}
+		tiers[name] = config
+	}
+
+	if len(tiers) == 0 {
+		return nil, fmt.Errorf("no rate limit tiers defined")
 	}
+	return tiers, nil
 }
 
-// ValidScopes returns valid API key scopes
+// API key scopes
+const (
+	ScopePassportsRead       = "passports:read"
+	ScopePassportsTransition = "passports:transition"
+	ScopeBatchesWrite        = "batches:write"
+	ScopeLabelsRead          = "labels:read"
+	ScopeTelemetryWrite      = "telemetry:write"
+)
+
+// ValidScopes returns valid fine-grained API key scopes
 func ValidScopes() []string {
-	return []string{"read", "write"}
+	return []string{
+		ScopePassportsRead,
+		ScopePassportsTransition,
+		ScopeBatchesWrite,
+		ScopeLabelsRead,
+		ScopeTelemetryWrite,
+	}
 }
 
 // IsValidScope checks if a scope is valid
@@ -90,3 +246,120 @@ func IsValidScope(scope string) bool {
 	}
 	return false
 }
+
+// LegacyScopes expands a legacy "read"/"write" scope into fine-grained scopes.
+// "write" keeps what write keys could always do: create batches and passports.
+func LegacyScopes(scope string) []string {
+	switch scope {
+	case "write":
+		return []string{ScopePassportsRead, ScopeLabelsRead, ScopeBatchesWrite}
+	case "read":
+		return []string{ScopePassportsRead, ScopeLabelsRead}
+	default:
+		return nil
+	}
+}
+
+// SummarizeScopes derives the legacy "read"/"write" summary from scopes
+func SummarizeScopes(scopes []string) string {
+	for _, s := range scopes {
+		if strings.HasSuffix(s, ":write") || s == ScopePassportsTransition {
+			return "write"
+		}
+	}
+	return "read"
+}
+
+// IsValidActorRole checks if a role can be assigned to an API key for lifecycle transitions
+func IsValidActorRole(role string) bool {
+	_, exists := RoleTransitionPermissions[role]
+	return exists
+}
+
+// ============================================================================
+// API KEY USAGE ANALYTICS & AUDIT
+// ============================================================================
+
+// APIKeyAlertNewIP is raised the first time a key is used from an unseen IP
+const APIKeyAlertNewIP = "NEW_IP"
+
+// APIRequestLog is a single logged API key request
+type APIRequestLog struct {
+	ID         uuid.UUID `json:"id"`
+	APIKeyID   uuid.UUID `json:"api_key_id"`
+	TenantID   uuid.UUID `json:"tenant_id"`
+	Method     string    `json:"method"`
+	Endpoint   string    `json:"endpoint"` // Route pattern
+	Path       string    `json:"path"`
+	StatusCode int       `json:"status_code"`
+	LatencyMs  int       `json:"latency_ms"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// APIKeyEndpointUsage aggregates requests for one endpoint
+type APIKeyEndpointUsage struct {
+	Endpoint     string  `json:"endpoint"`
+	Requests     int     `json:"requests"`
+	Errors       int     `json:"errors"` // Status >= 400
+	ErrorRate    float64 `json:"error_rate"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+}
+
+// APIKeyDailyUsage aggregates requests for one day (UTC)
+type APIKeyDailyUsage struct {
+	Date      string  `json:"date"` // YYYY-MM-DD
+	Requests  int     `json:"requests"`
+	Errors    int     `json:"errors"`
+	ErrorRate float64 `json:"error_rate"`
+}
+
+// APIKeyUsageSummary is the usage report for a key over a period
+type APIKeyUsageSummary struct {
+	APIKeyID   uuid.UUID              `json:"api_key_id"`
+	Days       int                    `json:"days"`
+	Requests   int                    `json:"requests"`
+	Errors     int                    `json:"errors"`
+	ErrorRate  float64                `json:"error_rate"`
+	ByEndpoint []*APIKeyEndpointUsage `json:"by_endpoint"`
+	ByDay      []*APIKeyDailyUsage    `json:"by_day"`
+}
+
+// APIKeyIP is a client IP seen for a key
+type APIKeyIP struct {
+	IPAddress    string    `json:"ip_address"`
+	FirstSeenAt  time.Time `json:"first_seen_at"`
+	LastSeenAt   time.Time `json:"last_seen_at"`
+	RequestCount int64     `json:"request_count"`
+}
+
+// APIKeyAlert is a security alert raised for a key
+type APIKeyAlert struct {
+	ID        uuid.UUID `json:"id"`
+	APIKeyID  uuid.UUID `json:"api_key_id"`
+	TenantID  uuid.UUID `json:"tenant_id"`
+	AlertType string    `json:"alert_type"`
+	IPAddress string    `json:"ip_address,omitempty"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ============================================================================
+// IDEMPOTENCY
+// ============================================================================
+
+// IdempotencyKeyTTL is how long a stored response can be replayed
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// IdempotencyRecord is the stored outcome of a request sent with an Idempotency-Key
+type IdempotencyRecord struct {
+	TenantID     uuid.UUID
+	Key          string
+	Fingerprint  string // SHA-256 of method, path and normalized body
+	StatusCode   *int   // nil while the original request is still in flight
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
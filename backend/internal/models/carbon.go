@@ -0,0 +1,242 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// CARBON FOOTPRINT (EU 2023/1542 Art. 7)
+// ============================================================================
+
+// Lifecycle stages of the carbon footprint declaration
+const (
+	CarbonStageRawMaterial    = "RAW_MATERIAL_ACQUISITION" // Raw material acquisition and pre-processing
+	CarbonStageMainProduction = "MAIN_PRODUCTION"          // Main product production (cells, modules, pack)
+	CarbonStageDistribution   = "DISTRIBUTION"             // Transport to the point of sale
+	CarbonStageEndOfLife      = "END_OF_LIFE"              // End of life and recycling
+)
+
+// CarbonStages lists the lifecycle stages in declaration order
+var CarbonStages = []string{
+	CarbonStageRawMaterial,
+	CarbonStageMainProduction,
+	CarbonStageDistribution,
+	CarbonStageEndOfLife,
+}
+
+// IsValidCarbonStage reports whether s is a lifecycle stage
+func IsValidCarbonStage(s string) bool {
+	for _, stage := range CarbonStages {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// CarbonPerformanceClass is an upper bound (kg CO2e per kWh) for a class.
+// The delegated act has not fixed the class boundaries yet; these are provisional.
+type CarbonPerformanceClass struct {
+	Class string
+	MaxKg float64 // Inclusive; the last class has no bound
+}
+
+// CarbonPerformanceClasses are checked in order; A is the lowest footprint
+var CarbonPerformanceClasses = []CarbonPerformanceClass{
+	{Class: "A", MaxKg: 60},
+	{Class: "B", MaxKg: 75},
+	{Class: "C", MaxKg: 90},
+	{Class: "D", MaxKg: 110},
+	{Class: "E", MaxKg: math.Inf(1)},
+}
+
+// CarbonClassFor returns the performance class of a footprint in kg CO2e per kWh
+func CarbonClassFor(kgPerKWh float64) string {
+	for _, c := range CarbonPerformanceClasses {
+		if kgPerKWh <= c.MaxKg {
+			return c.Class
+		}
+	}
+	return CarbonPerformanceClasses[len(CarbonPerformanceClasses)-1].Class
+}
+
+// EmissionFactor is an entry of a tenant's factor library, e.g. kg CO2e per kg
+// of NMC811 cathode material or per kWh of grid electricity
+type EmissionFactor struct {
+	ID            uuid.UUID `json:"id"`
+	TenantID      uuid.UUID `json:"tenant_id"`
+	Key           string    `json:"key"`              // Referenced by calculation inputs, e.g. cam.nmc811
+	Name          string    `json:"name"`             // Human-readable name
+	Unit          string    `json:"unit"`             // Unit of the input quantity: kg, kWh, MJ, tkm...
+	KgCO2ePerUnit float64   `json:"kg_co2e_per_unit"` // Negative for recycling credits
+	Stage         string    `json:"stage,omitempty"`  // Default lifecycle stage, if the factor implies one
+	Source        string    `json:"source,omitempty"` // Database and version, e.g. ecoinvent 3.10
+	Region        string    `json:"region,omitempty"` // Geography, e.g. IN, CN, EU
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// CarbonInputLine is one bill-of-materials or energy input of a calculation,
+// as a quantity per battery of the factor's unit
+type CarbonInputLine struct {
+	Name      string  `json:"name"`            // e.g. Cathode active material, Cell formation electricity
+	FactorKey string  `json:"factor_key"`      // Emission factor to apply
+	Quantity  float64 `json:"quantity"`        // Per battery, in the factor's unit
+	Stage     string  `json:"stage,omitempty"` // Overrides the factor and list default
+}
+
+// CarbonCalculationInput is the bill of materials and energy inputs of one
+// battery. Bill-of-materials lines default to raw material acquisition and
+// energy lines to main production; distribution and end-of-life lines set stage.
+type CarbonCalculationInput struct {
+	BillOfMaterials []CarbonInputLine `json:"bill_of_materials"`
+	Energy          []CarbonInputLine `json:"energy"`
+}
+
+// Input line categories in a calculation report
+const (
+	CarbonLineMaterial = "MATERIAL"
+	CarbonLineEnergy   = "ENERGY"
+)
+
+// CarbonLineResult is an input line with the factor applied
+type CarbonLineResult struct {
+	Category      string  `json:"category"` // MATERIAL or ENERGY
+	Name          string  `json:"name"`
+	Stage         string  `json:"stage"`
+	FactorKey     string  `json:"factor_key"`
+	FactorName    string  `json:"factor_name"`
+	FactorSource  string  `json:"factor_source,omitempty"`
+	Quantity      float64 `json:"quantity"`
+	Unit          string  `json:"unit"`
+	KgCO2ePerUnit float64 `json:"kg_co2e_per_unit"`
+	KgCO2e        float64 `json:"kg_co2e"` // Per battery
+}
+
+// CarbonStageResult is the footprint of one lifecycle stage
+type CarbonStageResult struct {
+	Stage        string  `json:"stage"`
+	KgCO2e       float64 `json:"kg_co2e"`         // Per battery
+	KgCO2ePerKWh float64 `json:"kg_co2e_per_kwh"` // Per kWh of rated energy
+	SharePct     float64 `json:"share_pct"`       // Literal percentage of the total
+}
+
+// CarbonFootprintDeclaration is the public part of the footprint, stored in the
+// batch specs next to carbon_footprint and shown on the passport
+type CarbonFootprintDeclaration struct {
+	KgCO2ePerKWh     float64             `json:"kg_co2e_per_kwh"`
+	PerformanceClass string              `json:"performance_class"`
+	Stages           []CarbonStageResult `json:"stages"`
+	RatedEnergyKWh   float64             `json:"rated_energy_kwh"`
+	CalculatedAt     time.Time           `json:"calculated_at"`
+}
+
+// CarbonFootprintReport is the full calculation attached to a batch: the
+// declaration plus every input line and factor used
+type CarbonFootprintReport struct {
+	BatchID        uuid.UUID                  `json:"batch_id"`
+	Capacity       string                     `json:"capacity"` // Batch spec the rated energy came from
+	Voltage        string                     `json:"voltage"`  // Batch spec the rated energy came from
+	RatedEnergyKWh float64                    `json:"rated_energy_kwh"`
+	TotalKgCO2e    float64                    `json:"total_kg_co2e"` // Per battery
+	Declaration    CarbonFootprintDeclaration `json:"declaration"`
+	Lines          []CarbonLineResult         `json:"lines"`
+	CalculatedBy   string                     `json:"calculated_by,omitempty"`
+	CalculatedAt   time.Time                  `json:"calculated_at"`
+}
+
+// CalculateCarbonFootprint applies factors to the input lines of one battery and
+// expresses each lifecycle stage per kWh of ratedKWh
+func CalculateCarbonFootprint(input CarbonCalculationInput, factors map[string]*EmissionFactor, ratedKWh float64, now time.Time) (*CarbonFootprintReport, error) {
+	if ratedKWh <= 0 {
+		return nil, fmt.Errorf("rated energy must be positive")
+	}
+	if len(input.BillOfMaterials) == 0 && len(input.Energy) == 0 {
+		return nil, fmt.Errorf("at least one bill_of_materials or energy line is required")
+	}
+
+	report := &CarbonFootprintReport{RatedEnergyKWh: ratedKWh, CalculatedAt: now}
+	byStage := make(map[string]float64, len(CarbonStages))
+
+	add := func(category, list, defaultStage string, lines []CarbonInputLine) error {
+		for i, line := range lines {
+			factor, ok := factors[line.FactorKey]
+			if !ok {
+				return fmt.Errorf("%s[%d]: unknown factor_key %q", list, i, line.FactorKey)
+			}
+			if line.Quantity < 0 || math.IsNaN(line.Quantity) || math.IsInf(line.Quantity, 0) {
+				return fmt.Errorf("%s[%d]: quantity must be a non-negative number", list, i)
+			}
+			stage := strings.ToUpper(strings.TrimSpace(line.Stage))
+			if stage == "" {
+				stage = factor.Stage
+			}
+			if stage == "" {
+				stage = defaultStage
+			}
+			if !IsValidCarbonStage(stage) {
+				return fmt.Errorf("%s[%d]: stage must be one of %s", list, i, strings.Join(CarbonStages, ", "))
+			}
+			name := strings.TrimSpace(line.Name)
+			if name == "" {
+				name = factor.Name
+			}
+
+			kg := line.Quantity * factor.KgCO2ePerUnit
+			byStage[stage] += kg
+			report.TotalKgCO2e += kg
+			report.Lines = append(report.Lines, CarbonLineResult{
+				Category:      category,
+				Name:          name,
+				Stage:         stage,
+				FactorKey:     factor.Key,
+				FactorName:    factor.Name,
+				FactorSource:  factor.Source,
+				Quantity:      line.Quantity,
+				Unit:          factor.Unit,
+				KgCO2ePerUnit: factor.KgCO2ePerUnit,
+				KgCO2e:        roundTo(kg, 4),
+			})
+		}
+		return nil
+	}
+	if err := add(CarbonLineMaterial, "bill_of_materials", CarbonStageRawMaterial, input.BillOfMaterials); err != nil {
+		return nil, err
+	}
+	if err := add(CarbonLineEnergy, "energy", CarbonStageMainProduction, input.Energy); err != nil {
+		return nil, err
+	}
+	if report.TotalKgCO2e <= 0 {
+		return nil, fmt.Errorf("total footprint must be positive; check quantities and recycling credits")
+	}
+
+	perKWh := report.TotalKgCO2e / ratedKWh
+	report.Declaration = CarbonFootprintDeclaration{
+		KgCO2ePerKWh:     roundTo(perKWh, 2),
+		PerformanceClass: CarbonClassFor(perKWh),
+		RatedEnergyKWh:   roundTo(ratedKWh, 4),
+		CalculatedAt:     now,
+	}
+	for _, stage := range CarbonStages {
+		kg := byStage[stage]
+		report.Declaration.Stages = append(report.Declaration.Stages, CarbonStageResult{
+			Stage:        stage,
+			KgCO2e:       roundTo(kg, 4),
+			KgCO2ePerKWh: roundTo(kg/ratedKWh, 2),
+			SharePct:     roundTo(kg/report.TotalKgCO2e*100, 1),
+		})
+	}
+	report.TotalKgCO2e = roundTo(report.TotalKgCO2e, 4)
+	return report, nil
+}
+
+// roundTo rounds v to the given number of decimal places
+func roundTo(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,211 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ============================================================================
+// BATTERY CATEGORIES (EU Battery Regulation 2023/1542 Art. 3)
+// ============================================================================
+
+// Battery categories of the EU Battery Regulation
+const (
+	BatteryCategoryPortable   = "PORTABLE"   // Sealed, up to 5 kg, not for industry, vehicles or LMT
+	BatteryCategoryLMT        = "LMT"        // Light means of transport: e-bikes, e-scooters
+	BatteryCategoryEV         = "EV"         // Traction batteries of hybrid and electric vehicles
+	BatteryCategoryIndustrial = "INDUSTRIAL" // Industrial use, stationary storage
+	BatteryCategorySLI        = "SLI"        // Starting, lighting and ignition of vehicles
+)
+
+// BatteryCategories lists the battery categories in display order
+var BatteryCategories = []string{
+	BatteryCategoryPortable,
+	BatteryCategoryLMT,
+	BatteryCategoryEV,
+	BatteryCategoryIndustrial,
+	BatteryCategorySLI,
+}
+
+// CollectionTarget is a waste battery collection rate producers must reach
+type CollectionTarget struct {
+	By  string  `json:"by"`  // YYYY-MM-DD
+	Pct float64 `json:"pct"` // Of batteries placed on the market (literal: 63 = 63%)
+}
+
+// BatteryCategoryRules are the EU obligations of a battery category. Dates are
+// when an obligation applies (YYYY-MM-DD); empty when it never does.
+type BatteryCategoryRules struct {
+	Category    string `json:"category"`
+	Name        string `json:"name"`
+	EPRCategory string `json:"epr_category"` // India BWM Rules category
+
+	PassportFrom        string `json:"passport_from,omitempty"`         // Digital battery passport (Art. 77)
+	CarbonFootprintFrom string `json:"carbon_footprint_from,omitempty"` // Carbon footprint declaration (Art. 7)
+	// ObligationMinEnergyWh limits the passport and carbon footprint to
+	// batteries above this rated energy; 0 applies them to all
+	ObligationMinEnergyWh float64 `json:"obligation_min_energy_wh,omitempty"`
+
+	Labelling         []string           `json:"labelling"`                    // Label and marking requirements (Art. 13)
+	CollectionTargets []CollectionTarget `json:"collection_targets,omitempty"` // Art. 59 and 69
+	Collection        string             `json:"collection"`                   // Producers' take-back obligation
+}
+
+// labellingAll applies to every category: the general information label from
+// 18 August 2026 and the QR code from 18 February 2027
+var labellingAll = []string{
+	"General information label: manufacturer, category, chemistry, weight, capacity, manufacture date",
+	"Separate collection symbol (crossed-out wheeled bin)",
+	"Cd / Pb symbol above 0.002% cadmium or 0.004% lead",
+	"QR code to the battery passport or battery information",
+}
+
+// labelling returns the labels of every category and a category's own
+func labelling(extra ...string) []string {
+	return append(append([]string{}, labellingAll...), extra...)
+}
+
+// batteryCategoryRules holds the obligations of each category
+var batteryCategoryRules = map[string]*BatteryCategoryRules{
+	BatteryCategoryPortable: {
+		Category: BatteryCategoryPortable, Name: "Portable battery", EPRCategory: EPRCategoryPortable,
+		Labelling: labelling(
+			"Capacity label (rechargeable)", "Minimum average duration (general-purpose, non-rechargeable)"),
+		CollectionTargets: []CollectionTarget{{"2023-12-31", 45}, {"2027-12-31", 63}, {"2030-12-31", 73}},
+		Collection:        "Collection rate targets for waste portable batteries",
+	},
+	BatteryCategoryLMT: {
+		Category: BatteryCategoryLMT, Name: "Light means of transport (LMT) battery", EPRCategory: EPRCategoryEV,
+		PassportFrom: "2027-02-18", CarbonFootprintFrom: "2028-08-18",
+		Labelling:         labelling("Capacity label"),
+		CollectionTargets: []CollectionTarget{{"2028-12-31", 51}, {"2031-12-31", 61}},
+		Collection:        "Collection rate targets for waste LMT batteries",
+	},
+	BatteryCategoryEV: {
+		Category: BatteryCategoryEV, Name: "Electric vehicle battery", EPRCategory: EPRCategoryEV,
+		PassportFrom: "2027-02-18", CarbonFootprintFrom: "2025-02-18",
+		Labelling:  labellingAll,
+		Collection: "Producers take back all waste EV batteries free of charge",
+	},
+	BatteryCategoryIndustrial: {
+		// Industrial batteries with external storage only declare their
+		// carbon footprint from 18 August 2030
+		Category: BatteryCategoryIndustrial, Name: "Industrial battery", EPRCategory: EPRCategoryIndustrial,
+		PassportFrom: "2027-02-18", CarbonFootprintFrom: "2026-02-18", ObligationMinEnergyWh: 2000,
+		Labelling:  labellingAll,
+		Collection: "Producers take back all waste industrial batteries free of charge",
+	},
+	BatteryCategorySLI: {
+		Category: BatteryCategorySLI, Name: "Starting, lighting and ignition (SLI) battery", EPRCategory: EPRCategoryAutomotive,
+		Labelling:  labelling("Capacity and cold cranking current label"),
+		Collection: "Producers take back all waste SLI batteries free of charge",
+	},
+}
+
+// IsValidBatteryCategory reports whether c is a battery category
+func IsValidBatteryCategory(c string) bool {
+	_, ok := batteryCategoryRules[c]
+	return ok
+}
+
+// BatteryCategoryRulesFor returns the obligations of a category, or nil if the
+// category is not set or unknown
+func BatteryCategoryRulesFor(category string) *BatteryCategoryRules {
+	return batteryCategoryRules[category]
+}
+
+// AllBatteryCategoryRules lists the obligations of every category, in display order
+func AllBatteryCategoryRules() []*BatteryCategoryRules {
+	rules := make([]*BatteryCategoryRules, 0, len(BatteryCategories))
+	for _, c := range BatteryCategories {
+		rules = append(rules, batteryCategoryRules[c])
+	}
+	return rules
+}
+
+// aboveThreshold reports whether a battery is over the category's energy
+// threshold. A battery of unknown energy is assumed to be.
+func (r *BatteryCategoryRules) aboveThreshold(ratings *BatchRatings) bool {
+	if r.ObligationMinEnergyWh == 0 || ratings == nil || ratings.RatedEnergyWh == 0 {
+		return true
+	}
+	return ratings.RatedEnergyWh > r.ObligationMinEnergyWh
+}
+
+// PassportRequired reports whether a battery of this category and ratings needs
+// a digital battery passport
+func (r *BatteryCategoryRules) PassportRequired(ratings *BatchRatings) bool {
+	return r.PassportFrom != "" && r.aboveThreshold(ratings)
+}
+
+// CarbonFootprintRequired reports whether a battery of this category and
+// ratings needs a carbon footprint declaration
+func (r *BatteryCategoryRules) CarbonFootprintRequired(ratings *BatchRatings) bool {
+	return r.CarbonFootprintFrom != "" && r.aboveThreshold(ratings)
+}
+
+// BatteryObligations are the obligations of one battery, shown on its passport
+type BatteryObligations struct {
+	Category            string             `json:"category"`
+	Name                string             `json:"name"`
+	PassportRequired    bool               `json:"passport_required"`
+	PassportFrom        string             `json:"passport_from,omitempty"`
+	CarbonFootprintFrom string             `json:"carbon_footprint_from,omitempty"` // Set when the declaration is required
+	Labelling           []string           `json:"labelling"`
+	CollectionTargets   []CollectionTarget `json:"collection_targets,omitempty"`
+	Collection          string             `json:"collection"`
+}
+
+// ObligationsFor returns the obligations of a battery from its category and
+// ratings, or nil if the category is not set
+func ObligationsFor(spec *BatchSpec) *BatteryObligations {
+	r := BatteryCategoryRulesFor(spec.BatteryCategory)
+	if r == nil {
+		return nil
+	}
+	o := &BatteryObligations{
+		Category:          r.Category,
+		Name:              r.Name,
+		PassportRequired:  r.PassportRequired(spec.Ratings),
+		Labelling:         r.Labelling,
+		CollectionTargets: r.CollectionTargets,
+		Collection:        r.Collection,
+	}
+	if o.PassportRequired {
+		o.PassportFrom = r.PassportFrom
+	}
+	if r.CarbonFootprintRequired(spec.Ratings) {
+		o.CarbonFootprintFrom = r.CarbonFootprintFrom
+	}
+	return o
+}
+
+// ValidateBatteryCategory normalises specs.battery_category and derives
+// specs.epr_category from it when that is not set. An unknown category, or a
+// portable battery over 5 kg, is an error; an epr_category disagreeing with the
+// category is a warning.
+func ValidateBatteryCategory(spec *BatchSpec) []SpecIssue {
+	spec.BatteryCategory = strings.ToUpper(strings.TrimSpace(spec.BatteryCategory))
+	if spec.BatteryCategory == "" {
+		return nil
+	}
+	r := BatteryCategoryRulesFor(spec.BatteryCategory)
+	if r == nil {
+		return []SpecIssue{{Field: "battery_category", Severity: SpecIssueError,
+			Message: "must be one of " + strings.Join(BatteryCategories, ", ")}}
+	}
+	if r.Category == BatteryCategoryPortable && spec.Ratings != nil && spec.Ratings.WeightKg > portableMaxWeightKg {
+		return []SpecIssue{{Field: "battery_category", Severity: SpecIssueError,
+			Message: fmt.Sprintf("portable batteries weigh at most %d kg; use LMT, EV or INDUSTRIAL", portableMaxWeightKg)}}
+	}
+
+	eprCategory := strings.ToUpper(strings.TrimSpace(spec.EPRCategory))
+	switch {
+	case eprCategory == "":
+		spec.EPRCategory = r.EPRCategory
+	case eprCategory != r.EPRCategory:
+		return []SpecIssue{{Field: "epr_category", Severity: SpecIssueWarning,
+			Message: fmt.Sprintf("%s batteries are normally %s under the BWM Rules, not %s", r.Category, r.EPRCategory, eprCategory)}}
+	}
+	return nil
+}
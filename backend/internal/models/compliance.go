@@ -0,0 +1,84 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// COMPLIANCE READINESS
+// ============================================================================
+
+// ComplianceSeverity tells whether a failed check stops the batch from shipping
+type ComplianceSeverity string
+
+const (
+	ComplianceBlocking ComplianceSeverity = "BLOCKING" // Mandatory for the market
+	ComplianceAdvisory ComplianceSeverity = "ADVISORY" // Recommended, or needed for a claim
+)
+
+// Score weights: a blocking check counts three times an advisory one
+const (
+	complianceBlockingWeight = 3
+	complianceAdvisoryWeight = 1
+)
+
+// ComplianceCheck is one item of a readiness checklist
+type ComplianceCheck struct {
+	Key      string             `json:"key"`    // e.g., "ce_marking", "epr_certificate"
+	Label    string             `json:"label"`  // Human readable name
+	Market   MarketRegion       `json:"market"` // Market of the rule pack; checks several packs share carry the report market
+	Severity ComplianceSeverity `json:"severity"`
+	Passed   bool               `json:"passed"`
+	Message  string             `json:"message,omitempty"` // What is missing, when failed
+}
+
+// ComplianceReport is a batch's readiness checklist for its target markets
+type ComplianceReport struct {
+	BatchID          uuid.UUID         `json:"batch_id"`
+	Market           MarketRegion      `json:"market"`  // The single market, or GLOBAL for several
+	Markets          []MarketRegion    `json:"markets"` // Markets whose rule packs were checked
+	Score            int               `json:"score"`   // 0-100, weighted by severity
+	Ready            bool              `json:"ready"`   // No blocking check failed
+	BlockingFailures int               `json:"blocking_failures"`
+	AdvisoryFailures int               `json:"advisory_failures"`
+	Checks           []ComplianceCheck `json:"checks"`
+	CheckedAt        time.Time         `json:"checked_at"`
+}
+
+// NewComplianceReport scores a checklist
+func NewComplianceReport(batchID uuid.UUID, markets []MarketRegion, checks []ComplianceCheck) *ComplianceReport {
+	report := &ComplianceReport{
+		BatchID:   batchID,
+		Market:    MarketsRegion(markets),
+		Markets:   markets,
+		Checks:    checks,
+		CheckedAt: time.Now().UTC(),
+	}
+
+	total, passed := 0, 0
+	for _, c := range checks {
+		weight := complianceAdvisoryWeight
+		if c.Severity == ComplianceBlocking {
+			weight = complianceBlockingWeight
+		}
+		total += weight
+		switch {
+		case c.Passed:
+			passed += weight
+		case c.Severity == ComplianceBlocking:
+			report.BlockingFailures++
+		default:
+			report.AdvisoryFailures++
+		}
+	}
+
+	report.Score = 100
+	if total > 0 {
+		report.Score = int(math.Floor(float64(passed) / float64(total) * 100))
+	}
+	report.Ready = report.BlockingFailures == 0
+	return report
+}
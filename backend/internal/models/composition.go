@@ -0,0 +1,170 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ============================================================================
+// MATERIAL COMPOSITION RULES
+// ============================================================================
+
+// EU 2023/1542 Annex I restrictions, as literal percentages by weight
+const (
+	MercuryLimitPct = 0.0005 // All batteries
+	CadmiumLimitPct = 0.002  // Portable batteries
+	LeadLimitPct    = 0.01   // Portable batteries (lead-acid excepted here)
+)
+
+// portableLimitsApply reports whether the portable-battery cadmium and lead
+// limits apply: to portable batteries, and to batteries of no declared category
+func portableLimitsApply(spec *BatchSpec) bool {
+	return spec.BatteryCategory == "" || spec.BatteryCategory == BatteryCategoryPortable
+}
+
+// compositionField is one material of a MaterialComposition
+type compositionField struct {
+	name string // JSON name under material_composition
+	pct  float64
+}
+
+func (c *MaterialComposition) fields() []compositionField {
+	return []compositionField{
+		{"cobalt_pct", c.CobaltPct},
+		{"lithium_pct", c.LithiumPct},
+		{"graphite_pct", c.GraphitePct},
+		{"nickel_pct", c.NickelPct},
+		{"lead_pct", c.LeadPct},
+		{"manganese_pct", c.ManganesePct},
+		{"mercury_pct", c.MercuryPct},
+		{"cadmium_pct", c.CadmiumPct},
+	}
+}
+
+// chemistryRule lists materials a chemistry cannot contain (errors) and ones it
+// is expected to contain (warnings when zero)
+type chemistryRule struct {
+	forbidden map[string]string // Field -> reason
+	expected  []string
+}
+
+var chemistryRules = map[string]chemistryRule{
+	ChemistryLFP: {
+		forbidden: map[string]string{
+			"cobalt_pct": "LFP cathodes contain no cobalt",
+			"nickel_pct": "LFP cathodes contain no nickel",
+		},
+		expected: []string{"lithium_pct"},
+	},
+	ChemistryNMC: {
+		expected: []string{"lithium_pct", "nickel_pct", "manganese_pct", "cobalt_pct"},
+	},
+	ChemistryNCA: {
+		forbidden: map[string]string{"manganese_pct": "NCA cathodes contain no manganese"},
+		expected:  []string{"lithium_pct", "nickel_pct", "cobalt_pct"},
+	},
+	ChemistryLCO: {
+		expected: []string{"lithium_pct", "cobalt_pct"},
+	},
+	ChemistryLeadAcid: {
+		forbidden: map[string]string{
+			"lithium_pct": "lead-acid batteries contain no lithium",
+			"cobalt_pct":  "lead-acid batteries contain no cobalt",
+		},
+		expected: []string{"lead_pct"},
+	},
+	ChemistryNiMH: {
+		forbidden: map[string]string{"lithium_pct": "NiMH batteries contain no lithium"},
+		expected:  []string{"nickel_pct"},
+	},
+}
+
+// ValidateComposition checks material_composition against the spec's chemistry
+// and the EU restricted-substance limits (cadmium and lead for portable
+// batteries only), and hazardous_substances for a declaration of every
+// substance flagged present
+func ValidateComposition(spec *BatchSpec) []SpecIssue {
+	var issues []SpecIssue
+	add := func(field, severity, format string, args ...interface{}) {
+		issues = append(issues, SpecIssue{Field: field, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	family := ChemistryFamily(spec.Chemistry)
+	hazards := spec.HazardousSubstances
+	if hazards == nil {
+		hazards = &HazardousSubstances{}
+	}
+
+	if comp := spec.MaterialComposition; comp != nil {
+		present := map[string]bool{}
+		total := 0.0
+		for _, f := range comp.fields() {
+			field := "material_composition." + f.name
+			if f.pct < 0 || f.pct > 100 {
+				add(field, SpecIssueError, "must be between 0 and 100")
+				continue
+			}
+			present[f.name] = f.pct > 0
+			total += f.pct
+		}
+		if total > 100+1e-9 {
+			add("material_composition", SpecIssueError, "materials add up to %g%%, more than the whole battery", roundTo(total, 4))
+		}
+
+		if rule, ok := chemistryRules[family]; ok {
+			for _, f := range comp.fields() {
+				if reason, forbidden := rule.forbidden[f.name]; forbidden && present[f.name] {
+					add("material_composition."+f.name, SpecIssueError, "%s; %g%% is not possible for %s", reason, f.pct, family)
+				}
+			}
+			// Only check expected materials once a composition has been entered
+			if total > 0 {
+				for _, name := range rule.expected {
+					if !present[name] {
+						add("material_composition."+name, SpecIssueWarning, "%s batteries normally contain %s",
+							family, strings.TrimSuffix(name, "_pct"))
+					}
+				}
+			}
+		}
+
+		if comp.MercuryPct > MercuryLimitPct {
+			add("material_composition.mercury_pct", SpecIssueError,
+				"%g%% exceeds the EU 2023/1542 mercury limit of %g%% by weight", comp.MercuryPct, MercuryLimitPct)
+		}
+		if portableLimitsApply(spec) && comp.CadmiumPct > CadmiumLimitPct {
+			add("material_composition.cadmium_pct", SpecIssueError,
+				"%g%% exceeds the EU 2023/1542 cadmium limit of %g%% by weight for portable batteries", comp.CadmiumPct, CadmiumLimitPct)
+		}
+		if portableLimitsApply(spec) && family != ChemistryLeadAcid && comp.LeadPct > LeadLimitPct {
+			add("material_composition.lead_pct", SpecIssueWarning,
+				"%g%% exceeds the EU 2023/1542 lead limit of %g%% by weight for portable batteries", comp.LeadPct, LeadLimitPct)
+		}
+
+		if comp.LeadPct > 0 && !hazards.LeadPresent {
+			add("hazardous_substances.lead_present", SpecIssueWarning, "material composition lists lead but lead_present is false")
+		}
+		if comp.MercuryPct > 0 && !hazards.MercuryPresent {
+			add("hazardous_substances.mercury_present", SpecIssueWarning, "material composition lists mercury but mercury_present is false")
+		}
+		if comp.CadmiumPct > 0 && !hazards.CadmiumPresent {
+			add("hazardous_substances.cadmium_present", SpecIssueWarning, "material composition lists cadmium but cadmium_present is false")
+		}
+	}
+
+	if (hazards.LeadPresent || hazards.MercuryPresent || hazards.CadmiumPresent) && strings.TrimSpace(hazards.Declaration) == "" {
+		add("hazardous_substances.declaration", SpecIssueError, "a declaration is required when lead, mercury or cadmium is present")
+	}
+	return issues
+}
+
+// ValidateBatchSpec derives the spec ratings and runs every spec rule. The
+// battery category is checked first, as the composition limits and the EPR
+// category depend on it.
+func ValidateBatchSpec(spec *BatchSpec) []SpecIssue {
+	issues := append(NormalizeBatchSpec(spec), ValidateBatteryCategory(spec)...)
+	issues = append(issues, ValidateComposition(spec)...)
+	issues = append(issues, ValidateDueDiligence(spec)...)
+	issues = append(issues, ValidateTransport(spec)...)
+	return append(issues, ValidateEPRCategory(spec)...)
+}
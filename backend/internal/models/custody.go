@@ -0,0 +1,137 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// CHAIN OF CUSTODY
+// ============================================================================
+
+// Custody party types
+const (
+	PartyManufacturer = "MANUFACTURER" // The tenant itself; never stored as a party
+	PartyDistributor  = "DISTRIBUTOR"
+	PartyRetailer     = "RETAILER"
+	PartyInstaller    = "INSTALLER"
+	PartyLogistics    = "LOGISTICS"
+	PartyEndUser      = "END_USER"
+	PartyRecycler     = "RECYCLER"
+	PartyOther        = "OTHER"
+)
+
+// IsValidPartyType reports whether t is a party type a transfer can name
+func IsValidPartyType(t string) bool {
+	switch t {
+	case PartyDistributor, PartyRetailer, PartyInstaller, PartyLogistics, PartyEndUser, PartyRecycler, PartyOther:
+		return true
+	}
+	return false
+}
+
+// PartyTypeForRole maps a magic link role to the party type it holds custody as
+func PartyTypeForRole(role string) string {
+	switch role {
+	case "TECHNICIAN":
+		return PartyInstaller
+	case "LOGISTICS":
+		return PartyLogistics
+	case "RECYCLER":
+		return PartyRecycler
+	case "CUSTOMER":
+		return PartyEndUser
+	}
+	return PartyOther
+}
+
+// Custody transfer statuses
+const (
+	TransferStatusPending   = "PENDING"   // Waiting for the receiving party to accept
+	TransferStatusAccepted  = "ACCEPTED"  // Custody changed hands at TransferredAt
+	TransferStatusRejected  = "REJECTED"  // Receiving party declined
+	TransferStatusCancelled = "CANCELLED" // Withdrawn by the manufacturer
+)
+
+// How a transfer was recorded
+const (
+	TransferMethodAcceptance = "ACCEPTANCE" // Receiving party accepted through the emailed link
+	TransferMethodMagicLink  = "MAGIC_LINK" // Party took custody with a magic link status update
+	TransferMethodImported   = "IMPORTED"   // Migrated from the former current owner email
+)
+
+// CustodyParty is a holder of batteries, identified per tenant by email
+type CustodyParty struct {
+	ID        uuid.UUID `json:"id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CustodyTransfer is one hand-over of a passport. A nil From means the
+// manufacturer. Transfers created together (a pallet) share GroupID and are
+// accepted or rejected together.
+type CustodyTransfer struct {
+	ID            uuid.UUID     `json:"id"`
+	GroupID       uuid.UUID     `json:"group_id"`
+	PassportID    uuid.UUID     `json:"passport_id"`
+	SerialNumber  string        `json:"serial_number"`
+	From          *CustodyParty `json:"from"`
+	To            CustodyParty  `json:"to"`
+	DocumentRef   string        `json:"document_ref,omitempty"` // Invoice, delivery note or e-way bill number
+	Note          string        `json:"note,omitempty"`
+	Status        string        `json:"status"`
+	Method        string        `json:"method"`
+	InitiatedBy   string        `json:"initiated_by"`
+	CreatedAt     time.Time     `json:"created_at"`
+	RespondedAt   *time.Time    `json:"responded_at,omitempty"`
+	ResponseNote  string        `json:"response_note,omitempty"`
+	TransferredAt *time.Time    `json:"transferred_at,omitempty"` // Set when accepted
+}
+
+// CustodyPeriod is one entry of a passport's ownership timeline. A nil Holder
+// means the manufacturer; HeldUntil is nil for the current holder.
+type CustodyPeriod struct {
+	Holder     *CustodyParty `json:"holder"`
+	HeldFrom   time.Time     `json:"held_from"`
+	HeldUntil  *time.Time    `json:"held_until,omitempty"`
+	TransferID *uuid.UUID    `json:"transfer_id,omitempty"` // Transfer that started the period
+	Document   string        `json:"document_ref,omitempty"`
+}
+
+// BuildCustodyTimeline turns a passport's accepted transfers, oldest first, into
+// holding periods. The manufacturer holds the battery from createdAt until the
+// first transfer.
+func BuildCustodyTimeline(createdAt time.Time, accepted []*CustodyTransfer) []CustodyPeriod {
+	timeline := []CustodyPeriod{{HeldFrom: createdAt}}
+	for _, t := range accepted {
+		if t.TransferredAt == nil {
+			continue
+		}
+		at := *t.TransferredAt
+		timeline[len(timeline)-1].HeldUntil = &at
+
+		holder := t.To
+		id := t.ID
+		timeline = append(timeline, CustodyPeriod{
+			Holder:     &holder,
+			HeldFrom:   at,
+			TransferID: &id,
+			Document:   t.DocumentRef,
+		})
+	}
+	return timeline
+}
+
+// CustodyHolderAt returns the timeline period covering at, or nil if at is
+// before the passport existed
+func CustodyHolderAt(timeline []CustodyPeriod, at time.Time) *CustodyPeriod {
+	for i := len(timeline) - 1; i >= 0; i-- {
+		if !at.Before(timeline[i].HeldFrom) {
+			return &timeline[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// RECYCLED CONTENT AND SUPPLY-CHAIN DUE DILIGENCE (EU 2023/1542 Art. 8, 48-52)
+// ============================================================================
+
+// RecycledContent is the share of each material recovered from battery
+// manufacturing or consumer waste, as literal percentages: 16 means 16% of the
+// cobalt in the battery is recycled
+type RecycledContent struct {
+	CobaltPct  float64 `json:"cobalt_pct,omitempty"`  // EU minimum 16% from 2031
+	LithiumPct float64 `json:"lithium_pct,omitempty"` // EU minimum 6% from 2031
+	NickelPct  float64 `json:"nickel_pct,omitempty"`  // EU minimum 6% from 2031
+	LeadPct    float64 `json:"lead_pct,omitempty"`    // EU minimum 85% from 2031
+}
+
+// Supply-chain party roles
+const (
+	SupplierRoleMine     = "MINE"
+	SupplierRoleSmelter  = "SMELTER"
+	SupplierRoleRefiner  = "REFINER"
+	SupplierRoleRecycler = "RECYCLER"
+	SupplierRoleCathode  = "CATHODE_PRODUCER"
+	SupplierRoleCell     = "CELL_MANUFACTURER"
+	SupplierRoleTrader   = "TRADER"
+)
+
+// SupplierRoles lists the valid supply-chain party roles
+var SupplierRoles = []string{
+	SupplierRoleMine,
+	SupplierRoleSmelter,
+	SupplierRoleRefiner,
+	SupplierRoleRecycler,
+	SupplierRoleCathode,
+	SupplierRoleCell,
+	SupplierRoleTrader,
+}
+
+// DueDiligenceMaterials are the raw materials covered by the due diligence
+// obligations
+var DueDiligenceMaterials = []string{"cobalt", "lithium", "nickel", "graphite"}
+
+// MaxSupplyChainParties bounds the supplier list of one batch
+const MaxSupplyChainParties = 200
+
+// SupplyChainParty is a supplier, smelter or mine in the batch's raw material
+// supply chain
+type SupplyChainParty struct {
+	Name          string `json:"name,omitempty"`          // Withheld from the public passport
+	Role          string `json:"role"`                    // MINE, SMELTER, REFINER...
+	Material      string `json:"material"`                // cobalt, lithium, nickel or graphite
+	Country       string `json:"country"`                 // ISO 3166-1 alpha-2 code of the site
+	Certification string `json:"certification,omitempty"` // e.g. RMI RMAP conformant, IRMA 75
+}
+
+// DueDiligence is the batch's supply-chain due diligence disclosure. The policy
+// and the role, material, country and certification of each party are public;
+// party names and audit reports are for partners with a legitimate interest.
+type DueDiligence struct {
+	Policy    string             `json:"policy,omitempty"`    // Standard followed, e.g. OECD Due Diligence Guidance
+	Suppliers []SupplyChainParty `json:"suppliers,omitempty"` // Smelters, mines and other parties
+}
+
+// Public returns the disclosure without party names
+func (d *DueDiligence) Public() *DueDiligence {
+	if d == nil {
+		return nil
+	}
+	public := &DueDiligence{Policy: d.Policy}
+	for _, s := range d.Suppliers {
+		s.Name = ""
+		public.Suppliers = append(public.Suppliers, s)
+	}
+	return public
+}
+
+// DueDiligenceDocument is a third-party audit report attached to a batch
+type DueDiligenceDocument struct {
+	ID         uuid.UUID  `json:"id"`
+	BatchID    uuid.UUID  `json:"batch_id"`
+	Title      string     `json:"title"`                 // e.g. RMAP assessment report 2025
+	Auditor    string     `json:"auditor,omitempty"`     // Audit firm or scheme
+	Supplier   string     `json:"supplier,omitempty"`    // Party audited; withheld from the public passport
+	IssuedOn   *time.Time `json:"issued_on,omitempty"`   // Report date
+	FileName   string     `json:"file_name,omitempty"`   // Uploaded file name; withheld from the public passport
+	SizeBytes  int64      `json:"size_bytes"`            // File size
+	UploadedBy string     `json:"uploaded_by,omitempty"` // Dashboard user email
+	CreatedAt  time.Time  `json:"created_at"`
+	FilePath   string     `json:"-"` // Stored under ./storage/{tenant_id}/due-diligence/
+}
+
+// Public returns the report without the audited party, file name and uploader
+func (d *DueDiligenceDocument) Public() *DueDiligenceDocument {
+	public := *d
+	public.Supplier = ""
+	public.FileName = ""
+	public.UploadedBy = ""
+	return &public
+}
+
+// countryCodeRegex matches an ISO 3166-1 alpha-2 code
+var countryCodeRegex = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// NormalizeDueDiligence upper-cases roles and country codes and lower-cases
+// materials so the disclosure is stored in one form
+func NormalizeDueDiligence(d *DueDiligence) {
+	if d == nil {
+		return
+	}
+	d.Policy = strings.TrimSpace(d.Policy)
+	for i := range d.Suppliers {
+		s := &d.Suppliers[i]
+		s.Name = strings.TrimSpace(s.Name)
+		s.Role = strings.ToUpper(strings.TrimSpace(s.Role))
+		s.Material = strings.ToLower(strings.TrimSpace(s.Material))
+		s.Country = strings.ToUpper(strings.TrimSpace(s.Country))
+		s.Certification = strings.TrimSpace(s.Certification)
+	}
+}
+
+// ValidateDueDiligence checks the recycled shares and the supplier list. A
+// recycled share of a material the composition does not list is a warning.
+func ValidateDueDiligence(spec *BatchSpec) []SpecIssue {
+	var issues []SpecIssue
+	add := func(field, severity, format string, args ...interface{}) {
+		issues = append(issues, SpecIssue{Field: field, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if rc := spec.RecycledContent; rc != nil {
+		comp := spec.MaterialComposition
+		if comp == nil {
+			comp = &MaterialComposition{}
+		}
+		shares := []struct {
+			name      string
+			pct, used float64
+		}{
+			{"cobalt", rc.CobaltPct, comp.CobaltPct},
+			{"lithium", rc.LithiumPct, comp.LithiumPct},
+			{"nickel", rc.NickelPct, comp.NickelPct},
+			{"lead", rc.LeadPct, comp.LeadPct},
+		}
+		for _, s := range shares {
+			field := "recycled_content." + s.name + "_pct"
+			switch {
+			case s.pct < 0 || s.pct > 100:
+				add(field, SpecIssueError, "must be between 0 and 100")
+			case s.pct > 0 && s.used == 0 && spec.MaterialComposition != nil:
+				add(field, SpecIssueWarning, "recycled %s is declared but material_composition lists no %s", s.name, s.name)
+			}
+		}
+	}
+
+	if spec.RecycledContentPct < 0 || spec.RecycledContentPct > 100 {
+		add("recycled_content_pct", SpecIssueError, "must be between 0 and 100")
+	}
+
+	if d := spec.DueDiligence; d != nil {
+		NormalizeDueDiligence(d)
+		if len(d.Suppliers) > MaxSupplyChainParties {
+			add("due_diligence.suppliers", SpecIssueError, "at most %d suppliers", MaxSupplyChainParties)
+			return issues
+		}
+		for i, s := range d.Suppliers {
+			field := fmt.Sprintf("due_diligence.suppliers[%d]", i)
+			if s.Name == "" {
+				add(field+".name", SpecIssueError, "is required")
+			}
+			if !isValidSupplierRole(s.Role) {
+				add(field+".role", SpecIssueError, "must be one of %s", strings.Join(SupplierRoles, ", "))
+			}
+			if !isDueDiligenceMaterial(s.Material) {
+				add(field+".material", SpecIssueError, "must be one of %s", strings.Join(DueDiligenceMaterials, ", "))
+			}
+			if !countryCodeRegex.MatchString(s.Country) {
+				add(field+".country", SpecIssueError, "must be a two-letter ISO country code, e.g. CD or AU")
+			}
+			if s.Certification == "" && (s.Role == SupplierRoleSmelter || s.Role == SupplierRoleRefiner) {
+				add(field+".certification", SpecIssueWarning, "no certification given for %s %s", strings.ToLower(s.Role), s.Name)
+			}
+		}
+	}
+	return issues
+}
+
+func isValidSupplierRole(role string) bool {
+	for _, r := range SupplierRoles {
+		if role == r {
+			return true
+		}
+	}
+	return false
+}
+
+func isDueDiligenceMaterial(material string) bool {
+	for _, m := range DueDiligenceMaterials {
+		if material == m {
+			return true
+		}
+	}
+	return false
+}
+
+// DueDiligenceDisclosure is a batch's recycled content and due diligence
+// information with its audit reports
+type DueDiligenceDisclosure struct {
+	BatchID            uuid.UUID               `json:"batch_id"`
+	RecycledContentPct float64                 `json:"recycled_content_pct,omitempty"` // Overall share, from specs
+	RecycledContent    *RecycledContent        `json:"recycled_content,omitempty"`
+	DueDiligence       *DueDiligence           `json:"due_diligence,omitempty"`
+	Documents          []*DueDiligenceDocument `json:"documents"`
+}
@@ -0,0 +1,317 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// EPR ANNUAL RETURN (India Battery Waste Management Rules 2022)
+// ============================================================================
+
+// Battery categories of the BWM Rules
+const (
+	EPRCategoryPortable   = "PORTABLE"   // Sealed, up to 5 kg, not automotive/industrial/EV
+	EPRCategoryAutomotive = "AUTOMOTIVE" // Starter, lighting and ignition
+	EPRCategoryIndustrial = "INDUSTRIAL" // Industrial use, storage, over 5 kg portable-type
+	EPRCategoryEV         = "EV"         // Traction batteries of electric vehicles
+)
+
+// EPRCategories lists the BWM battery categories in return order
+var EPRCategories = []string{
+	EPRCategoryPortable,
+	EPRCategoryAutomotive,
+	EPRCategoryIndustrial,
+	EPRCategoryEV,
+}
+
+// EPRCategoryUnclassified groups batches whose category is not set and cannot
+// be derived from their weight
+const EPRCategoryUnclassified = "UNCLASSIFIED"
+
+// portableMaxWeightKg is the BWM Rules upper weight of a portable battery
+const portableMaxWeightKg = 5
+
+// IsValidEPRCategory reports whether c is a BWM battery category
+func IsValidEPRCategory(c string) bool {
+	for _, category := range EPRCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// EPRCategoryFor returns the BWM category of a batch: the declared one, else
+// the one of its battery category, else PORTABLE for batteries of at most
+// 5 kg, else UNCLASSIFIED
+func EPRCategoryFor(spec BatchSpec) string {
+	if spec.EPRCategory != "" {
+		return spec.EPRCategory
+	}
+	if r := BatteryCategoryRulesFor(spec.BatteryCategory); r != nil {
+		return r.EPRCategory
+	}
+	if spec.Ratings != nil && spec.Ratings.WeightKg > 0 && spec.Ratings.WeightKg <= portableMaxWeightKg {
+		return EPRCategoryPortable
+	}
+	return EPRCategoryUnclassified
+}
+
+// ValidateEPRCategory normalises specs.epr_category. An unknown category is an
+// error; a battery over 5 kg without one is a warning, as the EPR return cannot
+// place it.
+func ValidateEPRCategory(spec *BatchSpec) []SpecIssue {
+	spec.EPRCategory = strings.ToUpper(strings.TrimSpace(spec.EPRCategory))
+	if spec.EPRCategory == "" {
+		if spec.Ratings != nil && spec.Ratings.WeightKg > portableMaxWeightKg {
+			return []SpecIssue{{Field: "epr_category", Severity: SpecIssueWarning,
+				Message: fmt.Sprintf("batteries over %d kg are not portable; set the category for the EPR annual return", portableMaxWeightKg)}}
+		}
+		return nil
+	}
+	if !IsValidEPRCategory(spec.EPRCategory) {
+		return []SpecIssue{{Field: "epr_category", Severity: SpecIssueError,
+			Message: "must be one of " + strings.Join(EPRCategories, ", ")}}
+	}
+	if spec.EPRCategory == EPRCategoryPortable && spec.Ratings != nil && spec.Ratings.WeightKg > portableMaxWeightKg {
+		return []SpecIssue{{Field: "epr_category", Severity: SpecIssueError,
+			Message: fmt.Sprintf("portable batteries weigh at most %d kg", portableMaxWeightKg)}}
+	}
+	return nil
+}
+
+// FinancialYear is an Indian financial year, April to March
+type FinancialYear struct {
+	StartYear int
+}
+
+// ist is India Standard Time; financial years start at midnight IST
+var ist = time.FixedZone("IST", 5*60*60+30*60)
+
+// FinancialYearOf returns the financial year containing t
+func FinancialYearOf(t time.Time) FinancialYear {
+	t = t.In(ist)
+	if t.Month() < time.April {
+		return FinancialYear{StartYear: t.Year() - 1}
+	}
+	return FinancialYear{StartYear: t.Year()}
+}
+
+// ParseFinancialYear parses "2025-26" or "2025" (the year it starts in)
+func ParseFinancialYear(s string) (FinancialYear, error) {
+	s = strings.TrimSpace(s)
+	start, end, hasEnd := strings.Cut(s, "-")
+	year, err := strconv.Atoi(start)
+	if err != nil || len(start) != 4 || year < 2000 || year > 2100 {
+		return FinancialYear{}, fmt.Errorf("financial year must look like 2025-26")
+	}
+	fy := FinancialYear{StartYear: year}
+	if hasEnd && end != fy.String()[5:] && end != strconv.Itoa(year+1) {
+		return FinancialYear{}, fmt.Errorf("financial year %s must end in the following year, e.g. %s", s, fy)
+	}
+	return fy, nil
+}
+
+// String formats the year as CPCB does, e.g. 2025-26
+func (fy FinancialYear) String() string {
+	return fmt.Sprintf("%d-%02d", fy.StartYear, (fy.StartYear+1)%100)
+}
+
+// Start is 1 April, 00:00 IST
+func (fy FinancialYear) Start() time.Time {
+	return time.Date(fy.StartYear, time.April, 1, 0, 0, 0, 0, ist)
+}
+
+// End is the start of the next year (exclusive)
+func (fy FinancialYear) End() time.Time {
+	return time.Date(fy.StartYear+1, time.April, 1, 0, 0, 0, 0, ist)
+}
+
+// EPRTarget is the share of the weight placed on the market in a category
+// that must be collected and recycled, as a literal percentage
+type EPRTarget struct {
+	Category  string     `json:"category"`
+	TargetPct float64    `json:"target_pct"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"` // Unset for defaults
+}
+
+// DefaultEPRTargets apply to categories a tenant has not configured. Schedule II
+// targets step up each year; set them to those on the EPR certificate.
+var DefaultEPRTargets = map[string]float64{
+	EPRCategoryPortable:   70,
+	EPRCategoryAutomotive: 90,
+	EPRCategoryIndustrial: 70,
+	EPRCategoryEV:         70,
+}
+
+// EPRBatchQuantity is what one batch placed on the market and had recycled in
+// a financial year
+type EPRBatchQuantity struct {
+	BatchID       uuid.UUID
+	BatchName     string
+	Specs         BatchSpec
+	UnitsPlaced   int
+	UnitsRecycled int
+}
+
+// EPRReturnLine is a row of the return: one category and chemistry
+type EPRReturnLine struct {
+	Category           string  `json:"category"`
+	Chemistry          string  `json:"chemistry"`
+	Batches            int     `json:"batches"`
+	UnitsPlaced        int     `json:"units_placed"`
+	WeightPlacedKg     float64 `json:"weight_placed_kg"`
+	UnitsRecycled      int     `json:"units_recycled"`
+	WeightRecycledKg   float64 `json:"weight_recycled_kg"`
+	UnitsWithoutWeight int     `json:"units_without_weight"` // Counted, but not in the weights
+}
+
+// EPRObligation is a category's recycling obligation for the year. Recycled
+// passports are credited whenever they were placed on the market.
+type EPRObligation struct {
+	Category         string  `json:"category"`
+	TargetPct        float64 `json:"target_pct"`
+	WeightPlacedKg   float64 `json:"weight_placed_kg"`
+	ObligationKg     float64 `json:"obligation_kg"`
+	RecycledCreditKg float64 `json:"recycled_credit_kg"`
+	ShortfallKg      float64 `json:"shortfall_kg"`
+	FulfilledPct     float64 `json:"fulfilled_pct"` // Capped at 100
+}
+
+// EPRAnnualReturn is a producer's annual return for one financial year
+type EPRAnnualReturn struct {
+	FinancialYear         string          `json:"financial_year"` // e.g. 2025-26
+	PeriodStart           time.Time       `json:"period_start"`
+	PeriodEnd             time.Time       `json:"period_end"` // Exclusive
+	CompanyName           string          `json:"company_name"`
+	EPRRegistrationNumber string          `json:"epr_registration_number"`
+	Lines                 []EPRReturnLine `json:"lines"`
+	Obligations           []EPRObligation `json:"obligations"`
+	TotalUnitsPlaced      int             `json:"total_units_placed"`
+	TotalWeightPlacedKg   float64         `json:"total_weight_placed_kg"`
+	TotalUnitsRecycled    int             `json:"total_units_recycled"`
+	TotalWeightRecycledKg float64         `json:"total_weight_recycled_kg"`
+	Warnings              []string        `json:"warnings,omitempty"`
+	GeneratedAt           time.Time       `json:"generated_at"`
+}
+
+// roundKg rounds a weight to grams
+func roundKg(kg float64) float64 {
+	return math.Round(kg*1000) / 1000
+}
+
+// BuildEPRReturn aggregates batch quantities by category and chemistry and
+// applies the targets (by category) to the weight placed on the market
+func BuildEPRReturn(fy FinancialYear, tenant *Tenant, quantities []EPRBatchQuantity, targets map[string]float64) *EPRAnnualReturn {
+	ret := &EPRAnnualReturn{
+		FinancialYear:         fy.String(),
+		PeriodStart:           fy.Start(),
+		PeriodEnd:             fy.End(),
+		CompanyName:           tenant.CompanyName,
+		EPRRegistrationNumber: tenant.EPRRegistrationNumber,
+		Lines:                 []EPRReturnLine{},
+		Obligations:           []EPRObligation{},
+		GeneratedAt:           time.Now().UTC(),
+	}
+	if tenant.EPRRegistrationNumber == "" {
+		ret.Warnings = append(ret.Warnings, "No EPR registration number on the company profile")
+	}
+
+	lines := map[[2]string]*EPRReturnLine{}
+	var unclassified, withoutWeight []string
+	for _, q := range quantities {
+		category := EPRCategoryFor(q.Specs)
+		chemistry := strings.TrimSpace(q.Specs.Chemistry)
+		if chemistry == "" {
+			chemistry = "Unspecified"
+		}
+		key := [2]string{category, chemistry}
+		line := lines[key]
+		if line == nil {
+			line = &EPRReturnLine{Category: category, Chemistry: chemistry}
+			lines[key] = line
+		}
+
+		line.Batches++
+		line.UnitsPlaced += q.UnitsPlaced
+		line.UnitsRecycled += q.UnitsRecycled
+		if q.Specs.Ratings != nil && q.Specs.Ratings.WeightKg > 0 {
+			line.WeightPlacedKg += float64(q.UnitsPlaced) * q.Specs.Ratings.WeightKg
+			line.WeightRecycledKg += float64(q.UnitsRecycled) * q.Specs.Ratings.WeightKg
+		} else {
+			line.UnitsWithoutWeight += q.UnitsPlaced + q.UnitsRecycled
+			withoutWeight = append(withoutWeight, q.BatchName)
+		}
+		if category == EPRCategoryUnclassified {
+			unclassified = append(unclassified, q.BatchName)
+		}
+	}
+	if len(unclassified) > 0 {
+		ret.Warnings = append(ret.Warnings, fmt.Sprintf("%d batch(es) have no battery category and are not in any obligation: %s",
+			len(unclassified), strings.Join(unclassified, ", ")))
+	}
+	if len(withoutWeight) > 0 {
+		ret.Warnings = append(ret.Warnings, fmt.Sprintf("%d batch(es) have no weight and are counted in units only: %s",
+			len(withoutWeight), strings.Join(withoutWeight, ", ")))
+	}
+
+	order := func(category string) int {
+		for i, c := range EPRCategories {
+			if c == category {
+				return i
+			}
+		}
+		return len(EPRCategories)
+	}
+	for _, line := range lines {
+		line.WeightPlacedKg = roundKg(line.WeightPlacedKg)
+		line.WeightRecycledKg = roundKg(line.WeightRecycledKg)
+		ret.Lines = append(ret.Lines, *line)
+	}
+	sort.Slice(ret.Lines, func(i, j int) bool {
+		a, b := ret.Lines[i], ret.Lines[j]
+		if a.Category != b.Category {
+			return order(a.Category) < order(b.Category)
+		}
+		return a.Chemistry < b.Chemistry
+	})
+
+	for _, category := range EPRCategories {
+		o := EPRObligation{Category: category, TargetPct: targets[category]}
+		for _, line := range ret.Lines {
+			if line.Category == category {
+				o.WeightPlacedKg += line.WeightPlacedKg
+				o.RecycledCreditKg += line.WeightRecycledKg
+			}
+		}
+		if o.WeightPlacedKg == 0 && o.RecycledCreditKg == 0 {
+			continue
+		}
+		o.WeightPlacedKg = roundKg(o.WeightPlacedKg)
+		o.RecycledCreditKg = roundKg(o.RecycledCreditKg)
+		o.ObligationKg = roundKg(o.WeightPlacedKg * o.TargetPct / 100)
+		o.ShortfallKg = roundKg(math.Max(0, o.ObligationKg-o.RecycledCreditKg))
+		o.FulfilledPct = 100
+		if o.ObligationKg > 0 {
+			o.FulfilledPct = math.Round(math.Min(100, o.RecycledCreditKg/o.ObligationKg*100)*10) / 10
+		}
+		ret.Obligations = append(ret.Obligations, o)
+	}
+
+	for _, line := range ret.Lines {
+		ret.TotalUnitsPlaced += line.UnitsPlaced
+		ret.TotalWeightPlacedKg += line.WeightPlacedKg
+		ret.TotalUnitsRecycled += line.UnitsRecycled
+		ret.TotalWeightRecycledKg += line.WeightRecycledKg
+	}
+	ret.TotalWeightPlacedKg = roundKg(ret.TotalWeightPlacedKg)
+	ret.TotalWeightRecycledKg = roundKg(ret.TotalWeightRecycledKg)
+	return ret
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// TENANT EVENT STREAM
+// ============================================================================
+
+// Event types pushed on the tenant event stream
+const (
+	EventScanRecorded         = "scan.recorded"
+	EventPassportTransitioned = "passport.transitioned"
+	EventPassportsBulkUpdated = "passports.bulk_updated" // Dashboard bulk status change
+	EventBatchActivated       = "batch.activated"
+	EventImportProgress       = "import.progress"
+	EventRecallCreated        = "recall.created"
+	EventCustodyTransferred   = "custody.transferred"
+	EventShipmentShipped      = "shipment.shipped"
+)
+
+// EventTypes lists every stream event type, for filtering
+var EventTypes = []string{
+	EventScanRecorded,
+	EventPassportTransitioned,
+	EventPassportsBulkUpdated,
+	EventBatchActivated,
+	EventImportProgress,
+	EventRecallCreated,
+	EventCustodyTransferred,
+	EventShipmentShipped,
+}
+
+// TenantEvent is one entry of a tenant's event stream. ID orders events and is
+// sent as the SSE id, so clients resume with Last-Event-ID.
+type TenantEvent struct {
+	ID        int64           `json:"id"`
+	TenantID  uuid.UUID       `json:"-"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Passport events are published by passport ID; serial_number, batch_id and
+// batch_name are filled in from the passport's batch when the event is stored.
+
+// ScanRecordedData is the data of a scan.recorded event (a superset of ScanFeedItem)
+type ScanRecordedData struct {
+	ID           uuid.UUID `json:"id"`
+	PassportID   uuid.UUID `json:"passport_id"`
+	City         string    `json:"city"`
+	Country      string    `json:"country"`
+	DeviceType   string    `json:"device_type"`
+	ScannedAt    time.Time `json:"scanned_at"`
+	SerialNumber string    `json:"serial_number"`
+	BatchID      uuid.UUID `json:"batch_id"`
+	BatchName    string    `json:"batch_name"`
+}
+
+// PassportTransitionedData is the data of a passport.transitioned event
+type PassportTransitionedData struct {
+	PassportID     uuid.UUID `json:"passport_id"`
+	EventID        uuid.UUID `json:"event_id"` // passport_events row
+	PreviousStatus string    `json:"previous_status"`
+	NewStatus      string    `json:"new_status"`
+	Actor          string    `json:"actor"`
+	SerialNumber   string    `json:"serial_number"`
+	BatchID        uuid.UUID `json:"batch_id"`
+	BatchName      string    `json:"batch_name"`
+}
+
+// PassportsBulkUpdatedData is the data of a passports.bulk_updated event
+type PassportsBulkUpdatedData struct {
+	PassportIDs []uuid.UUID `json:"passport_ids"`
+	Status      string      `json:"status"`
+	Updated     int64       `json:"updated"`
+}
+
+// BatchActivatedData is the data of a batch.activated event
+type BatchActivatedData struct {
+	BatchID       uuid.UUID `json:"batch_id"`
+	BatchName     string    `json:"batch_name"`
+	PassportCount int       `json:"passport_count"`
+	QuotaBalance  int       `json:"quota_balance"`
+}
+
+// Import sources and stages reported by import.progress
+const (
+	ImportSourceCSV          = "csv"
+	ImportSourceAutoGenerate = "auto_generate"
+	ImportSourceAPI          = "api"
+
+	ImportStageStarted   = "started"
+	ImportStageInserting = "inserting"
+	ImportStageCompleted = "completed"
+	ImportStageFailed    = "failed"
+)
+
+// ImportProgressData is the data of an import.progress event. One import sends
+// started, then inserting after each chunk of rows, then completed or failed;
+// JobID ties them together.
+type ImportProgressData struct {
+	JobID     uuid.UUID `json:"job_id"`
+	BatchID   uuid.UUID `json:"batch_id"`
+	Source    string    `json:"source"`
+	Stage     string    `json:"stage"`
+	Total     int       `json:"total"`     // Passports to insert
+	Processed int       `json:"processed"` // Passports inserted so far (committed on completed)
+	Rejected  int       `json:"rejected"`  // CSV rows that failed validation
+	Error     string    `json:"error,omitempty"`
+}
+
+// RecallCreatedData is the data of a recall.created event
+type RecallCreatedData struct {
+	CampaignID   uuid.UUID `json:"campaign_id"`
+	Title        string    `json:"title"`
+	Severity     string    `json:"severity"`
+	Affected     int       `json:"affected"`
+	Transitioned int       `json:"transitioned"` // Passports moved to RECALLED
+}
+
+// CustodyTransferredData is the data of a custody.transferred event, sent when
+// the receiving party accepts a transfer group
+type CustodyTransferredData struct {
+	GroupID     uuid.UUID `json:"group_id"`
+	ToEmail     string    `json:"to_email"`
+	ToName      string    `json:"to_name"`
+	ToType      string    `json:"to_type"`
+	DocumentRef string    `json:"document_ref,omitempty"`
+	Count       int       `json:"count"` // Passports now held by the receiving party
+}
+
+// ShipmentShippedData is the data of a shipment.shipped event
+type ShipmentShippedData struct {
+	ShipmentID         uuid.UUID `json:"shipment_id"`
+	Reference          string    `json:"reference"`
+	DestinationCountry string    `json:"destination_country"`
+	Count              int       `json:"count"` // Passports moved to SHIPPED
+}
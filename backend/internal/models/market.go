@@ -0,0 +1,149 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ============================================================================
+// TARGET MARKETS
+// ============================================================================
+
+// Markets lists the single markets in display order. GLOBAL is not one of them.
+var Markets = []MarketRegion{MarketRegionIndia, MarketRegionEU, MarketRegionUS, MarketRegionUK}
+
+// ValidMarketRegions names the accepted market_region values in error messages
+const ValidMarketRegions = "INDIA, EU, US, UK, or GLOBAL"
+
+// RegionMarkets returns the markets of a stored market_region: GLOBAL without
+// a market list predates multi-market batches and means INDIA and EU
+func RegionMarkets(region MarketRegion) []MarketRegion {
+	if region.IsMarket() {
+		return []MarketRegion{region}
+	}
+	return []MarketRegion{MarketRegionIndia, MarketRegionEU}
+}
+
+// StoredMarkets returns the markets of a batch row, falling back to its
+// market_region for rows written before the market list existed
+func StoredMarkets(region MarketRegion, markets []string) []MarketRegion {
+	if len(markets) == 0 {
+		return RegionMarkets(region)
+	}
+	out := make([]MarketRegion, len(markets))
+	for i, m := range markets {
+		out[i] = MarketRegion(m)
+	}
+	return out
+}
+
+// MarketStrings returns markets as strings for a TEXT[] column
+func MarketStrings(markets []MarketRegion) []string {
+	out := make([]string, len(markets))
+	for i, m := range markets {
+		out[i] = string(m)
+	}
+	return out
+}
+
+// MarketsRegion is the market_region summary of a market list: the market
+// itself for one, GLOBAL for several
+func MarketsRegion(markets []MarketRegion) MarketRegion {
+	if len(markets) == 1 {
+		return markets[0]
+	}
+	return MarketRegionGlobal
+}
+
+// ParseMarkets normalises a list of markets: upper case, GLOBAL expanded to
+// INDIA and EU, duplicates dropped, in display order
+func ParseMarkets(values []string) ([]MarketRegion, error) {
+	set := map[MarketRegion]bool{}
+	for _, v := range values {
+		m := MarketRegion(strings.ToUpper(strings.TrimSpace(v)))
+		if !m.IsValid() {
+			return nil, fmt.Errorf("invalid market %q. Must be %s", v, ValidMarketRegions)
+		}
+		for _, market := range RegionMarkets(m) {
+			set[market] = true
+		}
+	}
+	var markets []MarketRegion
+	for _, m := range Markets {
+		if set[m] {
+			markets = append(markets, m)
+		}
+	}
+	return markets, nil
+}
+
+// ResolveMarkets returns the markets and market_region summary of a new
+// batch. markets takes precedence; a market_region sent alongside it must agree.
+// Without either the batch is GLOBAL (INDIA and EU).
+func ResolveMarkets(region MarketRegion, markets []MarketRegion) ([]MarketRegion, MarketRegion, error) {
+	region = MarketRegion(strings.ToUpper(strings.TrimSpace(string(region))))
+	if region == "" {
+		region = MarketRegionGlobal
+	}
+	if !region.IsValid() {
+		return nil, "", fmt.Errorf("Invalid market_region. Must be %s", ValidMarketRegions)
+	}
+	if len(markets) == 0 {
+		return RegionMarkets(region), region, nil
+	}
+
+	values := make([]string, len(markets))
+	for i, m := range markets {
+		if strings.EqualFold(strings.TrimSpace(string(m)), string(MarketRegionGlobal)) {
+			return nil, "", fmt.Errorf("markets lists single markets (INDIA, EU, US, UK); GLOBAL is not one")
+		}
+		values[i] = string(m)
+	}
+	resolved, err := ParseMarkets(values)
+	if err != nil {
+		return nil, "", err
+	}
+	summary := MarketsRegion(resolved)
+	if region != MarketRegionGlobal && region != summary {
+		return nil, "", fmt.Errorf("market_region %s does not match markets; omit it or send %s", region, summary)
+	}
+	return resolved, summary, nil
+}
+
+// Targets reports whether the batch targets market
+func (b *Batch) Targets(market MarketRegion) bool {
+	for _, m := range b.Markets {
+		if m == market {
+			return true
+		}
+	}
+	return false
+}
+
+// MarketRequirementKind groups the requirements of a market rule pack
+type MarketRequirementKind string
+
+const (
+	MarketRequirementField         MarketRequirementKind = "FIELD"         // Batch or company profile data
+	MarketRequirementCertification MarketRequirementKind = "CERTIFICATION" // Mark in specs.certifications
+	MarketRequirementDocument      MarketRequirementKind = "DOCUMENT"      // Certificate, report or declaration
+)
+
+// MarketRequirement is one rule of a market rule pack, as checked in the
+// compliance checklist under the same key
+type MarketRequirement struct {
+	Key      string                `json:"key"`
+	Label    string                `json:"label"`
+	Kind     MarketRequirementKind `json:"kind"`
+	Severity ComplianceSeverity    `json:"severity"`            // In the compliance checklist
+	OnCreate string                `json:"on_create,omitempty"` // ERROR: refuses the batch; WARNING: reported on creation
+}
+
+// MarketRulePackInfo describes what a market requires of a batch
+type MarketRulePackInfo struct {
+	Market         MarketRegion        `json:"market"`
+	Name           string              `json:"name"`           // e.g. "EU Battery Regulation 2023/1542"
+	Certifications []string            `json:"certifications"` // Marks the pack accepts in specs.certifications
+	Documents      []string            `json:"documents"`      // Document types expected for the market
+	Requirements   []MarketRequirement `json:"requirements"`
+}
@@ -17,13 +17,20 @@ type MarketRegion string
 const (
 	MarketRegionIndia  MarketRegion = "INDIA"  // Battery Aadhaar (domestic)
 	MarketRegionEU     MarketRegion = "EU"     // Battery Passport (export)
-	MarketRegionGlobal MarketRegion = "GLOBAL" // Both markets (default)
+	MarketRegionUS     MarketRegion = "US"     // UL listing, DOT transport, IRA sourcing (export)
+	MarketRegionUK     MarketRegion = "UK"     // UKCA marking (export)
+	MarketRegionGlobal MarketRegion = "GLOBAL" // Several markets, listed in Batch.Markets (default: INDIA and EU)
 )
 
 // IsValid checks if the market region is valid
 func (m MarketRegion) IsValid() bool {
+	return m == MarketRegionGlobal || m.IsMarket()
+}
+
+// IsMarket reports whether m is a single market rather than GLOBAL
+func (m MarketRegion) IsMarket() bool {
 	switch m {
-	case MarketRegionIndia, MarketRegionEU, MarketRegionGlobal:
+	case MarketRegionIndia, MarketRegionEU, MarketRegionUS, MarketRegionUK:
 		return true
 	}
 	return false
@@ -116,11 +123,12 @@ type Batch struct {
 	Status    string    `json:"status"` // DRAFT, ACTIVE, ARCHIVED
 
 	// Dual-Mode Compliance Fields
-	MarketRegion     MarketRegion `json:"market_region"`         // INDIA, EU, or GLOBAL
-	PLICompliant     bool         `json:"pli_compliant"`         // India: PLI subsidy eligibility
-	DomesticValueAdd float64      `json:"domestic_value_add"`    // India: % of local value (stored as literal: 45.5 = 45.5%)
-	CellSource       string       `json:"cell_source,omitempty"` // IMPORTED or DOMESTIC
-	TotalPassports   int          `json:"total_passports"`       // Computed count of passports
+	MarketRegion     MarketRegion   `json:"market_region"`         // INDIA, EU, US, UK, or GLOBAL for several
+	Markets          []MarketRegion `json:"markets"`               // Target markets, each checked by its rule pack
+	PLICompliant     bool           `json:"pli_compliant"`         // India: PLI subsidy eligibility
+	DomesticValueAdd float64        `json:"domestic_value_add"`    // India: % of local value (stored as literal: 45.5 = 45.5%)
+	CellSource       string         `json:"cell_source,omitempty"` // IMPORTED or DOMESTIC
+	TotalPassports   int            `json:"total_passports"`       // Computed count of passports
 
 	// India Import/Customs Fields (Required when CellSource = IMPORTED)
 	BillOfEntryNo   string     `json:"bill_of_entry_no,omitempty"`  // Customs Bill of Entry number
@@ -131,9 +139,10 @@ type Batch struct {
 	HSNCode string `json:"hsn_code,omitempty"` // Harmonized System Nomenclature code (e.g., "8507.60")
 
 	// PLI/DVA Audit Compliance Fields (reduces legal liability)
+	// Set only by a CA attestation through the PLI audit workflow
 	DVASource               string   `json:"dva_source,omitempty"`                 // "ESTIMATED" or "AUDITED"
 	AuditedDomesticValueAdd *float64 `json:"audited_domestic_value_add,omitempty"` // CA-certified DVA % (nullable)
-	PLICertificateURL       string   `json:"pli_certificate_url,omitempty"`        // Path to CA certificate
+	PLICertificateURL       string   `json:"pli_certificate_url,omitempty"`        // API path of the signed CA certificate
 }
 
 // BatchSpec holds the common specifications stored as JSONB
@@ -160,6 +169,37 @@ type BatchSpec struct {
 	WarrantyMonths         int                  `json:"warranty_months,omitempty"`          // Warranty period in months
 	RecycledContentPct     float64              `json:"recycled_content_pct,omitempty"`     // % recycled content (stored as literal: 15.5 = 15.5%)
 	HazardousSubstances    *HazardousSubstances `json:"hazardous_substances,omitempty"`     // REACH/RoHS compliance
+
+	// Recycled share per material and supply-chain due diligence. Party names
+	// and audit reports are withheld from the public passport.
+	RecycledContent *RecycledContent `json:"recycled_content,omitempty"`
+	DueDiligence    *DueDiligence    `json:"due_diligence,omitempty"`
+
+	// Battery category under the EU Battery Regulation; sets the passport,
+	// labelling, carbon footprint and collection obligations (see category.go)
+	BatteryCategory string `json:"battery_category,omitempty"` // PORTABLE, LMT, EV, INDUSTRIAL, SLI
+
+	// Battery category under India's BWM Rules 2022, for the EPR annual return.
+	// Derived from battery_category when unset; otherwise batteries of at most
+	// 5 kg are reported as PORTABLE.
+	EPRCategory string `json:"epr_category,omitempty"` // PORTABLE, AUTOMOTIVE, INDUSTRIAL, EV
+
+	// Reference of the UN 38.3 test summary (lithium batteries), quoted on the
+	// dangerous-goods section of the customs documentation pack
+	UN383TestSummary string `json:"un38_3_test_summary,omitempty"`
+
+	// UK market: importer or authorised representative named on the product
+	// (UKCA), and the producer registration under the Waste Batteries Regulations
+	UKResponsiblePerson string `json:"uk_responsible_person,omitempty"`
+	UKProducerNumber    string `json:"uk_producer_number,omitempty"`
+
+	// Calculated carbon footprint: lifecycle stages and performance class.
+	// Set with CarbonFootprint by the calculator; the full report is kept on the batch.
+	CarbonFootprintDetail *CarbonFootprintDeclaration `json:"carbon_footprint_detail,omitempty"`
+
+	// Voltage, capacity and weight in base units with rated and specific energy.
+	// Derived from the strings above on save; client-supplied values are replaced.
+	Ratings *BatchRatings `json:"ratings,omitempty"`
 }
 
 // MaterialComposition holds the critical raw material percentages (EU Battery Regulation)
@@ -171,6 +211,8 @@ type MaterialComposition struct {
 	NickelPct    float64 `json:"nickel_pct,omitempty"`    // e.g., 15.0 = 15%
 	LeadPct      float64 `json:"lead_pct,omitempty"`      // e.g., 0 = 0%
 	ManganesePct float64 `json:"manganese_pct,omitempty"` // Common in LFP batteries
+	MercuryPct   float64 `json:"mercury_pct,omitempty"`   // EU limit 0.0005%
+	CadmiumPct   float64 `json:"cadmium_pct,omitempty"`   // EU limit 0.002% (portable)
 }
 
 // HazardousSubstances holds REACH/RoHS compliance declarations
@@ -356,33 +398,33 @@ type CSVRow struct {
 	ManufactureDate string `json:"manufacture_date"` // Expected format: YYYY-MM-DD
 }
 
-// CreateBatchRequest is the request body for creating a new batch
+// CreateBatchRequest is the request body for creating a new batch. India
+// batches start with an ESTIMATED DVA; an audited one can only come from a
+// chartered accountant's attestation (PLI audit).
 type CreateBatchRequest struct {
 	TenantID  uuid.UUID `json:"tenant_id"`
 	BatchName string    `json:"batch_name"`
 	Specs     BatchSpec `json:"specs"`
 
 	// Dual-Mode Fields
-	MarketRegion     MarketRegion `json:"market_region"`                // INDIA, EU, or GLOBAL
-	PLICompliant     bool         `json:"pli_compliant,omitempty"`      // India only
-	DomesticValueAdd float64      `json:"domestic_value_add,omitempty"` // India only (stored as literal: 45.5 = 45.5%)
-	CellSource       string       `json:"cell_source,omitempty"`        // India only
+	MarketRegion     MarketRegion   `json:"market_region"`                // INDIA, EU, US, UK, or GLOBAL (INDIA and EU)
+	Markets          []MarketRegion `json:"markets,omitempty"`            // Several target markets; takes precedence over market_region
+	PLICompliant     bool           `json:"pli_compliant,omitempty"`      // India only
+	DomesticValueAdd float64        `json:"domestic_value_add,omitempty"` // India only (stored as literal: 45.5 = 45.5%)
+	CellSource       string         `json:"cell_source,omitempty"`        // India only
 
-	// India Import/Customs Fields (Required when MarketRegion=INDIA and CellSource=IMPORTED)
+	// India Import/Customs Fields (Required when the batch targets INDIA and CellSource=IMPORTED)
 	BillOfEntryNo   string `json:"bill_of_entry_no,omitempty"`  // Customs Bill of Entry number
 	CountryOfOrigin string `json:"country_of_origin,omitempty"` // Source country
 	CustomsDate     string `json:"customs_date,omitempty"`      // Date in YYYY-MM-DD format
 	HSNCode         string `json:"hsn_code,omitempty"`          // India: HSN code (e.g., "8507.60")
-
-	// PLI/DVA Audit Compliance Fields
-	DVASource               string   `json:"dva_source,omitempty"`                 // "ESTIMATED" or "AUDITED"
-	AuditedDomesticValueAdd *float64 `json:"audited_domestic_value_add,omitempty"` // CA-certified DVA %
-	PLICertificateURL       string   `json:"pli_certificate_url,omitempty"`        // Path to CA certificate
 }
 
 // CreateBatchResponse is the response after creating a batch
 type CreateBatchResponse struct {
-	Batch *Batch `json:"batch"`
+	Batch          *Batch            `json:"batch"`
+	Warnings       []SpecIssue       `json:"warnings,omitempty"`        // Spec issues that did not block creation
+	MarketWarnings []ComplianceCheck `json:"market_warnings,omitempty"` // Failed market rules that did not block creation
 }
 
 // UploadCSVResponse is the response after processing a CSV upload
@@ -395,11 +437,12 @@ type UploadCSVResponse struct {
 
 // PassportWithSpecs combines passport data with batch specs for the public page
 type PassportWithSpecs struct {
-	Passport     *Passport    `json:"passport"`
-	BatchName    string       `json:"batch_name"`
-	Specs        *BatchSpec   `json:"specs"`
-	MarketRegion MarketRegion `json:"market_region"` // For conditional UI rendering
-	Tenant       *Tenant      `json:"tenant"`        // Added for public profile display
+	Passport     *Passport      `json:"passport"`
+	BatchName    string         `json:"batch_name"`
+	Specs        *BatchSpec     `json:"specs"`
+	MarketRegion MarketRegion   `json:"market_region"` // For conditional UI rendering
+	Markets      []MarketRegion `json:"markets"`       // Target markets of the batch
+	Tenant       *Tenant        `json:"tenant"`        // Added for public profile display
 	// Added for Import/Domestic logic
 	CellSource      string `json:"cell_source,omitempty"`
 	BillOfEntryNo   string `json:"bill_of_entry_no,omitempty"`
@@ -409,6 +452,15 @@ type PassportWithSpecs struct {
 	PLICompliant     bool       `json:"pli_compliant,omitempty"`
 	CustomsDate      *time.Time `json:"customs_date,omitempty"`
 	HSNCode          string     `json:"hsn_code,omitempty"`
+	// Active recall covering this passport, shown as a warning banner
+	Recall *RecallNotice `json:"recall,omitempty"`
+	// Warranty position from specs.warranty_months and the shipped/installed dates
+	Warranty *WarrantyCoverage `json:"warranty,omitempty"`
+	// Obligations of specs.battery_category: passport, labelling, carbon footprint, collection
+	Obligations *BatteryObligations `json:"obligations,omitempty"`
+	// Third-party supply-chain audit reports. On the public passport, supplier
+	// names here and in specs.due_diligence are withheld.
+	AuditReports []*DueDiligenceDocument `json:"audit_reports,omitempty"`
 	// EU fields from specs are already in BatchSpec
 	// Materials composition is in specs.material_composition
 }
@@ -431,6 +483,7 @@ type ScanEvent struct {
 
 // ScanFeedItem represents a scan event for the live feed
 type ScanFeedItem struct {
+	ID           uuid.UUID `json:"id"`
 	City         string    `json:"city"`
 	Country      string    `json:"country"`
 	DeviceType   string    `json:"device_type"`
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// PLI DVA AUDIT
+// ============================================================================
+
+// Sources of a batch's domestic value addition
+const (
+	DVASourceEstimated = "ESTIMATED" // Calculated from sale price and import cost
+	DVASourceAudited   = "AUDITED"   // Attested by a chartered accountant
+)
+
+// PLIMinimumDVA is the domestic value addition (%) the PLI scheme requires
+const PLIMinimumDVA = 50.0
+
+// PLI audit statuses
+const (
+	PLIAuditStatusPending   = "PENDING"   // Waiting for the CA to attest
+	PLIAuditStatusAttested  = "ATTESTED"  // The CA attested the DVA; the batch is AUDITED
+	PLIAuditStatusCancelled = "CANCELLED" // Withdrawn by the manufacturer
+	PLIAuditStatusExpired   = "EXPIRED"   // The link expired unused
+)
+
+// PLI audit log actions
+const (
+	PLIAuditActionRequested = "REQUESTED"
+	PLIAuditActionCancelled = "CANCELLED"
+	PLIAuditActionAttested  = "ATTESTED"
+)
+
+// PLIAudit is a request to a chartered accountant to attest a batch's DVA.
+// The attestation fields are set once the CA has attested.
+type PLIAudit struct {
+	ID          uuid.UUID `json:"id"`
+	TenantID    uuid.UUID `json:"-"`
+	BatchID     uuid.UUID `json:"batch_id"`
+	CAEmail     string    `json:"ca_email"`
+	CAName      string    `json:"ca_name"`
+	Note        string    `json:"note,omitempty"`
+	Status      string    `json:"status"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	RequestedBy string    `json:"requested_by"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	AuditedDVA          *float64   `json:"audited_dva,omitempty"`      // %, as attested
+	CAMembershipNo      string     `json:"ca_membership_no,omitempty"` // ICAI membership number
+	UDIN                string     `json:"udin,omitempty"`             // ICAI Unique Document Identification Number
+	CertificateFileName string     `json:"certificate_file_name,omitempty"`
+	CertificateSHA256   string     `json:"certificate_sha256,omitempty"` // Of the uploaded signed certificate
+	CertificatePath     string     `json:"-"`
+	AttestedAt          *time.Time `json:"attested_at,omitempty"`
+	ClosedAt            *time.Time `json:"closed_at,omitempty"` // When cancelled or expired
+}
+
+// PLIAuditLogEntry is one step of a batch's audit trail. Entries are never
+// changed or removed.
+type PLIAuditLogEntry struct {
+	ID        uuid.UUID              `json:"id"`
+	AuditID   uuid.UUID              `json:"audit_id"`
+	Action    string                 `json:"action"`
+	Actor     string                 `json:"actor"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// PLIAuditHistory is a batch's DVA attestation state, its audit requests
+// (newest first) and its audit log (oldest first)
+type PLIAuditHistory struct {
+	BatchID    uuid.UUID           `json:"batch_id"`
+	DVASource  string              `json:"dva_source"`
+	AuditedDVA *float64            `json:"audited_dva,omitempty"`
+	Audits     []*PLIAudit         `json:"audits"`
+	Log        []*PLIAuditLogEntry `json:"log"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// QUANTITIES
+// ============================================================================
+
+// Base units quantities are normalised to
+const (
+	UnitVolt     = "V"
+	UnitAmpHour  = "Ah"
+	UnitKilogram = "kg"
+	UnitWattHour = "Wh"
+)
+
+// Quantity is a parsed spec value in its base unit
+type Quantity struct {
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"` // V, Ah, kg or Wh
+}
+
+type unitScale struct {
+	base  string
+	scale float64 // Multiplier to the base unit
+}
+
+// unitScales maps lower-cased unit spellings to their base unit
+var unitScales = map[string]unitScale{
+	"v": {UnitVolt, 1}, "volt": {UnitVolt, 1}, "volts": {UnitVolt, 1},
+	"mv": {UnitVolt, 0.001}, "kv": {UnitVolt, 1000},
+	"ah": {UnitAmpHour, 1}, "mah": {UnitAmpHour, 0.001},
+	"kg": {UnitKilogram, 1}, "kgs": {UnitKilogram, 1}, "kilogram": {UnitKilogram, 1}, "kilograms": {UnitKilogram, 1},
+	"g": {UnitKilogram, 0.001}, "gram": {UnitKilogram, 0.001}, "grams": {UnitKilogram, 0.001},
+	"wh": {UnitWattHour, 1}, "kwh": {UnitWattHour, 1000},
+}
+
+// quantityRegex splits "5000mAh", "3.7 V" or "1,5kg" into number and unit
+var quantityRegex = regexp.MustCompile(`^\s*([0-9]+(?:[.,][0-9]+)?)\s*([a-zA-Z]*)\s*$`)
+
+// thousandsRegex matches a comma thousands separator, as in "1,000mAh"
+var thousandsRegex = regexp.MustCompile(`^[0-9]+,[0-9]{3}$`)
+
+// ParseQuantity parses a spec string into its base unit. A bare number is taken
+// to be in defaultUnit. A comma followed by three digits is a thousands
+// separator ("1,000mAh"); otherwise it is a decimal comma ("1,5kg").
+func ParseQuantity(s, defaultUnit string) (Quantity, error) {
+	m := quantityRegex.FindStringSubmatch(s)
+	if m == nil {
+		return Quantity{}, fmt.Errorf("%q is not a number with a unit", s)
+	}
+
+	number := m[1]
+	if thousandsRegex.MatchString(number) {
+		number = strings.Replace(number, ",", "", 1)
+	} else {
+		number = strings.Replace(number, ",", ".", 1)
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || math.IsInf(value, 0) {
+		return Quantity{}, fmt.Errorf("%q is not a number with a unit", s)
+	}
+
+	if m[2] == "" {
+		return Quantity{Value: value, Unit: defaultUnit}, nil
+	}
+	unit, ok := unitScales[strings.ToLower(m[2])]
+	if !ok {
+		return Quantity{}, fmt.Errorf("unit %q is not supported", m[2])
+	}
+	return Quantity{Value: value * unit.scale, Unit: unit.base}, nil
+}
@@ -0,0 +1,136 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// RECALL CAMPAIGNS
+// ============================================================================
+
+// Recall severities
+const (
+	RecallSeverityLow      = "LOW"
+	RecallSeverityMedium   = "MEDIUM"
+	RecallSeverityHigh     = "HIGH"
+	RecallSeverityCritical = "CRITICAL" // Stop using the battery immediately
+)
+
+// Recall campaign statuses
+const (
+	RecallStatusActive = "ACTIVE" // Public passport pages show the recall banner
+	RecallStatusClosed = "CLOSED"
+)
+
+// Per-unit remedy statuses, in the order a unit normally moves through them
+const (
+	RemedyPending  = "PENDING"  // No owner notified yet (owner unknown or email not sent)
+	RemedyNotified = "NOTIFIED" // Owner emailed
+	RemedyReturned = "RETURNED" // Unit back with the manufacturer
+	RemedyReplaced = "REPLACED" // Owner received a replacement
+	RemedyRecycled = "RECYCLED" // Unit sent for recycling (passport moves to RECYCLED)
+)
+
+// IsValidRecallSeverity reports whether s is a recall severity
+func IsValidRecallSeverity(s string) bool {
+	switch s {
+	case RecallSeverityLow, RecallSeverityMedium, RecallSeverityHigh, RecallSeverityCritical:
+		return true
+	}
+	return false
+}
+
+// IsValidRemedyStatus reports whether s is a status a unit can be set to by hand
+func IsValidRemedyStatus(s string) bool {
+	switch s {
+	case RemedyNotified, RemedyReturned, RemedyReplaced, RemedyRecycled:
+		return true
+	}
+	return false
+}
+
+// IsRecallable reports whether a campaign may recall a passport in this status.
+// A recall overrides the normal lifecycle; only recycled and end-of-life units are out of reach.
+func IsRecallable(status string) bool {
+	return status != PassportStatusRecycled && status != PassportStatusEndOfLife
+}
+
+// RecallScope selects the passports a campaign covers. Criteria combine with AND;
+// at least one is required.
+type RecallScope struct {
+	BatchIDs         []uuid.UUID `json:"batch_ids,omitempty"`
+	SerialFrom       string      `json:"serial_from,omitempty"`       // Inclusive; compared as text, so use same-length serials
+	SerialTo         string      `json:"serial_to,omitempty"`         // Inclusive
+	ManufacturedFrom string      `json:"manufactured_from,omitempty"` // YYYY-MM-DD, inclusive
+	ManufacturedTo   string      `json:"manufactured_to,omitempty"`   // YYYY-MM-DD, inclusive
+	Chemistry        string      `json:"chemistry,omitempty"`         // specs.chemistry, case-insensitive
+}
+
+// IsEmpty reports whether the scope has no criteria (and would match every passport)
+func (s RecallScope) IsEmpty() bool {
+	return len(s.BatchIDs) == 0 && s.SerialFrom == "" && s.SerialTo == "" &&
+		s.ManufacturedFrom == "" && s.ManufacturedTo == "" && s.Chemistry == ""
+}
+
+// RecallCampaign is a manufacturer recall over a set of passports
+type RecallCampaign struct {
+	ID                 uuid.UUID   `json:"id"`
+	TenantID           uuid.UUID   `json:"tenant_id"`
+	Title              string      `json:"title"`
+	Reason             string      `json:"reason"`
+	Severity           string      `json:"severity"`
+	RegulatorReference string      `json:"regulator_reference,omitempty"` // e.g. authority case or notification number
+	RemedyInstructions string      `json:"remedy_instructions,omitempty"` // Shown to owners on the passport page and in the email
+	Scope              RecallScope `json:"scope"`
+	Status             string      `json:"status"`
+	AffectedCount      int         `json:"affected_count"`
+	CreatedBy          string      `json:"created_by"`
+	CreatedAt          time.Time   `json:"created_at"`
+	ClosedAt           *time.Time  `json:"closed_at,omitempty"`
+}
+
+// RecallUnit is one passport covered by a campaign
+type RecallUnit struct {
+	PassportID     uuid.UUID  `json:"passport_id"`
+	SerialNumber   string     `json:"serial_number"`
+	BatchID        uuid.UUID  `json:"batch_id"`
+	PassportStatus string     `json:"passport_status"`
+	PreviousStatus string     `json:"previous_status"` // Passport status before the recall
+	OwnerEmail     string     `json:"owner_email,omitempty"`
+	RemedyStatus   string     `json:"remedy_status"`
+	NotifiedAt     *time.Time `json:"notified_at,omitempty"`
+	RemediedAt     *time.Time `json:"remedied_at,omitempty"`
+	Note           string     `json:"note,omitempty"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// RecallProgress summarizes remedy status across a campaign's units
+type RecallProgress struct {
+	Total           int            `json:"total"`
+	ByRemedyStatus  map[string]int `json:"by_remedy_status"`
+	OwnersKnown     int            `json:"owners_known"` // Units with an owner email
+	Notified        int            `json:"notified"`     // Units whose owner was emailed
+	Resolved        int            `json:"resolved"`     // Returned, replaced or recycled
+	ResolvedPercent float64        `json:"resolved_percent"`
+}
+
+// RecallPreview is what creating a campaign with a scope would affect
+type RecallPreview struct {
+	Affected    int            `json:"affected"`     // Passports the campaign would cover
+	ByStatus    map[string]int `json:"by_status"`    // Current status of those passports
+	OwnersKnown int            `json:"owners_known"` // Of those, with an owner email
+	Excluded    int            `json:"excluded"`     // Matching passports already recycled or end of life
+}
+
+// RecallNotice is the recall banner on a public passport page
+type RecallNotice struct {
+	CampaignID         uuid.UUID `json:"campaign_id"`
+	Title              string    `json:"title"`
+	Severity           string    `json:"severity"`
+	Reason             string    `json:"reason"`
+	RemedyInstructions string    `json:"remedy_instructions,omitempty"`
+	RegulatorReference string    `json:"regulator_reference,omitempty"`
+	IssuedAt           time.Time `json:"issued_at"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// PASSPORT SEARCH
+// ============================================================================
+
+// Searchable fields reported in PassportSearchResult.MatchedFields
+const (
+	SearchFieldSerialNumber = "serial_number" // Also covers India BPANs
+	SearchFieldOwnerEmail   = "owner_email"   // Current custodian (installer, technician, recycler)
+	SearchFieldBatchName    = "batch_name"
+	SearchFieldBillOfEntry  = "bill_of_entry_no"
+	SearchFieldHSNCode      = "hsn_code"
+	SearchFieldSpecs        = "specs" // Any value in the batch specs (manufacturer, chemistry, ...)
+)
+
+// PassportSearchResult is one passport hit with the batch fields support staff need to identify it
+type PassportSearchResult struct {
+	UUID          uuid.UUID    `json:"uuid"`
+	SerialNumber  string       `json:"serial_number"`
+	Status        string       `json:"status"`
+	CreatedAt     time.Time    `json:"created_at"`
+	OwnerEmail    string       `json:"owner_email,omitempty"`
+	BatchID       uuid.UUID    `json:"batch_id"`
+	BatchName     string       `json:"batch_name"`
+	MarketRegion  MarketRegion `json:"market_region"`
+	Chemistry     string       `json:"chemistry,omitempty"`
+	BillOfEntryNo string       `json:"bill_of_entry_no,omitempty"`
+	HSNCode       string       `json:"hsn_code,omitempty"`
+	Score         float64      `json:"score"`          // Relevance; exact identifier matches rank first
+	MatchedFields []string     `json:"matched_fields"` // Which SearchField* values contain the query
+}
+
+// FacetCount is the number of matching passports for one facet value
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"` // Display name where the value is an ID (batch facet)
+	Count int    `json:"count"`
+}
+
+// SearchFacets breaks the full match set down for drill-down filters
+type SearchFacets struct {
+	Status       []FacetCount `json:"status"`
+	MarketRegion []FacetCount `json:"market_region"`
+	Chemistry    []FacetCount `json:"chemistry"`
+	Batch        []FacetCount `json:"batch"` // Top batches by match count
+}
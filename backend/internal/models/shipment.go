@@ -0,0 +1,150 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// EXPORT SHIPMENTS
+// ============================================================================
+
+// Shipment statuses
+const (
+	ShipmentStatusDraft     = "DRAFT"     // Passports reserved; documents can be generated
+	ShipmentStatusShipped   = "SHIPPED"   // Passports moved to SHIPPED
+	ShipmentStatusCancelled = "CANCELLED" // Passports released
+)
+
+// How the batteries travel, which decides the UN number
+const (
+	PackingBatteriesOnly        = "BATTERIES_ONLY"         // UN3480
+	PackingPackedWithEquipment  = "PACKED_WITH_EQUIPMENT"  // UN3481
+	PackingContainedInEquipment = "CONTAINED_IN_EQUIPMENT" // UN3481
+)
+
+// IsValidPacking reports whether p is a shipment packing
+func IsValidPacking(p string) bool {
+	switch p {
+	case PackingBatteriesOnly, PackingPackedWithEquipment, PackingContainedInEquipment:
+		return true
+	}
+	return false
+}
+
+// IsShippable reports whether a passport in this status can move to SHIPPED
+func IsShippable(status string) bool {
+	return IsValidTransition(status, PassportStatusShipped)
+}
+
+// ShippableStatuses lists the passport statuses that can move to SHIPPED
+func ShippableStatuses() []string {
+	var statuses []string
+	for status := range ValidPassportTransitions {
+		if IsShippable(status) {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Strings(statuses)
+	return statuses
+}
+
+// DangerousGoods is the transport classification of a battery
+type DangerousGoods struct {
+	UNNumber           string `json:"un_number"` // e.g. UN3480
+	ProperShippingName string `json:"proper_shipping_name"`
+	Class              string `json:"class"` // Hazard class, 9 for lithium batteries
+}
+
+// ClassifyDangerousGoods returns the UN classification of a lithium-ion
+// battery for the packing, or nil if the chemistry is not a lithium-ion family
+func ClassifyDangerousGoods(chemistry, packing string) *DangerousGoods {
+	switch ChemistryFamily(chemistry) {
+	case ChemistryLFP, ChemistryNMC, ChemistryNCA, ChemistryLCO:
+	default:
+		return nil
+	}
+	switch packing {
+	case PackingPackedWithEquipment:
+		return &DangerousGoods{UNNumber: "UN3481", ProperShippingName: "Lithium ion batteries packed with equipment", Class: "9"}
+	case PackingContainedInEquipment:
+		return &DangerousGoods{UNNumber: "UN3481", ProperShippingName: "Lithium ion batteries contained in equipment", Class: "9"}
+	}
+	return &DangerousGoods{UNNumber: "UN3480", ProperShippingName: "Lithium ion batteries", Class: "9"}
+}
+
+// ValidateTransport normalises specs.un38_3_test_summary
+func ValidateTransport(spec *BatchSpec) []SpecIssue {
+	spec.UN383TestSummary = strings.TrimSpace(spec.UN383TestSummary)
+	if len(spec.UN383TestSummary) > 255 {
+		return []SpecIssue{{Field: "un38_3_test_summary", Severity: SpecIssueError,
+			Message: "must be at most 255 characters"}}
+	}
+	return nil
+}
+
+// Shipment is an export consignment of passports
+type Shipment struct {
+	ID                 uuid.UUID             `json:"id"`
+	TenantID           uuid.UUID             `json:"-"`
+	Reference          string                `json:"reference"`
+	Status             string                `json:"status"`
+	DestinationCountry string                `json:"destination_country"`
+	ConsigneeName      string                `json:"consignee_name"`
+	ConsigneeAddress   string                `json:"consignee_address,omitempty"`
+	InvoiceNumber      string                `json:"invoice_number,omitempty"`
+	InvoiceDate        string                `json:"invoice_date,omitempty"` // YYYY-MM-DD
+	Incoterm           string                `json:"incoterm,omitempty"`
+	Currency           string                `json:"currency"`
+	UnitValues         map[uuid.UUID]float64 `json:"unit_values,omitempty"` // Invoice value per unit, by batch ID
+	Carrier            string                `json:"carrier,omitempty"`
+	PortOfLoading      string                `json:"port_of_loading,omitempty"`
+	PortOfDischarge    string                `json:"port_of_discharge,omitempty"`
+	Packing            string                `json:"packing"`
+	Note               string                `json:"note,omitempty"`
+	PassportCount      int                   `json:"passport_count"`
+	CreatedBy          string                `json:"created_by"`
+	CreatedAt          time.Time             `json:"created_at"`
+	ShippedBy          string                `json:"shipped_by,omitempty"`
+	ShippedAt          *time.Time            `json:"shipped_at,omitempty"`
+	CancelledAt        *time.Time            `json:"cancelled_at,omitempty"`
+}
+
+// ShipmentLine is one batch of a shipment, as it appears on the invoice and
+// packing list
+type ShipmentLine struct {
+	BatchID          uuid.UUID       `json:"batch_id"`
+	BatchName        string          `json:"batch_name"`
+	Chemistry        string          `json:"chemistry"`
+	Capacity         string          `json:"capacity,omitempty"`
+	NominalVoltage   string          `json:"voltage,omitempty"`
+	HSNCode          string          `json:"hsn_code,omitempty"`
+	CountryOfOrigin  string          `json:"country_of_origin,omitempty"`
+	Quantity         int             `json:"quantity"`
+	UnitValue        float64         `json:"unit_value"`
+	TotalValue       float64         `json:"total_value"`
+	UnitWeightKg     float64         `json:"unit_weight_kg,omitempty"`
+	NetWeightKg      float64         `json:"net_weight_kg,omitempty"`
+	RatedEnergyWh    float64         `json:"rated_energy_wh,omitempty"` // Per battery
+	DangerousGoods   *DangerousGoods `json:"dangerous_goods,omitempty"`
+	UN383TestSummary string          `json:"un38_3_test_summary,omitempty"`
+}
+
+// ShipmentItem is a passport in a shipment
+type ShipmentItem struct {
+	PassportID   uuid.UUID `json:"passport_id"`
+	SerialNumber string    `json:"serial_number"`
+	BatchID      uuid.UUID `json:"batch_id"`
+	Status       string    `json:"status"`
+}
+
+// ShipmentDetail is a shipment with its invoice lines and the gaps that would
+// leave its customs documents incomplete
+type ShipmentDetail struct {
+	Shipment *Shipment      `json:"shipment"`
+	Lines    []ShipmentLine `json:"lines"`
+	Warnings []string       `json:"warnings,omitempty"`
+}
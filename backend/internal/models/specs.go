@@ -0,0 +1,222 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ============================================================================
+// SPEC VALIDATION
+// ============================================================================
+
+// Spec issue severities
+const (
+	SpecIssueError   = "ERROR"   // The batch or template is not saved
+	SpecIssueWarning = "WARNING" // Saved, and returned alongside the result
+)
+
+// SpecIssue is a problem with one field of a BatchSpec
+type SpecIssue struct {
+	Field    string `json:"field"` // JSON name in specs, e.g. voltage
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// SplitSpecIssues separates errors from warnings
+func SplitSpecIssues(issues []SpecIssue) (errs, warnings []SpecIssue) {
+	for _, issue := range issues {
+		if issue.Severity == SpecIssueError {
+			errs = append(errs, issue)
+		} else {
+			warnings = append(warnings, issue)
+		}
+	}
+	return errs, warnings
+}
+
+// Chemistry families BatchSpec.Chemistry is matched to
+const (
+	ChemistryLFP      = "LFP"
+	ChemistryNMC      = "NMC"
+	ChemistryNCA      = "NCA"
+	ChemistryLCO      = "LCO"
+	ChemistryLeadAcid = "LEAD_ACID"
+	ChemistryNiMH     = "NIMH"
+)
+
+// chemistryAliases are checked in order against the upper-cased chemistry with
+// spaces, hyphens and underscores removed, so "Li-ion NMC" is NMC
+var chemistryAliases = []struct {
+	family  string
+	aliases []string
+}{
+	{ChemistryLFP, []string{"LFP", "LIFEPO4", "LITHIUMIRONPHOSPHATE"}},
+	{ChemistryNMC, []string{"NMC", "NCM", "NICKELMANGANESECOBALT"}},
+	{ChemistryNCA, []string{"NCA", "NICKELCOBALTALUMINIUM", "NICKELCOBALTALUMINUM"}},
+	{ChemistryLCO, []string{"LCO", "LICOO2", "LITHIUMCOBALTOXIDE"}},
+	{ChemistryLeadAcid, []string{"LEADACID", "PBACID", "VRLA", "AGM"}},
+	{ChemistryNiMH, []string{"NIMH", "NICKELMETALHYDRIDE"}},
+}
+
+// ChemistryFamily returns the family of a free-text chemistry, or "" if unknown
+func ChemistryFamily(chemistry string) string {
+	key := strings.NewReplacer(" ", "", "-", "", "_", "", "/", "").Replace(strings.ToUpper(chemistry))
+	if key == "" {
+		return ""
+	}
+	for _, c := range chemistryAliases {
+		for _, alias := range c.aliases {
+			if strings.Contains(key, alias) {
+				return c.family
+			}
+		}
+	}
+	return ""
+}
+
+// chemistryLimits are plausible ranges for a chemistry. Specific energy is at
+// pack level, so the lower bound allows for housing and BMS weight.
+type chemistryLimits struct {
+	cellVoltageMin, cellVoltageMax       float64 // Nominal cell voltage (V)
+	specificEnergyMin, specificEnergyMax float64 // Wh/kg
+}
+
+var chemistryRanges = map[string]chemistryLimits{
+	ChemistryLFP:      {3.0, 3.4, 50, 210},
+	ChemistryNMC:      {3.5, 3.8, 60, 300},
+	ChemistryNCA:      {3.5, 3.7, 80, 320},
+	ChemistryLCO:      {3.6, 3.9, 60, 280},
+	ChemistryLeadAcid: {1.9, 2.2, 15, 55},
+	ChemistryNiMH:     {1.15, 1.3, 25, 120},
+}
+
+// Bounds for any chemistry
+const (
+	maxNominalVoltage = 1500    // V; above the high-voltage range of EV and stationary packs
+	maxRatedEnergy    = 5000000 // Wh; a containerised storage system
+	maxBatteryWeight  = 50000   // kg
+)
+
+// BatchRatings are the spec quantities in base units, with derived values
+type BatchRatings struct {
+	NominalVoltageV    float64 `json:"nominal_voltage_v,omitempty"`
+	CapacityAh         float64 `json:"capacity_ah,omitempty"`
+	WeightKg           float64 `json:"weight_kg,omitempty"`
+	RatedEnergyWh      float64 `json:"rated_energy_wh,omitempty"`       // Capacity x voltage, or capacity given in Wh/kWh
+	SpecificEnergyWhKg float64 `json:"specific_energy_wh_kg,omitempty"` // Rated energy / weight
+}
+
+// ParseBatchRatings parses voltage, capacity and weight. Capacity may be charge
+// (mAh, Ah; Ah for a bare number) or energy (Wh, kWh), in which case the charge is
+// derived from the voltage. Empty fields are skipped; unparseable or out-of-range
+// values are errors.
+func ParseBatchRatings(spec BatchSpec) (*BatchRatings, []SpecIssue) {
+	ratings := &BatchRatings{}
+	var issues []SpecIssue
+	fail := func(field, format string, args ...interface{}) {
+		issues = append(issues, SpecIssue{Field: field, Severity: SpecIssueError, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(spec.NominalVoltage) != "" {
+		q, err := ParseQuantity(spec.NominalVoltage, UnitVolt)
+		switch {
+		case err != nil:
+			fail("voltage", "%v; use e.g. 3.7V or 51.2V", err)
+		case q.Unit != UnitVolt:
+			fail("voltage", "expected a voltage such as 3.7V, got %s", q.Unit)
+		case q.Value <= 0 || q.Value > maxNominalVoltage:
+			fail("voltage", "must be above 0 and at most %dV", maxNominalVoltage)
+		default:
+			ratings.NominalVoltageV = q.Value
+		}
+	}
+
+	if strings.TrimSpace(spec.Capacity) != "" {
+		q, err := ParseQuantity(spec.Capacity, UnitAmpHour)
+		switch {
+		case err != nil:
+			fail("capacity", "%v; use e.g. 5000mAh, 100Ah or 5kWh", err)
+		case q.Unit != UnitAmpHour && q.Unit != UnitWattHour:
+			fail("capacity", "expected a charge (mAh, Ah) or energy (Wh, kWh), got %s", q.Unit)
+		case q.Value <= 0:
+			fail("capacity", "must be above 0")
+		case q.Unit == UnitAmpHour:
+			ratings.CapacityAh = q.Value
+			if ratings.NominalVoltageV > 0 {
+				ratings.RatedEnergyWh = q.Value * ratings.NominalVoltageV
+			}
+		default:
+			ratings.RatedEnergyWh = q.Value
+			if ratings.NominalVoltageV > 0 {
+				ratings.CapacityAh = q.Value / ratings.NominalVoltageV
+			}
+		}
+	}
+	if ratings.RatedEnergyWh > maxRatedEnergy {
+		fail("capacity", "rated energy %.0fWh is above the %dWh limit", ratings.RatedEnergyWh, maxRatedEnergy)
+		ratings.RatedEnergyWh = 0
+	}
+
+	if strings.TrimSpace(spec.Weight) != "" {
+		q, err := ParseQuantity(spec.Weight, UnitKilogram)
+		switch {
+		case err != nil:
+			fail("weight", "%v; use e.g. 500g or 12kg", err)
+		case q.Unit != UnitKilogram:
+			fail("weight", "expected a mass such as 500g or 12kg, got %s", q.Unit)
+		case q.Value <= 0 || q.Value > maxBatteryWeight:
+			fail("weight", "must be above 0 and at most %dkg", maxBatteryWeight)
+		default:
+			ratings.WeightKg = q.Value
+		}
+	}
+
+	if ratings.RatedEnergyWh > 0 && ratings.WeightKg > 0 {
+		ratings.SpecificEnergyWhKg = ratings.RatedEnergyWh / ratings.WeightKg
+	}
+
+	ratings.CapacityAh = roundTo(ratings.CapacityAh, 4)
+	ratings.WeightKg = roundTo(ratings.WeightKg, 4)
+	ratings.RatedEnergyWh = roundTo(ratings.RatedEnergyWh, 2)
+	ratings.SpecificEnergyWhKg = roundTo(ratings.SpecificEnergyWhKg, 1)
+	return ratings, issues
+}
+
+// NormalizeBatchSpec sets spec.Ratings from the quantity strings and checks them
+// against the ranges of the spec's chemistry. Client-supplied ratings are replaced.
+func NormalizeBatchSpec(spec *BatchSpec) []SpecIssue {
+	ratings, issues := ParseBatchRatings(*spec)
+	spec.Ratings = ratings
+	if *ratings == (BatchRatings{}) {
+		spec.Ratings = nil
+	}
+
+	if ratings.RatedEnergyWh == 0 && len(issues) == 0 {
+		issues = append(issues, SpecIssue{Field: "capacity", Severity: SpecIssueWarning,
+			Message: "voltage and capacity are needed to derive rated energy"})
+	}
+
+	family := ChemistryFamily(spec.Chemistry)
+	limits, ok := chemistryRanges[family]
+	if !ok {
+		return issues
+	}
+
+	if v := ratings.NominalVoltageV; v > 0 {
+		// The smallest series count that keeps cells at or below their maximum
+		// voltage gives the highest per-cell voltage; below the minimum, no count fits
+		cells := math.Ceil(v/limits.cellVoltageMax - 1e-9)
+		if v/cells < limits.cellVoltageMin-1e-9 {
+			issues = append(issues, SpecIssue{Field: "voltage", Severity: SpecIssueError,
+				Message: fmt.Sprintf("%gV is not a series multiple of a %s cell (%g-%gV nominal)",
+					v, family, limits.cellVoltageMin, limits.cellVoltageMax)})
+		}
+	}
+	if e := ratings.SpecificEnergyWhKg; e > 0 && (e < limits.specificEnergyMin || e > limits.specificEnergyMax) {
+		issues = append(issues, SpecIssue{Field: "weight", Severity: SpecIssueError,
+			Message: fmt.Sprintf("specific energy %gWh/kg is outside the %g-%gWh/kg plausible for %s; check capacity, voltage and weight",
+				e, limits.specificEnergyMin, limits.specificEnergyMax, family)})
+	}
+	return issues
+}
@@ -0,0 +1,154 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// WARRANTY
+// ============================================================================
+
+// Warranty start basis: installation when known, otherwise shipment
+const (
+	WarrantyStartInstalled = "INSTALLED"
+	WarrantyStartShipped   = "SHIPPED"
+)
+
+// WarrantyCoverage is a passport's warranty position at a point in time
+type WarrantyCoverage struct {
+	Months          int        `json:"months"`                // Batch warranty_months
+	StartBasis      string     `json:"start_basis,omitempty"` // INSTALLED or SHIPPED
+	StartsAt        *time.Time `json:"starts_at,omitempty"`   // Installation or shipment time
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`  // StartsAt plus Months calendar months
+	Active          bool       `json:"active"`                // Within the warranty period
+	RemainingMonths int        `json:"remaining_months"`      // Whole calendar months left
+	RemainingDays   int        `json:"remaining_days"`        // Days left (including the partial month)
+	Reason          string     `json:"reason,omitempty"`      // Why there is no active warranty
+}
+
+// AddCalendarMonths adds months to t, keeping the day of month where it exists
+// and clamping to the month's last day otherwise (Jan 31 + 1 month = Feb 28/29)
+func AddCalendarMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	firstOfTarget := time.Date(year, month+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	lastDay := firstOfTarget.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	hour, min, sec := t.Clock()
+	return time.Date(firstOfTarget.Year(), firstOfTarget.Month(), day, hour, min, sec, t.Nanosecond(), t.Location())
+}
+
+// calendarMonthsBetween counts the whole calendar months from a to b (a <= b)
+func calendarMonthsBetween(a, b time.Time) int {
+	months := (b.Year()-a.Year())*12 + int(b.Month()-a.Month())
+	for months > 0 && AddCalendarMonths(a, months).After(b) {
+		months--
+	}
+	return months
+}
+
+// ComputeWarranty works out a passport's warranty at now from the batch warranty
+// period. The period runs from installation, or from shipment if the battery was
+// never registered as installed, for whole calendar months.
+func ComputeWarranty(p *Passport, warrantyMonths int, now time.Time) WarrantyCoverage {
+	w := WarrantyCoverage{Months: warrantyMonths}
+
+	switch {
+	case p.InstalledAt != nil:
+		w.StartBasis, w.StartsAt = WarrantyStartInstalled, p.InstalledAt
+	case p.ShippedAt != nil:
+		w.StartBasis, w.StartsAt = WarrantyStartShipped, p.ShippedAt
+	}
+
+	if warrantyMonths <= 0 {
+		w.Reason = "No warranty period is set for this battery"
+		return w
+	}
+	if w.StartsAt == nil {
+		w.Reason = "Warranty starts when the battery is shipped or installed"
+		return w
+	}
+
+	expires := AddCalendarMonths(*w.StartsAt, warrantyMonths)
+	w.ExpiresAt = &expires
+	if !now.Before(expires) {
+		w.Reason = "Warranty expired"
+		return w
+	}
+
+	w.Active = true
+	w.RemainingMonths = calendarMonthsBetween(now, expires)
+	w.RemainingDays = int(expires.Sub(now).Hours() / 24)
+	return w
+}
+
+// ============================================================================
+// WARRANTY CLAIMS
+// ============================================================================
+
+// Warranty claim statuses
+const (
+	ClaimStatusSubmitted = "SUBMITTED" // Passport is RETURN_REQUESTED, awaiting the manufacturer
+	ClaimStatusApproved  = "APPROVED"  // RMA issued, waiting for the unit
+	ClaimStatusRejected  = "REJECTED"  // Passport back IN_SERVICE
+	ClaimStatusReceived  = "RECEIVED"  // Unit received against the RMA, passport RETURNED
+)
+
+// Fault codes a technician can report
+const (
+	FaultCapacityFade   = "CAPACITY_FADE"
+	FaultCellImbalance  = "CELL_IMBALANCE"
+	FaultBMS            = "BMS_FAULT"
+	FaultNoPower        = "NO_POWER"
+	FaultThermal        = "THERMAL_EVENT"
+	FaultSwelling       = "SWELLING"
+	FaultConnector      = "CONNECTOR_FAULT"
+	FaultPhysicalDamage = "PHYSICAL_DAMAGE"
+	FaultWaterIngress   = "WATER_INGRESS"
+	FaultOther          = "OTHER"
+)
+
+// FaultCodes lists the accepted fault codes
+var FaultCodes = []string{
+	FaultCapacityFade, FaultCellImbalance, FaultBMS, FaultNoPower, FaultThermal,
+	FaultSwelling, FaultConnector, FaultPhysicalDamage, FaultWaterIngress, FaultOther,
+}
+
+// IsValidFaultCode reports whether code is an accepted fault code
+func IsValidFaultCode(code string) bool {
+	for _, c := range FaultCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// WarrantyClaim is a technician's warranty claim on a passport
+type WarrantyClaim struct {
+	ID                uuid.UUID  `json:"id"`
+	TenantID          uuid.UUID  `json:"tenant_id"`
+	PassportID        uuid.UUID  `json:"passport_id"`
+	SerialNumber      string     `json:"serial_number"`
+	Status            string     `json:"status"`
+	RMANumber         string     `json:"rma_number,omitempty"` // Issued on approval
+	FaultCode         string     `json:"fault_code"`
+	Description       string     `json:"description,omitempty"`
+	StateOfHealth     float64    `json:"state_of_health"` // SoH reading at submission, literal percentage
+	Photos            []string   `json:"photos"`          // Stored photo file names, served by the photos endpoint
+	PreviousStatus    string     `json:"previous_status"` // Passport status before the claim
+	Eligible          bool       `json:"eligible"`        // In warranty at submission
+	EligibilityReason string     `json:"eligibility_reason,omitempty"`
+	WarrantyExpiresAt *time.Time `json:"warranty_expires_at,omitempty"`
+	SubmittedBy       string     `json:"submitted_by"`
+	SubmittedRole     string     `json:"submitted_role"`
+	DecidedBy         string     `json:"decided_by,omitempty"`
+	DecidedAt         *time.Time `json:"decided_at,omitempty"`
+	DecisionNote      string     `json:"decision_note,omitempty"`
+	ReceivedAt        *time.Time `json:"received_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,323 @@
+// Package openapi describes the HTTP API as an OpenAPI 3.1 document.
+//
+// The document is built from the route table in routes.go and from the Go
+// request/response types themselves, so struct changes flow into the spec
+// automatically. cmd/contract checks the table against the routes registered
+// in cmd/server/main.go and validates live responses against it.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ============================================================================
+// DOCUMENT MODEL
+// ============================================================================
+
+// Document is an OpenAPI 3.1 document
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers"`
+	Tags       []Tag                `json:"tags"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+}
+
+// Server is a base URL the API is served from
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag groups operations
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations for one path, keyed by lowercase HTTP method
+type PathItem map[string]*Operation
+
+// Operation is a single method + path
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security"`
+}
+
+// Parameter is a path, query or header parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is the payload of an operation
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response is one documented status code
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType wraps a schema for a content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds reusable schemas and security schemes
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme describes an authentication method
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// Security scheme names
+const (
+	SecurityJWT       = "bearerAuth"
+	SecurityAPIKey    = "apiKeyAuth"
+	SecurityMagicLink = "magicLinkAuth"
+)
+
+const (
+	contentJSON      = "application/json"
+	contentMultipart = "multipart/form-data"
+)
+
+// ============================================================================
+// BUILD & SERVE
+// ============================================================================
+
+var (
+	buildOnce sync.Once
+	built     *Document
+	builtJSON []byte
+)
+
+// Spec returns the API document. It is built once and must not be modified.
+func Spec() *Document {
+	buildOnce.Do(func() {
+		built = build()
+		data, err := json.MarshalIndent(built, "", "  ")
+		if err != nil {
+			panic("openapi: failed to encode document: " + err.Error())
+		}
+		builtJSON = data
+	})
+	return built
+}
+
+// JSON returns the encoded API document
+func JSON() []byte {
+	Spec()
+	return builtJSON
+}
+
+// Handler serves the document at GET /api/v1/openapi.json
+func Handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentJSON)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	w.Write(JSON())
+}
+
+// Patterns returns every ServeMux pattern the document covers, e.g. "GET /api/v1/batches/{id}"
+func Patterns() []string {
+	patterns := make([]string, 0, len(routes()))
+	for _, rt := range routes() {
+		patterns = append(patterns, rt.Pattern)
+	}
+	sort.Strings(patterns)
+	return patterns
+}
+
+var pathParamRe = regexp.MustCompile(`\{([a-zA-Z_]+)\}`)
+
+// muxPathToOpenAPI converts a ServeMux path to an OpenAPI path.
+// Subtree patterns ("/uploads/") become a trailing {path} parameter.
+func muxPathToOpenAPI(path string) string {
+	if strings.HasSuffix(path, "/") && path != "/" {
+		return path + "{path}"
+	}
+	return path
+}
+
+func build() *Document {
+	reg := newSchemaRegistry()
+	// Some errors carry details, e.g. row errors or duplicate serials, so extra keys are allowed
+	errorSchema := object(prop("error", str()))
+	errorSchema.AdditionalProperties = nil
+	reg.schemas["Error"] = errorSchema
+
+	doc := &Document{
+		OpenAPI: "3.1.0",
+		Info: Info{
+			Title:   "ExportReady Battery API",
+			Version: "1.0.0",
+			Description: "Digital battery passports for EU Battery Regulation 2023/1542 and India BWM Rules 2022. " +
+				"Dashboard routes use a JWT from /api/v1/auth/login, ERP integrations use an X-API-Key " +
+				"under /api/v1/external, and field partners use a magic link token.",
+		},
+		Servers: []Server{{URL: "/", Description: "Current host"}},
+		Tags:    tags,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas: reg.schemas,
+			SecuritySchemes: map[string]*SecurityScheme{
+				SecurityJWT: {
+					Type: "http", Scheme: "bearer", BearerFormat: "JWT",
+					Description: "Access token from /api/v1/auth/login or /api/v1/auth/register",
+				},
+				SecurityAPIKey: {
+					Type: "apiKey", In: "header", Name: "X-API-Key",
+					Description: "Tenant API key. Required scopes are listed per operation.",
+				},
+				SecurityMagicLink: {
+					Type: "http", Scheme: "bearer", BearerFormat: "magic-link JWT",
+					Description: "Token from the link sent by /api/v1/auth/magic-link. Also accepted as ?token=.",
+				},
+			},
+		},
+	}
+
+	errorRef := &Schema{Ref: "#/components/schemas/Error"}
+	for _, rt := range routes() {
+		method, path, _ := strings.Cut(rt.Pattern, " ")
+		path = muxPathToOpenAPI(path)
+
+		op := &Operation{
+			OperationID: rt.ID,
+			Summary:     rt.Summary,
+			Description: rt.Description,
+			Tags:        []string{rt.Tag},
+			Responses:   map[string]*Response{},
+			Security:    []map[string][]string{},
+		}
+
+		for _, m := range pathParamRe.FindAllStringSubmatch(path, -1) {
+			op.Parameters = append(op.Parameters, &Parameter{Name: m[1], In: "path", Required: true, Schema: str()})
+		}
+		op.Parameters = append(op.Parameters, rt.Query...)
+		if rt.idempotent() {
+			op.Parameters = append(op.Parameters, idempotencyKeyParam)
+		}
+
+		switch rt.Auth {
+		case authJWT:
+			op.Security = append(op.Security, map[string][]string{SecurityJWT: {}})
+		case authAPIKey:
+			op.Security = append(op.Security, map[string][]string{SecurityAPIKey: {rt.Scope}})
+		case authMagicLink:
+			op.Security = append(op.Security, map[string][]string{SecurityMagicLink: {}})
+		}
+
+		if rt.Body != nil {
+			op.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{
+				contentJSON: {Schema: resolve(reg, rt.Body)},
+			}}
+		}
+		if rt.Multipart != nil {
+			if op.RequestBody == nil {
+				op.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{}}
+			}
+			op.RequestBody.Content[contentMultipart] = &MediaType{Schema: rt.Multipart}
+		}
+
+		for status, res := range rt.Responses {
+			response := &Response{Description: res.Description}
+			if res.Description == "" {
+				response.Description = http.StatusText(status)
+			}
+			switch {
+			case res.ContentType != "":
+				response.Content = map[string]*MediaType{res.ContentType: {Schema: &Schema{Type: "string", Format: "binary"}}}
+			case res.Schema != nil:
+				response.Content = map[string]*MediaType{contentJSON: {Schema: resolve(reg, res.Schema)}}
+			}
+			op.Responses[strconv.Itoa(status)] = response
+		}
+
+		// Every route can fail; all errors share one shape
+		for _, status := range rt.errorStatuses() {
+			key := strconv.Itoa(status)
+			if _, documented := op.Responses[key]; !documented {
+				op.Responses[key] = &Response{
+					Description: http.StatusText(status),
+					Content:     map[string]*MediaType{contentJSON: {Schema: errorRef}},
+				}
+			}
+		}
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		(*item)[strings.ToLower(method)] = op
+	}
+
+	return doc
+}
+
+// resolve turns a Go value into a component reference, or passes a *Schema through
+func resolve(reg *schemaRegistry, v interface{}) *Schema {
+	if s, ok := v.(*Schema); ok {
+		return s.resolveTypes(reg)
+	}
+	return reg.ref(v)
+}
+
+// resolveTypes replaces Go type placeholders (see typeOf) nested inside an inline schema
+func (s *Schema) resolveTypes(reg *schemaRegistry) *Schema {
+	if s == nil {
+		return nil
+	}
+	if s.goType != nil {
+		return reg.ref(s.goType)
+	}
+	for name, p := range s.Properties {
+		s.Properties[name] = p.resolveTypes(reg)
+	}
+	s.Items = s.Items.resolveTypes(reg)
+	for i, a := range s.AnyOf {
+		s.AnyOf[i] = a.resolveTypes(reg)
+	}
+	if ap, ok := s.AdditionalProperties.(*Schema); ok {
+		s.AdditionalProperties = ap.resolveTypes(reg)
+	}
+	return s
+}
@@ -0,0 +1,1548 @@
+package openapi
+
+import (
+	"net/http"
+	"strings"
+
+	"exportready-battery/internal/graph"
+	"exportready-battery/internal/handlers"
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+	"exportready-battery/internal/services"
+)
+
+// ============================================================================
+// ROUTE TABLE
+// ============================================================================
+// Keep in sync with cmd/server/main.go. `go run ./cmd/contract -static`
+// fails when a registered route is missing here or vice versa.
+
+type authKind int
+
+const (
+	authNone authKind = iota
+	authJWT
+	authAPIKey
+	authMagicLink
+)
+
+// route documents one ServeMux pattern
+type route struct {
+	Pattern     string // Exactly as registered, e.g. "GET /api/v1/batches/{id}"
+	ID          string // operationId
+	Tag         string
+	Summary     string
+	Description string
+	Auth        authKind
+	Scope       string // Required API key scope
+	Query       []*Parameter
+	Body        interface{} // Go request type or *Schema
+	Multipart   *Schema
+	Responses   map[int]response
+	Errors      []int // Route-specific error statuses beyond the defaults
+	RateLimited bool  // Wrapped in a brute-force limiter
+	Idempotent  bool  // Wrapped in the Idempotency-Key middleware
+}
+
+// response is a documented success response
+type response struct {
+	Description string
+	Schema      interface{} // Go response type or *Schema
+	ContentType string      // Binary download content type
+}
+
+// errorStatuses returns the documented error codes for a route
+func (rt route) errorStatuses() []int {
+	statuses := []int{http.StatusBadRequest, http.StatusInternalServerError}
+	if strings.Contains(rt.Pattern, "{") || strings.HasSuffix(rt.Pattern, "/") {
+		statuses = append(statuses, http.StatusNotFound)
+	}
+	switch rt.Auth {
+	case authJWT, authMagicLink:
+		statuses = append(statuses, http.StatusUnauthorized, http.StatusForbidden)
+	case authAPIKey:
+		statuses = append(statuses, http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests)
+		if rt.idempotent() {
+			// Key still in flight, or reused with a different request
+			statuses = append(statuses, http.StatusConflict, http.StatusUnprocessableEntity)
+		}
+	}
+	if rt.RateLimited {
+		statuses = append(statuses, http.StatusTooManyRequests)
+	}
+	return append(statuses, rt.Errors...)
+}
+
+// idempotent reports whether the route accepts an Idempotency-Key header
+func (rt route) idempotent() bool {
+	return rt.Idempotent
+}
+
+// idempotencyKeyParam documents the retry header accepted by mutating external POSTs
+var idempotencyKeyParam = &Parameter{
+	Name: "Idempotency-Key", In: "header",
+	Description: "Client-generated key (max 255 chars). A retry with the same key and payload within 24 hours " +
+		"replays the original response with Idempotent-Replayed: true; the same key with a different payload is rejected with 422.",
+	Schema: str(),
+}
+
+func ok(v interface{}) map[int]response {
+	return map[int]response{http.StatusOK: {Schema: v}}
+}
+
+func created(v interface{}) map[int]response {
+	return map[int]response{http.StatusCreated: {Schema: v}}
+}
+
+// download is a binary response such as a PDF or ZIP
+func download(contentType, description string) map[int]response {
+	return map[int]response{http.StatusOK: {ContentType: contentType, Description: description}}
+}
+
+func queryParam(name, description string, s *Schema) *Parameter {
+	return &Parameter{Name: name, In: "query", Description: description, Schema: s}
+}
+
+func requiredQuery(name, description string, s *Schema) *Parameter {
+	return &Parameter{Name: name, In: "query", Required: true, Description: description, Schema: s}
+}
+
+var pageParams = []*Parameter{
+	queryParam("cursor", "next_cursor from the previous page; takes precedence over page", str()),
+	queryParam("page", "1-based page number (legacy offset paging)", integer()),
+	queryParam("limit", "Page size", integer()),
+}
+
+// createdRange filters a list by creation time (RFC 3339 or YYYY-MM-DD)
+var createdRange = []*Parameter{
+	queryParam("created_after", "Created at or after this time (RFC 3339 or YYYY-MM-DD)", str()),
+	queryParam("created_before", "Created before this time (RFC 3339 or YYYY-MM-DD)", str()),
+}
+
+func marketRegionsQuery() *Parameter {
+	return queryParam("market_region", "Market region; comma-separated for several (INDIA, EU, US, UK, GLOBAL)", str())
+}
+
+func serialPrefixQuery() *Parameter {
+	return queryParam("serial_prefix", "Serial number prefix", str())
+}
+
+func chemistryQuery() *Parameter {
+	return queryParam("chemistry", "Battery chemistry from the batch specs, e.g. LFP", str())
+}
+
+func withPaging(params ...*Parameter) []*Parameter {
+	return append(params, pageParams...)
+}
+
+// paged is the envelope used by every list endpoint. total, page and total_pages
+// are only present when rows were counted (page mode, not cursor mode).
+func paged(key string, items *Schema) *Schema {
+	return object(
+		prop(key, arrayOf(items)),
+		prop("count", integer()),
+		prop("limit", integer()),
+		prop("has_more", boolean()),
+		prop("next_cursor", nullable(str())),
+		opt("total", integer()),
+		opt("page", integer()),
+		opt("total_pages", integer()),
+	)
+}
+
+// searchResults is the paged search envelope with the query echoed and facet counts
+func searchResults() *Schema {
+	s := paged("results", typeOf(models.PassportSearchResult{}))
+	s.Properties["query"] = str()
+	s.Properties["facets"] = typeOf(models.SearchFacets{})
+	s.Required = append(s.Required, "query", "facets")
+	return s
+}
+
+// searchQuery is the query grammar shared by the dashboard and external search endpoints
+func searchQuery() []*Parameter {
+	return append([]*Parameter{
+		requiredQuery("q", "Serial/BPAN fragment, custodian email, batch name, Bill of Entry, HSN code or spec value (2-100 chars)", str()),
+		queryParam("page", "1-based page number", integer()),
+		queryParam("limit", "Page size (default 20, max 100)", integer()),
+		queryParam("batch_id", "Only passports in this batch", uuidStr()),
+		queryParam("status", "Lifecycle status", str()),
+		serialPrefixQuery(),
+		marketRegionsQuery(),
+		chemistryQuery(),
+	}, createdRange...)
+}
+
+// graphqlErrors is the GraphQL error list; errors may carry locations, path and extensions
+func graphqlErrors() *Schema {
+	item := object(prop("message", str()))
+	item.AdditionalProperties = nil
+	return object(prop("errors", arrayOf(item)))
+}
+
+// graphqlResult is a GraphQL response: data shaped by the query, plus any field errors
+func graphqlResult() map[int]response {
+	s := graphqlErrors()
+	s.Properties["data"] = nullable(&Schema{Type: "object"})
+	s.Required = nil
+	return map[int]response{
+		http.StatusOK:         {Schema: s},
+		http.StatusBadRequest: {Description: "Query could not be parsed or exceeds the depth, length or cost limits", Schema: graphqlErrors()},
+	}
+}
+
+func message() *Schema { return object(prop("message", str())) }
+
+// specWarnings lists spec issues that did not block a save
+func specWarnings() field { return opt("warnings", arrayOf(typeOf(models.SpecIssue{}))) }
+
+func successMessage() *Schema {
+	return object(prop("success", boolean()), prop("message", str()))
+}
+
+func fileUpload(fields ...field) *Schema {
+	return object(append([]field{prop("file", &Schema{Type: "string", Format: "binary"})}, fields...)...)
+}
+
+var (
+	tenantQuery      = requiredQuery("tenant_id", "Tenant UUID", uuidStr())
+	batchStatusEnum  = enum(models.BatchStatusDraft, models.BatchStatusActive, models.BatchStatusArchived)
+	marketRegionEnum = enum(string(models.MarketRegionIndia), string(models.MarketRegionEU), string(models.MarketRegionUS),
+		string(models.MarketRegionUK), string(models.MarketRegionGlobal))
+	remedyStatusEnum = enum(models.RemedyPending, models.RemedyNotified, models.RemedyReturned, models.RemedyReplaced, models.RemedyRecycled)
+	claimStatusEnum  = enum(models.ClaimStatusSubmitted, models.ClaimStatusApproved, models.ClaimStatusRejected, models.ClaimStatusReceived)
+	transferStatus   = enum(models.TransferStatusPending, models.TransferStatusAccepted, models.TransferStatusRejected, models.TransferStatusCancelled)
+	roleStats        = object(prop("installations", integer()), prop("recycles", integer()), prop("returns", integer()))
+
+	financialYearQuery     = queryParam("fy", "Financial year, e.g. 2025-26 (default: the current one)", str())
+	enforceComplianceQuery = queryParam("enforce_compliance",
+		"true to refuse activation (409 with the compliance checklist) when a blocking check fails", boolean())
+)
+
+var tags = []Tag{
+	{Name: "system", Description: "Health and API description"},
+	{Name: "auth", Description: "Registration, login and account recovery"},
+	{Name: "batches", Description: "Production batches and passport generation"},
+	{Name: "passports", Description: "Passport lifecycle and public passport data"},
+	{Name: "recalls", Description: "Recall campaigns: scoped bulk recall, owner notices and remedy tracking"},
+	{Name: "warranty", Description: "Warranty claims: magic-link submission, manufacturer review and RMA numbers"},
+	{Name: "custody", Description: "Chain-of-custody ledger: transfers, receiver acceptance and ownership timeline"},
+	{Name: "shipments", Description: "Export shipments: customs documentation pack and one-step move to SHIPPED"},
+	{Name: "carbon", Description: "Carbon footprint: emission factor library and per-kWh lifecycle calculation"},
+	{Name: "due-diligence", Description: "Recycled content per material, supplier and smelter list, and third-party audit reports"},
+	{Name: "pli-audit", Description: "PLI domestic value addition attested by a chartered accountant, with an append-only audit log"},
+	{Name: "compliance", Description: "Per-market rule packs and the readiness checklist of mandatory fields, certifications and documents"},
+	{Name: "epr", Description: "India EPR annual return: quantities placed on the market, recycling credits and obligations"},
+	{Name: "events", Description: "Live Server-Sent Events stream of scans, transitions, activations and imports"},
+	{Name: "graphql", Description: "GraphQL read API over batches, passports, events, scans and rewards"},
+	{Name: "templates", Description: "Reusable batch specification templates"},
+	{Name: "dashboard", Description: "Dashboard statistics and scan feed"},
+	{Name: "billing", Description: "Activation quota and payments"},
+	{Name: "documents", Description: "Compliance certificates and branding"},
+	{Name: "admin", Description: "Document verification"},
+	{Name: "magic-link", Description: "Field partner actions authenticated by magic link"},
+	{Name: "api-keys", Description: "API key management, usage and audit"},
+	{Name: "partners", Description: "Trusted partner domains and partner codes"},
+	{Name: "rewards", Description: "Scan-to-earn reputation points"},
+	{Name: "external", Description: "ERP integration API authenticated by X-API-Key"},
+}
+
+func routes() []route {
+	batchPassport := typeOf(models.Passport{})
+	transitionResult := typeOf(services.TransitionResult{})
+	bulkResult := typeOf(services.BulkTransitionResult{})
+	passportEvents := object(
+		prop("passport_id", uuidStr()),
+		prop("events", arrayOf(nullable(typeOf(models.PassportEvent{})))),
+		prop("count", integer()),
+	)
+	allowedTransitions := object(
+		prop("current_status", str()),
+		prop("allowed_transitions", arrayOf(str())),
+	)
+	batchDetail := object(
+		prop("batch", nullable(typeOf(models.Batch{}))),
+		prop("passport_count", integer()),
+	)
+	activation := object(
+		prop("message", str()),
+		prop("batch_id", uuidStr()),
+		prop("quota_balance", integer()),
+	)
+
+	return []route{
+		// ============================================
+		// SYSTEM
+		// ============================================
+		{
+			Pattern: "GET /health", ID: "health", Tag: "system",
+			Summary:   "Health check",
+			Responses: ok(object(prop("status", str()), prop("service", str()))),
+		},
+		{
+			Pattern: "GET /api/v1/openapi.json", ID: "getOpenAPI", Tag: "system",
+			Summary:   "This OpenAPI document",
+			Responses: ok(&Schema{Type: "object"}),
+		},
+
+		// ============================================
+		// AUTH
+		// ============================================
+		{
+			Pattern: "POST /api/v1/auth/register", ID: "register", Tag: "auth",
+			Summary:   "Register a company and its first login",
+			Body:      handlers.RegisterRequest{},
+			Responses: created(handlers.AuthResponse{}),
+			Errors:    []int{http.StatusConflict},
+		},
+		{
+			Pattern: "POST /api/v1/auth/login", ID: "login", Tag: "auth",
+			Summary:     "Log in with email and password",
+			Description: "Rate limited per IP and per email. Repeated failures lock the account and email an unlock link.",
+			Body:        handlers.LoginRequest{},
+			Responses:   ok(handlers.AuthResponse{}),
+			Errors:      []int{http.StatusUnauthorized},
+			RateLimited: true,
+		},
+		{
+			Pattern: "POST /api/v1/auth/refresh", ID: "refreshToken", Tag: "auth",
+			Summary:   "Exchange a refresh token for a new access token",
+			Body:      object(prop("refresh_token", str())),
+			Responses: ok(object(prop("token", str()), prop("expires_in", integer()))),
+			Errors:    []int{http.StatusUnauthorized},
+		},
+		{
+			Pattern: "POST /api/v1/auth/forgot-password", ID: "forgotPassword", Tag: "auth",
+			Summary:     "Email a password reset link",
+			Body:        handlers.ForgotPasswordRequest{},
+			Responses:   ok(message()),
+			RateLimited: true,
+		},
+		{
+			Pattern: "POST /api/v1/auth/reset-password", ID: "resetPassword", Tag: "auth",
+			Summary:   "Set a new password with a reset token",
+			Body:      handlers.ResetPasswordRequest{},
+			Responses: ok(message()),
+		},
+		{
+			Pattern: "POST /api/v1/auth/unlock", ID: "unlockAccount", Tag: "auth",
+			Summary:   "Lift a login lockout with the token from the lockout email",
+			Body:      handlers.UnlockAccountRequest{},
+			Responses: ok(message()),
+		},
+		{
+			Pattern: "POST /api/v1/auth/magic-link", ID: "requestMagicLink", Tag: "magic-link",
+			Summary:     "Request a magic link for a field partner",
+			Description: "Tier A: trusted email domain. Tier B: unknown domain with a valid partner code. In development the link is returned in the response.",
+			Body:        handlers.RequestMagicLinkRequest{},
+			Responses: ok(object(
+				prop("success", boolean()),
+				prop("message", str()),
+				prop("expires_at", dateTime()),
+				prop("verified_via", str()),
+				prop("company", str()),
+				prop("role", str()),
+				opt("link", str()),
+				opt("dev_mode", boolean()),
+			)),
+			Errors:      []int{http.StatusForbidden},
+			RateLimited: true,
+		},
+		{
+			Pattern: "GET /api/v1/auth/me", ID: "getMe", Tag: "auth", Auth: authJWT,
+			Summary: "Current tenant profile",
+			Responses: ok(object(
+				prop("id", uuidStr()),
+				prop("tenant_id", str()),
+				prop("company_name", str()),
+				prop("email", str()),
+				prop("address", str()),
+				prop("logo_url", str()),
+				prop("support_email", str()),
+				prop("website", str()),
+				prop("created_at", dateTime()),
+				prop("last_login", nullable(dateTime())),
+				prop("quota_balance", integer()),
+				prop("epr_registration_number", str()),
+				prop("bis_r_number", str()),
+				prop("iec_code", str()),
+				prop("epr_certificate_path", str()),
+				prop("bis_certificate_path", str()),
+				prop("pli_certificate_path", str()),
+				prop("epr_status", str()),
+				prop("bis_status", str()),
+				prop("pli_status", str()),
+			)),
+			Errors: []int{http.StatusNotFound},
+		},
+		{
+			Pattern: "PUT /api/v1/auth/profile", ID: "updateProfile", Tag: "auth", Auth: authJWT,
+			Summary:   "Update the tenant profile",
+			Body:      models.UpdateProfileRequest{},
+			Responses: ok(models.Tenant{}),
+		},
+
+		// ============================================
+		// BATCHES
+		// ============================================
+		{
+			Pattern: "POST /api/v1/batches", ID: "createBatch", Tag: "batches", Auth: authJWT,
+			Summary: "Create a DRAFT batch",
+			Description: "specs.voltage, capacity and weight are parsed (V, mAh/Ah or Wh/kWh, g/kg) into specs.ratings with rated " +
+				"and specific energy. Values implausible for the chemistry, material compositions the chemistry cannot have, " +
+				"the EU 2023/1542 mercury limit, the cadmium limit of portable batteries and undeclared hazardous substances are " +
+				"rejected with per-field issues; non-blocking issues are returned as warnings. specs.battery_category (PORTABLE, " +
+				"LMT, EV, INDUSTRIAL, SLI) sets specs.epr_category when that is not given; its obligations (GET /battery-categories) " +
+				"drive the EU and US rules. India batches get an ESTIMATED domestic value addition computed " +
+				"from specs.sale_price_inr and import_cost_inr; it becomes AUDITED only through a CA attestation (pli-audit). " +
+				"markets (INDIA, EU, US, UK) targets several markets at once; market_region then reads GLOBAL. Each market's " +
+				"rule pack (GET /markets) runs its on_create rules: ERROR rules refuse the batch for every market listed (a " +
+				"market_region GLOBAL batch without markets is only warned); other failures are returned as market_warnings.",
+			Body:      models.CreateBatchRequest{},
+			Responses: created(models.CreateBatchResponse{}),
+		},
+		{
+			Pattern: "GET /api/v1/batches", ID: "listBatches", Tag: "batches", Auth: authJWT,
+			Summary: "List batches",
+			Query: withPaging(append([]*Parameter{
+				tenantQuery,
+				queryParam("status", "Batch status", batchStatusEnum),
+				marketRegionsQuery(),
+				chemistryQuery(),
+			}, createdRange...)...),
+			Responses: ok(paged("batches", nullable(typeOf(models.Batch{})))),
+		},
+		{
+			Pattern: "GET /api/v1/batches/{id}", ID: "getBatch", Tag: "batches", Auth: authJWT,
+			Summary:   "Get a batch",
+			Responses: ok(batchDetail),
+		},
+		{
+			Pattern: "POST /api/v1/batches/{id}/upload", ID: "uploadBatchCSV", Tag: "batches", Auth: authJWT,
+			Summary:     "Import passports from CSV",
+			Description: "When some rows fail, the created passports are returned under result with the row errors as warnings.",
+			Multipart:   fileUpload(),
+			Responses: ok(&Schema{AnyOf: []*Schema{
+				typeOf(models.UploadCSVResponse{}),
+				object(
+					prop("result", typeOf(models.UploadCSVResponse{})),
+					prop("warnings", arrayOf(typeOf(services.CSVRowError{}))),
+				),
+			}}),
+		},
+		{
+			Pattern: "POST /api/v1/batches/{id}/validate", ID: "validateBatchCSV", Tag: "batches", Auth: authJWT,
+			Summary:   "Dry-run a CSV import",
+			Multipart: fileUpload(),
+			Responses: ok(object(
+				prop("valid_count", integer()),
+				prop("total_rows", integer()),
+				prop("duplicates", arrayOf(typeOf(repository.DuplicateInfo{}))),
+				prop("duplicate_count", integer()),
+				prop("errors", arrayOf(typeOf(services.CSVRowError{}))),
+				prop("error_count", integer()),
+				prop("ready_to_import", boolean()),
+			)),
+		},
+		{
+			Pattern: "POST /api/v1/batches/{id}/auto-generate", ID: "autoGeneratePassports", Tag: "batches", Auth: authJWT,
+			Summary: "Generate sequential passports",
+			Body: object(
+				prop("count", integer()),
+				opt("prefix", str()),
+				opt("start_number", integer()),
+				opt("manufacture_date", &Schema{Type: "string", Format: "date"}),
+			),
+			Responses: created(object(
+				prop("batch_id", uuidStr()),
+				prop("batch_name", str()),
+				prop("passports_created", integer()),
+				prop("serial_range", str()),
+				prop("qr_codes_ready", boolean()),
+			)),
+		},
+		{
+			Pattern: "GET /api/v1/batches/{id}/download", ID: "downloadQRCodes", Tag: "batches", Auth: authJWT,
+			Summary:   "Download QR codes as a ZIP",
+			Responses: download("application/zip", "ZIP of QR code PNGs"),
+		},
+		{
+			Pattern: "GET /api/v1/batches/{id}/labels", ID: "downloadLabels", Tag: "batches", Auth: authJWT,
+			Summary:   "Download printable labels",
+			Responses: download("application/pdf", "Label sheet PDF"),
+		},
+		{
+			Pattern: "GET /api/v1/batches/{id}/export", ID: "exportBatchCSV", Tag: "batches", Auth: authJWT,
+			Summary:   "Export passports as CSV",
+			Responses: download("text/csv", "Serial number export"),
+		},
+		{
+			Pattern: "GET /api/v1/batches/{id}/passports", ID: "listBatchPassports", Tag: "batches", Auth: authJWT,
+			Summary: "List passports in a batch",
+			Query: withPaging(append([]*Parameter{
+				queryParam("status", "Lifecycle status", str()),
+				serialPrefixQuery(),
+			}, createdRange...)...),
+			Responses: ok(paged("passports", nullable(batchPassport))),
+		},
+		{
+			Pattern: "DELETE /api/v1/batches/{id}", ID: "deleteBatch", Tag: "batches", Auth: authJWT,
+			Summary: "Delete a batch and its passports",
+			Responses: ok(object(
+				prop("deleted_passports", integer()),
+				prop("batch_name", str()),
+				prop("message", str()),
+			)),
+		},
+		{
+			Pattern: "GET /api/v1/batches/recent", ID: "listRecentBatches", Tag: "dashboard", Auth: authJWT,
+			Summary:   "Recently created batches",
+			Query:     []*Parameter{tenantQuery, queryParam("limit", "Maximum results", integer())},
+			Responses: ok(object(prop("batches", arrayOf(typeOf(models.BatchSummary{}))), prop("count", integer()))),
+		},
+		{
+			Pattern: "POST /api/v1/batches/{id}/activate", ID: "activateBatch", Tag: "billing", Auth: authJWT,
+			Summary:   "Activate a batch (uses one quota unit)",
+			Query:     []*Parameter{enforceComplianceQuery},
+			Responses: ok(activation),
+			Errors:    []int{http.StatusPaymentRequired, http.StatusConflict},
+		},
+		{
+			Pattern: "POST /api/v1/batches/{id}/duplicate", ID: "duplicateBatch", Tag: "batches", Auth: authJWT,
+			Summary: "Copy a batch's settings into a new DRAFT batch",
+			Responses: created(object(
+				prop("success", boolean()),
+				prop("new_batch_id", uuidStr()),
+				prop("batch", nullable(typeOf(models.Batch{}))),
+			)),
+		},
+
+		// ============================================
+		// PASSPORTS
+		// ============================================
+		{
+			Pattern: "GET /api/v1/passports/search", ID: "searchPassports", Tag: "passports", Auth: authJWT,
+			Summary:     "Search passports",
+			Description: "Ranked full-text and substring search across the tenant's passports with facet counts by status, market region, chemistry and batch.",
+			Query:       searchQuery(),
+			Responses:   ok(searchResults()),
+		},
+		{
+			Pattern: "GET /api/v1/events/stream", ID: "streamEvents", Tag: "events", Auth: authJWT,
+			Summary: "Stream live tenant events",
+			Description: "Server-Sent Events. Each message has the event ID as id, the type as event and {id, type, data, created_at} as data. " +
+				"Types: scan.recorded, passport.transitioned, passports.bulk_updated, batch.activated, import.progress " +
+				"(started, inserting after each 1000 passports, then completed or failed), recall.created, custody.transferred and shipment.shipped. " +
+				"Reconnects replay events after the Last-Event-ID header for up to 24 hours; a client more than 500 events behind " +
+				"receives a resync event and should reload. EventSource can pass the JWT as ?token=.",
+			Query: []*Parameter{
+				queryParam("types", "Comma-separated event types (default all)", str()),
+				queryParam("last_event_id", "Resume after this event ID when the Last-Event-ID header cannot be set", integer()),
+			},
+			Responses: map[int]response{http.StatusOK: {ContentType: "text/event-stream", Description: "Event stream"}},
+		},
+		{
+			Pattern: "POST /api/v1/graphql", ID: "graphql", Tag: "graphql", Auth: authJWT,
+			Summary: "Run a GraphQL query",
+			Description: "Read-only queries over the tenant's batches, passports and their events, scans and rewards. " +
+				"List fields take first (default 20, max 100); queries are limited to depth 8 and an estimated cost of 10000. " +
+				"The schema is documented in its SDL descriptions and available by introspection.",
+			Body:      graph.Request{},
+			Responses: graphqlResult(),
+		},
+		{
+			Pattern: "POST /api/v1/passports/bulk/status", ID: "bulkUpdatePassportStatus", Tag: "passports", Auth: authJWT,
+			Summary: "Set the status of many passports",
+			Body:    handlers.BulkUpdateStatusRequest{},
+			Responses: ok(object(
+				prop("updated", integer()),
+				prop("status", str()),
+				prop("message", str()),
+			)),
+		},
+		{
+			Pattern: "POST /api/v1/passports/bulk/delete", ID: "bulkDeletePassports", Tag: "passports", Auth: authJWT,
+			Summary:   "Delete many passports",
+			Body:      handlers.BulkDeleteRequest{},
+			Responses: ok(object(prop("deleted", integer()), prop("message", str()))),
+		},
+		{
+			Pattern: "POST /api/v1/passports/bulk/transition", ID: "bulkTransitionPassports", Tag: "passports", Auth: authJWT,
+			Summary:   "Transition many passports through the lifecycle",
+			Body:      handlers.BulkLifecycleTransitionRequest{},
+			Responses: ok(bulkResult),
+		},
+		{
+			Pattern: "POST /api/v1/passports/{uuid}/transition", ID: "transitionPassport", Tag: "passports", Auth: authJWT,
+			Summary:   "Transition a passport through the lifecycle",
+			Body:      handlers.LifecycleTransitionRequest{},
+			Responses: ok(transitionResult),
+		},
+		{
+			Pattern: "GET /api/v1/passports/{uuid}/transitions", ID: "listAllowedTransitions", Tag: "passports", Auth: authJWT,
+			Summary:   "Next statuses allowed for a passport",
+			Responses: ok(allowedTransitions),
+		},
+		{
+			Pattern: "GET /api/v1/passports/{uuid}/events", ID: "listPassportEvents", Tag: "passports", Auth: authJWT,
+			Summary:   "Lifecycle audit trail",
+			Responses: ok(passportEvents),
+		},
+		{
+			Pattern: "GET /api/v1/passports/{uuid}", ID: "getPublicPassport", Tag: "passports",
+			Summary:     "Public passport page data (QR code target)",
+			Description: "obligations lists the passport, carbon footprint, labelling and collection obligations of specs.battery_category.",
+			Responses:   ok(models.PassportWithSpecs{}),
+		},
+
+		// ============================================
+		// RECALLS
+		// ============================================
+		{
+			Pattern: "POST /api/v1/recalls/preview", ID: "previewRecall", Tag: "recalls", Auth: authJWT,
+			Summary:     "Preview a recall scope",
+			Description: "Counts the passports a campaign with this scope would recall, by current status. Changes nothing.",
+			Body:        models.RecallScope{},
+			Responses:   ok(models.RecallPreview{}),
+		},
+		{
+			Pattern: "POST /api/v1/recalls", ID: "createRecall", Tag: "recalls", Auth: authJWT,
+			Summary: "Open a recall campaign",
+			Description: "Scope criteria (batch IDs, serial range, manufacture date window, chemistry) combine with AND; at least one is required. " +
+				"Every passport in scope that is not recycled or end of life moves to RECALLED in one transaction, the public passport page " +
+				"shows a recall banner, and owners with a known email are notified in the background.",
+			Body:      services.CreateRecallRequest{},
+			Responses: created(services.CreateRecallResult{}),
+		},
+		{
+			Pattern: "GET /api/v1/recalls", ID: "listRecalls", Tag: "recalls", Auth: authJWT,
+			Summary:   "List recall campaigns",
+			Query:     withPaging(queryParam("status", "Campaign status", enum(models.RecallStatusActive, models.RecallStatusClosed))),
+			Responses: ok(paged("recalls", nullable(typeOf(models.RecallCampaign{})))),
+		},
+		{
+			Pattern: "GET /api/v1/recalls/{id}", ID: "getRecall", Tag: "recalls", Auth: authJWT,
+			Summary: "Recall campaign with progress report",
+			Responses: ok(object(
+				prop("recall", nullable(typeOf(models.RecallCampaign{}))),
+				prop("progress", nullable(typeOf(models.RecallProgress{}))),
+			)),
+			Errors: []int{http.StatusNotFound},
+		},
+		{
+			Pattern: "GET /api/v1/recalls/{id}/units", ID: "listRecallUnits", Tag: "recalls", Auth: authJWT,
+			Summary:   "Units covered by a recall, in serial order",
+			Query:     withPaging(queryParam("remedy_status", "Remedy status", remedyStatusEnum)),
+			Responses: ok(paged("units", nullable(typeOf(models.RecallUnit{})))),
+			Errors:    []int{http.StatusNotFound},
+		},
+		{
+			Pattern: "POST /api/v1/recalls/{id}/remedies", ID: "updateRecallRemedies", Tag: "recalls", Auth: authJWT,
+			Summary:     "Record remedies for recalled units",
+			Description: "Sets the remedy status of up to 1000 units. RECYCLED also moves the passport from RECALLED to RECYCLED.",
+			Body:        services.UpdateRemediesRequest{},
+			Responses:   ok(services.UpdateRemediesResult{}),
+			Errors:      []int{http.StatusNotFound, http.StatusConflict},
+		},
+		{
+			Pattern: "POST /api/v1/recalls/{id}/notify", ID: "notifyRecallOwners", Tag: "recalls", Auth: authJWT,
+			Summary:     "Email owners not yet notified",
+			Description: "Picks up owner emails registered since the campaign opened and emails them in the background.",
+			Responses:   map[int]response{http.StatusAccepted: {Schema: message()}},
+			Errors:      []int{http.StatusNotFound, http.StatusConflict},
+		},
+		{
+			Pattern: "POST /api/v1/recalls/{id}/close", ID: "closeRecall", Tag: "recalls", Auth: authJWT,
+			Summary:     "Close a recall campaign",
+			Description: "Removes the recall banner from the campaign's passports. Passport statuses are not changed.",
+			Responses:   ok(message()),
+			Errors:      []int{http.StatusNotFound, http.StatusConflict},
+		},
+
+		// ============================================
+		// EXPORT SHIPMENTS
+		// ============================================
+		{
+			Pattern: "POST /api/v1/shipments", ID: "createShipment", Tag: "shipments", Auth: authJWT,
+			Summary: "Draft an export shipment",
+			Description: "Reserves the shippable passports of the listed batches plus any listed passport_ids for the shipment; " +
+				"a passport can be in only one open shipment. unit_values is the invoice value per unit, keyed by batch ID. " +
+				"Warnings list the data missing from the customs documents.",
+			Body:      services.CreateShipmentRequest{},
+			Responses: created(models.ShipmentDetail{}),
+			Errors:    []int{http.StatusConflict},
+		},
+		{
+			Pattern: "GET /api/v1/shipments", ID: "listShipments", Tag: "shipments", Auth: authJWT,
+			Summary: "List export shipments, newest first",
+			Query: withPaging(queryParam("status", "Shipment status",
+				enum(models.ShipmentStatusDraft, models.ShipmentStatusShipped, models.ShipmentStatusCancelled))),
+			Responses: ok(paged("shipments", nullable(typeOf(models.Shipment{})))),
+		},
+		{
+			Pattern: "GET /api/v1/shipments/{id}", ID: "getShipment", Tag: "shipments", Auth: authJWT,
+			Summary:   "Shipment with its invoice lines and missing document data",
+			Responses: ok(models.ShipmentDetail{}),
+			Errors:    []int{http.StatusNotFound},
+		},
+		{
+			Pattern: "GET /api/v1/shipments/{id}/documents", ID: "downloadShipmentDocuments", Tag: "shipments", Auth: authJWT,
+			Summary: "Download the customs documentation pack",
+			Description: "Packing list, commercial invoice data (HSN, IEC), UN38.3 test summary references, country of origin " +
+				"declaration and passport QR index. Not available for cancelled shipments.",
+			Query: []*Parameter{queryParam("format", "Pack format (default: zip)", enum("zip", "pdf"))},
+			Responses: download("application/zip",
+				"ZIP of the pack PDF, a passport index CSV and QR code PNGs; application/pdf with format=pdf"),
+			Errors: []int{http.StatusNotFound, http.StatusConflict},
+		},
+		{
+			Pattern: "POST /api/v1/shipments/{id}/ship", ID: "shipShipment", Tag: "shipments", Auth: authJWT,
+			Summary:     "Mark a draft shipment as shipped",
+			Description: "Moves every passport of the shipment to SHIPPED in one transaction, or none if any can no longer ship.",
+			Responses:   ok(services.ShipResult{}),
+			Errors:      []int{http.StatusNotFound, http.StatusConflict},
+		},
+		{
+			Pattern: "POST /api/v1/shipments/{id}/cancel", ID: "cancelShipment", Tag: "shipments", Auth: authJWT,
+			Summary:     "Cancel a draft shipment",
+			Description: "Releases its passports for other shipments. Passport statuses are not changed.",
+			Responses:   ok(object(prop("shipment", nullable(typeOf(models.Shipment{}))))),
+			Errors:      []int{http.StatusNotFound, http.StatusConflict},
+		},
+
+		// ============================================
+		// CHAIN OF CUSTODY
+		// ============================================
+		{
+			Pattern: "POST /api/v1/passports/{uuid}/custody/transfers", ID: "transferPassportCustody", Tag: "custody", Auth: authJWT,
+			Summary: "Transfer a passport to another party",
+			Description: "Records a PENDING transfer from the current owner (or the manufacturer) and emails the receiving party an " +
+				"acceptance link. Custody changes hands when they accept. passport_ids in the body is ignored.",
+			Body:      services.CreateTransferRequest{},
+			Responses: created(services.CreateTransferResult{}),
+			Errors:    []int{http.StatusConflict},
+		},
+		{
+			Pattern: "GET /api/v1/passports/{uuid}/custody", ID: "getPassportCustody", Tag: "custody", Auth: authJWT,
+			Summary:     "Ownership timeline of a passport",
+			Description: "Holding periods from accepted transfers, oldest first; the current owner is the holder of the last period (null = manufacturer).",
+			Query:       []*Parameter{queryParam("at", "Also report who held the battery at this time (RFC 3339 or YYYY-MM-DD)", str())},
+			Responses:   ok(services.CustodyTimeline{}),
+			Errors:      []int{http.StatusNotFound},
+		},
+		{
+			Pattern: "POST /api/v1/custody/transfers", ID: "bulkTransferCustody", Tag: "custody", Auth: authJWT,
+			Summary:     "Transfer up to 1000 passports to one party",
+			Description: "For distributor pallets: the transfers share a group_id and one acceptance link, and are accepted or rejected together.",
+			Body:        services.CreateTransferRequest{},
+			Responses:   created(services.CreateTransferResult{}),
+			Errors:      []int{http.StatusConflict},
+		},
+		{
+			Pattern: "GET /api/v1/custody/transfers", ID: "listCustodyTransfers", Tag: "custody", Auth: authJWT,
+			Summary:   "List custody transfers, newest first",
+			Query:     withPaging(queryParam("status", "Transfer status", transferStatus)),
+			Responses: ok(paged("transfers", nullable(typeOf(models.CustodyTransfer{})))),
+		},
+		{
+			Pattern: "POST /api/v1/custody/transfers/{id}/cancel", ID: "cancelCustodyTransfer", Tag: "custody", Auth: authJWT,
+			Summary:     "Cancel a pending transfer",
+			Description: "Cancels every pending transfer in the transfer's group.",
+			Responses:   ok(object(prop("cancelled", integer()))),
+			Errors:      []int{http.StatusNotFound, http.StatusConflict},
+		},
+		{
+			Pattern: "GET /api/v1/custody/accept", ID: "getCustodyAcceptance", Tag: "custody",
+			Summary:   "Transfer group behind an acceptance link",
+			Query:     []*Parameter{requiredQuery("token", "Acceptance token from the transfer email", str())},
+			Responses: ok(services.CustodyAcceptance{}),
+			Errors:    []int{http.StatusNotFound},
+		},
+		{
+			Pattern: "POST /api/v1/custody/accept", ID: "respondToCustodyTransfer", Tag: "custody",
+			Summary:     "Accept or reject a transfer as the receiving party",
+			Description: "ACCEPT makes the receiving party the current owner of every passport in the group.",
+			Body:        handlers.RespondToTransferRequest{},
+			Responses:   ok(services.CustodyAcceptance{}),
+			Errors:      []int{http.StatusNotFound, http.StatusConflict},
+		},
+
+		// ============================================
+		// CARBON FOOTPRINT
+		// ============================================
+		{
+			Pattern: "POST /api/v1/emission-factors", ID: "importEmissionFactors", Tag: "carbon", Auth: authJWT,
+			Summary: "Import emission factors",
+			Description: "Upserts factors into the tenant's library by key, from a JSON body or a CSV upload with columns " +
+				"key, name, unit, kg_co2e_per_unit and optional stage, source, region. Any invalid row rejects the whole import " +
+				"and the row errors are returned.",
+			Body:      handlers.ImportFactorsRequest{},
+			Multipart: fileUpload(),
+			Responses: ok(object(prop("imported", integer()), prop("factors", arrayOf(nullable(typeOf(models.EmissionFactor{})))))),
+		},
+		{
+			Pattern: "GET /api/v1/emission-factors", ID: "listEmissionFactors", Tag: "carbon", Auth: authJWT,
+			Summary:   "List the emission factor library",
+			Responses: ok(object(prop("factors", arrayOf(nullable(typeOf(models.EmissionFactor{})))), prop("count", integer()))),
+		},
+		{
+			Pattern: "DELETE /api/v1/emission-factors/{id}", ID: "deleteEmissionFactor", Tag: "carbon", Auth: authJWT,
+			Summary:   "Delete an emission factor",
+			Responses: ok(message()),
+			Errors:    []int{http.StatusNotFound},
+		},
+		{
+			Pattern: "POST /api/v1/batches/{id}/carbon-footprint", ID: "calculateCarbonFootprint", Tag: "carbon", Auth: authJWT,
+			Summary: "Calculate a batch carbon footprint",
+			Description: "Applies library factors to the bill of materials and energy inputs of one battery, groups the result by " +
+				"lifecycle stage and divides by the rated energy from the capacity and voltage specs. The kg CO2e/kWh total, stage " +
+				"breakdown and performance class are written to specs (carbon_footprint, carbon_footprint_detail) and the full " +
+				"report is kept on the batch. Only DRAFT batches are updated; other batches accept dry runs only (409).",
+			Query:     []*Parameter{queryParam("dry_run", "Only calculate; leave the batch unchanged", boolean())},
+			Body:      models.CarbonCalculationInput{},
+			Responses: ok(models.CarbonFootprintReport{}),
+			Errors:    []int{http.StatusNotFound, http.StatusConflict},
+		},
+		{
+			Pattern: "GET /api/v1/batches/{id}/carbon-footprint", ID: "getCarbonFootprint", Tag: "carbon", Auth: authJWT,
+			Summary:   "Carbon footprint report of a batch",
+			Responses: ok(models.CarbonFootprintReport{}),
+			Errors:    []int{http.StatusNotFound},
+		},
+
+		// ============================================
+		// RECYCLED CONTENT & DUE DILIGENCE
+		// ============================================
+		{
+			Pattern: "GET /api/v1/batches/{id}/due-diligence", ID: "getDueDiligence", Tag: "due-diligence", Auth: authJWT,
+			Summary:   "Recycled content, suppliers and audit reports of a batch",
+			Responses: ok(models.DueDiligenceDisclosure{}),
+			Errors:    []int{http.StatusNotFound},
+		},
+		{
+			Pattern: "PUT /api/v1/batches/{id}/due-diligence", ID: "updateDueDiligence", Tag: "due-diligence", Auth: authJWT,
+			Summary: "Replace the recycled content and supplier list of a batch",
+			Description: "Sets specs.recycled_content (recycled share of cobalt, lithium, nickel and lead) and specs.due_diligence " +
+				"(policy and suppliers with role, material, ISO country code and certification). Invalid entries are rejected " +
+				"with per-field issues; non-blocking issues are returned as warnings. The public passport withholds supplier names.",
+			Body:      handlers.UpdateDueDiligenceRequest{},
+			Responses: ok(object(prop("due_diligence", nullable(typeOf(models.DueDiligenceDisclosure{}))), specWarnings())),
+			Errors:    []int{http.StatusNotFound},
+		},
+		{
+			Pattern: "POST /api/v1/batches/{id}/due-diligence/documents", ID: "uploadAuditReport", Tag: "due-diligence", Auth: authJWT,
+			Summary: "Attach a third-party audit report (PDF)",
+			Description: "Audit reports are listed on the public passport by title, auditor and date only; the PDF is available " +
+				"to the tenant and, through a magic link, to partners other than customers.",
+			Multipart: fileUpload(
+				prop("title", str()),
+				opt("auditor", str()),
+				opt("supplier", &Schema{Type: "string", Description: "Party audited"}),
+				opt("issued_on", &Schema{Type: "string", Format: "date"}),
+			),
+			Responses: created(object(prop("document", nullable(typeOf(models.DueDiligenceDocument{}))))),
+			Errors:    []int{http.StatusNotFound},
+		},
+		{
+			Pattern: "GET /api/v1/batches/{id}/due-diligence/documents/{doc}", ID: "getAuditReport", Tag: "due-diligence", Auth: authJWT,
+			Summary:   "Download an audit report",
+			Responses: download("application/pdf", "Audit report PDF"),
+			Errors:    []int{http.StatusNotFound},
+		},
+		{
+			Pattern: "DELETE /api/v1/batches/{id}/due-diligence/documents/{doc}", ID: "deleteAuditReport", Tag: "due-diligence", Auth: authJWT,
+			Summary:   "Delete an audit report",
+			Responses: ok(message()),
+			Errors:    []int{http.StatusNotFound},
+		},
+
+		// ============================================
+		// PLI DVA AUDITS
+		// ============================================
+		{
+			Pattern: "POST /api/v1/batches/{id}/pli-audit", ID: "requestPLIAudit", Tag: "pli-audit", Auth: authJWT,
+			Summary: "Invite a chartered accountant to attest the batch's DVA",
+			Description: "Emails the CA a one-time link valid for 14 days. INDIA batches with domestic cells only; a batch has " +
+				"at most one pending request.",
+			Body:      services.RequestPLIAuditRequest{},
+			Responses: created(object(prop("audit", nullable(typeOf(models.PLIAudit{}))))),
+			Errors:    []int{http.StatusConflict},
+		},
+		{
+			Pattern: "GET /api/v1/batches/{id}/pli-audit", ID: "getPLIAudits", Tag: "pli-audit", Auth: authJWT,
+			Summary:   "DVA source, audit requests and audit log of a batch",
+			Responses: ok(models.PLIAuditHistory{}),
+		},
+		{
+			Pattern: "POST /api/v1/batches/{id}/pli-audit/{audit}/cancel", ID: "cancelPLIAudit", Tag: "pli-audit", Auth: authJWT,
+			Summary:   "Withdraw a pending audit request",
+			Responses: ok(object(prop("audit", nullable(typeOf(models.PLIAudit{}))))),
+			Errors:    []int{http.StatusConflict},
+		},
+		{
+			Pattern: "GET /api/v1/batches/{id}/pli-audit/certificate", ID: "getPLICertificate", Tag: "pli-audit", Auth: authJWT,
+			Summary:   "Download the signed CA certificate of the latest attestation",
+			Responses: download("application/pdf", "Signed CA certificate PDF"),
+		},
+		{
+			Pattern: "GET /api/v1/pli-audit/attest", ID: "getPLIAttestation", Tag: "pli-audit",
+			Summary:   "Audit request behind an attestation link",
+			Query:     []*Parameter{requiredQuery("token", "Token from the audit request email", str())},
+			Responses: ok(services.PLIAuditInvitation{}),
+			Errors:    []int{http.StatusNotFound},
+		},
+		{
+			Pattern: "POST /api/v1/pli-audit/attest", ID: "attestPLIAudit", Tag: "pli-audit",
+			Summary: "Attest the DVA as the chartered accountant",
+			Description: "Stores the signed certificate with its SHA-256, sets the batch's dva_source to AUDITED with the attested " +
+				"value and pli_compliant to whether it is at least 50%, and invalidates the link. The UDIN must embed the " +
+				"membership number.",
+			Multipart: fileUpload(
+				prop("token", str()),
+				prop("audited_dva", &Schema{Type: "string", Description: "Audited DVA %, 0-100"}),
+				prop("ca_membership_no", &Schema{Type: "string", Description: "ICAI membership number (6 digits)"}),
+				prop("udin", &Schema{Type: "string", Description: "UDIN of the certificate (18 characters)"}),
+				opt("ca_name", str()),
+			),
+			Responses: ok(object(prop("audit", nullable(typeOf(models.PLIAudit{}))))),
+			Errors:    []int{http.StatusNotFound, http.StatusConflict},
+		},
+
+		// ============================================
+		// COMPLIANCE READINESS
+		// ============================================
+		{
+			Pattern: "GET /api/v1/batches/{id}/compliance", ID: "getBatchCompliance", Tag: "compliance", Auth: authJWT,
+			Summary: "Compliance readiness checklist of a batch for its target markets",
+			Description: "Runs the rule pack of each market (GET /markets): HSN code and IEC for customs, CE marking, " +
+				"EU representative, battery category, carbon footprint, material composition and passport data for the EU, BIS " +
+				"R-number and a verified EPR certificate for India, UL listing, UN 38.3 and IRA sourcing for the US, UKCA and a UK responsible person for " +
+				"the UK, and the CA audit for PLI claims. The battery category decides which rules apply. BLOCKING failures stop shipment; ADVISORY ones lower the score only. " +
+				"Checks several markets share are listed once.",
+			Query: []*Parameter{queryParam("market",
+				"Market, or comma-separated markets; GLOBAL means INDIA and EU (default: the batch's markets)", str())},
+			Responses: ok(models.ComplianceReport{}),
+			Errors:    []int{http.StatusNotFound},
+		},
+		{
+			Pattern: "GET /api/v1/markets", ID: "listMarketRules", Tag: "compliance", Auth: authJWT,
+			Summary:     "Rule pack of every target market",
+			Description: "Required fields, accepted certifications and expected documents per market, with each rule's checklist severity.",
+			Responses:   ok(object(prop("markets", arrayOf(typeOf(models.MarketRulePackInfo{}))))),
+		},
+		{
+			Pattern: "GET /api/v1/battery-categories", ID: "listBatteryCategories", Tag: "compliance", Auth: authJWT,
+			Summary: "EU obligations of every battery category",
+			Description: "Passport obligation (LMT, EV, industrial over 2 kWh), carbon footprint deadline, labelling requirements, " +
+				"collection targets and the India EPR category each battery category maps to.",
+			Responses: ok(object(prop("categories", arrayOf(typeOf(models.BatteryCategoryRules{}))))),
+		},
+
+		// ============================================
+		// EPR ANNUAL RETURN
+		// ============================================
+		{
+			Pattern: "GET /api/v1/epr/targets", ID: "getEPRTargets", Tag: "epr", Auth: authJWT,
+			Summary:   "EPR recycling target per battery category, defaults included",
+			Responses: ok(object(prop("targets", arrayOf(typeOf(models.EPRTarget{}))))),
+		},
+		{
+			Pattern: "PUT /api/v1/epr/targets", ID: "updateEPRTargets", Tag: "epr", Auth: authJWT,
+			Summary:   "Set the EPR targets of the listed categories",
+			Body:      handlers.UpdateEPRTargetsRequest{},
+			Responses: ok(object(prop("targets", arrayOf(typeOf(models.EPRTarget{}))))),
+		},
+		{
+			Pattern: "GET /api/v1/epr/annual-return", ID: "getEPRAnnualReturn", Tag: "epr", Auth: authJWT,
+			Summary: "EPR annual return for a financial year (April-March)",
+			Description: "Aggregates passports placed on the market (shipped, or manufactured if not shipped) by BWM category " +
+				"(specs.epr_category, or PORTABLE up to 5 kg) and chemistry, with weights from specs.ratings. Passports recycled " +
+				"during the year are credited against each category's obligation: target % of the weight placed on the market.",
+			Query:     []*Parameter{financialYearQuery},
+			Responses: ok(models.EPRAnnualReturn{}),
+		},
+		{
+			Pattern: "GET /api/v1/epr/annual-return/export", ID: "exportEPRAnnualReturn", Tag: "epr", Auth: authJWT,
+			Summary: "Download the EPR annual return as XLSX or PDF",
+			Query: []*Parameter{financialYearQuery,
+				queryParam("format", "Export format (default: xlsx)", enum("xlsx", "pdf"))},
+			Responses: download("application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+				"Annual return workbook (producer, quantities placed, obligations); application/pdf with format=pdf"),
+		},
+
+		// ============================================
+		// WARRANTY CLAIMS
+		// ============================================
+		{
+			Pattern: "GET /api/v1/warranty-claims", ID: "listWarrantyClaims", Tag: "warranty", Auth: authJWT,
+			Summary:   "List warranty claims, newest first",
+			Query:     withPaging(queryParam("status", "Claim status", claimStatusEnum)),
+			Responses: ok(paged("claims", nullable(typeOf(models.WarrantyClaim{})))),
+		},
+		{
+			Pattern: "GET /api/v1/warranty-claims/{id}", ID: "getWarrantyClaim", Tag: "warranty", Auth: authJWT,
+			Summary:   "Get a warranty claim",
+			Responses: ok(object(prop("claim", nullable(typeOf(models.WarrantyClaim{}))))),
+			Errors:    []int{http.StatusNotFound},
+		},
+		{
+			Pattern: "GET /api/v1/warranty-claims/{id}/photos/{name}", ID: "getWarrantyClaimPhoto", Tag: "warranty", Auth: authJWT,
+			Summary:   "Download a claim photo",
+			Responses: download("application/octet-stream", "Photo file"),
+			Errors:    []int{http.StatusNotFound},
+		},
+		{
+			Pattern: "POST /api/v1/warranty-claims/{id}/approve", ID: "approveWarrantyClaim", Tag: "warranty", Auth: authJWT,
+			Summary:     "Approve a warranty claim",
+			Description: "Issues an RMA number and emails the technician. The passport stays RETURN_REQUESTED until the unit is received.",
+			Body:        handlers.ClaimDecisionRequest{},
+			Responses:   ok(object(prop("claim", nullable(typeOf(models.WarrantyClaim{}))))),
+			Errors:      []int{http.StatusNotFound, http.StatusConflict},
+		},
+		{
+			Pattern: "POST /api/v1/warranty-claims/{id}/reject", ID: "rejectWarrantyClaim", Tag: "warranty", Auth: authJWT,
+			Summary:     "Reject a warranty claim",
+			Description: "A note is required and is emailed to the technician. The passport moves back to IN_SERVICE.",
+			Body:        handlers.ClaimDecisionRequest{},
+			Responses:   ok(object(prop("claim", nullable(typeOf(models.WarrantyClaim{}))))),
+			Errors:      []int{http.StatusNotFound, http.StatusConflict},
+		},
+		{
+			Pattern: "POST /api/v1/warranty-claims/{id}/receive", ID: "receiveWarrantyClaim", Tag: "warranty", Auth: authJWT,
+			Summary:     "Record receipt of a returned unit",
+			Description: "Marks an approved claim RECEIVED and moves the passport to RETURNED.",
+			Responses:   ok(object(prop("claim", nullable(typeOf(models.WarrantyClaim{}))))),
+			Errors:      []int{http.StatusNotFound, http.StatusConflict},
+		},
+
+		// ============================================
+		// TEMPLATES
+		// ============================================
+		{
+			Pattern: "POST /api/v1/templates", ID: "createTemplate", Tag: "templates", Auth: authJWT,
+			Summary:   "Save a batch spec template",
+			Body:      models.CreateTemplateRequest{},
+			Responses: created(object(prop("template", nullable(typeOf(models.Template{}))), specWarnings())),
+		},
+		{
+			Pattern: "GET /api/v1/templates", ID: "listTemplates", Tag: "templates", Auth: authJWT,
+			Summary:   "List templates",
+			Query:     []*Parameter{tenantQuery},
+			Responses: ok(object(prop("templates", arrayOf(nullable(typeOf(models.Template{})))), prop("count", integer()))),
+		},
+		{
+			Pattern: "GET /api/v1/templates/{id}", ID: "getTemplate", Tag: "templates", Auth: authJWT,
+			Summary:   "Get a template",
+			Responses: ok(object(prop("template", nullable(typeOf(models.Template{}))))),
+		},
+		{
+			Pattern: "DELETE /api/v1/templates/{id}", ID: "deleteTemplate", Tag: "templates", Auth: authJWT,
+			Summary:   "Delete a template",
+			Responses: ok(message()),
+		},
+
+		// ============================================
+		// UTILITY & DASHBOARD
+		// ============================================
+		{
+			Pattern: "GET /api/v1/sample-csv", ID: "downloadSampleCSV", Tag: "batches",
+			Summary:   "Sample CSV for imports",
+			Query:     []*Parameter{queryParam("market", "INDIA or EU column layout; other values get the generic one", marketRegionEnum)},
+			Responses: download("text/csv", "Sample CSV"),
+		},
+		{
+			Pattern: "GET /api/v1/dashboard/stats", ID: "getDashboardStats", Tag: "dashboard", Auth: authJWT,
+			Summary:   "Overview statistics",
+			Query:     []*Parameter{tenantQuery},
+			Responses: ok(nullable(typeOf(models.DashboardStats{}))),
+		},
+		{
+			Pattern: "GET /api/v1/scans/feed", ID: "getScanFeed", Tag: "dashboard", Auth: authJWT,
+			Summary: "Recent QR scans",
+			Query: append([]*Parameter{
+				tenantQuery,
+				queryParam("limit", "Maximum results", integer()),
+				queryParam("cursor", "next_cursor from the previous page", str()),
+				serialPrefixQuery(),
+				marketRegionsQuery(),
+			}, createdRange...),
+			Responses: ok(paged("scans", typeOf(models.ScanFeedItem{}))),
+		},
+
+		// ============================================
+		// BILLING
+		// ============================================
+		{
+			Pattern: "GET /api/v1/billing/balance", ID: "getQuotaBalance", Tag: "billing", Auth: authJWT,
+			Summary:   "Activation quota balance",
+			Responses: ok(object(prop("quota_balance", integer()))),
+		},
+		{
+			Pattern: "GET /api/v1/billing/transactions", ID: "listTransactions", Tag: "billing", Auth: authJWT,
+			Summary: "Quota ledger",
+			Query: append([]*Parameter{
+				queryParam("limit", "Maximum results", integer()),
+				queryParam("cursor", "next_cursor from the previous page", str()),
+			}, createdRange...),
+			Responses: ok(paged("transactions", nullable(typeOf(models.Transaction{})))),
+		},
+		{
+			Pattern: "POST /api/v1/billing/top-up", ID: "topUpQuota", Tag: "billing", Auth: authJWT,
+			Summary: "Add quota without payment (development)",
+			Body:    object(prop("amount", integer())),
+			Responses: ok(object(
+				prop("message", str()),
+				prop("amount_added", integer()),
+				prop("quota_balance", integer()),
+			)),
+		},
+		{
+			Pattern: "GET /api/v1/billing/packages", ID: "listPackages", Tag: "billing", Auth: authJWT,
+			Summary:   "Quota packages for purchase",
+			Responses: ok(object(prop("packages", arrayOf(typeOf(handlers.Package{}))))),
+		},
+		{
+			Pattern: "POST /api/v1/billing/razorpay/order", ID: "createRazorpayOrder", Tag: "billing", Auth: authJWT,
+			Summary:   "Create a Razorpay order for a package",
+			Body:      object(prop("package_id", str())),
+			Responses: ok(nullable(typeOf(services.CreateOrderResponse{}))),
+		},
+		{
+			Pattern: "POST /api/v1/billing/razorpay/verify", ID: "verifyRazorpayPayment", Tag: "billing", Auth: authJWT,
+			Summary: "Verify a Razorpay payment and credit quota",
+			Body:    services.VerifyPaymentRequest{},
+			Responses: ok(object(
+				prop("success", boolean()),
+				prop("message", str()),
+				prop("quota_added", integer()),
+				prop("quota_balance", integer()),
+			)),
+		},
+
+		// ============================================
+		// DOCUMENTS
+		// ============================================
+		{
+			Pattern: "POST /api/v1/settings/upload-document", ID: "uploadDocument", Tag: "documents", Auth: authJWT,
+			Summary:   "Upload an EPR, BIS or PLI certificate (PDF)",
+			Multipart: fileUpload(prop("document_type", enum("epr", "bis", "pli"))),
+			Responses: ok(object(
+				prop("success", boolean()),
+				prop("path", str()),
+				prop("document_type", str()),
+				prop("message", str()),
+			)),
+		},
+		{
+			Pattern: "GET /api/v1/settings/documents/{type}", ID: "viewDocument", Tag: "documents", Auth: authJWT,
+			Summary:   "View an uploaded certificate",
+			Responses: download("application/pdf", "Certificate PDF"),
+		},
+		{
+			Pattern: "POST /api/v1/settings/upload-logo", ID: "uploadLogo", Tag: "documents", Auth: authJWT,
+			Summary:   "Upload the company logo",
+			Multipart: fileUpload(),
+			Responses: ok(object(prop("success", boolean()), prop("logo_url", str()), prop("message", str()))),
+		},
+		{
+			Pattern: "GET /api/v1/uploads/", ID: "getUpload", Tag: "documents",
+			Summary:   "Serve a tenant logo ({tenant_id}/logo.png or logo.jpg)",
+			Responses: download("application/octet-stream", "File contents"),
+		},
+		{
+			Pattern: "POST /api/v1/admin/verify-doc", ID: "adminVerifyDocument", Tag: "admin", Auth: authJWT,
+			Summary: "Approve or reject a tenant's certificate",
+			Body:    handlers.VerifyDocumentRequest{},
+			Responses: ok(object(
+				prop("success", boolean()),
+				prop("tenant_id", str()),
+				prop("doc_type", str()),
+				prop("new_status", str()),
+				prop("message", str()),
+			)),
+		},
+
+		// ============================================
+		// SCANS & MAGIC LINK
+		// ============================================
+		{
+			Pattern: "POST /api/v1/scans/record", ID: "recordScan", Tag: "passports",
+			Summary: "Record a QR scan from the public passport page",
+			Body:    handlers.RecordScanRequest{},
+			Responses: map[int]response{
+				http.StatusCreated: {Description: "Scan recorded", Schema: object(
+					prop("status", constant("recorded")),
+					prop("city", str()),
+					prop("country", str()),
+					prop("device", str()),
+				)},
+				http.StatusOK: {Description: "Duplicate scan ignored", Schema: object(
+					prop("status", constant("ignored")),
+					prop("message", str()),
+				)},
+			},
+		},
+		{
+			Pattern: "POST /api/v1/passport/{uuid}/transition", ID: "magicLinkTransition", Tag: "magic-link", Auth: authMagicLink,
+			Summary: "Transition a passport as a field partner",
+			Body:    handlers.MagicTransitionRequest{},
+			Responses: ok(object(
+				prop("success", boolean()),
+				prop("previous_status", str()),
+				prop("new_status", str()),
+				prop("actor", str()),
+				prop("role", str()),
+				prop("event_id", uuidStr()),
+				prop("points_awarded", integer()),
+			)),
+		},
+		{
+			Pattern: "GET /api/v1/passport/{uuid}/action-info", ID: "magicLinkActionInfo", Tag: "magic-link", Auth: authMagicLink,
+			Summary: "Passport summary and allowed actions for a magic link holder",
+			Query:   []*Parameter{queryParam("token", "Magic link token (alternative to the Authorization header)", str())},
+			Responses: ok(object(
+				prop("passport", object(prop("uuid", uuidStr()), prop("serial_number", str()), prop("status", str()))),
+				prop("actor", object(prop("email", str()), prop("role", str()))),
+				prop("allowed_transitions", arrayOf(str())),
+				opt("warranty", typeOf(models.WarrantyCoverage{})),
+				opt("open_claim", typeOf(models.WarrantyClaim{})),
+				opt("fault_codes", arrayOf(enum(models.FaultCodes...))),
+			)),
+		},
+		{
+			Pattern: "POST /api/v1/passport/{uuid}/warranty-claim", ID: "submitWarrantyClaim", Tag: "magic-link", Auth: authMagicLink,
+			Summary: "File a warranty claim as a technician",
+			Description: "Records the claim with its warranty eligibility (calendar months from installation, or shipment if never installed) " +
+				"and moves the passport to RETURN_REQUESTED. Up to 6 JPEG, PNG or WebP photos of 5MB each. A passport has at most one open claim.",
+			Multipart: object(
+				prop("fault_code", enum(models.FaultCodes...)),
+				prop("state_of_health", &Schema{Type: "number", Description: "State of health reading, 0-100"}),
+				opt("description", str()),
+				opt("photos", arrayOf(&Schema{Type: "string", Format: "binary"})),
+			),
+			Responses: created(object(
+				prop("success", boolean()),
+				prop("claim", nullable(typeOf(models.WarrantyClaim{}))),
+				prop("message", str()),
+			)),
+			Errors: []int{http.StatusConflict},
+		},
+		{
+			Pattern: "GET /api/v1/passport/{uuid}/due-diligence", ID: "magicLinkDueDiligence", Tag: "magic-link", Auth: authMagicLink,
+			Summary:     "Full due diligence disclosure for a partner with a legitimate interest",
+			Description: "Includes supplier names and audit reports, which the public passport withholds. Not available to the CUSTOMER role.",
+			Query:       []*Parameter{queryParam("token", "Magic link token (alternative to the Authorization header)", str())},
+			Responses:   ok(models.DueDiligenceDisclosure{}),
+			Errors:      []int{http.StatusNotFound},
+		},
+		{
+			Pattern: "GET /api/v1/passport/{uuid}/due-diligence/documents/{doc}", ID: "magicLinkAuditReport", Tag: "magic-link", Auth: authMagicLink,
+			Summary:   "Download an audit report as a partner",
+			Query:     []*Parameter{queryParam("token", "Magic link token (alternative to the Authorization header)", str())},
+			Responses: download("application/pdf", "Audit report PDF"),
+			Errors:    []int{http.StatusNotFound},
+		},
+
+		// ============================================
+		// API KEYS
+		// ============================================
+		{
+			Pattern: "POST /api/v1/api-keys", ID: "createAPIKey", Tag: "api-keys", Auth: authJWT,
+			Summary:     "Create an API key",
+			Description: "The full key is only returned once.",
+			Body:        models.CreateAPIKeyRequest{},
+			Responses:   created(models.APIKeyWithSecret{}),
+		},
+		{
+			Pattern: "GET /api/v1/api-keys", ID: "listAPIKeys", Tag: "api-keys", Auth: authJWT,
+			Summary:   "List API keys",
+			Responses: ok(object(prop("api_keys", arrayOf(nullable(typeOf(models.APIKey{})))), prop("count", integer()))),
+		},
+		{
+			Pattern: "GET /api/v1/api-keys/{id}", ID: "getAPIKey", Tag: "api-keys", Auth: authJWT,
+			Summary:   "Get an API key",
+			Responses: ok(object(prop("api_key", nullable(typeOf(models.APIKey{}))))),
+		},
+		{
+			Pattern: "GET /api/v1/api-keys/{id}/usage", ID: "getAPIKeyUsage", Tag: "api-keys", Auth: authJWT,
+			Summary:   "Request volume, error rate and endpoint breakdown",
+			Query:     []*Parameter{queryParam("days", "Look-back window in days (max 90)", integer())},
+			Responses: ok(object(prop("usage", nullable(typeOf(models.APIKeyUsageSummary{}))))),
+		},
+		{
+			Pattern: "GET /api/v1/api-keys/{id}/audit", ID: "getAPIKeyAudit", Tag: "api-keys", Auth: authJWT,
+			Summary: "Recent requests, known IPs and alerts",
+			Responses: ok(object(
+				prop("api_key_id", uuidStr()),
+				prop("recent_requests", arrayOf(typeOf(models.APIRequestLog{}))),
+				prop("known_ips", arrayOf(typeOf(models.APIKeyIP{}))),
+				prop("alerts", arrayOf(typeOf(models.APIKeyAlert{}))),
+			)),
+		},
+		{
+			Pattern: "PATCH /api/v1/api-keys/{id}", ID: "updateAPIKey", Tag: "api-keys", Auth: authJWT,
+			Summary:   "Update an API key's name, status, scopes or restrictions",
+			Body:      models.UpdateAPIKeyRequest{},
+			Responses: ok(message()),
+		},
+		{
+			Pattern: "DELETE /api/v1/api-keys/{id}", ID: "deleteAPIKey", Tag: "api-keys", Auth: authJWT,
+			Summary:   "Revoke an API key",
+			Responses: ok(message()),
+		},
+
+		// ============================================
+		// TRUSTED PARTNERS
+		// ============================================
+		{
+			Pattern: "POST /api/v1/partners/trusted", ID: "createTrustedPartner", Tag: "partners", Auth: authJWT,
+			Summary:   "Trust an email domain (Tier A)",
+			Body:      handlers.CreateTrustedPartnerRequest{},
+			Responses: created(object(prop("success", boolean()), prop("partner", nullable(typeOf(repository.TrustedPartner{}))))),
+			Errors:    []int{http.StatusConflict},
+		},
+		{
+			Pattern: "GET /api/v1/partners/trusted", ID: "listTrustedPartners", Tag: "partners", Auth: authJWT,
+			Summary: "List trusted partner domains",
+			Responses: ok(object(
+				prop("success", boolean()),
+				prop("partners", arrayOf(nullable(typeOf(repository.TrustedPartner{})))),
+				prop("count", integer()),
+			)),
+		},
+		{
+			Pattern: "DELETE /api/v1/partners/trusted/{id}", ID: "deleteTrustedPartner", Tag: "partners", Auth: authJWT,
+			Summary:   "Remove a trusted partner domain",
+			Responses: ok(successMessage()),
+		},
+		{
+			Pattern: "POST /api/v1/partners/codes", ID: "createPartnerCode", Tag: "partners", Auth: authJWT,
+			Summary:   "Create a partner code (Tier B)",
+			Body:      handlers.CreatePartnerCodeRequest{},
+			Responses: created(object(prop("success", boolean()), prop("code", nullable(typeOf(repository.PartnerCode{}))))),
+			Errors:    []int{http.StatusConflict},
+		},
+		{
+			Pattern: "GET /api/v1/partners/codes", ID: "listPartnerCodes", Tag: "partners", Auth: authJWT,
+			Summary: "List partner codes",
+			Responses: ok(object(
+				prop("success", boolean()),
+				prop("codes", arrayOf(nullable(typeOf(repository.PartnerCode{})))),
+				prop("count", integer()),
+			)),
+		},
+		{
+			Pattern: "DELETE /api/v1/partners/codes/{id}", ID: "deactivatePartnerCode", Tag: "partners", Auth: authJWT,
+			Summary:   "Deactivate a partner code",
+			Responses: ok(successMessage()),
+		},
+
+		// ============================================
+		// REWARDS
+		// ============================================
+		{
+			Pattern: "GET /api/v1/rewards/balance", ID: "getRewardBalance", Tag: "rewards", Auth: authMagicLink,
+			Summary: "Points balance for the magic link holder",
+			Query:   []*Parameter{queryParam("token", "Magic link token (alternative to the Authorization header)", str())},
+			Responses: ok(object(
+				prop("email", str()),
+				prop("total_points", integer()),
+				prop("loyalty_level", str()),
+				prop("stats", roleStats),
+				prop("last_activity", dateTime()),
+			)),
+		},
+		{
+			Pattern: "GET /api/v1/rewards/leaderboard", ID: "getRewardLeaderboard", Tag: "rewards",
+			Summary: "Top partners by points (emails masked)",
+			Query:   []*Parameter{tenantQuery, queryParam("limit", "Maximum results", integer())},
+			Responses: ok(object(
+				prop("leaderboard", arrayOf(object(
+					prop("rank", integer()),
+					prop("email", str()),
+					prop("total_points", integer()),
+					prop("loyalty_level", str()),
+					prop("stats", roleStats),
+				))),
+				prop("count", integer()),
+			)),
+		},
+		{
+			Pattern: "GET /api/v1/rewards/history", ID: "getRewardHistory", Tag: "rewards", Auth: authMagicLink,
+			Summary: "Points history for the magic link holder",
+			Query:   []*Parameter{queryParam("token", "Magic link token (alternative to the Authorization header)", str()), queryParam("limit", "Maximum results", integer())},
+			Responses: ok(object(
+				prop("email", str()),
+				prop("history", arrayOf(nullable(typeOf(models.RewardLedger{})))),
+				prop("count", integer()),
+			)),
+		},
+
+		// ============================================
+		// EXTERNAL API (X-API-Key)
+		// ============================================
+		{
+			Pattern: "GET /api/v1/external/passports", ID: "externalListPassports", Tag: "external",
+			Auth: authAPIKey, Scope: models.ScopePassportsRead,
+			Summary: "List passports across batches",
+			Query: withPaging(append([]*Parameter{
+				queryParam("batch_id", "Only passports in this batch", uuidStr()),
+				queryParam("status", "Lifecycle status", str()),
+				serialPrefixQuery(),
+				queryParam("serial", "Deprecated alias of serial_prefix", str()),
+				marketRegionsQuery(),
+				chemistryQuery(),
+			}, createdRange...)...),
+			Responses: ok(paged("passports", nullable(batchPassport))),
+		},
+		{
+			Pattern: "GET /api/v1/external/passports/search", ID: "externalSearchPassports", Tag: "external",
+			Auth: authAPIKey, Scope: models.ScopePassportsRead,
+			Summary:   "Search passports",
+			Query:     searchQuery(),
+			Responses: ok(searchResults()),
+		},
+		{
+			Pattern: "GET /api/v1/external/passports/{uuid}", ID: "externalGetPassport", Tag: "external",
+			Auth: authAPIKey, Scope: models.ScopePassportsRead,
+			Summary:   "Get a passport with batch specs",
+			Responses: ok(object(prop("passport", nullable(typeOf(models.PassportWithSpecs{}))))),
+		},
+		{
+			Pattern: "GET /api/v1/external/passports/{uuid}/events", ID: "externalListPassportEvents", Tag: "external",
+			Auth: authAPIKey, Scope: models.ScopePassportsRead,
+			Summary:   "Lifecycle audit trail",
+			Responses: ok(passportEvents),
+		},
+		{
+			Pattern: "GET /api/v1/external/passports/{uuid}/transitions", ID: "externalListAllowedTransitions", Tag: "external",
+			Auth: authAPIKey, Scope: models.ScopePassportsRead,
+			Summary: "Next statuses this key's actor role may set",
+			Responses: ok(object(
+				prop("current_status", str()),
+				prop("actor_role", str()),
+				prop("allowed_transitions", arrayOf(str())),
+			)),
+		},
+		{
+			Pattern: "POST /api/v1/external/passports/{uuid}/transition", ID: "externalTransitionPassport", Tag: "external",
+			Auth: authAPIKey, Scope: models.ScopePassportsTransition,
+			Summary:     "Transition a passport",
+			Description: "The API key is recorded as the actor; its actor_role limits the allowed transitions.",
+			Body:        handlers.ExternalTransitionRequest{},
+			Responses:   ok(transitionResult),
+			Errors:      []int{http.StatusConflict},
+			Idempotent:  true,
+		},
+		{
+			Pattern: "POST /api/v1/external/passports/bulk/transition", ID: "externalBulkTransitionPassports", Tag: "external",
+			Auth: authAPIKey, Scope: models.ScopePassportsTransition,
+			Summary:    "Transition up to 500 passports",
+			Body:       handlers.ExternalBulkTransitionRequest{},
+			Responses:  ok(bulkResult),
+			Idempotent: true,
+		},
+		{
+			Pattern: "GET /api/v1/external/batches", ID: "externalListBatches", Tag: "external",
+			Auth: authAPIKey, Scope: models.ScopePassportsRead,
+			Summary: "List batches",
+			Query: withPaging(append([]*Parameter{
+				queryParam("status", "Batch status", batchStatusEnum),
+				marketRegionsQuery(),
+				queryParam("q", "Batch name contains", str()),
+				chemistryQuery(),
+			}, createdRange...)...),
+			Responses: ok(paged("batches", nullable(typeOf(models.Batch{})))),
+		},
+		{
+			Pattern: "POST /api/v1/external/batches", ID: "externalCreateBatch", Tag: "external",
+			Auth: authAPIKey, Scope: models.ScopeBatchesWrite,
+			Summary: "Create a DRAFT batch",
+			Description: "Validated like POST /batches: spec issues, then each target market's rule pack (GET /markets). " +
+				"Failed ERROR rules refuse the batch (400 with checks); other failures are returned as market_warnings. " +
+				"India batches get an ESTIMATED domestic value addition computed from specs.sale_price_inr and import_cost_inr.",
+			Body: handlers.ExternalCreateBatchRequest{},
+			Responses: created(object(
+				prop("id", str()),
+				prop("batch_name", str()),
+				prop("message", str()),
+				specWarnings(),
+				opt("market_warnings", arrayOf(typeOf(models.ComplianceCheck{}))),
+			)),
+			Idempotent: true,
+		},
+		{
+			Pattern: "GET /api/v1/external/batches/{id}", ID: "externalGetBatch", Tag: "external",
+			Auth: authAPIKey, Scope: models.ScopePassportsRead,
+			Summary:   "Get a batch",
+			Responses: ok(batchDetail),
+		},
+		{
+			Pattern: "PATCH /api/v1/external/batches/{id}", ID: "externalUpdateBatch", Tag: "external",
+			Auth: authAPIKey, Scope: models.ScopeBatchesWrite,
+			Summary: "Update a DRAFT batch",
+			Description: "The estimated domestic value addition is recalculated from specs.sale_price_inr and import_cost_inr " +
+				"when specs, cell_source or pli_compliant change, and pli_compliant is checked against it; domestic_value_add " +
+				"cannot be sent. A CA-attested DVA only changes through a new PLI audit.",
+			Body:      handlers.ExternalUpdateBatchRequest{},
+			Responses: ok(object(prop("batch", nullable(typeOf(models.Batch{}))), prop("message", str()), specWarnings())),
+			Errors:    []int{http.StatusConflict},
+		},
+		{
+			Pattern: "POST /api/v1/external/batches/{id}/activate", ID: "externalActivateBatch", Tag: "external",
+			Auth: authAPIKey, Scope: models.ScopeBatchesWrite,
+			Summary:    "Activate a batch (uses one quota unit)",
+			Query:      []*Parameter{enforceComplianceQuery},
+			Responses:  ok(activation),
+			Errors:     []int{http.StatusPaymentRequired, http.StatusConflict},
+			Idempotent: true,
+		},
+		{
+			Pattern: "GET /api/v1/external/batches/{id}/passports", ID: "externalListBatchPassports", Tag: "external",
+			Auth: authAPIKey, Scope: models.ScopePassportsRead,
+			Summary: "List passports in a batch",
+			Query: withPaging(append([]*Parameter{
+				queryParam("status", "Lifecycle status", str()),
+				serialPrefixQuery(),
+				queryParam("serial", "Deprecated alias of serial_prefix", str()),
+				marketRegionsQuery(),
+				chemistryQuery(),
+			}, createdRange...)...),
+			Responses: ok(paged("passports", nullable(batchPassport))),
+		},
+		{
+			Pattern: "POST /api/v1/external/batches/{id}/passports", ID: "externalCreatePassports", Tag: "external",
+			Auth: authAPIKey, Scope: models.ScopeBatchesWrite,
+			Summary: "Add up to 500 passports to a batch",
+			Body:    handlers.ExternalCreatePassportsRequest{},
+			Responses: created(object(
+				prop("created", integer()),
+				prop("batch_id", str()),
+				prop("batch_name", str()),
+				prop("message", str()),
+			)),
+			Errors:     []int{http.StatusConflict},
+			Idempotent: true,
+		},
+		{
+			Pattern: "GET /api/v1/external/batches/{id}/export", ID: "externalExportBatchCSV", Tag: "external",
+			Auth: authAPIKey, Scope: models.ScopePassportsRead,
+			Summary:   "Export passports as CSV",
+			Responses: download("text/csv", "Serial number export"),
+		},
+		{
+			Pattern: "GET /api/v1/external/batches/{id}/labels", ID: "externalDownloadLabels", Tag: "external",
+			Auth: authAPIKey, Scope: models.ScopeLabelsRead,
+			Summary:   "Download printable labels",
+			Responses: download("application/pdf", "Label sheet PDF"),
+		},
+		{
+			Pattern: "POST /api/v1/external/graphql", ID: "externalGraphql", Tag: "external",
+			Auth: authAPIKey, Scope: models.ScopePassportsRead,
+			Summary:     "Run a GraphQL query",
+			Description: "Same schema as POST /api/v1/graphql. Results follow the key's batch and market restrictions.",
+			Body:        graph.Request{},
+			Responses:   graphqlResult(),
+		},
+	}
+}
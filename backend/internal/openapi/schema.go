@@ -0,0 +1,218 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// JSON SCHEMA (OpenAPI 3.1 dialect)
+// ============================================================================
+
+// Schema is the subset of JSON Schema used by this API.
+// Type is a string or, for nullable values, a []string including "null".
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"` // false or *Schema
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+
+	goType interface{} // Placeholder for a Go type, resolved to a $ref at build time
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	uuidType    = reflect.TypeOf(uuid.UUID{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaRegistry turns Go types into component schemas, following encoding/json rules
+// so the documented shape is exactly what the handlers emit
+type schemaRegistry struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// ref returns a $ref to the component for a struct value, registering it on first use
+func (r *schemaRegistry) ref(v interface{}) *Schema {
+	return r.schemaFor(reflect.TypeOf(v))
+}
+
+func (r *schemaRegistry) schemaFor(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	case rawJSONType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(r.schemaFor(t.Elem()))
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		// nil slices encode as null
+		return &Schema{Type: []string{"array", "null"}, Items: r.schemaFor(t.Elem())}
+	case reflect.Array:
+		return &Schema{Type: "array", Items: r.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: []string{"object", "null"}, AdditionalProperties: r.schemaFor(t.Elem())}
+	case reflect.Struct:
+		return r.component(t)
+	}
+	return &Schema{}
+}
+
+// component registers a named struct schema and returns a reference to it
+func (r *schemaRegistry) component(t reflect.Type) *Schema {
+	if name, ok := r.names[t]; ok {
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+
+	name := t.Name()
+	if _, taken := r.schemas[name]; taken || name == "" {
+		// Same type name in two packages, e.g. models.CreateBatchRequest
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	r.names[t] = name
+
+	// Reserve the name before walking fields so recursive types terminate
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+	r.schemas[name] = s
+	r.addFields(s, t)
+
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// addFields adds a struct's JSON fields to s, flattening embedded structs like encoding/json
+func (r *schemaRegistry) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				r.addFields(s, ft)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		s.Properties[name] = r.schemaFor(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// ============================================================================
+// SCHEMA BUILDERS (for inline response envelopes)
+// ============================================================================
+
+// field is a named property of an inline object schema
+type field struct {
+	name     string
+	schema   *Schema
+	optional bool
+}
+
+// prop is a required property
+func prop(name string, s *Schema) field { return field{name: name, schema: s} }
+
+// opt is a property the handler only sometimes includes
+func opt(name string, s *Schema) field { return field{name: name, schema: s, optional: true} }
+
+// object builds a closed object schema: unknown properties are contract violations
+func object(fields ...field) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+	for _, f := range fields {
+		s.Properties[f.name] = f.schema
+		if !f.optional {
+			s.Required = append(s.Required, f.name)
+		}
+	}
+	return s
+}
+
+// arrayOf is a list that may be null when the handler returns a nil slice
+func arrayOf(items *Schema) *Schema {
+	return &Schema{Type: []string{"array", "null"}, Items: items}
+}
+
+// typeOf embeds a Go struct's component schema inside an inline schema
+func typeOf(v interface{}) *Schema { return &Schema{goType: v} }
+
+// nullable allows JSON null in addition to the given schema
+func nullable(s *Schema) *Schema {
+	if s.Ref != "" || s.goType != nil {
+		return &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+	}
+	if t, ok := s.Type.(string); ok {
+		copied := *s
+		copied.Type = []string{t, "null"}
+		return &copied
+	}
+	return s
+}
+
+func str() *Schema              { return &Schema{Type: "string"} }
+func integer() *Schema          { return &Schema{Type: "integer"} }
+func boolean() *Schema          { return &Schema{Type: "boolean"} }
+func uuidStr() *Schema          { return &Schema{Type: "string", Format: "uuid"} }
+func dateTime() *Schema         { return &Schema{Type: "string", Format: "date-time"} }
+func constant(v string) *Schema { return &Schema{Type: "string", Enum: []interface{}{v}} }
+
+// enum is a string limited to the given values
+func enum(values ...string) *Schema {
+	s := &Schema{Type: "string"}
+	for _, v := range values {
+		s.Enum = append(s.Enum, v)
+	}
+	return s
+}
@@ -0,0 +1,203 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// RESPONSE VALIDATION
+// ============================================================================
+
+// ValidateResponse checks a JSON response body against the documented schema for
+// a route pattern and status code. Binary responses are not checked.
+func ValidateResponse(pattern string, status int, body []byte) error {
+	doc := Spec()
+	method, path, _ := strings.Cut(pattern, " ")
+
+	item, ok := doc.Paths[muxPathToOpenAPI(path)]
+	if !ok {
+		return fmt.Errorf("%s: route not documented", pattern)
+	}
+	op, ok := (*item)[strings.ToLower(method)]
+	if !ok {
+		return fmt.Errorf("%s: method not documented", pattern)
+	}
+	res, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		return fmt.Errorf("%s: status %d not documented", pattern, status)
+	}
+	media, ok := res.Content[contentJSON]
+	if !ok {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("%s %d: response is not JSON: %w", pattern, status, err)
+	}
+
+	v := validator{schemas: doc.Components.Schemas}
+	v.check(media.Schema, value, "$")
+	if len(v.errs) > 0 {
+		return fmt.Errorf("%s %d: %s", pattern, status, strings.Join(v.errs, "; "))
+	}
+	return nil
+}
+
+// validator implements the JSON Schema keywords the document uses
+type validator struct {
+	schemas map[string]*Schema
+	errs    []string
+}
+
+func (v *validator) fail(path, format string, args ...interface{}) {
+	v.errs = append(v.errs, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (v *validator) check(s *Schema, value interface{}, path string) {
+	if s == nil {
+		return
+	}
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		target, ok := v.schemas[name]
+		if !ok {
+			v.fail(path, "unknown schema %s", s.Ref)
+			return
+		}
+		v.check(target, value, path)
+		return
+	}
+
+	if len(s.AnyOf) > 0 {
+		var firstErrs []string
+		for i, alt := range s.AnyOf {
+			sub := validator{schemas: v.schemas}
+			sub.check(alt, value, path)
+			if len(sub.errs) == 0 {
+				firstErrs = nil
+				break
+			}
+			if i == 0 {
+				firstErrs = sub.errs
+			}
+		}
+		if firstErrs != nil {
+			v.errs = append(v.errs, firstErrs...)
+		}
+		return
+	}
+
+	if s.Type != nil && !typeMatches(s.Type, value) {
+		v.fail(path, "expected %v, got %s", s.Type, jsonType(value))
+		return
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if e == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(path, "%v is not one of %v", value, s.Enum)
+		}
+	}
+
+	switch val := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := val[name]; !ok {
+				v.fail(path, "missing required property %q", name)
+			}
+		}
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if ps, ok := s.Properties[k]; ok {
+				v.check(ps, val[k], path+"."+k)
+				continue
+			}
+			switch ap := s.AdditionalProperties.(type) {
+			case bool:
+				if !ap {
+					v.fail(path, "undocumented property %q", k)
+				}
+			case *Schema:
+				v.check(ap, val[k], path+"."+k)
+			}
+		}
+	case []interface{}:
+		for i, item := range val {
+			v.check(s.Items, item, path+"["+strconv.Itoa(i)+"]")
+		}
+	}
+}
+
+func typeMatches(t interface{}, value interface{}) bool {
+	switch t := t.(type) {
+	case string:
+		return typeIs(t, value)
+	case []string:
+		for _, name := range t {
+			if typeIs(name, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func typeIs(name string, value interface{}) bool {
+	switch name {
+	case "null":
+		return value == nil
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+	return false
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "unknown"
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,11 +17,65 @@ import (
 // API KEY REPOSITORY
 // ============================================================================
 
+// ErrAPIKeyNotFound is returned when no (active) key matches the ID or prefix
+var ErrAPIKeyNotFound = errors.New("API key not found")
+
+// apiKeyColumns is the column list read by scanAPIKey
+const apiKeyColumns = `id, tenant_id, name, key_hash, key_prefix, scope, rate_limit_tier, last_used_at, expires_at, is_active, created_at,
+		       scopes, allowed_batch_ids, allowed_markets, allowed_cidrs, actor_role`
+
+// scanAPIKey scans a row selected with apiKeyColumns
+func scanAPIKey(row pgx.Row) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	var actorRole *string
+	if err := row.Scan(
+		&key.ID,
+		&key.TenantID,
+		&key.Name,
+		&key.KeyHash,
+		&key.KeyPrefix,
+		&key.Scope,
+		&key.RateLimitTier,
+		&key.LastUsedAt,
+		&key.ExpiresAt,
+		&key.IsActive,
+		&key.CreatedAt,
+		&key.Scopes,
+		&key.AllowedBatchIDs,
+		&key.AllowedMarkets,
+		&key.AllowedCIDRs,
+		&actorRole,
+	); err != nil {
+		return nil, err
+	}
+	if actorRole != nil {
+		key.ActorRole = *actorRole
+	}
+	return key, nil
+}
+
+// nonNilStrings keeps NOT NULL array columns from receiving NULL
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// nonNilUUIDs keeps NOT NULL array columns from receiving NULL
+func nonNilUUIDs(values []uuid.UUID) []uuid.UUID {
+	if values == nil {
+		return []uuid.UUID{}
+	}
+	return values
+}
+
 // CreateAPIKey creates a new API key
 func (r *Repository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
 	query := `
-		INSERT INTO api_keys (id, tenant_id, name, key_hash, key_prefix, scope, rate_limit_tier, expires_at, is_active, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO api_keys (id, tenant_id, name, key_hash, key_prefix, scope, rate_limit_tier, expires_at, is_active, created_at,
+		                      scopes, allowed_batch_ids, allowed_markets, allowed_cidrs, actor_role)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 	`
 
 	_, err := r.db.Pool.Exec(ctx, query,
@@ -33,6 +89,11 @@ func (r *Repository) CreateAPIKey(ctx context.Context, key *models.APIKey) error
 		key.ExpiresAt,
 		key.IsActive,
 		key.CreatedAt,
+		nonNilStrings(key.Scopes),
+		nonNilUUIDs(key.AllowedBatchIDs),
+		nonNilStrings(key.AllowedMarkets),
+		nonNilStrings(key.AllowedCIDRs),
+		nullIfEmpty(key.ActorRole),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create API key: %w", err)
@@ -44,7 +105,7 @@ func (r *Repository) CreateAPIKey(ctx context.Context, key *models.APIKey) error
 // ListAPIKeys returns all API keys for a tenant
 func (r *Repository) ListAPIKeys(ctx context.Context, tenantID uuid.UUID) ([]*models.APIKey, error) {
 	query := `
-		SELECT id, tenant_id, name, key_prefix, scope, rate_limit_tier, last_used_at, expires_at, is_active, created_at
+		SELECT ` + apiKeyColumns + `
 		FROM api_keys
 		WHERE tenant_id = $1
 		ORDER BY created_at DESC
@@ -58,19 +119,8 @@ func (r *Repository) ListAPIKeys(ctx context.Context, tenantID uuid.UUID) ([]*mo
 
 	var keys []*models.APIKey
 	for rows.Next() {
-		key := &models.APIKey{}
-		if err := rows.Scan(
-			&key.ID,
-			&key.TenantID,
-			&key.Name,
-			&key.KeyPrefix,
-			&key.Scope,
-			&key.RateLimitTier,
-			&key.LastUsedAt,
-			&key.ExpiresAt,
-			&key.IsActive,
-			&key.CreatedAt,
-		); err != nil {
+		key, err := scanAPIKey(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan API key: %w", err)
 		}
 		keys = append(keys, key)
@@ -82,28 +132,15 @@ func (r *Repository) ListAPIKeys(ctx context.Context, tenantID uuid.UUID) ([]*mo
 // GetAPIKeyByID retrieves an API key by ID
 func (r *Repository) GetAPIKeyByID(ctx context.Context, id uuid.UUID) (*models.APIKey, error) {
 	query := `
-		SELECT id, tenant_id, name, key_hash, key_prefix, scope, rate_limit_tier, last_used_at, expires_at, is_active, created_at
+		SELECT ` + apiKeyColumns + `
 		FROM api_keys
 		WHERE id = $1
 	`
 
-	key := &models.APIKey{}
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
-		&key.ID,
-		&key.TenantID,
-		&key.Name,
-		&key.KeyHash,
-		&key.KeyPrefix,
-		&key.Scope,
-		&key.RateLimitTier,
-		&key.LastUsedAt,
-		&key.ExpiresAt,
-		&key.IsActive,
-		&key.CreatedAt,
-	)
+	key, err := scanAPIKey(r.db.Pool.QueryRow(ctx, query, id))
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("API key not found")
+			return nil, ErrAPIKeyNotFound
 		}
 		return nil, fmt.Errorf("failed to get API key: %w", err)
 	}
@@ -111,31 +148,18 @@ func (r *Repository) GetAPIKeyByID(ctx context.Context, id uuid.UUID) (*models.A
 	return key, nil
 }
 
-// GetAPIKeyByPrefix retrieves an API key by its prefix (for validation lookup)
+// GetAPIKeyByPrefix retrieves an API key by its unique prefix (for validation lookup)
 func (r *Repository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
 	query := `
-		SELECT id, tenant_id, name, key_hash, key_prefix, scope, rate_limit_tier, last_used_at, expires_at, is_active, created_at
+		SELECT ` + apiKeyColumns + `
 		FROM api_keys
 		WHERE key_prefix = $1 AND is_active = true
 	`
 
-	key := &models.APIKey{}
-	err := r.db.Pool.QueryRow(ctx, query, prefix).Scan(
-		&key.ID,
-		&key.TenantID,
-		&key.Name,
-		&key.KeyHash,
-		&key.KeyPrefix,
-		&key.Scope,
-		&key.RateLimitTier,
-		&key.LastUsedAt,
-		&key.ExpiresAt,
-		&key.IsActive,
-		&key.CreatedAt,
-	)
+	key, err := scanAPIKey(r.db.Pool.QueryRow(ctx, query, prefix))
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("API key not found")
+			return nil, ErrAPIKeyNotFound
 		}
 		return nil, fmt.Errorf("failed to get API key: %w", err)
 	}
@@ -143,36 +167,25 @@ func (r *Repository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*mod
 	return key, nil
 }
 
-// GetAllActiveAPIKeys returns all active keys (for validation - we'll check hash on each)
-func (r *Repository) GetAllActiveAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+// GetAPIKeysByLegacyPrefix returns active keys sharing a legacy 4-char prefix.
+// Only keys issued before unique prefixes need this fallback.
+func (r *Repository) GetAPIKeysByLegacyPrefix(ctx context.Context, prefix string) ([]*models.APIKey, error) {
 	query := `
-		SELECT id, tenant_id, name, key_hash, key_prefix, scope, rate_limit_tier, last_used_at, expires_at, is_active, created_at
+		SELECT ` + apiKeyColumns + `
 		FROM api_keys
-		WHERE is_active = true AND (expires_at IS NULL OR expires_at > NOW())
+		WHERE key_prefix = $1 AND is_active = true
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query)
+	rows, err := r.db.Pool.Query(ctx, query, prefix)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list active API keys: %w", err)
+		return nil, fmt.Errorf("failed to list API keys by prefix: %w", err)
 	}
 	defer rows.Close()
 
 	var keys []*models.APIKey
 	for rows.Next() {
-		key := &models.APIKey{}
-		if err := rows.Scan(
-			&key.ID,
-			&key.TenantID,
-			&key.Name,
-			&key.KeyHash,
-			&key.KeyPrefix,
-			&key.Scope,
-			&key.RateLimitTier,
-			&key.LastUsedAt,
-			&key.ExpiresAt,
-			&key.IsActive,
-			&key.CreatedAt,
-		); err != nil {
+		key, err := scanAPIKey(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan API key: %w", err)
 		}
 		keys = append(keys, key)
@@ -188,39 +201,53 @@ func (r *Repository) UpdateAPIKeyLastUsed(ctx context.Context, keyID uuid.UUID)
 	return err
 }
 
-// UpdateAPIKey updates an API key's name or active status
-func (r *Repository) UpdateAPIKey(ctx context.Context, id uuid.UUID, name *string, isActive *bool) error {
-	if name == nil && isActive == nil {
-		return nil
-	}
-
-	query := `UPDATE api_keys SET `
+// UpdateAPIKey updates an API key's name, active status, scopes or restrictions
+func (r *Repository) UpdateAPIKey(ctx context.Context, id uuid.UUID, req models.UpdateAPIKeyRequest) error {
+	sets := []string{}
 	args := []interface{}{}
-	argNum := 1
 
-	if name != nil {
-		query += fmt.Sprintf("name = $%d", argNum)
-		args = append(args, *name)
-		argNum++
+	set := func(column string, value interface{}) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
 	}
 
-	if isActive != nil {
-		if argNum > 1 {
-			query += ", "
-		}
-		query += fmt.Sprintf("is_active = $%d", argNum)
-		args = append(args, *isActive)
-		argNum++
+	if req.Name != nil {
+		set("name", *req.Name)
+	}
+	if req.IsActive != nil {
+		set("is_active", *req.IsActive)
+	}
+	if req.Scopes != nil {
+		set("scopes", nonNilStrings(*req.Scopes))
+		set("scope", models.SummarizeScopes(*req.Scopes))
+	}
+	if req.AllowedBatchIDs != nil {
+		set("allowed_batch_ids", nonNilUUIDs(*req.AllowedBatchIDs))
+	}
+	if req.AllowedMarkets != nil {
+		set("allowed_markets", nonNilStrings(*req.AllowedMarkets))
+	}
+	if req.AllowedCIDRs != nil {
+		set("allowed_cidrs", nonNilStrings(*req.AllowedCIDRs))
+	}
+	if req.ActorRole != nil {
+		set("actor_role", nullIfEmpty(*req.ActorRole))
+	}
+
+	if len(sets) == 0 {
+		return nil
 	}
 
-	query += fmt.Sprintf(" WHERE id = $%d", argNum)
 	args = append(args, id)
+	query := fmt.Sprintf("UPDATE api_keys SET %s WHERE id = $%d", strings.Join(sets, ", "), len(args))
 
 	_, err := r.db.Pool.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update API key: %w", err)
 	}
 
+	r.InvalidateAPIKey(id)
+
 	return nil
 }
 
@@ -232,94 +259,11 @@ func (r *Repository) DeleteAPIKey(ctx context.Context, id uuid.UUID) error {
 		return fmt.Errorf("failed to delete API key: %w", err)
 	}
 
+	r.InvalidateAPIKey(id)
+
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("API key not found")
+		return ErrAPIKeyNotFound
 	}
 
 	return nil
 }
-
-// ============================================================================
-// RATE LIMITING
-// ============================================================================
-
-// GetOrCreateUsageWindow gets or creates a rate limit usage window for the current hour
-func (r *Repository) GetOrCreateUsageWindow(ctx context.Context, keyID uuid.UUID, endpoint string) (*models.APIKeyUsage, error) {
-	// Get current hour window
-	now := time.Now().UTC()
-	windowStart := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, time.UTC)
-
-	// Try to get existing window
-	query := `
-		SELECT id, api_key_id, endpoint, request_count, window_start
-		FROM api_key_usage
-		WHERE api_key_id = $1 AND endpoint = $2 AND window_start = $3
-	`
-
-	usage := &models.APIKeyUsage{}
-	err := r.db.Pool.QueryRow(ctx, query, keyID, endpoint, windowStart).Scan(
-		&usage.ID,
-		&usage.APIKeyID,
-		&usage.Endpoint,
-		&usage.RequestCount,
-		&usage.WindowStart,
-	)
-
-	if err == pgx.ErrNoRows {
-		// Create new window
-		usage = &models.APIKeyUsage{
-			ID:           uuid.New(),
-			APIKeyID:     keyID,
-			Endpoint:     endpoint,
-			RequestCount: 0,
-			WindowStart:  windowStart,
-		}
-
-		insertQuery := `
-			INSERT INTO api_key_usage (id, api_key_id, endpoint, request_count, window_start)
-			VALUES ($1, $2, $3, $4, $5)
-			ON CONFLICT (api_key_id, endpoint, window_start) DO NOTHING
-		`
-		_, err = r.db.Pool.Exec(ctx, insertQuery, usage.ID, usage.APIKeyID, usage.Endpoint, usage.RequestCount, usage.WindowStart)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create usage window: %w", err)
-		}
-
-		return usage, nil
-	}
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to get usage window: %w", err)
-	}
-
-	return usage, nil
-}
-
-// IncrementUsage increments the request count for a usage window
-func (r *Repository) IncrementUsage(ctx context.Context, keyID uuid.UUID, endpoint string) (int, error) {
-	now := time.Now().UTC()
-	windowStart := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, time.UTC)
-
-	query := `
-		INSERT INTO api_key_usage (id, api_key_id, endpoint, request_count, window_start)
-		VALUES ($1, $2, $3, 1, $4)
-		ON CONFLICT (api_key_id, endpoint, window_start)
-		DO UPDATE SET request_count = api_key_usage.request_count + 1
-		RETURNING request_count
-	`
-
-	var count int
-	err := r.db.Pool.QueryRow(ctx, query, uuid.New(), keyID, endpoint, windowStart).Scan(&count)
-	if err != nil {
-		return 0, fmt.Errorf("failed to increment usage: %w", err)
-	}
-
-	return count, nil
-}
-
-// CleanupOldUsage removes usage records older than 24 hours
-func (r *Repository) CleanupOldUsage(ctx context.Context) error {
-	query := `DELETE FROM api_key_usage WHERE window_start < NOW() - INTERVAL '24 hours'`
-	_, err := r.db.Pool.Exec(ctx, query)
-	return err
-}
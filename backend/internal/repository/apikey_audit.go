@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"exportready-battery/internal/models"
+)
+
+// ============================================================================
+// API KEY REQUEST LOGS & AUDIT
+// ============================================================================
+
+// InsertAPIRequestLogs bulk inserts request log entries
+func (r *Repository) InsertAPIRequestLogs(ctx context.Context, entries []*models.APIRequestLog) (int, error) {
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	columns := []string{"id", "api_key_id", "tenant_id", "method", "endpoint", "path", "status_code", "latency_ms", "ip_address", "user_agent", "created_at"}
+
+	rows := make([][]interface{}, len(entries))
+	for i, e := range entries {
+		rows[i] = []interface{}{e.ID, e.APIKeyID, e.TenantID, e.Method, e.Endpoint, e.Path, e.StatusCode, e.LatencyMs, e.IPAddress, e.UserAgent, e.CreatedAt}
+	}
+
+	copyCount, err := r.db.Pool.CopyFrom(
+		ctx,
+		pgx.Identifier{"api_key_request_logs"},
+		columns,
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert API request logs: %w", err)
+	}
+
+	return int(copyCount), nil
+}
+
+// TouchAPIKeyIP records requests from an IP for a key.
+// Returns true when the IP has never been seen for this key before.
+func (r *Repository) TouchAPIKeyIP(ctx context.Context, keyID uuid.UUID, ip string, requests int, seenAt time.Time) (bool, error) {
+	query := `
+		INSERT INTO api_key_ips (api_key_id, ip_address, first_seen_at, last_seen_at, request_count)
+		VALUES ($1, $2, $3, $3, $4)
+		ON CONFLICT (api_key_id, ip_address) DO UPDATE SET
+			last_seen_at = GREATEST(api_key_ips.last_seen_at, EXCLUDED.last_seen_at),
+			request_count = api_key_ips.request_count + EXCLUDED.request_count
+		RETURNING (xmax = 0)
+	`
+
+	var inserted bool
+	err := r.db.Pool.QueryRow(ctx, query, keyID, ip, seenAt, requests).Scan(&inserted)
+	if err != nil {
+		return false, fmt.Errorf("failed to record API key IP: %w", err)
+	}
+
+	return inserted, nil
+}
+
+// CountAPIKeyIPs returns how many distinct IPs have used a key
+func (r *Repository) CountAPIKeyIPs(ctx context.Context, keyID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM api_key_ips WHERE api_key_id = $1`, keyID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count API key IPs: %w", err)
+	}
+	return count, nil
+}
+
+// CreateAPIKeyAlert stores a security alert for a key
+func (r *Repository) CreateAPIKeyAlert(ctx context.Context, alert *models.APIKeyAlert) error {
+	query := `
+		INSERT INTO api_key_alerts (id, api_key_id, tenant_id, alert_type, ip_address, message, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		alert.ID,
+		alert.APIKeyID,
+		alert.TenantID,
+		alert.AlertType,
+		nullIfEmpty(alert.IPAddress),
+		alert.Message,
+		alert.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create API key alert: %w", err)
+	}
+
+	return nil
+}
+
+// GetAPIKeyUsageSummary aggregates request logs for a key over the last N days
+func (r *Repository) GetAPIKeyUsageSummary(ctx context.Context, keyID uuid.UUID, days int) (*models.APIKeyUsageSummary, error) {
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	summary := &models.APIKeyUsageSummary{
+		APIKeyID:   keyID,
+		Days:       days,
+		ByEndpoint: []*models.APIKeyEndpointUsage{},
+		ByDay:      []*models.APIKeyDailyUsage{},
+	}
+
+	// Per endpoint
+	endpointQuery := `
+		SELECT endpoint,
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE status_code >= 400),
+		       COALESCE(AVG(latency_ms), 0)
+		FROM api_key_request_logs
+		WHERE api_key_id = $1 AND created_at >= $2
+		GROUP BY endpoint
+		ORDER BY COUNT(*) DESC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, endpointQuery, keyID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key usage by endpoint: %w", err)
+	}
+	for rows.Next() {
+		u := &models.APIKeyEndpointUsage{}
+		if err := rows.Scan(&u.Endpoint, &u.Requests, &u.Errors, &u.AvgLatencyMs); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan endpoint usage: %w", err)
+		}
+		u.ErrorRate = errorRate(u.Errors, u.Requests)
+		summary.Requests += u.Requests
+		summary.Errors += u.Errors
+		summary.ByEndpoint = append(summary.ByEndpoint, u)
+	}
+	rows.Close()
+	summary.ErrorRate = errorRate(summary.Errors, summary.Requests)
+
+	// Per day (UTC)
+	dayQuery := `
+		SELECT TO_CHAR(DATE_TRUNC('day', created_at AT TIME ZONE 'UTC'), 'YYYY-MM-DD') AS day,
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE status_code >= 400)
+		FROM api_key_request_logs
+		WHERE api_key_id = $1 AND created_at >= $2
+		GROUP BY day
+		ORDER BY day
+	`
+
+	rows, err = r.db.Pool.Query(ctx, dayQuery, keyID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key usage by day: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		d := &models.APIKeyDailyUsage{}
+		if err := rows.Scan(&d.Date, &d.Requests, &d.Errors); err != nil {
+			return nil, fmt.Errorf("failed to scan daily usage: %w", err)
+		}
+		d.ErrorRate = errorRate(d.Errors, d.Requests)
+		summary.ByDay = append(summary.ByDay, d)
+	}
+
+	return summary, nil
+}
+
+// GetRecentAPIRequestLogs returns the most recent requests for a key
+func (r *Repository) GetRecentAPIRequestLogs(ctx context.Context, keyID uuid.UUID, limit int) ([]*models.APIRequestLog, error) {
+	query := `
+		SELECT id, api_key_id, tenant_id, method, endpoint, path, status_code, latency_ms,
+		       COALESCE(ip_address, ''), COALESCE(user_agent, ''), created_at
+		FROM api_key_request_logs
+		WHERE api_key_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, keyID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API request logs: %w", err)
+	}
+	defer rows.Close()
+
+	logs := []*models.APIRequestLog{}
+	for rows.Next() {
+		l := &models.APIRequestLog{}
+		if err := rows.Scan(&l.ID, &l.APIKeyID, &l.TenantID, &l.Method, &l.Endpoint, &l.Path,
+			&l.StatusCode, &l.LatencyMs, &l.IPAddress, &l.UserAgent, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan API request log: %w", err)
+		}
+		logs = append(logs, l)
+	}
+
+	return logs, nil
+}
+
+// ListAPIKeyIPs returns the IPs seen for a key, most recent first
+func (r *Repository) ListAPIKeyIPs(ctx context.Context, keyID uuid.UUID) ([]*models.APIKeyIP, error) {
+	query := `
+		SELECT ip_address, first_seen_at, last_seen_at, request_count
+		FROM api_key_ips
+		WHERE api_key_id = $1
+		ORDER BY last_seen_at DESC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API key IPs: %w", err)
+	}
+	defer rows.Close()
+
+	ips := []*models.APIKeyIP{}
+	for rows.Next() {
+		ip := &models.APIKeyIP{}
+		if err := rows.Scan(&ip.IPAddress, &ip.FirstSeenAt, &ip.LastSeenAt, &ip.RequestCount); err != nil {
+			return nil, fmt.Errorf("failed to scan API key IP: %w", err)
+		}
+		ips = append(ips, ip)
+	}
+
+	return ips, nil
+}
+
+// ListAPIKeyAlerts returns recent alerts for a key
+func (r *Repository) ListAPIKeyAlerts(ctx context.Context, keyID uuid.UUID, limit int) ([]*models.APIKeyAlert, error) {
+	query := `
+		SELECT id, api_key_id, tenant_id, alert_type, COALESCE(ip_address, ''), COALESCE(message, ''), created_at
+		FROM api_key_alerts
+		WHERE api_key_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, keyID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API key alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := []*models.APIKeyAlert{}
+	for rows.Next() {
+		a := &models.APIKeyAlert{}
+		if err := rows.Scan(&a.ID, &a.APIKeyID, &a.TenantID, &a.AlertType, &a.IPAddress, &a.Message, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan API key alert: %w", err)
+		}
+		alerts = append(alerts, a)
+	}
+
+	return alerts, nil
+}
+
+// CleanupOldAPIRequestLogs removes request logs older than the retention period
+func (r *Repository) CleanupOldAPIRequestLogs(ctx context.Context, retentionDays int) error {
+	query := `DELETE FROM api_key_request_logs WHERE created_at < NOW() - make_interval(days => $1)`
+	_, err := r.db.Pool.Exec(ctx, query, retentionDays)
+	return err
+}
+
+// errorRate returns errors/requests as a percentage rounded to 2 decimals
+func errorRate(errors, requests int) float64 {
+	if requests == 0 {
+		return 0
+	}
+	return float64(int(float64(errors)/float64(requests)*10000+0.5)) / 100
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"exportready-battery/internal/models"
+)
+
+// ============================================================================
+// VALIDATED API KEY CACHE
+// ============================================================================
+
+// apiKeyCacheTTL bounds how long a validated key is trusted without hitting
+// the database. Kept short so changes made by other instances propagate quickly.
+const apiKeyCacheTTL = 30 * time.Second
+
+// apiKeyCacheEntry is a validated key and when it stops being trusted
+type apiKeyCacheEntry struct {
+	key       *models.APIKey
+	expiresAt time.Time
+}
+
+// apiKeyCache maps a SHA-256 digest of the raw key to its validated record,
+// so repeat requests skip both the lookup query and the bcrypt comparison.
+// Raw keys are never held in memory.
+type apiKeyCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[string]apiKeyCacheEntry
+	byID    map[uuid.UUID]string // key ID -> digest, for invalidation
+}
+
+func newAPIKeyCache(ttl time.Duration) *apiKeyCache {
+	return &apiKeyCache{
+		ttl:     ttl,
+		entries: make(map[string]apiKeyCacheEntry),
+		byID:    make(map[uuid.UUID]string),
+	}
+}
+
+func apiKeyDigest(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *apiKeyCache) get(rawKey string) (*models.APIKey, bool) {
+	digest := apiKeyDigest(rawKey)
+
+	c.mu.RLock()
+	entry, ok := c.entries[digest]
+	c.mu.RUnlock()
+
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.key, true
+}
+
+func (c *apiKeyCache) put(rawKey string, key *models.APIKey) {
+	digest := apiKeyDigest(rawKey)
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Drop expired entries opportunistically so the map stays bounded
+	for d, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, d)
+			delete(c.byID, entry.key.ID)
+		}
+	}
+
+	c.entries[digest] = apiKeyCacheEntry{key: key, expiresAt: now.Add(c.ttl)}
+	c.byID[key.ID] = digest
+}
+
+func (c *apiKeyCache) invalidate(id uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if digest, ok := c.byID[id]; ok {
+		delete(c.entries, digest)
+		delete(c.byID, id)
+	}
+}
+
+// GetCachedAPIKey returns a recently validated key for the raw key, if any
+func (r *Repository) GetCachedAPIKey(rawKey string) (*models.APIKey, bool) {
+	return r.apiKeys.get(rawKey)
+}
+
+// CacheValidatedAPIKey remembers a key whose hash has already been verified
+func (r *Repository) CacheValidatedAPIKey(rawKey string, key *models.APIKey) {
+	r.apiKeys.put(rawKey, key)
+}
+
+// InvalidateAPIKey evicts a key from the validation cache
+func (r *Repository) InvalidateAPIKey(id uuid.UUID) {
+	r.apiKeys.invalidate(id)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,6 +20,7 @@ type CreateBatchRequest struct {
 	BatchName        string
 	Specs            models.BatchSpec
 	MarketRegion     models.MarketRegion
+	Markets          []models.MarketRegion // Defaults to the markets of MarketRegion
 	PLICompliant     bool
 	DomesticValueAdd float64
 	CellSource       string
@@ -28,36 +30,37 @@ type CreateBatchRequest struct {
 	CountryOfOrigin string
 	CustomsDate     *time.Time
 	HSNCode         string // India: Harmonized System Nomenclature code
-
-	// DVA Audit Mode Fields
-	DVASource         string // "ESTIMATED" or "AUDITED"
-	PLICertificateURL string // URL to CA certificate (Supabase Storage)
 }
 
-// CreateBatch creates a new batch with dual-mode support
+// CreateBatch creates a new batch with dual-mode support. Its DVA is ESTIMATED;
+// only a CA attestation (AttestPLIAudit) makes it AUDITED.
 func (r *Repository) CreateBatch(ctx context.Context, req CreateBatchRequest) (*models.Batch, error) {
 	// Default to GLOBAL if not specified
 	marketRegion := req.MarketRegion
 	if marketRegion == "" {
 		marketRegion = models.MarketRegionGlobal
 	}
+	markets := req.Markets
+	if len(markets) == 0 {
+		markets = models.RegionMarkets(marketRegion)
+	}
 
 	batch := &models.Batch{
-		ID:                uuid.New(),
-		TenantID:          req.TenantID,
-		BatchName:         req.BatchName,
-		Specs:             req.Specs,
-		CreatedAt:         time.Now(),
-		MarketRegion:      marketRegion,
-		PLICompliant:      req.PLICompliant,
-		DomesticValueAdd:  req.DomesticValueAdd,
-		CellSource:        req.CellSource,
-		BillOfEntryNo:     req.BillOfEntryNo,
-		CountryOfOrigin:   req.CountryOfOrigin,
-		CustomsDate:       req.CustomsDate,
-		HSNCode:           req.HSNCode,
-		DVASource:         req.DVASource,
-		PLICertificateURL: req.PLICertificateURL,
+		ID:               uuid.New(),
+		TenantID:         req.TenantID,
+		BatchName:        req.BatchName,
+		Specs:            req.Specs,
+		CreatedAt:        time.Now(),
+		MarketRegion:     marketRegion,
+		Markets:          markets,
+		PLICompliant:     req.PLICompliant,
+		DomesticValueAdd: req.DomesticValueAdd,
+		CellSource:       req.CellSource,
+		BillOfEntryNo:    req.BillOfEntryNo,
+		CountryOfOrigin:  req.CountryOfOrigin,
+		CustomsDate:      req.CustomsDate,
+		HSNCode:          req.HSNCode,
+		DVASource:        models.DVASourceEstimated,
 	}
 
 	specsJSON, err := json.Marshal(req.Specs)
@@ -74,10 +77,10 @@ func (r *Repository) CreateBatch(ctx context.Context, req CreateBatchRequest) (*
 	// Debug logging
 	log.Printf("DEBUG CreateBatch: specsJSON=%s, marketRegion=%s", string(specsJSON), marketRegion)
 
-	// Updated query to include hsn_code, dva_source, and pli_certificate_url
+	// Updated query to include hsn_code and dva_source
 	query := `INSERT INTO public.batches 
 		(id, tenant_id, batch_name, specs, created_at, status, market_region, pli_compliant, domestic_value_add, cell_source,
-		 bill_of_entry_no, country_of_origin, customs_date, hsn_code, dva_source, pli_certificate_url) 
+		 bill_of_entry_no, country_of_origin, customs_date, hsn_code, dva_source, markets) 
 		VALUES ($1, $2, $3, $4::jsonb, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
 
 	// Handle nullable import fields
@@ -92,15 +95,6 @@ func (r *Repository) CreateBatch(ctx context.Context, req CreateBatchRequest) (*
 		hsnCode = req.HSNCode
 	}
 
-	// Handle nullable DVA audit fields
-	var dvaSource, pliCertURL interface{}
-	if req.DVASource != "" {
-		dvaSource = req.DVASource
-	}
-	if req.PLICertificateURL != "" {
-		pliCertURL = req.PLICertificateURL
-	}
-
 	_, err = r.db.Pool.Exec(ctx, query,
 		batch.ID,
 		batch.TenantID,
@@ -116,8 +110,8 @@ func (r *Repository) CreateBatch(ctx context.Context, req CreateBatchRequest) (*
 		countryOrigin,
 		req.CustomsDate,
 		hsnCode,
-		dvaSource,
-		pliCertURL,
+		batch.DVASource,
+		models.MarketStrings(markets),
 	)
 	if err != nil {
 		log.Printf("DEBUG CreateBatch ERROR: %v", err)
@@ -136,6 +130,7 @@ func (r *Repository) GetBatch(ctx context.Context, id uuid.UUID) (*models.Batch,
 	var billOfEntry, countryOrigin, hsnCode *string
 	var customsDate *time.Time
 	var dvaSource, pliCertURL *string
+	var markets []string
 
 	query := `SELECT id, tenant_id, batch_name, specs, created_at, 
 	          COALESCE(status, 'DRAFT') as status,
@@ -148,7 +143,9 @@ func (r *Repository) GetBatch(ctx context.Context, id uuid.UUID) (*models.Batch,
 	          customs_date,
 	          hsn_code,
 	          dva_source,
-	          pli_certificate_url
+	          audited_domestic_value_add::float8,
+	          pli_certificate_url,
+	          COALESCE(markets, '{}')
 	          FROM public.batches WHERE id = $1 AND deleted_at IS NULL`
 
 	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
@@ -167,7 +164,9 @@ func (r *Repository) GetBatch(ctx context.Context, id uuid.UUID) (*models.Batch,
 		&customsDate,
 		&hsnCode,
 		&dvaSource,
+		&batch.AuditedDomesticValueAdd,
 		&pliCertURL,
+		&markets,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -181,6 +180,7 @@ func (r *Repository) GetBatch(ctx context.Context, id uuid.UUID) (*models.Batch,
 	}
 
 	batch.MarketRegion = models.MarketRegion(marketRegion)
+	batch.Markets = models.StoredMarkets(batch.MarketRegion, markets)
 
 	if cellSource != nil {
 		batch.CellSource = *cellSource
@@ -209,23 +209,72 @@ func (r *Repository) GetBatch(ctx context.Context, id uuid.UUID) (*models.Batch,
 	return batch, nil
 }
 
-// ListBatches retrieves batches for a tenant with dual-mode fields and pagination
-func (r *Repository) ListBatches(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*models.Batch, int, error) {
-	// Default limit if not specified
-	if limit <= 0 {
-		limit = 50
+// BatchFilter narrows batch listings. Empty fields match everything.
+type BatchFilter struct {
+	Status        string      // DRAFT, ACTIVE, ARCHIVED
+	MarketRegions []string    // INDIA, EU, US, UK, GLOBAL (market_region summary)
+	BatchIDs      []uuid.UUID // Restrict to these batches (API key restrictions)
+	Search        string      // Case-insensitive match on batch name
+	Chemistry     string      // Case-insensitive match on specs.chemistry, e.g. LFP
+	CreatedAfter  *time.Time  // created_at >= CreatedAfter
+	CreatedBefore *time.Time  // created_at < CreatedBefore
+}
+
+// where builds the WHERE clause and positional args for a batch listing
+func (f BatchFilter) where(tenantID uuid.UUID) (string, []interface{}) {
+	clause := "b.tenant_id = $1 AND b.deleted_at IS NULL"
+	args := []interface{}{tenantID}
+
+	if f.Status != "" {
+		args = append(args, f.Status)
+		clause += fmt.Sprintf(" AND COALESCE(b.status, 'DRAFT') = $%d", len(args))
+	}
+	if len(f.MarketRegions) > 0 {
+		args = append(args, f.MarketRegions)
+		clause += fmt.Sprintf(" AND COALESCE(b.market_region::text, 'GLOBAL') = ANY($%d)", len(args))
 	}
-	if limit > 200 {
-		limit = 200 // Cap at 200
+	if len(f.BatchIDs) > 0 {
+		args = append(args, f.BatchIDs)
+		clause += fmt.Sprintf(" AND b.id = ANY($%d)", len(args))
 	}
+	if f.Search != "" {
+		args = append(args, "%"+f.Search+"%")
+		clause += fmt.Sprintf(" AND b.batch_name ILIKE $%d", len(args))
+	}
+	if f.Chemistry != "" {
+		args = append(args, strings.ToLower(f.Chemistry))
+		clause += fmt.Sprintf(" AND lower(b.specs->>'chemistry') = $%d", len(args))
+	}
+	if f.CreatedAfter != nil {
+		args = append(args, *f.CreatedAfter)
+		clause += fmt.Sprintf(" AND b.created_at >= $%d", len(args))
+	}
+	if f.CreatedBefore != nil {
+		args = append(args, *f.CreatedBefore)
+		clause += fmt.Sprintf(" AND b.created_at < $%d", len(args))
+	}
+	return clause, args
+}
+
+// ListBatches retrieves batches for a tenant matching the filter, newest first
+func (r *Repository) ListBatches(ctx context.Context, tenantID uuid.UUID, filter BatchFilter, page PageRequest) ([]*models.Batch, PageInfo, error) {
+	page = page.normalize(50, 200)
 
-	// Get total count first
-	var totalCount int
-	countQuery := `SELECT COUNT(*) FROM public.batches WHERE tenant_id = $1 AND deleted_at IS NULL`
-	if err := r.db.Pool.QueryRow(ctx, countQuery, tenantID).Scan(&totalCount); err != nil {
-		return nil, 0, fmt.Errorf("failed to count batches: %w", err)
+	where, args := filter.where(tenantID)
+
+	totalCount := -1
+	if page.WithTotal {
+		countQuery := `SELECT COUNT(*) FROM public.batches b WHERE ` + where
+		if err := r.db.Pool.QueryRow(ctx, countQuery, args...).Scan(&totalCount); err != nil {
+			return nil, PageInfo{}, fmt.Errorf("failed to count batches: %w", err)
+		}
 	}
 
+	var after, limit string
+	after, args = page.keyset("b.created_at", "b.id", true, args)
+	limit, args = page.limitClause(args)
+
+	// Passport counts are computed per returned row only, not for every batch of the tenant
 	query := `SELECT b.id, b.tenant_id, b.batch_name, b.specs, b.created_at, 
 	          COALESCE(b.status, 'DRAFT') as status,
 	          COALESCE(b.market_region::text, 'GLOBAL') as market_region, 
@@ -237,22 +286,18 @@ func (r *Repository) ListBatches(ctx context.Context, tenantID uuid.UUID, limit,
 	          b.customs_date,
 	          b.hsn_code,
 	          b.dva_source,
+	          b.audited_domestic_value_add::float8,
 	          b.pli_certificate_url,
-	          COUNT(p.uuid)::int as total_passports
+	          COALESCE(b.markets, '{}'),
+	          (SELECT COUNT(*) FROM public.passports p WHERE p.batch_id = b.id)::int as total_passports
 	          FROM public.batches b
-	          LEFT JOIN public.passports p ON b.id = p.batch_id
-	          WHERE b.tenant_id = $1 AND b.deleted_at IS NULL
-	          GROUP BY b.id, b.tenant_id, b.batch_name, b.specs, b.created_at, b.status, 
-	                   b.market_region, b.pli_compliant, b.domestic_value_add, b.cell_source,
-	                   b.bill_of_entry_no, b.country_of_origin, b.customs_date, b.hsn_code,
-	                   b.dva_source, b.pli_certificate_url
-	          ORDER BY b.created_at DESC
-	          LIMIT $2 OFFSET $3`
-
-	rows, err := r.db.Pool.Query(ctx, query, tenantID, limit, offset)
+	          WHERE ` + where + after + `
+	          ORDER BY b.created_at DESC, b.id DESC` + limit
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
 	if err != nil {
 		log.Printf("ListBatches Query Error: %v", err)
-		return nil, 0, fmt.Errorf("failed to list batches: %w\n", err)
+		return nil, PageInfo{}, fmt.Errorf("failed to list batches: %w", err)
 	}
 	defer rows.Close()
 
@@ -265,6 +310,7 @@ func (r *Repository) ListBatches(ctx context.Context, tenantID uuid.UUID, limit,
 		var billOfEntry, countryOrigin, hsnCode *string
 		var customsDate *time.Time
 		var dvaSource, pliCertURL *string
+		var markets []string
 
 		if err := rows.Scan(
 			&batch.ID,
@@ -282,19 +328,22 @@ func (r *Repository) ListBatches(ctx context.Context, tenantID uuid.UUID, limit,
 			&customsDate,
 			&hsnCode,
 			&dvaSource,
+			&batch.AuditedDomesticValueAdd,
 			&pliCertURL,
+			&markets,
 			&batch.TotalPassports,
 		); err != nil {
-			return nil, 0, fmt.Errorf("failed to scan batch: %w", err)
+			return nil, PageInfo{}, fmt.Errorf("failed to scan batch: %w", err)
 		}
 
 		if len(specsJSON) > 0 {
 			if err := json.Unmarshal(specsJSON, &batch.Specs); err != nil {
-				return nil, 0, fmt.Errorf("failed to unmarshal specs: %w", err)
+				return nil, PageInfo{}, fmt.Errorf("failed to unmarshal specs: %w", err)
 			}
 		}
 
 		batch.MarketRegion = models.MarketRegion(marketRegion)
+		batch.Markets = models.StoredMarkets(batch.MarketRegion, markets)
 
 		if cellSource != nil {
 			batch.CellSource = *cellSource
@@ -322,8 +371,14 @@ func (r *Repository) ListBatches(ctx context.Context, tenantID uuid.UUID, limit,
 
 		batches = append(batches, batch)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to list batches: %w", err)
+	}
 
-	return batches, totalCount, nil
+	n, info := pageInfo(page, len(batches), totalCount, func(i int) Cursor {
+		return Cursor{Sort: SortBatchesNewest, Time: batches[i].CreatedAt, ID: batches[i].ID}
+	})
+	return batches[:n], info, nil
 }
 
 func (r *Repository) DeleteBatch(ctx context.Context, id uuid.UUID) error {
@@ -361,3 +416,61 @@ func (r *Repository) SetBatchStatus(ctx context.Context, batchID uuid.UUID, stat
 	}
 	return nil
 }
+
+// UpdateDraftBatchRequest contains the editable fields of a DRAFT batch.
+// Nil fields are left unchanged; Specs replaces the whole spec document.
+type UpdateDraftBatchRequest struct {
+	BatchName        *string
+	Specs            *models.BatchSpec
+	PLICompliant     *bool
+	DomesticValueAdd *float64
+	CellSource       *string
+	BillOfEntryNo    *string
+	CountryOfOrigin  *string
+	CustomsDate      *time.Time
+	HSNCode          *string
+}
+
+// UpdateDraftBatch updates batch details while the batch is still in DRAFT.
+// Active and archived batches are immutable because their labels may already be printed.
+func (r *Repository) UpdateDraftBatch(ctx context.Context, id uuid.UUID, req UpdateDraftBatchRequest) error {
+	var specsJSON interface{}
+	if req.Specs != nil {
+		data, err := json.Marshal(req.Specs)
+		if err != nil {
+			return fmt.Errorf("failed to marshal specs: %w", err)
+		}
+		specsJSON = string(data)
+	}
+
+	query := `UPDATE public.batches SET
+		batch_name = COALESCE($2, batch_name),
+		specs = COALESCE($3::jsonb, specs),
+		pli_compliant = COALESCE($4, pli_compliant),
+		domestic_value_add = COALESCE($5, domestic_value_add),
+		cell_source = COALESCE($6, cell_source),
+		bill_of_entry_no = COALESCE($7, bill_of_entry_no),
+		country_of_origin = COALESCE($8, country_of_origin),
+		customs_date = COALESCE($9, customs_date),
+		hsn_code = COALESCE($10, hsn_code)
+		WHERE id = $1 AND COALESCE(status, 'DRAFT') = 'DRAFT' AND deleted_at IS NULL`
+
+	tag, err := r.db.Pool.Exec(ctx, query, id,
+		req.BatchName,
+		specsJSON,
+		req.PLICompliant,
+		req.DomesticValueAdd,
+		req.CellSource,
+		req.BillOfEntryNo,
+		req.CountryOfOrigin,
+		req.CustomsDate,
+		req.HSNCode,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update batch: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("batch is not in draft")
+	}
+	return nil
+}
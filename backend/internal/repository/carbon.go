@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"exportready-battery/internal/models"
+)
+
+// ErrCarbonBatchNotDraft is returned when a calculation would overwrite the
+// declared footprint of a batch that is no longer a DRAFT
+var ErrCarbonBatchNotDraft = errors.New("batch is not in draft")
+
+// UpsertEmissionFactors imports factors into a tenant's library in one
+// transaction. A factor whose key already exists is replaced.
+func (r *Repository) UpsertEmissionFactors(ctx context.Context, tenantID uuid.UUID, factors []*models.EmissionFactor) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO emission_factors (tenant_id, key, name, unit, kg_co2e_per_unit, stage, source, region)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''))
+		ON CONFLICT (tenant_id, key) DO UPDATE SET
+			name = EXCLUDED.name,
+			unit = EXCLUDED.unit,
+			kg_co2e_per_unit = EXCLUDED.kg_co2e_per_unit,
+			stage = EXCLUDED.stage,
+			source = EXCLUDED.source,
+			region = EXCLUDED.region,
+			updated_at = NOW()
+		RETURNING id, created_at, updated_at`
+
+	for _, f := range factors {
+		f.TenantID = tenantID
+		err := tx.QueryRow(ctx, query,
+			tenantID, f.Key, f.Name, f.Unit, f.KgCO2ePerUnit, f.Stage, f.Source, f.Region,
+		).Scan(&f.ID, &f.CreatedAt, &f.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to upsert emission factor %s: %w", f.Key, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit emission factors: %w", err)
+	}
+	return nil
+}
+
+// ListEmissionFactors returns a tenant's factor library ordered by key
+func (r *Repository) ListEmissionFactors(ctx context.Context, tenantID uuid.UUID) ([]*models.EmissionFactor, error) {
+	query := `
+		SELECT id, tenant_id, key, name, unit, kg_co2e_per_unit::float8,
+		       COALESCE(stage, ''), COALESCE(source, ''), COALESCE(region, ''), created_at, updated_at
+		FROM emission_factors
+		WHERE tenant_id = $1
+		ORDER BY key`
+
+	rows, err := r.db.Pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list emission factors: %w", err)
+	}
+	defer rows.Close()
+
+	factors := []*models.EmissionFactor{}
+	for rows.Next() {
+		f := &models.EmissionFactor{}
+		if err := rows.Scan(&f.ID, &f.TenantID, &f.Key, &f.Name, &f.Unit, &f.KgCO2ePerUnit,
+			&f.Stage, &f.Source, &f.Region, &f.CreatedAt, &f.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan emission factor: %w", err)
+		}
+		factors = append(factors, f)
+	}
+	return factors, rows.Err()
+}
+
+// DeleteEmissionFactor removes a factor from a tenant's library. Reports already
+// calculated keep their copy of the factor.
+func (r *Repository) DeleteEmissionFactor(ctx context.Context, tenantID, id uuid.UUID) (bool, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM emission_factors WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete emission factor: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// SetBatchCarbonFootprint attaches a calculation to a batch: the per-kWh total
+// and declaration go into specs, the full report into carbon_report. Only a
+// DRAFT batch is updated.
+func (r *Repository) SetBatchCarbonFootprint(ctx context.Context, batchID uuid.UUID, report *models.CarbonFootprintReport) error {
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal carbon report: %w", err)
+	}
+	declarationJSON, err := json.Marshal(report.Declaration)
+	if err != nil {
+		return fmt.Errorf("failed to marshal carbon declaration: %w", err)
+	}
+
+	query := `
+		UPDATE public.batches SET
+			specs = specs || jsonb_build_object('carbon_footprint', $2::text, 'carbon_footprint_detail', $3::jsonb),
+			carbon_report = $4::jsonb
+		WHERE id = $1 AND COALESCE(status, 'DRAFT') = 'DRAFT' AND deleted_at IS NULL`
+
+	footprint := fmt.Sprintf("%.2f", report.Declaration.KgCO2ePerKWh)
+	tag, err := r.db.Pool.Exec(ctx, query, batchID, footprint, string(declarationJSON), string(reportJSON))
+	if err != nil {
+		return fmt.Errorf("failed to set batch carbon footprint: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCarbonBatchNotDraft
+	}
+	return nil
+}
+
+// GetBatchCarbonReport returns the batch's last calculation, or nil if it has none
+func (r *Repository) GetBatchCarbonReport(ctx context.Context, batchID uuid.UUID) (*models.CarbonFootprintReport, error) {
+	var reportJSON []byte
+	err := r.db.Pool.QueryRow(ctx,
+		`SELECT carbon_report FROM public.batches WHERE id = $1 AND deleted_at IS NULL`, batchID,
+	).Scan(&reportJSON)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("batch not found")
+		}
+		return nil, fmt.Errorf("failed to get carbon report: %w", err)
+	}
+	if reportJSON == nil {
+		return nil, nil
+	}
+
+	report := &models.CarbonFootprintReport{}
+	if err := json.Unmarshal(reportJSON, report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal carbon report: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,387 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"exportready-battery/internal/models"
+)
+
+// Custody transfer errors
+var (
+	ErrCustodyPassportsNotFound = errors.New("some passports were not found")
+	ErrCustodyTransferPending   = errors.New("a passport already has a pending custody transfer")
+	ErrCustodySameHolder        = errors.New("the receiving party already holds a passport")
+)
+
+// CreateCustodyTransfersRequest hands passports to one receiving party as a group
+type CreateCustodyTransfersRequest struct {
+	TenantID    uuid.UUID
+	PassportIDs []uuid.UUID
+	To          models.CustodyParty // Email, Name and Type; upserted by email
+	DocumentRef string
+	Note        string
+	InitiatedBy string
+	TokenHash   string // Acceptance token, shared by the group
+}
+
+// upsertCustodyParty returns the tenant's party with party.Email, creating it if
+// needed. With overwrite, an existing party takes the given name and type.
+func upsertCustodyParty(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID, party *models.CustodyParty, overwrite bool) error {
+	onConflict := `DO UPDATE SET email = EXCLUDED.email`
+	if overwrite {
+		onConflict = `DO UPDATE SET name = EXCLUDED.name, party_type = EXCLUDED.party_type`
+	}
+
+	err := tx.QueryRow(ctx, `
+		INSERT INTO custody_parties (tenant_id, email, name, party_type)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, email) `+onConflict+`
+		RETURNING id, name, party_type, created_at`,
+		tenantID, strings.ToLower(party.Email), party.Name, party.Type,
+	).Scan(&party.ID, &party.Name, &party.Type, &party.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save custody party: %w", err)
+	}
+	party.Email = strings.ToLower(party.Email)
+	return nil
+}
+
+// CreateCustodyTransfers records PENDING transfers of the tenant's passports to
+// one party, all sharing a new group ID, which is returned. The sending party of
+// each transfer is the passport's current owner (nil for the manufacturer).
+func (r *Repository) CreateCustodyTransfers(ctx context.Context, req CreateCustodyTransfersRequest) (uuid.UUID, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to begin custody transfer: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := upsertCustodyParty(ctx, tx, req.TenantID, &req.To, true); err != nil {
+		return uuid.Nil, err
+	}
+
+	// Lock the passports so a concurrent acceptance cannot change the sending party
+	var found, held int
+	err = tx.QueryRow(ctx, `
+		WITH locked AS (
+			SELECT p.uuid, p.owner_id
+			FROM public.passports p
+			JOIN public.batches b ON b.id = p.batch_id
+			WHERE p.uuid = ANY($1) AND b.tenant_id = $2 AND b.deleted_at IS NULL
+			FOR UPDATE OF p
+		)
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE owner_id = $3) FROM locked`,
+		req.PassportIDs, req.TenantID, req.To.ID,
+	).Scan(&found, &held)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to lock passports for transfer: %w", err)
+	}
+	if found != len(req.PassportIDs) {
+		return uuid.Nil, ErrCustodyPassportsNotFound
+	}
+	if held > 0 {
+		return uuid.Nil, ErrCustodySameHolder
+	}
+
+	groupID := uuid.New()
+	_, err = tx.Exec(ctx, `
+		INSERT INTO custody_transfers
+			(tenant_id, group_id, passport_id, from_party_id, to_party_id, document_ref, note,
+			 status, method, acceptance_token_hash, initiated_by)
+		SELECT $1, $2, p.uuid, p.owner_id, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8, $9, $10
+		FROM public.passports p
+		WHERE p.uuid = ANY($3)`,
+		req.TenantID, groupID, req.PassportIDs, req.To.ID, req.DocumentRef, req.Note,
+		models.TransferStatusPending, models.TransferMethodAcceptance, req.TokenHash, req.InitiatedBy,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return uuid.Nil, ErrCustodyTransferPending
+		}
+		return uuid.Nil, fmt.Errorf("failed to create custody transfers: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to commit custody transfer: %w", err)
+	}
+	return groupID, nil
+}
+
+// RecordCustodyHandover records that party took custody of a passport now, as an
+// already-accepted transfer, and makes them the current owner. It does nothing
+// if they already hold the passport. Returns whether a transfer was recorded.
+func (r *Repository) RecordCustodyHandover(ctx context.Context, passportID uuid.UUID, party *models.CustodyParty, initiatedBy string) (bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin custody handover: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var tenantID uuid.UUID
+	var ownerID *uuid.UUID
+	err = tx.QueryRow(ctx, `
+		SELECT b.tenant_id, p.owner_id
+		FROM public.passports p
+		JOIN public.batches b ON b.id = p.batch_id
+		WHERE p.uuid = $1
+		FOR UPDATE OF p`, passportID,
+	).Scan(&tenantID, &ownerID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, fmt.Errorf("passport not found")
+		}
+		return false, fmt.Errorf("failed to lock passport for handover: %w", err)
+	}
+
+	if err := upsertCustodyParty(ctx, tx, tenantID, party, false); err != nil {
+		return false, err
+	}
+	if ownerID != nil && *ownerID == party.ID {
+		return false, nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO custody_transfers
+			(tenant_id, group_id, passport_id, from_party_id, to_party_id, status, method,
+			 initiated_by, responded_at, transferred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())`,
+		tenantID, uuid.New(), passportID, ownerID, party.ID,
+		models.TransferStatusAccepted, models.TransferMethodMagicLink, initiatedBy,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to record custody handover: %w", err)
+	}
+
+	if err := setPassportOwner(ctx, tx, []uuid.UUID{passportID}, party); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit custody handover: %w", err)
+	}
+	return true, nil
+}
+
+// setPassportOwner points passports at their new owner after an accepted transfer
+func setPassportOwner(ctx context.Context, tx pgx.Tx, passportIDs []uuid.UUID, party *models.CustodyParty) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE public.passports SET owner_id = $1, current_owner_email = $2 WHERE uuid = ANY($3)`,
+		party.ID, party.Email, passportIDs)
+	if err != nil {
+		return fmt.Errorf("failed to update passport owner: %w", err)
+	}
+	return nil
+}
+
+// RespondToCustodyTransfers accepts or rejects the PENDING transfers of the group
+// behind an acceptance token. Accepting makes the receiving party the current
+// owner of every passport in the group. Returns the updated transfers; none if
+// nothing was pending.
+func (r *Repository) RespondToCustodyTransfers(ctx context.Context, tokenHash string, accept bool, note string) ([]*models.CustodyTransfer, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin custody response: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	status := models.TransferStatusRejected
+	if accept {
+		status = models.TransferStatusAccepted
+	}
+
+	rows, err := tx.Query(ctx, `
+		UPDATE custody_transfers
+		SET status = $2, responded_at = NOW(), response_note = NULLIF($3, ''),
+		    transferred_at = CASE WHEN $2 = '`+models.TransferStatusAccepted+`' THEN NOW() END
+		WHERE acceptance_token_hash = $1 AND status = $4
+		RETURNING id`,
+		tokenHash, status, note, models.TransferStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to respond to custody transfer: %w", err)
+	}
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan custody transfer: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to respond to custody transfer: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	transfers, err := queryCustodyTransfers(ctx, tx, `t.id = ANY($1) ORDER BY p.serial_number`, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if accept {
+		passportIDs := make([]uuid.UUID, len(transfers))
+		for i, t := range transfers {
+			passportIDs[i] = t.PassportID
+		}
+		to := transfers[0].To // A group has one receiving party
+		if err := setPassportOwner(ctx, tx, passportIDs, &to); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit custody response: %w", err)
+	}
+	return transfers, nil
+}
+
+// CancelCustodyTransferGroup cancels the pending transfers in the group of the
+// given transfer. Returns how many were cancelled; -1 if the transfer does not exist.
+func (r *Repository) CancelCustodyTransferGroup(ctx context.Context, tenantID, transferID uuid.UUID) (int, error) {
+	var groupID uuid.UUID
+	err := r.db.Pool.QueryRow(ctx,
+		`SELECT group_id FROM custody_transfers WHERE id = $1 AND tenant_id = $2`,
+		transferID, tenantID).Scan(&groupID)
+	if err == pgx.ErrNoRows {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get custody transfer: %w", err)
+	}
+
+	result, err := r.db.Pool.Exec(ctx, `
+		UPDATE custody_transfers SET status = $2, responded_at = NOW()
+		WHERE group_id = $1 AND status = $3`,
+		groupID, models.TransferStatusCancelled, models.TransferStatusPending)
+	if err != nil {
+		return 0, fmt.Errorf("failed to cancel custody transfers: %w", err)
+	}
+	return int(result.RowsAffected()), nil
+}
+
+const custodyTransferColumns = `
+	t.id, t.group_id, t.passport_id, p.serial_number,
+	fp.id, COALESCE(fp.email, ''), COALESCE(fp.name, ''), COALESCE(fp.party_type, ''), fp.created_at,
+	tp.id, tp.email, tp.name, tp.party_type, tp.created_at,
+	COALESCE(t.document_ref, ''), COALESCE(t.note, ''), t.status, t.method, t.initiated_by,
+	t.created_at, t.responded_at, COALESCE(t.response_note, ''), t.transferred_at`
+
+const custodyTransferFrom = `
+	FROM custody_transfers t
+	JOIN public.passports p ON p.uuid = t.passport_id
+	JOIN custody_parties tp ON tp.id = t.to_party_id
+	LEFT JOIN custody_parties fp ON fp.id = t.from_party_id`
+
+func scanCustodyTransfer(row pgx.Row) (*models.CustodyTransfer, error) {
+	t := &models.CustodyTransfer{}
+	var fromID *uuid.UUID
+	var fromEmail, fromName, fromType string
+	var fromCreated *time.Time
+	err := row.Scan(
+		&t.ID, &t.GroupID, &t.PassportID, &t.SerialNumber,
+		&fromID, &fromEmail, &fromName, &fromType, &fromCreated,
+		&t.To.ID, &t.To.Email, &t.To.Name, &t.To.Type, &t.To.CreatedAt,
+		&t.DocumentRef, &t.Note, &t.Status, &t.Method, &t.InitiatedBy,
+		&t.CreatedAt, &t.RespondedAt, &t.ResponseNote, &t.TransferredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if fromID != nil {
+		t.From = &models.CustodyParty{ID: *fromID, Email: fromEmail, Name: fromName, Type: fromType}
+		if fromCreated != nil {
+			t.From.CreatedAt = *fromCreated
+		}
+	}
+	return t, nil
+}
+
+// queryCustodyTransfers runs a transfer query; where may end in ORDER BY / LIMIT
+func queryCustodyTransfers(ctx context.Context, q interface {
+	Query(context.Context, string, ...interface{}) (pgx.Rows, error)
+}, where string, args ...interface{}) ([]*models.CustodyTransfer, error) {
+	rows, err := q.Query(ctx, `SELECT `+custodyTransferColumns+custodyTransferFrom+` WHERE `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query custody transfers: %w", err)
+	}
+	defer rows.Close()
+
+	var transfers []*models.CustodyTransfer
+	for rows.Next() {
+		t, err := scanCustodyTransfer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan custody transfer: %w", err)
+		}
+		transfers = append(transfers, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query custody transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+// GetCustodyTransfersByToken retrieves the group of transfers behind an acceptance token
+func (r *Repository) GetCustodyTransfersByToken(ctx context.Context, tokenHash string) ([]*models.CustodyTransfer, error) {
+	return queryCustodyTransfers(ctx, r.db.Pool, `t.acceptance_token_hash = $1 ORDER BY p.serial_number`, tokenHash)
+}
+
+// GetCustodyTransfer retrieves a tenant's transfer; nil if it does not exist
+func (r *Repository) GetCustodyTransfer(ctx context.Context, tenantID, id uuid.UUID) (*models.CustodyTransfer, error) {
+	t, err := scanCustodyTransfer(r.db.Pool.QueryRow(ctx,
+		`SELECT `+custodyTransferColumns+custodyTransferFrom+` WHERE t.id = $1 AND t.tenant_id = $2`, id, tenantID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custody transfer: %w", err)
+	}
+	return t, nil
+}
+
+// ListPassportCustodyTransfers retrieves a tenant's passport's accepted and
+// pending transfers in the order they took effect (pending last)
+func (r *Repository) ListPassportCustodyTransfers(ctx context.Context, tenantID, passportID uuid.UUID) ([]*models.CustodyTransfer, error) {
+	return queryCustodyTransfers(ctx, r.db.Pool, `
+		t.passport_id = $1 AND t.tenant_id = $2 AND t.status IN ($3, $4)
+		ORDER BY t.transferred_at ASC NULLS LAST, t.created_at ASC`,
+		passportID, tenantID, models.TransferStatusAccepted, models.TransferStatusPending)
+}
+
+// ListCustodyTransfers retrieves a tenant's transfers, newest first. status filters when set.
+func (r *Repository) ListCustodyTransfers(ctx context.Context, tenantID uuid.UUID, status string, page PageRequest) ([]*models.CustodyTransfer, PageInfo, error) {
+	page = page.normalize(20, 100)
+
+	where := "t.tenant_id = $1"
+	args := []interface{}{tenantID}
+	if status != "" {
+		args = append(args, status)
+		where += fmt.Sprintf(" AND t.status = $%d", len(args))
+	}
+
+	var after, limit string
+	after, args = page.keyset("t.created_at", "t.id", true, args)
+	limit, args = page.limitClause(args)
+
+	transfers, err := queryCustodyTransfers(ctx, r.db.Pool, where+after+`
+		ORDER BY t.created_at DESC, t.id DESC`+limit, args...)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	n, info := pageInfo(page, len(transfers), -1, func(i int) Cursor {
+		return Cursor{Sort: SortTransfersNewest, Time: transfers[i].CreatedAt, ID: transfers[i].ID}
+	})
+	return transfers[:n], info, nil
+}
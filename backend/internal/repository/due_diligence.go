@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"exportready-battery/internal/models"
+)
+
+// SetBatchDueDiligence replaces the recycled content and due diligence
+// disclosure in the batch specs. A nil value removes the key.
+func (r *Repository) SetBatchDueDiligence(ctx context.Context, batchID uuid.UUID, recycled *models.RecycledContent, dd *models.DueDiligence) error {
+	recycledJSON, err := json.Marshal(recycled)
+	if err != nil {
+		return fmt.Errorf("failed to marshal recycled content: %w", err)
+	}
+	ddJSON, err := json.Marshal(dd)
+	if err != nil {
+		return fmt.Errorf("failed to marshal due diligence: %w", err)
+	}
+
+	query := `
+		UPDATE public.batches SET
+			specs = (specs - 'recycled_content' - 'due_diligence')
+				|| jsonb_strip_nulls(jsonb_build_object('recycled_content', $2::jsonb, 'due_diligence', $3::jsonb))
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	tag, err := r.db.Pool.Exec(ctx, query, batchID, string(recycledJSON), string(ddJSON))
+	if err != nil {
+		return fmt.Errorf("failed to set batch due diligence: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("batch not found")
+	}
+	return nil
+}
+
+const dueDiligenceDocumentColumns = `
+	id, batch_id, title, COALESCE(auditor, ''), COALESCE(supplier, ''), issued_on,
+	file_name, file_path, size_bytes, COALESCE(uploaded_by, ''), created_at`
+
+func scanDueDiligenceDocument(row pgx.Row) (*models.DueDiligenceDocument, error) {
+	doc := &models.DueDiligenceDocument{}
+	err := row.Scan(&doc.ID, &doc.BatchID, &doc.Title, &doc.Auditor, &doc.Supplier, &doc.IssuedOn,
+		&doc.FileName, &doc.FilePath, &doc.SizeBytes, &doc.UploadedBy, &doc.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// CreateDueDiligenceDocument records an uploaded audit report. doc.ID and
+// doc.FilePath are set by the caller, which has already stored the file.
+func (r *Repository) CreateDueDiligenceDocument(ctx context.Context, tenantID uuid.UUID, doc *models.DueDiligenceDocument) error {
+	query := `
+		INSERT INTO due_diligence_documents
+			(id, tenant_id, batch_id, title, auditor, supplier, issued_on, file_name, file_path, size_bytes, uploaded_by)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8, $9, $10, NULLIF($11, ''))
+		RETURNING created_at`
+
+	err := r.db.Pool.QueryRow(ctx, query,
+		doc.ID, tenantID, doc.BatchID, doc.Title, doc.Auditor, doc.Supplier, doc.IssuedOn,
+		doc.FileName, doc.FilePath, doc.SizeBytes, doc.UploadedBy,
+	).Scan(&doc.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create due diligence document: %w", err)
+	}
+	return nil
+}
+
+// ListDueDiligenceDocuments returns a batch's audit reports, oldest first
+func (r *Repository) ListDueDiligenceDocuments(ctx context.Context, batchID uuid.UUID) ([]*models.DueDiligenceDocument, error) {
+	query := `SELECT` + dueDiligenceDocumentColumns + `
+		FROM due_diligence_documents
+		WHERE batch_id = $1
+		ORDER BY created_at, id`
+
+	rows, err := r.db.Pool.Query(ctx, query, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due diligence documents: %w", err)
+	}
+	defer rows.Close()
+
+	docs := []*models.DueDiligenceDocument{}
+	for rows.Next() {
+		doc, err := scanDueDiligenceDocument(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan due diligence document: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+// GetDueDiligenceDocument returns one of a batch's audit reports, or nil if the
+// batch has no such document
+func (r *Repository) GetDueDiligenceDocument(ctx context.Context, batchID, id uuid.UUID) (*models.DueDiligenceDocument, error) {
+	query := `SELECT` + dueDiligenceDocumentColumns + `
+		FROM due_diligence_documents
+		WHERE id = $1 AND batch_id = $2`
+
+	doc, err := scanDueDiligenceDocument(r.db.Pool.QueryRow(ctx, query, id, batchID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get due diligence document: %w", err)
+	}
+	return doc, nil
+}
+
+// DeleteDueDiligenceDocument removes an audit report record
+func (r *Repository) DeleteDueDiligenceDocument(ctx context.Context, batchID, id uuid.UUID) (bool, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM due_diligence_documents WHERE id = $1 AND batch_id = $2`, id, batchID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete due diligence document: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
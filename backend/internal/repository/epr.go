@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"exportready-battery/internal/models"
+)
+
+// ListEPRTargets returns the categories a tenant has configured a target for
+func (r *Repository) ListEPRTargets(ctx context.Context, tenantID uuid.UUID) ([]models.EPRTarget, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT category, target_pct::float8, updated_at
+		FROM epr_targets
+		WHERE tenant_id = $1
+		ORDER BY category`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list EPR targets: %w", err)
+	}
+	defer rows.Close()
+
+	targets := []models.EPRTarget{}
+	for rows.Next() {
+		var t models.EPRTarget
+		var updatedAt time.Time
+		if err := rows.Scan(&t.Category, &t.TargetPct, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan EPR target: %w", err)
+		}
+		t.UpdatedAt = &updatedAt
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+// UpsertEPRTargets sets a tenant's targets for the given categories in one
+// transaction; other categories keep theirs
+func (r *Repository) UpsertEPRTargets(ctx context.Context, tenantID uuid.UUID, targets []models.EPRTarget) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO epr_targets (tenant_id, category, target_pct)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id, category) DO UPDATE SET
+			target_pct = EXCLUDED.target_pct,
+			updated_at = NOW()`
+
+	for _, t := range targets {
+		if _, err := tx.Exec(ctx, query, tenantID, t.Category, t.TargetPct); err != nil {
+			return fmt.Errorf("failed to set EPR target %s: %w", t.Category, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit EPR targets: %w", err)
+	}
+	return nil
+}
+
+// ListEPRBatchQuantities counts, per batch, the passports placed on the market
+// in [from, to) and those recycled in it. A passport is placed on the market when
+// shipped, or at manufacture if it has not been; it is recycled when its RECYCLED
+// event was logged, whatever its status now. DRAFT batches have not been placed on the market.
+func (r *Repository) ListEPRBatchQuantities(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]models.EPRBatchQuantity, error) {
+	query := `
+		SELECT b.id, b.batch_name, b.specs, q.placed, q.recycled
+		FROM public.batches b
+		JOIN LATERAL (
+			SELECT
+				COUNT(*) FILTER (WHERE COALESCE(p.shipped_at, p.manufacture_date) >= $2
+				                   AND COALESCE(p.shipped_at, p.manufacture_date) < $3) AS placed,
+				COUNT(*) FILTER (WHERE EXISTS (
+					SELECT 1 FROM public.passport_events e
+					WHERE e.passport_id = p.uuid AND e.event_type = $4
+					  AND e.created_at >= $2 AND e.created_at < $3
+				)) AS recycled
+			FROM public.passports p
+			WHERE p.batch_id = b.id
+		) q ON q.placed > 0 OR q.recycled > 0
+		WHERE b.tenant_id = $1 AND b.deleted_at IS NULL AND COALESCE(b.status, 'DRAFT') <> $5
+		ORDER BY b.batch_name, b.id`
+
+	rows, err := r.db.Pool.Query(ctx, query, tenantID, from, to,
+		models.PassportEventRecycled, models.BatchStatusDraft)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate EPR quantities: %w", err)
+	}
+	defer rows.Close()
+
+	quantities := []models.EPRBatchQuantity{}
+	for rows.Next() {
+		var q models.EPRBatchQuantity
+		var specsJSON []byte
+		if err := rows.Scan(&q.BatchID, &q.BatchName, &specsJSON, &q.UnitsPlaced, &q.UnitsRecycled); err != nil {
+			return nil, fmt.Errorf("failed to scan EPR quantity: %w", err)
+		}
+		if err := json.Unmarshal(specsJSON, &q.Specs); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal specs of batch %s: %w", q.BatchID, err)
+		}
+		quantities = append(quantities, q)
+	}
+	return quantities, rows.Err()
+}
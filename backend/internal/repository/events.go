@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"exportready-battery/internal/models"
+)
+
+// EventChannel is the NOTIFY channel announcing new tenant events
+const EventChannel = "tenant_events"
+
+// notifyInserted announces the rows of an "inserted" CTE. NOTIFY payloads are
+// capped at 8000 bytes, so large events send only their id and tenant and
+// listeners load them with GetTenantEvent.
+const notifyInserted = `
+	SELECT pg_notify('` + EventChannel + `', CASE
+		WHEN octet_length(full_event) < 7900 THEN full_event
+		ELSE json_build_object('id', id, 'tenant_id', tenant_id)::text
+	END)
+	FROM (
+		SELECT id, tenant_id, json_build_object(
+			'id', id, 'tenant_id', tenant_id, 'type', event_type,
+			'data', payload, 'created_at', created_at
+		)::text AS full_event
+		FROM inserted
+	) e`
+
+// EventNotification is the NOTIFY payload for a tenant event. Type is empty
+// when the event was too large to inline.
+type EventNotification struct {
+	ID        int64           `json:"id"`
+	TenantID  uuid.UUID       `json:"tenant_id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Event returns the notified event, or nil if it has to be loaded by ID
+func (n *EventNotification) Event() *models.TenantEvent {
+	if n.Type == "" {
+		return nil
+	}
+	return &models.TenantEvent{ID: n.ID, TenantID: n.TenantID, Type: n.Type, Data: n.Data, CreatedAt: n.CreatedAt}
+}
+
+// PublishTenantEvent appends an event to the tenant's stream and notifies listeners
+func (r *Repository) PublishTenantEvent(ctx context.Context, tenantID uuid.UUID, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	query := `
+		WITH inserted AS (
+			INSERT INTO tenant_events (tenant_id, event_type, payload)
+			VALUES ($1, $2, $3::jsonb)
+			RETURNING id, tenant_id, event_type, payload, created_at
+		)` + notifyInserted
+
+	if _, err := r.db.Pool.Exec(ctx, query, tenantID, eventType, string(payload)); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
+
+// PublishPassportEvent publishes an event to the tenant owning the passport,
+// adding the passport's serial_number, batch_id and batch_name to the data
+func (r *Repository) PublishPassportEvent(ctx context.Context, passportID uuid.UUID, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	query := `
+		WITH inserted AS (
+			INSERT INTO tenant_events (tenant_id, event_type, payload)
+			SELECT b.tenant_id, $2, $3::jsonb || jsonb_build_object(
+				'serial_number', p.serial_number, 'batch_id', b.id, 'batch_name', b.batch_name
+			)
+			FROM public.passports p
+			JOIN public.batches b ON b.id = p.batch_id
+			WHERE p.uuid = $1
+			RETURNING id, tenant_id, event_type, payload, created_at
+		)` + notifyInserted
+
+	if _, err := r.db.Pool.Exec(ctx, query, passportID, eventType, string(payload)); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
+
+// GetTenantEvent loads one event by ID
+func (r *Repository) GetTenantEvent(ctx context.Context, id int64) (*models.TenantEvent, error) {
+	query := `SELECT id, tenant_id, event_type, payload, created_at FROM tenant_events WHERE id = $1`
+
+	event := &models.TenantEvent{}
+	var payload []byte
+	if err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+		&event.ID, &event.TenantID, &event.Type, &payload, &event.CreatedAt,
+	); err != nil {
+		return nil, fmt.Errorf("failed to get event: %w", err)
+	}
+	event.Data = payload
+	return event, nil
+}
+
+// ListTenantEventsAfter returns up to limit of the tenant's events with ID above afterID, oldest first
+func (r *Repository) ListTenantEventsAfter(ctx context.Context, tenantID uuid.UUID, afterID int64, limit int) ([]*models.TenantEvent, error) {
+	query := `
+		SELECT id, tenant_id, event_type, payload, created_at
+		FROM tenant_events
+		WHERE tenant_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3`
+
+	rows, err := r.db.Pool.Query(ctx, query, tenantID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.TenantEvent
+	for rows.Next() {
+		event := &models.TenantEvent{}
+		var payload []byte
+		if err := rows.Scan(&event.ID, &event.TenantID, &event.Type, &payload, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		event.Data = payload
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// CleanupOldTenantEvents removes events older than the replay window
+func (r *Repository) CleanupOldTenantEvents(ctx context.Context, retention time.Duration) error {
+	query := `DELETE FROM tenant_events WHERE created_at < $1`
+	_, err := r.db.Pool.Exec(ctx, query, time.Now().Add(-retention))
+	return err
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"exportready-battery/internal/models"
+)
+
+// ============================================================================
+// IDEMPOTENCY KEYS (External API retries)
+// ============================================================================
+
+// ReserveIdempotencyKey claims a key for a new request. Returns nil when the key was
+// free (or its previous use expired or was abandoned mid-request) and is now held by
+// the caller; otherwise returns the existing record so the caller can replay, wait or reject.
+func (r *Repository) ReserveIdempotencyKey(ctx context.Context, tenantID uuid.UUID, key, fingerprint string, ttl time.Duration) (*models.IdempotencyRecord, error) {
+	// Expired rows, and in-flight rows older than any request can run, are taken over in place
+	claim := `
+		INSERT INTO api_idempotency_keys (tenant_id, idempotency_key, fingerprint, expires_at)
+		VALUES ($1, $2, $3, NOW() + $4::interval)
+		ON CONFLICT (tenant_id, idempotency_key) DO UPDATE SET
+			fingerprint = EXCLUDED.fingerprint,
+			status_code = NULL,
+			content_type = NULL,
+			response_body = NULL,
+			created_at = NOW(),
+			expires_at = EXCLUDED.expires_at
+		WHERE api_idempotency_keys.expires_at < NOW()
+		   OR (api_idempotency_keys.status_code IS NULL AND api_idempotency_keys.created_at < NOW() - INTERVAL '5 minutes')
+	`
+	tag, err := r.db.Pool.Exec(ctx, claim, tenantID, key, fingerprint, fmt.Sprintf("%d seconds", int(ttl.Seconds())))
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if tag.RowsAffected() == 1 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT tenant_id, idempotency_key, fingerprint, status_code,
+		       COALESCE(content_type, ''), response_body, created_at, expires_at
+		FROM api_idempotency_keys
+		WHERE tenant_id = $1 AND idempotency_key = $2
+	`
+	var rec models.IdempotencyRecord
+	err = r.db.Pool.QueryRow(ctx, query, tenantID, key).Scan(
+		&rec.TenantID, &rec.Key, &rec.Fingerprint, &rec.StatusCode,
+		&rec.ContentType, &rec.ResponseBody, &rec.CreatedAt, &rec.ExpiresAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	return &rec, nil
+}
+
+// CompleteIdempotencyKey stores the response for a reserved key so retries can replay it
+func (r *Repository) CompleteIdempotencyKey(ctx context.Context, tenantID uuid.UUID, key string, statusCode int, contentType string, body []byte) error {
+	query := `
+		UPDATE api_idempotency_keys
+		SET status_code = $3, content_type = $4, response_body = $5
+		WHERE tenant_id = $1 AND idempotency_key = $2
+	`
+	_, err := r.db.Pool.Exec(ctx, query, tenantID, key, statusCode, contentType, body)
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey frees a reserved key without storing a response, e.g. after a
+// server error, so the client's retry is processed again
+func (r *Repository) ReleaseIdempotencyKey(ctx context.Context, tenantID uuid.UUID, key string) error {
+	query := `DELETE FROM api_idempotency_keys WHERE tenant_id = $1 AND idempotency_key = $2 AND status_code IS NULL`
+	_, err := r.db.Pool.Exec(ctx, query, tenantID, key)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// CleanupExpiredIdempotencyKeys removes keys past their replay window
+func (r *Repository) CleanupExpiredIdempotencyKeys(ctx context.Context) error {
+	query := `DELETE FROM api_idempotency_keys WHERE expires_at < NOW()`
+	_, err := r.db.Pool.Exec(ctx, query)
+	return err
+}
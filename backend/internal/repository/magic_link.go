@@ -62,18 +62,6 @@ func (r *Repository) IsMagicTokenUsed(ctx context.Context, tokenHash string) (bo
 	return usedAt != nil, nil
 }
 
-// UpdatePassportOwner updates the current owner email on a passport
-func (r *Repository) UpdatePassportOwner(ctx context.Context, passportID uuid.UUID, email string) error {
-	query := `UPDATE passports SET current_owner_email = $1 WHERE uuid = $2`
-
-	_, err := r.db.Pool.Exec(ctx, query, email, passportID)
-	if err != nil {
-		return fmt.Errorf("failed to update passport owner: %w", err)
-	}
-
-	return nil
-}
-
 // UpdatePassportRecycledAt sets the recycled_at timestamp
 func (r *Repository) UpdatePassportRecycledAt(ctx context.Context, passportID uuid.UUID) error {
 	query := `UPDATE passports SET recycled_at = NOW() WHERE uuid = $1`
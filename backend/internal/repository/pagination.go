@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// KEYSET PAGINATION
+// ============================================================================
+// List queries order by (sort value, id) and continue from the last row seen
+// instead of using OFFSET, so deep pages stay fast and rows inserted while a
+// client scrolls do not shift results between pages.
+
+// Sort orders a cursor can be issued for. A cursor is only valid for the order it came from.
+const (
+	SortBatchesNewest      = "batches.created_at"      // created_at DESC, id DESC
+	SortPassportsNewest    = "passports.created_at"    // created_at DESC, uuid DESC
+	SortPassportsSerial    = "passports.serial_number" // serial_number ASC, uuid ASC
+	SortScansNewest        = "scans.scanned_at"        // scanned_at DESC, id DESC
+	SortTransactionsNewest = "transactions.created_at" // created_at DESC, id DESC
+	SortRecallsNewest      = "recalls.created_at"      // created_at DESC, id DESC
+	SortRecallUnitsSerial  = "recall_units.serial"     // serial_number ASC, passport uuid ASC
+	SortClaimsNewest       = "claims.created_at"       // created_at DESC, id DESC
+	SortTransfersNewest    = "transfers.created_at"    // created_at DESC, id DESC
+	SortShipmentsNewest    = "shipments.created_at"    // created_at DESC, id DESC
+)
+
+// Cursor is the position after the last row of a page
+type Cursor struct {
+	Sort string    `json:"s"`
+	Time time.Time `json:"t,omitempty"` // Sort value for time-ordered lists
+	Key  string    `json:"k,omitempty"` // Sort value for text-ordered lists
+	ID   uuid.UUID `json:"id"`          // Tie-breaker
+}
+
+// Encode returns the opaque string handed to clients as next_cursor
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a client cursor and checks it was issued for the given sort
+func DecodeCursor(s, sort string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort != sort || c.ID == uuid.Nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &c, nil
+}
+
+// PageRequest selects one page of a list. After takes precedence over Page.
+type PageRequest struct {
+	Limit     int
+	Page      int     // Legacy 1-based page/limit paging (OFFSET)
+	After     *Cursor // Keyset paging: rows after this position
+	WithTotal bool    // Also COUNT(*) the full result (costly on large tables)
+}
+
+// PageInfo describes where a page sits in the full result
+type PageInfo struct {
+	Limit      int    // Page size actually applied
+	Total      int    // -1 when not counted
+	HasMore    bool   // Another page follows
+	NextCursor string // Empty on the last page
+}
+
+// normalize applies the default and maximum page size
+func (p PageRequest) normalize(defaultLimit, maxLimit int) PageRequest {
+	if p.Limit <= 0 {
+		p.Limit = defaultLimit
+	}
+	if p.Limit > maxLimit {
+		p.Limit = maxLimit
+	}
+	if p.Page < 1 || p.After != nil {
+		p.Page = 1
+	}
+	return p
+}
+
+// keyset appends the "after cursor" condition for a (sort column, id column) order.
+// desc selects the direction; args is extended with the cursor values.
+func (p PageRequest) keyset(sortCol, idCol string, desc bool, args []interface{}) (string, []interface{}) {
+	if p.After == nil {
+		return "", args
+	}
+	var value interface{} = p.After.Time
+	if p.After.Key != "" || p.After.Time.IsZero() {
+		value = p.After.Key
+	}
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	args = append(args, value, p.After.ID)
+	return fmt.Sprintf(" AND (%s, %s) %s ($%d, $%d)", sortCol, idCol, op, len(args)-1, len(args)), args
+}
+
+// limitClause fetches one extra row so HasMore is known without counting
+func (p PageRequest) limitClause(args []interface{}) (string, []interface{}) {
+	args = append(args, p.Limit+1, (p.Page-1)*p.Limit)
+	return fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args)), args
+}
+
+// pageInfo trims the extra row and builds the next cursor from the last row kept.
+// cursorAt returns the cursor for row i; nil for orders that only page by offset.
+func pageInfo(p PageRequest, rows int, total int, cursorAt func(i int) Cursor) (int, PageInfo) {
+	info := PageInfo{Limit: p.Limit, Total: total}
+	if rows > p.Limit {
+		rows = p.Limit
+		info.HasMore = true
+	}
+	if info.HasMore && rows > 0 && cursorAt != nil {
+		info.NextCursor = cursorAt(rows - 1).Encode()
+	}
+	return rows, info
+}
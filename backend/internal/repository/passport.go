@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -32,6 +33,18 @@ func (r *Repository) CreatePassport(ctx context.Context, passport *models.Passpo
 	return nil
 }
 
+// passportCopyColumns are the columns written by the bulk passport inserts
+var passportCopyColumns = []string{"uuid", "batch_id", "serial_number", "manufacture_date", "status", "created_at"}
+
+// passportCopyRows converts passports to COPY rows
+func passportCopyRows(passports []*models.Passport) [][]interface{} {
+	rows := make([][]interface{}, len(passports))
+	for i, p := range passports {
+		rows[i] = []interface{}{p.UUID, p.BatchID, p.SerialNumber, p.ManufactureDate, p.Status, p.CreatedAt}
+	}
+	return rows
+}
+
 // CreatePassportsBatch inserts multiple passports efficiently using COPY
 func (r *Repository) CreatePassportsBatch(ctx context.Context, passports []*models.Passport) (int, error) {
 	if len(passports) == 0 {
@@ -39,18 +52,11 @@ func (r *Repository) CreatePassportsBatch(ctx context.Context, passports []*mode
 	}
 
 	// Use CopyFrom for bulk insert (much faster than individual inserts)
-	columns := []string{"uuid", "batch_id", "serial_number", "manufacture_date", "status", "created_at"}
-
-	rows := make([][]interface{}, len(passports))
-	for i, p := range passports {
-		rows[i] = []interface{}{p.UUID, p.BatchID, p.SerialNumber, p.ManufactureDate, p.Status, p.CreatedAt}
-	}
-
 	copyCount, err := r.db.Pool.CopyFrom(
 		ctx,
 		pgx.Identifier{"public", "passports"},
-		columns,
-		pgx.CopyFromRows(rows),
+		passportCopyColumns,
+		pgx.CopyFromRows(passportCopyRows(passports)),
 	)
 	if err != nil {
 		return 0, fmt.Errorf("failed to bulk insert passports: %w", err)
@@ -59,11 +65,53 @@ func (r *Repository) CreatePassportsBatch(ctx context.Context, passports []*mode
 	return int(copyCount), nil
 }
 
+// PassportImportChunk is how many passports are copied between progress callbacks
+const PassportImportChunk = 1000
+
+// CreatePassportsBatchWithProgress inserts passports like CreatePassportsBatch,
+// in chunks within one transaction, calling progress with the running count
+// after each chunk. Nothing is committed unless every chunk succeeds.
+func (r *Repository) CreatePassportsBatchWithProgress(ctx context.Context, passports []*models.Passport, progress func(inserted int)) (int, error) {
+	if len(passports) <= PassportImportChunk {
+		return r.CreatePassportsBatch(ctx, passports)
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin passport import: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	inserted := 0
+	for start := 0; start < len(passports); start += PassportImportChunk {
+		end := min(start+PassportImportChunk, len(passports))
+		copyCount, err := tx.CopyFrom(
+			ctx,
+			pgx.Identifier{"public", "passports"},
+			passportCopyColumns,
+			pgx.CopyFromRows(passportCopyRows(passports[start:end])),
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to bulk insert passports: %w", err)
+		}
+		inserted += int(copyCount)
+		if end < len(passports) {
+			progress(inserted)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit passport import: %w", err)
+	}
+	return inserted, nil
+}
+
 // GetPassport retrieves a passport by UUID
 func (r *Repository) GetPassport(ctx context.Context, id uuid.UUID) (*models.Passport, error) {
 	passport := &models.Passport{}
 
-	query := `SELECT uuid, batch_id, serial_number, manufacture_date, status, created_at 
+	query := `SELECT uuid, batch_id, serial_number, manufacture_date, status, created_at,
+	                 shipped_at, installed_at, returned_at, COALESCE(state_of_health, 100), owner_id
 	          FROM public.passports WHERE uuid = $1`
 
 	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
@@ -73,6 +121,11 @@ func (r *Repository) GetPassport(ctx context.Context, id uuid.UUID) (*models.Pas
 		&passport.ManufactureDate,
 		&passport.Status,
 		&passport.CreatedAt,
+		&passport.ShippedAt,
+		&passport.InstalledAt,
+		&passport.ReturnedAt,
+		&passport.StateOfHealth,
+		&passport.OwnerID,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -88,7 +141,8 @@ func (r *Repository) GetPassport(ctx context.Context, id uuid.UUID) (*models.Pas
 func (r *Repository) GetPassportWithSpecs(ctx context.Context, id uuid.UUID) (*models.PassportWithSpecs, error) {
 	query := `
 		SELECT p.uuid, p.batch_id, p.serial_number, p.manufacture_date, p.status, p.created_at,
-		       b.batch_name, b.specs, b.market_region,
+		       p.shipped_at, p.installed_at, p.returned_at, COALESCE(p.state_of_health, 100),
+		       b.batch_name, b.specs, b.market_region, COALESCE(b.markets, '{}'),
 		       COALESCE(b.cell_source, ''), COALESCE(b.bill_of_entry_no, ''), COALESCE(b.country_of_origin, ''),
 		       b.domestic_value_add, b.pli_compliant, b.customs_date, COALESCE(b.hsn_code, ''),
 		       t.company_name, COALESCE(t.address, ''), COALESCE(t.logo_url, ''), COALESCE(t.support_email, ''), COALESCE(t.website, ''),
@@ -105,6 +159,7 @@ func (r *Repository) GetPassportWithSpecs(ctx context.Context, id uuid.UUID) (*m
 	var batchName string
 	var specsJSON []byte
 	var marketRegion models.MarketRegion
+	var markets []string
 	var cellSource, billOfEntry, countryOrigin, hsnCode string
 	var domesticValueAdd float64
 	var pliCompliant bool
@@ -118,9 +173,14 @@ func (r *Repository) GetPassportWithSpecs(ctx context.Context, id uuid.UUID) (*m
 		&passport.ManufactureDate,
 		&passport.Status,
 		&passport.CreatedAt,
+		&passport.ShippedAt,
+		&passport.InstalledAt,
+		&passport.ReturnedAt,
+		&passport.StateOfHealth,
 		&batchName,
 		&specsJSON,
 		&marketRegion,
+		&markets,
 		&cellSource,
 		&billOfEntry,
 		&countryOrigin,
@@ -159,6 +219,7 @@ func (r *Repository) GetPassportWithSpecs(ctx context.Context, id uuid.UUID) (*m
 		BatchName:        batchName,
 		Specs:            specs,
 		MarketRegion:     marketRegion,
+		Markets:          models.StoredMarkets(marketRegion, markets),
 		Tenant:           tenant,
 		CellSource:       cellSource,
 		BillOfEntryNo:    billOfEntry,
@@ -211,6 +272,145 @@ func (r *Repository) CountPassportsByBatch(ctx context.Context, batchID uuid.UUI
 	return count, nil
 }
 
+// PassportFilter narrows tenant-wide passport listings. Empty fields match everything.
+type PassportFilter struct {
+	BatchID       *uuid.UUID
+	Status        string
+	SerialPrefix  string      // Matches serial numbers starting with this value
+	Chemistry     string      // Case-insensitive match on the batch's specs.chemistry
+	CreatedAfter  *time.Time  // created_at >= CreatedAfter
+	CreatedBefore *time.Time  // created_at < CreatedBefore
+	PassportIDs   []uuid.UUID // Restrict to these passports (bulk operations)
+	BatchIDs      []uuid.UUID // Restrict to these batches (API key restrictions)
+	MarketRegions []string    // Restrict to these markets (API key restrictions)
+}
+
+// Sort returns the order ListPassports uses: serial number within one batch
+// (matching labels and exports), newest first across batches
+func (f PassportFilter) Sort() string {
+	if f.BatchID != nil {
+		return SortPassportsSerial
+	}
+	return SortPassportsNewest
+}
+
+// where builds the WHERE clause over passports p JOIN batches b for the filter
+func (f PassportFilter) where(tenantID uuid.UUID) (string, []interface{}) {
+	where := "b.tenant_id = $1 AND b.deleted_at IS NULL"
+	args := []interface{}{tenantID}
+
+	if f.BatchID != nil {
+		args = append(args, *f.BatchID)
+		where += fmt.Sprintf(" AND p.batch_id = $%d", len(args))
+	}
+	if f.Status != "" {
+		args = append(args, f.Status)
+		where += fmt.Sprintf(" AND p.status = $%d", len(args))
+	}
+	if f.SerialPrefix != "" {
+		args = append(args, escapeLike(f.SerialPrefix)+"%")
+		where += fmt.Sprintf(" AND p.serial_number LIKE $%d", len(args))
+	}
+	if f.Chemistry != "" {
+		args = append(args, strings.ToLower(f.Chemistry))
+		where += fmt.Sprintf(" AND lower(b.specs->>'chemistry') = $%d", len(args))
+	}
+	if f.CreatedAfter != nil {
+		args = append(args, *f.CreatedAfter)
+		where += fmt.Sprintf(" AND p.created_at >= $%d", len(args))
+	}
+	if f.CreatedBefore != nil {
+		args = append(args, *f.CreatedBefore)
+		where += fmt.Sprintf(" AND p.created_at < $%d", len(args))
+	}
+	if len(f.PassportIDs) > 0 {
+		args = append(args, f.PassportIDs)
+		where += fmt.Sprintf(" AND p.uuid = ANY($%d)", len(args))
+	}
+	if len(f.BatchIDs) > 0 {
+		args = append(args, f.BatchIDs)
+		where += fmt.Sprintf(" AND p.batch_id = ANY($%d)", len(args))
+	}
+	if len(f.MarketRegions) > 0 {
+		args = append(args, f.MarketRegions)
+		where += fmt.Sprintf(" AND COALESCE(b.market_region::text, 'GLOBAL') = ANY($%d)", len(args))
+	}
+
+	return where, args
+}
+
+// ListPassports retrieves passports across all of a tenant's batches matching the filter
+func (r *Repository) ListPassports(ctx context.Context, tenantID uuid.UUID, filter PassportFilter, page PageRequest) ([]*models.Passport, PageInfo, error) {
+	page = page.normalize(50, 500)
+
+	where, args := filter.where(tenantID)
+
+	total := -1
+	if page.WithTotal {
+		countQuery := `SELECT COUNT(*) FROM public.passports p
+		               JOIN public.batches b ON p.batch_id = b.id
+		               WHERE ` + where
+		if err := r.db.Pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+			return nil, PageInfo{}, fmt.Errorf("failed to count passports: %w", err)
+		}
+	}
+
+	sort := filter.Sort()
+	var after, orderBy, limit string
+	if sort == SortPassportsSerial {
+		after, args = page.keyset("p.serial_number", "p.uuid", false, args)
+		orderBy = "p.serial_number, p.uuid"
+	} else {
+		after, args = page.keyset("p.created_at", "p.uuid", true, args)
+		orderBy = "p.created_at DESC, p.uuid DESC"
+	}
+	limit, args = page.limitClause(args)
+
+	query := `SELECT p.uuid, p.batch_id, p.serial_number, p.manufacture_date, p.status, p.created_at
+	          FROM public.passports p
+	          JOIN public.batches b ON p.batch_id = b.id
+	          WHERE ` + where + after + `
+	          ORDER BY ` + orderBy + limit
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to list passports: %w", err)
+	}
+	defer rows.Close()
+
+	passports := []*models.Passport{}
+	for rows.Next() {
+		passport := &models.Passport{}
+		if err := rows.Scan(
+			&passport.UUID,
+			&passport.BatchID,
+			&passport.SerialNumber,
+			&passport.ManufactureDate,
+			&passport.Status,
+			&passport.CreatedAt,
+		); err != nil {
+			return nil, PageInfo{}, fmt.Errorf("failed to scan passport: %w", err)
+		}
+		passports = append(passports, passport)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to list passports: %w", err)
+	}
+
+	n, info := pageInfo(page, len(passports), total, func(i int) Cursor {
+		if sort == SortPassportsSerial {
+			return Cursor{Sort: sort, Key: passports[i].SerialNumber, ID: passports[i].UUID}
+		}
+		return Cursor{Sort: sort, Time: passports[i].CreatedAt, ID: passports[i].UUID}
+	})
+	return passports[:n], info, nil
+}
+
+// escapeLike escapes LIKE wildcards so user input only matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(s)
+}
+
 // DuplicateInfo represents info about an existing serial number
 type DuplicateInfo struct {
 	SerialNumber string `json:"serial_number"`
@@ -268,6 +468,38 @@ func (r *Repository) UpdatePassportStatus(ctx context.Context, id uuid.UUID, sta
 	return nil
 }
 
+// UpdatePassportTransition stores a status change with the lifecycle timestamps
+// set on the passport; timestamps left nil keep their stored value
+func (r *Repository) UpdatePassportTransition(ctx context.Context, passport *models.Passport) error {
+	query := `UPDATE public.passports
+	          SET status = $1,
+	              shipped_at = COALESCE($2, shipped_at),
+	              installed_at = COALESCE($3, installed_at),
+	              returned_at = COALESCE($4, returned_at)
+	          WHERE uuid = $5`
+	_, err := r.db.Pool.Exec(ctx, query,
+		passport.Status,
+		passport.ShippedAt,
+		passport.InstalledAt,
+		passport.ReturnedAt,
+		passport.UUID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update passport status: %w", err)
+	}
+	return nil
+}
+
+// UpdatePassportStateOfHealth records a State of Health reading (literal percentage)
+func (r *Repository) UpdatePassportStateOfHealth(ctx context.Context, id uuid.UUID, soh float64) error {
+	query := `UPDATE public.passports SET state_of_health = $1 WHERE uuid = $2`
+	_, err := r.db.Pool.Exec(ctx, query, soh, id)
+	if err != nil {
+		return fmt.Errorf("failed to update state of health: %w", err)
+	}
+	return nil
+}
+
 // UpdatePassportLifecycle updates lifecycle-specific fields
 func (r *Repository) UpdatePassportLifecycle(ctx context.Context, passport *models.Passport) error {
 	query := `UPDATE public.passports 
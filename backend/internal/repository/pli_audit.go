@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"exportready-battery/internal/models"
+)
+
+// PLI audit errors
+var (
+	ErrPLIAuditPending    = errors.New("the batch already has a pending PLI audit request")
+	ErrPLIAuditNotPending = errors.New("the PLI audit request is no longer pending")
+)
+
+// PLIAttestation is what the chartered accountant attests. The certificate has
+// already been stored at CertificatePath by the caller.
+type PLIAttestation struct {
+	AuditedDVA          float64
+	CAName              string
+	CAMembershipNo      string
+	UDIN                string
+	CertificateFileName string
+	CertificatePath     string
+	CertificateSHA256   string
+	CertificateURL      string // API path the batch links to
+	PLICompliant        bool   // Whether the attested DVA meets the PLI threshold
+}
+
+// Pending requests past their expiry are reported as EXPIRED before they are closed
+const pliAuditColumns = `
+	id, tenant_id, batch_id, ca_email, ca_name, COALESCE(note, ''),
+	CASE WHEN status = '` + models.PLIAuditStatusPending + `' AND expires_at <= NOW()
+	     THEN '` + models.PLIAuditStatusExpired + `' ELSE status END,
+	expires_at, requested_by, created_at,
+	audited_dva::float8, COALESCE(ca_membership_no, ''), COALESCE(udin, ''),
+	COALESCE(certificate_file_name, ''), COALESCE(certificate_sha256, ''), COALESCE(certificate_path, ''),
+	attested_at, closed_at`
+
+func scanPLIAudit(row pgx.Row) (*models.PLIAudit, error) {
+	a := &models.PLIAudit{}
+	err := row.Scan(&a.ID, &a.TenantID, &a.BatchID, &a.CAEmail, &a.CAName, &a.Note,
+		&a.Status, &a.ExpiresAt, &a.RequestedBy, &a.CreatedAt,
+		&a.AuditedDVA, &a.CAMembershipNo, &a.UDIN,
+		&a.CertificateFileName, &a.CertificateSHA256, &a.CertificatePath,
+		&a.AttestedAt, &a.ClosedAt)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// appendPLIAuditLog adds an entry to the append-only audit log
+func appendPLIAuditLog(ctx context.Context, tx pgx.Tx, a *models.PLIAudit, action, actor string, details map[string]interface{}) error {
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to marshal PLI audit log details: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO pli_audit_log (tenant_id, batch_id, audit_id, action, actor, details)
+		VALUES ($1, $2, $3, $4, $5, $6::jsonb)`,
+		a.TenantID, a.BatchID, a.ID, action, actor, string(detailsJSON))
+	if err != nil {
+		return fmt.Errorf("failed to write PLI audit log: %w", err)
+	}
+	return nil
+}
+
+// CreatePLIAudit records a PENDING audit request and logs it. The batch's
+// expired requests are closed first; a pending one returns ErrPLIAuditPending.
+func (r *Repository) CreatePLIAudit(ctx context.Context, a *models.PLIAudit, tokenHash string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin PLI audit request: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE pli_audits SET status = $2, token_hash = NULL, closed_at = expires_at
+		WHERE batch_id = $1 AND status = $3 AND expires_at <= NOW()`,
+		a.BatchID, models.PLIAuditStatusExpired, models.PLIAuditStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to close expired PLI audit requests: %w", err)
+	}
+
+	a.Status = models.PLIAuditStatusPending
+	err = tx.QueryRow(ctx, `
+		INSERT INTO pli_audits (tenant_id, batch_id, ca_email, ca_name, note, status, token_hash, expires_at, requested_by)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9)
+		RETURNING id, created_at`,
+		a.TenantID, a.BatchID, a.CAEmail, a.CAName, a.Note, a.Status, tokenHash, a.ExpiresAt, a.RequestedBy,
+	).Scan(&a.ID, &a.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrPLIAuditPending
+		}
+		return fmt.Errorf("failed to create PLI audit request: %w", err)
+	}
+
+	if err := appendPLIAuditLog(ctx, tx, a, models.PLIAuditActionRequested, a.RequestedBy, map[string]interface{}{
+		"ca_email":   a.CAEmail,
+		"ca_name":    a.CAName,
+		"expires_at": a.ExpiresAt,
+	}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit PLI audit request: %w", err)
+	}
+	return nil
+}
+
+// ListPLIAudits returns a batch's audit requests, newest first
+func (r *Repository) ListPLIAudits(ctx context.Context, batchID uuid.UUID) ([]*models.PLIAudit, error) {
+	rows, err := r.db.Pool.Query(ctx, `SELECT`+pliAuditColumns+`
+		FROM pli_audits
+		WHERE batch_id = $1
+		ORDER BY created_at DESC, id DESC`, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PLI audits: %w", err)
+	}
+	defer rows.Close()
+
+	audits := []*models.PLIAudit{}
+	for rows.Next() {
+		a, err := scanPLIAudit(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan PLI audit: %w", err)
+		}
+		audits = append(audits, a)
+	}
+	return audits, rows.Err()
+}
+
+// ListPLIAuditLog returns a batch's audit log, oldest first
+func (r *Repository) ListPLIAuditLog(ctx context.Context, batchID uuid.UUID) ([]*models.PLIAuditLogEntry, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT id, audit_id, action, actor, details, created_at
+		FROM pli_audit_log
+		WHERE batch_id = $1
+		ORDER BY created_at, id`, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PLI audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*models.PLIAuditLogEntry{}
+	for rows.Next() {
+		e := &models.PLIAuditLogEntry{}
+		var detailsJSON []byte
+		if err := rows.Scan(&e.ID, &e.AuditID, &e.Action, &e.Actor, &detailsJSON, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan PLI audit log entry: %w", err)
+		}
+		if err := json.Unmarshal(detailsJSON, &e.Details); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal PLI audit log details: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// GetPLIAuditByToken returns the audit request behind a link token, nil if none
+func (r *Repository) GetPLIAuditByToken(ctx context.Context, tokenHash string) (*models.PLIAudit, error) {
+	a, err := scanPLIAudit(r.db.Pool.QueryRow(ctx, `SELECT`+pliAuditColumns+`
+		FROM pli_audits WHERE token_hash = $1`, tokenHash))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PLI audit: %w", err)
+	}
+	return a, nil
+}
+
+// GetLatestPLIAttestation returns the batch's most recent attested audit, nil if
+// it has none
+func (r *Repository) GetLatestPLIAttestation(ctx context.Context, batchID uuid.UUID) (*models.PLIAudit, error) {
+	a, err := scanPLIAudit(r.db.Pool.QueryRow(ctx, `SELECT`+pliAuditColumns+`
+		FROM pli_audits
+		WHERE batch_id = $1 AND status = $2
+		ORDER BY attested_at DESC
+		LIMIT 1`, batchID, models.PLIAuditStatusAttested))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PLI attestation: %w", err)
+	}
+	return a, nil
+}
+
+// CancelPLIAudit withdraws a batch's pending audit request and logs it. Returns
+// nil if the request does not exist, ErrPLIAuditNotPending if it is closed.
+func (r *Repository) CancelPLIAudit(ctx context.Context, tenantID, batchID, auditID uuid.UUID, actor string) (*models.PLIAudit, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin PLI audit cancellation: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	a, err := scanPLIAudit(tx.QueryRow(ctx, `SELECT`+pliAuditColumns+`
+		FROM pli_audits
+		WHERE id = $1 AND tenant_id = $2 AND batch_id = $3
+		FOR UPDATE`, auditID, tenantID, batchID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PLI audit: %w", err)
+	}
+	if a.Status != models.PLIAuditStatusPending {
+		return nil, ErrPLIAuditNotPending
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE pli_audits SET status = $2, token_hash = NULL, closed_at = NOW()
+		WHERE id = $1
+		RETURNING closed_at`, a.ID, models.PLIAuditStatusCancelled).Scan(&a.ClosedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel PLI audit: %w", err)
+	}
+	a.Status = models.PLIAuditStatusCancelled
+
+	if err := appendPLIAuditLog(ctx, tx, a, models.PLIAuditActionCancelled, actor, map[string]interface{}{
+		"ca_email": a.CAEmail,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit PLI audit cancellation: %w", err)
+	}
+	return a, nil
+}
+
+// AttestPLIAudit records the CA's attestation of the pending, unexpired request
+// behind a link token, invalidates the token and makes the attested DVA the
+// batch's, all in one transaction with the log entry. Returns
+// ErrPLIAuditNotPending if the request is no longer open.
+func (r *Repository) AttestPLIAudit(ctx context.Context, tokenHash string, att PLIAttestation) (*models.PLIAudit, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin PLI attestation: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	a, err := scanPLIAudit(tx.QueryRow(ctx, `SELECT`+pliAuditColumns+`
+		FROM pli_audits
+		WHERE token_hash = $1
+		FOR UPDATE`, tokenHash))
+	if err == pgx.ErrNoRows {
+		return nil, ErrPLIAuditNotPending
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PLI audit: %w", err)
+	}
+	if a.Status != models.PLIAuditStatusPending {
+		return nil, ErrPLIAuditNotPending
+	}
+
+	// The batch's values before the attestation go into the log
+	var previousSource string
+	var previousDVA float64
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(dva_source, ''), COALESCE(domestic_value_add, 0)::float8
+		FROM public.batches
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE`, a.BatchID).Scan(&previousSource, &previousDVA)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("batch not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock batch for PLI attestation: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE pli_audits SET
+			status = $2, token_hash = NULL, ca_name = $3, audited_dva = $4, ca_membership_no = $5, udin = $6,
+			certificate_file_name = $7, certificate_path = $8, certificate_sha256 = $9, attested_at = NOW()
+		WHERE id = $1
+		RETURNING attested_at`,
+		a.ID, models.PLIAuditStatusAttested, att.CAName, att.AuditedDVA, att.CAMembershipNo, att.UDIN,
+		att.CertificateFileName, att.CertificatePath, att.CertificateSHA256,
+	).Scan(&a.AttestedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record PLI attestation: %w", err)
+	}
+	a.Status = models.PLIAuditStatusAttested
+	a.CAName = att.CAName
+	dva := att.AuditedDVA
+	a.AuditedDVA = &dva
+	a.CAMembershipNo = att.CAMembershipNo
+	a.UDIN = att.UDIN
+	a.CertificateFileName = att.CertificateFileName
+	a.CertificatePath = att.CertificatePath
+	a.CertificateSHA256 = att.CertificateSHA256
+
+	_, err = tx.Exec(ctx, `
+		UPDATE public.batches SET
+			dva_source = $2, audited_domestic_value_add = $3, domestic_value_add = $3,
+			pli_compliant = $4, pli_certificate_url = $5
+		WHERE id = $1`,
+		a.BatchID, models.DVASourceAudited, att.AuditedDVA, att.PLICompliant, att.CertificateURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update batch DVA: %w", err)
+	}
+
+	if err := appendPLIAuditLog(ctx, tx, a, models.PLIAuditActionAttested, a.CAEmail, map[string]interface{}{
+		"audited_dva":                 att.AuditedDVA,
+		"ca_name":                     att.CAName,
+		"ca_membership_no":            att.CAMembershipNo,
+		"udin":                        att.UDIN,
+		"certificate_file_name":       att.CertificateFileName,
+		"certificate_sha256":          att.CertificateSHA256,
+		"pli_compliant":               att.PLICompliant,
+		"previous_dva_source":         previousSource,
+		"previous_domestic_value_add": previousDVA,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit PLI attestation: %w", err)
+	}
+	return a, nil
+}
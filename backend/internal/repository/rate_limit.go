@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ============================================================================
+// RATE LIMIT BUCKETS (Shared token bucket store)
+// ============================================================================
+
+// TakeRateLimitToken refills a token bucket and tries to take one token in a
+// single atomic statement, so concurrent instances share the same budget.
+// Returns the tokens left after the request and whether it was allowed.
+func (r *Repository) TakeRateLimitToken(ctx context.Context, bucketKey string, capacity int, refillPerSecond float64) (float64, bool, error) {
+	query := `
+		INSERT INTO api_rate_limit_buckets (bucket_key, tokens, last_allowed, updated_at)
+		VALUES ($1, $2::double precision - 1, TRUE, clock_timestamp())
+		ON CONFLICT (bucket_key) DO UPDATE SET
+			tokens = CASE
+				WHEN LEAST($2::double precision, api_rate_limit_buckets.tokens + EXTRACT(EPOCH FROM clock_timestamp() - api_rate_limit_buckets.updated_at) * $3::double precision) >= 1
+				THEN LEAST($2::double precision, api_rate_limit_buckets.tokens + EXTRACT(EPOCH FROM clock_timestamp() - api_rate_limit_buckets.updated_at) * $3::double precision) - 1
+				ELSE LEAST($2::double precision, api_rate_limit_buckets.tokens + EXTRACT(EPOCH FROM clock_timestamp() - api_rate_limit_buckets.updated_at) * $3::double precision)
+			END,
+			last_allowed = LEAST($2::double precision, api_rate_limit_buckets.tokens + EXTRACT(EPOCH FROM clock_timestamp() - api_rate_limit_buckets.updated_at) * $3::double precision) >= 1,
+			updated_at = clock_timestamp()
+		RETURNING tokens, last_allowed
+	`
+
+	var tokens float64
+	var allowed bool
+	err := r.db.Pool.QueryRow(ctx, query, bucketKey, capacity, refillPerSecond).Scan(&tokens, &allowed)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+
+	return tokens, allowed, nil
+}
+
+// CleanupRateLimitBuckets removes buckets idle for longer than maxIdle (they would be full anyway)
+func (r *Repository) CleanupRateLimitBuckets(ctx context.Context, maxIdle time.Duration) error {
+	query := `DELETE FROM api_rate_limit_buckets WHERE updated_at < NOW() - $1::interval`
+	_, err := r.db.Pool.Exec(ctx, query, fmt.Sprintf("%d seconds", int(maxIdle.Seconds())))
+	return err
+}
@@ -0,0 +1,480 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"exportready-battery/internal/models"
+)
+
+// ErrRecallNoPassports means a recall scope matched no recallable passports
+var ErrRecallNoPassports = errors.New("no recallable passports match the scope")
+
+// recallScopeWhere builds the condition on passports p JOIN batches b for a
+// tenant's passports in a recall scope. Dates must already be validated.
+func recallScopeWhere(tenantID uuid.UUID, scope models.RecallScope, args []interface{}) (string, []interface{}) {
+	args = append(args, tenantID)
+	where := fmt.Sprintf("b.tenant_id = $%d AND b.deleted_at IS NULL", len(args))
+
+	if len(scope.BatchIDs) > 0 {
+		args = append(args, scope.BatchIDs)
+		where += fmt.Sprintf(" AND b.id = ANY($%d)", len(args))
+	}
+	if scope.SerialFrom != "" {
+		args = append(args, scope.SerialFrom)
+		where += fmt.Sprintf(" AND p.serial_number >= $%d", len(args))
+	}
+	if scope.SerialTo != "" {
+		args = append(args, scope.SerialTo)
+		where += fmt.Sprintf(" AND p.serial_number <= $%d", len(args))
+	}
+	if scope.ManufacturedFrom != "" {
+		args = append(args, scope.ManufacturedFrom)
+		where += fmt.Sprintf(" AND p.manufacture_date >= $%d::date", len(args))
+	}
+	if scope.ManufacturedTo != "" {
+		args = append(args, scope.ManufacturedTo)
+		where += fmt.Sprintf(" AND p.manufacture_date < $%d::date + 1", len(args))
+	}
+	if scope.Chemistry != "" {
+		args = append(args, strings.ToLower(scope.Chemistry))
+		where += fmt.Sprintf(" AND lower(b.specs->>'chemistry') = $%d", len(args))
+	}
+	return where, args
+}
+
+// PreviewRecall counts the passports a campaign with this scope would cover
+func (r *Repository) PreviewRecall(ctx context.Context, tenantID uuid.UUID, scope models.RecallScope) (*models.RecallPreview, error) {
+	where, args := recallScopeWhere(tenantID, scope, nil)
+	query := `
+		SELECT p.status, COUNT(*), COUNT(NULLIF(p.current_owner_email, ''))
+		FROM public.passports p
+		JOIN public.batches b ON b.id = p.batch_id
+		WHERE ` + where + `
+		GROUP BY p.status`
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to preview recall: %w", err)
+	}
+	defer rows.Close()
+
+	preview := &models.RecallPreview{ByStatus: map[string]int{}}
+	for rows.Next() {
+		var status string
+		var count, owners int
+		if err := rows.Scan(&status, &count, &owners); err != nil {
+			return nil, fmt.Errorf("failed to scan recall preview: %w", err)
+		}
+		if !models.IsRecallable(status) {
+			preview.Excluded += count
+			continue
+		}
+		preview.ByStatus[status] = count
+		preview.Affected += count
+		preview.OwnersKnown += owners
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to preview recall: %w", err)
+	}
+	return preview, nil
+}
+
+// CreateRecallCampaign stores the campaign, records every recallable passport in
+// scope as a unit and moves those not already recalled to RECALLED with a
+// passport event, all in one transaction. It fills in campaign.ID, CreatedAt and
+// AffectedCount and returns how many passports changed status.
+func (r *Repository) CreateRecallCampaign(ctx context.Context, campaign *models.RecallCampaign) (int, error) {
+	scopeJSON, err := json.Marshal(campaign.Scope)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal recall scope: %w", err)
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin recall: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO recall_campaigns
+			(tenant_id, title, reason, severity, regulator_reference, remedy_instructions, scope, status, created_by)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7::jsonb, $8, $9)
+		RETURNING id, created_at`,
+		campaign.TenantID, campaign.Title, campaign.Reason, campaign.Severity,
+		campaign.RegulatorReference, campaign.RemedyInstructions, string(scopeJSON),
+		models.RecallStatusActive, campaign.CreatedBy,
+	).Scan(&campaign.ID, &campaign.CreatedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create recall campaign: %w", err)
+	}
+	campaign.Status = models.RecallStatusActive
+
+	// Lock the passports in scope so concurrent transitions cannot slip past the recall
+	where, args := recallScopeWhere(campaign.TenantID, campaign.Scope, []interface{}{campaign.ID})
+	units, err := tx.Exec(ctx, `
+		INSERT INTO recall_units (campaign_id, passport_id, previous_status, owner_email)
+		SELECT $1, p.uuid, p.status, NULLIF(p.current_owner_email, '')
+		FROM public.passports p
+		JOIN public.batches b ON b.id = p.batch_id
+		WHERE `+where+`
+		  AND p.status NOT IN ('`+models.PassportStatusRecycled+`', '`+models.PassportStatusEndOfLife+`')
+		FOR UPDATE OF p`, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record recall units: %w", err)
+	}
+	if units.RowsAffected() == 0 {
+		return 0, ErrRecallNoPassports
+	}
+	campaign.AffectedCount = int(units.RowsAffected())
+
+	transitioned, err := tx.Exec(ctx, `
+		UPDATE public.passports p
+		SET status = $2
+		FROM recall_units u
+		WHERE u.campaign_id = $1 AND u.passport_id = p.uuid AND p.status <> $2`,
+		campaign.ID, models.PassportStatusRecalled)
+	if err != nil {
+		return 0, fmt.Errorf("failed to recall passports: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO public.passport_events (id, passport_id, event_type, actor, metadata, created_at)
+		SELECT gen_random_uuid(), u.passport_id, $2, $3,
+		       jsonb_build_object(
+		           'previous_status', u.previous_status, 'new_status', $4::text,
+		           'recall_campaign_id', $1::uuid, 'reason', $5::text
+		       ),
+		       NOW()
+		FROM recall_units u
+		WHERE u.campaign_id = $1 AND u.previous_status <> $4`,
+		campaign.ID, models.PassportEventRecalled, campaign.CreatedBy, models.PassportStatusRecalled, campaign.Reason)
+	if err != nil {
+		return 0, fmt.Errorf("failed to log recall events: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit recall: %w", err)
+	}
+	return int(transitioned.RowsAffected()), nil
+}
+
+const recallCampaignColumns = `
+	c.id, c.tenant_id, c.title, c.reason, c.severity,
+	COALESCE(c.regulator_reference, ''), COALESCE(c.remedy_instructions, ''),
+	c.scope, c.status, c.created_by, c.created_at, c.closed_at,
+	(SELECT COUNT(*) FROM recall_units u WHERE u.campaign_id = c.id)`
+
+func scanRecallCampaign(row pgx.Row) (*models.RecallCampaign, error) {
+	c := &models.RecallCampaign{}
+	var scopeJSON []byte
+	err := row.Scan(
+		&c.ID, &c.TenantID, &c.Title, &c.Reason, &c.Severity,
+		&c.RegulatorReference, &c.RemedyInstructions,
+		&scopeJSON, &c.Status, &c.CreatedBy, &c.CreatedAt, &c.ClosedAt,
+		&c.AffectedCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(scopeJSON, &c.Scope); err != nil {
+		return nil, fmt.Errorf("failed to parse recall scope: %w", err)
+	}
+	return c, nil
+}
+
+// GetRecallCampaign retrieves a tenant's campaign; nil if it does not exist
+func (r *Repository) GetRecallCampaign(ctx context.Context, tenantID, id uuid.UUID) (*models.RecallCampaign, error) {
+	query := `SELECT ` + recallCampaignColumns + ` FROM recall_campaigns c WHERE c.id = $1 AND c.tenant_id = $2`
+
+	c, err := scanRecallCampaign(r.db.Pool.QueryRow(ctx, query, id, tenantID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recall campaign: %w", err)
+	}
+	return c, nil
+}
+
+// ListRecallCampaigns retrieves a tenant's campaigns, newest first. status filters when set.
+func (r *Repository) ListRecallCampaigns(ctx context.Context, tenantID uuid.UUID, status string, page PageRequest) ([]*models.RecallCampaign, PageInfo, error) {
+	page = page.normalize(20, 100)
+
+	where := "c.tenant_id = $1"
+	args := []interface{}{tenantID}
+	if status != "" {
+		args = append(args, status)
+		where += fmt.Sprintf(" AND c.status = $%d", len(args))
+	}
+
+	var after, limit string
+	after, args = page.keyset("c.created_at", "c.id", true, args)
+	limit, args = page.limitClause(args)
+
+	query := `SELECT ` + recallCampaignColumns + `
+		FROM recall_campaigns c
+		WHERE ` + where + after + `
+		ORDER BY c.created_at DESC, c.id DESC` + limit
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to list recall campaigns: %w", err)
+	}
+	defer rows.Close()
+
+	var campaigns []*models.RecallCampaign
+	for rows.Next() {
+		c, err := scanRecallCampaign(rows)
+		if err != nil {
+			return nil, PageInfo{}, fmt.Errorf("failed to scan recall campaign: %w", err)
+		}
+		campaigns = append(campaigns, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to list recall campaigns: %w", err)
+	}
+
+	n, info := pageInfo(page, len(campaigns), -1, func(i int) Cursor {
+		return Cursor{Sort: SortRecallsNewest, Time: campaigns[i].CreatedAt, ID: campaigns[i].ID}
+	})
+	return campaigns[:n], info, nil
+}
+
+// CloseRecallCampaign ends an active campaign; the passport banner disappears.
+// Returns false if the campaign is not an active campaign of the tenant.
+func (r *Repository) CloseRecallCampaign(ctx context.Context, tenantID, id uuid.UUID) (bool, error) {
+	query := `UPDATE recall_campaigns SET status = $3, closed_at = NOW()
+	          WHERE id = $1 AND tenant_id = $2 AND status = $4`
+
+	result, err := r.db.Pool.Exec(ctx, query, id, tenantID, models.RecallStatusClosed, models.RecallStatusActive)
+	if err != nil {
+		return false, fmt.Errorf("failed to close recall campaign: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// GetRecallProgress summarizes a campaign's remedy statuses
+func (r *Repository) GetRecallProgress(ctx context.Context, campaignID uuid.UUID) (*models.RecallProgress, error) {
+	query := `
+		SELECT remedy_status, COUNT(*), COUNT(owner_email), COUNT(notified_at)
+		FROM recall_units
+		WHERE campaign_id = $1
+		GROUP BY remedy_status`
+
+	rows, err := r.db.Pool.Query(ctx, query, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recall progress: %w", err)
+	}
+	defer rows.Close()
+
+	progress := &models.RecallProgress{ByRemedyStatus: map[string]int{
+		models.RemedyPending:  0,
+		models.RemedyNotified: 0,
+		models.RemedyReturned: 0,
+		models.RemedyReplaced: 0,
+		models.RemedyRecycled: 0,
+	}}
+	for rows.Next() {
+		var status string
+		var count, owners, notified int
+		if err := rows.Scan(&status, &count, &owners, &notified); err != nil {
+			return nil, fmt.Errorf("failed to scan recall progress: %w", err)
+		}
+		progress.ByRemedyStatus[status] = count
+		progress.Total += count
+		progress.OwnersKnown += owners
+		progress.Notified += notified
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get recall progress: %w", err)
+	}
+
+	progress.Resolved = progress.ByRemedyStatus[models.RemedyReturned] +
+		progress.ByRemedyStatus[models.RemedyReplaced] +
+		progress.ByRemedyStatus[models.RemedyRecycled]
+	if progress.Total > 0 {
+		progress.ResolvedPercent = float64(progress.Resolved*10000/progress.Total) / 100
+	}
+	return progress, nil
+}
+
+// ListRecallUnits retrieves a campaign's units in serial order. remedyStatus filters when set.
+func (r *Repository) ListRecallUnits(ctx context.Context, campaignID uuid.UUID, remedyStatus string, page PageRequest) ([]*models.RecallUnit, PageInfo, error) {
+	page = page.normalize(50, 500)
+
+	where := "u.campaign_id = $1"
+	args := []interface{}{campaignID}
+	if remedyStatus != "" {
+		args = append(args, remedyStatus)
+		where += fmt.Sprintf(" AND u.remedy_status = $%d", len(args))
+	}
+
+	var after, limit string
+	after, args = page.keyset("p.serial_number", "p.uuid", false, args)
+	limit, args = page.limitClause(args)
+
+	query := `
+		SELECT p.uuid, p.serial_number, p.batch_id, p.status, u.previous_status,
+		       COALESCE(u.owner_email, ''), u.remedy_status, u.notified_at, u.remedied_at,
+		       COALESCE(u.note, ''), u.updated_at
+		FROM recall_units u
+		JOIN public.passports p ON p.uuid = u.passport_id
+		WHERE ` + where + after + `
+		ORDER BY p.serial_number, p.uuid` + limit
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to list recall units: %w", err)
+	}
+	defer rows.Close()
+
+	var units []*models.RecallUnit
+	for rows.Next() {
+		u := &models.RecallUnit{}
+		if err := rows.Scan(
+			&u.PassportID, &u.SerialNumber, &u.BatchID, &u.PassportStatus, &u.PreviousStatus,
+			&u.OwnerEmail, &u.RemedyStatus, &u.NotifiedAt, &u.RemediedAt,
+			&u.Note, &u.UpdatedAt,
+		); err != nil {
+			return nil, PageInfo{}, fmt.Errorf("failed to scan recall unit: %w", err)
+		}
+		units = append(units, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to list recall units: %w", err)
+	}
+
+	n, info := pageInfo(page, len(units), -1, func(i int) Cursor {
+		return Cursor{Sort: SortRecallUnitsSerial, Key: units[i].SerialNumber, ID: units[i].PassportID}
+	})
+	return units[:n], info, nil
+}
+
+// RecallOwner is an owner to notify with the units of theirs a campaign covers
+type RecallOwner struct {
+	Email string
+	Units []RecallOwnerUnit
+}
+
+// RecallOwnerUnit is a unit listed in a recall notice
+type RecallOwnerUnit struct {
+	PassportID   uuid.UUID
+	SerialNumber string
+}
+
+// ListRecallOwnersToNotify picks up owner emails set on passports since the
+// campaign started, then returns each owner with units not yet notified
+func (r *Repository) ListRecallOwnersToNotify(ctx context.Context, campaignID uuid.UUID) ([]*RecallOwner, error) {
+	_, err := r.db.Pool.Exec(ctx, `
+		UPDATE recall_units u
+		SET owner_email = p.current_owner_email, updated_at = NOW()
+		FROM public.passports p
+		WHERE u.campaign_id = $1 AND u.passport_id = p.uuid AND u.notified_at IS NULL
+		  AND NULLIF(p.current_owner_email, '') IS NOT NULL
+		  AND p.current_owner_email IS DISTINCT FROM u.owner_email`, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh recall owners: %w", err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT lower(u.owner_email), p.uuid, p.serial_number
+		FROM recall_units u
+		JOIN public.passports p ON p.uuid = u.passport_id
+		WHERE u.campaign_id = $1 AND u.owner_email IS NOT NULL AND u.notified_at IS NULL
+		ORDER BY lower(u.owner_email), p.serial_number`, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recall owners: %w", err)
+	}
+	defer rows.Close()
+
+	var owners []*RecallOwner
+	for rows.Next() {
+		var email string
+		var unit RecallOwnerUnit
+		if err := rows.Scan(&email, &unit.PassportID, &unit.SerialNumber); err != nil {
+			return nil, fmt.Errorf("failed to scan recall owner: %w", err)
+		}
+		if len(owners) == 0 || owners[len(owners)-1].Email != email {
+			owners = append(owners, &RecallOwner{Email: email})
+		}
+		last := owners[len(owners)-1]
+		last.Units = append(last.Units, unit)
+	}
+	return owners, rows.Err()
+}
+
+// MarkRecallOwnerNotified records that an owner was emailed about their units
+func (r *Repository) MarkRecallOwnerNotified(ctx context.Context, campaignID uuid.UUID, email string) error {
+	query := `
+		UPDATE recall_units
+		SET notified_at = NOW(), updated_at = NOW(),
+		    remedy_status = CASE WHEN remedy_status = $3 THEN $4 ELSE remedy_status END
+		WHERE campaign_id = $1 AND lower(owner_email) = $2 AND notified_at IS NULL`
+
+	_, err := r.db.Pool.Exec(ctx, query, campaignID, email, models.RemedyPending, models.RemedyNotified)
+	if err != nil {
+		return fmt.Errorf("failed to mark recall owner notified: %w", err)
+	}
+	return nil
+}
+
+// UpdateRecallRemedies sets the remedy status of units in a campaign and returns
+// the passports that were updated
+func (r *Repository) UpdateRecallRemedies(ctx context.Context, campaignID uuid.UUID, passportIDs []uuid.UUID, status, note string) ([]uuid.UUID, error) {
+	query := `
+		UPDATE recall_units
+		SET remedy_status = $3,
+		    note = COALESCE(NULLIF($4, ''), note),
+		    remedied_at = CASE WHEN $3 = $5 THEN remedied_at ELSE NOW() END,
+		    updated_at = NOW()
+		WHERE campaign_id = $1 AND passport_id = ANY($2)
+		RETURNING passport_id`
+
+	rows, err := r.db.Pool.Query(ctx, query, campaignID, passportIDs, status, note, models.RemedyNotified)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update recall remedies: %w", err)
+	}
+	defer rows.Close()
+
+	var updated []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan recall unit: %w", err)
+		}
+		updated = append(updated, id)
+	}
+	return updated, rows.Err()
+}
+
+// GetActiveRecallForPassport returns the newest active recall covering a passport, or nil
+func (r *Repository) GetActiveRecallForPassport(ctx context.Context, passportID uuid.UUID) (*models.RecallNotice, error) {
+	query := `
+		SELECT c.id, c.title, c.severity, c.reason,
+		       COALESCE(c.remedy_instructions, ''), COALESCE(c.regulator_reference, ''), c.created_at
+		FROM recall_units u
+		JOIN recall_campaigns c ON c.id = u.campaign_id
+		WHERE u.passport_id = $1 AND c.status = $2
+		ORDER BY c.created_at DESC
+		LIMIT 1`
+
+	n := &models.RecallNotice{}
+	err := r.db.Pool.QueryRow(ctx, query, passportID, models.RecallStatusActive).Scan(
+		&n.CampaignID, &n.Title, &n.Severity, &n.Reason,
+		&n.RemedyInstructions, &n.RegulatorReference, &n.IssuedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get passport recall: %w", err)
+	}
+	return n, nil
+}
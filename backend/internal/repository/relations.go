@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"exportready-battery/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// BATCHED RELATION LOADERS
+// ============================================================================
+// Each loader fetches one relation for many parents in a single query (a LATERAL
+// join keeps the per-parent limit), so the GraphQL API resolves a list of N
+// parents with one query per relation instead of N.
+//
+// Callers pass parent IDs they already resolved within the tenant.
+
+// ListPassportsForBatches returns up to limit passports per batch in serial order
+func (r *Repository) ListPassportsForBatches(ctx context.Context, batchIDs []uuid.UUID, status string, limit int) (map[uuid.UUID][]*models.Passport, error) {
+	query := `SELECT p.uuid, p.batch_id, p.serial_number, p.manufacture_date, p.status, p.created_at
+	          FROM unnest($1::uuid[]) AS parent(id)
+	          CROSS JOIN LATERAL (
+	              SELECT * FROM public.passports
+	              WHERE batch_id = parent.id AND ($2 = '' OR status = $2)
+	              ORDER BY serial_number, uuid
+	              LIMIT $3
+	          ) p`
+
+	rows, err := r.db.Pool.Query(ctx, query, batchIDs, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list passports for batches: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[uuid.UUID][]*models.Passport, len(batchIDs))
+	for rows.Next() {
+		passport := &models.Passport{}
+		if err := rows.Scan(
+			&passport.UUID,
+			&passport.BatchID,
+			&passport.SerialNumber,
+			&passport.ManufactureDate,
+			&passport.Status,
+			&passport.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan passport: %w", err)
+		}
+		result[passport.BatchID] = append(result[passport.BatchID], passport)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list passports for batches: %w", err)
+	}
+	return result, nil
+}
+
+// GetPassportEventsForPassports returns up to limit events per passport, newest first
+func (r *Repository) GetPassportEventsForPassports(ctx context.Context, passportIDs []uuid.UUID, limit int) (map[uuid.UUID][]*models.PassportEvent, error) {
+	query := `SELECT e.id, e.passport_id, e.event_type, e.actor, e.metadata, e.created_at
+	          FROM unnest($1::uuid[]) AS parent(id)
+	          CROSS JOIN LATERAL (
+	              SELECT * FROM public.passport_events
+	              WHERE passport_id = parent.id
+	              ORDER BY created_at DESC
+	              LIMIT $2
+	          ) e`
+
+	rows, err := r.db.Pool.Query(ctx, query, passportIDs, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get passport events: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[uuid.UUID][]*models.PassportEvent, len(passportIDs))
+	for rows.Next() {
+		event := &models.PassportEvent{}
+		var metadataJSON []byte
+		if err := rows.Scan(
+			&event.ID,
+			&event.PassportID,
+			&event.EventType,
+			&event.Actor,
+			&metadataJSON,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		if len(metadataJSON) > 0 {
+			if err := json.Unmarshal(metadataJSON, &event.Metadata); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal event metadata: %w", err)
+			}
+		}
+		result[event.PassportID] = append(result[event.PassportID], event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get passport events: %w", err)
+	}
+	return result, nil
+}
+
+// GetScanEventsForPassports returns up to limit QR scans per passport, newest first
+func (r *Repository) GetScanEventsForPassports(ctx context.Context, passportIDs []uuid.UUID, limit int) (map[uuid.UUID][]*models.ScanEvent, error) {
+	query := `SELECT s.id, s.passport_id, COALESCE(s.city, ''), COALESCE(s.country, ''),
+	                 COALESCE(s.device_type, ''), s.scanned_at
+	          FROM unnest($1::uuid[]) AS parent(id)
+	          CROSS JOIN LATERAL (
+	              SELECT * FROM public.scan_events
+	              WHERE passport_id = parent.id
+	              ORDER BY scanned_at DESC
+	              LIMIT $2
+	          ) s`
+
+	rows, err := r.db.Pool.Query(ctx, query, passportIDs, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scan events: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[uuid.UUID][]*models.ScanEvent, len(passportIDs))
+	for rows.Next() {
+		scan := &models.ScanEvent{}
+		if err := rows.Scan(
+			&scan.ID,
+			&scan.PassportID,
+			&scan.City,
+			&scan.Country,
+			&scan.DeviceType,
+			&scan.ScannedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan scan event: %w", err)
+		}
+		result[scan.PassportID] = append(result[scan.PassportID], scan)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get scan events: %w", err)
+	}
+	return result, nil
+}
+
+// GetRewardEntriesForPassports returns up to limit reward ledger entries per passport, newest first
+func (r *Repository) GetRewardEntriesForPassports(ctx context.Context, tenantID uuid.UUID, passportIDs []uuid.UUID, limit int) (map[uuid.UUID][]*models.RewardLedger, error) {
+	query := `SELECT l.id, l.tenant_id, l.recipient_email, l.passport_uuid, l.action_type, l.points_earned, l.created_at
+	          FROM unnest($2::uuid[]) AS parent(id)
+	          CROSS JOIN LATERAL (
+	              SELECT * FROM public.reward_ledger
+	              WHERE tenant_id = $1 AND passport_uuid = parent.id
+	              ORDER BY created_at DESC
+	              LIMIT $3
+	          ) l`
+
+	rows, err := r.db.Pool.Query(ctx, query, tenantID, passportIDs, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reward entries: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[uuid.UUID][]*models.RewardLedger, len(passportIDs))
+	for rows.Next() {
+		entry := &models.RewardLedger{}
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.TenantID,
+			&entry.RecipientEmail,
+			&entry.PassportUUID,
+			&entry.ActionType,
+			&entry.PointsEarned,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan reward entry: %w", err)
+		}
+		result[*entry.PassportUUID] = append(result[*entry.PassportUUID], entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get reward entries: %w", err)
+	}
+	return result, nil
+}
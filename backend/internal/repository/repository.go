@@ -6,10 +6,14 @@ import (
 
 // Repository handles all database operations
 type Repository struct {
-	db *db.DB
+	db      *db.DB
+	apiKeys *apiKeyCache
 }
 
 // New creates a new Repository
 func New(database *db.DB) *Repository {
-	return &Repository{db: database}
+	return &Repository{
+		db:      database,
+		apiKeys: newAPIKeyCache(apiKeyCacheTTL),
+	}
 }
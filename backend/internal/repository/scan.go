@@ -45,10 +45,44 @@ func (r *Repository) GetLastScanTime(ctx context.Context, passportID uuid.UUID)
 	return &scannedAt, nil
 }
 
-// GetScanFeed retrieves recent scans for the live feed
-func (r *Repository) GetScanFeed(ctx context.Context, tenantID uuid.UUID, limit int) ([]*models.ScanFeedItem, error) {
+// ScanFilter narrows the scan feed. Empty fields match everything.
+type ScanFilter struct {
+	SerialPrefix  string     // Passport serial numbers starting with this value
+	MarketRegions []string   // Batch market regions
+	CreatedAfter  *time.Time // scanned_at >= CreatedAfter
+	CreatedBefore *time.Time // scanned_at < CreatedBefore
+}
+
+// GetScanFeed retrieves recent scans for the live feed, newest first
+func (r *Repository) GetScanFeed(ctx context.Context, tenantID uuid.UUID, filter ScanFilter, page PageRequest) ([]*models.ScanFeedItem, PageInfo, error) {
+	page = page.normalize(10, 100)
+
+	where := "b.tenant_id = $1"
+	args := []interface{}{tenantID}
+	if filter.SerialPrefix != "" {
+		args = append(args, escapeLike(filter.SerialPrefix)+"%")
+		where += fmt.Sprintf(" AND p.serial_number LIKE $%d", len(args))
+	}
+	if len(filter.MarketRegions) > 0 {
+		args = append(args, filter.MarketRegions)
+		where += fmt.Sprintf(" AND COALESCE(b.market_region::text, 'GLOBAL') = ANY($%d)", len(args))
+	}
+	if filter.CreatedAfter != nil {
+		args = append(args, *filter.CreatedAfter)
+		where += fmt.Sprintf(" AND s.scanned_at >= $%d", len(args))
+	}
+	if filter.CreatedBefore != nil {
+		args = append(args, *filter.CreatedBefore)
+		where += fmt.Sprintf(" AND s.scanned_at < $%d", len(args))
+	}
+
+	var after, limit string
+	after, args = page.keyset("s.scanned_at", "s.id", true, args)
+	limit, args = page.limitClause(args)
+
 	query := `
 		SELECT 
+			s.id,
 			s.city, 
 			s.country, 
 			s.device_type, 
@@ -58,14 +92,12 @@ func (r *Repository) GetScanFeed(ctx context.Context, tenantID uuid.UUID, limit
 		FROM public.scan_events s
 		JOIN public.passports p ON s.passport_id = p.uuid
 		JOIN public.batches b ON p.batch_id = b.id
-		WHERE b.tenant_id = $1
-		ORDER BY s.scanned_at DESC
-		LIMIT $2
-	`
+		WHERE ` + where + after + `
+		ORDER BY s.scanned_at DESC, s.id DESC` + limit
 
-	rows, err := r.db.Pool.Query(ctx, query, tenantID, limit)
+	rows, err := r.db.Pool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get scan feed: %w", err)
+		return nil, PageInfo{}, fmt.Errorf("failed to get scan feed: %w", err)
 	}
 	defer rows.Close()
 
@@ -73,6 +105,7 @@ func (r *Repository) GetScanFeed(ctx context.Context, tenantID uuid.UUID, limit
 	for rows.Next() {
 		item := &models.ScanFeedItem{}
 		if err := rows.Scan(
+			&item.ID,
 			&item.City,
 			&item.Country,
 			&item.DeviceType,
@@ -80,12 +113,18 @@ func (r *Repository) GetScanFeed(ctx context.Context, tenantID uuid.UUID, limit
 			&item.SerialNumber,
 			&item.BatchName,
 		); err != nil {
-			return nil, fmt.Errorf("failed to scan feed item: %w", err)
+			return nil, PageInfo{}, fmt.Errorf("failed to scan feed item: %w", err)
 		}
 		items = append(items, item)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to get scan feed: %w", err)
+	}
 
-	return items, nil
+	n, info := pageInfo(page, len(items), -1, func(i int) Cursor {
+		return Cursor{Sort: SortScansNewest, Time: items[i].ScannedAt, ID: items[i].ID}
+	})
+	return items[:n], info, nil
 }
 
 // GetDashboardStats retrieves statistics for the dashboard
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"exportready-battery/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// PASSPORT SEARCH
+// ============================================================================
+// A query matches whole words through the search_vector columns (serial, custodian
+// email, batch name, customs fields, spec values) and substrings of identifiers
+// through trigram indexes, so "00042", "BE-7731" or "installer@" all find passports.
+
+const maxBatchFacets = 10
+
+// searchCondition appends the match condition for q. Returns the clause and the
+// positions of q and its LIKE pattern in args for use in the score expression.
+func searchCondition(q string, args []interface{}) (string, int, []interface{}) {
+	args = append(args, q, "%"+escapeLike(q)+"%")
+	qi, li := len(args)-1, len(args)
+	cond := fmt.Sprintf(` AND (p.search_vector @@ plainto_tsquery('simple', $%[1]d)
+	               OR b.search_vector @@ plainto_tsquery('simple', $%[1]d)
+	               OR p.serial_number ILIKE $%[2]d
+	               OR p.current_owner_email ILIKE $%[2]d
+	               OR b.batch_name ILIKE $%[2]d
+	               OR b.bill_of_entry_no ILIKE $%[2]d
+	               OR b.hsn_code ILIKE $%[2]d)`, qi, li)
+	return cond, qi, args
+}
+
+// searchScore ranks exact identifier matches first, then by trigram similarity and text rank
+func searchScore(qi int) string {
+	return fmt.Sprintf(`(CASE WHEN lower(p.serial_number) = lower($%[1]d)
+	                          OR lower(p.current_owner_email) = lower($%[1]d)
+	                          OR lower(b.bill_of_entry_no) = lower($%[1]d) THEN 1 ELSE 0 END
+	        + GREATEST(similarity(p.serial_number, $%[1]d),
+	                   similarity(COALESCE(p.current_owner_email, ''), $%[1]d),
+	                   similarity(COALESCE(b.bill_of_entry_no, ''), $%[1]d),
+	                   similarity(b.batch_name, $%[1]d))
+	        + ts_rank(p.search_vector || b.search_vector, plainto_tsquery('simple', $%[1]d)))`, qi)
+}
+
+// SearchPassports finds a tenant's passports matching q within the filter, best matches first.
+// Results page by offset only (relevance order has no stable keyset).
+func (r *Repository) SearchPassports(ctx context.Context, tenantID uuid.UUID, q string, filter PassportFilter, page PageRequest) ([]*models.PassportSearchResult, PageInfo, error) {
+	page.After = nil
+	page = page.normalize(20, 100)
+
+	where, args := filter.where(tenantID)
+	cond, qi, args := searchCondition(q, args)
+	where += cond
+
+	total := -1
+	if page.WithTotal {
+		countQuery := `SELECT COUNT(*) FROM public.passports p
+		               JOIN public.batches b ON p.batch_id = b.id
+		               WHERE ` + where
+		if err := r.db.Pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+			return nil, PageInfo{}, fmt.Errorf("failed to count search results: %w", err)
+		}
+	}
+
+	var limit string
+	limit, args = page.limitClause(args)
+
+	query := `SELECT p.uuid, p.serial_number, p.status, p.created_at, COALESCE(p.current_owner_email, ''),
+	                 b.id, b.batch_name, COALESCE(b.market_region::text, 'GLOBAL'), COALESCE(b.specs->>'chemistry', ''),
+	                 COALESCE(b.bill_of_entry_no, ''), COALESCE(b.hsn_code, ''),
+	                 jsonb_to_tsvector('simple', b.specs, '["string", "numeric"]') @@ plainto_tsquery('simple', $` + fmt.Sprint(qi) + `),
+	                 ` + searchScore(qi) + ` AS score
+	          FROM public.passports p
+	          JOIN public.batches b ON p.batch_id = b.id
+	          WHERE ` + where + `
+	          ORDER BY score DESC, p.serial_number, p.uuid` + limit
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to search passports: %w", err)
+	}
+	defer rows.Close()
+
+	terms := strings.Fields(strings.ToLower(q))
+	results := []*models.PassportSearchResult{}
+	for rows.Next() {
+		res := &models.PassportSearchResult{}
+		var marketRegion string
+		var specsMatch bool
+		if err := rows.Scan(
+			&res.UUID,
+			&res.SerialNumber,
+			&res.Status,
+			&res.CreatedAt,
+			&res.OwnerEmail,
+			&res.BatchID,
+			&res.BatchName,
+			&marketRegion,
+			&res.Chemistry,
+			&res.BillOfEntryNo,
+			&res.HSNCode,
+			&specsMatch,
+			&res.Score,
+		); err != nil {
+			return nil, PageInfo{}, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		res.MarketRegion = models.MarketRegion(marketRegion)
+		res.MatchedFields = matchedFields(res, terms, specsMatch)
+		results = append(results, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to search passports: %w", err)
+	}
+
+	n, info := pageInfo(page, len(results), total, nil)
+	return results[:n], info, nil
+}
+
+// matchedFields lists the fields containing any query term, so support staff see why a passport matched
+func matchedFields(res *models.PassportSearchResult, terms []string, specsMatch bool) []string {
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{models.SearchFieldSerialNumber, res.SerialNumber},
+		{models.SearchFieldOwnerEmail, res.OwnerEmail},
+		{models.SearchFieldBatchName, res.BatchName},
+		{models.SearchFieldBillOfEntry, res.BillOfEntryNo},
+		{models.SearchFieldHSNCode, res.HSNCode},
+	}
+
+	matched := []string{}
+	for _, field := range fields {
+		value := strings.ToLower(field.value)
+		for _, term := range terms {
+			if value != "" && strings.Contains(value, term) {
+				matched = append(matched, field.name)
+				break
+			}
+		}
+	}
+	if specsMatch {
+		matched = append(matched, models.SearchFieldSpecs)
+	}
+	return matched
+}
+
+// SearchPassportFacets counts every passport matching q within the filter by status,
+// market region, chemistry and batch (top batches only)
+func (r *Repository) SearchPassportFacets(ctx context.Context, tenantID uuid.UUID, q string, filter PassportFilter) (*models.SearchFacets, error) {
+	where, args := filter.where(tenantID)
+	cond, _, args := searchCondition(q, args)
+	where += cond
+
+	query := `SELECT CASE
+	                   WHEN GROUPING(status) = 0 THEN 'status'
+	                   WHEN GROUPING(market_region) = 0 THEN 'market_region'
+	                   WHEN GROUPING(chemistry) = 0 THEN 'chemistry'
+	                   ELSE 'batch'
+	                 END AS facet,
+	                 COALESCE(status, market_region, chemistry, batch_id::text, '') AS value,
+	                 COALESCE(batch_name, '') AS label,
+	                 COUNT(*) AS count
+	          FROM (
+	              SELECT p.status,
+	                     COALESCE(b.market_region::text, 'GLOBAL') AS market_region,
+	                     NULLIF(upper(b.specs->>'chemistry'), '') AS chemistry,
+	                     b.id AS batch_id,
+	                     b.batch_name
+	              FROM public.passports p
+	              JOIN public.batches b ON p.batch_id = b.id
+	              WHERE ` + where + `
+	          ) matches
+	          GROUP BY GROUPING SETS ((status), (market_region), (chemistry), (batch_id, batch_name))
+	          ORDER BY facet, count DESC, value`
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count search facets: %w", err)
+	}
+	defer rows.Close()
+
+	facets := &models.SearchFacets{
+		Status:       []models.FacetCount{},
+		MarketRegion: []models.FacetCount{},
+		Chemistry:    []models.FacetCount{},
+		Batch:        []models.FacetCount{},
+	}
+	for rows.Next() {
+		var facet string
+		var fc models.FacetCount
+		if err := rows.Scan(&facet, &fc.Value, &fc.Label, &fc.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan search facet: %w", err)
+		}
+		if fc.Value == "" {
+			continue // Batches without a chemistry in their specs
+		}
+		switch facet {
+		case "status":
+			facets.Status = append(facets.Status, models.FacetCount{Value: fc.Value, Count: fc.Count})
+		case "market_region":
+			facets.MarketRegion = append(facets.MarketRegion, models.FacetCount{Value: fc.Value, Count: fc.Count})
+		case "chemistry":
+			facets.Chemistry = append(facets.Chemistry, models.FacetCount{Value: fc.Value, Count: fc.Count})
+		case "batch":
+			if len(facets.Batch) < maxBatchFacets {
+				facets.Batch = append(facets.Batch, fc)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count search facets: %w", err)
+	}
+
+	return facets, nil
+}
@@ -0,0 +1,387 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"exportready-battery/internal/models"
+)
+
+// Shipment errors
+var (
+	ErrShipmentNoPassports          = errors.New("no shippable passports in the selected batches")
+	ErrShipmentPassportsUnavailable = errors.New("some passports cannot be shipped or are already in another draft shipment")
+	ErrShipmentValueBatch           = errors.New("unit values are given for batches that are not in the shipment")
+	ErrShipmentNotDraft             = errors.New("shipment is not a draft")
+)
+
+// CreateShipment stores a draft shipment and reserves its passports: every
+// shippable passport of batchIDs plus the passports in passportIDs, which must
+// all be shippable. Batches must be active batches of the tenant. It fills in
+// s.ID, CreatedAt and PassportCount.
+func (r *Repository) CreateShipment(ctx context.Context, s *models.Shipment, batchIDs, passportIDs []uuid.UUID) error {
+	valuesJSON, err := json.Marshal(s.UnitValues)
+	if err != nil {
+		return fmt.Errorf("failed to marshal unit values: %w", err)
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin shipment: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO shipments
+			(tenant_id, reference, status, destination_country, consignee_name, consignee_address,
+			 invoice_number, invoice_date, incoterm, currency, unit_values, carrier,
+			 port_of_loading, port_of_discharge, packing, note, created_by)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, '')::date, NULLIF($9, ''),
+		        $10, $11::jsonb, NULLIF($12, ''), NULLIF($13, ''), NULLIF($14, ''), $15, NULLIF($16, ''), $17)
+		RETURNING id, created_at`,
+		s.TenantID, s.Reference, models.ShipmentStatusDraft, s.DestinationCountry, s.ConsigneeName, s.ConsigneeAddress,
+		s.InvoiceNumber, s.InvoiceDate, s.Incoterm, s.Currency, string(valuesJSON), s.Carrier,
+		s.PortOfLoading, s.PortOfDischarge, s.Packing, s.Note, s.CreatedBy,
+	).Scan(&s.ID, &s.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create shipment: %w", err)
+	}
+	s.Status = models.ShipmentStatusDraft
+
+	// Lock the passports so a concurrent transition cannot make them unshippable
+	items, err := tx.Exec(ctx, `
+		INSERT INTO shipment_items (shipment_id, passport_id, batch_id)
+		SELECT $1, p.uuid, p.batch_id
+		FROM public.passports p
+		JOIN public.batches b ON b.id = p.batch_id
+		WHERE b.tenant_id = $2 AND b.deleted_at IS NULL AND b.status = $3
+		  AND (b.id = ANY($4) OR p.uuid = ANY($5))
+		  AND p.status = ANY($6)
+		  AND NOT EXISTS (SELECT 1 FROM shipment_items i WHERE i.passport_id = p.uuid AND i.open)
+		FOR UPDATE OF p`,
+		s.ID, s.TenantID, models.BatchStatusActive, batchIDs, passportIDs, models.ShippableStatuses())
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrShipmentPassportsUnavailable
+		}
+		return fmt.Errorf("failed to reserve shipment passports: %w", err)
+	}
+	if items.RowsAffected() == 0 {
+		return ErrShipmentNoPassports
+	}
+	s.PassportCount = int(items.RowsAffected())
+
+	if len(passportIDs) > 0 {
+		var reserved int
+		err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM shipment_items WHERE shipment_id = $1 AND passport_id = ANY($2)`,
+			s.ID, passportIDs).Scan(&reserved)
+		if err != nil {
+			return fmt.Errorf("failed to check shipment passports: %w", err)
+		}
+		if reserved != len(passportIDs) {
+			return ErrShipmentPassportsUnavailable
+		}
+	}
+
+	if len(s.UnitValues) > 0 {
+		valued := make([]uuid.UUID, 0, len(s.UnitValues))
+		for id := range s.UnitValues {
+			valued = append(valued, id)
+		}
+		var found int
+		err = tx.QueryRow(ctx, `SELECT COUNT(DISTINCT batch_id) FROM shipment_items WHERE shipment_id = $1 AND batch_id = ANY($2)`,
+			s.ID, valued).Scan(&found)
+		if err != nil {
+			return fmt.Errorf("failed to check shipment unit values: %w", err)
+		}
+		if found != len(valued) {
+			return ErrShipmentValueBatch
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit shipment: %w", err)
+	}
+	return nil
+}
+
+const shipmentColumns = `
+	s.id, s.tenant_id, s.reference, s.status, s.destination_country, s.consignee_name,
+	COALESCE(s.consignee_address, ''), COALESCE(s.invoice_number, ''),
+	COALESCE(to_char(s.invoice_date, 'YYYY-MM-DD'), ''), COALESCE(s.incoterm, ''),
+	s.currency, s.unit_values, COALESCE(s.carrier, ''),
+	COALESCE(s.port_of_loading, ''), COALESCE(s.port_of_discharge, ''), s.packing, COALESCE(s.note, ''),
+	(SELECT COUNT(*) FROM shipment_items i WHERE i.shipment_id = s.id),
+	s.created_by, s.created_at, COALESCE(s.shipped_by, ''), s.shipped_at, s.cancelled_at`
+
+func scanShipment(row pgx.Row) (*models.Shipment, error) {
+	s := &models.Shipment{}
+	var valuesJSON []byte
+	err := row.Scan(
+		&s.ID, &s.TenantID, &s.Reference, &s.Status, &s.DestinationCountry, &s.ConsigneeName,
+		&s.ConsigneeAddress, &s.InvoiceNumber, &s.InvoiceDate, &s.Incoterm,
+		&s.Currency, &valuesJSON, &s.Carrier,
+		&s.PortOfLoading, &s.PortOfDischarge, &s.Packing, &s.Note,
+		&s.PassportCount,
+		&s.CreatedBy, &s.CreatedAt, &s.ShippedBy, &s.ShippedAt, &s.CancelledAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(valuesJSON, &s.UnitValues); err != nil {
+		return nil, fmt.Errorf("failed to parse shipment unit values: %w", err)
+	}
+	return s, nil
+}
+
+// GetShipment retrieves a tenant's shipment; nil if it does not exist
+func (r *Repository) GetShipment(ctx context.Context, tenantID, id uuid.UUID) (*models.Shipment, error) {
+	query := `SELECT ` + shipmentColumns + ` FROM shipments s WHERE s.id = $1 AND s.tenant_id = $2`
+
+	s, err := scanShipment(r.db.Pool.QueryRow(ctx, query, id, tenantID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shipment: %w", err)
+	}
+	return s, nil
+}
+
+// ListShipments retrieves a tenant's shipments, newest first. status filters when set.
+func (r *Repository) ListShipments(ctx context.Context, tenantID uuid.UUID, status string, page PageRequest) ([]*models.Shipment, PageInfo, error) {
+	page = page.normalize(20, 100)
+
+	where := "s.tenant_id = $1"
+	args := []interface{}{tenantID}
+	if status != "" {
+		args = append(args, status)
+		where += fmt.Sprintf(" AND s.status = $%d", len(args))
+	}
+
+	var after, limit string
+	after, args = page.keyset("s.created_at", "s.id", true, args)
+	limit, args = page.limitClause(args)
+
+	query := `SELECT ` + shipmentColumns + `
+		FROM shipments s
+		WHERE ` + where + after + `
+		ORDER BY s.created_at DESC, s.id DESC` + limit
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to list shipments: %w", err)
+	}
+	defer rows.Close()
+
+	var shipments []*models.Shipment
+	for rows.Next() {
+		s, err := scanShipment(rows)
+		if err != nil {
+			return nil, PageInfo{}, fmt.Errorf("failed to scan shipment: %w", err)
+		}
+		shipments = append(shipments, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to list shipments: %w", err)
+	}
+
+	n, info := pageInfo(page, len(shipments), -1, func(i int) Cursor {
+		return Cursor{Sort: SortShipmentsNewest, Time: shipments[i].CreatedAt, ID: shipments[i].ID}
+	})
+	return shipments[:n], info, nil
+}
+
+// CountShipmentItemsByBatch returns how many of a shipment's passports come from each batch
+func (r *Repository) CountShipmentItemsByBatch(ctx context.Context, shipmentID uuid.UUID) (map[uuid.UUID]int, error) {
+	rows, err := r.db.Pool.Query(ctx,
+		`SELECT batch_id, COUNT(*) FROM shipment_items WHERE shipment_id = $1 GROUP BY batch_id`, shipmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count shipment passports: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var batchID uuid.UUID
+		var count int
+		if err := rows.Scan(&batchID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan shipment count: %w", err)
+		}
+		counts[batchID] = count
+	}
+	return counts, rows.Err()
+}
+
+// ListShipmentItems retrieves every passport of a shipment by serial number
+func (r *Repository) ListShipmentItems(ctx context.Context, shipmentID uuid.UUID) ([]*models.ShipmentItem, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT p.uuid, p.serial_number, i.batch_id, p.status
+		FROM shipment_items i
+		JOIN public.passports p ON p.uuid = i.passport_id
+		WHERE i.shipment_id = $1
+		ORDER BY p.serial_number, p.uuid`, shipmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shipment passports: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*models.ShipmentItem
+	for rows.Next() {
+		item := &models.ShipmentItem{}
+		if err := rows.Scan(&item.PassportID, &item.SerialNumber, &item.BatchID, &item.Status); err != nil {
+			return nil, fmt.Errorf("failed to scan shipment passport: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// lockDraftShipment locks a tenant's shipment for a status change. Returns
+// false if it does not exist and ErrShipmentNotDraft if it is not a draft.
+func lockDraftShipment(ctx context.Context, tx pgx.Tx, tenantID, id uuid.UUID) (bool, error) {
+	var status string
+	err := tx.QueryRow(ctx, `SELECT status FROM shipments WHERE id = $1 AND tenant_id = $2 FOR UPDATE`,
+		id, tenantID).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock shipment: %w", err)
+	}
+	if status != models.ShipmentStatusDraft {
+		return true, ErrShipmentNotDraft
+	}
+	return true, nil
+}
+
+// ShipShipment moves every passport of a draft shipment to SHIPPED with a
+// passport event and marks the shipment shipped, all in one transaction. The
+// first shipment date of a passport is kept. Returns false if the shipment does
+// not exist, and ErrShipmentPassportsUnavailable if a passport has left a
+// shippable status since the draft was created.
+func (r *Repository) ShipShipment(ctx context.Context, tenantID, id uuid.UUID, actor string) (bool, int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to begin shipping: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	found, err := lockDraftShipment(ctx, tx, tenantID, id)
+	if !found || err != nil {
+		return found, 0, err
+	}
+
+	var reference, destination string
+	err = tx.QueryRow(ctx, `SELECT reference, destination_country FROM shipments WHERE id = $1`, id).
+		Scan(&reference, &destination)
+	if err != nil {
+		return true, 0, fmt.Errorf("failed to get shipment: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT p.status
+		FROM public.passports p
+		JOIN shipment_items i ON i.passport_id = p.uuid
+		WHERE i.shipment_id = $1
+		FOR UPDATE OF p`, id)
+	if err != nil {
+		return true, 0, fmt.Errorf("failed to lock shipment passports: %w", err)
+	}
+	unshippable := 0
+	for rows.Next() {
+		var status string
+		if err := rows.Scan(&status); err != nil {
+			rows.Close()
+			return true, 0, fmt.Errorf("failed to scan passport status: %w", err)
+		}
+		if !models.IsShippable(status) {
+			unshippable++
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return true, 0, fmt.Errorf("failed to lock shipment passports: %w", err)
+	}
+	if unshippable > 0 {
+		return true, 0, fmt.Errorf("%w: %d passports changed status since the shipment was drafted", ErrShipmentPassportsUnavailable, unshippable)
+	}
+
+	// The CTEs share one snapshot, so prev still sees the status before the update
+	shipped, err := tx.Exec(ctx, `
+		WITH prev AS (
+			SELECT p.uuid, p.status
+			FROM public.passports p
+			JOIN shipment_items i ON i.passport_id = p.uuid
+			WHERE i.shipment_id = $1
+		), moved AS (
+			UPDATE public.passports p
+			SET status = $2, shipped_at = COALESCE(p.shipped_at, NOW())
+			FROM prev
+			WHERE p.uuid = prev.uuid
+			RETURNING p.uuid, prev.status AS previous_status
+		)
+		INSERT INTO public.passport_events (id, passport_id, event_type, actor, metadata, created_at)
+		SELECT gen_random_uuid(), m.uuid, $3, $4,
+		       jsonb_build_object(
+		           'previous_status', m.previous_status, 'new_status', $2::text,
+		           'shipment_id', $1::uuid, 'shipment_reference', $5::text, 'destination_country', $6::text
+		       ),
+		       NOW()
+		FROM moved m`,
+		id, models.PassportStatusShipped, models.PassportEventShipped, actor, reference, destination)
+	if err != nil {
+		return true, 0, fmt.Errorf("failed to ship passports: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE shipment_items SET open = FALSE WHERE shipment_id = $1`, id); err != nil {
+		return true, 0, fmt.Errorf("failed to release shipment passports: %w", err)
+	}
+	_, err = tx.Exec(ctx, `UPDATE shipments SET status = $2, shipped_by = $3, shipped_at = NOW() WHERE id = $1`,
+		id, models.ShipmentStatusShipped, actor)
+	if err != nil {
+		return true, 0, fmt.Errorf("failed to mark shipment shipped: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return true, 0, fmt.Errorf("failed to commit shipping: %w", err)
+	}
+	return true, int(shipped.RowsAffected()), nil
+}
+
+// CancelShipment cancels a draft shipment and releases its passports for other
+// shipments. Returns false if the shipment does not exist and
+// ErrShipmentNotDraft if it is not a draft.
+func (r *Repository) CancelShipment(ctx context.Context, tenantID, id uuid.UUID) (bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin cancellation: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	found, err := lockDraftShipment(ctx, tx, tenantID, id)
+	if !found || err != nil {
+		return found, err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE shipment_items SET open = FALSE WHERE shipment_id = $1`, id); err != nil {
+		return true, fmt.Errorf("failed to release shipment passports: %w", err)
+	}
+	_, err = tx.Exec(ctx, `UPDATE shipments SET status = $2, cancelled_at = NOW() WHERE id = $1`,
+		id, models.ShipmentStatusCancelled)
+	if err != nil {
+		return true, fmt.Errorf("failed to cancel shipment: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return true, fmt.Errorf("failed to commit cancellation: %w", err)
+	}
+	return true, nil
+}
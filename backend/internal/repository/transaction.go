@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -27,22 +28,40 @@ func (r *Repository) CreateTransaction(ctx context.Context, tx *models.Transacti
 	return nil
 }
 
-// ListTransactions returns all transactions for a tenant
-func (r *Repository) ListTransactions(ctx context.Context, tenantID uuid.UUID, limit int) ([]*models.Transaction, error) {
-	if limit <= 0 {
-		limit = 50
+// TransactionFilter narrows the quota ledger. Empty fields match everything.
+type TransactionFilter struct {
+	CreatedAfter  *time.Time // created_at >= CreatedAfter
+	CreatedBefore *time.Time // created_at < CreatedBefore
+}
+
+// ListTransactions returns a tenant's quota ledger, newest first
+func (r *Repository) ListTransactions(ctx context.Context, tenantID uuid.UUID, filter TransactionFilter, page PageRequest) ([]*models.Transaction, PageInfo, error) {
+	page = page.normalize(50, 200)
+
+	where := "tenant_id = $1"
+	args := []interface{}{tenantID}
+	if filter.CreatedAfter != nil {
+		args = append(args, *filter.CreatedAfter)
+		where += fmt.Sprintf(" AND created_at >= $%d", len(args))
 	}
+	if filter.CreatedBefore != nil {
+		args = append(args, *filter.CreatedBefore)
+		where += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+
+	var after, limit string
+	after, args = page.keyset("created_at", "id", true, args)
+	limit, args = page.limitClause(args)
 
 	query := `
 		SELECT id, tenant_id, description, quota_change, batch_id, created_at
 		FROM public.transactions
-		WHERE tenant_id = $1
-		ORDER BY created_at DESC
-		LIMIT $2`
+		WHERE ` + where + after + `
+		ORDER BY created_at DESC, id DESC` + limit
 
-	rows, err := r.db.Pool.Query(ctx, query, tenantID, limit)
+	rows, err := r.db.Pool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list transactions: %w", err)
+		return nil, PageInfo{}, fmt.Errorf("failed to list transactions: %w", err)
 	}
 	defer rows.Close()
 
@@ -58,10 +77,16 @@ func (r *Repository) ListTransactions(ctx context.Context, tenantID uuid.UUID, l
 			&tx.CreatedAt,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+			return nil, PageInfo{}, fmt.Errorf("failed to scan transaction: %w", err)
 		}
 		transactions = append(transactions, tx)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to list transactions: %w", err)
+	}
 
-	return transactions, nil
+	n, info := pageInfo(page, len(transactions), -1, func(i int) Cursor {
+		return Cursor{Sort: SortTransactionsNewest, Time: transactions[i].CreatedAt, ID: transactions[i].ID}
+	})
+	return transactions[:n], info, nil
 }
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"exportready-battery/internal/models"
+)
+
+// ErrOpenClaimExists means the passport already has a submitted or approved claim
+var ErrOpenClaimExists = errors.New("passport already has an open warranty claim")
+
+// CreateWarrantyClaim stores a new claim and fills in its ID and timestamps
+func (r *Repository) CreateWarrantyClaim(ctx context.Context, claim *models.WarrantyClaim) error {
+	photosJSON, err := json.Marshal(claim.Photos)
+	if err != nil {
+		return fmt.Errorf("failed to marshal claim photos: %w", err)
+	}
+
+	query := `
+		INSERT INTO warranty_claims
+			(tenant_id, passport_id, status, fault_code, description, state_of_health, photos,
+			 previous_status, eligible, eligibility_reason, warranty_expires_at, submitted_by, submitted_role)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7::jsonb, $8, $9, NULLIF($10, ''), $11, $12, $13)
+		RETURNING id, created_at, updated_at`
+
+	err = r.db.Pool.QueryRow(ctx, query,
+		claim.TenantID, claim.PassportID, claim.Status, claim.FaultCode, claim.Description,
+		claim.StateOfHealth, string(photosJSON), claim.PreviousStatus, claim.Eligible,
+		claim.EligibilityReason, claim.WarrantyExpiresAt, claim.SubmittedBy, claim.SubmittedRole,
+	).Scan(&claim.ID, &claim.CreatedAt, &claim.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrOpenClaimExists
+		}
+		return fmt.Errorf("failed to create warranty claim: %w", err)
+	}
+	return nil
+}
+
+// SetWarrantyClaimPhotos replaces the stored photo file names of a claim
+func (r *Repository) SetWarrantyClaimPhotos(ctx context.Context, id uuid.UUID, photos []string) error {
+	photosJSON, err := json.Marshal(photos)
+	if err != nil {
+		return fmt.Errorf("failed to marshal claim photos: %w", err)
+	}
+
+	query := `UPDATE warranty_claims SET photos = $2::jsonb, updated_at = NOW() WHERE id = $1`
+	if _, err := r.db.Pool.Exec(ctx, query, id, string(photosJSON)); err != nil {
+		return fmt.Errorf("failed to update claim photos: %w", err)
+	}
+	return nil
+}
+
+// DeleteWarrantyClaim removes a claim whose submission could not be completed
+func (r *Repository) DeleteWarrantyClaim(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.db.Pool.Exec(ctx, `DELETE FROM warranty_claims WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete warranty claim: %w", err)
+	}
+	return nil
+}
+
+const warrantyClaimColumns = `
+	c.id, c.tenant_id, c.passport_id, p.serial_number, c.status, COALESCE(c.rma_number, ''),
+	c.fault_code, COALESCE(c.description, ''), c.state_of_health, c.photos, c.previous_status,
+	c.eligible, COALESCE(c.eligibility_reason, ''), c.warranty_expires_at,
+	c.submitted_by, c.submitted_role, COALESCE(c.decided_by, ''), c.decided_at,
+	COALESCE(c.decision_note, ''), c.received_at, c.created_at, c.updated_at`
+
+func scanWarrantyClaim(row pgx.Row) (*models.WarrantyClaim, error) {
+	c := &models.WarrantyClaim{}
+	var photosJSON []byte
+	err := row.Scan(
+		&c.ID, &c.TenantID, &c.PassportID, &c.SerialNumber, &c.Status, &c.RMANumber,
+		&c.FaultCode, &c.Description, &c.StateOfHealth, &photosJSON, &c.PreviousStatus,
+		&c.Eligible, &c.EligibilityReason, &c.WarrantyExpiresAt,
+		&c.SubmittedBy, &c.SubmittedRole, &c.DecidedBy, &c.DecidedAt,
+		&c.DecisionNote, &c.ReceivedAt, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(photosJSON, &c.Photos); err != nil {
+		return nil, fmt.Errorf("failed to parse claim photos: %w", err)
+	}
+	if c.Photos == nil {
+		c.Photos = []string{}
+	}
+	return c, nil
+}
+
+// GetWarrantyClaim retrieves a tenant's claim; nil if it does not exist
+func (r *Repository) GetWarrantyClaim(ctx context.Context, tenantID, id uuid.UUID) (*models.WarrantyClaim, error) {
+	query := `SELECT ` + warrantyClaimColumns + `
+		FROM warranty_claims c
+		JOIN public.passports p ON p.uuid = c.passport_id
+		WHERE c.id = $1 AND c.tenant_id = $2`
+
+	c, err := scanWarrantyClaim(r.db.Pool.QueryRow(ctx, query, id, tenantID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get warranty claim: %w", err)
+	}
+	return c, nil
+}
+
+// GetOpenWarrantyClaim retrieves the passport's submitted or approved claim, or nil
+func (r *Repository) GetOpenWarrantyClaim(ctx context.Context, passportID uuid.UUID) (*models.WarrantyClaim, error) {
+	query := `SELECT ` + warrantyClaimColumns + `
+		FROM warranty_claims c
+		JOIN public.passports p ON p.uuid = c.passport_id
+		WHERE c.passport_id = $1 AND c.status IN ($2, $3)`
+
+	c, err := scanWarrantyClaim(r.db.Pool.QueryRow(ctx, query, passportID, models.ClaimStatusSubmitted, models.ClaimStatusApproved))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open warranty claim: %w", err)
+	}
+	return c, nil
+}
+
+// ListWarrantyClaims retrieves a tenant's claims, newest first. status filters when set.
+func (r *Repository) ListWarrantyClaims(ctx context.Context, tenantID uuid.UUID, status string, page PageRequest) ([]*models.WarrantyClaim, PageInfo, error) {
+	page = page.normalize(20, 100)
+
+	where := "c.tenant_id = $1"
+	args := []interface{}{tenantID}
+	if status != "" {
+		args = append(args, status)
+		where += fmt.Sprintf(" AND c.status = $%d", len(args))
+	}
+
+	var after, limit string
+	after, args = page.keyset("c.created_at", "c.id", true, args)
+	limit, args = page.limitClause(args)
+
+	query := `SELECT ` + warrantyClaimColumns + `
+		FROM warranty_claims c
+		JOIN public.passports p ON p.uuid = c.passport_id
+		WHERE ` + where + after + `
+		ORDER BY c.created_at DESC, c.id DESC` + limit
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to list warranty claims: %w", err)
+	}
+	defer rows.Close()
+
+	var claims []*models.WarrantyClaim
+	for rows.Next() {
+		c, err := scanWarrantyClaim(rows)
+		if err != nil {
+			return nil, PageInfo{}, fmt.Errorf("failed to scan warranty claim: %w", err)
+		}
+		claims = append(claims, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to list warranty claims: %w", err)
+	}
+
+	n, info := pageInfo(page, len(claims), -1, func(i int) Cursor {
+		return Cursor{Sort: SortClaimsNewest, Time: claims[i].CreatedAt, ID: claims[i].ID}
+	})
+	return claims[:n], info, nil
+}
+
+// DecideWarrantyClaim moves a SUBMITTED claim to APPROVED or REJECTED. rmaNumber
+// is stored on approval. Returns false if the claim was no longer SUBMITTED.
+func (r *Repository) DecideWarrantyClaim(ctx context.Context, id uuid.UUID, status, rmaNumber, decidedBy, note string) (bool, error) {
+	query := `
+		UPDATE warranty_claims
+		SET status = $2, rma_number = NULLIF($3, ''), decided_by = $4, decision_note = NULLIF($5, ''),
+		    decided_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = $6`
+
+	result, err := r.db.Pool.Exec(ctx, query, id, status, rmaNumber, decidedBy, note, models.ClaimStatusSubmitted)
+	if err != nil {
+		return false, fmt.Errorf("failed to decide warranty claim: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// MarkWarrantyClaimReceived moves an APPROVED claim to RECEIVED. Returns false
+// if the claim was no longer APPROVED.
+func (r *Repository) MarkWarrantyClaimReceived(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE warranty_claims
+		SET status = $2, received_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = $3`
+
+	result, err := r.db.Pool.Exec(ctx, query, id, models.ClaimStatusReceived, models.ClaimStatusApproved)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark warranty claim received: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+)
+
+// ============================================================================
+// API USAGE RECORDER (Async request logging for API keys)
+// ============================================================================
+
+const (
+	usageBufferSize      = 2048            // Entries queued before new ones are dropped
+	usageFlushSize       = 200             // Flush once this many entries are queued
+	usageFlushInterval   = 2 * time.Second // ...or at least this often
+	usageRetentionDays   = 90
+	usageCleanupInterval = 6 * time.Hour
+)
+
+// APIUsageRecorder persists API key request logs off the request path.
+// Entries are buffered and written in batches; known IPs are tracked so a key
+// used from a new address raises an alert.
+type APIUsageRecorder struct {
+	repo    *repository.Repository
+	entries chan *models.APIRequestLog
+	done    chan struct{}
+}
+
+// NewAPIUsageRecorder creates a recorder and starts its background writer
+func NewAPIUsageRecorder(repo *repository.Repository) *APIUsageRecorder {
+	rec := &APIUsageRecorder{
+		repo:    repo,
+		entries: make(chan *models.APIRequestLog, usageBufferSize),
+		done:    make(chan struct{}),
+	}
+	go rec.run()
+	return rec
+}
+
+// Record queues a request log entry. Never blocks; drops the entry if the buffer is full.
+func (s *APIUsageRecorder) Record(entry *models.APIRequestLog) {
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	select {
+	case s.entries <- entry:
+	default:
+		log.Printf("⚠️ API usage buffer full, dropping log for key %s", entry.APIKeyID)
+	}
+}
+
+// Close flushes queued entries and stops the background writer
+func (s *APIUsageRecorder) Close() {
+	close(s.entries)
+	<-s.done
+}
+
+func (s *APIUsageRecorder) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(usageFlushInterval)
+	defer ticker.Stop()
+
+	lastCleanup := time.Now()
+	batch := make([]*models.APIRequestLog, 0, usageFlushSize)
+
+	for {
+		select {
+		case entry, ok := <-s.entries:
+			if !ok {
+				s.flush(batch)
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= usageFlushSize {
+				s.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				s.flush(batch)
+				batch = batch[:0]
+			}
+			if time.Since(lastCleanup) > usageCleanupInterval {
+				lastCleanup = time.Now()
+				if err := s.repo.CleanupOldAPIRequestLogs(context.Background(), usageRetentionDays); err != nil {
+					log.Printf("Failed to clean up API request logs: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// flush writes a batch of logs and updates known IPs
+func (s *APIUsageRecorder) flush(batch []*models.APIRequestLog) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := s.repo.InsertAPIRequestLogs(ctx, batch); err != nil {
+		log.Printf("Failed to write API request logs: %v", err)
+		return
+	}
+
+	// Aggregate per key+IP so each pair costs one upsert per flush
+	type keyIP struct {
+		keyID uuid.UUID
+		ip    string
+	}
+	type ipSeen struct {
+		tenantID uuid.UUID
+		count    int
+		lastSeen time.Time
+	}
+	seen := make(map[keyIP]*ipSeen)
+	for _, entry := range batch {
+		if entry.IPAddress == "" {
+			continue
+		}
+		k := keyIP{entry.APIKeyID, entry.IPAddress}
+		if seen[k] == nil {
+			seen[k] = &ipSeen{tenantID: entry.TenantID}
+		}
+		seen[k].count++
+		if entry.CreatedAt.After(seen[k].lastSeen) {
+			seen[k].lastSeen = entry.CreatedAt
+		}
+	}
+
+	for k, v := range seen {
+		isNew, err := s.repo.TouchAPIKeyIP(ctx, k.keyID, k.ip, v.count, v.lastSeen)
+		if err != nil {
+			log.Printf("Failed to record API key IP: %v", err)
+			continue
+		}
+		if isNew {
+			s.alertNewIP(ctx, k.keyID, v.tenantID, k.ip)
+		}
+	}
+}
+
+// alertNewIP raises an alert when a key that was already in use shows up from a new IP
+func (s *APIUsageRecorder) alertNewIP(ctx context.Context, keyID, tenantID uuid.UUID, ip string) {
+	count, err := s.repo.CountAPIKeyIPs(ctx, keyID)
+	if err != nil {
+		log.Printf("Failed to count API key IPs: %v", err)
+		return
+	}
+	if count <= 1 {
+		return // First IP ever for this key - nothing to compare against
+	}
+
+	alert := &models.APIKeyAlert{
+		ID:        uuid.New(),
+		APIKeyID:  keyID,
+		TenantID:  tenantID,
+		AlertType: models.APIKeyAlertNewIP,
+		IPAddress: ip,
+		Message:   fmt.Sprintf("API key used from new IP address %s", ip),
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.CreateAPIKeyAlert(ctx, alert); err != nil {
+		log.Printf("Failed to create API key alert: %v", err)
+		return
+	}
+
+	log.Printf("🚨 API key %s used from new IP %s (tenant: %s)", keyID, ip, tenantID.String()[:8])
+}
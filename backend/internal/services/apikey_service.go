@@ -20,6 +20,14 @@ func NewAPIKeyService() *APIKeyService {
 // KeyPrefix is the prefix for all API keys
 const KeyPrefix = "er_sk_live_"
 
+const (
+	// lookupChars is how many chars of the random part are stored in key_prefix.
+	// 12 hex chars (48 bits) make the stored prefix unique so validation fetches one row.
+	lookupChars = 12
+	// legacyLookupChars is the prefix length used by keys issued before lookupChars
+	legacyLookupChars = 4
+)
+
 // GenerateKey generates a new API key with the format: er_sk_live_{32 random chars}
 func (s *APIKeyService) GenerateKey() (fullKey string, prefix string, err error) {
 	// Generate 32 random bytes (will become 64 hex chars, we'll use 32)
@@ -31,8 +39,8 @@ func (s *APIKeyService) GenerateKey() (fullKey string, prefix string, err error)
 	randomPart := hex.EncodeToString(randomBytes)
 	fullKey = KeyPrefix + randomPart
 
-	// Prefix for display: er_sk_live_ + first 4 chars + ****
-	prefix = KeyPrefix + randomPart[:4] + "****"
+	// Prefix for display and lookup: er_sk_live_ + first 12 chars + ****
+	prefix = KeyPrefix + randomPart[:lookupChars] + "****"
 
 	return fullKey, prefix, nil
 }
@@ -52,18 +60,28 @@ func (s *APIKeyService) ValidateKey(key, hash string) bool {
 	return err == nil
 }
 
-// ExtractPrefix extracts the display prefix from a full key
+// ExtractPrefix extracts the lookup prefix from a full key
 func (s *APIKeyService) ExtractPrefix(fullKey string) string {
+	return extractPrefix(fullKey, lookupChars)
+}
+
+// ExtractLegacyPrefix extracts the 4-char prefix used by keys created
+// before prefixes were made unique
+func (s *APIKeyService) ExtractLegacyPrefix(fullKey string) string {
+	return extractPrefix(fullKey, legacyLookupChars)
+}
+
+func extractPrefix(fullKey string, chars int) string {
 	if !strings.HasPrefix(fullKey, KeyPrefix) {
 		return ""
 	}
 
 	randomPart := strings.TrimPrefix(fullKey, KeyPrefix)
-	if len(randomPart) < 4 {
+	if len(randomPart) < chars {
 		return ""
 	}
 
-	return KeyPrefix + randomPart[:4] + "****"
+	return KeyPrefix + randomPart[:chars] + "****"
 }
 
 // IsValidKeyFormat checks if a key has the correct format
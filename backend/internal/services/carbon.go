@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// CARBON FOOTPRINT SERVICE
+// ============================================================================
+
+// Carbon footprint errors, shown to the caller as-is
+var (
+	ErrCarbonInvalid  = errors.New("invalid carbon footprint input")
+	ErrCarbonNotFound = errors.New("not found")
+	ErrCarbonConflict = errors.New("carbon footprint conflicts with the batch's status")
+)
+
+const (
+	MaxEmissionFactors = 5000 // Per import
+	maxCarbonLines     = 500  // Per calculation, both lists together
+)
+
+// CarbonService manages the tenant emission factor library and calculates
+// batch carbon footprints from it
+type CarbonService struct {
+	repo *repository.Repository
+}
+
+// NewCarbonService creates a new carbon footprint service
+func NewCarbonService(repo *repository.Repository) *CarbonService {
+	return &CarbonService{repo: repo}
+}
+
+// FactorImportError is returned when any factor of an import is invalid; nothing
+// is imported in that case
+type FactorImportError struct {
+	Errors []CSVRowError
+}
+
+func (e *FactorImportError) Error() string {
+	return fmt.Sprintf("%d invalid emission factors", len(e.Errors))
+}
+
+func (e *FactorImportError) Unwrap() error { return ErrCarbonInvalid }
+
+// ParseEmissionFactorsCSV reads a factor library export.
+// Expected columns: key, name, unit, kg_co2e_per_unit
+// Optional columns: stage, source, region
+func ParseEmissionFactorsCSV(reader io.Reader) ([]*models.EmissionFactor, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read CSV header: %v", ErrCarbonInvalid, err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	headerMap := make(map[string]int)
+	for i, h := range header {
+		headerMap[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, required := range []string{"key", "name", "unit", "kg_co2e_per_unit"} {
+		if _, ok := headerMap[required]; !ok {
+			return nil, fmt.Errorf("%w: CSV must have key, name, unit and kg_co2e_per_unit columns", ErrCarbonInvalid)
+		}
+	}
+
+	column := func(record []string, name string) string {
+		if i, ok := headerMap[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var factors []*models.EmissionFactor
+	var rowErrors []CSVRowError
+	for row := 2; ; row++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, CSVRowError{Row: row, Message: err.Error()})
+			continue
+		}
+
+		value, err := strconv.ParseFloat(column(record, "kg_co2e_per_unit"), 64)
+		if err != nil {
+			rowErrors = append(rowErrors, CSVRowError{Row: row, Message: "kg_co2e_per_unit must be a number"})
+			continue
+		}
+		factors = append(factors, &models.EmissionFactor{
+			Key:           column(record, "key"),
+			Name:          column(record, "name"),
+			Unit:          column(record, "unit"),
+			KgCO2ePerUnit: value,
+			Stage:         column(record, "stage"),
+			Source:        column(record, "source"),
+			Region:        column(record, "region"),
+		})
+	}
+	if len(rowErrors) > 0 {
+		return nil, &FactorImportError{Errors: rowErrors}
+	}
+	return factors, nil
+}
+
+// ImportFactors validates and upserts factors into the tenant's library. rowOffset
+// is added to the index in error rows (2 for CSV data rows, 1 for JSON arrays).
+func (s *CarbonService) ImportFactors(ctx context.Context, tenantID uuid.UUID, factors []*models.EmissionFactor, rowOffset int) (int, error) {
+	if len(factors) == 0 {
+		return 0, fmt.Errorf("%w: no emission factors to import", ErrCarbonInvalid)
+	}
+	if len(factors) > MaxEmissionFactors {
+		return 0, fmt.Errorf("%w: at most %d emission factors per import", ErrCarbonInvalid, MaxEmissionFactors)
+	}
+
+	var rowErrors []CSVRowError
+	seen := make(map[string]bool, len(factors))
+	for i, f := range factors {
+		f.Key = strings.ToLower(strings.TrimSpace(f.Key))
+		f.Name = strings.TrimSpace(f.Name)
+		f.Unit = strings.TrimSpace(f.Unit)
+		f.Stage = strings.ToUpper(strings.TrimSpace(f.Stage))
+
+		var msg string
+		switch {
+		case f.Key == "" || len(f.Key) > 100:
+			msg = "key is required (at most 100 characters)"
+		case seen[f.Key]:
+			msg = fmt.Sprintf("duplicate key %q", f.Key)
+		case f.Name == "":
+			msg = "name is required"
+		case f.Unit == "" || len(f.Unit) > 20:
+			msg = "unit is required (at most 20 characters)"
+		case math.IsNaN(f.KgCO2ePerUnit) || math.IsInf(f.KgCO2ePerUnit, 0):
+			msg = "kg_co2e_per_unit must be a number"
+		case f.Stage != "" && !models.IsValidCarbonStage(f.Stage):
+			msg = "stage must be one of " + strings.Join(models.CarbonStages, ", ")
+		}
+		if msg != "" {
+			rowErrors = append(rowErrors, CSVRowError{Row: i + rowOffset, Message: msg})
+		}
+		seen[f.Key] = true
+	}
+	if len(rowErrors) > 0 {
+		return 0, &FactorImportError{Errors: rowErrors}
+	}
+
+	if err := s.repo.UpsertEmissionFactors(ctx, tenantID, factors); err != nil {
+		return 0, err
+	}
+	return len(factors), nil
+}
+
+// ListFactors returns the tenant's factor library
+func (s *CarbonService) ListFactors(ctx context.Context, tenantID uuid.UUID) ([]*models.EmissionFactor, error) {
+	return s.repo.ListEmissionFactors(ctx, tenantID)
+}
+
+// DeleteFactor removes a factor from the tenant's library
+func (s *CarbonService) DeleteFactor(ctx context.Context, tenantID, id uuid.UUID) error {
+	deleted, err := s.repo.DeleteEmissionFactor(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrCarbonNotFound
+	}
+	return nil
+}
+
+// tenantBatch loads a batch owned by the tenant
+func (s *CarbonService) tenantBatch(ctx context.Context, tenantID, batchID uuid.UUID) (*models.Batch, error) {
+	batch, err := s.repo.GetBatch(ctx, batchID)
+	if err != nil {
+		if err.Error() == "batch not found" {
+			return nil, ErrCarbonNotFound
+		}
+		return nil, err
+	}
+	if batch.TenantID != tenantID {
+		return nil, ErrCarbonNotFound
+	}
+	return batch, nil
+}
+
+// Calculate works out the footprint of one battery of the batch from its bill of
+// materials and energy inputs, per kWh of the rated energy from the capacity and
+// voltage specs. Unless dryRun, the result is attached to the batch, which must
+// still be a DRAFT: an active batch's declaration is not rewritten.
+func (s *CarbonService) Calculate(ctx context.Context, tenantID, batchID uuid.UUID, actor string, input models.CarbonCalculationInput, dryRun bool) (*models.CarbonFootprintReport, error) {
+	if len(input.BillOfMaterials)+len(input.Energy) > maxCarbonLines {
+		return nil, fmt.Errorf("%w: at most %d input lines", ErrCarbonInvalid, maxCarbonLines)
+	}
+
+	batch, err := s.tenantBatch(ctx, tenantID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if !dryRun && batch.Status != models.BatchStatusDraft {
+		return nil, fmt.Errorf("%w: the footprint of a %s batch is already declared; only DRAFT batches can be recalculated (use dry_run to preview)",
+			ErrCarbonConflict, batch.Status)
+	}
+	ratings, issues := models.ParseBatchRatings(batch.Specs)
+	if errs, _ := models.SplitSpecIssues(issues); len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %s: %s", ErrCarbonInvalid, errs[0].Field, errs[0].Message)
+	}
+	if ratings.RatedEnergyWh <= 0 {
+		return nil, fmt.Errorf("%w: the batch needs capacity and voltage specs (or capacity in kWh) to work out rated energy", ErrCarbonInvalid)
+	}
+	ratedKWh := ratings.RatedEnergyWh / 1000
+
+	library, err := s.repo.ListEmissionFactors(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	factors := make(map[string]*models.EmissionFactor, len(library))
+	for _, f := range library {
+		factors[f.Key] = f
+	}
+	for _, lines := range [][]models.CarbonInputLine{input.BillOfMaterials, input.Energy} {
+		for i := range lines {
+			lines[i].FactorKey = strings.ToLower(strings.TrimSpace(lines[i].FactorKey))
+		}
+	}
+
+	report, err := models.CalculateCarbonFootprint(input, factors, ratedKWh, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCarbonInvalid, err)
+	}
+	report.BatchID = batch.ID
+	report.Capacity = batch.Specs.Capacity
+	report.Voltage = batch.Specs.NominalVoltage
+	report.CalculatedBy = actor
+
+	if dryRun {
+		return report, nil
+	}
+	if err := s.repo.SetBatchCarbonFootprint(ctx, batch.ID, report); err != nil {
+		if errors.Is(err, repository.ErrCarbonBatchNotDraft) {
+			return nil, fmt.Errorf("%w: the batch was activated during the calculation", ErrCarbonConflict)
+		}
+		return nil, err
+	}
+	return report, nil
+}
+
+// Report returns the calculation attached to the batch, or nil if there is none
+func (s *CarbonService) Report(ctx context.Context, tenantID, batchID uuid.UUID) (*models.CarbonFootprintReport, error) {
+	if _, err := s.tenantBatch(ctx, tenantID, batchID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetBatchCarbonReport(ctx, batchID)
+}
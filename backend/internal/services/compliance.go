@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// COMPLIANCE READINESS SERVICE
+// ============================================================================
+
+// Compliance errors, shown to the caller as-is
+var (
+	ErrComplianceInvalid  = errors.New("invalid compliance check")
+	ErrComplianceNotFound = errors.New("not found")
+)
+
+// ComplianceService scores a batch against the mandatory fields and documents
+// of its target markets (see the market rule packs) before it is activated or shipped
+type ComplianceService struct {
+	repo       *repository.Repository
+	validation *ValidationService
+}
+
+// NewComplianceService creates a new compliance service
+func NewComplianceService(repo *repository.Repository) *ComplianceService {
+	return &ComplianceService{repo: repo, validation: NewValidationService()}
+}
+
+// Check loads a tenant batch and evaluates it for market: one market, a
+// comma-separated list, or GLOBAL (INDIA and EU). An empty market uses the
+// batch's own markets.
+func (s *ComplianceService) Check(ctx context.Context, tenantID, batchID uuid.UUID, market string) (*models.ComplianceReport, error) {
+	var markets []models.MarketRegion
+	if strings.TrimSpace(market) != "" {
+		var err error
+		if markets, err = models.ParseMarkets(strings.Split(market, ",")); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrComplianceInvalid, err)
+		}
+	}
+
+	batch, err := s.repo.GetBatch(ctx, batchID)
+	if err != nil {
+		if err.Error() == "batch not found" {
+			return nil, ErrComplianceNotFound
+		}
+		return nil, err
+	}
+	if batch.TenantID != tenantID {
+		return nil, ErrComplianceNotFound
+	}
+	tenant, err := s.repo.GetTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Evaluate(batch, tenant, markets), nil
+}
+
+// Evaluate builds the readiness checklist of a batch from the rule packs of
+// markets, or of the batch's own markets when none are given. Checks several
+// packs share are listed once.
+func (s *ComplianceService) Evaluate(batch *models.Batch, tenant *models.Tenant, markets []models.MarketRegion) *models.ComplianceReport {
+	if len(markets) == 0 {
+		markets = batch.Markets
+	}
+	c := &marketContext{batch: batch, tenant: tenant, validation: s.validation}
+
+	var checks []models.ComplianceCheck
+	for _, ref := range applicableMarketRules(batch, markets) {
+		checks = append(checks, ref.check(c))
+	}
+	return models.NewComplianceReport(batch.ID, markets, checks)
+}
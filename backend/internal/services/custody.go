@@ -0,0 +1,379 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"exportready-battery/internal/auth"
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// CUSTODY SERVICE
+// ============================================================================
+
+// Custody errors, shown to the caller as-is
+var (
+	ErrCustodyInvalid  = errors.New("invalid custody transfer")
+	ErrCustodyNotFound = errors.New("custody transfer not found")
+	ErrCustodyConflict = errors.New("custody transfer conflicts with the passports' current custody")
+)
+
+const (
+	MaxCustodyTransfer       = 1000 // Passports per transfer (one pallet or shipment)
+	maxCustodyDocumentRef    = 100
+	maxCustodyNote           = 1000
+	custodyEmailSerialsShown = 20
+)
+
+// CustodyService runs the chain-of-custody ledger. Custody changes hands when the
+// receiving party accepts a transfer; the current owner is always the receiving
+// party of the latest accepted transfer.
+type CustodyService struct {
+	repo  *repository.Repository
+	email *EmailService
+}
+
+// NewCustodyService creates a new custody service
+func NewCustodyService(repo *repository.Repository, email *EmailService) *CustodyService {
+	return &CustodyService{repo: repo, email: email}
+}
+
+// CreateTransferRequest hands one or more passports to a receiving party
+type CreateTransferRequest struct {
+	PassportIDs []uuid.UUID `json:"passport_ids"`           // Up to 1000; set from the path for a single passport
+	ToEmail     string      `json:"to_email"`               // Receiving party; gets the acceptance link
+	ToName      string      `json:"to_name,omitempty"`      // Company or person; defaults to the email
+	ToType      string      `json:"to_type"`                // DISTRIBUTOR, RETAILER, INSTALLER, LOGISTICS, END_USER, RECYCLER, OTHER
+	DocumentRef string      `json:"document_ref,omitempty"` // Invoice, delivery note or e-way bill number
+	Note        string      `json:"note,omitempty"`
+}
+
+// CreateTransferResult is a created transfer group
+type CreateTransferResult struct {
+	GroupID   uuid.UUID           `json:"group_id"`
+	Transfers int                 `json:"transfers"`
+	To        models.CustodyParty `json:"to"`
+	Status    string              `json:"status"` // PENDING until the receiving party accepts
+}
+
+// CreateTransfer records pending transfers of the tenant's passports and emails
+// the receiving party a link to accept them
+func (s *CustodyService) CreateTransfer(ctx context.Context, tenantID uuid.UUID, actor string, req CreateTransferRequest) (*CreateTransferResult, error) {
+	to, err := validateTransferRequest(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := generateCustodyToken()
+	if err != nil {
+		return nil, err
+	}
+
+	groupID, err := s.repo.CreateCustodyTransfers(ctx, repository.CreateCustodyTransfersRequest{
+		TenantID:    tenantID,
+		PassportIDs: req.PassportIDs,
+		To:          *to,
+		DocumentRef: req.DocumentRef,
+		Note:        req.Note,
+		InitiatedBy: actor,
+		TokenHash:   auth.HashToken(token),
+	})
+	switch {
+	case errors.Is(err, repository.ErrCustodyPassportsNotFound):
+		return nil, fmt.Errorf("%w: %v", ErrCustodyInvalid, err)
+	case errors.Is(err, repository.ErrCustodyTransferPending), errors.Is(err, repository.ErrCustodySameHolder):
+		return nil, fmt.Errorf("%w: %v", ErrCustodyConflict, err)
+	case err != nil:
+		return nil, err
+	}
+
+	s.sendTransferRequest(tenantID, groupID, to.Email, req.DocumentRef, token)
+
+	return &CreateTransferResult{
+		GroupID:   groupID,
+		Transfers: len(req.PassportIDs),
+		To:        *to,
+		Status:    models.TransferStatusPending,
+	}, nil
+}
+
+// validateTransferRequest normalizes the request and returns the receiving party
+func validateTransferRequest(req *CreateTransferRequest) (*models.CustodyParty, error) {
+	req.ToEmail = strings.ToLower(strings.TrimSpace(req.ToEmail))
+	req.ToName = strings.TrimSpace(req.ToName)
+	req.ToType = strings.ToUpper(strings.TrimSpace(req.ToType))
+	req.DocumentRef = strings.TrimSpace(req.DocumentRef)
+	req.Note = strings.TrimSpace(req.Note)
+
+	if addr, err := mail.ParseAddress(req.ToEmail); err != nil || addr.Address != req.ToEmail {
+		return nil, fmt.Errorf("%w: to_email must be a valid email address", ErrCustodyInvalid)
+	}
+	if !models.IsValidPartyType(req.ToType) {
+		return nil, fmt.Errorf("%w: to_type must be DISTRIBUTOR, RETAILER, INSTALLER, LOGISTICS, END_USER, RECYCLER or OTHER", ErrCustodyInvalid)
+	}
+	if len(req.DocumentRef) > maxCustodyDocumentRef {
+		return nil, fmt.Errorf("%w: document_ref must be at most %d characters", ErrCustodyInvalid, maxCustodyDocumentRef)
+	}
+	if len(req.Note) > maxCustodyNote {
+		return nil, fmt.Errorf("%w: note must be at most %d characters", ErrCustodyInvalid, maxCustodyNote)
+	}
+
+	// Drop duplicates so a re-scanned unit does not count twice
+	seen := make(map[uuid.UUID]bool, len(req.PassportIDs))
+	ids := req.PassportIDs[:0]
+	for _, id := range req.PassportIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	req.PassportIDs = ids
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: passport_ids is required", ErrCustodyInvalid)
+	}
+	if len(ids) > MaxCustodyTransfer {
+		return nil, fmt.Errorf("%w: at most %d passports per transfer", ErrCustodyInvalid, MaxCustodyTransfer)
+	}
+
+	name := req.ToName
+	if name == "" {
+		name = req.ToEmail
+	}
+	return &models.CustodyParty{Email: req.ToEmail, Name: name, Type: req.ToType}, nil
+}
+
+// sendTransferRequest emails the acceptance link in the background
+func (s *CustodyService) sendTransferRequest(tenantID, groupID uuid.UUID, toEmail, documentRef, token string) {
+	if s.email == nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		fromName := "The manufacturer"
+		if tenant, err := s.repo.GetTenant(ctx, tenantID); err == nil && tenant.CompanyName != "" {
+			fromName = tenant.CompanyName
+		}
+
+		transfers, err := s.repo.GetCustodyTransfersByToken(ctx, auth.HashToken(token))
+		if err != nil {
+			log.Printf("Warning: Failed to load custody transfer group %s for email: %v", groupID, err)
+			return
+		}
+		serials := make([]string, 0, custodyEmailSerialsShown)
+		for _, t := range transfers {
+			if len(serials) == custodyEmailSerialsShown {
+				break
+			}
+			serials = append(serials, t.SerialNumber)
+		}
+
+		if err := s.email.SendCustodyTransferRequest(toEmail, fromName, documentRef, serials, len(transfers), token); err != nil {
+			log.Printf("Warning: Failed to send custody transfer %s to %s: %v", groupID, toEmail, err)
+		}
+	}()
+}
+
+// CustodyAcceptance is what the receiving party sees behind an acceptance link
+type CustodyAcceptance struct {
+	GroupID     uuid.UUID                 `json:"group_id"`
+	Status      string                    `json:"status"` // Status of the group's transfers
+	To          models.CustodyParty       `json:"to"`
+	DocumentRef string                    `json:"document_ref,omitempty"`
+	Note        string                    `json:"note,omitempty"`
+	Count       int                       `json:"count"`
+	Transfers   []*models.CustodyTransfer `json:"transfers"`
+}
+
+func newCustodyAcceptance(transfers []*models.CustodyTransfer) *CustodyAcceptance {
+	first := transfers[0]
+	return &CustodyAcceptance{
+		GroupID:     first.GroupID,
+		Status:      first.Status,
+		To:          first.To,
+		DocumentRef: first.DocumentRef,
+		Note:        first.Note,
+		Count:       len(transfers),
+		Transfers:   transfers,
+	}
+}
+
+// GetAcceptance retrieves the transfer group behind an acceptance token
+func (s *CustodyService) GetAcceptance(ctx context.Context, token string) (*CustodyAcceptance, error) {
+	if token == "" {
+		return nil, ErrCustodyNotFound
+	}
+	transfers, err := s.repo.GetCustodyTransfersByToken(ctx, auth.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if len(transfers) == 0 {
+		return nil, ErrCustodyNotFound
+	}
+	return newCustodyAcceptance(transfers), nil
+}
+
+// RespondToTransfer accepts or rejects the transfer group behind an acceptance
+// token. Accepting makes the receiving party the current owner.
+func (s *CustodyService) RespondToTransfer(ctx context.Context, token string, accept bool, note string) (*CustodyAcceptance, error) {
+	note = strings.TrimSpace(note)
+	if len(note) > maxCustodyNote {
+		return nil, fmt.Errorf("%w: note must be at most %d characters", ErrCustodyInvalid, maxCustodyNote)
+	}
+
+	current, err := s.GetAcceptance(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if current.Status != models.TransferStatusPending {
+		return nil, fmt.Errorf("%w: this transfer is already %s", ErrCustodyConflict, strings.ToLower(current.Status))
+	}
+
+	transfers, err := s.repo.RespondToCustodyTransfers(ctx, auth.HashToken(token), accept, note)
+	if err != nil {
+		return nil, err
+	}
+	if len(transfers) == 0 {
+		return nil, fmt.Errorf("%w: this transfer is no longer pending", ErrCustodyConflict)
+	}
+	result := newCustodyAcceptance(transfers)
+
+	if accept {
+		s.publishTransferred(ctx, transfers)
+	}
+	return result, nil
+}
+
+// publishTransferred pushes custody.transferred to the tenant's live event stream (non-critical)
+func (s *CustodyService) publishTransferred(ctx context.Context, transfers []*models.CustodyTransfer) {
+	passport, err := s.repo.GetPassport(ctx, transfers[0].PassportID)
+	if err != nil {
+		log.Printf("Warning: Failed to publish custody event: %v", err)
+		return
+	}
+	batch, err := s.repo.GetBatch(ctx, passport.BatchID)
+	if err != nil {
+		log.Printf("Warning: Failed to publish custody event: %v", err)
+		return
+	}
+
+	first := transfers[0]
+	if err := s.repo.PublishTenantEvent(ctx, batch.TenantID, models.EventCustodyTransferred, models.CustodyTransferredData{
+		GroupID:     first.GroupID,
+		ToEmail:     first.To.Email,
+		ToName:      first.To.Name,
+		ToType:      first.To.Type,
+		DocumentRef: first.DocumentRef,
+		Count:       len(transfers),
+	}); err != nil {
+		log.Printf("Warning: Failed to publish custody event: %v", err)
+	}
+}
+
+// CancelTransfer withdraws the pending transfers in a transfer's group
+func (s *CustodyService) CancelTransfer(ctx context.Context, tenantID, transferID uuid.UUID) (int, error) {
+	cancelled, err := s.repo.CancelCustodyTransferGroup(ctx, tenantID, transferID)
+	if err != nil {
+		return 0, err
+	}
+	if cancelled < 0 {
+		return 0, ErrCustodyNotFound
+	}
+	if cancelled == 0 {
+		return 0, fmt.Errorf("%w: the transfer is no longer pending", ErrCustodyConflict)
+	}
+	return cancelled, nil
+}
+
+// ListTransfers lists a tenant's transfers, newest first
+func (s *CustodyService) ListTransfers(ctx context.Context, tenantID uuid.UUID, status string, page repository.PageRequest) ([]*models.CustodyTransfer, repository.PageInfo, error) {
+	return s.repo.ListCustodyTransfers(ctx, tenantID, status, page)
+}
+
+// RecordHandover makes the holder of a magic link the current owner when their
+// status update shows they took the battery (shipping or installing it). The
+// hand-over is recorded as an accepted transfer; the link proves the receiver.
+func (s *CustodyService) RecordHandover(ctx context.Context, passportID uuid.UUID, email, role string) error {
+	party := &models.CustodyParty{
+		Email: strings.ToLower(strings.TrimSpace(email)),
+		Name:  strings.ToLower(strings.TrimSpace(email)),
+		Type:  models.PartyTypeForRole(role),
+	}
+	_, err := s.repo.RecordCustodyHandover(ctx, passportID, party, email)
+	return err
+}
+
+// CustodyTimeline is a passport's ownership history
+type CustodyTimeline struct {
+	PassportID   uuid.UUID                 `json:"passport_id"`
+	SerialNumber string                    `json:"serial_number"`
+	CurrentOwner *models.CustodyParty      `json:"current_owner"` // nil while the manufacturer holds it
+	Timeline     []models.CustodyPeriod    `json:"timeline"`      // Oldest first
+	Pending      []*models.CustodyTransfer `json:"pending"`       // Awaiting acceptance
+	At           *time.Time                `json:"at,omitempty"`
+	HolderAt     *models.CustodyPeriod     `json:"holder_at,omitempty"` // Period covering At
+}
+
+// Timeline builds a tenant's passport's ownership timeline. With at set, it
+// also reports who held the battery at that time.
+func (s *CustodyService) Timeline(ctx context.Context, tenantID, passportID uuid.UUID, at *time.Time) (*CustodyTimeline, error) {
+	passport, err := s.repo.GetPassport(ctx, passportID)
+	if err != nil {
+		return nil, ErrCustodyNotFound
+	}
+	batch, err := s.repo.GetBatch(ctx, passport.BatchID)
+	if err != nil || batch.TenantID != tenantID {
+		return nil, ErrCustodyNotFound
+	}
+
+	transfers, err := s.repo.ListPassportCustodyTransfers(ctx, tenantID, passportID)
+	if err != nil {
+		return nil, err
+	}
+
+	var accepted []*models.CustodyTransfer
+	pending := []*models.CustodyTransfer{}
+	for _, t := range transfers {
+		if t.Status == models.TransferStatusAccepted {
+			accepted = append(accepted, t)
+		} else {
+			pending = append(pending, t)
+		}
+	}
+
+	timeline := models.BuildCustodyTimeline(passport.CreatedAt, accepted)
+	result := &CustodyTimeline{
+		PassportID:   passport.UUID,
+		SerialNumber: passport.SerialNumber,
+		CurrentOwner: timeline[len(timeline)-1].Holder,
+		Timeline:     timeline,
+		Pending:      pending,
+	}
+	if at != nil {
+		result.At = at
+		result.HolderAt = models.CustodyHolderAt(timeline, *at)
+	}
+	return result, nil
+}
+
+// generateCustodyToken returns a random acceptance token; only its hash is stored
+func generateCustodyToken() (string, error) {
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", fmt.Errorf("failed to generate acceptance token: %w", err)
+	}
+	return hex.EncodeToString(randomBytes), nil
+}
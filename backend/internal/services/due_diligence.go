@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// DUE DILIGENCE SERVICE
+// ============================================================================
+
+// Due diligence errors, shown to the caller as-is
+var (
+	ErrDueDiligenceInvalid  = errors.New("invalid due diligence input")
+	ErrDueDiligenceNotFound = errors.New("not found")
+)
+
+const (
+	MaxDueDiligenceDocuments = 50 // Per batch
+	maxDocumentField         = 255
+)
+
+// DueDiligenceService manages a batch's recycled content, supplier list and
+// third-party audit reports
+type DueDiligenceService struct {
+	repo *repository.Repository
+}
+
+// NewDueDiligenceService creates a new due diligence service
+func NewDueDiligenceService(repo *repository.Repository) *DueDiligenceService {
+	return &DueDiligenceService{repo: repo}
+}
+
+// tenantBatch loads a batch, hiding other tenants' batches as not found
+func (s *DueDiligenceService) tenantBatch(ctx context.Context, tenantID, batchID uuid.UUID) (*models.Batch, error) {
+	batch, err := s.repo.GetBatch(ctx, batchID)
+	if err != nil {
+		if err.Error() == "batch not found" {
+			return nil, ErrDueDiligenceNotFound
+		}
+		return nil, err
+	}
+	if batch.TenantID != tenantID {
+		return nil, ErrDueDiligenceNotFound
+	}
+	return batch, nil
+}
+
+// disclosure assembles a batch's disclosure with its audit reports
+func (s *DueDiligenceService) disclosure(ctx context.Context, batch *models.Batch) (*models.DueDiligenceDisclosure, error) {
+	docs, err := s.repo.ListDueDiligenceDocuments(ctx, batch.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.DueDiligenceDisclosure{
+		BatchID:            batch.ID,
+		RecycledContentPct: batch.Specs.RecycledContentPct,
+		RecycledContent:    batch.Specs.RecycledContent,
+		DueDiligence:       batch.Specs.DueDiligence,
+		Documents:          docs,
+	}, nil
+}
+
+// Get returns a tenant batch's full disclosure
+func (s *DueDiligenceService) Get(ctx context.Context, tenantID, batchID uuid.UUID) (*models.DueDiligenceDisclosure, error) {
+	batch, err := s.tenantBatch(ctx, tenantID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	return s.disclosure(ctx, batch)
+}
+
+// Update replaces the batch's recycled shares and supplier list. It returns every
+// spec issue found; nothing is saved when any of them is an error.
+func (s *DueDiligenceService) Update(ctx context.Context, tenantID, batchID uuid.UUID, recycled *models.RecycledContent, dd *models.DueDiligence) (*models.DueDiligenceDisclosure, []models.SpecIssue, error) {
+	batch, err := s.tenantBatch(ctx, tenantID, batchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if batch.Status == models.BatchStatusArchived {
+		return nil, nil, fmt.Errorf("%w: archived batches cannot be changed", ErrDueDiligenceInvalid)
+	}
+
+	// Validated against the batch's own composition
+	spec := batch.Specs
+	spec.RecycledContent = recycled
+	spec.DueDiligence = dd
+	issues := models.ValidateDueDiligence(&spec)
+	if errs, _ := models.SplitSpecIssues(issues); len(errs) > 0 {
+		return nil, issues, nil
+	}
+
+	if err := s.repo.SetBatchDueDiligence(ctx, batch.ID, spec.RecycledContent, spec.DueDiligence); err != nil {
+		return nil, nil, err
+	}
+	batch.Specs = spec
+	disclosure, err := s.disclosure(ctx, batch)
+	return disclosure, issues, err
+}
+
+// PassportDisclosure returns the full disclosure of a passport's batch, for a
+// partner with a legitimate interest
+func (s *DueDiligenceService) PassportDisclosure(ctx context.Context, passportID uuid.UUID) (*models.DueDiligenceDisclosure, error) {
+	passport, err := s.repo.GetPassport(ctx, passportID)
+	if err != nil {
+		if err.Error() == "passport not found" {
+			return nil, ErrDueDiligenceNotFound
+		}
+		return nil, err
+	}
+	batch, err := s.repo.GetBatch(ctx, passport.BatchID)
+	if err != nil {
+		return nil, err
+	}
+	return s.disclosure(ctx, batch)
+}
+
+// PassportDocument returns an audit report of a passport's batch
+func (s *DueDiligenceService) PassportDocument(ctx context.Context, passportID, docID uuid.UUID) (*models.DueDiligenceDocument, error) {
+	passport, err := s.repo.GetPassport(ctx, passportID)
+	if err != nil {
+		if err.Error() == "passport not found" {
+			return nil, ErrDueDiligenceNotFound
+		}
+		return nil, err
+	}
+	return s.document(ctx, passport.BatchID, docID)
+}
+
+// AddDocumentRequest describes an uploaded audit report
+type AddDocumentRequest struct {
+	Title      string
+	Auditor    string
+	Supplier   string // Party audited
+	IssuedOn   string // YYYY-MM-DD, optional
+	FileName   string
+	SizeBytes  int64
+	UploadedBy string
+}
+
+// dueDiligenceDir is where a batch's audit reports are stored:
+// ./storage/{tenant_id}/due-diligence/{batch_id}/
+func dueDiligenceDir(tenantID, batchID uuid.UUID) string {
+	return privateDir(tenantID, "due-diligence", batchID.String())
+}
+
+// AddDocument stores an audit report PDF and attaches it to the batch
+func (s *DueDiligenceService) AddDocument(ctx context.Context, tenantID, batchID uuid.UUID, req AddDocumentRequest, content io.Reader) (*models.DueDiligenceDocument, error) {
+	doc := &models.DueDiligenceDocument{
+		ID:         uuid.New(),
+		BatchID:    batchID,
+		Title:      strings.TrimSpace(req.Title),
+		Auditor:    strings.TrimSpace(req.Auditor),
+		Supplier:   strings.TrimSpace(req.Supplier),
+		FileName:   filepath.Base(req.FileName),
+		SizeBytes:  req.SizeBytes,
+		UploadedBy: req.UploadedBy,
+	}
+	if doc.Title == "" {
+		return nil, fmt.Errorf("%w: title is required", ErrDueDiligenceInvalid)
+	}
+	for _, f := range []struct{ name, value string }{
+		{"title", doc.Title}, {"auditor", doc.Auditor}, {"supplier", doc.Supplier}, {"file name", doc.FileName},
+	} {
+		if len(f.value) > maxDocumentField {
+			return nil, fmt.Errorf("%w: %s must be at most %d characters", ErrDueDiligenceInvalid, f.name, maxDocumentField)
+		}
+	}
+	if issued := strings.TrimSpace(req.IssuedOn); issued != "" {
+		date, err := time.Parse("2006-01-02", issued)
+		if err != nil {
+			return nil, fmt.Errorf("%w: issued_on must be a date (YYYY-MM-DD)", ErrDueDiligenceInvalid)
+		}
+		doc.IssuedOn = &date
+	}
+
+	if _, err := s.tenantBatch(ctx, tenantID, batchID); err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.ListDueDiligenceDocuments(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxDueDiligenceDocuments {
+		return nil, fmt.Errorf("%w: at most %d audit reports per batch", ErrDueDiligenceInvalid, MaxDueDiligenceDocuments)
+	}
+
+	dir := dueDiligenceDir(tenantID, batchID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit report directory: %w", err)
+	}
+	doc.FilePath = filepath.Join(dir, doc.ID.String()+".pdf")
+	dst, err := os.Create(doc.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save audit report: %w", err)
+	}
+	_, err = io.Copy(dst, content)
+	dst.Close()
+	if err == nil {
+		err = s.repo.CreateDueDiligenceDocument(ctx, tenantID, doc)
+	}
+	if err != nil {
+		if rmErr := os.Remove(doc.FilePath); rmErr != nil {
+			log.Printf("Warning: Failed to remove audit report %s: %v", doc.FilePath, rmErr)
+		}
+		return nil, err
+	}
+	return doc, nil
+}
+
+// document loads one of a batch's audit reports
+func (s *DueDiligenceService) document(ctx context.Context, batchID, docID uuid.UUID) (*models.DueDiligenceDocument, error) {
+	doc, err := s.repo.GetDueDiligenceDocument(ctx, batchID, docID)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, ErrDueDiligenceNotFound
+	}
+	return doc, nil
+}
+
+// Document returns one of a tenant batch's audit reports
+func (s *DueDiligenceService) Document(ctx context.Context, tenantID, batchID, docID uuid.UUID) (*models.DueDiligenceDocument, error) {
+	if _, err := s.tenantBatch(ctx, tenantID, batchID); err != nil {
+		return nil, err
+	}
+	return s.document(ctx, batchID, docID)
+}
+
+// DeleteDocument removes an audit report and its file
+func (s *DueDiligenceService) DeleteDocument(ctx context.Context, tenantID, batchID, docID uuid.UUID) error {
+	doc, err := s.Document(ctx, tenantID, batchID, docID)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.repo.DeleteDueDiligenceDocument(ctx, batchID, docID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrDueDiligenceNotFound
+	}
+	if err := os.Remove(doc.FilePath); err != nil && !os.IsNotExist(err) {
+		log.Printf("Warning: Failed to remove audit report %s: %v", doc.FilePath, err)
+	}
+	return nil
+}
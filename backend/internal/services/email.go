@@ -4,9 +4,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"html"
 	"log"
 	"net/http"
 	"os"
+	"strings"
+	"time"
+
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
 )
 
 // EmailService handles sending transactional emails
@@ -126,6 +132,83 @@ If you didn't request this, please ignore this email.
 	return e.sendEmail(toEmail, "Reset Your Password - ExportReady", html, plainText)
 }
 
+// SendAccountLockedEmail notifies the account owner that repeated failed
+// logins locked the account, with a link to unlock it early
+func (e *EmailService) SendAccountLockedEmail(toEmail, unlockToken, ip string, lockedUntil time.Time) error {
+	unlockLink := fmt.Sprintf("%s/unlock?token=%s", e.baseURL, unlockToken)
+	until := lockedUntil.UTC().Format("15:04 MST, 02 Jan 2006")
+
+	if !e.enabled {
+		// Log to console in development
+		log.Printf("🔒 Account Unlock Link for %s (locked until %s, ip %s):\n%s", toEmail, until, ip, unlockLink)
+		return nil
+	}
+
+	html := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Your Account Was Locked</title>
+</head>
+<body style="margin: 0; padding: 0; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; background-color: #f1f5f9;">
+    <table role="presentation" style="width: 100%%; border-collapse: collapse;">
+        <tr>
+            <td style="padding: 40px 20px;">
+                <table role="presentation" style="max-width: 480px; margin: 0 auto; background-color: #ffffff; border-radius: 12px; overflow: hidden; box-shadow: 0 4px 6px rgba(0, 0, 0, 0.05);">
+                    <tr>
+                        <td style="background: linear-gradient(135deg, #dc2626 0%%, #f87171 100%%); padding: 32px 40px; text-align: center;">
+                            <h1 style="margin: 0; color: #ffffff; font-size: 24px; font-weight: 700;">ExportReady</h1>
+                            <p style="margin: 8px 0 0; color: rgba(255,255,255,0.9); font-size: 14px;">Battery Passport Registry</p>
+                        </td>
+                    </tr>
+                    <tr>
+                        <td style="padding: 40px;">
+                            <h2 style="margin: 0 0 16px; color: #1e293b; font-size: 20px; font-weight: 600;">
+                                Your Account Was Locked
+                            </h2>
+                            <p style="margin: 0 0 24px; color: #64748b; font-size: 15px; line-height: 1.6;">
+                                We blocked sign-in after too many failed login attempts from <strong>%s</strong>. The lock lifts automatically at <strong>%s</strong>. If this was you, unlock your account now:
+                            </p>
+                            <a href="%s" style="display: inline-block; background-color: #059669; color: #ffffff; text-decoration: none; padding: 14px 32px; border-radius: 8px; font-size: 15px; font-weight: 600;">
+                                Unlock Account →
+                            </a>
+                            <p style="margin: 24px 0 0; color: #94a3b8; font-size: 13px; line-height: 1.5;">
+                                🔒 If this wasn't you, consider resetting your password.
+                            </p>
+                        </td>
+                    </tr>
+                    <tr>
+                        <td style="background-color: #f8fafc; padding: 24px 40px; border-top: 1px solid #e2e8f0;">
+                            <p style="margin: 0; color: #94a3b8; font-size: 12px; text-align: center;">
+                                © 2026 ExportReady Battery
+                            </p>
+                        </td>
+                    </tr>
+                </table>
+            </td>
+        </tr>
+    </table>
+</body>
+</html>
+`, ip, until, unlockLink)
+
+	plainText := fmt.Sprintf(`Your Account Was Locked
+
+We blocked sign-in after too many failed login attempts from %s.
+The lock lifts automatically at %s. If this was you, unlock your account now:
+
+%s
+
+If this wasn't you, consider resetting your password.
+
+© 2026 ExportReady Battery
+`, ip, until, unlockLink)
+
+	return e.sendEmail(toEmail, "Your Account Was Locked - ExportReady", html, plainText)
+}
+
 // ResendEmailRequest is the Resend API request structure
 type ResendEmailRequest struct {
 	From    string   `json:"from"`
@@ -273,6 +356,454 @@ func getActionContent(action string) (title, description, color string) {
 	}
 }
 
+// SendRecallNotice tells an owner that a recall covers their batteries, with a
+// link to each battery's public passport page
+func (e *EmailService) SendRecallNotice(toEmail string, campaign *models.RecallCampaign, units []repository.RecallOwnerUnit) error {
+	if !e.enabled {
+		log.Printf("📧 [MOCK] Would send recall notice %q to %s for %d batteries", campaign.Title, toEmail, len(units))
+		return nil
+	}
+
+	severityColor := getRecallSeverityColor(campaign.Severity)
+
+	var unitRows, unitLines strings.Builder
+	for _, u := range units {
+		link := fmt.Sprintf("%s/p/%s", e.baseURL, u.PassportID)
+		fmt.Fprintf(&unitRows, `<li style="margin: 0 0 6px;"><a href="%s" style="color: #059669; font-family: monospace;">%s</a></li>`,
+			link, html.EscapeString(u.SerialNumber))
+		fmt.Fprintf(&unitLines, "- %s: %s\n", u.SerialNumber, link)
+	}
+
+	remedy := campaign.RemedyInstructions
+	if remedy == "" {
+		remedy = "Stop using the battery and wait for further instructions from the manufacturer."
+	}
+	reference := ""
+	if campaign.RegulatorReference != "" {
+		reference = fmt.Sprintf(`<p style="margin: 16px 0 0; color: #94a3b8; font-size: 13px;">Regulator reference: %s</p>`,
+			html.EscapeString(campaign.RegulatorReference))
+	}
+
+	htmlBody := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Safety Recall - %s</title>
+</head>
+<body style="margin: 0; padding: 0; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; background-color: #f1f5f9;">
+    <table role="presentation" style="width: 100%%; border-collapse: collapse;">
+        <tr>
+            <td style="padding: 40px 20px;">
+                <table role="presentation" style="max-width: 480px; margin: 0 auto; background-color: #ffffff; border-radius: 12px; overflow: hidden; box-shadow: 0 4px 6px rgba(0, 0, 0, 0.05);">
+                    <!-- Header -->
+                    <tr>
+                        <td style="background: linear-gradient(135deg, #b91c1c 0%%, #ef4444 100%%); padding: 32px 40px; text-align: center;">
+                            <h1 style="margin: 0; color: #ffffff; font-size: 24px; font-weight: 700;">Safety Recall</h1>
+                            <p style="margin: 8px 0 0; color: rgba(255,255,255,0.9); font-size: 14px;">ExportReady Battery Passport Registry</p>
+                        </td>
+                    </tr>
+
+                    <!-- Content -->
+                    <tr>
+                        <td style="padding: 40px;">
+                            <!-- Severity Badge -->
+                            <div style="background-color: %s; color: #ffffff; display: inline-block; padding: 8px 16px; border-radius: 20px; font-size: 12px; font-weight: 600; text-transform: uppercase; margin-bottom: 24px;">
+                                %s severity
+                            </div>
+
+                            <h2 style="margin: 0 0 16px; color: #1e293b; font-size: 20px; font-weight: 600;">
+                                %s
+                            </h2>
+
+                            <p style="margin: 0 0 24px; color: #64748b; font-size: 15px; line-height: 1.6;">
+                                %s
+                            </p>
+
+                            <!-- Remedy -->
+                            <div style="background-color: #fef2f2; border-left: 4px solid #ef4444; padding: 16px; margin-bottom: 24px;">
+                                <p style="margin: 0 0 4px; color: #991b1b; font-size: 12px; text-transform: uppercase; letter-spacing: 0.5px;">What to do</p>
+                                <p style="margin: 0; color: #7f1d1d; font-size: 14px; line-height: 1.5;">%s</p>
+                            </div>
+
+                            <!-- Affected Batteries -->
+                            <div style="background-color: #f8fafc; border-radius: 8px; padding: 16px;">
+                                <p style="margin: 0 0 8px; color: #94a3b8; font-size: 12px; text-transform: uppercase; letter-spacing: 0.5px;">Your affected batteries</p>
+                                <ul style="margin: 0; padding-left: 20px; font-size: 14px;">%s</ul>
+                            </div>
+                            %s
+                        </td>
+                    </tr>
+
+                    <!-- Footer -->
+                    <tr>
+                        <td style="background-color: #f8fafc; padding: 24px 40px; border-top: 1px solid #e2e8f0;">
+                            <p style="margin: 0; color: #94a3b8; font-size: 12px; text-align: center;">
+                                You are receiving this because you are the registered owner of these batteries.<br>
+                                © 2026 ExportReady Battery. Compliant with EU Battery Regulation 2023/1542.
+                            </p>
+                        </td>
+                    </tr>
+                </table>
+            </td>
+        </tr>
+    </table>
+</body>
+</html>
+`, html.EscapeString(campaign.Title), severityColor, html.EscapeString(campaign.Severity),
+		html.EscapeString(campaign.Title), html.EscapeString(campaign.Reason), html.EscapeString(remedy),
+		unitRows.String(), reference)
+
+	plainText := fmt.Sprintf(`
+SAFETY RECALL (%s severity): %s
+
+%s
+
+What to do:
+%s
+
+Your affected batteries:
+%s
+You are receiving this because you are the registered owner of these batteries.
+
+© 2026 ExportReady Battery
+`, campaign.Severity, campaign.Title, campaign.Reason, remedy, unitLines.String())
+
+	return e.sendEmail(toEmail, fmt.Sprintf("Safety recall: %s", campaign.Title), htmlBody, plainText)
+}
+
+// SendWarrantyClaimDecision tells the technician who filed a claim whether it was
+// approved (with the RMA number to quote when returning the unit) or rejected
+func (e *EmailService) SendWarrantyClaimDecision(toEmail string, claim *models.WarrantyClaim) error {
+	if !e.enabled {
+		log.Printf("📧 [MOCK] Would send warranty claim %s decision (%s) to %s", claim.ID, claim.Status, toEmail)
+		return nil
+	}
+
+	title, badgeColor := "Warranty Claim Rejected", "#dc2626"
+	next := "The battery has been returned to service. Contact the manufacturer if you have questions about this decision."
+	if claim.Status == models.ClaimStatusApproved {
+		title, badgeColor = "Warranty Claim Approved", "#059669"
+		next = fmt.Sprintf("Send the battery back quoting RMA number %s. The claim is closed when the unit is received.", claim.RMANumber)
+	}
+	passportLink := fmt.Sprintf("%s/p/%s", e.baseURL, claim.PassportID)
+
+	note := ""
+	if claim.DecisionNote != "" {
+		note = fmt.Sprintf(`<p style="margin: 0 0 24px; color: #475569; font-size: 14px; line-height: 1.5;"><strong>Note from the manufacturer:</strong> %s</p>`,
+			html.EscapeString(claim.DecisionNote))
+	}
+
+	htmlBody := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>%s</title>
+</head>
+<body style="margin: 0; padding: 0; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; background-color: #f1f5f9;">
+    <table role="presentation" style="width: 100%%; border-collapse: collapse;">
+        <tr>
+            <td style="padding: 40px 20px;">
+                <table role="presentation" style="max-width: 480px; margin: 0 auto; background-color: #ffffff; border-radius: 12px; overflow: hidden; box-shadow: 0 4px 6px rgba(0, 0, 0, 0.05);">
+                    <!-- Header -->
+                    <tr>
+                        <td style="background: linear-gradient(135deg, #059669 0%%, #10b981 100%%); padding: 32px 40px; text-align: center;">
+                            <h1 style="margin: 0; color: #ffffff; font-size: 24px; font-weight: 700;">ExportReady</h1>
+                            <p style="margin: 8px 0 0; color: rgba(255,255,255,0.9); font-size: 14px;">Battery Passport Registry</p>
+                        </td>
+                    </tr>
+
+                    <!-- Content -->
+                    <tr>
+                        <td style="padding: 40px;">
+                            <div style="background-color: %s; color: #ffffff; display: inline-block; padding: 8px 16px; border-radius: 20px; font-size: 12px; font-weight: 600; text-transform: uppercase; margin-bottom: 24px;">
+                                %s
+                            </div>
+
+                            <h2 style="margin: 0 0 16px; color: #1e293b; font-size: 20px; font-weight: 600;">
+                                %s
+                            </h2>
+
+                            <p style="margin: 0 0 24px; color: #64748b; font-size: 15px; line-height: 1.6;">
+                                %s
+                            </p>
+                            %s
+                            <!-- Claim Info -->
+                            <div style="background-color: #f8fafc; border-radius: 8px; padding: 16px;">
+                                <p style="margin: 0 0 4px; color: #94a3b8; font-size: 12px; text-transform: uppercase; letter-spacing: 0.5px;">Battery</p>
+                                <p style="margin: 0 0 12px; color: #475569; font-size: 14px; font-family: monospace;"><a href="%s" style="color: #059669;">%s</a></p>
+                                <p style="margin: 0 0 4px; color: #94a3b8; font-size: 12px; text-transform: uppercase; letter-spacing: 0.5px;">Fault code</p>
+                                <p style="margin: 0; color: #475569; font-size: 14px; font-family: monospace;">%s</p>
+                            </div>
+                        </td>
+                    </tr>
+
+                    <!-- Footer -->
+                    <tr>
+                        <td style="background-color: #f8fafc; padding: 24px 40px; border-top: 1px solid #e2e8f0;">
+                            <p style="margin: 0; color: #94a3b8; font-size: 12px; text-align: center;">
+                                © 2026 ExportReady Battery. Compliant with EU Battery Regulation 2023/1542.
+                            </p>
+                        </td>
+                    </tr>
+                </table>
+            </td>
+        </tr>
+    </table>
+</body>
+</html>
+`, title, badgeColor, claim.Status, title, html.EscapeString(next), note,
+		passportLink, html.EscapeString(claim.SerialNumber), claim.FaultCode)
+
+	plainText := fmt.Sprintf(`
+%s
+
+%s
+
+Battery: %s (%s)
+Fault code: %s
+%s
+© 2026 ExportReady Battery
+`, title, next, claim.SerialNumber, passportLink, claim.FaultCode, claim.DecisionNote)
+
+	return e.sendEmail(toEmail, fmt.Sprintf("%s - %s", title, claim.SerialNumber), htmlBody, plainText)
+}
+
+// SendCustodyTransferRequest asks the receiving party to accept custody of the
+// transferred batteries. serials lists the first units of the group; total is
+// the full count.
+func (e *EmailService) SendCustodyTransferRequest(toEmail, fromName, documentRef string, serials []string, total int, token string) error {
+	acceptURL := fmt.Sprintf("%s/custody/accept?token=%s", e.baseURL, token)
+
+	if !e.enabled {
+		log.Printf("📧 [MOCK] Would send custody transfer of %d batteries from %s to %s", total, fromName, toEmail)
+		log.Printf("📧 [MOCK] Acceptance link: %s", acceptURL)
+		return nil
+	}
+
+	var list strings.Builder
+	for _, serial := range serials {
+		fmt.Fprintf(&list, `<li style="font-family: monospace;">%s</li>`, html.EscapeString(serial))
+	}
+	if more := total - len(serials); more > 0 {
+		fmt.Fprintf(&list, `<li>… and %d more</li>`, more)
+	}
+
+	document := ""
+	if documentRef != "" {
+		document = fmt.Sprintf(`<p style="margin: 0 0 4px; color: #94a3b8; font-size: 12px; text-transform: uppercase; letter-spacing: 0.5px;">Document</p>
+                                <p style="margin: 0 0 12px; color: #475569; font-size: 14px; font-family: monospace;">%s</p>`, html.EscapeString(documentRef))
+	}
+
+	htmlBody := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Confirm Battery Custody</title>
+</head>
+<body style="margin: 0; padding: 0; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; background-color: #f1f5f9;">
+    <table role="presentation" style="width: 100%%; border-collapse: collapse;">
+        <tr>
+            <td style="padding: 40px 20px;">
+                <table role="presentation" style="max-width: 480px; margin: 0 auto; background-color: #ffffff; border-radius: 12px; overflow: hidden; box-shadow: 0 4px 6px rgba(0, 0, 0, 0.05);">
+                    <!-- Header -->
+                    <tr>
+                        <td style="background: linear-gradient(135deg, #059669 0%%, #10b981 100%%); padding: 32px 40px; text-align: center;">
+                            <h1 style="margin: 0; color: #ffffff; font-size: 24px; font-weight: 700;">ExportReady</h1>
+                            <p style="margin: 8px 0 0; color: rgba(255,255,255,0.9); font-size: 14px;">Battery Passport Registry</p>
+                        </td>
+                    </tr>
+
+                    <!-- Content -->
+                    <tr>
+                        <td style="padding: 40px;">
+                            <h2 style="margin: 0 0 16px; color: #1e293b; font-size: 20px; font-weight: 600;">
+                                Confirm receipt of %d batteries
+                            </h2>
+
+                            <p style="margin: 0 0 24px; color: #64748b; font-size: 15px; line-height: 1.6;">
+                                <strong>%s</strong> is transferring custody of these batteries to you. Confirm once you have received them so the chain of custody on their battery passports is up to date.
+                            </p>
+
+                            <!-- CTA Button -->
+                            <table role="presentation" style="width: 100%%; border-collapse: collapse;">
+                                <tr>
+                                    <td style="text-align: center; padding: 8px 0 32px;">
+                                        <a href="%s" style="display: inline-block; background: linear-gradient(135deg, #059669 0%%, #10b981 100%%); color: #ffffff; text-decoration: none; padding: 14px 32px; border-radius: 8px; font-weight: 600; font-size: 16px;">
+                                            Review &amp; Accept →
+                                        </a>
+                                    </td>
+                                </tr>
+                            </table>
+
+                            <!-- Transfer Info -->
+                            <div style="background-color: #f8fafc; border-radius: 8px; padding: 16px;">
+                                %s
+                                <p style="margin: 0 0 4px; color: #94a3b8; font-size: 12px; text-transform: uppercase; letter-spacing: 0.5px;">Batteries</p>
+                                <ul style="margin: 0; padding-left: 20px; color: #475569; font-size: 14px;">%s</ul>
+                            </div>
+                        </td>
+                    </tr>
+
+                    <!-- Footer -->
+                    <tr>
+                        <td style="background-color: #f8fafc; padding: 24px 40px; border-top: 1px solid #e2e8f0;">
+                            <p style="margin: 0; color: #94a3b8; font-size: 12px; text-align: center;">
+                                If you did not expect this transfer, you can decline it from the same page.<br>
+                                © 2026 ExportReady Battery. Compliant with EU Battery Regulation 2023/1542.
+                            </p>
+                        </td>
+                    </tr>
+                </table>
+            </td>
+        </tr>
+    </table>
+</body>
+</html>
+`, total, html.EscapeString(fromName), acceptURL, document, list.String())
+
+	plainText := fmt.Sprintf(`
+Confirm receipt of %d batteries
+
+%s is transferring custody of these batteries to you. Confirm once you have received them:
+
+%s
+
+Document: %s
+Batteries: %s
+
+If you did not expect this transfer, you can decline it from the same page.
+
+© 2026 ExportReady Battery
+`, total, fromName, acceptURL, documentRef, strings.Join(serials, ", "))
+
+	return e.sendEmail(toEmail, fmt.Sprintf("Confirm receipt of %d batteries from %s", total, fromName), htmlBody, plainText)
+}
+
+// SendPLIAuditRequest invites a chartered accountant to attest the domestic
+// value addition of a batch through a one-time link
+func (e *EmailService) SendPLIAuditRequest(toEmail, caName, companyName, batchName, note string, expiresAt time.Time, token string) error {
+	attestURL := fmt.Sprintf("%s/pli-audit/attest?token=%s", e.baseURL, token)
+
+	if !e.enabled {
+		log.Printf("📧 [MOCK] Would send PLI DVA audit request for batch %s of %s to %s", batchName, companyName, toEmail)
+		log.Printf("📧 [MOCK] Attestation link: %s", attestURL)
+		return nil
+	}
+
+	message := ""
+	if note != "" {
+		message = fmt.Sprintf(`<p style="margin: 0 0 4px; color: #94a3b8; font-size: 12px; text-transform: uppercase; letter-spacing: 0.5px;">Message</p>
+                                <p style="margin: 0 0 12px; color: #475569; font-size: 14px;">%s</p>`, html.EscapeString(note))
+	}
+	expires := expiresAt.Format("02 Jan 2006")
+
+	htmlBody := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>PLI DVA Attestation Request</title>
+</head>
+<body style="margin: 0; padding: 0; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; background-color: #f1f5f9;">
+    <table role="presentation" style="width: 100%%; border-collapse: collapse;">
+        <tr>
+            <td style="padding: 40px 20px;">
+                <table role="presentation" style="max-width: 480px; margin: 0 auto; background-color: #ffffff; border-radius: 12px; overflow: hidden; box-shadow: 0 4px 6px rgba(0, 0, 0, 0.05);">
+                    <!-- Header -->
+                    <tr>
+                        <td style="background: linear-gradient(135deg, #059669 0%%, #10b981 100%%); padding: 32px 40px; text-align: center;">
+                            <h1 style="margin: 0; color: #ffffff; font-size: 24px; font-weight: 700;">ExportReady</h1>
+                            <p style="margin: 8px 0 0; color: rgba(255,255,255,0.9); font-size: 14px;">Battery Passport Registry</p>
+                        </td>
+                    </tr>
+
+                    <!-- Content -->
+                    <tr>
+                        <td style="padding: 40px;">
+                            <h2 style="margin: 0 0 16px; color: #1e293b; font-size: 20px; font-weight: 600;">
+                                Domestic value addition audit
+                            </h2>
+
+                            <p style="margin: 0 0 24px; color: #64748b; font-size: 15px; line-height: 1.6;">
+                                Dear %s, <strong>%s</strong> requests your attestation of the domestic value addition (DVA) of battery batch <strong>%s</strong> under the PLI scheme. Enter the audited DVA with your ICAI membership number and UDIN, and upload the signed certificate.
+                            </p>
+
+                            <!-- CTA Button -->
+                            <table role="presentation" style="width: 100%%; border-collapse: collapse;">
+                                <tr>
+                                    <td style="text-align: center; padding: 8px 0 32px;">
+                                        <a href="%s" style="display: inline-block; background: linear-gradient(135deg, #059669 0%%, #10b981 100%%); color: #ffffff; text-decoration: none; padding: 14px 32px; border-radius: 8px; font-weight: 600; font-size: 16px;">
+                                            Attest DVA →
+                                        </a>
+                                    </td>
+                                </tr>
+                            </table>
+
+                            <!-- Request Info -->
+                            <div style="background-color: #f8fafc; border-radius: 8px; padding: 16px;">
+                                %s
+                                <p style="margin: 0 0 4px; color: #94a3b8; font-size: 12px; text-transform: uppercase; letter-spacing: 0.5px;">Link valid until</p>
+                                <p style="margin: 0; color: #475569; font-size: 14px;">%s</p>
+                            </div>
+                        </td>
+                    </tr>
+
+                    <!-- Footer -->
+                    <tr>
+                        <td style="background-color: #f8fafc; padding: 24px 40px; border-top: 1px solid #e2e8f0;">
+                            <p style="margin: 0; color: #94a3b8; font-size: 12px; text-align: center;">
+                                The link can be used once. If you were not engaged for this audit, you can ignore this email.<br>
+                                © 2026 ExportReady Battery. Compliant with EU Battery Regulation 2023/1542.
+                            </p>
+                        </td>
+                    </tr>
+                </table>
+            </td>
+        </tr>
+    </table>
+</body>
+</html>
+`, html.EscapeString(caName), html.EscapeString(companyName), html.EscapeString(batchName), attestURL, message, expires)
+
+	plainText := fmt.Sprintf(`
+Domestic value addition audit
+
+Dear %s, %s requests your attestation of the domestic value addition (DVA) of battery batch %s under the PLI scheme. Enter the audited DVA with your ICAI membership number and UDIN, and upload the signed certificate:
+
+%s
+
+Message: %s
+Link valid until: %s
+
+The link can be used once. If you were not engaged for this audit, you can ignore this email.
+
+© 2026 ExportReady Battery
+`, caName, companyName, batchName, attestURL, note, expires)
+
+	return e.sendEmail(toEmail, fmt.Sprintf("PLI DVA attestation request from %s - %s", companyName, batchName), htmlBody, plainText)
+}
+
+// getRecallSeverityColor returns the badge color for a recall severity
+func getRecallSeverityColor(severity string) string {
+	switch severity {
+	case models.RecallSeverityCritical:
+		return "#7f1d1d" // dark red
+	case models.RecallSeverityHigh:
+		return "#dc2626" // red
+	case models.RecallSeverityMedium:
+		return "#f97316" // orange
+	default:
+		return "#f59e0b" // amber
+	}
+}
+
 // sendEmail sends an email via Resend API
 func (e *EmailService) sendEmail(to, subject, html, plainText string) error {
 	reqBody := ResendEmailRequest{
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// ============================================================================
+// EPR ANNUAL RETURN SERVICE
+// ============================================================================
+
+// ErrEPRInvalid is returned for invalid targets or financial years, shown to
+// the caller as-is
+var ErrEPRInvalid = errors.New("invalid EPR input")
+
+// EPRService assembles the producer's EPR annual return under the BWM Rules
+// from batches and passports, and exports it for filing with CPCB
+type EPRService struct {
+	repo *repository.Repository
+}
+
+// NewEPRService creates a new EPR service
+func NewEPRService(repo *repository.Repository) *EPRService {
+	return &EPRService{repo: repo}
+}
+
+// Targets returns the tenant's target for every category, defaults included
+func (s *EPRService) Targets(ctx context.Context, tenantID uuid.UUID) ([]models.EPRTarget, error) {
+	configured, err := s.repo.ListEPRTargets(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	byCategory := make(map[string]models.EPRTarget, len(configured))
+	for _, t := range configured {
+		byCategory[t.Category] = t
+	}
+
+	targets := make([]models.EPRTarget, 0, len(models.EPRCategories))
+	for _, category := range models.EPRCategories {
+		t, ok := byCategory[category]
+		if !ok {
+			t = models.EPRTarget{Category: category, TargetPct: models.DefaultEPRTargets[category]}
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// SetTargets sets the targets of the listed categories
+func (s *EPRService) SetTargets(ctx context.Context, tenantID uuid.UUID, targets []models.EPRTarget) ([]models.EPRTarget, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("%w: at least one target is required", ErrEPRInvalid)
+	}
+	seen := map[string]bool{}
+	for i := range targets {
+		t := &targets[i]
+		t.Category = strings.ToUpper(strings.TrimSpace(t.Category))
+		if !models.IsValidEPRCategory(t.Category) {
+			return nil, fmt.Errorf("%w: targets[%d].category must be one of %s", ErrEPRInvalid, i, strings.Join(models.EPRCategories, ", "))
+		}
+		if seen[t.Category] {
+			return nil, fmt.Errorf("%w: %s is listed twice", ErrEPRInvalid, t.Category)
+		}
+		seen[t.Category] = true
+		if t.TargetPct < 0 || t.TargetPct > 100 {
+			return nil, fmt.Errorf("%w: targets[%d].target_pct must be between 0 and 100", ErrEPRInvalid, i)
+		}
+	}
+
+	if err := s.repo.UpsertEPRTargets(ctx, tenantID, targets); err != nil {
+		return nil, err
+	}
+	return s.Targets(ctx, tenantID)
+}
+
+// AnnualReturn builds the return for a financial year ("2025-26"); an empty
+// year is the current one
+func (s *EPRService) AnnualReturn(ctx context.Context, tenantID uuid.UUID, year string) (*models.EPRAnnualReturn, error) {
+	fy := models.FinancialYearOf(time.Now())
+	if year != "" {
+		var err error
+		if fy, err = models.ParseFinancialYear(year); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrEPRInvalid, err)
+		}
+	}
+
+	tenant, err := s.repo.GetTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := s.Targets(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	quantities, err := s.repo.ListEPRBatchQuantities(ctx, tenantID, fy.Start(), fy.End())
+	if err != nil {
+		return nil, err
+	}
+
+	targetPct := make(map[string]float64, len(targets))
+	for _, t := range targets {
+		targetPct[t.Category] = t.TargetPct
+	}
+	return models.BuildEPRReturn(fy, tenant, quantities, targetPct), nil
+}
+
+// tonnes converts kg to metric tonnes, the unit of the CPCB portal
+func tonnes(kg float64) float64 {
+	return float64(int64(kg+0.5)) / 1000
+}
+
+// eprReturnLineHeader is shared by the XLSX and PDF exports
+var eprReturnLineHeader = []string{
+	"Battery Category", "Chemistry", "Batches", "Quantity Placed (Nos)", "Weight Placed (MT)",
+	"Quantity Recycled (Nos)", "Weight Recycled (MT)", "Quantity Without Weight (Nos)",
+}
+
+var eprObligationHeader = []string{
+	"Battery Category", "Target (%)", "Weight Placed (MT)", "EPR Obligation (MT)",
+	"Recycled Credit (MT)", "Shortfall (MT)", "Fulfilled (%)",
+}
+
+// WriteReturnXLSX writes the return as a workbook: producer details, quantities
+// placed on the market by category and chemistry, and EPR obligations
+func (s *EPRService) WriteReturnXLSX(ret *models.EPRAnnualReturn, w io.Writer) error {
+	producer := [][]interface{}{
+		{"Field", "Value"},
+		{"Producer", ret.CompanyName},
+		{"EPR Registration Number", ret.EPRRegistrationNumber},
+		{"Financial Year", ret.FinancialYear},
+		{"Period", fmt.Sprintf("%s to %s", ret.PeriodStart.Format("02-01-2006"), ret.PeriodEnd.AddDate(0, 0, -1).Format("02-01-2006"))},
+		{"Total Quantity Placed (Nos)", ret.TotalUnitsPlaced},
+		{"Total Weight Placed (MT)", tonnes(ret.TotalWeightPlacedKg)},
+		{"Total Quantity Recycled (Nos)", ret.TotalUnitsRecycled},
+		{"Total Weight Recycled (MT)", tonnes(ret.TotalWeightRecycledKg)},
+		{"Generated At", ret.GeneratedAt.Format(time.RFC3339)},
+	}
+	for _, warning := range ret.Warnings {
+		producer = append(producer, []interface{}{"Warning", warning})
+	}
+
+	quantities := [][]interface{}{stringRow(eprReturnLineHeader)}
+	for _, l := range ret.Lines {
+		quantities = append(quantities, []interface{}{
+			l.Category, l.Chemistry, l.Batches, l.UnitsPlaced, tonnes(l.WeightPlacedKg),
+			l.UnitsRecycled, tonnes(l.WeightRecycledKg), l.UnitsWithoutWeight,
+		})
+	}
+
+	obligations := [][]interface{}{stringRow(eprObligationHeader)}
+	for _, o := range ret.Obligations {
+		obligations = append(obligations, []interface{}{
+			o.Category, o.TargetPct, tonnes(o.WeightPlacedKg), tonnes(o.ObligationKg),
+			tonnes(o.RecycledCreditKg), tonnes(o.ShortfallKg), o.FulfilledPct,
+		})
+	}
+
+	return writeXLSX(w, []xlsxSheet{
+		{Name: "Producer", Rows: producer},
+		{Name: "Quantities Placed", Rows: quantities},
+		{Name: "EPR Obligations", Rows: obligations},
+	})
+}
+
+// stringRow converts a header to a row
+func stringRow(header []string) []interface{} {
+	row := make([]interface{}, len(header))
+	for i, h := range header {
+		row[i] = h
+	}
+	return row
+}
+
+// WriteReturnPDF writes the return as a landscape A4 document with the same
+// tables as the workbook
+func (s *EPRService) WriteReturnPDF(ret *models.EPRAnnualReturn, w io.Writer) error {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(12, 12, 12)
+	pdf.SetAutoPageBreak(true, 12)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 16)
+	pdf.CellFormat(0, 9, "EPR Annual Return - Battery Waste Management Rules, 2022", "", 1, "L", false, 0, "")
+	pdf.SetFont("Arial", "", 10)
+	for _, line := range [][2]string{
+		{"Producer", ret.CompanyName},
+		{"EPR Registration Number", ret.EPRRegistrationNumber},
+		{"Financial Year", fmt.Sprintf("%s (%s to %s)", ret.FinancialYear,
+			ret.PeriodStart.Format("02-01-2006"), ret.PeriodEnd.AddDate(0, 0, -1).Format("02-01-2006"))},
+	} {
+		pdf.SetFont("Arial", "B", 10)
+		pdf.CellFormat(55, 6, line[0]+":", "", 0, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 10)
+		pdf.CellFormat(0, 6, tr(line[1]), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	table := func(title string, header []string, widths []float64, rows [][]string) {
+		pdf.SetFont("Arial", "B", 12)
+		pdf.CellFormat(0, 8, title, "", 1, "L", false, 0, "")
+		pdf.SetFont("Arial", "B", 8)
+		pdf.SetFillColor(230, 230, 230)
+		for i, h := range header {
+			pdf.CellFormat(widths[i], 7, h, "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Arial", "", 8)
+		if len(rows) == 0 {
+			pdf.CellFormat(0, 7, "Nothing to report for this year", "1", 1, "C", false, 0, "")
+		}
+		for _, row := range rows {
+			for i, cell := range row {
+				align := "R"
+				if i < 2 {
+					align = "L"
+				}
+				pdf.CellFormat(widths[i], 6, tr(cell), "1", 0, align, false, 0, "")
+			}
+			pdf.Ln(-1)
+		}
+		pdf.Ln(4)
+	}
+
+	var lines [][]string
+	for _, l := range ret.Lines {
+		lines = append(lines, []string{
+			l.Category, l.Chemistry, fmt.Sprint(l.Batches), fmt.Sprint(l.UnitsPlaced), fmt.Sprintf("%.3f", tonnes(l.WeightPlacedKg)),
+			fmt.Sprint(l.UnitsRecycled), fmt.Sprintf("%.3f", tonnes(l.WeightRecycledKg)), fmt.Sprint(l.UnitsWithoutWeight),
+		})
+	}
+	lines = append(lines, []string{
+		"Total", "", "", fmt.Sprint(ret.TotalUnitsPlaced), fmt.Sprintf("%.3f", tonnes(ret.TotalWeightPlacedKg)),
+		fmt.Sprint(ret.TotalUnitsRecycled), fmt.Sprintf("%.3f", tonnes(ret.TotalWeightRecycledKg)), "",
+	})
+	table("Quantities placed on the market", eprReturnLineHeader,
+		[]float64{32, 40, 18, 34, 30, 36, 32, 51}, lines)
+
+	var obligations [][]string
+	for _, o := range ret.Obligations {
+		obligations = append(obligations, []string{
+			o.Category, fmt.Sprintf("%.1f", o.TargetPct), fmt.Sprintf("%.3f", tonnes(o.WeightPlacedKg)),
+			fmt.Sprintf("%.3f", tonnes(o.ObligationKg)), fmt.Sprintf("%.3f", tonnes(o.RecycledCreditKg)),
+			fmt.Sprintf("%.3f", tonnes(o.ShortfallKg)), fmt.Sprintf("%.1f", o.FulfilledPct),
+		})
+	}
+	table("EPR obligations", eprObligationHeader, []float64{40, 30, 38, 40, 40, 40, 45}, obligations)
+
+	if len(ret.Warnings) > 0 {
+		pdf.SetFont("Arial", "B", 10)
+		pdf.CellFormat(0, 7, "Notes", "", 1, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 9)
+		for _, warning := range ret.Warnings {
+			pdf.MultiCell(0, 5, tr("- "+warning), "", "L", false)
+		}
+	}
+
+	pdf.SetY(-20)
+	pdf.SetFont("Arial", "I", 8)
+	pdf.CellFormat(0, 5, "Generated "+ret.GeneratedAt.Format("02 Jan 2006 15:04 MST")+
+		". Recycled quantities are credited in the year their RECYCLED event was recorded.", "", 1, "L", false, 0, "")
+
+	if err := pdf.Output(w); err != nil {
+		return fmt.Errorf("failed to generate PDF: %w", err)
+	}
+	return nil
+}
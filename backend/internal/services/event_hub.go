@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"exportready-battery/internal/db"
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+)
+
+// ============================================================================
+// EVENT HUB (Live tenant events over Postgres LISTEN/NOTIFY)
+// ============================================================================
+
+const (
+	eventSubscriberBuffer = 64               // Events queued per subscriber before it is dropped
+	eventRetention        = 24 * time.Hour   // Replay window for Last-Event-ID
+	eventCleanupInterval  = time.Hour        // How often old events are pruned
+	listenRetryMax        = 30 * time.Second // Longest wait between listener reconnects
+)
+
+// EventHub fans tenant events out to this instance's stream subscribers.
+// Events are stored and announced in Postgres (repository.PublishTenantEvent),
+// so a write on any instance reaches subscribers on every instance.
+type EventHub struct {
+	repo      *repository.Repository
+	listenURL string
+
+	mu     sync.Mutex
+	subs   map[uuid.UUID]map[*EventSubscription]struct{}
+	closed bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// EventSubscription receives one tenant's events. Its channel is closed when the
+// hub drops it (too slow, listener reconnected, shutdown); clients then reconnect
+// with Last-Event-ID and replay what they missed from the database.
+type EventSubscription struct {
+	hub      *EventHub
+	tenantID uuid.UUID
+	events   chan *models.TenantEvent
+}
+
+// NewEventHub creates a hub and starts its listener and cleanup loops
+func NewEventHub(repo *repository.Repository, listenURL string) *EventHub {
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &EventHub{
+		repo:      repo,
+		listenURL: listenURL,
+		subs:      make(map[uuid.UUID]map[*EventSubscription]struct{}),
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	go h.run(ctx)
+	go h.cleanup(ctx)
+	return h
+}
+
+// Subscribe registers for a tenant's live events
+func (h *EventHub) Subscribe(tenantID uuid.UUID) *EventSubscription {
+	sub := &EventSubscription{hub: h, tenantID: tenantID, events: make(chan *models.TenantEvent, eventSubscriberBuffer)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(sub.events)
+		return sub
+	}
+	if h.subs[tenantID] == nil {
+		h.subs[tenantID] = make(map[*EventSubscription]struct{})
+	}
+	h.subs[tenantID][sub] = struct{}{}
+	return sub
+}
+
+// Events returns the subscription's event channel
+func (s *EventSubscription) Events() <-chan *models.TenantEvent {
+	return s.events
+}
+
+// Close unsubscribes. Safe to call after the hub dropped the subscription.
+func (s *EventSubscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.dropLocked(s)
+}
+
+// Close stops the listener and ends every subscription so open streams return
+func (h *EventHub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	for _, subs := range h.subs {
+		for sub := range subs {
+			h.dropLocked(sub)
+		}
+	}
+	h.mu.Unlock()
+
+	h.cancel()
+	<-h.done
+}
+
+// dropLocked removes a subscription and closes its channel; h.mu must be held
+func (h *EventHub) dropLocked(sub *EventSubscription) {
+	subs := h.subs[sub.tenantID]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subs, sub.tenantID)
+	}
+	close(sub.events)
+}
+
+// dropAll ends every subscription, e.g. after notifications may have been missed
+func (h *EventHub) dropAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, subs := range h.subs {
+		for sub := range subs {
+			h.dropLocked(sub)
+		}
+	}
+}
+
+func (h *EventHub) hasSubscribers(tenantID uuid.UUID) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs[tenantID]) > 0
+}
+
+// deliver queues an event for the tenant's subscribers, dropping any that are full
+func (h *EventHub) deliver(event *models.TenantEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs[event.TenantID] {
+		select {
+		case sub.events <- event:
+		default:
+			log.Printf("⚠️ Event stream subscriber for tenant %s is too slow, dropping it", event.TenantID)
+			h.dropLocked(sub)
+		}
+	}
+}
+
+// run keeps a LISTEN connection open, reconnecting with backoff
+func (h *EventHub) run(ctx context.Context) {
+	defer close(h.done)
+
+	retry := time.Second
+	for {
+		listening, err := h.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if listening {
+			retry = time.Second
+		}
+		log.Printf("⚠️ Event listener disconnected: %v (retrying in %s)", err, retry)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		retry = min(retry*2, listenRetryMax)
+	}
+}
+
+// listen subscribes to the event channel and dispatches notifications until the
+// connection fails. It reports whether LISTEN was established.
+func (h *EventHub) listen(ctx context.Context) (bool, error) {
+	conn, err := db.ConnectListener(ctx, h.listenURL)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+repository.EventChannel); err != nil {
+		return false, err
+	}
+
+	// Subscribers may have missed events while no connection was listening
+	h.dropAll()
+	log.Println("✅ Event listener connected")
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+		h.dispatch(ctx, notification.Payload)
+	}
+}
+
+// dispatch delivers one notification to local subscribers of its tenant
+func (h *EventHub) dispatch(ctx context.Context, payload string) {
+	var n repository.EventNotification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		log.Printf("Invalid event notification: %v", err)
+		return
+	}
+	if !h.hasSubscribers(n.TenantID) {
+		return
+	}
+
+	event := n.Event()
+	if event == nil {
+		loadCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		var err error
+		if event, err = h.repo.GetTenantEvent(loadCtx, n.ID); err != nil {
+			log.Printf("Failed to load event %d: %v", n.ID, err)
+			return
+		}
+	}
+	h.deliver(event)
+}
+
+// cleanup prunes events older than the replay window
+func (h *EventHub) cleanup(ctx context.Context) {
+	ticker := time.NewTicker(eventCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := h.repo.CleanupOldTenantEvents(ctx, eventRetention); err != nil {
+				log.Printf("Failed to clean up tenant events: %v", err)
+			}
+		}
+	}
+}
+
+// ReplayEvents returns the tenant's events after lastID, up to limit
+func (h *EventHub) ReplayEvents(ctx context.Context, tenantID uuid.UUID, lastID int64, limit int) ([]*models.TenantEvent, error) {
+	return h.repo.ListTenantEventsAfter(ctx, tenantID, lastID, limit)
+}
@@ -76,20 +76,28 @@ func (s *LifecycleService) TransitionPassport(ctx context.Context, req Transitio
 		}
 	}
 
-	// Set appropriate timestamp based on target status
+	// Set appropriate timestamp based on target status. The first shipment and
+	// installation are kept: the warranty runs from them, so a cancelled return
+	// (RETURN_REQUESTED → IN_SERVICE) must not restart it. Nor is a cancelled
+	// return an installation: a battery returned straight from SHIPPED (e.g. a
+	// rejected dead-on-arrival claim) keeps no installation date.
 	now := time.Now()
 	switch req.ToStatus {
 	case models.PassportStatusShipped:
-		passport.ShippedAt = &now
+		if passport.ShippedAt == nil {
+			passport.ShippedAt = &now
+		}
 	case models.PassportStatusInService:
-		passport.InstalledAt = &now
+		if passport.InstalledAt == nil && previousStatus != models.PassportStatusReturnRequested {
+			passport.InstalledAt = &now
+		}
 	case models.PassportStatusReturned:
 		passport.ReturnedAt = &now
 	}
 
-	// Update passport status
+	// Update passport status (and the lifecycle timestamp set above)
 	passport.Status = req.ToStatus
-	if err := s.repo.UpdatePassportStatus(ctx, passport.UUID, req.ToStatus); err != nil {
+	if err := s.repo.UpdatePassportTransition(ctx, passport); err != nil {
 		return &TransitionResult{
 			Success:        false,
 			PreviousStatus: previousStatus,
@@ -120,6 +128,17 @@ func (s *LifecycleService) TransitionPassport(ctx context.Context, req Transitio
 		fmt.Printf("Warning: Failed to log passport event: %v\n", err)
 	}
 
+	// Push to the tenant's live event stream (non-critical)
+	if err := s.repo.PublishPassportEvent(ctx, passport.UUID, models.EventPassportTransitioned, models.PassportTransitionedData{
+		PassportID:     passport.UUID,
+		EventID:        event.ID,
+		PreviousStatus: previousStatus,
+		NewStatus:      req.ToStatus,
+		Actor:          req.Actor,
+	}); err != nil {
+		fmt.Printf("Warning: Failed to publish transition event: %v\n", err)
+	}
+
 	return &TransitionResult{
 		Success:        true,
 		Passport:       passport,
@@ -139,6 +158,7 @@ type BulkTransitionRequest struct {
 	PassportIDs []uuid.UUID            `json:"passport_ids"`
 	ToStatus    string                 `json:"to_status"`
 	Actor       string                 `json:"actor"`
+	ActorRole   string                 `json:"actor_role,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 }
 
@@ -164,6 +184,7 @@ func (s *LifecycleService) BulkTransitionPassports(ctx context.Context, req Bulk
 			PassportID: passportID,
 			ToStatus:   req.ToStatus,
 			Actor:      req.Actor,
+			ActorRole:  req.ActorRole,
 			Metadata:   req.Metadata,
 		}
 
@@ -184,21 +205,16 @@ func (s *LifecycleService) BulkTransitionPassports(ctx context.Context, req Bulk
 	return result, nil
 }
 
-// CalculateWarrantyRemaining calculates remaining warranty based on shipped date
+// CalculateWarrantyRemaining returns the whole calendar months of warranty left
+// and whether the warranty is still active (see models.ComputeWarranty). A
+// battery not yet shipped has its full warranty ahead of it.
 func (s *LifecycleService) CalculateWarrantyRemaining(passport *models.Passport, warrantyMonths int) (int, bool) {
-	if passport.ShippedAt == nil {
+	if passport.ShippedAt == nil && passport.InstalledAt == nil {
 		return warrantyMonths, true // Not shipped yet, full warranty
 	}
 
-	elapsed := time.Since(*passport.ShippedAt)
-	elapsedMonths := int(elapsed.Hours() / (24 * 30)) // Approximate months
-
-	remaining := warrantyMonths - elapsedMonths
-	if remaining < 0 {
-		return 0, false // Warranty expired
-	}
-
-	return remaining, true
+	coverage := models.ComputeWarranty(passport, warrantyMonths, time.Now())
+	return coverage.RemainingMonths, coverage.Active
 }
 
 // getEventTypeForStatus maps status to appropriate event type
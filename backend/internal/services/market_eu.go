@@ -0,0 +1,130 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"exportready-battery/internal/models"
+)
+
+// ============================================================================
+// EU RULE PACK (BATTERY REGULATION 2023/1542)
+// ============================================================================
+
+var euRulePack = &MarketRulePack{
+	Market:         models.MarketRegionEU,
+	Name:           "EU Battery Regulation 2023/1542",
+	Certifications: []string{"CE"},
+	Documents: []string{
+		"EU declaration of conformity", "Carbon footprint declaration",
+		"Supply chain due diligence report", "Battery passport (LMT, EV, industrial over 2 kWh)",
+	},
+	Rules: []MarketRule{
+		hsnRule(""),
+		iecRule(nil, ""),
+		certificationRule("ce_marking", "CE marking", models.SpecIssueWarning,
+			"Add CE to the batch certifications", "CE"),
+		{
+			Key: "battery_category", Label: "Battery category", Kind: models.MarketRequirementField,
+			Severity: models.ComplianceBlocking, OnCreate: models.SpecIssueWarning,
+			Check: func(c *marketContext) (bool, string) {
+				return c.batch.Specs.BatteryCategory != "",
+					"Classify the battery in specs.battery_category (" + strings.Join(models.BatteryCategories, ", ") +
+						"); its passport, labelling and carbon footprint obligations depend on it"
+			},
+		},
+		{
+			Key: "eu_representative", Label: "EU authorised representative", Kind: models.MarketRequirementField,
+			Severity: models.ComplianceBlocking,
+			Check: func(c *marketContext) (bool, string) {
+				return strings.TrimSpace(c.batch.Specs.EURepresentative) != "",
+					"Name the EU authorised representative in specs.eu_representative"
+			},
+		},
+		{
+			Key: "carbon_footprint", Label: "Carbon footprint declaration", Kind: models.MarketRequirementDocument,
+			Severity: models.ComplianceBlocking, OnCreate: models.SpecIssueError, Applies: carbonFootprintRequired,
+			Check: func(c *marketContext) (bool, string) {
+				message := "Declare or calculate the carbon footprint (kg CO2e/kWh)"
+				if r := categoryRules(c.batch); r != nil {
+					message = fmt.Sprintf("The carbon footprint is required from %s (%s); declare or calculate it (kg CO2e/kWh)",
+						r.CarbonFootprintFrom, r.Name)
+				}
+				return strings.TrimSpace(c.batch.Specs.CarbonFootprint) != "", message
+			},
+		},
+		{
+			Key: "carbon_footprint_calculated", Label: "Carbon footprint calculation", Kind: models.MarketRequirementDocument,
+			Severity: models.ComplianceAdvisory, Applies: carbonFootprintRequired,
+			Check: func(c *marketContext) (bool, string) {
+				return c.batch.Specs.CarbonFootprintDetail != nil,
+					"The declared footprint is not backed by a lifecycle calculation"
+			},
+		},
+		{
+			Key: "material_composition", Label: "Material composition", Kind: models.MarketRequirementField,
+			Severity: models.ComplianceBlocking,
+			Check: func(c *marketContext) (bool, string) {
+				mc := c.batch.Specs.MaterialComposition
+				return mc != nil && *mc != (models.MaterialComposition{}),
+					"Declare the critical raw material shares in specs.material_composition"
+			},
+		},
+		hazardousSubstancesRule(),
+		{
+			// Passport content (Annex XIII) the other rules do not cover
+			Key: "battery_passport", Label: "Battery passport data", Kind: models.MarketRequirementDocument,
+			Severity: models.ComplianceBlocking,
+			Applies: func(b *models.Batch) bool {
+				r := categoryRules(b)
+				return r != nil && r.PassportRequired(b.Specs.Ratings)
+			},
+			Check: func(c *marketContext) (bool, string) {
+				s := c.batch.Specs
+				var missing []string
+				if s.ExpectedLifetimeCycles <= 0 {
+					missing = append(missing, "expected lifetime")
+				}
+				if s.RecycledContent == nil {
+					missing = append(missing, "recycled content")
+				}
+				if s.DueDiligence == nil || len(s.DueDiligence.Suppliers) == 0 {
+					missing = append(missing, "supply chain due diligence")
+				}
+				if len(missing) == 0 {
+					return true, ""
+				}
+				r := categoryRules(c.batch)
+				return false, fmt.Sprintf("A battery passport is required from %s (%s); add its %s",
+					r.PassportFrom, r.Name, strings.Join(missing, ", "))
+			},
+		},
+		{
+			Key: "eu_labelling", Label: "Label information", Kind: models.MarketRequirementField,
+			Severity: models.ComplianceAdvisory,
+			Check: func(c *marketContext) (bool, string) {
+				s := c.batch.Specs
+				var missing []string
+				for _, f := range []struct{ name, value string }{
+					{"manufacturer", s.Manufacturer}, {"chemistry", s.Chemistry}, {"capacity", s.Capacity},
+					{"weight", s.Weight}, {"battery category", s.BatteryCategory},
+				} {
+					if strings.TrimSpace(f.value) == "" {
+						missing = append(missing, f.name)
+					}
+				}
+				return len(missing) == 0,
+					"The battery label (from 2026-08-18) needs the " + strings.Join(missing, ", ")
+			},
+		},
+		{
+			Key: "due_diligence", Label: "Supply chain due diligence", Kind: models.MarketRequirementDocument,
+			Severity: models.ComplianceAdvisory,
+			Check: func(c *marketContext) (bool, string) {
+				dd := c.batch.Specs.DueDiligence
+				return dd != nil && len(dd.Suppliers) > 0,
+					"List the mines, smelters and refiners of the batch's raw materials"
+			},
+		},
+	},
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"exportready-battery/internal/models"
+)
+
+// ============================================================================
+// INDIA RULE PACK (BWM RULES 2022, BIS CRS, PLI)
+// ============================================================================
+
+var indiaRulePack = &MarketRulePack{
+	Market:         models.MarketRegionIndia,
+	Name:           "India: Battery Waste Management Rules 2022, BIS CRS (IS 16046) and PLI",
+	Certifications: []string{"BIS (IS 16046)"},
+	Documents: []string{
+		"EPR certificate (CPCB)", "BIS registration certificate",
+		"Bill of Entry (imported cells)", "CA certificate of domestic value addition (PLI)",
+	},
+	Prepare: prepareIndiaBatch,
+	Rules: []MarketRule{
+		hsnRule(models.SpecIssueError),
+		{
+			Key: "customs_declaration", Label: "Customs declaration (Bill of Entry)", Kind: models.MarketRequirementDocument,
+			Severity: models.ComplianceBlocking, OnCreate: models.SpecIssueError, Applies: isImported,
+			Check: func(c *marketContext) (bool, string) {
+				return c.batch.BillOfEntryNo != "" && c.batch.CountryOfOrigin != "",
+					"Imported batches must include Bill of Entry and Country of Origin per Customs regulations"
+			},
+		},
+		iecRule(isImported, models.SpecIssueError),
+		{
+			Key: "domestic_value_add", Label: "Domestic value addition", Kind: models.MarketRequirementField,
+			Severity: models.ComplianceAdvisory, OnCreate: models.SpecIssueError,
+			Applies: func(b *models.Batch) bool { return b.CellSource == "DOMESTIC" },
+			Check: func(c *marketContext) (bool, string) {
+				return c.batch.DomesticValueAdd > 0,
+					"Domestic batches must have Domestic Value Add > 0%. Please enter Sale Price and Import Cost."
+			},
+		},
+		{
+			Key: "bis_r_number", Label: "BIS R-number", Kind: models.MarketRequirementCertification,
+			Severity: models.ComplianceBlocking,
+			Check: func(c *marketContext) (bool, string) {
+				if c.tenant.BISRNumber == "" {
+					return false, "Add the BIS R-number (IS 16046) to the company profile"
+				}
+				bis := c.validation.ValidateBISRNumber(c.tenant.BISRNumber)
+				return bis.Valid, bis.Message
+			},
+		},
+		{
+			Key: "epr_certificate", Label: "EPR certificate (verified)", Kind: models.MarketRequirementDocument,
+			Severity: models.ComplianceBlocking,
+			Check: func(c *marketContext) (bool, string) {
+				t := c.tenant
+				switch {
+				case t.EPRRegistrationNumber == "":
+					return false, "Add the CPCB EPR registration number to the company profile"
+				case t.EPRStatus == "VERIFIED":
+					return true, ""
+				case t.EPRStatus == "PENDING":
+					return false, "EPR certificate is awaiting verification"
+				case t.EPRStatus == "REJECTED":
+					return false, "EPR certificate was rejected; upload a valid certificate"
+				}
+				return false, "Upload the EPR certificate for verification"
+			},
+		},
+		{
+			// Only batches claiming the PLI subsidy need a CA audit
+			Key: "pli_audit", Label: "PLI DVA audit", Kind: models.MarketRequirementDocument,
+			Severity: models.ComplianceAdvisory,
+			Applies:  func(b *models.Batch) bool { return b.PLICompliant },
+			Check: func(c *marketContext) (bool, string) {
+				b := c.batch
+				switch {
+				case b.DVASource != models.DVASourceAudited || b.AuditedDomesticValueAdd == nil:
+					return false, "PLI claim is based on an estimated DVA; request a CA attestation of the DVA"
+				case *b.AuditedDomesticValueAdd < models.PLIMinimumDVA:
+					return false, fmt.Sprintf("Audited DVA is %.1f%%; PLI requires at least 50%%", *b.AuditedDomesticValueAdd)
+				case c.tenant.PLIStatus != "VERIFIED":
+					return false, "PLI certificate has not been verified"
+				}
+				return true, ""
+			},
+		},
+	},
+}
+
+// prepareIndiaBatch validates cell_source and derives the ESTIMATED DVA of a
+// new India batch server-side. Never trust a client-provided
+// domestic_value_add: an AUDITED DVA is only ever set by a CA attestation (PLI audit).
+func prepareIndiaBatch(v *ValidationService, req *models.CreateBatchRequest) error {
+	if req.CellSource != "" && req.CellSource != "IMPORTED" && req.CellSource != "DOMESTIC" {
+		return errors.New("cell_source must be IMPORTED or DOMESTIC")
+	}
+
+	// ===== SECURITY: FORCE OVERRIDES FOR IMPORTED CELLS =====
+	// Prevent API manipulation of PLI flags for imported battery cells
+	if req.CellSource == "IMPORTED" && req.PLICompliant {
+		log.Printf("⚠️ SECURITY OVERRIDE: Forced PLICompliant=false for IMPORTED cells (TenantID=%s, BatchName=%s)",
+			req.TenantID, req.BatchName)
+		req.PLICompliant = false
+	}
+
+	var calculatedDVA float64
+	if req.Specs.SalePriceINR > 0 {
+		calculatedDVA = ((req.Specs.SalePriceINR - req.Specs.ImportCostINR) / req.Specs.SalePriceINR) * 100
+		if calculatedDVA < 0 {
+			calculatedDVA = 0
+		}
+	}
+	req.DomesticValueAdd = calculatedDVA
+
+	// A new batch has no attested DVA yet
+	return v.ValidatePLICompliance(nil, req.DomesticValueAdd, req.PLICompliant)
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"strings"
+
+	"exportready-battery/internal/models"
+)
+
+// ============================================================================
+// MARKET RULE PACKS
+// ============================================================================
+
+// MarketRule is one requirement of a market: a field, a certification or a
+// document. The same rule drives the compliance checklist and, through
+// OnCreate, batch creation.
+type MarketRule struct {
+	Key      string // Checklist key, e.g. "ce_marking"; packs sharing a key share the check
+	Label    string
+	Kind     models.MarketRequirementKind
+	Severity models.ComplianceSeverity // In the compliance checklist
+
+	// OnCreate is checked when a batch is created: SpecIssueError refuses it
+	// (a legacy GLOBAL batch is only warned), SpecIssueWarning warns, empty
+	// leaves the rule to the checklist
+	OnCreate string
+
+	// Applies limits the rule to some batches, e.g. imported cells; nil applies to all
+	Applies func(b *models.Batch) bool
+
+	// Check reports whether the batch passes and, if not, what is missing
+	Check func(c *marketContext) (bool, string)
+}
+
+// marketContext is what market rules check: a stored batch, or one being
+// created, and its tenant
+type marketContext struct {
+	batch      *models.Batch
+	tenant     *models.Tenant
+	validation *ValidationService
+}
+
+// MarketRulePack is the rule set of one target market: its required fields,
+// certifications and documents
+type MarketRulePack struct {
+	Market         models.MarketRegion
+	Name           string
+	Certifications []string // Marks accepted in specs.certifications
+	Documents      []string // Document types expected for the market
+	Rules          []MarketRule
+
+	// Prepare normalises the market's own request fields before its rules run
+	// (India: server-side DVA, PLI overrides). Its error refuses the batch.
+	Prepare func(v *ValidationService, req *models.CreateBatchRequest) error
+}
+
+// marketRulePacks holds the rule pack of each market. A new market needs a
+// MarketRegion constant and its pack here.
+var marketRulePacks = map[models.MarketRegion]*MarketRulePack{
+	models.MarketRegionIndia: indiaRulePack,
+	models.MarketRegionEU:    euRulePack,
+	models.MarketRegionUS:    usRulePack,
+	models.MarketRegionUK:    ukRulePack,
+}
+
+// MarketRulePacks describes the rule pack of every market, in display order
+func MarketRulePacks() []models.MarketRulePackInfo {
+	var infos []models.MarketRulePackInfo
+	for _, market := range models.Markets {
+		pack, ok := marketRulePacks[market]
+		if !ok {
+			continue
+		}
+		info := models.MarketRulePackInfo{
+			Market:         pack.Market,
+			Name:           pack.Name,
+			Certifications: pack.Certifications,
+			Documents:      pack.Documents,
+		}
+		for _, rule := range pack.Rules {
+			info.Requirements = append(info.Requirements, models.MarketRequirement{
+				Key:      rule.Key,
+				Label:    rule.Label,
+				Kind:     rule.Kind,
+				Severity: rule.Severity,
+				OnCreate: rule.OnCreate,
+			})
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// marketRuleRef is a rule to check and the market it is reported under
+type marketRuleRef struct {
+	rule     *MarketRule
+	market   models.MarketRegion
+	onCreate string // Strictest OnCreate among the packs sharing the rule
+}
+
+// applicableMarketRules lists the rules of the markets' packs that apply to the
+// batch, in market order. A rule several packs share (same key) is listed once,
+// under the report market (GLOBAL).
+func applicableMarketRules(b *models.Batch, markets []models.MarketRegion) []marketRuleRef {
+	report := models.MarketsRegion(markets)
+	var refs []marketRuleRef
+	seen := map[string]int{}
+	for _, market := range markets {
+		pack, ok := marketRulePacks[market]
+		if !ok {
+			continue
+		}
+		for i := range pack.Rules {
+			rule := &pack.Rules[i]
+			if rule.Applies != nil && !rule.Applies(b) {
+				continue
+			}
+			if j, ok := seen[rule.Key]; ok {
+				refs[j].market = report
+				if onCreateRank(rule.OnCreate) > onCreateRank(refs[j].onCreate) {
+					refs[j].onCreate = rule.OnCreate
+				}
+				continue
+			}
+			seen[rule.Key] = len(refs)
+			refs = append(refs, marketRuleRef{rule: rule, market: market, onCreate: rule.OnCreate})
+		}
+	}
+	return refs
+}
+
+// onCreateRank orders OnCreate values from lenient to strict
+func onCreateRank(onCreate string) int {
+	switch onCreate {
+	case models.SpecIssueError:
+		return 2
+	case models.SpecIssueWarning:
+		return 1
+	}
+	return 0
+}
+
+// check runs a rule and returns its checklist entry
+func (ref marketRuleRef) check(c *marketContext) models.ComplianceCheck {
+	passed, message := ref.rule.Check(c)
+	check := models.ComplianceCheck{
+		Key:      ref.rule.Key,
+		Label:    ref.rule.Label,
+		Market:   ref.market,
+		Severity: ref.rule.Severity,
+		Passed:   passed,
+	}
+	if !passed {
+		check.Message = message
+	}
+	return check
+}
+
+// MarketValidation is the outcome of the market rules for a new batch
+type MarketValidation struct {
+	Errors   []models.ComplianceCheck // Refuse the batch
+	Warnings []models.ComplianceCheck // Returned with the created batch
+}
+
+// PrepareMarkets runs the Prepare of each pack of req.Markets (already
+// resolved); India derives the DVA server-side and checks the PLI claim. A
+// batch not for India has no PLI eligibility or DVA, whatever the client sent.
+func (v *ValidationService) PrepareMarkets(req *models.CreateBatchRequest) error {
+	targetsIndia := false
+	for _, market := range req.Markets {
+		targetsIndia = targetsIndia || market == models.MarketRegionIndia
+		if pack, ok := marketRulePacks[market]; ok && pack.Prepare != nil {
+			if err := pack.Prepare(v, req); err != nil {
+				return err
+			}
+		}
+	}
+	if !targetsIndia {
+		req.PLICompliant, req.DomesticValueAdd = false, 0
+	}
+	return nil
+}
+
+// ValidateNewBatch runs the rule packs of req.Markets (already resolved) on a
+// new batch. PrepareMarkets normalises the request first; its error refuses
+// the batch. Rules failing with OnCreate ERROR refuse the batch for every
+// market it lists. Only a legacyGlobal batch (market_region GLOBAL without a
+// markets list) is warned instead, whose gaps the checklist and the activation
+// gate track.
+func (v *ValidationService) ValidateNewBatch(req *models.CreateBatchRequest, tenant *models.Tenant, legacyGlobal bool) (*MarketValidation, error) {
+	if err := v.PrepareMarkets(req); err != nil {
+		return nil, err
+	}
+
+	batch := &models.Batch{
+		TenantID:         req.TenantID,
+		BatchName:        req.BatchName,
+		Specs:            req.Specs,
+		MarketRegion:     req.MarketRegion,
+		Markets:          req.Markets,
+		PLICompliant:     req.PLICompliant,
+		DomesticValueAdd: req.DomesticValueAdd,
+		CellSource:       req.CellSource,
+		BillOfEntryNo:    req.BillOfEntryNo,
+		CountryOfOrigin:  req.CountryOfOrigin,
+		HSNCode:          req.HSNCode,
+		DVASource:        models.DVASourceEstimated,
+	}
+	c := &marketContext{batch: batch, tenant: tenant, validation: v}
+
+	result := &MarketValidation{}
+	for _, ref := range applicableMarketRules(batch, req.Markets) {
+		if ref.onCreate == "" {
+			continue
+		}
+		check := ref.check(c)
+		switch {
+		case check.Passed:
+		case ref.onCreate == models.SpecIssueError && !legacyGlobal:
+			result.Errors = append(result.Errors, check)
+		default:
+			result.Warnings = append(result.Warnings, check)
+		}
+	}
+	return result, nil
+}
+
+// ============================================================================
+// RULES SHARED BY SEVERAL PACKS
+// ============================================================================
+
+// hsnRule requires a valid battery HSN code, for customs in both directions
+func hsnRule(onCreate string) MarketRule {
+	return MarketRule{
+		Key: "hsn_code", Label: "HSN code", Kind: models.MarketRequirementField,
+		Severity: models.ComplianceBlocking, OnCreate: onCreate,
+		Check: func(c *marketContext) (bool, string) {
+			hsn := c.validation.ValidateHSNCode(c.batch.HSNCode)
+			return hsn.Valid, hsn.Message
+		},
+	}
+}
+
+// iecRule requires the tenant's Import Export Code, needed to export batteries
+// or import cells
+func iecRule(applies func(b *models.Batch) bool, onCreate string) MarketRule {
+	return MarketRule{
+		Key: "iec_code", Label: "Import Export Code (IEC)", Kind: models.MarketRequirementField,
+		Severity: models.ComplianceBlocking, OnCreate: onCreate, Applies: applies,
+		Check: func(c *marketContext) (bool, string) {
+			if c.tenant.IECCode == "" {
+				return false, "IEC code is required to export batteries or import cells; add it to the company profile"
+			}
+			iec := c.validation.ValidateIECCode(c.tenant.IECCode)
+			return iec.Valid, iec.Message
+		},
+	}
+}
+
+// hazardousSubstancesRule asks for the lead, mercury and cadmium declaration
+func hazardousSubstancesRule() MarketRule {
+	return MarketRule{
+		Key: "hazardous_substances", Label: "Hazardous substances declaration", Kind: models.MarketRequirementField,
+		Severity: models.ComplianceAdvisory,
+		Check: func(c *marketContext) (bool, string) {
+			return c.batch.Specs.HazardousSubstances != nil,
+				"Declare lead, mercury and cadmium presence in specs.hazardous_substances"
+		},
+	}
+}
+
+// un383Rule asks for the UN 38.3 test summary of a lithium battery
+func un383Rule(severity models.ComplianceSeverity) MarketRule {
+	return MarketRule{
+		Key: "un38_3_test_summary", Label: "UN 38.3 test summary", Kind: models.MarketRequirementDocument,
+		Severity: severity,
+		Check: func(c *marketContext) (bool, string) {
+			return strings.TrimSpace(c.batch.Specs.UN383TestSummary) != "",
+				"Reference the UN 38.3 test summary in specs.un38_3_test_summary"
+		},
+	}
+}
+
+// certificationRule requires one of the marks in specs.certifications
+func certificationRule(key, label string, onCreate, message string, marks ...string) MarketRule {
+	return MarketRule{
+		Key: key, Label: label, Kind: models.MarketRequirementCertification,
+		Severity: models.ComplianceBlocking, OnCreate: onCreate,
+		Check: func(c *marketContext) (bool, string) {
+			for _, mark := range marks {
+				if hasCertification(c.batch.Specs.Certifications, mark) {
+					return true, ""
+				}
+			}
+			return false, message
+		},
+	}
+}
+
+// categoryRules returns the obligations of the batch's battery category, or nil
+// if it has none
+func categoryRules(b *models.Batch) *models.BatteryCategoryRules {
+	return models.BatteryCategoryRulesFor(b.Specs.BatteryCategory)
+}
+
+// carbonFootprintRequired reports whether the batch must declare its carbon
+// footprint: by its category and energy, and always without a category
+func carbonFootprintRequired(b *models.Batch) bool {
+	r := categoryRules(b)
+	return r == nil || r.CarbonFootprintRequired(b.Specs.Ratings)
+}
+
+// isImported reports whether the batch uses imported cells
+func isImported(b *models.Batch) bool {
+	return b.CellSource == "IMPORTED"
+}
+
+// hasCertification reports whether certs lists name, ignoring case, spaces
+// and hyphens (UL 2054 = UL-2054 = ul2054)
+func hasCertification(certs []string, name string) bool {
+	want := certificationKey(name)
+	for _, c := range certs {
+		if certificationKey(c) == want {
+			return true
+		}
+	}
+	return false
+}
+
+// certificationKey normalises a certification mark for comparison
+func certificationKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '.' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(s)))
+}
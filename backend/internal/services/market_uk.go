@@ -0,0 +1,43 @@
+package services
+
+import (
+	"strings"
+
+	"exportready-battery/internal/models"
+)
+
+// ============================================================================
+// UK RULE PACK (UKCA, WASTE BATTERIES REGULATIONS 2009)
+// ============================================================================
+
+var ukRulePack = &MarketRulePack{
+	Market:         models.MarketRegionUK,
+	Name:           "UK: UKCA marking and Waste Batteries and Accumulators Regulations 2009",
+	Certifications: []string{"UKCA", "CE"},
+	Documents:      []string{"UK declaration of conformity", "UN 38.3 test summary"},
+	Rules: []MarketRule{
+		hsnRule(""),
+		iecRule(nil, ""),
+		// Great Britain keeps recognising the CE mark alongside UKCA
+		certificationRule("ukca_marking", "UKCA marking", models.SpecIssueWarning,
+			"Add UKCA (or CE, recognised in Great Britain) to the batch certifications", "UKCA", "CE"),
+		{
+			Key: "uk_responsible_person", Label: "UK importer or authorised representative", Kind: models.MarketRequirementField,
+			Severity: models.ComplianceBlocking,
+			Check: func(c *marketContext) (bool, string) {
+				return strings.TrimSpace(c.batch.Specs.UKResponsiblePerson) != "",
+					"Name the UK importer or authorised representative in specs.uk_responsible_person"
+			},
+		},
+		{
+			Key: "uk_producer_number", Label: "UK battery producer registration", Kind: models.MarketRequirementField,
+			Severity: models.ComplianceAdvisory,
+			Check: func(c *marketContext) (bool, string) {
+				return strings.TrimSpace(c.batch.Specs.UKProducerNumber) != "",
+					"Add the producer registration number under the Waste Batteries Regulations in specs.uk_producer_number"
+			},
+		},
+		hazardousSubstancesRule(),
+		un383Rule(models.ComplianceAdvisory),
+	},
+}
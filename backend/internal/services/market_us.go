@@ -0,0 +1,80 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"exportready-battery/internal/models"
+)
+
+// ============================================================================
+// US RULE PACK (UL LISTING, DOT TRANSPORT, IRA SOURCING)
+// ============================================================================
+
+// feocCountries are the foreign entity of concern countries of the Inflation
+// Reduction Act clean vehicle credit (critical minerals and battery components)
+var feocCountries = map[string]bool{"CN": true, "RU": true, "KP": true, "IR": true}
+
+// ulStandards are the UL safety standards accepted for each battery category.
+// SLI batteries have none; a batch of no category may show either general one.
+var ulStandards = map[string][]string{
+	models.BatteryCategoryPortable:   {"UL 2054"},
+	models.BatteryCategoryLMT:        {"UL 2271"},
+	models.BatteryCategoryEV:         {"UL 2580"},
+	models.BatteryCategoryIndustrial: {"UL 1973"},
+	"":                               {"UL 2054", "UL 2580"},
+}
+
+var usRulePack = &MarketRulePack{
+	Market:         models.MarketRegionUS,
+	Name:           "US: UL safety listing by battery category, DOT lithium battery transport and IRA sourcing",
+	Certifications: []string{"UL 2054", "UL 2271", "UL 2580", "UL 1973"},
+	Documents: []string{
+		"UL test report", "UN 38.3 test summary (49 CFR 173.185)",
+		"IRA critical mineral sourcing attestation",
+	},
+	Rules: []MarketRule{
+		hsnRule(""),
+		iecRule(nil, ""),
+		{
+			Key: "ul_listing", Label: "UL safety listing", Kind: models.MarketRequirementCertification,
+			Severity: models.ComplianceBlocking, OnCreate: models.SpecIssueWarning,
+			Applies: func(b *models.Batch) bool { return len(ulStandards[b.Specs.BatteryCategory]) > 0 },
+			Check: func(c *marketContext) (bool, string) {
+				standards := ulStandards[c.batch.Specs.BatteryCategory]
+				for _, standard := range standards {
+					if hasCertification(c.batch.Specs.Certifications, standard) {
+						return true, ""
+					}
+				}
+				return false, "Add " + strings.Join(standards, " or ") + " to the batch certifications"
+			},
+		},
+		un383Rule(models.ComplianceBlocking),
+		{
+			Key: "ira_sourcing", Label: "IRA critical mineral sourcing", Kind: models.MarketRequirementDocument,
+			Severity: models.ComplianceAdvisory,
+			// The clean vehicle credit only concerns EV batteries
+			Applies: func(b *models.Batch) bool {
+				return b.Specs.BatteryCategory == "" || b.Specs.BatteryCategory == models.BatteryCategoryEV
+			},
+			Check: func(c *marketContext) (bool, string) {
+				dd := c.batch.Specs.DueDiligence
+				if dd == nil || len(dd.Suppliers) == 0 {
+					return false, "List the mines, smelters and refiners in specs.due_diligence to show where critical minerals are sourced"
+				}
+				feoc := 0
+				for _, s := range dd.Suppliers {
+					if feocCountries[s.Country] {
+						feoc++
+					}
+				}
+				if feoc > 0 {
+					return false, fmt.Sprintf("%d supplier(s) are in a foreign entity of concern country (CN, RU, KP, IR); "+
+						"the battery may not qualify for the IRA clean vehicle credit", feoc)
+				}
+				return true, ""
+			},
+		},
+	},
+}
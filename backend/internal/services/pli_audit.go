@@ -0,0 +1,400 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"exportready-battery/internal/auth"
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// PLI DVA AUDIT SERVICE
+// ============================================================================
+
+// PLI audit errors, shown to the caller as-is
+var (
+	ErrPLIAuditInvalid  = errors.New("invalid PLI audit")
+	ErrPLIAuditNotFound = errors.New("PLI audit not found")
+	ErrPLIAuditConflict = errors.New("PLI audit conflicts with the batch's audit state")
+)
+
+const (
+	PLIAuditLinkValidity = 14 * 24 * time.Hour // How long the CA's link can be used
+	maxPLIAuditNote      = 1000
+	maxCAName            = 200
+)
+
+// PLIAuditService runs the CA attestation of a batch's domestic value
+// addition. The manufacturer invites a chartered accountant with a one-time
+// link; the batch's DVA becomes AUDITED only when the CA attests it.
+type PLIAuditService struct {
+	repo       *repository.Repository
+	email      *EmailService
+	validation *ValidationService
+}
+
+// NewPLIAuditService creates a new PLI audit service
+func NewPLIAuditService(repo *repository.Repository, email *EmailService) *PLIAuditService {
+	return &PLIAuditService{repo: repo, email: email, validation: NewValidationService()}
+}
+
+// tenantBatch loads a batch, hiding other tenants' batches as not found
+func (s *PLIAuditService) tenantBatch(ctx context.Context, tenantID, batchID uuid.UUID) (*models.Batch, error) {
+	batch, err := s.repo.GetBatch(ctx, batchID)
+	if err != nil {
+		if err.Error() == "batch not found" {
+			return nil, ErrPLIAuditNotFound
+		}
+		return nil, err
+	}
+	if batch.TenantID != tenantID {
+		return nil, ErrPLIAuditNotFound
+	}
+	return batch, nil
+}
+
+// RequestPLIAuditRequest invites a chartered accountant to attest a batch's DVA
+type RequestPLIAuditRequest struct {
+	CAEmail string `json:"ca_email"`          // Gets the one-time attestation link
+	CAName  string `json:"ca_name,omitempty"` // Defaults to the email
+	Note    string `json:"note,omitempty"`    // Message to the CA
+}
+
+// RequestAudit records a pending audit request for an India batch with domestic
+// cells and emails the CA the attestation link
+func (s *PLIAuditService) RequestAudit(ctx context.Context, tenantID, batchID uuid.UUID, actor string, req RequestPLIAuditRequest) (*models.PLIAudit, error) {
+	req.CAEmail = strings.ToLower(strings.TrimSpace(req.CAEmail))
+	req.CAName = strings.TrimSpace(req.CAName)
+	req.Note = strings.TrimSpace(req.Note)
+	if addr, err := mail.ParseAddress(req.CAEmail); err != nil || addr.Address != req.CAEmail {
+		return nil, fmt.Errorf("%w: ca_email must be a valid email address", ErrPLIAuditInvalid)
+	}
+	if req.CAName == "" {
+		req.CAName = req.CAEmail
+	}
+	if len(req.CAName) > maxCAName {
+		return nil, fmt.Errorf("%w: ca_name must be at most %d characters", ErrPLIAuditInvalid, maxCAName)
+	}
+	if len(req.Note) > maxPLIAuditNote {
+		return nil, fmt.Errorf("%w: note must be at most %d characters", ErrPLIAuditInvalid, maxPLIAuditNote)
+	}
+
+	batch, err := s.tenantBatch(ctx, tenantID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if !batch.Targets(models.MarketRegionIndia) {
+		return nil, fmt.Errorf("%w: PLI audits apply to batches targeting INDIA only", ErrPLIAuditInvalid)
+	}
+	if batch.CellSource == "IMPORTED" {
+		return nil, fmt.Errorf("%w: batches with imported cells are not eligible for PLI", ErrPLIAuditInvalid)
+	}
+	if batch.Status == models.BatchStatusArchived {
+		return nil, fmt.Errorf("%w: archived batches cannot be audited", ErrPLIAuditInvalid)
+	}
+
+	token, err := generatePLIAuditToken()
+	if err != nil {
+		return nil, err
+	}
+
+	audit := &models.PLIAudit{
+		TenantID:    tenantID,
+		BatchID:     batch.ID,
+		CAEmail:     req.CAEmail,
+		CAName:      req.CAName,
+		Note:        req.Note,
+		ExpiresAt:   time.Now().Add(PLIAuditLinkValidity),
+		RequestedBy: actor,
+	}
+	if err := s.repo.CreatePLIAudit(ctx, audit, auth.HashToken(token)); err != nil {
+		if errors.Is(err, repository.ErrPLIAuditPending) {
+			return nil, fmt.Errorf("%w: %v; cancel it first", ErrPLIAuditConflict, err)
+		}
+		return nil, err
+	}
+
+	s.sendAuditRequest(tenantID, batch.BatchName, audit, token)
+	return audit, nil
+}
+
+// sendAuditRequest emails the attestation link in the background
+func (s *PLIAuditService) sendAuditRequest(tenantID uuid.UUID, batchName string, audit *models.PLIAudit, token string) {
+	if s.email == nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		companyName := "The manufacturer"
+		if tenant, err := s.repo.GetTenant(ctx, tenantID); err == nil && tenant.CompanyName != "" {
+			companyName = tenant.CompanyName
+		}
+
+		if err := s.email.SendPLIAuditRequest(audit.CAEmail, audit.CAName, companyName, batchName, audit.Note, audit.ExpiresAt, token); err != nil {
+			log.Printf("Warning: Failed to send PLI audit request %s to %s: %v", audit.ID, audit.CAEmail, err)
+		}
+	}()
+}
+
+// History returns a tenant batch's attestation state, audit requests and log
+func (s *PLIAuditService) History(ctx context.Context, tenantID, batchID uuid.UUID) (*models.PLIAuditHistory, error) {
+	batch, err := s.tenantBatch(ctx, tenantID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	audits, err := s.repo.ListPLIAudits(ctx, batch.ID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.ListPLIAuditLog(ctx, batch.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	dvaSource := batch.DVASource
+	if dvaSource == "" {
+		dvaSource = models.DVASourceEstimated
+	}
+	return &models.PLIAuditHistory{
+		BatchID:    batch.ID,
+		DVASource:  dvaSource,
+		AuditedDVA: batch.AuditedDomesticValueAdd,
+		Audits:     audits,
+		Log:        entries,
+	}, nil
+}
+
+// Cancel withdraws a pending audit request; its link stops working
+func (s *PLIAuditService) Cancel(ctx context.Context, tenantID, batchID, auditID uuid.UUID, actor string) (*models.PLIAudit, error) {
+	audit, err := s.repo.CancelPLIAudit(ctx, tenantID, batchID, auditID, actor)
+	if errors.Is(err, repository.ErrPLIAuditNotPending) {
+		return nil, fmt.Errorf("%w: %v", ErrPLIAuditConflict, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if audit == nil {
+		return nil, ErrPLIAuditNotFound
+	}
+	return audit, nil
+}
+
+// PLIAuditInvitation is what the chartered accountant sees behind the link
+type PLIAuditInvitation struct {
+	AuditID       uuid.UUID           `json:"audit_id"`
+	Status        string              `json:"status"` // PENDING, or EXPIRED after expires_at
+	CAName        string              `json:"ca_name"`
+	CAEmail       string              `json:"ca_email"`
+	Note          string              `json:"note,omitempty"`
+	ExpiresAt     time.Time           `json:"expires_at"`
+	CompanyName   string              `json:"company_name"`
+	BatchName     string              `json:"batch_name"`
+	MarketRegion  models.MarketRegion `json:"market_region"`
+	CellSource    string              `json:"cell_source,omitempty"`
+	Chemistry     string              `json:"chemistry,omitempty"`
+	DVASource     string              `json:"dva_source"`      // Of the current DVA: ESTIMATED, or AUDITED by an earlier attestation
+	CurrentDVA    float64             `json:"current_dva"`     // %
+	PLIMinimumDVA float64             `json:"pli_minimum_dva"` // Threshold for PLI eligibility, %
+}
+
+// GetInvitation returns the audit request behind a link token. The token stops
+// working once the request is attested or cancelled.
+func (s *PLIAuditService) GetInvitation(ctx context.Context, token string) (*PLIAuditInvitation, error) {
+	_, invitation, err := s.invitation(ctx, token)
+	return invitation, err
+}
+
+// invitation loads the audit request behind a link token and its batch
+func (s *PLIAuditService) invitation(ctx context.Context, token string) (*models.PLIAudit, *PLIAuditInvitation, error) {
+	if token == "" {
+		return nil, nil, ErrPLIAuditNotFound
+	}
+	audit, err := s.repo.GetPLIAuditByToken(ctx, auth.HashToken(token))
+	if err != nil {
+		return nil, nil, err
+	}
+	if audit == nil {
+		return nil, nil, ErrPLIAuditNotFound
+	}
+	batch, err := s.repo.GetBatch(ctx, audit.BatchID)
+	if err != nil {
+		if err.Error() == "batch not found" {
+			return nil, nil, ErrPLIAuditNotFound
+		}
+		return nil, nil, err
+	}
+	tenant, err := s.repo.GetTenant(ctx, audit.TenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	dvaSource := batch.DVASource
+	if dvaSource == "" {
+		dvaSource = models.DVASourceEstimated
+	}
+
+	return audit, &PLIAuditInvitation{
+		AuditID:       audit.ID,
+		Status:        audit.Status,
+		CAName:        audit.CAName,
+		CAEmail:       audit.CAEmail,
+		Note:          audit.Note,
+		ExpiresAt:     audit.ExpiresAt,
+		CompanyName:   tenant.CompanyName,
+		BatchName:     batch.BatchName,
+		MarketRegion:  batch.MarketRegion,
+		CellSource:    batch.CellSource,
+		Chemistry:     batch.Specs.Chemistry,
+		DVASource:     dvaSource,
+		CurrentDVA:    batch.DomesticValueAdd,
+		PLIMinimumDVA: models.PLIMinimumDVA,
+	}, nil
+}
+
+// AttestPLIAuditRequest is the chartered accountant's attestation
+type AttestPLIAuditRequest struct {
+	Token          string
+	AuditedDVA     string // %, 0-100
+	CAName         string // Defaults to the name the CA was invited with
+	CAMembershipNo string // ICAI membership number
+	UDIN           string // UDIN of the signed certificate
+	FileName       string
+}
+
+// pliAuditDir is where a batch's signed CA certificates are stored:
+// ./storage/{tenant_id}/pli-audits/{batch_id}/
+func pliAuditDir(tenantID, batchID uuid.UUID) string {
+	return privateDir(tenantID, "pli-audits", batchID.String())
+}
+
+// Attest stores the signed certificate and records the CA's attestation: the
+// batch's DVA becomes AUDITED with the attested value, and it is PLI compliant
+// when that value meets the threshold. The link cannot be used again.
+func (s *PLIAuditService) Attest(ctx context.Context, req AttestPLIAuditRequest, certificate io.Reader) (*models.PLIAudit, error) {
+	dva, err := parseAuditedDVA(req.AuditedDVA)
+	if err != nil {
+		return nil, err
+	}
+	if result := s.validation.ValidateCAMembershipNo(req.CAMembershipNo); !result.Valid {
+		return nil, fmt.Errorf("%w: %s", ErrPLIAuditInvalid, result.Message)
+	}
+	membershipNo := strings.TrimSpace(req.CAMembershipNo)
+	if result := s.validation.ValidateUDIN(req.UDIN, membershipNo); !result.Valid {
+		return nil, fmt.Errorf("%w: %s", ErrPLIAuditInvalid, result.Message)
+	}
+	caName := strings.TrimSpace(req.CAName)
+	if len(caName) > maxCAName {
+		return nil, fmt.Errorf("%w: ca_name must be at most %d characters", ErrPLIAuditInvalid, maxCAName)
+	}
+	fileName := filepath.Base(req.FileName)
+	if len(fileName) > maxDocumentField {
+		return nil, fmt.Errorf("%w: file name must be at most %d characters", ErrPLIAuditInvalid, maxDocumentField)
+	}
+
+	audit, invitation, err := s.invitation(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.Status != models.PLIAuditStatusPending {
+		return nil, fmt.Errorf("%w: this audit request is %s", ErrPLIAuditConflict, strings.ToLower(invitation.Status))
+	}
+	if invitation.CellSource == "IMPORTED" {
+		return nil, fmt.Errorf("%w: the batch now has imported cells, which are not eligible for PLI", ErrPLIAuditConflict)
+	}
+	if caName == "" {
+		caName = invitation.CAName
+	}
+
+	// Stored under a fresh name so a concurrent attempt cannot remove it
+	dir := pliAuditDir(audit.TenantID, audit.BatchID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create certificate directory: %w", err)
+	}
+	path := filepath.Join(dir, uuid.New().String()+".pdf")
+	dst, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save certificate: %w", err)
+	}
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(dst, hash), certificate)
+	dst.Close()
+	if err == nil {
+		audit, err = s.repo.AttestPLIAudit(ctx, auth.HashToken(req.Token), repository.PLIAttestation{
+			AuditedDVA:          dva,
+			CAName:              caName,
+			CAMembershipNo:      membershipNo,
+			UDIN:                strings.ToUpper(strings.TrimSpace(req.UDIN)),
+			CertificateFileName: fileName,
+			CertificatePath:     path,
+			CertificateSHA256:   hex.EncodeToString(hash.Sum(nil)),
+			CertificateURL:      fmt.Sprintf("/api/v1/batches/%s/pli-audit/certificate", audit.BatchID),
+			PLICompliant:        s.validation.ValidatePLICompliance(&dva, 0, true) == nil,
+		})
+	}
+	if err != nil {
+		if rmErr := os.Remove(path); rmErr != nil {
+			log.Printf("Warning: Failed to remove PLI certificate %s: %v", path, rmErr)
+		}
+		if errors.Is(err, repository.ErrPLIAuditNotPending) {
+			return nil, fmt.Errorf("%w: %v", ErrPLIAuditConflict, err)
+		}
+		return nil, err
+	}
+	return audit, nil
+}
+
+// parseAuditedDVA reads the attested DVA percentage
+func parseAuditedDVA(value string) (float64, error) {
+	dva, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsNaN(dva) {
+		return 0, fmt.Errorf("%w: audited_dva must be a number", ErrPLIAuditInvalid)
+	}
+	if dva < 0 || dva > 100 {
+		return 0, fmt.Errorf("%w: audited_dva must be between 0 and 100", ErrPLIAuditInvalid)
+	}
+	return math.Round(dva*100) / 100, nil
+}
+
+// Certificate returns the batch's latest attestation, whose signed certificate
+// the batch links to
+func (s *PLIAuditService) Certificate(ctx context.Context, tenantID, batchID uuid.UUID) (*models.PLIAudit, error) {
+	if _, err := s.tenantBatch(ctx, tenantID, batchID); err != nil {
+		return nil, err
+	}
+	audit, err := s.repo.GetLatestPLIAttestation(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if audit == nil {
+		return nil, ErrPLIAuditNotFound
+	}
+	return audit, nil
+}
+
+// generatePLIAuditToken returns a random attestation link token; only its hash is stored
+func generatePLIAuditToken() (string, error) {
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", fmt.Errorf("failed to generate attestation token: %w", err)
+	}
+	return hex.EncodeToString(randomBytes), nil
+}
@@ -0,0 +1,333 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// RECALL SERVICE
+// ============================================================================
+
+// Recall validation errors, shown to the caller as-is
+var (
+	ErrRecallInvalid  = errors.New("invalid recall")
+	ErrRecallNotFound = errors.New("recall campaign not found")
+	ErrRecallClosed   = errors.New("recall campaign is closed")
+)
+
+const recallNotifyTimeout = 10 * time.Minute
+
+// RecallService runs recall campaigns: scoping, the bulk RECALLED transition,
+// owner notices and per-unit remedy tracking
+type RecallService struct {
+	repo      *repository.Repository
+	lifecycle *LifecycleService
+	email     *EmailService
+}
+
+// NewRecallService creates a new recall service
+func NewRecallService(repo *repository.Repository, lifecycle *LifecycleService, email *EmailService) *RecallService {
+	return &RecallService{repo: repo, lifecycle: lifecycle, email: email}
+}
+
+// CreateRecallRequest is a new recall campaign
+type CreateRecallRequest struct {
+	Title              string             `json:"title"`
+	Reason             string             `json:"reason"`
+	Severity           string             `json:"severity"`
+	RegulatorReference string             `json:"regulator_reference,omitempty"`
+	RemedyInstructions string             `json:"remedy_instructions,omitempty"`
+	Scope              models.RecallScope `json:"scope"`
+}
+
+// CreateRecallResult is the created campaign and how many passports it moved
+type CreateRecallResult struct {
+	Campaign     *models.RecallCampaign `json:"campaign"`
+	Transitioned int                    `json:"transitioned"` // Passports moved to RECALLED (the rest were already recalled)
+}
+
+// ValidateScope checks a recall scope; an empty scope would recall everything
+func (s *RecallService) ValidateScope(scope *models.RecallScope) error {
+	scope.SerialFrom = strings.TrimSpace(scope.SerialFrom)
+	scope.SerialTo = strings.TrimSpace(scope.SerialTo)
+	scope.Chemistry = strings.TrimSpace(scope.Chemistry)
+
+	if scope.IsEmpty() {
+		return fmt.Errorf("%w: scope needs at least one of batch_ids, serial range, manufacture window or chemistry", ErrRecallInvalid)
+	}
+	if scope.SerialFrom != "" && scope.SerialTo != "" && scope.SerialFrom > scope.SerialTo {
+		return fmt.Errorf("%w: serial_from is after serial_to", ErrRecallInvalid)
+	}
+
+	var from, to time.Time
+	var err error
+	if scope.ManufacturedFrom != "" {
+		if from, err = time.Parse("2006-01-02", scope.ManufacturedFrom); err != nil {
+			return fmt.Errorf("%w: manufactured_from must be YYYY-MM-DD", ErrRecallInvalid)
+		}
+	}
+	if scope.ManufacturedTo != "" {
+		if to, err = time.Parse("2006-01-02", scope.ManufacturedTo); err != nil {
+			return fmt.Errorf("%w: manufactured_to must be YYYY-MM-DD", ErrRecallInvalid)
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return fmt.Errorf("%w: manufactured_from is after manufactured_to", ErrRecallInvalid)
+	}
+	return nil
+}
+
+// PreviewRecall reports what a campaign with this scope would affect
+func (s *RecallService) PreviewRecall(ctx context.Context, tenantID uuid.UUID, scope models.RecallScope) (*models.RecallPreview, error) {
+	if err := s.ValidateScope(&scope); err != nil {
+		return nil, err
+	}
+	return s.repo.PreviewRecall(ctx, tenantID, scope)
+}
+
+// CreateRecall opens a campaign, recalls every passport in scope and starts
+// emailing the known owners in the background
+func (s *RecallService) CreateRecall(ctx context.Context, tenantID uuid.UUID, actor string, req CreateRecallRequest) (*CreateRecallResult, error) {
+	req.Title = strings.TrimSpace(req.Title)
+	req.Reason = strings.TrimSpace(req.Reason)
+	req.Severity = strings.ToUpper(strings.TrimSpace(req.Severity))
+
+	if req.Title == "" || req.Reason == "" {
+		return nil, fmt.Errorf("%w: title and reason are required", ErrRecallInvalid)
+	}
+	if !models.IsValidRecallSeverity(req.Severity) {
+		return nil, fmt.Errorf("%w: severity must be LOW, MEDIUM, HIGH or CRITICAL", ErrRecallInvalid)
+	}
+	if err := s.ValidateScope(&req.Scope); err != nil {
+		return nil, err
+	}
+
+	campaign := &models.RecallCampaign{
+		TenantID:           tenantID,
+		Title:              req.Title,
+		Reason:             req.Reason,
+		Severity:           req.Severity,
+		RegulatorReference: strings.TrimSpace(req.RegulatorReference),
+		RemedyInstructions: strings.TrimSpace(req.RemedyInstructions),
+		Scope:              req.Scope,
+		CreatedBy:          actor,
+	}
+
+	transitioned, err := s.repo.CreateRecallCampaign(ctx, campaign)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecallNoPassports) {
+			return nil, fmt.Errorf("%w: %v", ErrRecallInvalid, err)
+		}
+		return nil, err
+	}
+
+	// Push to the tenant's live event stream (non-critical)
+	if err := s.repo.PublishTenantEvent(ctx, tenantID, models.EventRecallCreated, models.RecallCreatedData{
+		CampaignID:   campaign.ID,
+		Title:        campaign.Title,
+		Severity:     campaign.Severity,
+		Affected:     campaign.AffectedCount,
+		Transitioned: transitioned,
+	}); err != nil {
+		log.Printf("Warning: Failed to publish recall event: %v", err)
+	}
+
+	s.NotifyOwnersAsync(campaign)
+
+	return &CreateRecallResult{Campaign: campaign, Transitioned: transitioned}, nil
+}
+
+// GetRecall retrieves a campaign with its remedy progress
+func (s *RecallService) GetRecall(ctx context.Context, tenantID, campaignID uuid.UUID) (*models.RecallCampaign, *models.RecallProgress, error) {
+	campaign, err := s.repo.GetRecallCampaign(ctx, tenantID, campaignID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if campaign == nil {
+		return nil, nil, ErrRecallNotFound
+	}
+
+	progress, err := s.repo.GetRecallProgress(ctx, campaignID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return campaign, progress, nil
+}
+
+// NotifyOwnersAsync emails owners not yet notified without blocking the caller
+func (s *RecallService) NotifyOwnersAsync(campaign *models.RecallCampaign) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), recallNotifyTimeout)
+		defer cancel()
+
+		sent, err := s.NotifyOwners(ctx, campaign)
+		if err != nil {
+			log.Printf("⚠️ Recall %s owner notification stopped after %d emails: %v", campaign.ID, sent, err)
+			return
+		}
+		log.Printf("📧 Recall %s: notified %d owners", campaign.ID, sent)
+	}()
+}
+
+// NotifyOwners emails each known owner once about all of their units in the
+// campaign and marks those units notified. Owners whose email fails are left
+// for the next run. Returns how many owners were emailed.
+func (s *RecallService) NotifyOwners(ctx context.Context, campaign *models.RecallCampaign) (int, error) {
+	owners, err := s.repo.ListRecallOwnersToNotify(ctx, campaign.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, owner := range owners {
+		if err := ctx.Err(); err != nil {
+			return sent, err
+		}
+		if err := s.email.SendRecallNotice(owner.Email, campaign, owner.Units); err != nil {
+			log.Printf("Failed to send recall notice for %s to %s: %v", campaign.ID, owner.Email, err)
+			continue
+		}
+		if err := s.repo.MarkRecallOwnerNotified(ctx, campaign.ID, owner.Email); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// UpdateRemediesRequest sets the remedy status of units in a campaign
+type UpdateRemediesRequest struct {
+	PassportIDs  []uuid.UUID `json:"passport_ids"`
+	RemedyStatus string      `json:"remedy_status"`
+	Note         string      `json:"note,omitempty"`
+}
+
+// UpdateRemediesResult reports which units were updated
+type UpdateRemediesResult struct {
+	Updated  int      `json:"updated"`
+	NotFound []string `json:"not_found,omitempty"` // Passports not in the campaign
+	Errors   []string `json:"errors,omitempty"`    // Passports whose RECYCLED transition failed
+}
+
+// UpdateRemedies records remedies for units of an active campaign. Recycled
+// units also move their passport from RECALLED to RECYCLED.
+func (s *RecallService) UpdateRemedies(ctx context.Context, tenantID, campaignID uuid.UUID, actor string, req UpdateRemediesRequest) (*UpdateRemediesResult, error) {
+	req.RemedyStatus = strings.ToUpper(strings.TrimSpace(req.RemedyStatus))
+	if !models.IsValidRemedyStatus(req.RemedyStatus) {
+		return nil, fmt.Errorf("%w: remedy_status must be NOTIFIED, RETURNED, REPLACED or RECYCLED", ErrRecallInvalid)
+	}
+
+	campaign, err := s.repo.GetRecallCampaign(ctx, tenantID, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if campaign == nil {
+		return nil, ErrRecallNotFound
+	}
+	if campaign.Status != models.RecallStatusActive {
+		return nil, ErrRecallClosed
+	}
+
+	updated, err := s.repo.UpdateRecallRemedies(ctx, campaignID, req.PassportIDs, req.RemedyStatus, strings.TrimSpace(req.Note))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UpdateRemediesResult{Updated: len(updated)}
+	found := make(map[uuid.UUID]bool, len(updated))
+	for _, id := range updated {
+		found[id] = true
+	}
+	for _, id := range req.PassportIDs {
+		if !found[id] {
+			result.NotFound = append(result.NotFound, id.String())
+		}
+	}
+
+	if req.RemedyStatus == models.RemedyRecycled {
+		for _, id := range updated {
+			transition, err := s.lifecycle.TransitionPassport(ctx, TransitionRequest{
+				PassportID: id,
+				ToStatus:   models.PassportStatusRecycled,
+				Actor:      actor,
+				Metadata:   map[string]interface{}{"recall_campaign_id": campaignID.String()},
+			})
+			if err != nil && transition != nil && transition.PreviousStatus == models.PassportStatusRecycled {
+				continue // Already recycled
+			}
+			if err != nil {
+				msg := err.Error()
+				if transition != nil && transition.Error != "" {
+					msg = transition.Error
+				}
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", id, msg))
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// CloseRecall ends an active campaign
+func (s *RecallService) CloseRecall(ctx context.Context, tenantID, campaignID uuid.UUID) error {
+	closed, err := s.repo.CloseRecallCampaign(ctx, tenantID, campaignID)
+	if err != nil {
+		return err
+	}
+	if closed {
+		return nil
+	}
+
+	campaign, err := s.repo.GetRecallCampaign(ctx, tenantID, campaignID)
+	if err != nil {
+		return err
+	}
+	if campaign == nil {
+		return ErrRecallNotFound
+	}
+	return ErrRecallClosed
+}
+
+// ListRecalls retrieves a tenant's campaigns, newest first
+func (s *RecallService) ListRecalls(ctx context.Context, tenantID uuid.UUID, status string, page repository.PageRequest) ([]*models.RecallCampaign, repository.PageInfo, error) {
+	return s.repo.ListRecallCampaigns(ctx, tenantID, status, page)
+}
+
+// ListRecallUnits retrieves a campaign's units in serial order
+func (s *RecallService) ListRecallUnits(ctx context.Context, tenantID, campaignID uuid.UUID, remedyStatus string, page repository.PageRequest) ([]*models.RecallUnit, repository.PageInfo, error) {
+	campaign, err := s.repo.GetRecallCampaign(ctx, tenantID, campaignID)
+	if err != nil {
+		return nil, repository.PageInfo{}, err
+	}
+	if campaign == nil {
+		return nil, repository.PageInfo{}, ErrRecallNotFound
+	}
+	return s.repo.ListRecallUnits(ctx, campaignID, remedyStatus, page)
+}
+
+// NotifyRecallOwners starts emailing owners of an active campaign who have not
+// been notified, e.g. owners registered after the campaign opened
+func (s *RecallService) NotifyRecallOwners(ctx context.Context, tenantID, campaignID uuid.UUID) error {
+	campaign, err := s.repo.GetRecallCampaign(ctx, tenantID, campaignID)
+	if err != nil {
+		return err
+	}
+	if campaign == nil {
+		return ErrRecallNotFound
+	}
+	if campaign.Status != models.RecallStatusActive {
+		return ErrRecallClosed
+	}
+	s.NotifyOwnersAsync(campaign)
+	return nil
+}
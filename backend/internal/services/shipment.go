@@ -0,0 +1,667 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jung-kurt/gofpdf"
+
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+)
+
+// ============================================================================
+// SHIPMENT SERVICE
+// ============================================================================
+
+// Shipment errors, shown to the caller as-is
+var (
+	ErrShipmentInvalid  = errors.New("invalid shipment")
+	ErrShipmentNotFound = errors.New("shipment not found")
+	ErrShipmentConflict = errors.New("shipment conflicts with its passports' current state")
+)
+
+const (
+	MaxShipmentPassportIDs = 10000 // Passports listed one by one; whole batches have no limit
+	maxShipmentReference   = 100
+	maxShipmentField       = 200
+	maxShipmentNote        = 1000
+)
+
+// incoterms are the Incoterms 2020 rules
+var incoterms = map[string]bool{
+	"EXW": true, "FCA": true, "CPT": true, "CIP": true, "DAP": true, "DPU": true,
+	"DDP": true, "FAS": true, "FOB": true, "CFR": true, "CIF": true,
+}
+
+var currencyRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ShipmentService groups passports into export shipments, generates their
+// customs documentation pack and ships them
+type ShipmentService struct {
+	repo *repository.Repository
+	qr   *QRService
+}
+
+// NewShipmentService creates a new shipment service
+func NewShipmentService(repo *repository.Repository, qr *QRService) *ShipmentService {
+	return &ShipmentService{repo: repo, qr: qr}
+}
+
+// CreateShipmentRequest is a new draft shipment. Every shippable passport of
+// batch_ids is included, plus the passports in passport_ids.
+type CreateShipmentRequest struct {
+	Reference          string                `json:"reference"`
+	DestinationCountry string                `json:"destination_country"`
+	ConsigneeName      string                `json:"consignee_name"`
+	ConsigneeAddress   string                `json:"consignee_address,omitempty"`
+	InvoiceNumber      string                `json:"invoice_number,omitempty"`
+	InvoiceDate        string                `json:"invoice_date,omitempty"` // YYYY-MM-DD
+	Incoterm           string                `json:"incoterm,omitempty"`     // Incoterms 2020, e.g. FOB
+	Currency           string                `json:"currency,omitempty"`     // ISO 4217; defaults to USD
+	UnitValues         map[uuid.UUID]float64 `json:"unit_values,omitempty"`  // Invoice value per unit, by batch ID
+	Carrier            string                `json:"carrier,omitempty"`
+	PortOfLoading      string                `json:"port_of_loading,omitempty"`
+	PortOfDischarge    string                `json:"port_of_discharge,omitempty"`
+	Packing            string                `json:"packing,omitempty"` // BATTERIES_ONLY (default), PACKED_WITH_EQUIPMENT, CONTAINED_IN_EQUIPMENT
+	Note               string                `json:"note,omitempty"`
+	BatchIDs           []uuid.UUID           `json:"batch_ids,omitempty"`
+	PassportIDs        []uuid.UUID           `json:"passport_ids,omitempty"`
+}
+
+// ShipResult is a shipped shipment and how many passports it moved
+type ShipResult struct {
+	Shipment *models.Shipment `json:"shipment"`
+	Shipped  int              `json:"shipped"` // Passports moved to SHIPPED
+}
+
+// validateShipmentRequest trims and checks a new shipment
+func validateShipmentRequest(req *CreateShipmentRequest) error {
+	for _, f := range []*string{&req.Reference, &req.DestinationCountry, &req.ConsigneeName, &req.ConsigneeAddress,
+		&req.InvoiceNumber, &req.InvoiceDate, &req.Carrier, &req.PortOfLoading, &req.PortOfDischarge, &req.Note} {
+		*f = strings.TrimSpace(*f)
+	}
+	req.Incoterm = strings.ToUpper(strings.TrimSpace(req.Incoterm))
+	req.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+	req.Packing = strings.ToUpper(strings.TrimSpace(req.Packing))
+
+	if req.Reference == "" || req.DestinationCountry == "" || req.ConsigneeName == "" {
+		return fmt.Errorf("%w: reference, destination_country and consignee_name are required", ErrShipmentInvalid)
+	}
+	if len(req.Reference) > maxShipmentReference || len(req.InvoiceNumber) > maxShipmentReference {
+		return fmt.Errorf("%w: reference and invoice_number must be at most %d characters", ErrShipmentInvalid, maxShipmentReference)
+	}
+	for _, f := range []struct{ name, value string }{
+		{"destination_country", req.DestinationCountry}, {"consignee_name", req.ConsigneeName},
+		{"carrier", req.Carrier}, {"port_of_loading", req.PortOfLoading}, {"port_of_discharge", req.PortOfDischarge},
+	} {
+		if len(f.value) > maxShipmentField {
+			return fmt.Errorf("%w: %s must be at most %d characters", ErrShipmentInvalid, f.name, maxShipmentField)
+		}
+	}
+	if len(req.ConsigneeAddress) > maxShipmentNote || len(req.Note) > maxShipmentNote {
+		return fmt.Errorf("%w: consignee_address and note must be at most %d characters", ErrShipmentInvalid, maxShipmentNote)
+	}
+	if req.InvoiceDate != "" {
+		if _, err := time.Parse("2006-01-02", req.InvoiceDate); err != nil {
+			return fmt.Errorf("%w: invoice_date must be YYYY-MM-DD", ErrShipmentInvalid)
+		}
+	}
+	if req.Incoterm != "" && !incoterms[req.Incoterm] {
+		return fmt.Errorf("%w: incoterm must be an Incoterms 2020 rule such as FOB, CIF or DAP", ErrShipmentInvalid)
+	}
+	if req.Currency == "" {
+		req.Currency = "USD"
+	}
+	if !currencyRegex.MatchString(req.Currency) {
+		return fmt.Errorf("%w: currency must be a 3-letter ISO 4217 code", ErrShipmentInvalid)
+	}
+	if req.Packing == "" {
+		req.Packing = models.PackingBatteriesOnly
+	}
+	if !models.IsValidPacking(req.Packing) {
+		return fmt.Errorf("%w: packing must be BATTERIES_ONLY, PACKED_WITH_EQUIPMENT or CONTAINED_IN_EQUIPMENT", ErrShipmentInvalid)
+	}
+	for _, v := range req.UnitValues {
+		if v < 0 {
+			return fmt.Errorf("%w: unit_values must not be negative", ErrShipmentInvalid)
+		}
+	}
+
+	req.BatchIDs = dedupeUUIDs(req.BatchIDs)
+	req.PassportIDs = dedupeUUIDs(req.PassportIDs)
+	if len(req.BatchIDs) == 0 && len(req.PassportIDs) == 0 {
+		return fmt.Errorf("%w: batch_ids or passport_ids is required", ErrShipmentInvalid)
+	}
+	if len(req.PassportIDs) > MaxShipmentPassportIDs {
+		return fmt.Errorf("%w: at most %d passport_ids; add whole batches with batch_ids", ErrShipmentInvalid, MaxShipmentPassportIDs)
+	}
+	return nil
+}
+
+// dedupeUUIDs drops repeated IDs, keeping the first occurrence
+func dedupeUUIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	out := ids[:0]
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// CreateShipment drafts a shipment and reserves its passports
+func (s *ShipmentService) CreateShipment(ctx context.Context, tenantID uuid.UUID, actor string, req CreateShipmentRequest) (*models.ShipmentDetail, error) {
+	if err := validateShipmentRequest(&req); err != nil {
+		return nil, err
+	}
+	for _, batchID := range req.BatchIDs {
+		batch, err := s.repo.GetBatch(ctx, batchID)
+		if err != nil {
+			if err.Error() == "batch not found" {
+				return nil, fmt.Errorf("%w: batch %s not found", ErrShipmentInvalid, batchID)
+			}
+			return nil, err
+		}
+		if batch.TenantID != tenantID {
+			return nil, fmt.Errorf("%w: batch %s not found", ErrShipmentInvalid, batchID)
+		}
+		if batch.Status != models.BatchStatusActive {
+			return nil, fmt.Errorf("%w: batch %s is not active", ErrShipmentInvalid, batch.BatchName)
+		}
+	}
+
+	unitValues := req.UnitValues
+	if unitValues == nil {
+		unitValues = map[uuid.UUID]float64{}
+	}
+	shipment := &models.Shipment{
+		TenantID:           tenantID,
+		Reference:          req.Reference,
+		DestinationCountry: req.DestinationCountry,
+		ConsigneeName:      req.ConsigneeName,
+		ConsigneeAddress:   req.ConsigneeAddress,
+		InvoiceNumber:      req.InvoiceNumber,
+		InvoiceDate:        req.InvoiceDate,
+		Incoterm:           req.Incoterm,
+		Currency:           req.Currency,
+		UnitValues:         unitValues,
+		Carrier:            req.Carrier,
+		PortOfLoading:      req.PortOfLoading,
+		PortOfDischarge:    req.PortOfDischarge,
+		Packing:            req.Packing,
+		Note:               req.Note,
+		CreatedBy:          actor,
+	}
+
+	if err := s.repo.CreateShipment(ctx, shipment, req.BatchIDs, req.PassportIDs); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrShipmentNoPassports), errors.Is(err, repository.ErrShipmentValueBatch):
+			return nil, fmt.Errorf("%w: %v", ErrShipmentInvalid, err)
+		case errors.Is(err, repository.ErrShipmentPassportsUnavailable):
+			return nil, fmt.Errorf("%w: %v", ErrShipmentConflict, err)
+		}
+		return nil, err
+	}
+
+	exporter, err := s.repo.GetTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.detail(ctx, shipment, exporter)
+}
+
+// shipment retrieves a tenant's shipment or ErrShipmentNotFound
+func (s *ShipmentService) shipment(ctx context.Context, tenantID, id uuid.UUID) (*models.Shipment, error) {
+	shipment, err := s.repo.GetShipment(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if shipment == nil {
+		return nil, ErrShipmentNotFound
+	}
+	return shipment, nil
+}
+
+// GetShipment retrieves a shipment with its invoice lines and document gaps
+func (s *ShipmentService) GetShipment(ctx context.Context, tenantID, id uuid.UUID) (*models.ShipmentDetail, error) {
+	shipment, err := s.shipment(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	exporter, err := s.repo.GetTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.detail(ctx, shipment, exporter)
+}
+
+// ListShipments retrieves a page of the tenant's shipments
+func (s *ShipmentService) ListShipments(ctx context.Context, tenantID uuid.UUID, status string, page repository.PageRequest) ([]*models.Shipment, repository.PageInfo, error) {
+	return s.repo.ListShipments(ctx, tenantID, status, page)
+}
+
+// detail builds a shipment's invoice lines, one per batch by batch name, and
+// lists what its customs documents would be missing
+func (s *ShipmentService) detail(ctx context.Context, shipment *models.Shipment, exporter *models.Tenant) (*models.ShipmentDetail, error) {
+	counts, err := s.repo.CountShipmentItemsByBatch(ctx, shipment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &models.ShipmentDetail{Shipment: shipment, Lines: []models.ShipmentLine{}}
+	warn := func(format string, args ...interface{}) {
+		detail.Warnings = append(detail.Warnings, fmt.Sprintf(format, args...))
+	}
+	if exporter.IECCode == "" {
+		warn("the exporter's IEC code is not set in settings")
+	}
+	if shipment.InvoiceNumber == "" {
+		warn("no invoice number")
+	}
+
+	for batchID, quantity := range counts {
+		batch, err := s.repo.GetBatch(ctx, batchID)
+		if err != nil {
+			if err.Error() == "batch not found" {
+				warn("%d passports belong to a deleted batch", quantity)
+				continue
+			}
+			return nil, err
+		}
+		line := models.ShipmentLine{
+			BatchID:          batch.ID,
+			BatchName:        batch.BatchName,
+			Chemistry:        batch.Specs.Chemistry,
+			Capacity:         batch.Specs.Capacity,
+			NominalVoltage:   batch.Specs.NominalVoltage,
+			HSNCode:          batch.HSNCode,
+			CountryOfOrigin:  batch.Specs.CountryOfOrigin,
+			Quantity:         quantity,
+			UnitValue:        shipment.UnitValues[batch.ID],
+			DangerousGoods:   models.ClassifyDangerousGoods(batch.Specs.Chemistry, shipment.Packing),
+			UN383TestSummary: batch.Specs.UN383TestSummary,
+		}
+		line.TotalValue = line.UnitValue * float64(quantity)
+		if r := batch.Specs.Ratings; r != nil {
+			line.UnitWeightKg = r.WeightKg
+			line.NetWeightKg = r.WeightKg * float64(quantity)
+			line.RatedEnergyWh = r.RatedEnergyWh
+		}
+		detail.Lines = append(detail.Lines, line)
+
+		if line.HSNCode == "" {
+			warn("batch %s has no HSN code", batch.BatchName)
+		}
+		if line.CountryOfOrigin == "" {
+			warn("batch %s has no country of origin", batch.BatchName)
+		}
+		if line.UnitValue == 0 {
+			warn("batch %s has no unit value", batch.BatchName)
+		}
+		if line.UnitWeightKg == 0 {
+			warn("batch %s has no weight; the packing list cannot give its net weight", batch.BatchName)
+		}
+		if line.DangerousGoods == nil {
+			warn("batch %s is not a lithium-ion chemistry; classify its dangerous goods manually", batch.BatchName)
+		} else if line.UN383TestSummary == "" {
+			warn("batch %s has no UN 38.3 test summary reference", batch.BatchName)
+		}
+	}
+
+	sort.Slice(detail.Lines, func(i, j int) bool {
+		return detail.Lines[i].BatchName < detail.Lines[j].BatchName
+	})
+	sort.Strings(detail.Warnings)
+	return detail, nil
+}
+
+// ShipShipment moves every passport of a draft shipment to SHIPPED
+func (s *ShipmentService) ShipShipment(ctx context.Context, tenantID, id uuid.UUID, actor string) (*ShipResult, error) {
+	found, shipped, err := s.repo.ShipShipment(ctx, tenantID, id, actor)
+	if !found && err == nil {
+		return nil, ErrShipmentNotFound
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrShipmentNotDraft) || errors.Is(err, repository.ErrShipmentPassportsUnavailable) {
+			return nil, fmt.Errorf("%w: %v", ErrShipmentConflict, err)
+		}
+		return nil, err
+	}
+
+	shipment, err := s.shipment(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Push to the tenant's live event stream (non-critical)
+	if err := s.repo.PublishTenantEvent(ctx, tenantID, models.EventShipmentShipped, models.ShipmentShippedData{
+		ShipmentID:         shipment.ID,
+		Reference:          shipment.Reference,
+		DestinationCountry: shipment.DestinationCountry,
+		Count:              shipped,
+	}); err != nil {
+		log.Printf("Warning: Failed to publish shipment event: %v", err)
+	}
+
+	return &ShipResult{Shipment: shipment, Shipped: shipped}, nil
+}
+
+// CancelShipment cancels a draft shipment and releases its passports
+func (s *ShipmentService) CancelShipment(ctx context.Context, tenantID, id uuid.UUID) (*models.Shipment, error) {
+	found, err := s.repo.CancelShipment(ctx, tenantID, id)
+	if !found && err == nil {
+		return nil, ErrShipmentNotFound
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrShipmentNotDraft) {
+			return nil, fmt.Errorf("%w: %v", ErrShipmentConflict, err)
+		}
+		return nil, err
+	}
+	return s.shipment(ctx, tenantID, id)
+}
+
+// ============================================================================
+// CUSTOMS DOCUMENTATION PACK
+// ============================================================================
+
+// ShipmentPack is everything the customs documentation pack is rendered from
+type ShipmentPack struct {
+	Detail      *models.ShipmentDetail
+	Exporter    *models.Tenant
+	Items       []*models.ShipmentItem
+	GeneratedAt time.Time
+}
+
+// Pack gathers a shipment's documentation pack. Cancelled shipments have none.
+func (s *ShipmentService) Pack(ctx context.Context, tenantID, id uuid.UUID) (*ShipmentPack, error) {
+	shipment, err := s.shipment(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if shipment.Status == models.ShipmentStatusCancelled {
+		return nil, fmt.Errorf("%w: shipment is cancelled", ErrShipmentConflict)
+	}
+
+	exporter, err := s.repo.GetTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	detail, err := s.detail(ctx, shipment, exporter)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.ListShipmentItems(ctx, shipment.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &ShipmentPack{Detail: detail, Exporter: exporter, Items: items, GeneratedAt: time.Now()}, nil
+}
+
+// passportURL is the public passport page a QR code points to
+func (s *ShipmentService) passportURL(id uuid.UUID) string {
+	return fmt.Sprintf("%s/p/%s", s.qr.baseURL, id)
+}
+
+// WritePackPDF writes the pack as one A4 document: commercial invoice data,
+// packing list, dangerous-goods summary, country-of-origin declaration and the
+// passport QR index
+func (s *ShipmentService) WritePackPDF(pack *ShipmentPack, w io.Writer) error {
+	shipment, lines, exporter := pack.Detail.Shipment, pack.Detail.Lines, pack.Exporter
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(12, 12, 12)
+	pdf.SetAutoPageBreak(true, 15)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-10)
+		pdf.SetFont("Arial", "I", 7)
+		pdf.CellFormat(0, 4, tr(fmt.Sprintf("Shipment %s - generated %s - page %d",
+			shipment.Reference, pack.GeneratedAt.Format("02 Jan 2006 15:04 MST"), pdf.PageNo())), "", 0, "C", false, 0, "")
+	})
+
+	heading := func(title string) {
+		pdf.AddPage()
+		pdf.SetFont("Arial", "B", 15)
+		pdf.CellFormat(0, 9, title, "", 1, "L", false, 0, "")
+		pdf.Ln(2)
+	}
+	fields := func(rows [][2]string) {
+		for _, row := range rows {
+			if row[1] == "" {
+				continue
+			}
+			pdf.SetFont("Arial", "B", 9)
+			pdf.CellFormat(45, 5.5, row[0]+":", "", 0, "L", false, 0, "")
+			pdf.SetFont("Arial", "", 9)
+			pdf.MultiCell(0, 5.5, tr(row[1]), "", "L", false)
+		}
+		pdf.Ln(3)
+	}
+	table := func(header []string, widths []float64, rows [][]string, leftCols int) {
+		pdf.SetFont("Arial", "B", 8)
+		pdf.SetFillColor(230, 230, 230)
+		for i, h := range header {
+			pdf.CellFormat(widths[i], 7, h, "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Arial", "", 8)
+		for _, row := range rows {
+			for i, cell := range row {
+				align := "R"
+				if i < leftCols {
+					align = "L"
+				}
+				pdf.CellFormat(widths[i], 6, tr(cell), "1", 0, align, false, 0, "")
+			}
+			pdf.Ln(-1)
+		}
+		pdf.Ln(4)
+	}
+	exporterRows := [][2]string{
+		{"Exporter", exporter.CompanyName},
+		{"Address", exporter.Address},
+		{"IEC", exporter.IECCode},
+	}
+
+	// Commercial invoice data
+	heading("Commercial Invoice Data")
+	fields(append(exporterRows,
+		[2]string{"Consignee", shipment.ConsigneeName},
+		[2]string{"Consignee address", shipment.ConsigneeAddress},
+		[2]string{"Invoice number", shipment.InvoiceNumber},
+		[2]string{"Invoice date", shipment.InvoiceDate},
+		[2]string{"Shipment reference", shipment.Reference},
+		[2]string{"Destination", shipment.DestinationCountry},
+		[2]string{"Incoterm", shipment.Incoterm},
+		[2]string{"Port of loading", shipment.PortOfLoading},
+		[2]string{"Port of discharge", shipment.PortOfDischarge},
+		[2]string{"Carrier", shipment.Carrier},
+	))
+	var invoiceRows [][]string
+	var totalUnits int
+	var totalValue, totalWeight float64
+	for _, l := range lines {
+		invoiceRows = append(invoiceRows, []string{
+			l.BatchName, strings.TrimSpace(l.Chemistry + " " + l.NominalVoltage + " " + l.Capacity), l.HSNCode, l.CountryOfOrigin,
+			fmt.Sprint(l.Quantity), fmt.Sprintf("%.2f", l.UnitValue), fmt.Sprintf("%.2f", l.TotalValue),
+		})
+		totalUnits += l.Quantity
+		totalValue += l.TotalValue
+		totalWeight += l.NetWeightKg
+	}
+	invoiceRows = append(invoiceRows, []string{"Total", "", "", "", fmt.Sprint(totalUnits), "", fmt.Sprintf("%.2f", totalValue)})
+	table([]string{"Batch", "Description", "HSN", "Origin", "Qty", "Unit value " + shipment.Currency, "Total " + shipment.Currency},
+		[]float64{36, 48, 20, 24, 16, 21, 21}, invoiceRows, 4)
+
+	// Packing list
+	heading("Packing List")
+	fields([][2]string{
+		{"Shipment reference", shipment.Reference},
+		{"Consignee", shipment.ConsigneeName},
+		{"Packing", strings.ReplaceAll(strings.ToLower(shipment.Packing), "_", " ")},
+	})
+	var packingRows [][]string
+	for _, l := range lines {
+		packingRows = append(packingRows, []string{
+			l.BatchName, l.Chemistry, fmt.Sprint(l.Quantity), weightCell(l.UnitWeightKg), weightCell(l.NetWeightKg),
+		})
+	}
+	packingRows = append(packingRows, []string{"Total", "", fmt.Sprint(totalUnits), "", weightCell(totalWeight)})
+	table([]string{"Batch", "Chemistry", "Qty", "Unit weight kg", "Net weight kg"},
+		[]float64{60, 40, 26, 30, 30}, packingRows, 2)
+
+	// Dangerous goods
+	heading("Dangerous Goods Summary")
+	pdf.SetFont("Arial", "", 9)
+	pdf.MultiCell(0, 5, "Reference data for the shipper's dangerous goods declaration. The declaration itself must be "+
+		"completed and signed by a trained person under the IATA DGR or IMDG Code.", "", "L", false)
+	pdf.Ln(3)
+	var dgRows [][]string
+	for _, l := range lines {
+		un, name, class := "-", "Not classified (non lithium-ion)", "-"
+		if dg := l.DangerousGoods; dg != nil {
+			un, name, class = dg.UNNumber, dg.ProperShippingName, dg.Class
+		}
+		energy := "-"
+		if l.RatedEnergyWh > 0 {
+			energy = fmt.Sprintf("%.1f", l.RatedEnergyWh)
+		}
+		summary := l.UN383TestSummary
+		if summary == "" {
+			summary = "MISSING"
+		}
+		dgRows = append(dgRows, []string{l.BatchName, un, name, class, energy, fmt.Sprint(l.Quantity), summary})
+	}
+	table([]string{"Batch", "UN No.", "Proper shipping name", "Class", "Wh/battery", "Qty", "UN 38.3 test summary"},
+		[]float64{30, 16, 58, 11, 19, 12, 40}, dgRows, 3)
+
+	// Country of origin
+	heading("Country of Origin Declaration")
+	fields(exporterRows)
+	pdf.SetFont("Arial", "", 10)
+	pdf.MultiCell(0, 5.5, tr(fmt.Sprintf("We, %s, declare that the goods listed below, shipped to %s under shipment "+
+		"reference %s, originate in the countries stated.", exporter.CompanyName, shipment.ConsigneeName, shipment.Reference)), "", "L", false)
+	pdf.Ln(3)
+	var originRows [][]string
+	for _, l := range lines {
+		origin := l.CountryOfOrigin
+		if origin == "" {
+			origin = "NOT DECLARED"
+		}
+		originRows = append(originRows, []string{l.BatchName, l.HSNCode, fmt.Sprint(l.Quantity), origin})
+	}
+	table([]string{"Batch", "HSN", "Qty", "Country of origin"}, []float64{70, 30, 26, 60}, originRows, 2)
+	pdf.Ln(12)
+	pdf.SetFont("Arial", "", 9)
+	pdf.CellFormat(90, 5, "Authorised signatory: ______________________", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 5, "Date: ______________", "", 1, "L", false, 0, "")
+
+	// QR index
+	heading("Passport QR Index")
+	pdf.SetFont("Arial", "", 9)
+	pdf.MultiCell(0, 5, "Each battery's QR code opens its digital passport at the address below. "+
+		"The ZIP pack holds the QR images.", "", "L", false)
+	pdf.Ln(3)
+	batchNames := make(map[uuid.UUID]string, len(lines))
+	for _, l := range lines {
+		batchNames[l.BatchID] = l.BatchName
+	}
+	var indexRows [][]string
+	for _, item := range pack.Items {
+		indexRows = append(indexRows, []string{item.SerialNumber, batchNames[item.BatchID], s.passportURL(item.PassportID)})
+	}
+	table([]string{"Serial number", "Batch", "Passport"}, []float64{50, 40, 96}, indexRows, 3)
+
+	if warnings := pack.Detail.Warnings; len(warnings) > 0 {
+		heading("Missing Data")
+		pdf.SetFont("Arial", "", 9)
+		for _, warning := range warnings {
+			pdf.MultiCell(0, 5, tr("- "+warning), "", "L", false)
+		}
+	}
+
+	if err := pdf.Output(w); err != nil {
+		return fmt.Errorf("failed to generate PDF: %w", err)
+	}
+	return nil
+}
+
+// weightCell formats a weight for the packing list, or - if unknown
+func weightCell(kg float64) string {
+	if kg == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", kg)
+}
+
+// WritePackZIP writes the pack as a ZIP of the PDF, a CSV passport index and
+// one QR code PNG per passport
+func (s *ShipmentService) WritePackZIP(pack *ShipmentPack, w io.Writer) error {
+	var pdfBuf bytes.Buffer
+	if err := s.WritePackPDF(pack, &pdfBuf); err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	f, err := zw.Create("customs-pack.pdf")
+	if err != nil {
+		return fmt.Errorf("failed to create zip entry: %w", err)
+	}
+	if _, err := f.Write(pdfBuf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write to zip: %w", err)
+	}
+
+	f, err = zw.Create("passport-index.csv")
+	if err != nil {
+		return fmt.Errorf("failed to create zip entry: %w", err)
+	}
+	batchNames := make(map[uuid.UUID]string, len(pack.Detail.Lines))
+	for _, l := range pack.Detail.Lines {
+		batchNames[l.BatchID] = l.BatchName
+	}
+	cw := csv.NewWriter(f)
+	cw.Write([]string{"serial_number", "passport_id", "batch", "status", "passport_url", "qr_file"})
+	passports := make([]*models.Passport, 0, len(pack.Items))
+	for _, item := range pack.Items {
+		cw.Write([]string{item.SerialNumber, item.PassportID.String(), batchNames[item.BatchID], item.Status,
+			s.passportURL(item.PassportID), "qr/" + item.SerialNumber + ".png"})
+		passports = append(passports, &models.Passport{UUID: item.PassportID, SerialNumber: item.SerialNumber})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write passport index: %w", err)
+	}
+
+	for _, qr := range s.qr.GenerateQRCodesParallel(passports, 20) {
+		if qr.Error != nil || qr.PNGData == nil {
+			continue // Listed in the index; the passport URL still works
+		}
+		f, err := zw.Create("qr/" + qr.Filename)
+		if err != nil {
+			return fmt.Errorf("failed to create zip entry: %w", err)
+		}
+		if _, err := f.Write(qr.PNGData); err != nil {
+			return fmt.Errorf("failed to write to zip: %w", err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to close zip: %w", err)
+	}
+	return nil
+}
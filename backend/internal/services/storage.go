@@ -0,0 +1,14 @@
+package services
+
+import (
+	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// privateDir is where a tenant's restricted files are stored:
+// ./storage/{tenant_id}/{parts...}. Only logos under ./uploads are served
+// publicly; files here are only read by handlers that check access.
+func privateDir(tenantID uuid.UUID, parts ...string) string {
+	return filepath.Join(append([]string{".", "storage", tenantID.String()}, parts...)...)
+}
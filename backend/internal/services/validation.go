@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"exportready-battery/internal/models"
 )
 
 // ============================================================================
@@ -47,6 +49,16 @@ var CountryCodeRegex = regexp.MustCompile(`^[A-Z]{2}$`)
 // Example: 50, 42.5, 100.75
 var CarbonFootprintRegex = regexp.MustCompile(`^\d+(\.\d+)?$`)
 
+// ICAI Membership Number of a chartered accountant
+// Format: 6 digits
+// Example: 123456
+var CAMembershipNoRegex = regexp.MustCompile(`^[0-9]{6}$`)
+
+// UDIN (ICAI Unique Document Identification Number) of a CA-certified document
+// Format: 2-digit year + 6-digit membership number + 10 alphanumeric = 18 chars
+// Example: 25123456BMKPQR4821
+var UDINRegex = regexp.MustCompile(`^[0-9]{8}[A-Z0-9]{10}$`)
+
 // ValidationService provides strict validation for India regulatory fields
 type ValidationService struct{}
 
@@ -262,6 +274,43 @@ func (v *ValidationService) ValidateBISRNumber(bis string) ValidationResult {
 	return ValidationResult{Valid: true, Field: "bis_r_number", Value: bis}
 }
 
+// ValidateCAMembershipNo validates an ICAI membership number (required)
+func (v *ValidationService) ValidateCAMembershipNo(no string) ValidationResult {
+	no = strings.TrimSpace(no)
+	if !CAMembershipNoRegex.MatchString(no) {
+		return ValidationResult{
+			Valid:   false,
+			Field:   "ca_membership_no",
+			Value:   no,
+			Message: "ICAI Membership Number must be 6 digits (e.g., 123456)",
+		}
+	}
+	return ValidationResult{Valid: true, Field: "ca_membership_no", Value: no}
+}
+
+// ValidateUDIN validates the UDIN of a CA certificate (required). The UDIN
+// embeds the membership number of the CA who generated it.
+func (v *ValidationService) ValidateUDIN(udin, membershipNo string) ValidationResult {
+	udin = strings.ToUpper(strings.TrimSpace(udin))
+	if !UDINRegex.MatchString(udin) {
+		return ValidationResult{
+			Valid:   false,
+			Field:   "udin",
+			Value:   udin,
+			Message: "UDIN must be 18 characters: year, membership number and 10 letters or digits (e.g., 25123456BMKPQR4821)",
+		}
+	}
+	if udin[2:8] != strings.TrimSpace(membershipNo) {
+		return ValidationResult{
+			Valid:   false,
+			Field:   "udin",
+			Value:   udin,
+			Message: "UDIN was not generated by ICAI Membership Number " + strings.TrimSpace(membershipNo),
+		}
+	}
+	return ValidationResult{Valid: true, Field: "udin", Value: udin}
+}
+
 // ValidateBatchCompliance validates all India compliance fields for a batch
 func (v *ValidationService) ValidateBatchCompliance(hsn, iec string, isImported bool) ([]ValidationResult, error) {
 	var results []ValidationResult
@@ -300,56 +349,25 @@ func (v *ValidationService) ValidateBatchCompliance(hsn, iec string, isImported
 	return results, nil
 }
 
-// DVA Source constants
-const (
-	DVASourceEstimated = "ESTIMATED"
-	DVASourceAudited   = "AUDITED"
-)
-
-// ValidateDVASource validates the dva_source field
-func (v *ValidationService) ValidateDVASource(source string) ValidationResult {
-	if source == "" || source == DVASourceEstimated {
-		return ValidationResult{Valid: true, Field: "dva_source", Value: source}
-	}
-	if source == DVASourceAudited {
-		return ValidationResult{Valid: true, Field: "dva_source", Value: source}
-	}
-	return ValidationResult{
-		Valid:   false,
-		Field:   "dva_source",
-		Value:   source,
-		Message: "DVA Source must be 'ESTIMATED' or 'AUDITED'",
-	}
-}
-
-// ValidatePLICompliance validates PLI eligibility based on DVA source
-// For AUDITED source, requires auditedDVA >= 50%
-// For ESTIMATED source, logs warning but allows submission
-func (v *ValidationService) ValidatePLICompliance(dvaSource string, auditedDVA *float64, estimatedDVA float64, pliCompliant bool) error {
+// ValidatePLICompliance validates PLI eligibility. An attested DVA (from a CA
+// attestation, never from a request body) must be at least 50%; without one,
+// the estimated DVA must be.
+func (v *ValidationService) ValidatePLICompliance(attestedDVA *float64, estimatedDVA float64, pliCompliant bool) error {
 	if !pliCompliant {
 		return nil // No validation needed if not claiming PLI
 	}
 
-	if dvaSource == DVASourceAudited {
-		if auditedDVA == nil {
-			return errors.New("PLI eligibility with AUDITED source requires an audited DVA value")
+	if attestedDVA != nil {
+		if *attestedDVA < models.PLIMinimumDVA {
+			return fmt.Errorf("PLI eligibility requires Audited DVA >= 50%%. Attested: %.2f%%", *attestedDVA)
 		}
-		if *auditedDVA < 50 {
-			return errors.New("PLI eligibility requires Audited DVA >= 50%. Provided: " + formatFloat(*auditedDVA) + "%")
-		}
-		// Audited DVA is valid for PLI
 		return nil
 	}
 
-	// ESTIMATED source - warn if below threshold but allow (pending CA certification)
-	if estimatedDVA < 50 {
+	// Not yet attested - the estimate must meet the threshold (pending CA certification)
+	if estimatedDVA < models.PLIMinimumDVA {
 		return errors.New("Estimated DVA is below 50%. PLI subsidy claims require CA certification with DVA >= 50%")
 	}
 
 	return nil
 }
-
-// formatFloat formats a float64 to a string with 1 decimal place
-func formatFloat(f float64) string {
-	return fmt.Sprintf("%.1f", f)
-}
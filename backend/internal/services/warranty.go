@@ -0,0 +1,388 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"exportready-battery/internal/models"
+	"exportready-battery/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// WARRANTY SERVICE
+// ============================================================================
+
+// Warranty claim errors, shown to the caller as-is
+var (
+	ErrClaimInvalid  = errors.New("invalid warranty claim")
+	ErrClaimNotFound = errors.New("warranty claim not found")
+	ErrClaimState    = errors.New("warranty claim cannot change from its current status")
+)
+
+const (
+	MaxClaimPhotos       = 6
+	maxClaimDescription  = 2000
+	maxClaimDecisionNote = 1000
+)
+
+// WarrantyService computes warranty coverage and runs warranty claims. A claim
+// drives the passport: submitted → RETURN_REQUESTED, rejected → IN_SERVICE,
+// received against its RMA → RETURNED.
+type WarrantyService struct {
+	repo      *repository.Repository
+	lifecycle *LifecycleService
+	email     *EmailService
+}
+
+// NewWarrantyService creates a new warranty service
+func NewWarrantyService(repo *repository.Repository, lifecycle *LifecycleService, email *EmailService) *WarrantyService {
+	return &WarrantyService{repo: repo, lifecycle: lifecycle, email: email}
+}
+
+// Coverage computes a passport's warranty from its batch's warranty_months
+func (s *WarrantyService) Coverage(ctx context.Context, passport *models.Passport) (*models.WarrantyCoverage, error) {
+	batch, err := s.repo.GetBatch(ctx, passport.BatchID)
+	if err != nil {
+		return nil, err
+	}
+	coverage := models.ComputeWarranty(passport, batch.Specs.WarrantyMonths, time.Now())
+	return &coverage, nil
+}
+
+// SubmitClaimRequest is a claim filed through a magic link
+type SubmitClaimRequest struct {
+	PassportID    uuid.UUID
+	FaultCode     string
+	Description   string
+	StateOfHealth float64 // Literal percentage, 0-100
+	Actor         string  // Magic link email
+	ActorRole     string  // Magic link role; must be allowed to request a return
+}
+
+// ClaimPhoto is an uploaded claim photo; Ext is the validated file extension
+type ClaimPhoto struct {
+	Ext     string
+	Content io.Reader
+}
+
+// SubmitClaim files a warranty claim, stores its photos and moves the passport to
+// RETURN_REQUESTED. Eligibility is recorded, not enforced: out-of-warranty claims
+// are flagged for the manufacturer to decide.
+func (s *WarrantyService) SubmitClaim(ctx context.Context, req SubmitClaimRequest, photos []ClaimPhoto) (*models.WarrantyClaim, error) {
+	req.FaultCode = strings.ToUpper(strings.TrimSpace(req.FaultCode))
+	req.Description = strings.TrimSpace(req.Description)
+
+	if !models.IsValidFaultCode(req.FaultCode) {
+		return nil, fmt.Errorf("%w: fault_code must be one of %s", ErrClaimInvalid, strings.Join(models.FaultCodes, ", "))
+	}
+	if req.StateOfHealth < 0 || req.StateOfHealth > 100 {
+		return nil, fmt.Errorf("%w: state_of_health must be between 0 and 100", ErrClaimInvalid)
+	}
+	if len(req.Description) > maxClaimDescription {
+		return nil, fmt.Errorf("%w: description must be at most %d characters", ErrClaimInvalid, maxClaimDescription)
+	}
+	if len(photos) > MaxClaimPhotos {
+		return nil, fmt.Errorf("%w: at most %d photos", ErrClaimInvalid, MaxClaimPhotos)
+	}
+
+	passport, err := s.repo.GetPassportByUUID(ctx, req.PassportID)
+	if err != nil {
+		return nil, ErrClaimNotFound
+	}
+	batch, err := s.repo.GetBatch(ctx, passport.BatchID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the RETURN_REQUESTED transition up front so no claim is left behind
+	if !models.IsValidTransition(passport.Status, models.PassportStatusReturnRequested) {
+		return nil, fmt.Errorf("%w: a claim cannot be filed while the battery is %s", ErrClaimInvalid, passport.Status)
+	}
+	if req.ActorRole != "" && req.ActorRole != "MANUFACTURER" &&
+		!models.IsValidRoleTransition(req.ActorRole, passport.Status, models.PassportStatusReturnRequested) {
+		return nil, fmt.Errorf("%w: role %s cannot file a claim while the battery is %s", ErrClaimInvalid, req.ActorRole, passport.Status)
+	}
+
+	coverage := models.ComputeWarranty(passport, batch.Specs.WarrantyMonths, time.Now())
+	claim := &models.WarrantyClaim{
+		TenantID:          batch.TenantID,
+		PassportID:        passport.UUID,
+		SerialNumber:      passport.SerialNumber,
+		Status:            models.ClaimStatusSubmitted,
+		FaultCode:         req.FaultCode,
+		Description:       req.Description,
+		StateOfHealth:     req.StateOfHealth,
+		Photos:            []string{},
+		PreviousStatus:    passport.Status,
+		Eligible:          coverage.Active,
+		EligibilityReason: coverage.Reason,
+		WarrantyExpiresAt: coverage.ExpiresAt,
+		SubmittedBy:       req.Actor,
+		SubmittedRole:     req.ActorRole,
+	}
+	if err := s.repo.CreateWarrantyClaim(ctx, claim); err != nil {
+		if errors.Is(err, repository.ErrOpenClaimExists) {
+			return nil, fmt.Errorf("%w: %v", ErrClaimState, err)
+		}
+		return nil, err
+	}
+
+	if len(photos) > 0 {
+		names, err := s.saveClaimPhotos(claim, photos)
+		if err == nil {
+			err = s.repo.SetWarrantyClaimPhotos(ctx, claim.ID, names)
+		}
+		if err != nil {
+			s.discardClaim(ctx, claim)
+			return nil, err
+		}
+		claim.Photos = names
+	}
+
+	result, err := s.lifecycle.TransitionPassport(ctx, TransitionRequest{
+		PassportID: passport.UUID,
+		ToStatus:   models.PassportStatusReturnRequested,
+		Actor:      req.Actor,
+		ActorRole:  req.ActorRole,
+		Metadata: map[string]interface{}{
+			"warranty_claim_id": claim.ID.String(),
+			"fault_code":        claim.FaultCode,
+			"state_of_health":   claim.StateOfHealth,
+			"actor_role":        req.ActorRole,
+			"authenticated_via": "magic_link",
+		},
+	})
+	if err != nil {
+		s.discardClaim(ctx, claim)
+		if result != nil && result.Error != "" {
+			return nil, fmt.Errorf("%w: %s", ErrClaimInvalid, result.Error)
+		}
+		return nil, err
+	}
+
+	// The technician's reading becomes the passport's current State of Health
+	if err := s.repo.UpdatePassportStateOfHealth(ctx, passport.UUID, claim.StateOfHealth); err != nil {
+		log.Printf("Warning: Failed to record claim state of health: %v", err)
+	}
+
+	return claim, nil
+}
+
+// claimPhotoDir is where a claim's photos are stored: ./storage/{tenant_id}/claims/{claim_id}/
+func claimPhotoDir(claim *models.WarrantyClaim) string {
+	return privateDir(claim.TenantID, "claims", claim.ID.String())
+}
+
+// ClaimPhotoPath returns the file path of one of a claim's photos, or "" if the
+// claim has no photo with that name
+func (s *WarrantyService) ClaimPhotoPath(claim *models.WarrantyClaim, name string) string {
+	for _, photo := range claim.Photos {
+		if photo == name {
+			return filepath.Join(claimPhotoDir(claim), photo)
+		}
+	}
+	return ""
+}
+
+// saveClaimPhotos writes the photos as photo-1.jpg, photo-2.png, ...
+func (s *WarrantyService) saveClaimPhotos(claim *models.WarrantyClaim, photos []ClaimPhoto) ([]string, error) {
+	dir := claimPhotoDir(claim)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create claim photo directory: %w", err)
+	}
+
+	names := make([]string, 0, len(photos))
+	for i, photo := range photos {
+		name := fmt.Sprintf("photo-%d%s", i+1, photo.Ext)
+		dst, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to save claim photo: %w", err)
+		}
+		_, err = io.Copy(dst, photo.Content)
+		dst.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to save claim photo: %w", err)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// discardClaim removes a claim (and its photos) whose submission failed part way
+func (s *WarrantyService) discardClaim(ctx context.Context, claim *models.WarrantyClaim) {
+	if err := s.repo.DeleteWarrantyClaim(ctx, claim.ID); err != nil {
+		log.Printf("Warning: Failed to remove incomplete warranty claim %s: %v", claim.ID, err)
+	}
+	if err := os.RemoveAll(claimPhotoDir(claim)); err != nil {
+		log.Printf("Warning: Failed to remove photos of warranty claim %s: %v", claim.ID, err)
+	}
+}
+
+// GetOpenClaim returns the passport's submitted or approved claim, or nil
+func (s *WarrantyService) GetOpenClaim(ctx context.Context, passportID uuid.UUID) (*models.WarrantyClaim, error) {
+	return s.repo.GetOpenWarrantyClaim(ctx, passportID)
+}
+
+// GetClaim retrieves a tenant's claim
+func (s *WarrantyService) GetClaim(ctx context.Context, tenantID, claimID uuid.UUID) (*models.WarrantyClaim, error) {
+	claim, err := s.repo.GetWarrantyClaim(ctx, tenantID, claimID)
+	if err != nil {
+		return nil, err
+	}
+	if claim == nil {
+		return nil, ErrClaimNotFound
+	}
+	return claim, nil
+}
+
+// ListClaims retrieves a tenant's claims, newest first
+func (s *WarrantyService) ListClaims(ctx context.Context, tenantID uuid.UUID, status string, page repository.PageRequest) ([]*models.WarrantyClaim, repository.PageInfo, error) {
+	return s.repo.ListWarrantyClaims(ctx, tenantID, status, page)
+}
+
+// ApproveClaim approves a submitted claim and issues its RMA number. The passport
+// stays RETURN_REQUESTED until the unit is received.
+func (s *WarrantyService) ApproveClaim(ctx context.Context, tenantID, claimID uuid.UUID, actor, note string) (*models.WarrantyClaim, error) {
+	note, err := decisionNote(note)
+	if err != nil {
+		return nil, err
+	}
+	claim, err := s.GetClaim(ctx, tenantID, claimID)
+	if err != nil {
+		return nil, err
+	}
+
+	rma, err := generateRMANumber()
+	if err != nil {
+		return nil, err
+	}
+	decided, err := s.repo.DecideWarrantyClaim(ctx, claim.ID, models.ClaimStatusApproved, rma, actor, note)
+	if err != nil {
+		return nil, err
+	}
+	if !decided {
+		return nil, ErrClaimState
+	}
+
+	claim, err = s.GetClaim(ctx, tenantID, claimID)
+	if err != nil {
+		return nil, err
+	}
+	s.sendDecision(claim)
+	return claim, nil
+}
+
+// RejectClaim rejects a submitted claim and puts the passport back in service
+func (s *WarrantyService) RejectClaim(ctx context.Context, tenantID, claimID uuid.UUID, actor, note string) (*models.WarrantyClaim, error) {
+	note, err := decisionNote(note)
+	if err != nil {
+		return nil, err
+	}
+	if note == "" {
+		return nil, fmt.Errorf("%w: a note explaining the rejection is required", ErrClaimInvalid)
+	}
+	claim, err := s.GetClaim(ctx, tenantID, claimID)
+	if err != nil {
+		return nil, err
+	}
+
+	decided, err := s.repo.DecideWarrantyClaim(ctx, claim.ID, models.ClaimStatusRejected, "", actor, note)
+	if err != nil {
+		return nil, err
+	}
+	if !decided {
+		return nil, ErrClaimState
+	}
+
+	s.transitionForClaim(ctx, claim, models.PassportStatusReturnRequested, models.PassportStatusInService, actor)
+
+	claim, err = s.GetClaim(ctx, tenantID, claimID)
+	if err != nil {
+		return nil, err
+	}
+	s.sendDecision(claim)
+	return claim, nil
+}
+
+// ReceiveClaim records that the unit arrived against its RMA; the passport becomes RETURNED
+func (s *WarrantyService) ReceiveClaim(ctx context.Context, tenantID, claimID uuid.UUID, actor string) (*models.WarrantyClaim, error) {
+	claim, err := s.GetClaim(ctx, tenantID, claimID)
+	if err != nil {
+		return nil, err
+	}
+
+	received, err := s.repo.MarkWarrantyClaimReceived(ctx, claim.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !received {
+		return nil, ErrClaimState
+	}
+
+	s.transitionForClaim(ctx, claim, models.PassportStatusReturnRequested, models.PassportStatusReturned, actor)
+
+	return s.GetClaim(ctx, tenantID, claimID)
+}
+
+// transitionForClaim moves the claim's passport from one status to another. A
+// passport that has since moved on (e.g. the technician completed the return
+// through a magic link) is left alone.
+func (s *WarrantyService) transitionForClaim(ctx context.Context, claim *models.WarrantyClaim, from, to, actor string) {
+	passport, err := s.repo.GetPassportByUUID(ctx, claim.PassportID)
+	if err != nil {
+		log.Printf("Warning: Failed to load passport for warranty claim %s: %v", claim.ID, err)
+		return
+	}
+	if passport.Status != from {
+		return
+	}
+
+	if _, err := s.lifecycle.TransitionPassport(ctx, TransitionRequest{
+		PassportID: claim.PassportID,
+		ToStatus:   to,
+		Actor:      actor,
+		Metadata:   map[string]interface{}{"warranty_claim_id": claim.ID.String()},
+	}); err != nil {
+		log.Printf("Warning: Failed to move passport %s to %s for warranty claim %s: %v", claim.PassportID, to, claim.ID, err)
+	}
+}
+
+// sendDecision emails the technician who filed the claim (non-critical)
+func (s *WarrantyService) sendDecision(claim *models.WarrantyClaim) {
+	if s.email == nil {
+		return
+	}
+	go func() {
+		if err := s.email.SendWarrantyClaimDecision(claim.SubmittedBy, claim); err != nil {
+			log.Printf("Failed to send warranty claim decision for %s: %v", claim.ID, err)
+		}
+	}()
+}
+
+func decisionNote(note string) (string, error) {
+	note = strings.TrimSpace(note)
+	if len(note) > maxClaimDecisionNote {
+		return "", fmt.Errorf("%w: note must be at most %d characters", ErrClaimInvalid, maxClaimDecisionNote)
+	}
+	return note, nil
+}
+
+// generateRMANumber returns an RMA number like RMA-202610-3F9A1C7E
+func generateRMANumber() (string, error) {
+	randomBytes := make([]byte, 4)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", fmt.Errorf("failed to generate RMA number: %w", err)
+	}
+	return fmt.Sprintf("RMA-%s-%s", time.Now().UTC().Format("200601"), strings.ToUpper(hex.EncodeToString(randomBytes))), nil
+}
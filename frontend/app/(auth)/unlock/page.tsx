@@ -0,0 +1,20 @@
+"use client"
+
+import { Suspense } from "react"
+import { UnlockAccountForm } from "@/components/auth/unlock-account-form"
+
+function UnlockContent() {
+    return <UnlockAccountForm />
+}
+
+export default function UnlockPage() {
+    return (
+        <Suspense fallback={
+            <div className="min-h-screen flex items-center justify-center bg-black">
+                <div className="animate-spin rounded-full h-8 w-8 border-b-2 border-purple-500"></div>
+            </div>
+        }>
+            <UnlockContent />
+        </Suspense>
+    )
+}
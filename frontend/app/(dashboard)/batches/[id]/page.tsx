@@ -8,13 +8,14 @@ import { Button } from "@/components/ui/button"
 import { Card, CardContent, CardHeader, CardTitle, CardDescription } from "@/components/ui/card"
 import { Badge } from "@/components/ui/badge"
 import { UploadCSV } from "@/components/batches/upload-csv"
-import { ArrowLeft, Download, QrCode, FileSpreadsheet, ChevronLeft, ChevronRight, Leaf, Flag, Globe, AlertTriangle, CheckCircle, Zap, Printer, Factory, Scale, Atom, Battery, FileText, Lock, Unlock, Clock, Shield, FileCheck } from "lucide-react"
+import { ArrowLeft, Download, QrCode, FileSpreadsheet, ChevronLeft, ChevronRight, Leaf, Flag, Globe, AlertTriangle, CheckCircle, Zap, Printer, Factory, Scale, Atom, Battery, FileText, Lock, Unlock, Clock, Shield } from "lucide-react"
 import { PassportList } from "@/components/batches/passport-list"
 import { DownloadLabelsDialog } from "@/components/batches/DownloadLabelsDialog"
+import { PLIAuditPanel } from "@/components/batches/pli-audit-panel"
 import { toast } from "sonner"
 
 // Market region type
-type MarketRegion = "INDIA" | "EU" | "GLOBAL"
+type MarketRegion = "INDIA" | "EU" | "US" | "UK" | "GLOBAL"
 
 interface PaginationInfo {
     page: number
@@ -177,10 +178,16 @@ export default function BatchDetailsPage() {
                     </Badge>
                 )
             }
+        } else if (region === "US" || region === "UK") {
+            return (
+                <Badge className="bg-purple-500/20 text-purple-400 hover:bg-purple-500/30 border-purple-500/30 text-sm px-3 py-1">
+                    {region === "US" ? "🇺🇸 US Export" : "🇬🇧 UK Export"}
+                </Badge>
+            )
         } else {
             return (
                 <Badge className="bg-emerald-500/20 text-emerald-400 hover:bg-emerald-500/30 border-emerald-500/30 text-sm px-3 py-1">
-                    <Globe className="h-3 w-3 mr-1" /> Global
+                    <Globe className="h-3 w-3 mr-1" /> {batch?.markets?.length ? batch.markets.join(" · ") : "Global"}
                 </Badge>
             )
         }
@@ -244,6 +251,10 @@ export default function BatchDetailsPage() {
     const region = batch.market_region as MarketRegion
     const isIndia = region === "INDIA"
     const isEU = region === "EU"
+    // Target markets; GLOBAL batches list several
+    const markets: MarketRegion[] = batch.markets?.length ? batch.markets : [region]
+    const targetsIndia = markets.includes("INDIA")
+    const targetsEU = markets.includes("EU")
 
     return (
         <div className="min-h-screen bg-slate-950 text-slate-100 p-8 font-sans">
@@ -348,6 +359,26 @@ export default function BatchDetailsPage() {
                                         <dt className="text-slate-500 mb-1 text-xs uppercase tracking-wider">Weight</dt>
                                         <dd className="font-semibold text-white">{batch.specs.weight || 'N/A'}</dd>
                                     </div>
+                                    <div className="p-3 rounded-lg bg-slate-800/50 border border-slate-700/50">
+                                        <dt className="text-slate-500 mb-1 text-xs uppercase tracking-wider">Battery Category</dt>
+                                        <dd className="font-semibold text-white">
+                                            {batch.specs.battery_category || <span className="text-amber-400">Not classified</span>}
+                                            {batch.specs.epr_category && (
+                                                <span className="ml-2 text-xs text-slate-400">EPR: {batch.specs.epr_category}</span>
+                                            )}
+                                        </dd>
+                                    </div>
+                                    {batch.specs.ratings?.rated_energy_wh && (
+                                        <div className="p-3 rounded-lg bg-slate-800/50 border border-slate-700/50">
+                                            <dt className="text-slate-500 mb-1 text-xs uppercase tracking-wider">Rated Energy</dt>
+                                            <dd className="font-semibold text-white">
+                                                {batch.specs.ratings.rated_energy_wh} Wh
+                                                {batch.specs.ratings.specific_energy_wh_kg && (
+                                                    <span className="ml-2 text-xs text-slate-400">{batch.specs.ratings.specific_energy_wh_kg} Wh/kg</span>
+                                                )}
+                                            </dd>
+                                        </div>
+                                    )}
                                     <div className="p-3 rounded-lg bg-slate-800/50 border border-slate-700/50">
                                         <dt className="text-slate-500 mb-1 text-xs uppercase tracking-wider">Country of Origin</dt>
                                         <dd className="font-semibold text-white">{batch.specs.country_of_origin || 'N/A'}</dd>
@@ -379,7 +410,7 @@ export default function BatchDetailsPage() {
                         </Card>
 
                         {/* Market-Specific Compliance Card - INDIA DARK THEME */}
-                        {isIndia && (
+                        {targetsIndia && (
                             <Card className="bg-slate-900/80 border-slate-800 border-l-4 border-l-orange-500">
                                 <CardHeader>
                                     <CardTitle className="flex items-center gap-2 text-white">
@@ -417,21 +448,6 @@ export default function BatchDetailsPage() {
                                             {batch.domestic_value_add >= 50 && (
                                                 <span className="text-xs text-emerald-400 mt-1 block">✓ PLI Eligible</span>
                                             )}
-
-                                            {/* Certificate Download Link */}
-                                            {batch.dva_source === "AUDITED" && batch.pli_certificate_url && (
-                                                <div className="mt-3 pt-3 border-t border-slate-700/50">
-                                                    <a
-                                                        href={batch.pli_certificate_url}
-                                                        target="_blank"
-                                                        rel="noreferrer"
-                                                        className="text-xs flex items-center gap-2 text-blue-400 hover:text-blue-300 transition-colors"
-                                                    >
-                                                        <FileCheck className="h-3.5 w-3.5" />
-                                                        View Auditor Certificate
-                                                    </a>
-                                                </div>
-                                            )}
                                         </div>
                                         <div className="p-4 rounded-xl bg-slate-800/50 border border-slate-700/50">
                                             <dt className="text-slate-500 mb-2 text-xs uppercase tracking-wider flex items-center gap-2">
@@ -524,8 +540,13 @@ export default function BatchDetailsPage() {
                             </Card>
                         )}
 
+                        {/* PLI DVA Attestation - CA audit requests and audit log (domestic cells only) */}
+                        {targetsIndia && batch.cell_source !== "IMPORTED" && (
+                            <PLIAuditPanel batchId={batch.id} onChange={fetchBatch} />
+                        )}
+
                         {/* Market-Specific Compliance Card - EU DARK THEME */}
-                        {isEU && (
+                        {targetsEU && (
                             <Card className="bg-slate-900/80 border-slate-800 border-l-4 border-l-blue-500">
                                 <CardHeader>
                                     <CardTitle className="flex items-center gap-2 text-white">
@@ -546,10 +567,22 @@ export default function BatchDetailsPage() {
                                                 <span className="text-xl text-emerald-400">{batch.specs.carbon_footprint || 'N/A'}</span>
                                                 {batch.specs.carbon_footprint && (
                                                     <span className="text-xs bg-emerald-500/20 text-emerald-400 px-2 py-1 rounded-full border border-emerald-500/30">
-                                                        CO₂e Certified
+                                                        {batch.specs.carbon_footprint_detail
+                                                            ? `Class ${batch.specs.carbon_footprint_detail.performance_class}`
+                                                            : "CO₂e Certified"}
                                                     </span>
                                                 )}
                                             </dd>
+                                            {batch.specs.carbon_footprint_detail && (
+                                                <ul className="mt-3 space-y-1 text-xs text-slate-400">
+                                                    {batch.specs.carbon_footprint_detail.stages.map((stage: any) => (
+                                                        <li key={stage.stage} className="flex justify-between">
+                                                            <span>{stage.stage.replace(/_/g, " ").toLowerCase()}</span>
+                                                            <span className="text-slate-300">{stage.kg_co2e_per_kwh} kg/kWh</span>
+                                                        </li>
+                                                    ))}
+                                                </ul>
+                                            )}
                                         </div>
                                         <div className="p-4 rounded-xl bg-slate-800/50 border border-slate-700/50">
                                             <dt className="text-slate-500 mb-2 text-xs uppercase tracking-wider">Recyclable</dt>
@@ -805,13 +838,13 @@ export default function BatchDetailsPage() {
                             <CardContent className="pt-6">
                                 <div className="text-center">
                                     <div className="text-5xl mb-3">
-                                        {isIndia ? "🇮🇳" : isEU ? "🇪🇺" : "🌍"}
+                                        {isIndia ? "🇮🇳" : isEU ? "🇪🇺" : region === "US" ? "🇺🇸" : region === "UK" ? "🇬🇧" : "🌍"}
                                     </div>
                                     <p className="font-bold text-xl text-white">
-                                        {isIndia ? "India Market" : isEU ? "EU Export" : "Global"}
+                                        {isIndia ? "India Market" : isEU ? "EU Export" : region === "US" ? "US Export" : region === "UK" ? "UK Export" : "Global"}
                                     </p>
                                     <p className="text-sm text-slate-500 mt-1">
-                                        {isIndia ? "Battery Aadhaar Compliant" : isEU ? "EU Battery Regulation" : "Multi-Market Ready"}
+                                        {isIndia ? "Battery Aadhaar Compliant" : isEU ? "EU Battery Regulation" : region === "US" ? "UL Listing & IRA Sourcing" : region === "UK" ? "UKCA Marking" : markets.join(" · ")}
                                     </p>
                                 </div>
                             </CardContent>
@@ -44,7 +44,7 @@ import {
 import { toast } from "sonner"
 
 // Types
-type MarketRegion = "INDIA" | "EU" | "GLOBAL"
+type MarketRegion = "INDIA" | "EU" | "US" | "UK" | "GLOBAL"
 type FilterType = "ALL" | "DRAFT" | "ACTIVE"
 
 interface Batch {
@@ -54,6 +54,7 @@ interface Batch {
     specs: any
     status?: string
     market_region?: MarketRegion
+    markets?: MarketRegion[]
     pli_compliant?: boolean
     total_passports?: number
     domestic_value_add?: number
@@ -386,13 +387,20 @@ export default function BatchesPage() {
                                                     <span className="fi fi-in text-2xl rounded shadow-sm" title="India" />
                                                 ) : batch.market_region === "EU" ? (
                                                     <span className="fi fi-eu text-2xl rounded shadow-sm" title="European Union" />
+                                                ) : batch.market_region === "US" ? (
+                                                    <span className="fi fi-us text-2xl rounded shadow-sm" title="United States" />
+                                                ) : batch.market_region === "UK" ? (
+                                                    <span className="fi fi-gb text-2xl rounded shadow-sm" title="United Kingdom" />
                                                 ) : (
                                                     <Globe className="h-6 w-6 text-slate-400" />
                                                 )}
                                                 <div className="flex flex-col">
                                                     <span className="text-sm text-white font-medium">
                                                         {batch.market_region === "INDIA" ? "India" :
-                                                            batch.market_region === "EU" ? "European Union" : "Global"}
+                                                            batch.market_region === "EU" ? "European Union" :
+                                                                batch.market_region === "US" ? "United States" :
+                                                                    batch.market_region === "UK" ? "United Kingdom" :
+                                                                        batch.markets?.length ? batch.markets.join(" · ") : "Global"}
                                                     </span>
                                                     {batch.pli_compliant && (
                                                         <Badge className="bg-orange-500/15 text-orange-400 border-orange-500/25 text-xs px-2 py-0 h-5 w-fit mt-1">
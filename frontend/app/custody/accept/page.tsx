@@ -0,0 +1,197 @@
+"use client"
+
+import { Suspense, useEffect, useState } from "react"
+import { useSearchParams } from "next/navigation"
+import { motion } from "framer-motion"
+import { Loader2, CheckCircle, AlertCircle, Package, XCircle, FileText } from "lucide-react"
+
+interface CustodyAcceptance {
+    group_id: string
+    status: string
+    to: { email: string; name: string; type: string }
+    document_ref?: string
+    note?: string
+    count: number
+    transfers: { id: string; passport_id: string; serial_number: string }[]
+}
+
+function CustodyAcceptContent() {
+    const searchParams = useSearchParams()
+    const token = searchParams.get("token")
+
+    const [loading, setLoading] = useState(true)
+    const [acceptance, setAcceptance] = useState<CustodyAcceptance | null>(null)
+    const [error, setError] = useState("")
+    const [note, setNote] = useState("")
+    const [submitting, setSubmitting] = useState<"ACCEPT" | "REJECT" | null>(null)
+
+    const apiUrl = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080/api/v1"
+
+    useEffect(() => {
+        if (!token) {
+            setError("No transfer token provided. Please use the link from your email.")
+            setLoading(false)
+            return
+        }
+
+        const fetchTransfer = async () => {
+            try {
+                const response = await fetch(`${apiUrl}/custody/accept?token=${token}`)
+                const data = await response.json()
+                if (!response.ok) {
+                    throw new Error(data.error || "Transfer not found")
+                }
+                setAcceptance(data)
+            } catch (err: any) {
+                setError(err.message || "Invalid transfer link")
+            } finally {
+                setLoading(false)
+            }
+        }
+
+        fetchTransfer()
+    }, [apiUrl, token])
+
+    const respond = async (decision: "ACCEPT" | "REJECT") => {
+        setSubmitting(decision)
+        try {
+            const response = await fetch(`${apiUrl}/custody/accept`, {
+                method: "POST",
+                headers: { "Content-Type": "application/json" },
+                body: JSON.stringify({ token, decision, note })
+            })
+            const data = await response.json()
+            if (!response.ok) {
+                throw new Error(data.error || "Failed to respond to transfer")
+            }
+            setAcceptance(data)
+        } catch (err: any) {
+            setError(err.message || "Failed to respond to transfer")
+        } finally {
+            setSubmitting(null)
+        }
+    }
+
+    if (loading) {
+        return (
+            <div className="min-h-screen bg-slate-950 flex items-center justify-center p-4">
+                <div className="flex flex-col items-center gap-4">
+                    <Loader2 className="h-10 w-10 text-emerald-400 animate-spin" />
+                    <p className="text-slate-400">Loading transfer...</p>
+                </div>
+            </div>
+        )
+    }
+
+    if (error || !acceptance) {
+        return (
+            <div className="min-h-screen bg-slate-950 flex items-center justify-center p-4">
+                <motion.div
+                    initial={{ opacity: 0, y: 20 }}
+                    animate={{ opacity: 1, y: 0 }}
+                    className="max-w-md w-full bg-slate-900/80 backdrop-blur-xl rounded-2xl border border-red-500/20 p-8 text-center"
+                >
+                    <div className="mx-auto w-16 h-16 rounded-full bg-red-500/10 flex items-center justify-center mb-6">
+                        <AlertCircle className="h-8 w-8 text-red-400" />
+                    </div>
+                    <h2 className="text-xl font-bold text-white mb-2">Transfer Unavailable</h2>
+                    <p className="text-slate-400">{error}</p>
+                </motion.div>
+            </div>
+        )
+    }
+
+    const pending = acceptance.status === "PENDING"
+
+    return (
+        <div className="min-h-screen bg-slate-950 py-8 px-4">
+            <div className="relative z-10 max-w-lg mx-auto">
+                <motion.div
+                    initial={{ opacity: 0, y: -20 }}
+                    animate={{ opacity: 1, y: 0 }}
+                    className="text-center mb-8"
+                >
+                    <div className="flex items-center justify-center gap-2 mb-4">
+                        <Package className="h-6 w-6 text-emerald-400" />
+                        <span className="text-slate-400 text-sm">Chain of Custody</span>
+                    </div>
+                    <h1 className="text-2xl font-bold text-white mb-2">
+                        {acceptance.count} {acceptance.count === 1 ? "battery" : "batteries"} for {acceptance.to.name}
+                    </h1>
+                    {acceptance.document_ref && (
+                        <p className="text-slate-500 flex items-center justify-center gap-1">
+                            <FileText className="h-4 w-4" />
+                            <code className="text-slate-400">{acceptance.document_ref}</code>
+                        </p>
+                    )}
+                </motion.div>
+
+                {!pending && (
+                    <div className={`rounded-xl border p-4 mb-6 flex items-center gap-3 ${acceptance.status === "ACCEPTED"
+                        ? "bg-emerald-500/10 border-emerald-500/20 text-emerald-400"
+                        : "bg-slate-900/60 border-slate-800 text-slate-300"
+                        }`}
+                    >
+                        {acceptance.status === "ACCEPTED" ? <CheckCircle className="h-5 w-5" /> : <XCircle className="h-5 w-5" />}
+                        <p>This transfer is {acceptance.status.toLowerCase()}.</p>
+                    </div>
+                )}
+
+                {acceptance.note && (
+                    <div className="bg-slate-900/60 rounded-xl border border-slate-800 p-4 mb-6">
+                        <p className="text-slate-500 text-sm mb-1">Note from the sender</p>
+                        <p className="text-white">{acceptance.note}</p>
+                    </div>
+                )}
+
+                <div className="bg-slate-900/60 rounded-xl border border-slate-800 p-4 mb-6 max-h-72 overflow-y-auto">
+                    <p className="text-slate-500 text-sm mb-2">Serial numbers</p>
+                    <ul className="space-y-1">
+                        {acceptance.transfers.map((t) => (
+                            <li key={t.id} className="font-mono text-sm text-slate-300">{t.serial_number}</li>
+                        ))}
+                    </ul>
+                </div>
+
+                {pending && (
+                    <>
+                        <textarea
+                            placeholder="Note (optional), e.g. damaged or missing units"
+                            value={note}
+                            onChange={(e) => setNote(e.target.value)}
+                            rows={2}
+                            className="w-full mb-4 px-4 py-3 bg-slate-800/50 border border-slate-700 rounded-lg text-white placeholder-slate-500 focus:outline-none focus:border-emerald-500 resize-none"
+                        />
+                        <button
+                            onClick={() => respond("ACCEPT")}
+                            disabled={submitting !== null}
+                            className="w-full py-4 rounded-xl font-semibold text-white bg-emerald-600 hover:bg-emerald-500 transition-all flex items-center justify-center gap-2"
+                        >
+                            {submitting === "ACCEPT" ? <Loader2 className="h-5 w-5 animate-spin" /> : <CheckCircle className="h-5 w-5" />}
+                            Confirm Receipt
+                        </button>
+                        <button
+                            onClick={() => respond("REJECT")}
+                            disabled={submitting !== null}
+                            className="w-full mt-3 py-3 text-slate-400 hover:text-white transition-colors"
+                        >
+                            {submitting === "REJECT" ? "Declining..." : "Decline Transfer"}
+                        </button>
+                    </>
+                )}
+            </div>
+        </div>
+    )
+}
+
+export default function CustodyAcceptPage() {
+    return (
+        <Suspense fallback={
+            <div className="min-h-screen bg-slate-950 flex items-center justify-center">
+                <Loader2 className="h-8 w-8 text-emerald-400 animate-spin" />
+            </div>
+        }>
+            <CustodyAcceptContent />
+        </Suspense>
+    )
+}
@@ -158,7 +158,8 @@ export default function DatabasePage() {
                         { name: 'tenant_id', type: 'UUID', nullable: false, description: 'FK to tenants.id' },
                         { name: 'batch_name', type: 'VARCHAR(100)', nullable: false, description: 'User-defined name' },
                         { name: 'specs', type: 'JSONB', nullable: false, description: 'Battery specifications' },
-                        { name: 'market_region', type: 'VARCHAR(20)', nullable: false, description: 'INDIA, EU, or GLOBAL' },
+                        { name: 'market_region', type: 'VARCHAR(20)', nullable: false, description: 'INDIA, EU, US, UK, or GLOBAL (several markets)' },
+                        { name: 'markets', type: 'TEXT[]', nullable: false, description: 'Target markets (rule packs)' },
                         { name: 'pli_compliant', type: 'BOOLEAN', nullable: true, description: 'PLI eligibility (India)' },
                         { name: 'domestic_value_add', type: 'DECIMAL(5,2)', nullable: true, description: 'DVA percentage' },
                         { name: 'cell_source', type: 'VARCHAR(20)', nullable: true, description: 'IMPORTED or DOMESTIC' },
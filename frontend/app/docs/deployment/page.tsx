@@ -143,6 +143,12 @@ export default function DeploymentPage() {
                             required={false}
                             description="Razorpay API secret key."
                         />
+                        <EnvVar
+                            name="TRUSTED_PROXIES"
+                            example="127.0.0.1,10.0.0.0/8"
+                            required={false}
+                            description="Reverse proxies (IPs or CIDRs) whose X-Forwarded-For header gives the client IP for API key IP restrictions and rate limits. Unset, the connection address is used."
+                        />
                     </div>
 
                     <CodeBlock
@@ -153,7 +159,8 @@ PORT=8080
 FRONTEND_URL=https://app.exportready.com
 QR_BASE_URL=https://app.exportready.com
 RAZORPAY_KEY_ID=rzp_live_xxxx
-RAZORPAY_KEY_SECRET=xxxx`}
+RAZORPAY_KEY_SECRET=xxxx
+TRUSTED_PROXIES=127.0.0.1`}
                         language="bash"
                         title=".env"
                     />
@@ -20,8 +20,27 @@ interface PassportInfo {
         role: string
     }
     allowed_transitions: string[]
+    warranty?: {
+        months: number
+        start_basis?: string
+        expires_at?: string
+        active: boolean
+        remaining_months: number
+        remaining_days: number
+        reason?: string
+    }
+    open_claim?: {
+        id: string
+        status: string
+        fault_code: string
+        rma_number?: string
+        created_at: string
+    }
+    fault_codes?: string[]
 }
 
+const MAX_CLAIM_PHOTOS = 6
+
 const STATUS_CONFIG: Record<string, { label: string; icon: any; color: string; description: string }> = {
     SHIPPED: {
         label: "Mark as Shipped",
@@ -78,6 +97,10 @@ export default function PassportActionPage() {
     const [success, setSuccess] = useState(false)
     const [pointsAwarded, setPointsAwarded] = useState(0)
     const [partnerCode, setPartnerCode] = useState("")
+    const [faultCode, setFaultCode] = useState("")
+    const [stateOfHealth, setStateOfHealth] = useState("")
+    const [photos, setPhotos] = useState<File[]>([])
+    const [claimSubmitted, setClaimSubmitted] = useState(false)
 
     useEffect(() => {
         if (!token) {
@@ -108,9 +131,59 @@ export default function PassportActionPage() {
         fetchInfo()
     }, [passportId, token])
 
+    // Returns are filed as warranty claims when the server supports them
+    const isWarrantyClaim = selectedStatus === "RETURN_REQUESTED" && !!info?.fault_codes
+
+    const handleWarrantyClaim = async () => {
+        if (!token) return
+
+        if (!faultCode || stateOfHealth.trim() === "") {
+            setError("Fault code and state of health are required for a warranty claim")
+            return
+        }
+
+        setSubmitting(true)
+        try {
+            const apiUrl = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080/api/v1"
+
+            const form = new FormData()
+            form.append("fault_code", faultCode)
+            form.append("state_of_health", stateOfHealth.trim())
+            if (metadata.notes) {
+                form.append("description", metadata.notes)
+            }
+            photos.forEach((photo) => form.append("photos", photo))
+
+            const response = await fetch(`${apiUrl}/passport/${passportId}/warranty-claim`, {
+                method: "POST",
+                headers: {
+                    "Authorization": `Bearer ${token}`
+                },
+                body: form
+            })
+
+            const data = await response.json()
+
+            if (!response.ok) {
+                throw new Error(data.error || "Warranty claim failed")
+            }
+
+            setClaimSubmitted(true)
+            setSuccess(true)
+        } catch (err: any) {
+            setError(err.message || "Failed to submit warranty claim")
+        } finally {
+            setSubmitting(false)
+        }
+    }
+
     const handleTransition = async () => {
         if (!selectedStatus || !token) return
 
+        if (isWarrantyClaim) {
+            return handleWarrantyClaim()
+        }
+
         // Check if UNVERIFIED user needs partner code
         if (info?.actor?.role === "UNVERIFIED" && !partnerCode.trim()) {
             setError("Partner code is required for unverified accounts")
@@ -230,6 +303,11 @@ export default function PassportActionPage() {
                     <p className="text-slate-400 mb-2">
                         Battery status changed to <span className="text-emerald-400 font-semibold">{config.label}</span>
                     </p>
+                    {claimSubmitted && (
+                        <p className="text-slate-400 text-sm mb-2">
+                            Your warranty claim has been sent to the manufacturer. You will receive an email with the RMA number once it is approved.
+                        </p>
+                    )}
                     <p className="text-slate-500 text-sm mb-4">
                         This action has been recorded to the audit trail.
                     </p>
@@ -321,6 +399,34 @@ export default function PassportActionPage() {
                     <p className="text-lg font-semibold text-white">{info?.passport?.status}</p>
                 </motion.div>
 
+                {/* Warranty */}
+                {info?.warranty && (
+                    <motion.div
+                        initial={{ opacity: 0, y: 20 }}
+                        animate={{ opacity: 1, y: 0 }}
+                        transition={{ delay: 0.18 }}
+                        className={`bg-slate-900/60 backdrop-blur-xl rounded-xl border p-4 mb-6 ${info.warranty.active ? "border-emerald-500/20" : "border-slate-800"}`}
+                    >
+                        <p className="text-slate-500 text-sm mb-1">Warranty</p>
+                        {info.warranty.active ? (
+                            <p className="text-lg font-semibold text-emerald-400">
+                                {info.warranty.remaining_months} months left
+                                <span className="text-slate-500 text-sm font-normal ml-2">
+                                    until {new Date(info.warranty.expires_at!).toLocaleDateString()}
+                                </span>
+                            </p>
+                        ) : (
+                            <p className="text-lg font-semibold text-slate-300">{info.warranty.reason}</p>
+                        )}
+                        {info.open_claim && (
+                            <p className="text-amber-400 text-sm mt-2">
+                                Open claim: {info.open_claim.fault_code.replace(/_/g, " ")} ({info.open_claim.status})
+                                {info.open_claim.rma_number && <> · RMA <code>{info.open_claim.rma_number}</code></>}
+                            </p>
+                        )}
+                    </motion.div>
+                )}
+
                 {/* Available Transitions */}
                 <motion.div
                     initial={{ opacity: 0, y: 20 }}
@@ -396,7 +502,44 @@ export default function PassportActionPage() {
                             />
                         )}
 
-                        {selectedStatus === "RETURN_REQUESTED" && (
+                        {isWarrantyClaim && (
+                            <div className="space-y-3">
+                                <select
+                                    value={faultCode}
+                                    onChange={(e) => setFaultCode(e.target.value)}
+                                    className="w-full px-4 py-3 bg-slate-800/50 border border-slate-700 rounded-lg text-white focus:outline-none focus:border-emerald-500"
+                                >
+                                    <option value="">Select fault code</option>
+                                    {info?.fault_codes?.map((code) => (
+                                        <option key={code} value={code}>{code.replace(/_/g, " ")}</option>
+                                    ))}
+                                </select>
+                                <input
+                                    type="number"
+                                    min={0}
+                                    max={100}
+                                    step={0.1}
+                                    placeholder="State of Health reading (%)"
+                                    value={stateOfHealth}
+                                    onChange={(e) => setStateOfHealth(e.target.value)}
+                                    className="w-full px-4 py-3 bg-slate-800/50 border border-slate-700 rounded-lg text-white placeholder-slate-500 focus:outline-none focus:border-emerald-500"
+                                />
+                                <div>
+                                    <input
+                                        type="file"
+                                        accept="image/jpeg,image/png,image/webp"
+                                        multiple
+                                        onChange={(e) => setPhotos(Array.from(e.target.files || []).slice(0, MAX_CLAIM_PHOTOS))}
+                                        className="w-full text-sm text-slate-400 file:mr-3 file:px-4 file:py-2 file:rounded-lg file:border-0 file:bg-slate-800 file:text-white"
+                                    />
+                                    <p className="text-slate-500 text-xs mt-1">
+                                        Up to {MAX_CLAIM_PHOTOS} photos, 5MB each{photos.length > 0 && ` · ${photos.length} selected`}
+                                    </p>
+                                </div>
+                            </div>
+                        )}
+
+                        {selectedStatus === "RETURN_REQUESTED" && !isWarrantyClaim && (
                             <input
                                 type="text"
                                 placeholder="Reason (e.g. Warranty, Defect, End of Life)"
@@ -472,7 +615,7 @@ export default function PassportActionPage() {
                         </>
                     ) : (
                         <>
-                            Confirm Status Update
+                            {isWarrantyClaim ? "Submit Warranty Claim" : "Confirm Status Update"}
                             <ArrowRight className="h-5 w-5" />
                         </>
                     )}
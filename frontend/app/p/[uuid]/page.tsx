@@ -5,7 +5,7 @@ import { useParams } from "next/navigation"
 import api from "@/lib/api"
 import { CertificateView } from "@/components/passport/certificate-view"
 import { RequestMagicLinkModal } from "@/components/passport/request-magic-link-modal"
-import { AlertCircle, Loader2, CheckCircle, Download, Wrench } from "lucide-react"
+import { AlertCircle, AlertTriangle, Loader2, CheckCircle, Download, Wrench } from "lucide-react"
 
 export default function PublicPassportPage() {
     const params = useParams()
@@ -121,6 +121,32 @@ export default function PublicPassportPage() {
             {/* Main Content - The "Paper" */}
             <main className="px-4 md:px-6 pt-6 md:pt-8">
                 <div className="max-w-4xl mx-auto">
+                    {/* Safety Recall Banner */}
+                    {passport.recall && (
+                        <div className="mb-6 rounded-lg border-2 border-red-300 bg-red-50 p-4 md:p-5 print:border-red-600">
+                            <div className="flex items-start gap-3">
+                                <AlertTriangle className="h-6 w-6 text-red-600 shrink-0 mt-0.5" />
+                                <div className="space-y-1">
+                                    <p className="text-xs font-bold uppercase tracking-wide text-red-700">
+                                        Safety Recall · {passport.recall.severity} severity
+                                    </p>
+                                    <p className="text-lg font-bold text-red-900">{passport.recall.title}</p>
+                                    <p className="text-sm text-red-800">{passport.recall.reason}</p>
+                                    {passport.recall.remedy_instructions && (
+                                        <p className="text-sm text-red-900">
+                                            <span className="font-semibold">What to do: </span>
+                                            {passport.recall.remedy_instructions}
+                                        </p>
+                                    )}
+                                    <p className="text-xs text-red-700">
+                                        Issued {new Date(passport.recall.issued_at).toLocaleDateString()}
+                                        {passport.recall.regulator_reference && ` · Regulator reference ${passport.recall.regulator_reference}`}
+                                    </p>
+                                </div>
+                            </div>
+                        </div>
+                    )}
+
                     {/* The Certificate Paper */}
                     <div className="bg-white rounded-lg shadow-lg border border-slate-200 overflow-hidden print:shadow-none print:border-0">
                         <CertificateView passport={passport} />
@@ -0,0 +1,245 @@
+"use client"
+
+import { Suspense, useEffect, useState } from "react"
+import { useSearchParams } from "next/navigation"
+import { motion } from "framer-motion"
+import { Loader2, CheckCircle, AlertCircle, Shield, FileText } from "lucide-react"
+import type { PLIAudit, PLIAuditInvitation } from "@/lib/types"
+
+function PLIAttestContent() {
+    const searchParams = useSearchParams()
+    const token = searchParams.get("token")
+
+    const [loading, setLoading] = useState(true)
+    const [invitation, setInvitation] = useState<PLIAuditInvitation | null>(null)
+    const [attested, setAttested] = useState<PLIAudit | null>(null)
+    const [error, setError] = useState("")
+    const [submitError, setSubmitError] = useState("")
+    const [submitting, setSubmitting] = useState(false)
+
+    const [auditedDva, setAuditedDva] = useState("")
+    const [caName, setCaName] = useState("")
+    const [membershipNo, setMembershipNo] = useState("")
+    const [udin, setUdin] = useState("")
+    const [file, setFile] = useState<File | null>(null)
+
+    const apiUrl = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080/api/v1"
+
+    useEffect(() => {
+        if (!token) {
+            setError("No attestation token provided. Please use the link from your email.")
+            setLoading(false)
+            return
+        }
+
+        const fetchInvitation = async () => {
+            try {
+                const response = await fetch(`${apiUrl}/pli-audit/attest?token=${token}`)
+                const data = await response.json()
+                if (!response.ok) {
+                    throw new Error(data.error || "Attestation request not found")
+                }
+                setInvitation(data)
+                setCaName(data.ca_name)
+            } catch (err: any) {
+                setError(err.message || "Invalid attestation link")
+            } finally {
+                setLoading(false)
+            }
+        }
+
+        fetchInvitation()
+    }, [apiUrl, token])
+
+    const handleSubmit = async (e: React.FormEvent) => {
+        e.preventDefault()
+        if (!token || !file) return
+
+        setSubmitting(true)
+        setSubmitError("")
+        try {
+            const form = new FormData()
+            form.append("token", token)
+            form.append("audited_dva", auditedDva)
+            form.append("ca_name", caName)
+            form.append("ca_membership_no", membershipNo)
+            form.append("udin", udin.toUpperCase())
+            form.append("file", file)
+
+            const response = await fetch(`${apiUrl}/pli-audit/attest`, { method: "POST", body: form })
+            const data = await response.json()
+            if (!response.ok) {
+                throw new Error(data.error || "Failed to submit attestation")
+            }
+            setAttested(data.audit)
+        } catch (err: any) {
+            setSubmitError(err.message || "Failed to submit attestation")
+        } finally {
+            setSubmitting(false)
+        }
+    }
+
+    if (loading) {
+        return (
+            <div className="min-h-screen bg-slate-950 flex items-center justify-center p-4">
+                <div className="flex flex-col items-center gap-4">
+                    <Loader2 className="h-10 w-10 text-emerald-400 animate-spin" />
+                    <p className="text-slate-400">Loading attestation request...</p>
+                </div>
+            </div>
+        )
+    }
+
+    if (error || !invitation) {
+        return (
+            <div className="min-h-screen bg-slate-950 flex items-center justify-center p-4">
+                <motion.div
+                    initial={{ opacity: 0, y: 20 }}
+                    animate={{ opacity: 1, y: 0 }}
+                    className="max-w-md w-full bg-slate-900/80 backdrop-blur-xl rounded-2xl border border-red-500/20 p-8 text-center"
+                >
+                    <div className="mx-auto w-16 h-16 rounded-full bg-red-500/10 flex items-center justify-center mb-6">
+                        <AlertCircle className="h-8 w-8 text-red-400" />
+                    </div>
+                    <h2 className="text-xl font-bold text-white mb-2">Attestation Unavailable</h2>
+                    <p className="text-slate-400">{error}</p>
+                </motion.div>
+            </div>
+        )
+    }
+
+    const inputClass = "w-full px-4 py-3 bg-slate-800/50 border border-slate-700 rounded-lg text-white placeholder-slate-500 focus:outline-none focus:border-emerald-500"
+
+    return (
+        <div className="min-h-screen bg-slate-950 py-8 px-4">
+            <div className="relative z-10 max-w-lg mx-auto">
+                <motion.div
+                    initial={{ opacity: 0, y: -20 }}
+                    animate={{ opacity: 1, y: 0 }}
+                    className="text-center mb-8"
+                >
+                    <div className="flex items-center justify-center gap-2 mb-4">
+                        <Shield className="h-6 w-6 text-emerald-400" />
+                        <span className="text-slate-400 text-sm">PLI Domestic Value Addition</span>
+                    </div>
+                    <h1 className="text-2xl font-bold text-white mb-2">{invitation.batch_name}</h1>
+                    <p className="text-slate-500">{invitation.company_name}</p>
+                </motion.div>
+
+                <div className="bg-slate-900/60 rounded-xl border border-slate-800 p-4 mb-6 grid grid-cols-2 gap-4 text-sm">
+                    <div>
+                        <p className="text-slate-500 mb-1">Current DVA ({invitation.dva_source.toLowerCase()})</p>
+                        <p className="text-white font-semibold">{invitation.current_dva.toFixed(2)}%</p>
+                    </div>
+                    <div>
+                        <p className="text-slate-500 mb-1">PLI threshold</p>
+                        <p className="text-white font-semibold">{invitation.pli_minimum_dva}%</p>
+                    </div>
+                    {invitation.chemistry && (
+                        <div>
+                            <p className="text-slate-500 mb-1">Chemistry</p>
+                            <p className="text-white">{invitation.chemistry}</p>
+                        </div>
+                    )}
+                    <div>
+                        <p className="text-slate-500 mb-1">Link expires</p>
+                        <p className="text-white">{new Date(invitation.expires_at).toLocaleDateString()}</p>
+                    </div>
+                </div>
+
+                {invitation.note && (
+                    <div className="bg-slate-900/60 rounded-xl border border-slate-800 p-4 mb-6">
+                        <p className="text-slate-500 text-sm mb-1">Note from the manufacturer</p>
+                        <p className="text-white">{invitation.note}</p>
+                    </div>
+                )}
+
+                {attested ? (
+                    <div className="rounded-xl border p-4 flex items-center gap-3 bg-emerald-500/10 border-emerald-500/20 text-emerald-400">
+                        <CheckCircle className="h-5 w-5" />
+                        <p>Attestation recorded: {attested.audited_dva?.toFixed(2)}% DVA. You can close this page.</p>
+                    </div>
+                ) : invitation.status !== "PENDING" ? (
+                    <div className="rounded-xl border p-4 flex items-center gap-3 bg-slate-900/60 border-slate-800 text-slate-300">
+                        <AlertCircle className="h-5 w-5" />
+                        <p>This attestation request has {invitation.status.toLowerCase()}.</p>
+                    </div>
+                ) : (
+                    <form onSubmit={handleSubmit} className="space-y-4">
+                        <input
+                            type="number"
+                            min="0"
+                            max="100"
+                            step="0.01"
+                            required
+                            placeholder="Attested DVA %"
+                            value={auditedDva}
+                            onChange={(e) => setAuditedDva(e.target.value)}
+                            className={inputClass}
+                        />
+                        <input
+                            required
+                            placeholder="CA / Firm name"
+                            value={caName}
+                            onChange={(e) => setCaName(e.target.value)}
+                            className={inputClass}
+                        />
+                        <input
+                            required
+                            inputMode="numeric"
+                            pattern="[0-9]{6}"
+                            placeholder="ICAI membership number (6 digits)"
+                            value={membershipNo}
+                            onChange={(e) => setMembershipNo(e.target.value)}
+                            className={inputClass}
+                        />
+                        <input
+                            required
+                            maxLength={18}
+                            placeholder="UDIN (18 characters)"
+                            value={udin}
+                            onChange={(e) => setUdin(e.target.value)}
+                            className={`${inputClass} font-mono uppercase`}
+                        />
+                        <label className="flex items-center gap-3 px-4 py-3 bg-slate-800/50 border border-dashed border-slate-700 rounded-lg text-slate-400 cursor-pointer hover:border-emerald-500">
+                            <FileText className="h-5 w-5" />
+                            <span className="truncate">{file ? file.name : "Signed certificate (PDF, max 5MB)"}</span>
+                            <input
+                                type="file"
+                                accept=".pdf"
+                                className="hidden"
+                                onChange={(e) => setFile(e.target.files?.[0] || null)}
+                            />
+                        </label>
+
+                        {submitError && <p className="text-red-400 text-sm">{submitError}</p>}
+
+                        <button
+                            type="submit"
+                            disabled={submitting || !file}
+                            className="w-full py-4 rounded-xl font-semibold text-white bg-emerald-600 hover:bg-emerald-500 disabled:opacity-50 transition-all flex items-center justify-center gap-2"
+                        >
+                            {submitting ? <Loader2 className="h-5 w-5 animate-spin" /> : <CheckCircle className="h-5 w-5" />}
+                            Submit Attestation
+                        </button>
+                        <p className="text-slate-500 text-xs text-center">
+                            The attested value and certificate are recorded in an append-only audit log and cannot be changed.
+                        </p>
+                    </form>
+                )}
+            </div>
+        </div>
+    )
+}
+
+export default function PLIAttestPage() {
+    return (
+        <Suspense fallback={
+            <div className="min-h-screen bg-slate-950 flex items-center justify-center">
+                <Loader2 className="h-8 w-8 text-emerald-400 animate-spin" />
+            </div>
+        }>
+            <PLIAttestContent />
+        </Suspense>
+    )
+}
@@ -0,0 +1,157 @@
+"use client"
+
+import { useState } from "react"
+import { useSearchParams } from "next/navigation"
+import { Button } from "@/components/ui/button"
+import { AuthLayout } from "./auth-layout"
+import api from "@/lib/api"
+import { toast } from "sonner"
+import { motion } from "framer-motion"
+import { Loader2, CheckCircle, XCircle, LockOpen } from "lucide-react"
+import Link from "next/link"
+
+export function UnlockAccountForm() {
+    const searchParams = useSearchParams()
+    const token = searchParams.get("token")
+
+    const [isLoading, setIsLoading] = useState(false)
+    const [isSuccess, setIsSuccess] = useState(false)
+    const [error, setError] = useState<string | null>(null)
+
+    // Unlocking takes a click, so link scanners opening the email do not spend the token
+    const handleUnlock = async () => {
+        setIsLoading(true)
+        setError(null)
+
+        try {
+            await api.post("/auth/unlock", { token })
+            setIsSuccess(true)
+            toast.success("Account unlocked")
+        } catch (err: any) {
+            const errorMessage = err.response?.data?.error || "Failed to unlock account"
+            setError(errorMessage)
+            toast.error(errorMessage)
+        } finally {
+            setIsLoading(false)
+        }
+    }
+
+    if (!token) {
+        return (
+            <AuthLayout
+                title="Invalid link"
+                subtitle="This unlock link is invalid or incomplete"
+                type="login"
+            >
+                <motion.div
+                    initial={{ opacity: 0, scale: 0.95 }}
+                    animate={{ opacity: 1, scale: 1 }}
+                    className="text-center space-y-6"
+                >
+                    <div className="mx-auto w-16 h-16 bg-red-500/10 rounded-full flex items-center justify-center">
+                        <XCircle className="h-8 w-8 text-red-400" />
+                    </div>
+
+                    <p className="text-slate-400">
+                        Open the link from the lockout email again, or wait for the lockout to expire.
+                    </p>
+
+                    <Link href="/login" className="w-full">
+                        <Button variant="ghost" className="w-full h-11 text-slate-400">
+                            Back to login
+                        </Button>
+                    </Link>
+                </motion.div>
+            </AuthLayout>
+        )
+    }
+
+    if (isSuccess) {
+        return (
+            <AuthLayout
+                title="Account unlocked"
+                subtitle="You can sign in again"
+                type="login"
+            >
+                <motion.div
+                    initial={{ opacity: 0, scale: 0.95 }}
+                    animate={{ opacity: 1, scale: 1 }}
+                    className="text-center space-y-6"
+                >
+                    <div className="mx-auto w-16 h-16 bg-emerald-500/10 rounded-full flex items-center justify-center">
+                        <CheckCircle className="h-8 w-8 text-emerald-400" />
+                    </div>
+
+                    <p className="text-slate-300">
+                        If you did not make the failed sign-in attempts, reset your password.
+                    </p>
+
+                    <div className="flex flex-col gap-3">
+                        <Link href="/login" className="w-full">
+                            <Button className="w-full h-11 bg-teal-600 hover:bg-teal-500 text-white">
+                                Continue to login
+                            </Button>
+                        </Link>
+                        <Link href="/forgot-password" className="w-full">
+                            <Button variant="ghost" className="w-full h-11 text-slate-400">
+                                Reset password
+                            </Button>
+                        </Link>
+                    </div>
+                </motion.div>
+            </AuthLayout>
+        )
+    }
+
+    return (
+        <AuthLayout
+            title="Unlock your account"
+            subtitle="Your account was locked after too many failed sign-in attempts"
+            type="login"
+        >
+            <div className="space-y-5">
+                {/* Error display */}
+                {error && (
+                    <motion.div
+                        initial={{ opacity: 0 }}
+                        animate={{ opacity: 1 }}
+                        className="p-3 rounded-lg bg-red-500/10 border border-red-500/20 text-red-400 text-sm"
+                    >
+                        {error}
+                    </motion.div>
+                )}
+
+                <motion.div
+                    initial={{ opacity: 0, y: 10 }}
+                    animate={{ opacity: 1, y: 0 }}
+                    transition={{ delay: 0.1 }}
+                >
+                    <Button
+                        type="button"
+                        onClick={handleUnlock}
+                        disabled={isLoading}
+                        className="w-full h-12 bg-gradient-to-r from-blue-600 to-teal-500 hover:from-blue-500 hover:to-teal-400 text-white font-semibold shadow-lg shadow-blue-500/25 transition-all duration-200 disabled:opacity-50"
+                    >
+                        {isLoading ? (
+                            <>
+                                <Loader2 className="mr-2 h-4 w-4 animate-spin" />
+                                Unlocking...
+                            </>
+                        ) : (
+                            <>
+                                <LockOpen className="mr-2 h-4 w-4" />
+                                Unlock account
+                            </>
+                        )}
+                    </Button>
+                </motion.div>
+
+                <Link href="/login" className="block">
+                    <Button variant="ghost" className="w-full h-11 text-slate-400">
+                        Back to login
+                    </Button>
+                </Link>
+            </div>
+        </AuthLayout>
+    )
+}
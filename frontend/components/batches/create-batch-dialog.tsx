@@ -16,11 +16,22 @@ import { Input } from "@/components/ui/input"
 import { Label } from "@/components/ui/label"
 import api from "@/lib/api"
 import { toast } from "sonner"
-import { PlusCircle, Sparkles, Save, Globe, Leaf, Flag, FileText, Calendar, Calculator, Recycle, Shield, Activity, Users, FileCheck } from "lucide-react"
-import { supabase } from "@/lib/supabase"
+import { PlusCircle, Sparkles, Save, Globe, Leaf, Flag, FileText, Calendar, Calculator, Recycle, Shield, Activity, Users } from "lucide-react"
 
-// Market region type
-type MarketRegion = "INDIA" | "EU" | "GLOBAL"
+// Target markets; a batch may target several at once
+type MarketRegion = "INDIA" | "EU" | "US" | "UK"
+
+// EU Battery Regulation categories; the category sets the passport, labelling
+// and carbon footprint obligations
+type BatteryCategory = "PORTABLE" | "LMT" | "EV" | "INDUSTRIAL" | "SLI"
+
+const BATTERY_CATEGORIES: { value: BatteryCategory; label: string }[] = [
+    { value: "PORTABLE", label: "Portable (sealed, up to 5 kg)" },
+    { value: "LMT", label: "Light means of transport (e-bike, e-scooter)" },
+    { value: "EV", label: "Electric vehicle" },
+    { value: "INDUSTRIAL", label: "Industrial / stationary storage" },
+    { value: "SLI", label: "Starting, lighting & ignition (SLI)" },
+]
 
 interface Template {
     id: string
@@ -34,6 +45,7 @@ interface Template {
         carbon_footprint: string
         country_of_origin: string
         recyclable: boolean
+        battery_category?: BatteryCategory
     }
 }
 
@@ -53,8 +65,8 @@ export function CreateBatchDialog({ onBatchCreated }: CreateBatchDialogProps) {
     const [templateName, setTemplateName] = useState("")
     const [fieldsAnimating, setFieldsAnimating] = useState(false)
 
-    // ===== DUAL-MODE: Market Region =====
-    const [marketRegion, setMarketRegion] = useState<MarketRegion>("GLOBAL")
+    // ===== MULTI-MARKET: Target Markets =====
+    const [markets, setMarkets] = useState<MarketRegion[]>(["INDIA", "EU"])
 
     // Form states - Common
     const [batchName, setBatchName] = useState("")
@@ -67,6 +79,7 @@ export function CreateBatchDialog({ onBatchCreated }: CreateBatchDialogProps) {
     const [weight, setWeight] = useState("")
     const [countryOfOrigin, setCountryOfOrigin] = useState("")
     const [recyclable, setRecyclable] = useState(false)
+    const [batteryCategory, setBatteryCategory] = useState<BatteryCategory | "">("")
 
     // Form states - EU Specific
     const [carbonFootprint, setCarbonFootprint] = useState("")
@@ -91,6 +104,11 @@ export function CreateBatchDialog({ onBatchCreated }: CreateBatchDialogProps) {
     const [expectedLifetime, setExpectedLifetime] = useState("")
     const [warrantyMonths, setWarrantyMonths] = useState("")
 
+    // Form states - US / UK Specific
+    const [un383TestSummary, setUn383TestSummary] = useState("")
+    const [ukResponsiblePerson, setUkResponsiblePerson] = useState("")
+    const [ukProducerNumber, setUkProducerNumber] = useState("")
+
     // Form states - India Specific
     const [pliCompliant, setPliCompliant] = useState(false)
     // New Enterprise Fields for India
@@ -104,15 +122,6 @@ export function CreateBatchDialog({ onBatchCreated }: CreateBatchDialogProps) {
     const [cellCountryOfOrigin, setCellCountryOfOrigin] = useState("")
     const [customsDate, setCustomsDate] = useState("")
 
-    // DVA Audit Compliance
-    const [dvaSource, setDvaSource] = useState<"ESTIMATED" | "AUDITED">("ESTIMATED")
-    const [auditedDva, setAuditedDva] = useState("")
-
-    // File Upload State
-    const [uploadProgress, setUploadProgress] = useState(0)
-    const [uploadedFileUrl, setUploadedFileUrl] = useState<string | null>(null)
-    const [isUploading, setIsUploading] = useState(false)
-
     // IEC Validation State
     const [iecError, setIecError] = useState(false)
 
@@ -168,83 +177,52 @@ export function CreateBatchDialog({ onBatchCreated }: CreateBatchDialogProps) {
             setCarbonFootprint(template.specs.carbon_footprint || "")
             setCountryOfOrigin(template.specs.country_of_origin || "")
             setRecyclable(template.specs.recyclable || false)
+            setBatteryCategory(template.specs.battery_category || "")
 
             toast.success(`Loaded "${template.name}" template`)
         }
     }
 
-    const handleCertificateUpload = async (file: File) => {
-        if (!user) return
-
-        setIsUploading(true)
-        setUploadProgress(0)
-
-        try {
-            // Generate unique file path
-            const timestamp = Date.now()
-            const sanitizedBatchName = batchName.replace(/[^a-zA-Z0-9-]/g, '_') || 'unnamed'
-            const filePath = `certificates/${user.tenant_id}/${sanitizedBatchName}_${timestamp}.pdf`
-
-            // Simulate progress (Supabase doesn't provide real-time progress)
-            setUploadProgress(30)
-
-            // Upload to Supabase Storage
-            const { data, error } = await supabase.storage
-                .from('compliance-docs')
-                .upload(filePath, file, {
-                    cacheControl: '3600',
-                    upsert: false  // Prevent overwriting
-                })
-
-            if (error) throw error
-
-            setUploadProgress(70)
-
-            // Get public URL
-            const { data: { publicUrl } } = supabase.storage
-                .from('compliance-docs')
-                .getPublicUrl(filePath)
-
-            setUploadProgress(100)
-            setUploadedFileUrl(publicUrl)
-            toast.success('Certificate uploaded successfully')
+    // Toggle a target market; at least one stays selected
+    const toggleMarket = (market: MarketRegion) => {
+        setMarkets(prev => {
+            if (!prev.includes(market)) return [...prev, market]
+            return prev.length > 1 ? prev.filter(m => m !== market) : prev
+        })
+    }
 
-        } catch (error: any) {
-            console.error('Upload error:', error)
-            toast.error(error.message || 'Upload failed')
-        } finally {
-            setIsUploading(false)
-            setTimeout(() => setUploadProgress(0), 1000)
-        }
+    const toggleCertification = (mark: string) => {
+        setCertifications(prev => prev.includes(mark) ? prev.filter(c => c !== mark) : [...prev, mark])
     }
 
     const handleSubmit = async (e: React.FormEvent) => {
         e.preventDefault()
         if (!user) return
 
-        // ===== DUAL-MODE VALIDATION =====
+        // ===== MARKET VALIDATION =====
+        // Every target market's creation rules apply, as in the backend
 
         // India Mode: Cell Source is MANDATORY
-        if (marketRegion === "INDIA" && !cellSource) {
+        if (isIndiaMode && !cellSource) {
             toast.error("Please specify Cell Source (Domestic/Imported) for India compliance")
             return
         }
 
         // India Mode + IMPORTED: IEC Code is MANDATORY
-        if (marketRegion === "INDIA" && cellSource === "IMPORTED") {
+        if (isIndiaMode && cellSource === "IMPORTED") {
             if (!user.iec_code) {
                 toast.error("IEC Code is required for importing battery cells. Please add it in Organization Settings.")
                 return
             }
         }
 
-        if (marketRegion === "EU" && !carbonFootprint) {
+        if (isEUMode && carbonRequired && !carbonFootprint) {
             toast.error("Carbon Footprint is required for EU exports")
             return
         }
 
         // India Import Validation
-        if (marketRegion === "INDIA" && cellSource === "IMPORTED") {
+        if (isIndiaMode && cellSource === "IMPORTED") {
             if (!billOfEntryNo || !cellCountryOfOrigin || !customsDate) {
                 toast.error("Imported batches require Bill of Entry, Country of Origin, and Customs Date")
                 return
@@ -257,7 +235,7 @@ export function CreateBatchDialog({ onBatchCreated }: CreateBatchDialogProps) {
             const payload: any = {
                 tenant_id: user.tenant_id,
                 batch_name: batchName,
-                market_region: marketRegion,
+                markets,
                 specs: {
                     capacity,
                     voltage,
@@ -268,13 +246,17 @@ export function CreateBatchDialog({ onBatchCreated }: CreateBatchDialogProps) {
                     weight,
                     country_of_origin: countryOfOrigin,
                     recyclable,
+                    battery_category: batteryCategory || undefined,
                 }
             }
 
             // Add EU specific fields
-            if (marketRegion === "EU" || marketRegion === "GLOBAL") {
-                payload.specs.carbon_footprint = carbonFootprint
+            if (certifications.length > 0) {
                 payload.specs.certifications = certifications
+            }
+
+            if (targetsEU) {
+                payload.specs.carbon_footprint = carbonFootprint
 
                 // Add new EU fields
                 payload.specs.material_composition = {
@@ -301,36 +283,30 @@ export function CreateBatchDialog({ onBatchCreated }: CreateBatchDialogProps) {
                 payload.specs.warranty_months = parseInt(warrantyMonths) || 0
             }
 
+            // Add US / UK specific fields
+            if (targetsUS || targetsUK) {
+                payload.specs.un38_3_test_summary = un383TestSummary
+            }
+            if (targetsUK) {
+                payload.specs.uk_responsible_person = ukResponsiblePerson
+                payload.specs.uk_producer_number = ukProducerNumber
+            }
+
             // Add India-specific fields
-            if (marketRegion === "INDIA") {
+            if (targetsIndia) {
                 // PLI Compliant flag
                 payload.pli_compliant = pliCompliant
                 payload.hsn_code = hsnCode
-                payload.dva_source = dvaSource
-
-                if (dvaSource === "AUDITED") {
-                    // Audited Mode - require certificate upload
-                    if (!uploadedFileUrl) {
-                        toast.error("Please upload the CA certificate before submitting")
-                        setIsLoading(false)
-                        return
-                    }
-
-                    const auditedVal = parseFloat(auditedDva) || 0
-                    payload.audited_domestic_value_add = auditedVal
-                    payload.pli_certificate_url = uploadedFileUrl
-                } else {
-                    // Estimated Mode (Legacy DVA)
-                    const sale = parseFloat(salePrice) || 0
-                    const cost = parseFloat(importCost) || 0
-                    // Add Financials to Specs (Backend JSONB)
-                    payload.specs.sale_price_inr = sale
-                    payload.specs.import_cost_inr = cost
-
-                    // We still send domestic_value_add but backend recalculates it for security in Estimated mode
-                    const calculatedDva = sale > 0 ? ((sale - cost) / sale * 100) : 0
-                    payload.domestic_value_add = Math.max(0, calculatedDva)
-                }
+
+                const sale = parseFloat(salePrice) || 0
+                const cost = parseFloat(importCost) || 0
+                // Add Financials to Specs (Backend JSONB)
+                payload.specs.sale_price_inr = sale
+                payload.specs.import_cost_inr = cost
+
+                // The backend recalculates the DVA from these; a CA attestation is requested from the batch page
+                const calculatedDva = sale > 0 ? ((sale - cost) / sale * 100) : 0
+                payload.domestic_value_add = Math.max(0, calculatedDva)
 
                 payload.cell_source = cellSource || undefined
                 // Customs declaration for imported cells
@@ -341,7 +317,7 @@ export function CreateBatchDialog({ onBatchCreated }: CreateBatchDialogProps) {
                 }
             }
 
-            await api.post("/batches", payload)
+            const response = await api.post("/batches", payload)
 
             // Save as template if checkbox is checked
             if (saveAsTemplate && templateName.trim()) {
@@ -358,6 +334,12 @@ export function CreateBatchDialog({ onBatchCreated }: CreateBatchDialogProps) {
             }
 
             toast.success("Batch created successfully")
+            for (const warning of response.data.warnings || []) {
+                toast.warning(`${warning.field}: ${warning.message}`)
+            }
+            for (const check of response.data.market_warnings || []) {
+                toast.warning(`${check.market} · ${check.label}: ${check.message}`)
+            }
             setOpen(false)
             onBatchCreated()
             resetForm()
@@ -376,10 +358,11 @@ export function CreateBatchDialog({ onBatchCreated }: CreateBatchDialogProps) {
         setCarbonFootprint("")
         setCountryOfOrigin("")
         setRecyclable(false)
+        setBatteryCategory("")
         setSelectedTemplate("")
         setSaveAsTemplate(false)
         setTemplateName("")
-        setMarketRegion("GLOBAL")
+        setMarkets(["INDIA", "EU"])
         setPliCompliant(false)
         // Reset India Enterprise Fields
         setHsnCode("")
@@ -393,17 +376,21 @@ export function CreateBatchDialog({ onBatchCreated }: CreateBatchDialogProps) {
         setBillOfEntryNo("")
         setCellCountryOfOrigin("")
         setCustomsDate("")
-        // Reset Audit fields
-        setDvaSource("ESTIMATED")
-        setAuditedDva("")
-        setUploadedFileUrl(null)
-        setUploadProgress(0)
-        setIsUploading(false)
+        // Reset US / UK fields
+        setUn383TestSummary("")
+        setUkResponsiblePerson("")
+        setUkProducerNumber("")
     }
 
-    // Check if this is EU mode
-    const isEUMode = marketRegion === "EU"
-    const isIndiaMode = marketRegion === "INDIA"
+    const targetsIndia = markets.includes("INDIA")
+    const targetsEU = markets.includes("EU")
+    const targetsUS = markets.includes("US")
+    const targetsUK = markets.includes("UK")
+    // Single-market modes, whose creation rules are enforced
+    const isEUMode = targetsEU
+    const isIndiaMode = targetsIndia
+    // Portable and SLI batteries declare no carbon footprint (EU 2023/1542 Art. 7)
+    const carbonRequired = batteryCategory !== "PORTABLE" && batteryCategory !== "SLI"
 
     return (
         <Dialog open={open} onOpenChange={setOpen}>
@@ -426,13 +413,13 @@ export function CreateBatchDialog({ onBatchCreated }: CreateBatchDialogProps) {
                         <div className="grid gap-3 pb-4 border-b border-zinc-700">
                             <Label className="flex items-center gap-2">
                                 <Globe className="h-4 w-4 text-blue-500" />
-                                Target Market <span className="text-red-500">*</span>
+                                Target Markets <span className="text-red-500">*</span>
                             </Label>
-                            <div className="grid grid-cols-3 gap-2">
+                            <div className="grid grid-cols-4 gap-2">
                                 <button
                                     type="button"
-                                    onClick={() => setMarketRegion("INDIA")}
-                                    className={`p-3 rounded-lg border-2 text-center transition-all ${isIndiaMode
+                                    onClick={() => toggleMarket("INDIA")}
+                                    className={`p-3 rounded-lg border-2 text-center transition-all ${targetsIndia
                                         ? "border-orange-500 bg-orange-500/10 text-orange-400"
                                         : "border-zinc-700 hover:border-zinc-600 text-zinc-400"
                                         }`}
@@ -443,8 +430,8 @@ export function CreateBatchDialog({ onBatchCreated }: CreateBatchDialogProps) {
                                 </button>
                                 <button
                                     type="button"
-                                    onClick={() => setMarketRegion("EU")}
-                                    className={`p-3 rounded-lg border-2 text-center transition-all ${isEUMode
+                                    onClick={() => toggleMarket("EU")}
+                                    className={`p-3 rounded-lg border-2 text-center transition-all ${targetsEU
                                         ? "border-blue-500 bg-blue-500/10 text-blue-400"
                                         : "border-zinc-700 hover:border-zinc-600 text-zinc-400"
                                         }`}
@@ -455,15 +442,27 @@ export function CreateBatchDialog({ onBatchCreated }: CreateBatchDialogProps) {
                                 </button>
                                 <button
                                     type="button"
-                                    onClick={() => setMarketRegion("GLOBAL")}
-                                    className={`p-3 rounded-lg border-2 text-center transition-all ${marketRegion === "GLOBAL"
-                                        ? "border-teal-500 bg-teal-500/10 text-teal-400"
-                                        : "border-slate-700 hover:border-slate-600 text-slate-400"
+                                    onClick={() => toggleMarket("US")}
+                                    className={`p-3 rounded-lg border-2 text-center transition-all ${targetsUS
+                                        ? "border-red-500 bg-red-500/10 text-red-400"
+                                        : "border-zinc-700 hover:border-zinc-600 text-zinc-400"
+                                        }`}
+                                >
+                                    <Shield className="h-5 w-5 mx-auto mb-1" />
+                                    <div className="font-semibold text-sm">US</div>
+                                    <div className="text-xs opacity-70">UL Listing</div>
+                                </button>
+                                <button
+                                    type="button"
+                                    onClick={() => toggleMarket("UK")}
+                                    className={`p-3 rounded-lg border-2 text-center transition-all ${targetsUK
+                                        ? "border-purple-500 bg-purple-500/10 text-purple-400"
+                                        : "border-zinc-700 hover:border-zinc-600 text-zinc-400"
                                         }`}
                                 >
                                     <Globe className="h-5 w-5 mx-auto mb-1" />
-                                    <div className="font-semibold text-sm">Global</div>
-                                    <div className="text-xs opacity-70">Both Markets</div>
+                                    <div className="font-semibold text-sm">UK</div>
+                                    <div className="text-xs opacity-70">UKCA</div>
                                 </button>
                             </div>
                         </div>
@@ -596,8 +595,29 @@ export function CreateBatchDialog({ onBatchCreated }: CreateBatchDialogProps) {
                                 </div>
                             </div>
 
+                            {/* Row 5: Battery Category */}
+                            <div className="grid gap-2">
+                                <Label htmlFor="batteryCategory">
+                                    Battery Category {targetsEU && <span className="text-red-500">*</span>}
+                                </Label>
+                                <select
+                                    id="batteryCategory"
+                                    value={batteryCategory}
+                                    onChange={(e) => setBatteryCategory(e.target.value as BatteryCategory | "")}
+                                    className="flex h-10 w-full rounded-md border border-zinc-700 bg-zinc-800 px-3 py-2 text-sm text-zinc-100 focus-visible:outline-none focus-visible:ring-2 focus-visible:ring-teal-500"
+                                >
+                                    <option value="">-- Select Category --</option>
+                                    {BATTERY_CATEGORIES.map((c) => (
+                                        <option key={c.value} value={c.value}>{c.label}</option>
+                                    ))}
+                                </select>
+                                <p className="text-[10px] text-zinc-500">
+                                    Sets the EU passport, labelling and carbon footprint obligations, and the India EPR category.
+                                </p>
+                            </div>
+
                             {/* ===== EU-SPECIFIC FIELDS ===== */}
-                            {(isEUMode || marketRegion === "GLOBAL") && (
+                            {targetsEU && (
                                 <div className="p-4 rounded-lg border border-blue-500/30 bg-blue-500/10 space-y-4">
                                     <div className="flex items-center gap-2 text-blue-400 font-semibold text-sm">
                                         <Leaf className="h-4 w-4" />
@@ -608,14 +628,14 @@ export function CreateBatchDialog({ onBatchCreated }: CreateBatchDialogProps) {
                                     <div className="grid gap-2">
                                         <Label htmlFor="carbon" className="text-zinc-300">
                                             Carbon Footprint (kg CO₂e)
-                                            {isEUMode && <span className="text-red-500">*</span>}
+                                            {isEUMode && carbonRequired && <span className="text-red-500">*</span>}
                                         </Label>
                                         <Input
                                             id="carbon"
                                             value={carbonFootprint}
                                             onChange={(e) => setCarbonFootprint(e.target.value)}
                                             placeholder="e.g. 10 kg CO2e"
-                                            required={isEUMode}
+                                            required={isEUMode && carbonRequired}
                                             className="bg-zinc-800 border-zinc-700 text-zinc-100"
                                         />
                                     </div>
@@ -754,8 +774,77 @@ export function CreateBatchDialog({ onBatchCreated }: CreateBatchDialogProps) {
                                 </div>
                             )}
 
+                            {/* ===== US / UK SPECIFIC FIELDS ===== */}
+                            {(targetsUS || targetsUK) && (
+                                <div className="p-4 rounded-lg border border-purple-500/30 bg-purple-500/10 space-y-4">
+                                    <div className="flex items-center gap-2 text-purple-400 font-semibold text-sm">
+                                        <Shield className="h-4 w-4" />
+                                        US / UK Compliance Fields
+                                    </div>
+
+                                    <div className="grid gap-2">
+                                        <Label className="text-xs text-zinc-400">Certifications</Label>
+                                        <div className="flex gap-4">
+                                            {[
+                                                ...(targetsUS ? ["UL 2054", "UL 2580"] : []),
+                                                ...(targetsUK ? ["UKCA"] : []),
+                                            ].map((mark) => (
+                                                <div key={mark} className="flex items-center space-x-2">
+                                                    <input
+                                                        type="checkbox"
+                                                        id={`cert-${mark}`}
+                                                        checked={certifications.includes(mark)}
+                                                        onChange={() => toggleCertification(mark)}
+                                                        className="h-3.5 w-3.5 rounded border-zinc-600 bg-zinc-800 text-purple-500 focus:ring-purple-500"
+                                                    />
+                                                    <Label htmlFor={`cert-${mark}`} className="text-xs font-normal cursor-pointer text-zinc-300">
+                                                        {mark}
+                                                    </Label>
+                                                </div>
+                                            ))}
+                                        </div>
+                                    </div>
+
+                                    <div className="grid gap-2">
+                                        <Label htmlFor="un383" className="text-zinc-300">UN 38.3 Test Summary</Label>
+                                        <Input
+                                            id="un383"
+                                            value={un383TestSummary}
+                                            onChange={(e) => setUn383TestSummary(e.target.value)}
+                                            placeholder="e.g. Report TR-2024-118, TÜV SÜD, 2024-03-02"
+                                            className="bg-zinc-800 border-zinc-700 text-zinc-100"
+                                        />
+                                    </div>
+
+                                    {targetsUK && (
+                                        <div className="grid grid-cols-2 gap-3">
+                                            <div className="grid gap-1">
+                                                <Label htmlFor="ukRep" className="text-xs text-zinc-400">UK Importer / Representative</Label>
+                                                <Input
+                                                    id="ukRep"
+                                                    value={ukResponsiblePerson}
+                                                    onChange={(e) => setUkResponsiblePerson(e.target.value)}
+                                                    placeholder="Company Name"
+                                                    className="h-8 text-xs bg-zinc-800 border-zinc-700"
+                                                />
+                                            </div>
+                                            <div className="grid gap-1">
+                                                <Label htmlFor="ukProducer" className="text-xs text-zinc-400">UK Producer Number</Label>
+                                                <Input
+                                                    id="ukProducer"
+                                                    value={ukProducerNumber}
+                                                    onChange={(e) => setUkProducerNumber(e.target.value)}
+                                                    placeholder="e.g. BPRN01234"
+                                                    className="h-8 text-xs bg-zinc-800 border-zinc-700"
+                                                />
+                                            </div>
+                                        </div>
+                                    )}
+                                </div>
+                            )}
+
                             {/* ===== INDIA-SPECIFIC FIELDS ===== */}
-                            {isIndiaMode && (
+                            {targetsIndia && (
                                 <div className="p-4 rounded-lg border border-orange-500/30 bg-orange-500/10 space-y-4">
                                     <div className="flex items-center gap-2 text-orange-400 font-semibold text-sm">
                                         <Flag className="h-4 w-4" />
@@ -798,202 +887,64 @@ export function CreateBatchDialog({ onBatchCreated }: CreateBatchDialogProps) {
 
                                     {/* DVA Calculation Section - HIDE for IMPORTED cells */}
                                     {cellSource !== "IMPORTED" && (
-                                        <>
-                                            {/* DVA Calculation Method Selection */}
-                                            <div className="flex items-center justify-between mb-4 bg-zinc-800/50 p-3 rounded-lg border border-zinc-700">
-                                                <Label htmlFor="auditedMode" className="cursor-pointer flex items-center gap-2">
-                                                    <Shield className={`h-4 w-4 ${dvaSource === "AUDITED" ? "text-emerald-400" : "text-zinc-400"}`} />
-                                                    <span>I have an Audited CA Certificate</span>
-                                                </Label>
-                                                <input
-                                                    type="checkbox"
-                                                    id="auditedMode"
-                                                    checked={dvaSource === "AUDITED"}
-                                                    onChange={(e) => setDvaSource(e.target.checked ? "AUDITED" : "ESTIMATED")}
-                                                    className="h-4 w-4 rounded border-zinc-600 bg-zinc-800 text-emerald-500 focus:ring-emerald-500"
-                                                />
+                                        <div className="space-y-4 animate-in fade-in slide-in-from-top-2 duration-300">
+                                            <div className="flex items-center gap-2 text-sm font-semibold text-zinc-300">
+                                                <Calculator className="h-4 w-4 text-indigo-400" />
+                                                Indicative DVA Estimator
                                             </div>
 
-                                            {dvaSource === "AUDITED" ? (
-                                                // AUDITED Mode UI
-                                                <div className="space-y-4 animate-in fade-in slide-in-from-top-2 duration-300">
-                                                    <div className="p-4 rounded-lg border border-emerald-500/30 bg-emerald-500/10 space-y-2">
-                                                        <div className="flex items-center gap-2 text-emerald-400 font-semibold text-sm">
-                                                            <Shield className="h-4 w-4" />
-                                                            Audited Compliance Mode
-                                                        </div>
-                                                        <p className="text-xs text-emerald-300/80">
-                                                            Enter the final DVA percentage certified by your Chartered Accountant.
-                                                            This value overrides estimated calculations.
-                                                        </p>
-                                                    </div>
-
-                                                    <div className="grid gap-2">
-                                                        <Label htmlFor="auditedDva" className="text-zinc-300">
-                                                            Audited DVA % <span className="text-red-500">*</span>
-                                                        </Label>
-                                                        <Input
-                                                            id="auditedDva"
-                                                            type="number"
-                                                            min="0"
-                                                            max="100"
-                                                            step="0.1"
-                                                            value={auditedDva}
-                                                            onChange={(e) => setAuditedDva(e.target.value)}
-                                                            placeholder="e.g. 55.5"
-                                                            className="bg-zinc-800 border-zinc-700 text-zinc-100"
-                                                        />
-                                                    </div>
-
-                                                    <div className="grid gap-2">
-                                                        <Label className="text-zinc-300">Upload CA Certificate (PDF)</Label>
-
-                                                        {/* Upload Zone */}
-                                                        {!uploadedFileUrl ? (
-                                                            <div className="flex items-center justify-center w-full">
-                                                                <label className={`flex flex-col items-center justify-center w-full h-32 border-2 border-dashed rounded-lg cursor-pointer transition-colors ${isUploading
-                                                                    ? 'border-emerald-500 bg-emerald-500/5'
-                                                                    : 'border-zinc-700 bg-zinc-800 hover:bg-zinc-700'
-                                                                    }`}>
-                                                                    <div className="flex flex-col items-center justify-center pt-5 pb-6">
-                                                                        {isUploading ? (
-                                                                            <>
-                                                                                <div className="w-full px-8 mb-3">
-                                                                                    <div className="w-full bg-zinc-700 rounded-full h-2">
-                                                                                        <div
-                                                                                            className="bg-emerald-500 h-2 rounded-full transition-all duration-300"
-                                                                                            style={{ width: `${uploadProgress}%` }}
-                                                                                        />
-                                                                                    </div>
-                                                                                </div>
-                                                                                <p className="text-sm text-emerald-400">Uploading... ({uploadProgress}%)</p>
-                                                                            </>
-                                                                        ) : (
-                                                                            <>
-                                                                                <FileText className="w-8 h-8 mb-3 text-zinc-400" />
-                                                                                <p className="text-sm text-zinc-400">
-                                                                                    <span className="font-semibold">Click to upload</span> or drag and drop
-                                                                                </p>
-                                                                                <p className="text-xs text-zinc-500">PDF only (MAX. 5MB)</p>
-                                                                            </>
-                                                                        )}
-                                                                    </div>
-                                                                    <input
-                                                                        type="file"
-                                                                        className="hidden"
-                                                                        accept=".pdf"
-                                                                        disabled={isUploading}
-                                                                        onChange={(e) => {
-                                                                            const file = e.target.files?.[0]
-                                                                            if (file) {
-                                                                                if (file.size > 5242880) {
-                                                                                    toast.error('File size must be less than 5MB')
-                                                                                    return
-                                                                                }
-                                                                                if (file.type !== 'application/pdf') {
-                                                                                    toast.error('Only PDF files are allowed')
-                                                                                    return
-                                                                                }
-                                                                                handleCertificateUpload(file)
-                                                                            }
-                                                                        }}
-                                                                    />
-                                                                </label>
-                                                            </div>
-                                                        ) : (
-                                                            /* Uploaded State */
-                                                            <div className="p-4 bg-emerald-500/10 border border-emerald-500/30 rounded-lg">
-                                                                <div className="flex items-center justify-between">
-                                                                    <div className="flex items-center gap-3">
-                                                                        <FileCheck className="h-5 w-5 text-emerald-400" />
-                                                                        <div>
-                                                                            <p className="text-sm font-medium text-emerald-400">Certificate Uploaded</p>
-                                                                            <p className="text-xs text-emerald-300/70">Ready for submission</p>
-                                                                        </div>
-                                                                    </div>
-                                                                    <div className="flex gap-2">
-                                                                        <a
-                                                                            href={uploadedFileUrl}
-                                                                            target="_blank"
-                                                                            rel="noopener noreferrer"
-                                                                            className="text-xs px-3 py-1 bg-emerald-500/20 text-emerald-400 rounded hover:bg-emerald-500/30 transition-colors"
-                                                                        >
-                                                                            View File
-                                                                        </a>
-                                                                        <button
-                                                                            type="button"
-                                                                            onClick={() => setUploadedFileUrl(null)}
-                                                                            className="text-xs px-3 py-1 bg-zinc-700 text-zinc-300 rounded hover:bg-zinc-600 transition-colors"
-                                                                        >
-                                                                            Replace
-                                                                        </button>
-                                                                    </div>
-                                                                </div>
-                                                            </div>
-                                                        )}
-                                                    </div>
+                                            <div className="grid grid-cols-2 gap-4">
+                                                <div className="grid gap-2">
+                                                    <Label htmlFor="salePrice" className="text-zinc-300">Sale Price (₹)</Label>
+                                                    <Input
+                                                        id="salePrice"
+                                                        type="number"
+                                                        min="0"
+                                                        value={salePrice}
+                                                        onChange={(e) => setSalePrice(e.target.value)}
+                                                        placeholder="0.00"
+                                                        className="bg-zinc-800 border-zinc-700 text-zinc-100"
+                                                    />
                                                 </div>
-                                            ) : (
-                                                // ESTIMATED Mode UI (Original Calculator)
-                                                <div className="space-y-4 animate-in fade-in slide-in-from-top-2 duration-300">
-                                                    <div className="flex items-center gap-2 text-sm font-semibold text-zinc-300">
-                                                        <Calculator className="h-4 w-4 text-indigo-400" />
-                                                        Indicative DVA Estimator
-                                                    </div>
+                                                <div className="grid gap-2">
+                                                    <Label htmlFor="importCost" className="text-zinc-300">Imp. Material Cost (₹)</Label>
+                                                    <Input
+                                                        id="importCost"
+                                                        type="number"
+                                                        min="0"
+                                                        value={importCost}
+                                                        onChange={(e) => setImportCost(e.target.value)}
+                                                        placeholder="0.00"
+                                                        className="bg-zinc-800 border-zinc-700 text-zinc-100"
+                                                    />
+                                                </div>
+                                            </div>
 
-                                                    <div className="grid grid-cols-2 gap-4">
-                                                        <div className="grid gap-2">
-                                                            <Label htmlFor="salePrice" className="text-zinc-300">Sale Price (₹)</Label>
-                                                            <Input
-                                                                id="salePrice"
-                                                                type="number"
-                                                                min="0"
-                                                                value={salePrice}
-                                                                onChange={(e) => setSalePrice(e.target.value)}
-                                                                placeholder="0.00"
-                                                                className="bg-zinc-800 border-zinc-700 text-zinc-100"
-                                                            />
-                                                        </div>
-                                                        <div className="grid gap-2">
-                                                            <Label htmlFor="importCost" className="text-zinc-300">Imp. Material Cost (₹)</Label>
-                                                            <Input
-                                                                id="importCost"
-                                                                type="number"
-                                                                min="0"
-                                                                value={importCost}
-                                                                onChange={(e) => setImportCost(e.target.value)}
-                                                                placeholder="0.00"
-                                                                className="bg-zinc-800 border-zinc-700 text-zinc-100"
-                                                            />
-                                                        </div>
+                                            {/* Calculated DVA Display */}
+                                            {(salePrice && importCost) && (
+                                                <div className="space-y-2">
+                                                    <div className={`p-3 rounded border text-center text-sm font-semibold ${((parseFloat(salePrice) - parseFloat(importCost)) / parseFloat(salePrice) * 100) >= 50
+                                                        ? "bg-emerald-500/10 border-emerald-500/30 text-emerald-400"
+                                                        : "bg-red-500/10 border-red-500/30 text-red-400"
+                                                        }`}>
+                                                        Estimated DVA: {Math.max(0, ((parseFloat(salePrice) - parseFloat(importCost)) / parseFloat(salePrice) * 100)).toFixed(1)}%
+                                                        <span className="ml-1 opacity-80">
+                                                            {((parseFloat(salePrice) - parseFloat(importCost)) / parseFloat(salePrice) * 100) >= 50 ? "(Potentially Eligible)" : "(Ineligible)"}
+                                                        </span>
                                                     </div>
 
-                                                    {/* Calculated DVA Display */}
-                                                    {(salePrice && importCost) && (
-                                                        <div className="space-y-2">
-                                                            <div className={`p-3 rounded border text-center text-sm font-semibold ${((parseFloat(salePrice) - parseFloat(importCost)) / parseFloat(salePrice) * 100) >= 50
-                                                                ? "bg-emerald-500/10 border-emerald-500/30 text-emerald-400"
-                                                                : "bg-red-500/10 border-red-500/30 text-red-400"
-                                                                }`}>
-                                                                Estimated DVA: {Math.max(0, ((parseFloat(salePrice) - parseFloat(importCost)) / parseFloat(salePrice) * 100)).toFixed(1)}%
-                                                                <span className="ml-1 opacity-80">
-                                                                    {((parseFloat(salePrice) - parseFloat(importCost)) / parseFloat(salePrice) * 100) >= 50 ? "(Potentially Eligible)" : "(Ineligible)"}
-                                                                </span>
-                                                            </div>
-
-                                                            {/* Legal Warning Banner */}
-                                                            <div className="p-3 bg-amber-500/10 border border-amber-500/20 rounded-lg text-amber-200/80 text-xs flex gap-2 items-start">
-                                                                <Activity className="h-4 w-4 text-amber-500 mt-0.5 shrink-0" />
-                                                                <span>
-                                                                    <strong>Note:</strong> This is an estimated value based on raw material costs.
-                                                                    Final PLI eligibility requires certification by a Chartered Accountant.
-                                                                </span>
-                                                            </div>
-                                                        </div>
-                                                    )}
+                                                    {/* Legal Warning Banner */}
+                                                    <div className="p-3 bg-amber-500/10 border border-amber-500/20 rounded-lg text-amber-200/80 text-xs flex gap-2 items-start">
+                                                        <Activity className="h-4 w-4 text-amber-500 mt-0.5 shrink-0" />
+                                                        <span>
+                                                            <strong>Note:</strong> This is an estimated value based on raw material costs.
+                                                            Final PLI eligibility requires certification by a Chartered Accountant;
+                                                            request a CA attestation from the batch page once it is created.
+                                                        </span>
+                                                    </div>
                                                 </div>
                                             )}
-                                        </>
+                                        </div>
                                     )}
 
                                     {/* Cell Source Selection */}
@@ -1005,11 +956,11 @@ export function CreateBatchDialog({ onBatchCreated }: CreateBatchDialogProps) {
                                             id="cellSource"
                                             value={cellSource}
                                             onChange={(e) => setCellSource(e.target.value as any)}
-                                            className={`flex h-10 w-full rounded-md border px-3 py-2 text-sm text-zinc-100 focus-visible:outline-none focus-visible:ring-2 ${!cellSource && marketRegion === "INDIA"
+                                            className={`flex h-10 w-full rounded-md border px-3 py-2 text-sm text-zinc-100 focus-visible:outline-none focus-visible:ring-2 ${!cellSource && isIndiaMode
                                                 ? "border-red-500/50 bg-zinc-800"
                                                 : "border-zinc-700 bg-zinc-800"
                                                 }`}
-                                            required
+                                            required={isIndiaMode}
                                         >
                                             <option value="">-- Select Cell Source --</option>
                                             <option value="DOMESTIC">🏭 Domestic (Enables DVA Calculator)</option>
@@ -0,0 +1,204 @@
+"use client"
+
+import { useEffect, useState } from "react"
+import { Button } from "@/components/ui/button"
+import { Input } from "@/components/ui/input"
+import { Label } from "@/components/ui/label"
+import { Badge } from "@/components/ui/badge"
+import { Card, CardContent, CardHeader, CardTitle, CardDescription } from "@/components/ui/card"
+import { Shield, Send, XCircle, FileCheck, Clock, History } from "lucide-react"
+import { toast } from "sonner"
+import { cancelPLIAudit, getPLIAudits, getPLICertificate, requestPLIAudit } from "@/lib/api/pli-audit"
+import type { PLIAuditHistory, PLIAuditLogEntry } from "@/lib/types"
+
+interface PLIAuditPanelProps {
+    batchId: string
+    onChange?: () => void // Called after a request or cancellation to refresh the batch
+}
+
+const actionLabels: Record<PLIAuditLogEntry["action"], string> = {
+    REQUESTED: "Attestation requested",
+    CANCELLED: "Request cancelled",
+    ATTESTED: "DVA attested",
+}
+
+export function PLIAuditPanel({ batchId, onChange }: PLIAuditPanelProps) {
+    const [history, setHistory] = useState<PLIAuditHistory | null>(null)
+    const [caEmail, setCaEmail] = useState("")
+    const [caName, setCaName] = useState("")
+    const [note, setNote] = useState("")
+    const [submitting, setSubmitting] = useState(false)
+
+    const fetchHistory = async () => {
+        try {
+            setHistory(await getPLIAudits(batchId))
+        } catch (error) {
+            console.error("Failed to fetch PLI audits:", error)
+        }
+    }
+
+    useEffect(() => {
+        fetchHistory()
+    }, [batchId])
+
+    const pending = history?.audits.find((a) => a.status === "PENDING")
+
+    const handleRequest = async (e: React.FormEvent) => {
+        e.preventDefault()
+        setSubmitting(true)
+        try {
+            await requestPLIAudit(batchId, { ca_email: caEmail, ca_name: caName || undefined, note: note || undefined })
+            toast.success(`Attestation link sent to ${caEmail}`)
+            setCaEmail("")
+            setCaName("")
+            setNote("")
+            await fetchHistory()
+            onChange?.()
+        } catch (error: any) {
+            toast.error(error.response?.data?.error || "Failed to request CA attestation")
+        } finally {
+            setSubmitting(false)
+        }
+    }
+
+    const handleCancel = async (auditId: string) => {
+        try {
+            await cancelPLIAudit(batchId, auditId)
+            toast.success("Attestation request cancelled")
+            await fetchHistory()
+            onChange?.()
+        } catch (error: any) {
+            toast.error(error.response?.data?.error || "Failed to cancel request")
+        }
+    }
+
+    const handleViewCertificate = async () => {
+        try {
+            const blob = await getPLICertificate(batchId)
+            const url = window.URL.createObjectURL(new Blob([blob], { type: "application/pdf" }))
+            window.open(url, "_blank")
+        } catch (error) {
+            console.error("Failed to open PLI certificate", error)
+            toast.error("Failed to open CA certificate")
+        }
+    }
+
+    if (!history) return null
+
+    return (
+        <Card className="bg-slate-900/80 border-slate-800">
+            <CardHeader>
+                <CardTitle className="flex items-center gap-2 text-white">
+                    <div className="p-2 rounded-lg bg-emerald-500/10">
+                        <Shield className="h-5 w-5 text-emerald-400" />
+                    </div>
+                    PLI DVA Attestation
+                    {history.dva_source === "AUDITED" ? (
+                        <Badge className="bg-emerald-500/20 text-emerald-400 border-emerald-500/30 text-[10px] px-2">CA Verified</Badge>
+                    ) : (
+                        <Badge variant="outline" className="text-slate-500 text-[10px] px-2">Estimated</Badge>
+                    )}
+                </CardTitle>
+                <CardDescription className="text-slate-400">
+                    A chartered accountant attests the domestic value addition through a one-time link.
+                    The attested value replaces the estimate and cannot be edited afterwards.
+                </CardDescription>
+            </CardHeader>
+            <CardContent className="space-y-6">
+                {history.dva_source === "AUDITED" && (
+                    <div className="flex items-center justify-between p-4 rounded-xl bg-emerald-500/10 border border-emerald-500/20">
+                        <div>
+                            <p className="text-sm text-emerald-300/80">Attested DVA</p>
+                            <p className="text-2xl font-bold text-emerald-400">{history.audited_dva?.toFixed(2)}%</p>
+                        </div>
+                        <Button variant="outline" size="sm" onClick={handleViewCertificate} className="border-emerald-500/30 text-emerald-400">
+                            <FileCheck className="h-4 w-4 mr-2" /> View Certificate
+                        </Button>
+                    </div>
+                )}
+
+                {pending ? (
+                    <div className="flex items-center justify-between p-4 rounded-xl bg-slate-800/50 border border-slate-700/50">
+                        <div className="text-sm">
+                            <p className="text-white flex items-center gap-2">
+                                <Clock className="h-4 w-4 text-amber-400" />
+                                Waiting for {pending.ca_name} ({pending.ca_email})
+                            </p>
+                            <p className="text-slate-500 mt-1">Link expires {new Date(pending.expires_at).toLocaleDateString()}</p>
+                        </div>
+                        <Button variant="ghost" size="sm" onClick={() => handleCancel(pending.id)} className="text-slate-400 hover:text-red-400">
+                            <XCircle className="h-4 w-4 mr-2" /> Cancel
+                        </Button>
+                    </div>
+                ) : (
+                    <form onSubmit={handleRequest} className="grid gap-4 md:grid-cols-2">
+                        <div className="grid gap-2">
+                            <Label htmlFor="caEmail" className="text-slate-300">CA Email <span className="text-red-500">*</span></Label>
+                            <Input
+                                id="caEmail"
+                                type="email"
+                                required
+                                value={caEmail}
+                                onChange={(e) => setCaEmail(e.target.value)}
+                                placeholder="ca@firm.in"
+                                className="bg-slate-800 border-slate-700 text-white"
+                            />
+                        </div>
+                        <div className="grid gap-2">
+                            <Label htmlFor="caName" className="text-slate-300">CA / Firm Name</Label>
+                            <Input
+                                id="caName"
+                                value={caName}
+                                onChange={(e) => setCaName(e.target.value)}
+                                className="bg-slate-800 border-slate-700 text-white"
+                            />
+                        </div>
+                        <div className="grid gap-2 md:col-span-2">
+                            <Label htmlFor="caNote" className="text-slate-300">Note to the CA</Label>
+                            <Input
+                                id="caNote"
+                                value={note}
+                                onChange={(e) => setNote(e.target.value)}
+                                maxLength={1000}
+                                className="bg-slate-800 border-slate-700 text-white"
+                            />
+                        </div>
+                        <div className="md:col-span-2">
+                            <Button type="submit" disabled={submitting || !caEmail} className="bg-emerald-600 hover:bg-emerald-500">
+                                <Send className="h-4 w-4 mr-2" />
+                                {history.dva_source === "AUDITED" ? "Request Re-attestation" : "Request CA Attestation"}
+                            </Button>
+                        </div>
+                    </form>
+                )}
+
+                {history.log.length > 0 && (
+                    <div>
+                        <p className="text-slate-500 text-xs uppercase tracking-wider mb-3 flex items-center gap-2">
+                            <History className="h-3.5 w-3.5" /> Audit Log
+                        </p>
+                        <ul className="space-y-2">
+                            {history.log.map((entry) => (
+                                <li key={entry.id} className="text-sm flex items-start justify-between gap-4 border-b border-slate-800 pb-2">
+                                    <div>
+                                        <p className="text-white">
+                                            {actionLabels[entry.action]}
+                                            {entry.action === "ATTESTED" && entry.details?.audited_dva !== undefined && (
+                                                <span className="text-emerald-400"> · {Number(entry.details.audited_dva).toFixed(2)}%</span>
+                                            )}
+                                        </p>
+                                        <p className="text-slate-500">
+                                            {entry.actor}
+                                            {entry.action === "ATTESTED" && entry.details?.udin ? ` · UDIN ${entry.details.udin}` : ""}
+                                        </p>
+                                    </div>
+                                    <span className="text-slate-500 whitespace-nowrap">{new Date(entry.created_at).toLocaleString()}</span>
+                                </li>
+                            ))}
+                        </ul>
+                    </div>
+                )}
+            </CardContent>
+        </Card>
+    )
+}
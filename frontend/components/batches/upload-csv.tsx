@@ -21,7 +21,7 @@ import {
 
 interface UploadCSVProps {
     batchId: string
-    marketRegion?: 'INDIA' | 'EU' | 'GLOBAL'
+    marketRegion?: 'INDIA' | 'EU' | 'US' | 'UK' | 'GLOBAL'
     onUploadComplete: () => void
 }
 
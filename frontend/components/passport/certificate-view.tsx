@@ -4,7 +4,7 @@ import { CheckCircle, Leaf, Recycle, Shield, Factory, Zap, AlertTriangle, Truck,
 import { getBaseUrl } from "@/lib/api"
 import clsx from "clsx"
 
-type MarketRegion = "INDIA" | "EU" | "GLOBAL"
+type MarketRegion = "INDIA" | "EU" | "US" | "UK" | "GLOBAL"
 
 interface CertificateViewProps {
     passport: any
@@ -60,7 +60,10 @@ export function CertificateView({ passport }: CertificateViewProps) {
     const status = passportData.status || "CREATED"
 
     const marketRegion = (passport.market_region || "GLOBAL") as MarketRegion
-    const isIndia = marketRegion === "INDIA" || marketRegion === "GLOBAL"
+    // Target markets; older passports without them are GLOBAL (INDIA and EU)
+    const markets: MarketRegion[] = passport.markets?.length ? passport.markets
+        : marketRegion === "GLOBAL" ? ["INDIA", "EU"] : [marketRegion]
+    const isIndia = markets.includes("INDIA")
 
     const domesticValueAdd = passport.domestic_value_add ?? 0
     const cellSource = passport.cell_source || "DOMESTIC"
@@ -94,6 +97,11 @@ export function CertificateView({ passport }: CertificateViewProps) {
     const expectedLifetimeCycles = specs.expected_lifetime_cycles || 2000 // Default for LFP
     const recycledContentPct = specs.recycled_content_pct ?? 0 // Explicitly 0 if not declared
     const euRepresentative = specs.eu_representative || ""
+    const recycledShares = specs.recycled_content || {}
+    const hasRecycledShares = recycledShares.cobalt_pct || recycledShares.lithium_pct || recycledShares.nickel_pct || recycledShares.lead_pct
+    const dueDiligence = specs.due_diligence || null
+    const suppliers = dueDiligence?.suppliers || []
+    const auditReports = passport.audit_reports || []
 
     // EPR fallback
     const eprNumber = tenant.epr_registration_number || 'B-29016/2025-26/CPCB'
@@ -162,7 +170,7 @@ export function CertificateView({ passport }: CertificateViewProps) {
                     )}
                     <div className="bg-slate-50 rounded-lg p-3 border border-slate-100">
                         <p className="text-slate-500 text-xs uppercase tracking-wide mb-1">Market</p>
-                        <p className="text-slate-900 font-medium text-sm">{marketRegion}</p>
+                        <p className="text-slate-900 font-medium text-sm">{markets.join(", ")}</p>
                     </div>
                 </div>
             </div>
@@ -358,6 +366,52 @@ export function CertificateView({ passport }: CertificateViewProps) {
                             </div>
                         </div>
                     )}
+
+                    {/* Recycled content per material and supply-chain due diligence (supplier names and
+                        audit report files are withheld from the public passport) */}
+                    {(hasRecycledShares || dueDiligence || auditReports.length > 0) && (
+                        <div className="mt-4 pt-4 border-t border-slate-100">
+                            <p className="text-slate-600 text-xs uppercase tracking-wide mb-3 flex items-center gap-1">
+                                <Recycle className="w-3.5 h-3.5 text-slate-400" /> Supply Chain Due Diligence
+                            </p>
+                            <div className="space-y-0">
+                                {recycledShares.cobalt_pct > 0 && <DataRow label="Recycled Cobalt" value={`${recycledShares.cobalt_pct}%`} />}
+                                {recycledShares.lithium_pct > 0 && <DataRow label="Recycled Lithium" value={`${recycledShares.lithium_pct}%`} />}
+                                {recycledShares.nickel_pct > 0 && <DataRow label="Recycled Nickel" value={`${recycledShares.nickel_pct}%`} />}
+                                {recycledShares.lead_pct > 0 && <DataRow label="Recycled Lead" value={`${recycledShares.lead_pct}%`} />}
+                                <DataRow label="Due Diligence Policy" value={dueDiligence?.policy} />
+                            </div>
+                            {suppliers.length > 0 && (
+                                <div className="mt-3 space-y-1">
+                                    {suppliers.map((supplier: any, idx: number) => (
+                                        <div key={idx} className="flex items-center justify-between text-sm">
+                                            <span className="text-slate-700">
+                                                {supplier.name || supplier.role.replace('_', ' ').toLowerCase()} · {supplier.material} · {supplier.country}
+                                            </span>
+                                            {supplier.certification && (
+                                                <span className="px-2 py-0.5 bg-slate-100 text-slate-600 text-xs rounded border border-slate-200">
+                                                    {supplier.certification}
+                                                </span>
+                                            )}
+                                        </div>
+                                    ))}
+                                </div>
+                            )}
+                            {auditReports.length > 0 && (
+                                <div className="mt-3 space-y-1">
+                                    {auditReports.map((report: any) => (
+                                        <div key={report.id} className="flex items-center gap-2 text-sm text-slate-500">
+                                            <FileText className="w-4 h-4 text-slate-400" />
+                                            {report.title}
+                                            {report.auditor && ` — ${report.auditor}`}
+                                            {report.issued_on && ` (${new Date(report.issued_on).toLocaleDateString('en-GB')})`}
+                                        </div>
+                                    ))}
+                                    <p className="text-slate-400 text-xs">Audit reports are available to authorised repair, recycling and logistics partners.</p>
+                                </div>
+                            )}
+                        </div>
+                    )}
                 </div>
 
                 {/* Manufacturer */}
@@ -6,7 +6,7 @@ import clsx from "clsx"
 import { motion } from "framer-motion"
 
 // Market region type
-type MarketRegion = "INDIA" | "EU" | "GLOBAL"
+type MarketRegion = "INDIA" | "EU" | "US" | "UK" | "GLOBAL"
 
 interface PassportViewProps {
     passport: any
@@ -82,9 +82,25 @@ function MarketBadge({ region }: { region: MarketRegion }) {
             </motion.span>
         )
     }
+    if (region === "US" || region === "UK") {
+        return (
+            <motion.span
+                initial={{ scale: 0.9, opacity: 0 }}
+                animate={{ scale: 1, opacity: 1 }}
+                className="inline-flex items-center gap-2 px-4 py-2 rounded-full bg-gradient-to-r from-purple-500/20 to-pink-500/20 ring-1 ring-purple-500/50 text-purple-400 text-sm font-bold"
+            >
+                {region === "US" ? "🇺🇸 UL Listed Market" : "🇬🇧 UKCA Market"}
+            </motion.span>
+        )
+    }
     return null
 }
 
+// formatDate shows a YYYY-MM-DD date like the other passport dates
+function formatDate(date?: string) {
+    return date ? new Date(date).toLocaleDateString(undefined, { year: 'numeric', month: 'short', day: 'numeric' }) : ""
+}
+
 // Verified badge with animation
 function VerifiedBadge() {
     return (
@@ -220,6 +236,9 @@ export function PassportView({ passport }: PassportViewProps) {
     const isIndia = marketRegion === "INDIA"
     const isEU = marketRegion === "EU"
 
+    // Obligations of the battery category (passport, labelling, carbon footprint, collection)
+    const obligations = passport.obligations
+
     // Fix: allow 0 to be a valid value for imported batches
     const domesticValueAdd = passport.domestic_value_add !== undefined ? passport.domestic_value_add : 65
 
@@ -643,6 +662,71 @@ export function PassportView({ passport }: PassportViewProps) {
                 </motion.div>
             )}
 
+            {/* ═══════════════════════════════════════════════════════════════════
+                BATTERY CATEGORY OBLIGATIONS (EU 2023/1542)
+            ═══════════════════════════════════════════════════════════════════ */}
+            {obligations && (
+                <motion.div variants={itemVariants}>
+                    <GlowCard glowColor="blue">
+                        <div className="p-5">
+                            <div className="flex items-center gap-3 mb-4">
+                                <div className="p-2 rounded-lg bg-blue-500/10">
+                                    <FileCheck className="h-5 w-5 text-blue-400" />
+                                </div>
+                                <div>
+                                    <h2 className="text-lg font-bold text-white">{obligations.name}</h2>
+                                    <p className="text-xs text-slate-500">Obligations under EU Battery Regulation 2023/1542</p>
+                                </div>
+                            </div>
+
+                            <div className="grid grid-cols-2 gap-3 mb-4">
+                                <div className="p-3 rounded-lg bg-slate-800/50 border border-slate-700/50">
+                                    <div className="text-xs uppercase tracking-wider text-slate-500 font-medium">Battery Passport</div>
+                                    <div className={clsx("font-semibold mt-0.5", obligations.passport_required ? "text-blue-400" : "text-slate-400")}>
+                                        {obligations.passport_required ? `Mandatory from ${formatDate(obligations.passport_from)}` : "Not required"}
+                                    </div>
+                                </div>
+                                <div className="p-3 rounded-lg bg-slate-800/50 border border-slate-700/50">
+                                    <div className="text-xs uppercase tracking-wider text-slate-500 font-medium">Carbon Footprint</div>
+                                    <div className={clsx("font-semibold mt-0.5", obligations.carbon_footprint_from ? "text-emerald-400" : "text-slate-400")}>
+                                        {obligations.carbon_footprint_from ? `Declared from ${formatDate(obligations.carbon_footprint_from)}` : "Not required"}
+                                    </div>
+                                </div>
+                            </div>
+
+                            <div className="space-y-2 mb-4">
+                                <div className="text-xs uppercase tracking-wider text-slate-500 font-medium">Labelling</div>
+                                <ul className="space-y-1">
+                                    {obligations.labelling.map((label) => (
+                                        <li key={label} className="flex items-start gap-2 text-sm text-slate-300">
+                                            <CheckCircle className="h-4 w-4 text-blue-400 mt-0.5 shrink-0" />
+                                            {label}
+                                        </li>
+                                    ))}
+                                </ul>
+                            </div>
+
+                            <div className="p-3 rounded-lg bg-emerald-500/5 border border-emerald-500/20">
+                                <div className="flex items-center gap-2 text-xs uppercase tracking-wider text-emerald-400 font-medium">
+                                    <Recycle className="h-3.5 w-3.5" />
+                                    Collection
+                                </div>
+                                <p className="text-sm text-slate-300 mt-1">{obligations.collection}</p>
+                                {obligations.collection_targets?.length > 0 && (
+                                    <div className="flex flex-wrap gap-2 mt-2">
+                                        {obligations.collection_targets.map((t: { by: string; pct: number }) => (
+                                            <span key={t.by} className="text-xs px-2 py-0.5 rounded bg-emerald-500/10 text-emerald-300">
+                                                {t.pct}% by {formatDate(t.by)}
+                                            </span>
+                                        ))}
+                                    </div>
+                                )}
+                            </div>
+                        </div>
+                    </GlowCard>
+                </motion.div>
+            )}
+
             {/* ═══════════════════════════════════════════════════════════════════
                 LIFECYCLE TIMELINE
             ═══════════════════════════════════════════════════════════════════ */}
@@ -65,7 +65,7 @@ export function CreateTemplateDialog({
 
         setIsLoading(true)
         try {
-            await api.post("/templates", {
+            const response = await api.post("/templates", {
                 tenant_id: user.tenant_id,
                 name: name.trim(),
                 specs: {
@@ -81,6 +81,9 @@ export function CreateTemplateDialog({
             })
 
             toast.success(`Template "${name}" created successfully`)
+            for (const warning of response.data.warnings || []) {
+                toast.warning(`${warning.field}: ${warning.message}`)
+            }
             resetForm()
             onTemplateCreated()
         } catch (error: any) {
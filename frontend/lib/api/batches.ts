@@ -1,6 +1,24 @@
 import api from '../api';
+import type { BatteryCategoryRules, ComplianceReport, MarketRegion, MarketRulePackInfo } from '../types';
 
 export const duplicateBatch = async (id: string) => {
     const response = await api.post(`/batches/${id}/duplicate`);
     return response.data;
 };
+
+// market narrows the checklist to one or more markets; omitted checks the batch's own markets
+export const getBatchCompliance = async (id: string, market?: MarketRegion | MarketRegion[]): Promise<ComplianceReport> => {
+    const value = Array.isArray(market) ? market.join(',') : market;
+    const response = await api.get(`/batches/${id}/compliance`, { params: value ? { market: value } : undefined });
+    return response.data;
+};
+
+export const getMarketRules = async (): Promise<MarketRulePackInfo[]> => {
+    const response = await api.get('/markets');
+    return response.data.markets;
+};
+
+export const getBatteryCategories = async (): Promise<BatteryCategoryRules[]> => {
+    const response = await api.get('/battery-categories');
+    return response.data.categories;
+};
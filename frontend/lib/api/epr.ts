@@ -0,0 +1,26 @@
+import api from '../api';
+import type { EPRAnnualReturn, EPRTarget } from '../types';
+
+export const getEPRTargets = async (): Promise<EPRTarget[]> => {
+    const response = await api.get('/epr/targets');
+    return response.data.targets;
+};
+
+export const updateEPRTargets = async (targets: Pick<EPRTarget, 'category' | 'target_pct'>[]): Promise<EPRTarget[]> => {
+    const response = await api.put('/epr/targets', { targets });
+    return response.data.targets;
+};
+
+export const getEPRAnnualReturn = async (fy?: string): Promise<EPRAnnualReturn> => {
+    const response = await api.get('/epr/annual-return', { params: fy ? { fy } : undefined });
+    return response.data;
+};
+
+// Returns the XLSX or PDF file for download
+export const exportEPRAnnualReturn = async (format: 'xlsx' | 'pdf', fy?: string): Promise<Blob> => {
+    const response = await api.get('/epr/annual-return/export', {
+        params: fy ? { fy, format } : { format },
+        responseType: 'blob',
+    });
+    return response.data;
+};
@@ -0,0 +1,23 @@
+import api from '../api';
+import type { PLIAudit, PLIAuditHistory } from '../types';
+
+export const requestPLIAudit = async (batchId: string, req: { ca_email: string; ca_name?: string; note?: string }): Promise<PLIAudit> => {
+    const response = await api.post(`/batches/${batchId}/pli-audit`, req);
+    return response.data.audit;
+};
+
+export const getPLIAudits = async (batchId: string): Promise<PLIAuditHistory> => {
+    const response = await api.get(`/batches/${batchId}/pli-audit`);
+    return response.data;
+};
+
+export const cancelPLIAudit = async (batchId: string, auditId: string): Promise<PLIAudit> => {
+    const response = await api.post(`/batches/${batchId}/pli-audit/${auditId}/cancel`);
+    return response.data.audit;
+};
+
+// Returns the signed CA certificate PDF of the batch's latest attestation
+export const getPLICertificate = async (batchId: string): Promise<Blob> => {
+    const response = await api.get(`/batches/${batchId}/pli-audit/certificate`, { responseType: 'blob' });
+    return response.data;
+};
@@ -0,0 +1,36 @@
+import api from '../api';
+import type { CreateShipmentRequest, Shipment, ShipmentDetail, ShipmentStatus } from '../types';
+
+export const createShipment = async (data: CreateShipmentRequest): Promise<ShipmentDetail> => {
+    const response = await api.post('/shipments', data);
+    return response.data;
+};
+
+export const getShipments = async (status?: ShipmentStatus): Promise<Shipment[]> => {
+    const response = await api.get('/shipments', { params: status ? { status } : undefined });
+    return response.data.shipments || [];
+};
+
+export const getShipment = async (id: string): Promise<ShipmentDetail> => {
+    const response = await api.get(`/shipments/${id}`);
+    return response.data;
+};
+
+// Returns the customs documentation pack: a ZIP (PDF, passport CSV, QR codes) or the PDF alone
+export const downloadShipmentDocuments = async (id: string, format: 'zip' | 'pdf' = 'zip'): Promise<Blob> => {
+    const response = await api.get(`/shipments/${id}/documents`, {
+        params: { format },
+        responseType: 'blob',
+    });
+    return response.data;
+};
+
+export const shipShipment = async (id: string): Promise<{ shipment: Shipment; shipped: number }> => {
+    const response = await api.post(`/shipments/${id}/ship`);
+    return response.data;
+};
+
+export const cancelShipment = async (id: string): Promise<Shipment> => {
+    const response = await api.post(`/shipments/${id}/cancel`);
+    return response.data.shipment;
+};
@@ -80,10 +80,43 @@ export interface PassportEvent {
 // BATCH & SPECS
 // ============================================================================
 
-export type MarketRegion = 'INDIA' | 'EU' | 'GLOBAL';
+// GLOBAL summarises a batch targeting several markets (listed in Batch.markets)
+export type MarketRegion = 'INDIA' | 'EU' | 'US' | 'UK' | 'GLOBAL';
 
 export type BatchStatus = 'DRAFT' | 'ACTIVE' | 'ARCHIVED';
 
+// EU Battery Regulation 2023/1542 battery categories (GET /battery-categories)
+export type BatteryCategory = 'PORTABLE' | 'LMT' | 'EV' | 'INDUSTRIAL' | 'SLI';
+
+export interface CollectionTarget {
+    by: string; // YYYY-MM-DD
+    pct: number; // literal: 63 = 63%
+}
+
+export interface BatteryCategoryRules {
+    category: BatteryCategory;
+    name: string;
+    epr_category: EPRCategory;
+    passport_from?: string;         // YYYY-MM-DD
+    carbon_footprint_from?: string; // YYYY-MM-DD
+    obligation_min_energy_wh?: number; // Passport and carbon footprint only above this rated energy
+    labelling: string[];
+    collection_targets?: CollectionTarget[];
+    collection: string;
+}
+
+// Obligations of one battery, on its public passport
+export interface BatteryObligations {
+    category: BatteryCategory;
+    name: string;
+    passport_required: boolean;
+    passport_from?: string;
+    carbon_footprint_from?: string; // Set when the declaration is required
+    labelling: string[];
+    collection_targets?: CollectionTarget[];
+    collection: string;
+}
+
 // MaterialComposition uses float64 percentages (stored as literal: 12.5 = 12.5%)
 export interface MaterialComposition {
     cobalt_pct?: number;
@@ -91,6 +124,30 @@ export interface MaterialComposition {
     nickel_pct?: number;
     lead_pct?: number;
     manganese_pct?: number;
+    mercury_pct?: number;
+    cadmium_pct?: number;
+}
+
+// RecycledContent is the recycled share of each material (literal: 16 = 16%)
+export interface RecycledContent {
+    cobalt_pct?: number;
+    lithium_pct?: number;
+    nickel_pct?: number;
+    lead_pct?: number;
+}
+
+// SupplyChainParty is a supplier, smelter or mine; name is withheld on the public passport
+export interface SupplyChainParty {
+    name?: string;
+    role: 'MINE' | 'SMELTER' | 'REFINER' | 'RECYCLER' | 'CATHODE_PRODUCER' | 'CELL_MANUFACTURER' | 'TRADER';
+    material: 'cobalt' | 'lithium' | 'nickel' | 'graphite';
+    country: string; // ISO 3166-1 alpha-2
+    certification?: string;
+}
+
+export interface DueDiligence {
+    policy?: string;
+    suppliers?: SupplyChainParty[];
 }
 
 // HazardousSubstances for REACH/RoHS compliance
@@ -122,6 +179,24 @@ export interface BatchSpec {
     recycled_content_pct?: number;     // stored as literal: 15.5 = 15.5%
     hazardous_substances?: HazardousSubstances;
 
+    // Recycled share per material and supply-chain due diligence
+    recycled_content?: RecycledContent;
+    due_diligence?: DueDiligence;
+
+    // Battery category under the EU Battery Regulation; sets passport, labelling,
+    // carbon footprint and collection obligations
+    battery_category?: BatteryCategory;
+
+    // Battery category under India's BWM Rules 2022 (EPR annual return); derived from battery_category when unset
+    epr_category?: EPRCategory;
+
+    // UN38.3 test summary reference for the customs pack (report number, lab, date)
+    un38_3_test_summary?: string;
+
+    // UK importer or authorised representative and Waste Batteries Regulations producer number
+    uk_responsible_person?: string;
+    uk_producer_number?: string;
+
     // India PLI Compliance Fields - Financial data for DVA calculation
     sale_price_inr?: number;   // Sale price in INR for DVA calculation
     import_cost_inr?: number;  // Import material cost in INR
@@ -137,6 +212,7 @@ export interface Batch {
 
     // Dual-Mode Compliance Fields
     market_region: MarketRegion;
+    markets: MarketRegion[]; // Target markets (INDIA, EU, US, UK)
     pli_compliant: boolean;
     domestic_value_add: number; // stored as literal: 45.5 = 45.5%
     cell_source?: 'IMPORTED' | 'DOMESTIC';
@@ -149,6 +225,11 @@ export interface Batch {
 
     // India Compliance Fields
     hsn_code?: string; // e.g., "8507.60"
+
+    // PLI DVA attestation - set only by a CA via the PLI audit workflow
+    dva_source?: DVASource;
+    audited_domestic_value_add?: number;
+    pli_certificate_url?: string; // API path of the signed CA certificate
 }
 
 // ============================================================================
@@ -195,3 +276,227 @@ export interface PassportEventsResponse {
     events: PassportEvent[];
     count: number;
 }
+
+// Compliance readiness checklist (GET /batches/{id}/compliance)
+export type ComplianceSeverity = 'BLOCKING' | 'ADVISORY';
+
+export interface ComplianceCheck {
+    key: string;
+    label: string;
+    market: MarketRegion;
+    severity: ComplianceSeverity;
+    passed: boolean;
+    message?: string;
+}
+
+export interface ComplianceReport {
+    batch_id: string;
+    market: MarketRegion;
+    markets: MarketRegion[];
+    score: number;
+    ready: boolean;
+    blocking_failures: number;
+    advisory_failures: number;
+    checks: ComplianceCheck[];
+    checked_at: string;
+}
+
+// Per-market rule packs (GET /markets)
+export type MarketRequirementKind = 'FIELD' | 'CERTIFICATION' | 'DOCUMENT';
+
+export interface MarketRequirement {
+    key: string;
+    label: string;
+    kind: MarketRequirementKind;
+    severity: ComplianceSeverity;
+    on_create?: 'ERROR' | 'WARNING'; // Checked at batch creation
+}
+
+export interface MarketRulePackInfo {
+    market: MarketRegion;
+    name: string;
+    certifications: string[];
+    documents: string[];
+    requirements: MarketRequirement[];
+}
+
+// EPR annual return (BWM Rules 2022)
+export type EPRCategory = 'PORTABLE' | 'AUTOMOTIVE' | 'INDUSTRIAL' | 'EV';
+
+export interface EPRTarget {
+    category: EPRCategory;
+    target_pct: number;
+    updated_at?: string; // Unset for defaults
+}
+
+export interface EPRReturnLine {
+    category: EPRCategory | 'UNCLASSIFIED';
+    chemistry: string;
+    batches: number;
+    units_placed: number;
+    weight_placed_kg: number;
+    units_recycled: number;
+    weight_recycled_kg: number;
+    units_without_weight: number;
+}
+
+export interface EPRObligation {
+    category: EPRCategory;
+    target_pct: number;
+    weight_placed_kg: number;
+    obligation_kg: number;
+    recycled_credit_kg: number;
+    shortfall_kg: number;
+    fulfilled_pct: number;
+}
+
+export interface EPRAnnualReturn {
+    financial_year: string; // e.g. 2025-26
+    period_start: string;
+    period_end: string;
+    company_name: string;
+    epr_registration_number: string;
+    lines: EPRReturnLine[];
+    obligations: EPRObligation[];
+    total_units_placed: number;
+    total_weight_placed_kg: number;
+    total_units_recycled: number;
+    total_weight_recycled_kg: number;
+    warnings?: string[];
+    generated_at: string;
+}
+
+// PLI DVA audit - CA attestation of a batch's domestic value addition
+export type DVASource = 'ESTIMATED' | 'AUDITED';
+export type PLIAuditStatus = 'PENDING' | 'ATTESTED' | 'CANCELLED' | 'EXPIRED';
+
+export interface PLIAudit {
+    id: string;
+    batch_id: string;
+    ca_email: string;
+    ca_name: string;
+    note?: string;
+    status: PLIAuditStatus;
+    expires_at: string;
+    requested_by: string;
+    created_at: string;
+    audited_dva?: number;
+    ca_membership_no?: string;
+    udin?: string;
+    certificate_file_name?: string;
+    certificate_sha256?: string;
+    attested_at?: string;
+    closed_at?: string;
+}
+
+export interface PLIAuditLogEntry {
+    id: string;
+    audit_id: string;
+    action: 'REQUESTED' | 'CANCELLED' | 'ATTESTED';
+    actor: string;
+    details?: Record<string, unknown>;
+    created_at: string;
+}
+
+export interface PLIAuditHistory {
+    batch_id: string;
+    dva_source: DVASource;
+    audited_dva?: number;
+    audits: PLIAudit[];
+    log: PLIAuditLogEntry[];
+}
+
+export interface PLIAuditInvitation {
+    audit_id: string;
+    status: PLIAuditStatus;
+    ca_name: string;
+    ca_email: string;
+    note?: string;
+    expires_at: string;
+    company_name: string;
+    batch_name: string;
+    market_region: MarketRegion;
+    cell_source?: string;
+    chemistry?: string;
+    dva_source: DVASource;
+    current_dva: number;
+    pli_minimum_dva: number;
+}
+
+// Export shipments and their customs documentation pack
+export type ShipmentStatus = 'DRAFT' | 'SHIPPED' | 'CANCELLED';
+export type ShipmentPacking = 'BATTERIES_ONLY' | 'PACKED_WITH_EQUIPMENT' | 'CONTAINED_IN_EQUIPMENT';
+
+export interface Shipment {
+    id: string;
+    reference: string;
+    status: ShipmentStatus;
+    destination_country: string;
+    consignee_name: string;
+    consignee_address?: string;
+    invoice_number?: string;
+    invoice_date?: string; // YYYY-MM-DD
+    incoterm?: string;
+    currency: string;
+    unit_values?: Record<string, number>; // Invoice value per unit, by batch ID
+    carrier?: string;
+    port_of_loading?: string;
+    port_of_discharge?: string;
+    packing: ShipmentPacking;
+    note?: string;
+    passport_count: number;
+    created_by: string;
+    created_at: string;
+    shipped_by?: string;
+    shipped_at?: string;
+    cancelled_at?: string;
+}
+
+export interface DangerousGoods {
+    un_number: string;
+    proper_shipping_name: string;
+    class: string;
+}
+
+export interface ShipmentLine {
+    batch_id: string;
+    batch_name: string;
+    chemistry: string;
+    capacity?: string;
+    voltage?: string;
+    hsn_code?: string;
+    country_of_origin?: string;
+    quantity: number;
+    unit_value: number;
+    total_value: number;
+    unit_weight_kg?: number;
+    net_weight_kg?: number;
+    rated_energy_wh?: number;
+    dangerous_goods?: DangerousGoods;
+    un38_3_test_summary?: string;
+}
+
+export interface ShipmentDetail {
+    shipment: Shipment;
+    lines: ShipmentLine[];
+    warnings?: string[];
+}
+
+export interface CreateShipmentRequest {
+    reference: string;
+    destination_country: string;
+    consignee_name: string;
+    consignee_address?: string;
+    invoice_number?: string;
+    invoice_date?: string;
+    incoterm?: string;
+    currency?: string;
+    unit_values?: Record<string, number>;
+    carrier?: string;
+    port_of_loading?: string;
+    port_of_discharge?: string;
+    packing?: ShipmentPacking;
+    note?: string;
+    batch_ids?: string[];
+    passport_ids?: string[];
+}